@@ -0,0 +1,112 @@
+// Package adapter translates MCP requests and responses between protocol versions,
+// so that a client and a backend which negotiated different versions with the
+// gateway can still exchange messages the gateway relays without decoding.
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnsupportedVersionPair is returned when no adapter is registered for a
+// (client version, backend version) pair.
+var ErrUnsupportedVersionPair = errors.New("unsupported protocol version pair")
+
+// Transform rewrites the decoded params (for requests) or result (for responses)
+// of the given method in place.
+type Transform func(method string, body map[string]interface{}) error
+
+// Adapter converts messages between a client on ClientVersion and a backend on BackendVersion.
+type Adapter struct {
+	ClientVersion  string
+	BackendVersion string
+	Request        Transform // Applied to params sent from the client to the backend, may be nil
+	Response       Transform // Applied to results sent from the backend to the client, may be nil
+}
+
+// AdaptRequest converts request params from the client's version to the backend's.
+func (a *Adapter) AdaptRequest(method string, params json.RawMessage) (json.RawMessage, error) {
+	return apply(a.Request, method, params)
+}
+
+// AdaptResponse converts a result from the backend's version to the client's.
+func (a *Adapter) AdaptResponse(method string, result json.RawMessage) (json.RawMessage, error) {
+	return apply(a.Response, method, result)
+}
+
+// Translates reports whether the adapter converts messages, i.e. it is not the one used
+// when both sides speak the same version.
+func (a *Adapter) Translates() bool {
+	return a != identity
+}
+
+// apply runs the transform on body. Without a transform the bytes are returned untouched.
+func apply(transform Transform, method string, body json.RawMessage) (json.RawMessage, error) {
+	if transform == nil || len(body) == 0 {
+		return body, nil
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode %s message for version adaptation: %w", method, err)
+	}
+	if decoded == nil {
+		return body, nil
+	}
+	if err := transform(method, decoded); err != nil {
+		return nil, fmt.Errorf("failed to adapt %s message: %w", method, err)
+	}
+	return json.Marshal(decoded)
+}
+
+// identity is used when both sides speak the same version.
+var identity = &Adapter{}
+
+type versionPair struct {
+	client  string
+	backend string
+}
+
+// Registry holds adapters keyed by the negotiated client and backend versions.
+type Registry struct {
+	mu       sync.RWMutex
+	adapters map[versionPair]*Adapter
+}
+
+// NewRegistry creates an empty registry. Same-version pairs never need an adapter.
+func NewRegistry() *Registry {
+	return &Registry{adapters: make(map[versionPair]*Adapter)}
+}
+
+// Register adds or replaces the adapter for its version pair.
+func (r *Registry) Register(a *Adapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters[versionPair{a.ClientVersion, a.BackendVersion}] = a
+}
+
+// Lookup returns the adapter for the pair. If the versions are equal, or either is
+// unknown (empty), messages are relayed unchanged. An unregistered pair returns an
+// error wrapping ErrUnsupportedVersionPair.
+func (r *Registry) Lookup(clientVersion string, backendVersion string) (*Adapter, error) {
+	if clientVersion == backendVersion || clientVersion == "" || backendVersion == "" {
+		return identity, nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.adapters[versionPair{clientVersion, backendVersion}]
+	if !ok {
+		return nil, fmt.Errorf("%w: client '%s', backend '%s'", ErrUnsupportedVersionPair, clientVersion, backendVersion)
+	}
+	return a, nil
+}
+
+// Default is the registry used by the gateway, preloaded with the built-in adapters.
+var Default = NewRegistry()
+
+func init() {
+	for _, a := range builtinAdapters() {
+		Default.Register(a)
+	}
+}
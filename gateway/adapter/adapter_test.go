@@ -0,0 +1,114 @@
+package adapter_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/gateway/adapter"
+	schema2024 "github.com/gate4ai/mcp/shared/mcp/2024/schema"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+)
+
+// newV2ToV1Registry registers an adapter for a hypothetical v2 client and v1 backend,
+// where v1 names tool arguments "args" and returns tool output under "items".
+func newV2ToV1Registry() *adapter.Registry {
+	registry := adapter.NewRegistry()
+	registry.Register(&adapter.Adapter{
+		ClientVersion:  "v2",
+		BackendVersion: "v1",
+		Request: func(method string, params map[string]interface{}) error {
+			if method == "tools/call" {
+				params["args"] = params["arguments"]
+				delete(params, "arguments")
+			}
+			return nil
+		},
+		Response: func(method string, result map[string]interface{}) error {
+			if method == "tools/call" {
+				result["content"] = result["items"]
+				delete(result, "items")
+			}
+			return nil
+		},
+	})
+	return registry
+}
+
+func TestAdapterConvertsRequestAndResponse(t *testing.T) {
+	a, err := newV2ToV1Registry().Lookup("v2", "v1")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+
+	params, err := a.AdaptRequest("tools/call", json.RawMessage(`{"name":"echo","arguments":{"text":"hi"}}`))
+	if err != nil {
+		t.Fatalf("AdaptRequest failed: %v", err)
+	}
+	if want := `{"args":{"text":"hi"},"name":"echo"}`; string(params) != want {
+		t.Fatalf("Unexpected backend params:\n got: %s\nwant: %s", params, want)
+	}
+
+	result, err := a.AdaptResponse("tools/call", json.RawMessage(`{"items":[{"type":"text","text":"hi"}]}`))
+	if err != nil {
+		t.Fatalf("AdaptResponse failed: %v", err)
+	}
+	if want := `{"content":[{"text":"hi","type":"text"}]}`; string(result) != want {
+		t.Fatalf("Unexpected client result:\n got: %s\nwant: %s", result, want)
+	}
+}
+
+func TestLookupUnsupportedPair(t *testing.T) {
+	registry := newV2ToV1Registry()
+	_, err := registry.Lookup("v1", "v2")
+	if !errors.Is(err, adapter.ErrUnsupportedVersionPair) {
+		t.Fatalf("Expected ErrUnsupportedVersionPair, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "'v1'") || !strings.Contains(err.Error(), "'v2'") {
+		t.Fatalf("Error should name both versions: %v", err)
+	}
+}
+
+func TestSameVersionRelaysBytesUnchanged(t *testing.T) {
+	a, err := adapter.NewRegistry().Lookup("v1", "v1")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	body := json.RawMessage(`{"zeta":1,"alpha":2}`)
+	result, err := a.AdaptResponse("tools/call", body)
+	if err != nil || string(result) != string(body) {
+		t.Fatalf("Expected bytes unchanged, got %s (%v)", result, err)
+	}
+}
+
+func TestBuiltinDowngradeFor2024Client(t *testing.T) {
+	a, err := adapter.Default.Lookup(schema2024.PROTOCOL_VERSION, schema2025.PROTOCOL_VERSION)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+
+	tools, err := a.AdaptResponse("tools/list", json.RawMessage(`{"tools":[{"name":"t","annotations":{"readOnlyHint":true}}]}`))
+	if err != nil {
+		t.Fatalf("AdaptResponse failed: %v", err)
+	}
+	if strings.Contains(string(tools), "annotations") {
+		t.Fatalf("Tool annotations should be removed: %s", tools)
+	}
+
+	call, err := a.AdaptResponse("tools/call", json.RawMessage(`{"content":[{"type":"audio","data":"AAAA","mimeType":"audio/wav"},{"type":"text","text":"ok"}]}`))
+	if err != nil {
+		t.Fatalf("AdaptResponse failed: %v", err)
+	}
+	var decoded schema2024.CallToolResult
+	if err := json.Unmarshal(call, &decoded); err != nil {
+		t.Fatalf("Result is not a valid 2024 result: %v", err)
+	}
+	if len(decoded.Content) != 2 || decoded.Content[0].Type != "text" || !strings.Contains(*decoded.Content[0].Text, "audio/wav") {
+		t.Fatalf("Audio content was not replaced: %s", call)
+	}
+
+	if _, err := adapter.Default.Lookup(schema2025.PROTOCOL_VERSION, schema2024.PROTOCOL_VERSION); err != nil {
+		t.Fatalf("Expected a built-in adapter for 2025 clients on 2024 backends: %v", err)
+	}
+}
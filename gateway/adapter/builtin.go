@@ -0,0 +1,74 @@
+package adapter
+
+import (
+	"fmt"
+
+	schema2024 "github.com/gate4ai/mcp/shared/mcp/2024/schema"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+)
+
+// builtinAdapters returns the adapters between the protocol versions the gateway supports.
+func builtinAdapters() []*Adapter {
+	return []*Adapter{
+		{
+			// 2024-11-05 results are valid 2025-03-26 results, and the requests the
+			// gateway relays have no fields a 2024-11-05 backend does not know.
+			ClientVersion:  schema2025.PROTOCOL_VERSION,
+			BackendVersion: schema2024.PROTOCOL_VERSION,
+		},
+		{
+			ClientVersion:  schema2024.PROTOCOL_VERSION,
+			BackendVersion: schema2025.PROTOCOL_VERSION,
+			Response:       downgradeResponse2025To2024,
+		},
+	}
+}
+
+// downgradeResponse2025To2024 removes what a 2024-11-05 client cannot read:
+// tool annotations and audio content, which is replaced by a text placeholder.
+func downgradeResponse2025To2024(method string, result map[string]interface{}) error {
+	switch method {
+	case "tools/list":
+		for _, tool := range objects(result["tools"]) {
+			delete(tool, "annotations")
+		}
+	case "tools/call":
+		for _, content := range objects(result["content"]) {
+			replaceAudio(content)
+		}
+	case "prompts/get":
+		for _, message := range objects(result["messages"]) {
+			if content, ok := message["content"].(map[string]interface{}); ok {
+				replaceAudio(content)
+			}
+		}
+	}
+	return nil
+}
+
+// replaceAudio turns an audio content item into a text item describing it.
+func replaceAudio(content map[string]interface{}) {
+	if content["type"] != "audio" {
+		return
+	}
+	mimeType, _ := content["mimeType"].(string)
+	for key := range content {
+		if key != "annotations" {
+			delete(content, key)
+		}
+	}
+	content["type"] = "text"
+	content["text"] = fmt.Sprintf("[audio content (%s) not supported by protocol version %s]", mimeType, schema2024.PROTOCOL_VERSION)
+}
+
+// objects returns the JSON objects contained in a decoded JSON array.
+func objects(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+// Package balancer chooses among the replicas of a backend in proportion to their
+// weights.
+package balancer
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// SmoothWeighted is a smooth weighted round-robin: over any run of requests each URL is
+// chosen in proportion to its weight, and the choices of a heavier URL are spread
+// between those of the others rather than made in a row. With equal weights it is a
+// plain round-robin. A SmoothWeighted is safe for concurrent use.
+type SmoothWeighted struct {
+	mu      sync.Mutex
+	current map[string]int // URL -> current weight
+}
+
+// NewSmoothWeighted creates a smooth weighted round-robin.
+func NewSmoothWeighted() *SmoothWeighted {
+	return &SmoothWeighted{current: make(map[string]int)}
+}
+
+// Next returns the next of the URLs. Weights below 1 count as 1. The URLs may differ
+// from call to call, e.g. while some are excluded; a URL passed again resumes its share.
+func (s *SmoothWeighted) Next(urls []string, weight func(url string) int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var best string
+	total := 0
+	for _, url := range urls {
+		w := max(weight(url), 1)
+		total += w
+		s.current[url] += w
+		if best == "" || s.current[url] > s.current[best] {
+			best = url
+		}
+	}
+	s.current[best] -= total
+	return best
+}
+
+// Rendezvous returns the URL with the highest score of key, so that a key keeps its URL
+// as long as it is available and only keys of an unavailable URL move. The share of the
+// keys of a URL is proportional to its weight; weights below 1 count as 1.
+func Rendezvous(key string, urls []string, weight func(url string) int) string {
+	var best string
+	var bestScore float64
+	for _, url := range urls {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(url))
+		// The mixed hash as a uniform number in (0, 1); -w/ln(u) is the largest among the
+		// URLs with a probability of w over the sum of the weights.
+		u := (float64(mix(h.Sum64())>>11) + 0.5) / (1 << 53)
+		if score := -float64(max(weight(url), 1)) / math.Log(u); best == "" || score > bestScore {
+			best, bestScore = url, score
+		}
+	}
+	return best
+}
+
+// mix spreads the bits of the FNV hash, whose high bits hardly depend on the last bytes
+// hashed, so that the hashes of a key with URLs differing at the end are independent.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,101 @@
+package balancer
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// weights returns the weight function of the URLs.
+func weights(w map[string]int) func(string) int {
+	return func(url string) int { return w[url] }
+}
+
+// assertShares fails the test unless each URL got its share of the weights within
+// tolerance, as a fraction of all choices.
+func assertShares(t *testing.T, counts map[string]int, w map[string]int, tolerance float64) {
+	t.Helper()
+	total, weightSum := 0, 0
+	for url := range w {
+		total += counts[url]
+		weightSum += max(w[url], 1)
+	}
+	for url, weight := range w {
+		want := float64(max(weight, 1)) / float64(weightSum)
+		if got := float64(counts[url]) / float64(total); math.Abs(got-want) > tolerance {
+			t.Errorf("URL %s got %.3f of the choices, want %.3f (counts %v)", url, got, want, counts)
+		}
+	}
+}
+
+func TestSmoothWeightedDistribution(t *testing.T) {
+	w := map[string]int{"a": 3, "b": 1, "c": 2}
+	urls := []string{"a", "b", "c"}
+	s := NewSmoothWeighted()
+
+	counts := make(map[string]int)
+	longestRun, run, last := 0, 0, ""
+	for range 6000 {
+		url := s.Next(urls, weights(w))
+		counts[url]++
+		if url == last {
+			run++
+		} else {
+			run, last = 1, url
+		}
+		longestRun = max(longestRun, run)
+	}
+	assertShares(t, counts, w, 0.01)
+	// The three choices of a in each round of 6 are not made in a row
+	if longestRun > 2 {
+		t.Errorf("Expected choices spread over the URLs, got a run of %d", longestRun)
+	}
+}
+
+func TestSmoothWeightedEqualWeightsRoundRobin(t *testing.T) {
+	urls := []string{"a", "b", "c"}
+	s := NewSmoothWeighted()
+	for i := range 9 {
+		if url := s.Next(urls, weights(nil)); url != urls[i%3] {
+			t.Fatalf("Choice %d = %s, want %s", i, url, urls[i%3])
+		}
+	}
+}
+
+func TestSmoothWeightedExcludedURL(t *testing.T) {
+	w := map[string]int{"a": 3, "b": 1}
+	s := NewSmoothWeighted()
+	for range 10 {
+		if url := s.Next([]string{"b"}, weights(w)); url != "b" {
+			t.Fatalf("Expected the only available URL, got %s", url)
+		}
+	}
+
+	counts := make(map[string]int)
+	for range 4000 {
+		counts[s.Next([]string{"a", "b"}, weights(w))]++
+	}
+	assertShares(t, counts, w, 0.01)
+}
+
+func TestRendezvousDistribution(t *testing.T) {
+	w := map[string]int{"a": 3, "b": 1, "c": 0}
+	urls := []string{"a", "b", "c"}
+	counts := make(map[string]int)
+	for i := range 20000 {
+		counts[Rendezvous(fmt.Sprint("session-", i), urls, weights(w))]++
+	}
+	assertShares(t, counts, w, 0.02)
+}
+
+func TestRendezvousMovesOnlyKeysOfRemovedURL(t *testing.T) {
+	w := map[string]int{"a": 3, "b": 1, "c": 2}
+	for i := range 1000 {
+		key := fmt.Sprint("session-", i)
+		before := Rendezvous(key, []string{"a", "b", "c"}, weights(w))
+		after := Rendezvous(key, []string{"a", "c"}, weights(w))
+		if before != "b" && after != before {
+			t.Fatalf("Key %s moved from %s to %s although its URL is available", key, before, after)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package breaker implements a circuit breaker that stops requests to a failing
+// backend for a cooldown period and then probes whether it recovered.
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/metrics"
+)
+
+// ErrOpen is matched by the errors returned by Allow while the breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker, as reported by Status.
+type State string
+
+const (
+	StateClosed   State = "closed"    // Requests are let through
+	StateOpen     State = "open"      // Requests are rejected until the cooldown has passed
+	StateHalfOpen State = "half_open" // The cooldown has passed, one request probes the backend
+)
+
+// OpenError is returned by Allow while the breaker rejects requests.
+type OpenError struct {
+	RetryIn time.Duration // Until the next request may be let through
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrOpen, e.RetryIn.Round(time.Millisecond))
+}
+
+// Is makes the error match ErrOpen.
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// Status is a snapshot of the state of a breaker.
+type Status struct {
+	State    State
+	Failures int           // Consecutive faults
+	RetryIn  time.Duration // While open, until a probe is let through
+}
+
+// DefaultFaults are the result classes (see metrics.ResultClass) counted as backend
+// faults when none are configured: timeouts, transport failures and JSON-RPC internal
+// and server errors. Errors caused by the request itself, such as invalid params or an
+// unknown method, and tool errors reported by the backend are not faults.
+var DefaultFaults = []string{
+	metrics.ResultTimeout,
+	metrics.ResultTransport,
+	metrics.ResultInternalError,
+	metrics.ResultServerError,
+}
+
+// Breaker opens after threshold consecutive faults and rejects requests until the
+// cooldown has passed. It is then half-open: a single request is let through to probe
+// the backend while the others are still rejected. A fault of the probe reopens the
+// breaker, any other outcome closes it. A probe that is never recorded, e.g. because it
+// was canceled, is given up after another cooldown. While closed, errors that are not
+// faults leave the breaker state unchanged. A Breaker is safe for concurrent use.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	faults    map[string]bool
+	now       func() time.Time
+
+	mu         sync.Mutex
+	failures   int       // Consecutive faults
+	openUntil  time.Time // Zero while closed
+	probeUntil time.Time // While half-open, until the probe in flight is given up
+}
+
+// New creates a closed breaker. Faults lists the result classes counted as faults,
+// DefaultFaults if empty.
+func New(threshold int, cooldown time.Duration, faults []string) *Breaker {
+	if len(faults) == 0 {
+		faults = DefaultFaults
+	}
+	b := &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		faults:    make(map[string]bool, len(faults)),
+		now:       time.Now,
+	}
+	for _, class := range faults {
+		b.faults[class] = true
+	}
+	return b
+}
+
+// Allow returns an *OpenError while the breaker is open, or half-open with a probe in
+// flight. Once the cooldown has passed, the first call lets the probe through.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	now := b.now()
+	if remaining := b.openUntil.Sub(now); remaining > 0 {
+		return &OpenError{RetryIn: remaining}
+	}
+	if remaining := b.probeUntil.Sub(now); remaining > 0 {
+		return &OpenError{RetryIn: remaining}
+	}
+	b.probeUntil = now.Add(b.cooldown)
+	return nil
+}
+
+// Record updates the breaker with the outcome of a request and reports whether err was
+// counted as a fault.
+func (b *Breaker) Record(err error) bool {
+	fault := err != nil && b.IsFault(err)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	halfOpen := !b.openUntil.IsZero() && !b.now().Before(b.openUntil)
+	switch {
+	case fault:
+		b.failures++
+		if halfOpen || b.failures >= b.threshold {
+			b.openUntil = b.now().Add(b.cooldown)
+			b.probeUntil = time.Time{}
+		}
+	case err == nil || halfOpen:
+		b.failures = 0
+		b.openUntil = time.Time{}
+		b.probeUntil = time.Time{}
+	}
+	return fault
+}
+
+// Status returns the current state of the breaker.
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := Status{State: StateClosed, Failures: b.failures}
+	if b.openUntil.IsZero() {
+		return status
+	}
+	if remaining := b.openUntil.Sub(b.now()); remaining > 0 {
+		status.State = StateOpen
+		status.RetryIn = remaining
+		return status
+	}
+	status.State = StateHalfOpen
+	return status
+}
+
+// IsFault reports whether err belongs to one of the fault classes of the breaker.
+func (b *Breaker) IsFault(err error) bool {
+	return b.faults[metrics.ResultClass(err)]
+}
@@ -0,0 +1,186 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/metrics"
+	"github.com/gate4ai/mcp/shared"
+)
+
+var (
+	errInvalidParams = &shared.JSONRPCError{Code: -32602, Message: "invalid params"}
+	errTimeout       = fmt.Errorf("tools/call: %w", context.DeadlineExceeded)
+)
+
+// newTestBreaker returns a breaker with a clock the test advances.
+func newTestBreaker(threshold int, faults []string) (*Breaker, *time.Time) {
+	now := time.Unix(1000, 0)
+	b := New(threshold, time.Minute, faults)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestClientErrorsDoNotTripBreaker(t *testing.T) {
+	b, _ := newTestBreaker(3, nil)
+	for i := 0; i < 10; i++ {
+		if b.Record(errInvalidParams) {
+			t.Fatalf("Invalid params counted as a fault")
+		}
+		b.Record(&shared.JSONRPCError{Code: -32601, Message: "method not found"})
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the breaker to stay closed, got: %v", err)
+	}
+}
+
+func TestTimeoutsTripBreaker(t *testing.T) {
+	b, now := newTestBreaker(3, nil)
+	for i := 0; i < 2; i++ {
+		if !b.Record(errTimeout) {
+			t.Fatalf("Timeout not counted as a fault")
+		}
+	}
+	// Client errors in between neither count nor reset the faults
+	b.Record(errInvalidParams)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the breaker to be closed below the threshold, got: %v", err)
+	}
+	b.Record(errTimeout)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected ErrOpen after 3 timeouts, got: %v", err)
+	}
+
+	// After the cooldown a trial request is let through; another fault reopens at once
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected requests after the cooldown, got: %v", err)
+	}
+	b.Record(errTimeout)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected the breaker to reopen, got: %v", err)
+	}
+
+	*now = now.Add(time.Minute)
+	b.Record(nil)
+	b.Record(errTimeout)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a success to reset the faults, got: %v", err)
+	}
+}
+
+func TestConfiguredFaultClasses(t *testing.T) {
+	b, _ := newTestBreaker(1, []string{metrics.ResultInvalidParams})
+	if b.Record(errTimeout) {
+		t.Fatalf("Timeout counted although not configured as a fault")
+	}
+	if !b.Record(errInvalidParams) {
+		t.Fatalf("Invalid params not counted although configured as a fault")
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected ErrOpen, got: %v", err)
+	}
+}
+
+func TestStateTransitions(t *testing.T) {
+	b, now := newTestBreaker(2, nil)
+	expectState := func(want State, failures int) {
+		t.Helper()
+		if status := b.Status(); status.State != want || status.Failures != failures {
+			t.Fatalf("Expected %s with %d faults, got %s with %d", want, failures, status.State, status.Failures)
+		}
+	}
+	expectState(StateClosed, 0)
+
+	b.Record(errTimeout)
+	expectState(StateClosed, 1)
+	b.Record(errTimeout)
+	expectState(StateOpen, 2)
+	var openErr *OpenError
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryIn != time.Minute {
+		t.Fatalf("Expected an OpenError retrying in the cooldown, got: %v", err)
+	}
+
+	// Half-open: a single probe is let through
+	*now = now.Add(time.Minute)
+	expectState(StateHalfOpen, 2)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the probe to be let through, got: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected other requests to be rejected during the probe, got: %v", err)
+	}
+
+	// A failed probe reopens the breaker for another cooldown
+	b.Record(errTimeout)
+	expectState(StateOpen, 3)
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected the breaker to reopen after a failed probe, got: %v", err)
+	}
+
+	// A successful probe closes it
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the next probe to be let through, got: %v", err)
+	}
+	b.Record(nil)
+	expectState(StateClosed, 0)
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Expected requests to be let through once closed, got: %v", err)
+		}
+	}
+}
+
+func TestProbeNotFaultClosesBreaker(t *testing.T) {
+	b, now := newTestBreaker(1, nil)
+	b.Record(errTimeout)
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the probe to be let through, got: %v", err)
+	}
+	// The backend answered, if only with a client error
+	b.Record(errInvalidParams)
+	if status := b.Status(); status.State != StateClosed {
+		t.Fatalf("Expected the breaker to close, got %s", status.State)
+	}
+}
+
+func TestUnrecordedProbeIsGivenUp(t *testing.T) {
+	b, now := newTestBreaker(1, nil)
+	b.Record(errTimeout)
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the probe to be let through, got: %v", err)
+	}
+	*now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected another probe once the first was given up, got: %v", err)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	b, _ := newTestBreaker(5, nil)
+	b.now = time.Now
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if b.Allow() == nil {
+					b.Record(errTimeout)
+				}
+				b.Status()
+			}
+		}()
+	}
+	wg.Wait()
+	if status := b.Status(); status.State != StateOpen {
+		t.Fatalf("Expected the breaker to be open, got %s", status.State)
+	}
+}
@@ -0,0 +1,138 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway"
+	"github.com/gate4ai/mcp/server/a2a"
+	serverextra "github.com/gate4ai/mcp/server/extra"
+	a2aClient "github.com/gate4ai/mcp/shared/a2a/2025-draft/client"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// echoTasks completes every task with the text of its message.
+var echoTasks = a2a.TaskProcessorFunc(func(_ context.Context, store a2a.TaskStore, scope a2a.TaskScope, task *a2aSchema.Task, message a2aSchema.Message) {
+	reply := testutil.NewTextMessage("agent", "Echo: "+testutil.TextOf(&message))
+	store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateCompleted, Message: &reply})
+})
+
+func TestGatewayServesA2ATasks(t *testing.T) {
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u").
+		WithA2AAgent("assistant", "", "").
+		Build(t)
+	gwURL := startTestGateway(t, cfg, gateway.WithA2ATaskProcessor(echoTasks))
+	baseURL := strings.TrimSuffix(gwURL, "/sse")
+	waitListening(t, baseURL+"/status")
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	discovery, err := a2aClient.New(baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	card, err := discovery.FetchAgentInfo(ctx)
+	if err != nil {
+		t.Fatalf("Fetching the agent card failed: %v", err)
+	}
+	if card.URL != baseURL+config.DefaultA2AEndpointPath {
+		t.Fatalf("Card announces endpoint %s, want %s", card.URL, baseURL+config.DefaultA2AEndpointPath)
+	}
+
+	anonymous, err := a2aClient.New(card.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := testutil.NewTaskSendParams("t0", "", "hello")
+	if _, err := anonymous.SendTask(ctx, &params); err == nil {
+		t.Fatal("Expected a task sent without a key to be refused")
+	}
+
+	agent, err := a2aClient.New(card.URL, a2aClient.WithAuth(a2aClient.BearerToken("key-u")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params = testutil.NewTaskSendParams("t1", "s1", "hello")
+	task, err := agent.SendTask(ctx, &params)
+	if err != nil {
+		t.Fatalf("Sending a task failed: %v", err)
+	}
+	testutil.AssertTaskState(t, task, a2aSchema.TaskStateCompleted)
+	if text := testutil.TextOf(task.Status.Message); text != "Echo: hello" {
+		t.Fatalf("Unexpected reply %q", text)
+	}
+	got, err := agent.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t1"})
+	if err != nil || got.Status.State != a2aSchema.TaskStateCompleted {
+		t.Fatalf("Getting the task: %+v, %v", got, err)
+	}
+
+	// The agent does not announce streaming
+	req, err := http.NewRequest(http.MethodPost, card.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tasks/sendSubscribe","params":{}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer key-u")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rejected a2aSchema.JSONRPCResponse
+	err = json.NewDecoder(resp.Body).Decode(&rejected)
+	resp.Body.Close()
+	if err != nil || rejected.Error == nil || rejected.Error.Code != a2aSchema.ErrorUnsupportedOperation {
+		t.Fatalf("Expected tasks/sendSubscribe to be unsupported, got %+v, %v", rejected, err)
+	}
+
+	// The stored tasks are reported on /status
+	resp, err = http.Get(baseURL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var status serverextra.StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.A2ATasks == nil || *status.A2ATasks != (serverextra.TaskCounts{Sessions: 1, Tasks: 1}) {
+		t.Fatalf("Expected the task in the status, got %+v", status.A2ATasks)
+	}
+}
+
+func TestGatewaySanitizesA2AText(t *testing.T) {
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u").
+		WithA2AAgent("assistant", "", "").
+		WithSanitizeText(true, true).
+		Build(t)
+	var received string
+	processor := a2a.TaskProcessorFunc(func(ctx context.Context, store a2a.TaskStore, scope a2a.TaskScope, task *a2aSchema.Task, message a2aSchema.Message) {
+		received = testutil.TextOf(&message)
+		echoTasks(ctx, store, scope, task, a2aSchema.Message{Parts: []a2aSchema.Part{testutil.NewTextPart("\x1b]0;title\x07" + received)}})
+	})
+	gwURL := startTestGateway(t, cfg, gateway.WithA2ATaskProcessor(processor))
+	baseURL := strings.TrimSuffix(gwURL, "/sse")
+	waitListening(t, baseURL+"/status")
+
+	agent, err := a2aClient.New(baseURL+config.DefaultA2AEndpointPath, a2aClient.WithAuth(a2aClient.BearerToken("key-u")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := testutil.NewTaskSendParams("t1", "", "hi\x1b[2J")
+	task, err := agent.SendTask(context.Background(), &params)
+	if err != nil {
+		t.Fatalf("Sending a task failed: %v", err)
+	}
+	if received != "hi" {
+		t.Fatalf("Processor received %q, want the sanitized message", received)
+	}
+	if text := testutil.TextOf(task.Status.Message); text != "Echo: hi" {
+		t.Fatalf("Client received %q, want the sanitized reply", text)
+	}
+}
@@ -0,0 +1,32 @@
+package capability
+
+import (
+	"fmt"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+)
+
+// checkBackendAccess returns a shared.JSONRPCErrorAccessDenied error if the user of the
+// client session may not send requests to the backend (see config.UserCanAccessBackend).
+func (c *GatewayCapability) checkBackendAccess(clientSession shared.ISession, serverID string) error {
+	userID := transport.GetUserId(clientSession.GetParams())
+	allowed, err := config.UserCanAccessBackend(c.config, userID, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to check access of user '%s' to backend '%s': %w", userID, serverID, err)
+	}
+	if !allowed {
+		return accessDenied(serverID)
+	}
+	return nil
+}
+
+// accessDenied returns the error rejecting a request to a backend the user may not use.
+func accessDenied(serverID string) error {
+	return &shared.JSONRPCError{
+		Code:    shared.JSONRPCErrorAccessDenied,
+		Message: fmt.Sprintf("Access denied: user is not subscribed to backend '%s'", serverID),
+		Data:    map[string]interface{}{"backend": serverID},
+	}
+}
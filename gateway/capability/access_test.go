@@ -0,0 +1,40 @@
+package capability_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+func TestBackendAccessControl(t *testing.T) {
+	public := newUnlistedToolBackend(t, "public")
+	internal := newUnlistedToolBackend(t, "internal")
+
+	cfg := testutil.NewConfigBuilder().
+		WithUser("alice", "key-alice", "public").
+		WithUser("admin", "key-admin", config.AllBackends).
+		WithBackend("public", public.URL()).
+		WithBackend("internal", internal.URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+
+	alice := openGatewaySession(t, gwURL, "key-alice")
+	if text, err := callUnlistedTool(t, alice, "public:report"); err != nil || text != "public/report" {
+		t.Fatalf("Subscribed backend: got %q, %v; want public/report", text, err)
+	}
+	_, err := callUnlistedTool(t, alice, "internal:report")
+	var rpcErr *shared.JSONRPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != shared.JSONRPCErrorAccessDenied {
+		t.Fatalf("Unsubscribed backend: expected an access denied error, got %v", err)
+	}
+
+	admin := openGatewaySession(t, gwURL, "key-admin")
+	for _, backendID := range []string{"public", "internal"} {
+		if text, err := callUnlistedTool(t, admin, backendID+":report"); err != nil || text != backendID+"/report" {
+			t.Fatalf("Wildcard user calling %s: got %q, %v", backendID, text, err)
+		}
+	}
+}
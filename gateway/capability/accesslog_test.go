@@ -0,0 +1,96 @@
+package capability_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// waitForEntry returns the first access log entry with the message, waiting for it to
+// be written.
+func waitForEntry(t *testing.T, logs *observer.ObservedLogs, message string) observer.LoggedEntry {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if entries := logs.FilterMessage(message).AllUntimed(); len(entries) > 0 {
+			return entries[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("No %q entry was written to the access log", message)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestAccessLogEntries(t *testing.T) {
+	fb, _ := newFlakyBackend(t, codeInitializing, 0)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "b1").
+		WithBackend("b1", fb.URL()).
+		WithAccessLog("").
+		Build(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	session := openGatewaySession(t, startTestGateway(t, cfg, gateway.WithAccessLogger(zap.New(core))), "key-u")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if result := <-session.CallTool(ctx, "flaky", map[string]interface{}{}); result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+
+	var call map[string]interface{}
+	deadline := time.Now().Add(10 * time.Second)
+	for call == nil {
+		for _, entry := range logs.FilterMessage("Request answered").AllUntimed() {
+			if fields := entry.ContextMap(); fields["method"] == "tools/call" {
+				call = fields
+			}
+		}
+		if call == nil && time.Now().After(deadline) {
+			t.Fatalf("No access log entry of tools/call")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if call["userID"] != "u" || call["code"] != int64(0) {
+		t.Errorf("Expected a successful request of user u, got %v", call)
+	}
+	if backends, ok := call["backend"].([]interface{}); !ok || len(backends) != 1 || backends[0] != "b1" {
+		t.Errorf("Expected the request to be logged with backend b1, got %v", call["backend"])
+	}
+	if bytes, ok := call["bytes"].(int64); !ok || bytes <= 0 {
+		t.Errorf("Expected the size of the response, got %v", call["bytes"])
+	}
+	if _, ok := call["duration"]; !ok {
+		t.Errorf("Expected the duration of the request, got %v", call)
+	}
+
+	session.Close()
+	stream := waitForEntry(t, logs, "SSE stream closed").ContextMap()
+	if stream["path"] != "/sse" || stream["userID"] != "u" {
+		t.Errorf("Expected the SSE stream of user u, got %v", stream)
+	}
+	// The endpoint event and at least the responses to initialize and tools/call
+	if events, ok := stream["events"].(int64); !ok || events < 3 {
+		t.Errorf("Expected the events written to the stream, got %v", stream["events"])
+	}
+}
+
+func TestAccessLogDisabledByDefault(t *testing.T) {
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u").
+		Build(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	session := openGatewaySession(t, startTestGateway(t, cfg, gateway.WithAccessLogger(zap.New(core))), "key-u")
+	session.Close()
+
+	time.Sleep(200 * time.Millisecond)
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("Expected no access log entries while it is disabled, got %d", n)
+	}
+}
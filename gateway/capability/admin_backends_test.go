@@ -0,0 +1,62 @@
+package capability_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/gateway/extra"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+func TestAdminAPIChangesBackendsOfLiveGateway(t *testing.T) {
+	static := newUnlistedToolBackend(t, "static")
+	dynamic := newUnlistedToolBackend(t, "dynamic")
+
+	cfg := testutil.NewConfigBuilder().
+		WithUser("root", "key-root").
+		WithUserParam("root", "role", config.AdminRole).
+		WithUser("alice", "key-alice", config.AllBackends).
+		WithBackend("static", static.URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+	adminURL := strings.TrimSuffix(gwURL, "/sse") + extra.BackendsPath + "/dynamic"
+
+	adminRequest := func(method string, body string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, adminURL, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer key-root")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	openGatewaySession(t, gwURL, "key-root") // Waits for the gateway to listen
+	if status := adminRequest(http.MethodPost, "url: "+dynamic.URL()); status != http.StatusCreated {
+		t.Fatalf("Adding a backend: status %d", status)
+	}
+	alice := openGatewaySession(t, gwURL, "key-alice")
+	for _, backendID := range []string{"static", "dynamic"} {
+		if text, err := callUnlistedTool(t, alice, backendID+":report"); err != nil || text != backendID+"/report" {
+			t.Fatalf("Calling %s: got %q, %v", backendID, text, err)
+		}
+	}
+
+	if status := adminRequest(http.MethodDelete, ""); status != http.StatusNoContent {
+		t.Fatalf("Removing a backend: status %d", status)
+	}
+	// The prefix no longer names a backend, so the name goes to the only one left
+	if text, err := callUnlistedTool(t, alice, "dynamic:report"); err != nil || text != "static/dynamic:report" {
+		t.Fatalf("Calling the removed backend: got %q, %v", text, err)
+	}
+	if text, err := callUnlistedTool(t, alice, "static:report"); err != nil || text != "static/report" {
+		t.Fatalf("Calling the remaining backend: got %q, %v", text, err)
+	}
+}
@@ -0,0 +1,129 @@
+package capability_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/gateway/extra"
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// adminCall sends a request to the admin API of the gateway at url and decodes the
+// JSON answer into result, if any.
+func adminCall(t *testing.T, method string, url string, key string, result any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if result != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			t.Fatalf("Invalid answer to %s %s: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// pingMCP sends a ping in the session and returns the HTTP status.
+func pingMCP(t *testing.T, mcpURL string, sessionID string) int {
+	t.Helper()
+	resp := postMCP(t, mcpURL, sessionID, "application/json", `{"jsonrpc":"2.0","id":9,"method":"ping"}`)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAdminAPIListsAndTerminatesSessions(t *testing.T) {
+	cfg := testutil.NewConfigBuilder().
+		WithUser("root", "key-root").
+		WithUserParam("root", "role", config.AdminRole).
+		WithUser("u", "key-u", "b1").
+		WithUser("u", "key-u2").
+		WithBackend("b1", newUnlistedToolBackend(t, "b1").URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+	baseURL := strings.TrimSuffix(gwURL, "/sse")
+	mcpURL := baseURL + "/mcp"
+	waitListening(t, baseURL+"/status")
+
+	first := initializeMCP(t, mcpURL)
+	second := initializeMCP(t, mcpURL)
+	resp := postMCP(t, mcpURL, first, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"report","arguments":{}}}`)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	sse := openGatewaySession(t, gwURL, "key-u")
+	if _, err := callUnlistedTool(t, sse, "report"); err != nil {
+		t.Fatalf("Calling through the SSE session failed: %v", err)
+	}
+
+	if status := adminCall(t, http.MethodGet, baseURL+extra.SessionsPath, "key-u", nil); status != http.StatusForbidden {
+		t.Fatalf("Listing sessions as a user: status %d, want 403", status)
+	}
+	var listed struct {
+		Sessions []extra.SessionInfo `json:"sessions"`
+	}
+	if status := adminCall(t, http.MethodGet, baseURL+extra.SessionsPath, "key-root", &listed); status != http.StatusOK {
+		t.Fatalf("Listing sessions: status %d", status)
+	}
+	transports := make(map[string]int)
+	for _, session := range listed.Sessions {
+		if session.UserID != "u" || session.ConnectedAt.IsZero() {
+			t.Fatalf("Unexpected session %+v", session)
+		}
+		if session.ID == first && !slices.Equal(session.Backends, []string{"b1"}) {
+			t.Fatalf("Expected the session connected to b1, got %v", session.Backends)
+		}
+		transports[session.Transport]++
+	}
+	if len(listed.Sessions) != 3 || transports[transport.TransportStreamableHTTP] != 2 || transports[transport.TransportSSE] != 1 {
+		t.Fatalf("Expected 2 streamable HTTP sessions and 1 SSE session, got %+v", listed.Sessions)
+	}
+
+	// A terminated session is gone, the others are left
+	if status := adminCall(t, http.MethodDelete, baseURL+extra.SessionsPath+"/"+first, "key-root", nil); status != http.StatusNoContent {
+		t.Fatalf("Terminating a session: status %d", status)
+	}
+	if status := pingMCP(t, mcpURL, first); status != http.StatusNotFound {
+		t.Fatalf("Request in a terminated session: status %d, want 404", status)
+	}
+	if status := adminCall(t, http.MethodDelete, baseURL+extra.SessionsPath+"/"+first, "key-root", nil); status != http.StatusNotFound {
+		t.Fatalf("Terminating a terminated session: status %d, want 404", status)
+	}
+	if status := pingMCP(t, mcpURL, second); status != http.StatusOK {
+		t.Fatalf("Request in another session: status %d", status)
+	}
+
+	// Revoking a key keeps the sessions unless asked to terminate them
+	var revoked extra.RevokedKeyInfo
+	keysURL := baseURL + extra.KeysPath + "/"
+	if status := adminCall(t, http.MethodDelete, keysURL+config.HashAPIKey("key-u2"), "key-root", &revoked); status != http.StatusOK || revoked != (extra.RevokedKeyInfo{UserID: "u"}) {
+		t.Fatalf("Revoking a key: status %d, %+v", status, revoked)
+	}
+	if status := pingMCP(t, mcpURL, second); status != http.StatusOK {
+		t.Fatalf("Request in a session of the user after revoking a key: status %d", status)
+	}
+	if status := adminCall(t, http.MethodDelete, keysURL+config.HashAPIKey("key-u")+"?terminate_sessions=true", "key-root", &revoked); status != http.StatusOK || revoked != (extra.RevokedKeyInfo{UserID: "u", TerminatedSessions: 2}) {
+		t.Fatalf("Revoking a key with its sessions: status %d, %+v", status, revoked)
+	}
+	if status := pingMCP(t, mcpURL, second); status != http.StatusNotFound {
+		t.Fatalf("Request in a session of the revoked key: status %d, want 404", status)
+	}
+	if status := pingMCP(t, mcpURL, ""); status != http.StatusUnauthorized {
+		t.Fatalf("New session with the revoked key: status %d, want 401", status)
+	}
+	if status := adminCall(t, http.MethodDelete, keysURL+config.HashAPIKey("key-u"), "key-root", nil); status != http.StatusNotFound {
+		t.Fatalf("Revoking a revoked key: status %d, want 404", status)
+	}
+}
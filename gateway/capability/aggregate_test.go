@@ -0,0 +1,72 @@
+package capability_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newFailingListsBackend returns a backend answering every list request with an error.
+func newFailingListsBackend(t *testing.T) *fakeBackend {
+	fb := newFakeBackend(t)
+	for _, method := range []string{"tools/list", "prompts/list", "resources/list"} {
+		fb.Handle(method, func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+			return nil, &shared.JSONRPCError{Code: shared.JSONRPCErrorInternal, Message: "store unavailable"}
+		})
+	}
+	return fb
+}
+
+func TestListsAggregateHealthyBackends(t *testing.T) {
+	healthy := newSearchBackend(t, "weather")
+	healthy.Handle("resources/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"resources":[{"uri":"file:///forecast","name":"forecast"}]}`), nil
+	})
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "weather", "broken").
+		WithBackend("weather", healthy.URL()).
+		WithBackend("broken", newFailingListsBackend(t).URL()).
+		WithBackendAggregatePagination("broken"). // Its tools are listed like its prompts and resources
+		WithPrefixedNames("").
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	for _, tc := range []struct {
+		method string
+		field  string // Of the items in the result
+		want   []string
+	}{
+		{"tools/list", "tools", []string{"weather.search"}},
+		{"prompts/list", "prompts", []string{"weather.brief"}},
+		{"resources/list", "resources", []string{"weather.forecast"}},
+	} {
+		t.Run(tc.method, func(t *testing.T) {
+			result := callRaw(t, session, tc.method, map[string]interface{}{})
+			if result.Error != nil {
+				t.Fatalf("%s failed although a backend is healthy: %v", tc.method, result.Error)
+			}
+			var decoded map[string]json.RawMessage
+			var items []struct {
+				Name string `json:"name"`
+			}
+			var meta map[string][]string
+			if json.Unmarshal(result.Result, &decoded) != nil || json.Unmarshal(decoded[tc.field], &items) != nil || json.Unmarshal(decoded["_meta"], &meta) != nil {
+				t.Fatalf("Invalid %s result: %s", tc.method, result.Result)
+			}
+			var names []string
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			if !slices.Equal(names, tc.want) {
+				t.Fatalf("Listed %v, want %v", names, tc.want)
+			}
+			if degraded := meta["gate4ai/degradedBackends"]; fmt.Sprint(degraded) != "[broken]" {
+				t.Fatalf("Expected the failed backend in _meta, got %v", degraded)
+			}
+		})
+	}
+}
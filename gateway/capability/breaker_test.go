@@ -0,0 +1,127 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// callFailing calls a backend failing with code n times through a gateway whose breaker
+// opens after 2 faults, and returns the number of calls the backend received.
+func callFailing(t *testing.T, code int, n int) (int32, error) {
+	t.Helper()
+	fb, calls := newFlakyBackend(t, code, 100)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "flaky-backend").
+		WithBackend("flaky-backend", fb.URL()).
+		WithBackendBreaker("flaky-backend", 2, "1m").
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	var err error
+	for i := 0; i < n; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = (<-session.CallTool(ctx, "flaky", map[string]interface{}{})).Error
+		cancel()
+		if err == nil {
+			t.Fatalf("Expected call %d to fail", i+1)
+		}
+	}
+	return calls.Load(), err
+}
+
+func TestBreakerIgnoresClientErrors(t *testing.T) {
+	calls, _ := callFailing(t, -32602, 5) // Invalid params
+	if calls != 5 {
+		t.Fatalf("Expected every call to reach the backend, got %d of 5", calls)
+	}
+}
+
+func TestBreakerOpensOnServerErrors(t *testing.T) {
+	calls, err := callFailing(t, -32603, 5) // Internal error
+	if calls != 2 {
+		t.Fatalf("Expected the breaker to stop calls after 2 faults, backend got %d", calls)
+	}
+	if !strings.Contains(err.Error(), "circuit breaker is open") {
+		t.Fatalf("Expected an open breaker error, got: %v", err)
+	}
+}
+
+// circuitBreakers returns the circuit breakers reported by the status endpoint of the gateway.
+func circuitBreakers(t *testing.T, gwURL string) map[string]struct {
+	State     string `json:"state"`
+	Failures  int    `json:"failures"`
+	RetryInMs int64  `json:"retry_in_ms"`
+} {
+	t.Helper()
+	resp, err := http.Get(strings.TrimSuffix(gwURL, "/sse") + "/status")
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status struct {
+		CircuitBreakers map[string]struct {
+			State     string `json:"state"`
+			Failures  int    `json:"failures"`
+			RetryInMs int64  `json:"retry_in_ms"`
+		} `json:"circuit_breakers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	return status.CircuitBreakers
+}
+
+func TestBreakerStatesThroughGateway(t *testing.T) {
+	fb, calls := newFlakyBackend(t, -32603, 2) // Internal errors, then recovered
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "flaky-backend").
+		WithBackend("flaky-backend", fb.URL()).
+		WithBackendBreaker("flaky-backend", 2, "500ms").
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+	session := openGatewaySession(t, gwURL, "key-u")
+	call := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return (<-session.CallTool(ctx, "flaky", map[string]interface{}{})).Error
+	}
+
+	// Closed: faults reach the backend until the threshold
+	for i := 0; i < 2; i++ {
+		if err := call(); err == nil {
+			t.Fatalf("Expected call %d to fail", i+1)
+		}
+	}
+	if state := circuitBreakers(t, gwURL)["flaky-backend"]; state.State != "open" || state.Failures != 2 || state.RetryInMs <= 0 {
+		t.Fatalf("Expected the breaker to be open after 2 faults, got %+v", state)
+	}
+
+	// Open: requests fail fast without reaching the backend
+	var rpcErr *shared.JSONRPCError
+	if err := call(); !errors.As(err, &rpcErr) || rpcErr.Code != shared.JSONRPCErrorUnavailable {
+		t.Fatalf("Expected a backend unavailable error, got: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("Expected the open breaker to stop calls, backend got %d", calls.Load())
+	}
+
+	// Half-open once the open duration has passed; the probe succeeds and closes it
+	time.Sleep(600 * time.Millisecond)
+	if state := circuitBreakers(t, gwURL)["flaky-backend"]; state.State != "half_open" {
+		t.Fatalf("Expected the breaker to be half-open, got %+v", state)
+	}
+	if err := call(); err != nil {
+		t.Fatalf("Expected the probe to reach the recovered backend, got: %v", err)
+	}
+	if state := circuitBreakers(t, gwURL)["flaky-backend"]; state.State != "closed" || state.Failures != 0 {
+		t.Fatalf("Expected the breaker to be closed after the probe, got %+v", state)
+	}
+}
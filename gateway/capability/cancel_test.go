@@ -0,0 +1,115 @@
+package capability_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server"
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/gate4ai/mcp/tests"
+	"go.uber.org/zap"
+)
+
+// startHangingServer starts an MCP server with a "hang" tool running until its request
+// is cancelled, and returns its SSE URL. started receives when a call starts and
+// cancelled when the backend sees its context cancelled.
+func startHangingServer(t *testing.T) (url string, started, cancelled chan struct{}) {
+	t.Helper()
+	port, err := tests.FindAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to find available port: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cfg := testutil.NewConfigBuilder().WithAuthorization("none").Build(t)
+	tools, _, _, _, err := server.StartServer(ctx, LOGGER.With(zap.String("s", t.Name()+"-server")), cfg, fmt.Sprintf(":%d", port))
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	started = make(chan struct{}, 1)
+	cancelled = make(chan struct{}, 1)
+	err = tools.AddTool("hang", "Runs until cancelled", &schema.JSONSchemaProperty{Type: "object"}, nil,
+		func(msg *shared.Message, args schema.Arguments) (*schema.Meta, []schema.Content, error) {
+			started <- struct{}{}
+			select {
+			case <-msg.Context().Done():
+				cancelled <- struct{}{}
+				return nil, nil, msg.Context().Err()
+			case <-time.After(10 * time.Second):
+				return nil, schema.NewTextContent("not cancelled"), nil
+			}
+		})
+	if err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+	return fmt.Sprintf("http://localhost:%d/sse", port), started, cancelled
+}
+
+func TestCancellationReachesBackend(t *testing.T) {
+	for _, passthrough := range []bool{false, true} {
+		for _, how := range []string{"disconnect", "notification", "notification with allow list"} {
+			t.Run(fmt.Sprintf("passthrough=%v/%s", passthrough, how), func(t *testing.T) {
+				backendURL, started, cancelled := startHangingServer(t)
+				builder := testutil.NewConfigBuilder().
+					WithUser("u", "key-u", "hanging").
+					WithBackend("hanging", backendURL)
+				if passthrough {
+					builder.WithBackendPassthrough("hanging")
+				}
+				if how == "notification with allow list" {
+					builder.WithMethodsAllow("tools/*") // Notifications are accepted all the same
+				}
+				mcpURL := strings.TrimSuffix(startTestGateway(t, builder.Build(t)), "/sse") + "/mcp"
+				waitListening(t, strings.TrimSuffix(mcpURL, "/mcp")+"/status")
+				sessionID := initializeMCP(t, mcpURL)
+
+				ctx, disconnect := context.WithCancel(context.Background())
+				defer disconnect()
+				req, err := http.NewRequestWithContext(ctx, http.MethodPost, mcpURL,
+					bytes.NewBufferString(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"hang","arguments":{}}}`))
+				if err != nil {
+					t.Fatalf("Failed to create request: %v", err)
+				}
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Accept", "application/json, text/event-stream")
+				req.Header.Set("Authorization", "Bearer key-u")
+				req.Header.Set("Mcp-Session-Id", sessionID)
+				go func() {
+					// Reads the stream until it ends, closing it early would cancel the call
+					if resp, err := http.DefaultClient.Do(req); err == nil {
+						_, _ = io.Copy(io.Discard, resp.Body)
+						resp.Body.Close()
+					}
+				}()
+
+				select {
+				case <-started:
+				case <-time.After(5 * time.Second):
+					t.Fatal("The tool call did not reach the backend")
+				}
+				if how == "disconnect" {
+					disconnect()
+				} else {
+					resp := postMCP(t, mcpURL, sessionID, "application/json",
+						`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"user gave up"}}`)
+					resp.Body.Close()
+				}
+
+				select {
+				case <-cancelled:
+				case <-time.After(5 * time.Second):
+					t.Fatal("The backend did not see the call cancelled")
+				}
+			})
+		}
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/gate4ai/mcp/gateway/adapter"
 	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/gateway/metrics"
 	"github.com/gate4ai/mcp/server/mcp"
 	"github.com/gate4ai/mcp/server/transport"
 	"github.com/gate4ai/mcp/shared"
 	"github.com/gate4ai/mcp/shared/config"
 	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema" // Use 2025 schema
+	"github.com/gate4ai/mcp/shared/tracing"
 	"go.uber.org/zap"
 )
 
@@ -37,12 +40,37 @@ type GatewayCapability struct {
 	refreshRate  time.Duration
 	userSessions map[string]*mcp.Session // UserID -> mcp session
 	config       config.IConfig
+	adapters     *adapter.Registry // Protocol version adapters for relayed messages
+	metrics      *metrics.Registry // Latency and results of backend requests
+
+	breakersMu sync.Mutex
+	breakers   map[string]*backendBreaker // serverID -> circuit breaker, created on first use
+
+	handshakesMu sync.Mutex
+	handshakes   map[string]*handshakeFailure // serverID -> failed handshake, until a retry succeeds
+
+	listCachesMu sync.Mutex
+	listCaches   map[string]*backendListCache // serverID -> cached list answers, for backends caching them
+
+	healthMu sync.Mutex
+	health   map[string]*HealthState // serverID -> result of the health checks, once checked
+
+	replicasMu sync.Mutex
+	replicas   map[string]*replicaSet // serverID -> replica state, for backends with replicas
+
+	transportsMu sync.Mutex
+	transports   map[string]*backendTransport // serverID -> HTTP client of the backend
 }
 
 // NewGatewayCapability creates a new gateway capability
 func NewGatewayCapability(logger *zap.Logger, cfg config.IConfig) *GatewayCapability {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	buckets, err := cfg.MetricsLatencyBuckets()
+	if err != nil {
+		logger.Error("Failed to get metrics latency buckets from config, using defaults", zap.Error(err))
+	}
+
 	cap := &GatewayCapability{
 		logger:       logger,
 		ctx:          ctx,
@@ -50,10 +78,23 @@ func NewGatewayCapability(logger *zap.Logger, cfg config.IConfig) *GatewayCapabi
 		refreshRate:  5 * time.Minute, // Default refresh rate
 		userSessions: make(map[string]*mcp.Session),
 		config:       cfg,
+		adapters:     adapter.Default,
+		metrics:      metrics.NewRegistry(buckets),
+		breakers:     make(map[string]*backendBreaker),
+		handshakes:   make(map[string]*handshakeFailure),
+		listCaches:   make(map[string]*backendListCache),
+		health:       make(map[string]*HealthState),
+		replicas:     make(map[string]*replicaSet),
+		transports:   make(map[string]*backendTransport),
 	}
 	return cap
 }
 
+// Metrics returns the registry of backend request metrics.
+func (c *GatewayCapability) Metrics() *metrics.Registry {
+	return c.metrics
+}
+
 func (c *GatewayCapability) GetHandlers() map[string]func(*shared.Message) (interface{}, error) {
 	handlers := make(map[string]func(*shared.Message) (interface{}, error))
 
@@ -71,7 +112,8 @@ func (c *GatewayCapability) GetHandlers() map[string]func(*shared.Message) (inte
 	return handlers
 }
 
-// newBackendSession creates a new backend session for the given server
+// newBackendSession creates a new backend session for the given server, connecting to
+// the replica chosen for the client session if the server has replicas
 func (c *GatewayCapability) newBackendSession(serverID string, clientSession shared.ISession, logger *zap.Logger) *client.Session {
 	// Get the backend server by ID
 	backend, err := c.config.GetBackend(serverID)
@@ -80,22 +122,67 @@ func (c *GatewayCapability) newBackendSession(serverID string, clientSession sha
 		return nil
 	}
 
-	backendServer, err := client.New(serverID, backend.URL, logger)
+	return c.newBackendSessionTo(serverID, c.pickReplica(clientSession, serverID, backend), backend, clientSession, logger)
+}
+
+// backendTimeout returns the timeout of a request to the backend: its configured Timeout,
+// or def, the default of the request's method.
+func (c *GatewayCapability) backendTimeout(serverID string, def time.Duration) time.Duration {
+	backend, err := c.config.GetBackend(serverID)
+	if err != nil || backend == nil || backend.Timeout <= 0 {
+		return def
+	}
+	return backend.Timeout
+}
+
+// startBackendCall records that the client request handled with ctx is sent to the
+// backend, for the access log, and starts the span of the request to the backend as a
+// child of the span carried by ctx. It returns a context carrying the span, which passes
+// it on to the backend.
+func startBackendCall(ctx context.Context, method string, serverID string) (context.Context, *tracing.Span) {
+	shared.RecordBackend(ctx, serverID)
+	span := tracing.SpanFromContext(ctx).StartChild(method, tracing.KindClient)
+	span.SetAttribute(tracing.AttrMethod, method)
+	span.SetAttribute(tracing.AttrBackend, serverID)
+	return tracing.ContextWithSpan(ctx, span), span
+}
+
+// newBackendClient returns the client of the backend connecting to url, or running its
+// command if it is a stdio backend
+func newBackendClient(serverID string, url string, backend *config.Backend, logger *zap.Logger) (*client.Backend, error) {
+	if len(backend.Command) > 0 {
+		return client.NewStdio(serverID, client.StdioCommand{Args: backend.Command, Env: backend.Env, Dir: backend.Dir}, logger)
+	}
+	return client.New(serverID, url, logger)
+}
+
+// newBackendSessionTo creates a new backend session for the given server connecting to url
+func (c *GatewayCapability) newBackendSessionTo(serverID string, url string, backend *config.Backend, clientSession shared.ISession, logger *zap.Logger) *client.Session {
+	backendServer, err := newBackendClient(serverID, url, backend, logger)
 	if err != nil {
 		logger.Error("Failed to create backend client", zap.String("server", serverID), zap.Error(err))
 		return nil
 	}
 
-	newBackendSession := backendServer.NewSession(c.ctx, http.DefaultClient, backend.Bearer)
+	newBackendSession := backendServer.NewSession(c.ctx, c.backendHTTPClient(serverID, backend), backend.Bearer)
 	SaveServerID(newBackendSession.GetParams(), serverID)                          // Use GetParams()
 	SaveClientSession(newBackendSession.GetParams(), clientSession.(*mcp.Session)) // Use GetParams()
 	newBackendSession.SubscribeOnResourceUpdated(c.gw_resources_notification_updated)
+	newBackendSession.SubscribeOnListChanged(c.backendListChanged)
+	c.restoreSubscriptions(clientSession, newBackendSession)
 
 	return newBackendSession
 }
 
-// getBackendSession returns an existing backend session for the given server or creates a new one
+// getBackendSession returns an existing backend session for the given server or creates a
+// new one. Servers the user may not access are rejected with an access denied error.
 func (c *GatewayCapability) getBackendSession(clientSession shared.ISession, serverID string) (*client.Session, error) {
+	if err := c.checkBackendAccess(clientSession, serverID); err != nil {
+		return nil, err
+	}
+	if err := c.checkHandshake(serverID); err != nil {
+		return nil, err
+	}
 	backendSessions, err := c.getBackendSessions(clientSession)
 	if err != nil {
 		// Wrap error for better context
@@ -105,6 +192,13 @@ func (c *GatewayCapability) getBackendSession(clientSession shared.ISession, ser
 	// Look for an existing session for the requested server
 	for _, session := range backendSessions {
 		if session != nil && session.Backend != nil && session.Backend.ID == serverID {
+			if backend, err := c.config.GetBackend(serverID); err == nil && len(backend.Replicas) > 0 {
+				return c.replicaSession(clientSession, session, backend)
+			}
+			if err := session.HandshakeError(); err != nil {
+				c.recordHandshakeError(serverID, err)
+				return nil, c.checkHandshake(serverID)
+			}
 			return session, nil
 		}
 	}
@@ -119,7 +213,7 @@ func (c *GatewayCapability) getBackendSessions(clientSession shared.ISession) ([
 
 	// Check if we already have backend sessions cached
 	backendSessions, timestamp, found := LoadBackendSessions(params)
-	if found && time.Since(timestamp) < defaultCacheExpiration {
+	if found && time.Since(timestamp) < defaultCacheExpiration && !c.hasRecoveredSession(backendSessions) && !c.hasFailedReplica(backendSessions) {
 		// Filter out nil sessions from cache before returning
 		validSessions := make([]*client.Session, 0, len(backendSessions))
 		for _, s := range backendSessions {
@@ -146,7 +240,7 @@ func (c *GatewayCapability) getBackendSessions(clientSession shared.ISession) ([
 		logger.Warn("User ID not found in client session params, cannot get subscriptions")
 		return nil, fmt.Errorf("user ID not found in session")
 	}
-	userServers, err := c.config.GetUserSubscribes(userID)
+	userServers, err := config.UserBackends(c.config, userID)
 	if err != nil {
 		err = fmt.Errorf("failed to get user server subscriptions for user '%s': %w", userID, err)
 		logger.Error(err.Error(), zap.Error(err))
@@ -164,7 +258,7 @@ func (c *GatewayCapability) getBackendSessions(clientSession shared.ISession) ([
 		go func(sID string) {
 			defer wg.Done()
 			var sess *client.Session
-			if session, exists := existingSessions[sID]; exists && session != nil { // Check if session exists and is not nil
+			if session, exists := existingSessions[sID]; exists && session != nil && !c.recovered(session) && !c.replicaFailed(session) { // Check if session exists and is not nil
 				// TODO: Add a check here to see if the existing session is still valid/connected
 				// If not valid, create a new one instead of reusing.
 				sess = session
@@ -173,7 +267,7 @@ func (c *GatewayCapability) getBackendSessions(clientSession shared.ISession) ([
 			} else {
 				logger.Debug("Creating new backend session", zap.String("serverID", sID))
 				sess = c.newBackendSession(sID, clientSession, logger.With(zap.String("serverID", sID)))
-				// No need to call Open() here, fetchAndCombineFromBackends will handle it
+				// No need to call Open() here, fetchAndCombineFromBackendsWithin will handle it
 			}
 			if sess != nil { // Only send non-nil sessions to the channel
 				sessionChan <- sess
@@ -212,81 +306,112 @@ func (c *GatewayCapability) getBackendSessions(clientSession shared.ISession) ([
 	return currentBackendSessions, nil
 }
 
-func fetchAndCombineFromBackends[T any](
+// fetchAndCombineFromBackendsWithin fetches items from all backends of the client session
+// and prefixes keys shared by several backends with the backend ID. If deadline is
+// positive, it stops waiting for backends once it passes and combines what has arrived.
+// A failing backend does not fail the others: it returns the IDs of the backends whose
+// items are missing (failed or too slow).
+func fetchAndCombineFromBackendsWithin[T any](
 	c *GatewayCapability,
 	ctx context.Context,
 	clientSession shared.ISession,
+	deadline time.Duration,
+	method string,
 	fetchFunc func(context.Context, *client.Session) ([]T, error),
 	getKeyFunc func(T) string,
 	modifyKeyFunc func(T, string) T, // Takes original item and serverID
-) ([]T, error) {
+) ([]T, []string, error) {
 	logger := c.logger
 
 	backendSessions, err := c.getBackendSessions(clientSession)
 	if err != nil {
 		logger.Error("Failed to get backend sessions", zap.Error(err))
-		return nil, fmt.Errorf("failed to get backend sessions: %w", err)
+		return nil, nil, fmt.Errorf("failed to get backend sessions: %w", err)
 	}
 
 	logger.Debug("Fetching data from backend sessions", zap.Int("count", len(backendSessions)))
 
-	resultsChan := make(chan struct {
+	type backendResult struct {
 		items    []T
 		serverID string
 		err      error
-	}, len(backendSessions))
-	var wg sync.WaitGroup
+	}
+	// Buffered for every backend, so fetches finishing after the deadline never block
+	resultsChan := make(chan backendResult, len(backendSessions))
+	pending := make(map[string]bool) // serverIDs still expected
 
 	for _, session := range backendSessions {
 		if session == nil { // Skip nil sessions
 			logger.Warn("Skipping nil backend session")
 			continue
 		}
-		wg.Add(1)
-		go func(s *client.Session) {
-			defer wg.Done()
-			serverID := "unknown"
-			if s != nil && s.Backend != nil {
-				serverID = s.Backend.ID
-			}
-
+		serverID := "unknown"
+		if session.Backend != nil {
+			serverID = session.Backend.ID
+		}
+		pending[serverID] = true
+		go func(s *client.Session, serverID string) {
 			// Ensure session is open before fetching
 			initErr := <-s.Open() // Wait for initialization or failure
+			if initErr == nil {
+				initErr = s.HandshakeError() // Failed while another caller waited for it
+			}
 			if initErr != nil {
+				// A failing replica is skipped, only a backend without replicas is unhealthy as a whole
+				if !c.markReplicaDown(s) {
+					c.recordHandshakeError(serverID, initErr)
+				}
 				logger.Error("Backend session failed to initialize", zap.String("server", serverID), zap.Error(initErr))
-				resultsChan <- struct {
-					items    []T
-					serverID string
-					err      error
-				}{nil, serverID, fmt.Errorf("session init failed: %w", initErr)}
+				resultsChan <- backendResult{nil, serverID, fmt.Errorf("session init failed: %w", initErr)}
 				return
 			}
 
 			// Use a derived context with the overall timeout for the fetch operation
-			fetchCtx, cancel := context.WithTimeout(ctx, 10*time.Second) // Example: 10-second timeout per backend fetch
+			fetchCtx, cancel := context.WithTimeout(ctx, c.backendTimeout(serverID, config.DefaultBackendRequestTimeout))
 			defer cancel()
+			fetchCtx, span := startBackendCall(fetchCtx, method, serverID)
 
 			// Fetch data from this backend
+			start := time.Now()
 			items, fetchErr := fetchFunc(fetchCtx, s)
-			resultsChan <- struct {
-				items    []T
-				serverID string
-				err      error
-			}{items, serverID, fetchErr}
-
-		}(session)
+			span.End(fetchErr)
+			c.metrics.ObserveBackendRequest(method, serverID, time.Since(start), fetchErr)
+			c.recordReplicaResult(s, fetchErr)
+			resultsChan <- backendResult{items, serverID, fetchErr}
+		}(session, serverID)
 	}
 
-	wg.Wait()
-	close(resultsChan)
+	var deadlineC <-chan time.Time
+	if deadline > 0 {
+		timer := time.NewTimer(deadline)
+		defer timer.Stop()
+		deadlineC = timer.C
+	}
+	results := make([]backendResult, 0, len(pending))
+	degraded := make([]string, 0)
+collect:
+	for len(pending) > 0 {
+		select {
+		case result := <-resultsChan:
+			delete(pending, result.serverID)
+			results = append(results, result)
+		case <-deadlineC:
+			for serverID := range pending {
+				logger.Warn("Backend did not respond before the deadline", zap.String("server", serverID), zap.Duration("deadline", deadline))
+				degraded = append(degraded, serverID)
+			}
+			break collect
+		}
+	}
 
 	allItems := make([]T, 0)
 	keyToServer := make(map[string][]string) // Map key -> list of serverIDs that have this key
 
-	for result := range resultsChan {
+	for _, result := range results {
 		if result.err != nil {
 			// Log errors but potentially continue to combine results from other backends
 			logger.Error("Failed to get data from backend", zap.String("server", result.serverID), zap.Error(result.err))
+			degraded = append(degraded, result.serverID)
 			continue // Skip results from failed backends
 		}
 		if result.items == nil {
@@ -298,6 +423,7 @@ func fetchAndCombineFromBackends[T any](
 			allItems = append(allItems, item)
 		}
 	}
+	sort.Strings(degraded)
 
 	// Modify keys for duplicates
 	modifiedItems := make([]T, 0, len(allItems))
@@ -328,7 +454,7 @@ func fetchAndCombineFromBackends[T any](
 	}
 
 	logger.Debug("Finished fetching and combining data", zap.Int("totalItems", len(modifiedItems)))
-	return modifiedItems, nil
+	return modifiedItems, degraded, nil
 }
 
 // findBackendSessionForResourceURI finds the backend session and resource for a given URI
@@ -362,11 +488,18 @@ func (c *GatewayCapability) findBackendSessionForResourceURI(inputMsg *shared.Me
 	}
 
 	if targetResource == nil {
-		logger.Error("Resource not found", zap.String("uri", params.URI))
-		return nil, nil, fmt.Errorf("resource not found: %s", params.URI)
+		serverID, originalURI, err := c.routeUntargeted(inputMsg.Session, "resource", params.URI, logger)
+		if err != nil {
+			logger.Error("Resource not found", zap.String("uri", params.URI), zap.Error(err))
+			return nil, nil, err
+		}
+		targetResource = &resourceWithServerInfo{Resource: schema.Resource{URI: params.URI}, serverID: serverID, originalURI: originalURI}
 	}
 
 	backendSession, err := c.getBackendSession(inputMsg.Session, targetResource.serverID)
+	if rpcErr, ok := err.(*shared.JSONRPCError); ok {
+		return nil, nil, rpcErr // Access denied, sent to the client as is
+	}
 	if err != nil {
 		// Provide more context in the error message
 		return nil, nil, fmt.Errorf("failed to get backend session for server '%s' (resource URI '%s'): %w", targetResource.serverID, params.URI, err)
@@ -0,0 +1,255 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway"
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	schema2024 "github.com/gate4ai/mcp/shared/mcp/2024/schema"
+	"github.com/gate4ai/mcp/tests"
+	"go.uber.org/zap"
+)
+
+// fakeMethodHandler returns the raw JSON result for a request or a JSON-RPC error.
+type fakeMethodHandler func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError)
+
+// fakeBackend is a minimal V2024 (SSE + POST) MCP server whose responses are
+// written byte for byte as returned by the registered handlers.
+type fakeBackend struct {
+	Server   *httptest.Server
+	mu       sync.Mutex
+	handlers map[string]fakeMethodHandler
+	streams  map[string]chan []byte // sessionID -> SSE event data
+	nextID   int
+	notified []string               // Methods of the notifications received
+	headers  map[string]http.Header // Method -> headers of its last request
+
+	conns  map[net.Conn]http.ConnState // Client connections not closed yet
+	opened int
+}
+
+func newFakeBackend(t *testing.T) *fakeBackend {
+	t.Helper()
+	fb := &fakeBackend{
+		handlers: make(map[string]fakeMethodHandler),
+		streams:  make(map[string]chan []byte),
+		headers:  make(map[string]http.Header),
+		conns:    make(map[net.Conn]http.ConnState),
+	}
+	fb.Handle("initialize", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"protocolVersion":"` + schema2024.PROTOCOL_VERSION + `","capabilities":{"tools":{},"prompts":{},"resources":{}},"serverInfo":{"name":"fake","version":"0.0.1"}}`), nil
+	})
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[]}`), nil
+	})
+	fb.Handle("prompts/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"prompts":[]}`), nil
+	})
+	fb.Handle("resources/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"resources":[]}`), nil
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", fb.handleSSE)
+	mux.HandleFunc("/message", fb.handleMessage)
+	fb.Server = httptest.NewUnstartedServer(mux)
+	fb.Server.Config.ConnState = fb.trackConn
+	fb.Server.Start()
+	t.Cleanup(func() {
+		fb.Server.CloseClientConnections()
+		fb.Server.Close()
+	})
+	return fb
+}
+
+// Handle registers (or replaces) the handler for a method.
+func (fb *fakeBackend) Handle(method string, handler fakeMethodHandler) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	fb.handlers[method] = handler
+}
+
+// trackConn records the state of client connections.
+func (fb *fakeBackend) trackConn(conn net.Conn, state http.ConnState) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	switch state {
+	case http.StateNew:
+		fb.opened++
+		fb.conns[conn] = state
+	case http.StateClosed, http.StateHijacked:
+		delete(fb.conns, conn)
+	default:
+		fb.conns[conn] = state
+	}
+}
+
+// Conns returns the number of client connections opened so far and of those idle now.
+func (fb *fakeBackend) Conns() (opened int, idle int) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	for _, state := range fb.conns {
+		if state == http.StateIdle {
+			idle++
+		}
+	}
+	return fb.opened, idle
+}
+
+// Notified returns the number of notifications of the method received so far.
+func (fb *fakeBackend) Notified(method string) int {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	n := 0
+	for _, m := range fb.notified {
+		if m == method {
+			n++
+		}
+	}
+	return n
+}
+
+// Notify sends a notification without params to every open session.
+func (fb *fakeBackend) Notify(method string) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	for _, events := range fb.streams {
+		events <- []byte(`{"jsonrpc":"2.0","method":"` + method + `"}`)
+	}
+}
+
+// NotifyWithParams sends a notification with the raw JSON params to every open session.
+func (fb *fakeBackend) NotifyWithParams(method string, params string) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	for _, events := range fb.streams {
+		events <- []byte(`{"jsonrpc":"2.0","method":"` + method + `","params":` + params + `}`)
+	}
+}
+
+// Header returns the headers of the last request of method.
+func (fb *fakeBackend) Header(method string) http.Header {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	return fb.headers[method]
+}
+
+// URL returns the SSE endpoint of the backend.
+func (fb *fakeBackend) URL() string {
+	return fb.Server.URL + "/sse"
+}
+
+func (fb *fakeBackend) handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher := w.(http.Flusher)
+	fb.mu.Lock()
+	fb.nextID++
+	sessionID := strconv.Itoa(fb.nextID)
+	events := make(chan []byte, 100)
+	fb.streams[sessionID] = events
+	fb.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "event: endpoint\ndata: /message?session_id=%s\n\n", sessionID)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-events:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+func (fb *fakeBackend) handleMessage(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fb.mu.Lock()
+	events := fb.streams[r.URL.Query().Get("session_id")]
+	handler := fb.handlers[req.Method]
+	fb.headers[req.Method] = r.Header.Clone()
+	fb.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+	if len(req.ID) == 0 {
+		fb.mu.Lock()
+		fb.notified = append(fb.notified, req.Method)
+		fb.mu.Unlock()
+	}
+	if len(req.ID) == 0 || events == nil {
+		return // Notification or unknown session
+	}
+
+	go func() {
+		var data string
+		switch {
+		case handler == nil:
+			data = `{"jsonrpc":"2.0","id":` + string(req.ID) + `,"error":{"code":-32601,"message":"Method not found"}}`
+		default:
+			result, rpcErr := handler(req.Params)
+			if rpcErr != nil {
+				errBytes, _ := json.Marshal(rpcErr)
+				data = `{"jsonrpc":"2.0","id":` + string(req.ID) + `,"error":` + string(errBytes) + `}`
+			} else {
+				data = `{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + string(result) + `}`
+			}
+		}
+		events <- []byte(data)
+	}()
+}
+
+// startTestGateway starts a gateway with the given config and returns its V2024 SSE URL.
+func startTestGateway(t *testing.T, cfg config.IConfig, options ...gateway.NodeOption) string {
+	t.Helper()
+	port, err := tests.FindAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to find available port: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if _, err := gateway.Start(ctx, LOGGER.With(zap.String("s", t.Name())), cfg, fmt.Sprintf(":%d", port), options...); err != nil {
+		t.Fatalf("Failed to start gateway: %v", err)
+	}
+	return "http://localhost:" + strconv.Itoa(port) + "/sse"
+}
+
+// openGatewaySession connects an MCP client session to the gateway.
+func openGatewaySession(t *testing.T, gwURL string, key string) *client.Session {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	t.Cleanup(cancel)
+	c, err := client.New(gwURL, gwURL, LOGGER.With(zap.String("s", t.Name()+"-client")))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	session := c.NewSession(ctx, http.DefaultClient, key)
+	t.Cleanup(func() { session.Close() })
+	if err := <-session.Open(); err != nil {
+		t.Fatalf("Failed to open gateway session: %v", err)
+	}
+	return session
+}
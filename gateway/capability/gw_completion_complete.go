@@ -44,7 +44,7 @@ func (c *GatewayCapability) gw_completion_complete(inputMsg *shared.Message) (in
 		for _, p := range prompts {
 			if p.Name == params.Argument.Ref.ID {
 				serverID = p.serverID
-				originalID = p.Name
+				originalID = p.originalName
 				break
 			}
 		}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gate4ai/mcp/gateway/client"
 	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
 
 	// Use 2025 schema
 	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
@@ -24,8 +25,15 @@ type prompt struct {
 // GetPrompts fetches prompts from all subscribed backends for the user associated with inputMsg.
 // It handles combining results and resolving name conflicts.
 func (c *GatewayCapability) GetPrompts(inputMsg *shared.Message, logger *zap.Logger) ([]*prompt, error) {
+	prompts, _, err := c.getPrompts(inputMsg, logger)
+	return prompts, err
+}
+
+// getPrompts works like GetPrompts, also returning the IDs of the backends whose
+// prompts are missing because they failed.
+func (c *GatewayCapability) getPrompts(inputMsg *shared.Message, logger *zap.Logger) ([]*prompt, []string, error) {
 	// Use a timeout for the overall operation
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second) // Increased timeout
+	ctx, cancel := context.WithTimeout(inputMsg.Context(), 15*time.Second) // Increased timeout
 	defer cancel()
 
 	// TODO: Implement caching similar to GetResources
@@ -37,24 +45,25 @@ func (c *GatewayCapability) GetPrompts(inputMsg *shared.Message, logger *zap.Log
 		fetchLogger := logger.With(zap.String("server", session.Backend.ID))
 		fetchLogger.Debug("Getting prompts from backend")
 
-		// GetPrompts now returns a channel of results
-		promptsResultChan := session.GetPrompts(ctx)
-
-		// Wait for result from the channel
-		promptsResult := <-promptsResultChan
-
-		if promptsResult.Error != nil {
-			fetchLogger.Error("Failed to get prompts from backend", zap.Error(promptsResult.Error))
-			return nil, promptsResult.Error
+		// Answered from the list cache of the backend, if it has one
+		backendPrompts, err := cachedList(c, session, "prompts/list", schema.ListPromptsRequestParams{}, func() ([]schema.Prompt, error) {
+			// GetPrompts now returns a channel of results; wait for the result
+			promptsResult := <-session.GetPrompts(ctx)
+			return promptsResult.Prompts, promptsResult.Error
+		})
+		if err != nil {
+			fetchLogger.Error("Failed to get prompts from backend", zap.Error(err))
+			return nil, err
 		}
 
-		results := make([]*prompt, 0, len(promptsResult.Prompts))
-		for _, p := range promptsResult.Prompts {
+		results := make([]*prompt, 0, len(backendPrompts))
+		for _, p := range backendPrompts {
 			pCopy := p // Create a copy to avoid modifying the cache
+			pCopy.Name = c.listedName(session.Backend.ID, p.Name)
 			results = append(results, &prompt{
 				Prompt:       pCopy,
 				serverID:     session.Backend.ID,
-				originalName: pCopy.Name, // Store original name
+				originalName: p.Name, // Store original name
 			})
 		}
 		fetchLogger.Debug("Received prompts from backend", zap.Int("count", len(results)))
@@ -80,18 +89,18 @@ func (c *GatewayCapability) GetPrompts(inputMsg *shared.Message, logger *zap.Log
 	}
 
 	// Use the generic function to fetch and combine prompts
-	allPrompts, err := fetchAndCombineFromBackends(c, ctx, inputMsg.Session, fetchPromptsFunc, getPromptKeyFunc, modifyPromptKeyFunc)
+	allPrompts, degraded, err := fetchAndCombineFromBackendsWithin(c, ctx, inputMsg.Session, 0, "prompts/list", fetchPromptsFunc, getPromptKeyFunc, modifyPromptKeyFunc)
 	if err != nil {
 		logger.Error("Failed to fetch and combine prompts", zap.Error(err))
-		return nil, fmt.Errorf("failed to get prompts: %w", err)
+		return nil, nil, fmt.Errorf("failed to get prompts: %w", err)
 	}
 
-	logger.Debug("Collected all prompts", zap.Int("count", len(allPrompts)))
+	logger.Debug("Collected all prompts", zap.Int("count", len(allPrompts)), zap.Strings("degradedBackends", degraded))
 
 	// TODO: Cache the results if caching is implemented
 	// SaveCachedPrompts(sessionParams, allPrompts)
 
-	return allPrompts, nil
+	return allPrompts, degraded, nil
 }
 
 // gw_prompts_get handles the "prompts/get" request from the client.
@@ -132,11 +141,13 @@ func (c *GatewayCapability) gw_prompts_get(inputMsg *shared.Message) (interface{
 	}
 
 	if foundPrompt == nil {
-		logger.Warn("Prompt not found in any backend")
-		// Return an empty result or an error? Spec implies empty result might be acceptable.
-		// Let's return an error for clarity.
-		return nil, fmt.Errorf("prompt not found: %s", params.Name)
-		// Alternative: return schema.GetPromptResult{Messages: []schema.PromptMessage{}}, nil
+		// Not in any listing: route to the user's default (or only) backend
+		serverID, originalName, err := c.routeUntargeted(inputMsg.Session, "prompt", params.Name, logger)
+		if err != nil {
+			logger.Warn("Prompt not found in any backend", zap.Error(err))
+			return nil, err
+		}
+		foundPrompt = &prompt{serverID: serverID, originalName: originalName}
 	}
 
 	logger.Debug("Found prompt, forwarding to backend",
@@ -155,22 +166,43 @@ func (c *GatewayCapability) gw_prompts_get(inputMsg *shared.Message) (interface{
 		return nil, fmt.Errorf("internal error: failed to get valid backend session for server %s", foundPrompt.serverID)
 	}
 
-	// Use a timeout context for the backend call
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for the backend call
-	defer cancel()
+	if c.isPassthrough(foundPrompt.serverID) {
+		return c.forwardPassthrough(inputMsg.Context(), inputMsg.Session, backendSession, "prompts/get", inputMsg.Params, "name", foundPrompt.originalName, c.backendTimeout(foundPrompt.serverID, config.DefaultBackendRequestTimeout), logger)
+	}
+
+	if c.sanitizeInbound() {
+		for k, v := range params.Arguments {
+			params.Arguments[k] = shared.SanitizeText(v)
+		}
+	}
 
 	// Forward the request to the backend using the ORIGINAL prompt name and arguments
 	// The backend doesn't know about the gateway's prefixed names.
-	resultChan := backendSession.GetPrompt(ctx, foundPrompt.originalName, params.Arguments)
-	asyncResult := <-resultChan // Wait for the result from the backend
+	asyncResult, err := withHedging(c, inputMsg.Context(), "prompts/get", inputMsg.Session, backendSession, logger, func(ctx context.Context, session *client.Session) (client.GetPromptAsyncResult, error) {
+		// Use a timeout context for the backend call
+		ctx, cancel := context.WithTimeout(ctx, c.backendTimeout(foundPrompt.serverID, config.DefaultBackendRequestTimeout)) // Timeout for the backend call
+		defer cancel()
 
-	if asyncResult.Error != nil {
+		asyncResult := <-session.GetPrompt(ctx, foundPrompt.originalName, params.Arguments) // Wait for the result from the backend
+		return asyncResult, asyncResult.Error
+	})
+
+	if err != nil {
 		logger.Error("Failed to get prompt from backend server",
 			zap.String("server", foundPrompt.serverID),
 			zap.String("originalName", foundPrompt.originalName),
-			zap.Error(asyncResult.Error))
+			zap.Error(err))
+		if rpcErr, ok := unavailable(err); ok {
+			return nil, rpcErr // Open circuit breaker, sent to the client as is
+		}
 		// Return the error received from the backend
-		return nil, fmt.Errorf("backend error getting prompt '%s': %w", foundPrompt.originalName, asyncResult.Error)
+		return nil, fmt.Errorf("backend error getting prompt '%s': %w", foundPrompt.originalName, err)
+	}
+
+	if c.sanitizeOutbound() && asyncResult.Result != nil {
+		for i := range asyncResult.Result.Messages {
+			sanitizeContent(&asyncResult.Result.Messages[i].Content)
+		}
 	}
 
 	// Return the result obtained from the backend (already in 2025 format)
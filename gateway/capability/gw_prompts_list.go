@@ -11,9 +11,9 @@ func (c *GatewayCapability) gw_prompts_list(inputMsg *shared.Message) (interface
 	logger := c.logger.With(zap.String("msgID", inputMsg.ID.String()))
 	logger.Debug("Processing prompts/list request")
 
-	allPrompts, err := c.GetPrompts(inputMsg, logger)
+	allPrompts, degraded, err := c.getPrompts(inputMsg, logger)
 	if err != nil {
-		return nil, err // Error already logged in GetPrompts
+		return nil, err // Error already logged in getPrompts
 	}
 
 	// Convert []*prompt to []schema.Prompt for the result
@@ -24,8 +24,12 @@ func (c *GatewayCapability) gw_prompts_list(inputMsg *shared.Message) (interface
 		}
 	}
 
-	return schema.ListPromptsResult{
+	result := schema.ListPromptsResult{
 		Prompts: schemaPrompts,
-		// Pagination not implemented in fetchAndCombineFromBackends yet
-	}, nil
+		// Pagination not implemented in fetchAndCombineFromBackendsWithin yet
+	}
+	if len(degraded) > 0 {
+		result.Meta = map[string]interface{}{degradedBackendsMetaKey: degraded}
+	}
+	return result, nil
 }
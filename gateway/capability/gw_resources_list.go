@@ -24,8 +24,15 @@ type resourceWithServerInfo struct {
 // GetResources fetches resources from all subscribed backends for the user associated with inputMsg.
 // It handles combining results, resolving URI conflicts, and caching.
 func (c *GatewayCapability) GetResources(inputMsg *shared.Message, logger *zap.Logger) ([]*resourceWithServerInfo, error) {
+	resources, _, err := c.getResources(inputMsg, logger)
+	return resources, err
+}
+
+// getResources works like GetResources, also returning the IDs of the backends whose
+// resources are missing because they failed. An incomplete list is not cached.
+func (c *GatewayCapability) getResources(inputMsg *shared.Message, logger *zap.Logger) ([]*resourceWithServerInfo, []string, error) {
 	// Use a timeout for the overall operation
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second) // Adjusted timeout
+	ctx, cancel := context.WithTimeout(inputMsg.Context(), 15*time.Second) // Adjusted timeout
 	defer cancel()
 
 	sessionParams := inputMsg.Session.GetParams()
@@ -33,7 +40,7 @@ func (c *GatewayCapability) GetResources(inputMsg *shared.Message, logger *zap.L
 	// Check for cached resources first
 	if cachedResources, timestamp, ok := GetSavedResources(sessionParams); ok && time.Since(timestamp) < defaultCacheExpiration {
 		logger.Debug("Returning cached resources", zap.Int("count", len(cachedResources)), zap.Time("cached_at", timestamp))
-		return cachedResources, nil
+		return cachedResources, nil, nil
 	}
 	logger.Debug("Cache miss or expired, fetching fresh resources")
 
@@ -42,31 +49,34 @@ func (c *GatewayCapability) GetResources(inputMsg *shared.Message, logger *zap.L
 		fetchLogger := logger.With(zap.String("server", session.Backend.ID))
 		fetchLogger.Debug("Getting resources from backend")
 
-		// GetResources now returns a channel GetResourcesResult (using 2025 schema type)
-		resourcesResultChan := session.GetResources(ctx)
-		select {
-		case result := <-resourcesResultChan:
-			if result.Err != nil {
-				fetchLogger.Error("Failed to get resources from backend", zap.Error(result.Err))
-				return nil, result.Err // Propagate error
+		// Answered from the list cache of the backend, if it has one
+		backendResources, err := cachedList(c, session, "resources/list", schema.ListResourcesRequestParams{}, func() ([]schema.Resource, error) {
+			// GetResources now returns a channel GetResourcesResult (using 2025 schema type)
+			select {
+			case result := <-session.GetResources(ctx):
+				return result.Resources, result.Err
+			case <-ctx.Done():
+				fetchLogger.Warn("Context cancelled while waiting for resources from backend", zap.Error(ctx.Err()))
+				return nil, ctx.Err()
 			}
+		})
+		if err != nil {
+			fetchLogger.Error("Failed to get resources from backend", zap.Error(err))
+			return nil, err // Propagate error
+		}
 
-			backendResources := result.Resources // Slice of schema.Resource (V2025)
-			results := make([]*resourceWithServerInfo, 0, len(backendResources))
-			for _, r := range backendResources {
-				rCopy := r // Create copy
-				results = append(results, &resourceWithServerInfo{
-					Resource:    rCopy,
-					originalURI: rCopy.URI, // Store original URI
-					serverID:    session.Backend.ID,
-				})
-			}
-			fetchLogger.Debug("Received resources from backend", zap.Int("count", len(results)))
-			return results, nil
-		case <-ctx.Done():
-			fetchLogger.Warn("Context cancelled while waiting for resources from backend", zap.Error(ctx.Err()))
-			return nil, ctx.Err()
+		results := make([]*resourceWithServerInfo, 0, len(backendResources))
+		for _, r := range backendResources {
+			rCopy := r // Create copy
+			rCopy.Name = c.listedName(session.Backend.ID, r.Name)
+			results = append(results, &resourceWithServerInfo{
+				Resource:    rCopy,
+				originalURI: rCopy.URI, // Store original URI
+				serverID:    session.Backend.ID,
+			})
 		}
+		fetchLogger.Debug("Received resources from backend", zap.Int("count", len(results)))
+		return results, nil
 	}
 
 	// Define the function to get the key (URI) from a resource
@@ -88,17 +98,19 @@ func (c *GatewayCapability) GetResources(inputMsg *shared.Message, logger *zap.L
 	}
 
 	// Use the generic function to fetch and combine resources
-	allResources, err := fetchAndCombineFromBackends(c, ctx, inputMsg.Session, fetchResourcesFunc, getResourceKeyFunc, modifyResourceKeyFunc)
+	allResources, degraded, err := fetchAndCombineFromBackendsWithin(c, ctx, inputMsg.Session, 0, "resources/list", fetchResourcesFunc, getResourceKeyFunc, modifyResourceKeyFunc)
 	if err != nil {
 		logger.Error("Failed to fetch and combine resources", zap.Error(err))
-		return nil, fmt.Errorf("failed to get resources: %w", err)
+		return nil, nil, fmt.Errorf("failed to get resources: %w", err)
 	}
 
-	logger.Debug("Collected all resources", zap.Int("count", len(allResources)))
+	logger.Debug("Collected all resources", zap.Int("count", len(allResources)), zap.Strings("degradedBackends", degraded))
 
-	// Cache the combined and potentially modified resources
-	SaveCachedResources(sessionParams, allResources)
-	return allResources, nil
+	// Cache the combined and potentially modified resources, unless backends are missing
+	if len(degraded) == 0 {
+		SaveCachedResources(sessionParams, allResources)
+	}
+	return allResources, degraded, nil
 }
 
 // gw_resources_list handles the "resources/list" request from the client.
@@ -107,14 +119,18 @@ func (c *GatewayCapability) gw_resources_list(inputMsg *shared.Message) (interfa
 	logger.Debug("Processing request")
 
 	// Get combined list of resources (handles fetching, conflict resolution, caching)
-	allResources, err := c.GetResources(inputMsg, logger)
+	allResources, degraded, err := c.getResources(inputMsg, logger)
 	if err != nil {
-		// Error already logged by GetResources
+		// Error already logged by getResources
 		return nil, err
 	}
 
 	// Convert []*resourceWithServerInfo to []schema.Resource for the result
-	return toListResourcesResult(allResources), nil
+	result := toListResourcesResult(allResources)
+	if len(degraded) > 0 {
+		result.Meta = map[string]interface{}{degradedBackendsMetaKey: degraded}
+	}
+	return result, nil
 }
 
 // toListResourcesResult converts the internal representation to the schema result type.
@@ -127,7 +143,7 @@ func toListResourcesResult(resources []*resourceWithServerInfo) schema.ListResou
 	}
 	return schema.ListResourcesResult{
 		Resources: schemaResources,
-		// Pagination not implemented in fetchAndCombineFromBackends yet
+		// Pagination not implemented in fetchAndCombineFromBackendsWithin yet
 		PaginatedResult: schema.PaginatedResult{NextCursor: nil},
 	}
 }
@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
+	"github.com/gate4ai/mcp/gateway/client"
 	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
 	"github.com/gate4ai/mcp/shared/mcp/2024/schema"
 	"go.uber.org/zap"
 )
@@ -49,8 +50,13 @@ func (c *GatewayCapability) gw_resources_read(inputMsg *shared.Message) (interfa
 	}
 
 	if targetResource == nil {
-		logger.Error("Resource not found in any backend")
-		return nil, fmt.Errorf("resource not found: %s", params.URI)
+		// Not in any listing (e.g. a templated URI): route to the user's default (or only) backend
+		serverID, originalURI, err := c.routeUntargeted(inputMsg.Session, "resource", params.URI, logger)
+		if err != nil {
+			logger.Error("Resource not found in any backend", zap.Error(err))
+			return nil, err
+		}
+		targetResource = &resourceWithServerInfo{serverID: serverID, originalURI: originalURI}
 	}
 
 	logger.Debug("Found resource, forwarding read request to backend",
@@ -68,21 +74,30 @@ func (c *GatewayCapability) gw_resources_read(inputMsg *shared.Message) (interfa
 		return nil, fmt.Errorf("internal error: failed to get valid backend session for server %s", targetResource.serverID)
 	}
 
-	// Use a timeout context for the backend call
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for the backend read operation
-	defer cancel()
+	if c.isPassthrough(targetResource.serverID) {
+		return c.forwardPassthrough(inputMsg.Context(), inputMsg.Session, backendSession, "resources/read", inputMsg.Params, "uri", targetResource.originalURI, c.backendTimeout(targetResource.serverID, config.DefaultBackendRequestTimeout), logger)
+	}
+
+	// Forward the request to the backend using the ORIGINAL resource URI, hedged over its replicas if configured
+	result, err := withHedging(c, inputMsg.Context(), "resources/read", inputMsg.Session, backendSession, logger, func(ctx context.Context, session *client.Session) (client.ReadResourceResult, error) {
+		// Use a timeout context for the backend call
+		ctx, cancel := context.WithTimeout(ctx, c.backendTimeout(targetResource.serverID, config.DefaultBackendRequestTimeout)) // Timeout for the backend read operation
+		defer cancel()
 
-	// Forward the request to the backend using the ORIGINAL resource URI
-	resultChan := backendSession.ReadResource(ctx, targetResource.originalURI)
-	result := <-resultChan // Wait for the result from the backend
+		result := <-session.ReadResource(ctx, targetResource.originalURI) // Wait for the result from the backend
+		return result, result.Err
+	})
 
-	if result.Err != nil {
+	if err != nil {
 		logger.Error("Failed to read resource from backend server",
 			zap.String("server", targetResource.serverID),
 			zap.String("originalURI", targetResource.originalURI),
-			zap.Error(result.Err))
+			zap.Error(err))
+		if rpcErr, ok := unavailable(err); ok {
+			return nil, rpcErr // Open circuit breaker, sent to the client as is
+		}
 		// Return the error received from the backend
-		return nil, fmt.Errorf("backend error reading resource '%s': %w", targetResource.originalURI, result.Err)
+		return nil, fmt.Errorf("backend error reading resource '%s': %w", targetResource.originalURI, err)
 	}
 
 	if result.Result == nil {
@@ -93,6 +108,10 @@ func (c *GatewayCapability) gw_resources_read(inputMsg *shared.Message) (interfa
 		return nil, err
 	}
 
+	if c.sanitizeOutbound() {
+		sanitizeResourceContents(result.Result.Contents)
+	}
+
 	// Return the contents obtained from the backend (already in 2025 format)
 	logger.Debug("Successfully read resource from backend")
 	return result.Result, nil
@@ -4,8 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time" // Import time
 
+	"github.com/gate4ai/mcp/gateway/client"
 	"github.com/gate4ai/mcp/shared"
 	// Use 2025 schema for parsing notifications
 	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
@@ -32,7 +32,7 @@ func (c *GatewayCapability) gw_resources_subscribe(inputMsg *shared.Message) (in
 		zap.String("gatewayURI", targetResource.URI))
 
 	// Create a context with timeout for the backend subscribe call
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // Short timeout for subscribe/unsubscribe
+	ctx, cancel := context.WithTimeout(inputMsg.Context(), subscriptionTimeout)
 	defer cancel()
 
 	// Pass the ORIGINAL URI to the backend's SubscribeResource method
@@ -44,6 +44,7 @@ func (c *GatewayCapability) gw_resources_subscribe(inputMsg *shared.Message) (in
 			zap.Error(err))
 		return nil, fmt.Errorf("failed to subscribe to resource '%s' on backend: %w", targetResource.originalURI, err)
 	}
+	c.subscriptions(inputMsg.Session).add(targetResource.URI, resourceSubscription{serverID: targetResource.serverID, originalURI: targetResource.originalURI})
 
 	logger.Info("Successfully subscribed to resource via backend",
 		zap.String("gatewayURI", targetResource.URI),
@@ -62,12 +63,32 @@ func (c *GatewayCapability) gw_resources_unsubscribe(inputMsg *shared.Message) (
 	logger := c.logger.With(zap.String("msgID", inputMsg.ID.String()), zap.String("method", "resources/unsubscribe"))
 	logger.Debug("Processing request")
 
-	// findBackendSessionForResourceURI also parses the URI from params
-	backendSession, targetResource, err := c.findBackendSessionForResourceURI(inputMsg, logger)
-	if err != nil {
-		// Error logged by findBackendSessionForResourceURI
-		return nil, err // Return error finding session/resource
+	// A subscribed resource is unsubscribed on the backend it was subscribed on, even if
+	// it is no longer listed
+	var backendSession *client.Session
+	var targetResource *resourceWithServerInfo
+	var params schema.UnsubscribeRequestParams
+	if inputMsg.Params != nil && json.Unmarshal(*inputMsg.Params, &params) == nil {
+		if sub, ok := c.subscriptions(inputMsg.Session).get(params.URI); ok {
+			session, err := c.getBackendSession(inputMsg.Session, sub.serverID)
+			if err != nil {
+				logger.Error("Failed to get backend session of subscribed resource", zap.String("serverID", sub.serverID), zap.Error(err))
+				return nil, err
+			}
+			backendSession = session
+			targetResource = &resourceWithServerInfo{Resource: schema.Resource{URI: params.URI}, serverID: sub.serverID, originalURI: sub.originalURI}
+		}
 	}
+	if backendSession == nil {
+		// findBackendSessionForResourceURI also parses the URI from params
+		session, resource, err := c.findBackendSessionForResourceURI(inputMsg, logger)
+		if err != nil {
+			// Error logged by findBackendSessionForResourceURI
+			return nil, err // Return error finding session/resource
+		}
+		backendSession, targetResource = session, resource
+	}
+	c.subscriptions(inputMsg.Session).remove(targetResource.URI)
 
 	logger.Debug("Found resource, forwarding unsubscribe request to backend",
 		zap.String("backendServerID", targetResource.serverID),
@@ -75,11 +96,11 @@ func (c *GatewayCapability) gw_resources_unsubscribe(inputMsg *shared.Message) (
 		zap.String("gatewayURI", targetResource.URI))
 
 	// Create a context with timeout for the backend unsubscribe call
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // Short timeout
+	ctx, cancel := context.WithTimeout(inputMsg.Context(), subscriptionTimeout)
 	defer cancel()
 
 	// Pass the ORIGINAL URI to the backend's UnsubscribeResource method
-	err = backendSession.UnsubscribeResource(ctx, targetResource.originalURI)
+	err := backendSession.UnsubscribeResource(ctx, targetResource.originalURI)
 	if err != nil {
 		logger.Error("Failed to unsubscribe from resource on backend server",
 			zap.String("server", targetResource.serverID),
@@ -136,42 +157,20 @@ func (c *GatewayCapability) gw_resources_notification_updated(backendMsg *shared
 	}
 	clientSessionLogger := logger.With(zap.String("clientSessionID", clientSession.GetID()))
 
-	// Find the corresponding gateway-facing URI using the cached resources for the client session
-	// We need the cache to map originalURI@serverID back to the gateway URI
-	cachedResources, _, okCache := GetSavedResources(clientSession.GetParams())
-	if !okCache {
-		clientSessionLogger.Warn("No cached resources found for client session, cannot map updated URI", zap.String("originalURI", originalURI), zap.String("serverID", serverID))
-		// Option 1: Force refresh cache?
-		// Option 2: Send notification with original URI (client might not recognize it)?
-		// Option 3: Send notification with prefixed URI (serverID:originalURI)?
-		// Let's try Option 3 as a fallback.
-		gatewayURI := fmt.Sprintf("%s:%s", serverID, originalURI)
-		clientSessionLogger.Warn("Sending notification with prefixed URI as fallback", zap.String("gatewayURI", gatewayURI))
-		clientSession.SendNotification("notifications/resources/updated", map[string]interface{}{
-			"uri": gatewayURI,
-		})
+	// Relay the update only to a client that subscribed to the resource, with the URI it subscribed with
+	subs := loadSubscriptions(clientSession.GetParams())
+	if subs == nil {
+		clientSessionLogger.Debug("Dropping resource update, the client session subscribed to no resource", zap.String("originalURI", originalURI), zap.String("serverID", serverID))
 		return
 	}
-
-	gatewayURI := ""
-	for _, res := range cachedResources {
-		if res != nil && res.originalURI == originalURI && res.serverID == serverID {
-			gatewayURI = res.URI
-			break
-		}
-	}
-
-	if gatewayURI == "" {
-		clientSessionLogger.Error("Could not find gateway URI corresponding to updated resource", zap.String("originalURI", originalURI), zap.String("serverID", serverID))
-		// Fallback like above, or maybe don't send notification if mapping fails?
-		// Let's try sending prefixed URI again.
-		gatewayURI = fmt.Sprintf("%s:%s", serverID, originalURI)
-		clientSessionLogger.Warn("Sending notification with prefixed URI as fallback (mapping failed)", zap.String("gatewayURI", gatewayURI))
-	} else {
-		clientSessionLogger.Debug("Mapped backend update to gateway URI", zap.String("originalURI", originalURI), zap.String("serverID", serverID), zap.String("gatewayURI", gatewayURI))
+	gatewayURI, subscribed := subs.gatewayURI(serverID, originalURI)
+	if !subscribed {
+		clientSessionLogger.Debug("Dropping update of a resource the client session did not subscribe to", zap.String("originalURI", originalURI), zap.String("serverID", serverID))
+		return
 	}
+	clientSessionLogger.Debug("Mapped backend update to gateway URI", zap.String("originalURI", originalURI), zap.String("serverID", serverID), zap.String("gatewayURI", gatewayURI))
 
-	// Send notification to the gateway client using the mapped (or fallback prefixed) URI
+	// Send notification to the gateway client using the URI it subscribed with
 	clientSession.SendNotification("notifications/resources/updated", map[string]interface{}{
 		"uri": gatewayURI,
 	})
@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time" // Import time
 
+	"github.com/gate4ai/mcp/gateway/client"
 	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
 	// Use 2025 schema for request parsing, although structure is same as 2024
 	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
 	"go.uber.org/zap"
@@ -47,8 +48,13 @@ func (c *GatewayCapability) gw_tools_call(inputMsg *shared.Message) (interface{}
 	}
 
 	if selectedTool == nil {
-		logger.Warnw("Tool not found in any backend")
-		return nil, fmt.Errorf("tool not found: %s", params.Name)
+		// Not in any listing: route to the user's default (or only) backend
+		serverID, originalName, err := c.routeUntargeted(inputMsg.Session, "tool", params.Name, c.logger.With(zap.String("msgID", inputMsg.ID.String())))
+		if err != nil {
+			logger.Warnw("Tool not found in any backend", "error", err)
+			return nil, err
+		}
+		selectedTool = &tool{serverID: serverID, originalName: originalName}
 	}
 
 	logger.Debugw("Found tool, forwarding call to backend",
@@ -59,6 +65,9 @@ func (c *GatewayCapability) gw_tools_call(inputMsg *shared.Message) (interface{}
 	backendSession, err := c.getBackendSession(inputMsg.Session, selectedTool.serverID)
 	if err != nil {
 		logger.Errorw("Failed to get backend session", "serverID", selectedTool.serverID, "error", err)
+		if rpcErr, ok := err.(*shared.JSONRPCError); ok {
+			return nil, rpcErr // Access denied, sent to the client as is
+		}
 		return nil, fmt.Errorf("failed to get backend session for server %s: %w", selectedTool.serverID, err)
 	}
 	if backendSession == nil {
@@ -69,25 +78,45 @@ func (c *GatewayCapability) gw_tools_call(inputMsg *shared.Message) (interface{}
 	// Call the tool on the backend using the ORIGINAL tool name
 	toolName := selectedTool.originalName
 
+	if c.isPassthrough(selectedTool.serverID) {
+		return c.forwardPassthrough(inputMsg.Context(), inputMsg.Session, backendSession, "tools/call", inputMsg.Params, "name", toolName, c.backendTimeout(selectedTool.serverID, config.DefaultBackendToolTimeout), c.logger.With(zap.String("msgID", inputMsg.ID.String())))
+	}
+
 	// Arguments are already map[string]interface{} in V2025 params
 	args := params.Arguments
-
-	// Use a timeout context for the backend call
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second) // Timeout for tool execution
-	defer cancel()
-
-	resultChan := backendSession.CallTool(ctx, toolName, args)
-	result := <-resultChan // Wait for the result from the backend
+	if c.sanitizeInbound() {
+		sanitizeValue(map[string]interface{}(args))
+	}
+	args = c.injectUserParams(inputMsg.Session, selectedTool, args, c.logger.With(zap.String("msgID", inputMsg.ID.String())))
+	progressToken, wantsProgress := clientProgressToken(params.Meta)
+
+	var result client.CallToolResult
+	err = c.withRetry(inputMsg.Context(), "tools/call", backendSession, c.logger.With(zap.String("msgID", inputMsg.ID.String())), func(ctx context.Context) error {
+		// Use a timeout context for the backend call
+		ctx, cancel := context.WithTimeout(ctx, c.backendTimeout(selectedTool.serverID, config.DefaultBackendToolTimeout)) // Timeout for tool execution
+		defer cancel()
+
+		// Wait for the result from the backend, relaying its progress to the client
+		if wantsProgress {
+			result = <-backendSession.CallToolWithProgress(ctx, toolName, args, relayProgress(inputMsg.Session, progressToken))
+		} else {
+			result = <-backendSession.CallTool(ctx, toolName, args)
+		}
+		return result.Error
+	})
 
 	// Handle the result (CallToolResult uses 2025 schema)
-	if result.Error != nil {
-		// Error could be connection error OR IsError=true from backend
+	if err != nil {
+		// Error could be connection error, IsError=true from backend or an open circuit breaker
 		logger.Errorw("Failed to call tool on backend",
 			"server", selectedTool.serverID,
 			"tool", toolName,
-			"error", result.Error)
+			"error", err)
+		if rpcErr, ok := unavailable(err); ok {
+			return nil, rpcErr // Open circuit breaker, sent to the client as is
+		}
 		// Return the error received from the client call wrapper
-		return nil, fmt.Errorf("failed to call tool '%s' on backend: %w", toolName, result.Error)
+		return nil, fmt.Errorf("failed to call tool '%s' on backend: %w", toolName, err)
 	}
 
 	if result.Result == nil {
@@ -97,6 +126,10 @@ func (c *GatewayCapability) gw_tools_call(inputMsg *shared.Message) (interface{}
 		return nil, err
 	}
 
+	if c.sanitizeOutbound() {
+		sanitizeContents(result.Result.Content)
+	}
+
 	// Check IsError flag within the result from the backend
 	if result.Result.IsError {
 		logger.Warnw("Tool call succeeded but backend reported tool error",
@@ -2,12 +2,16 @@ package capability
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gate4ai/mcp/gateway/client"
 	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
 
 	// Use 2025 schema
 	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
@@ -21,17 +25,39 @@ type tool struct {
 	originalName string // Store original name before potential modification
 }
 
+// degradedBackendsMetaKey is the _meta key of a tools/list result listing the backends
+// whose tools are missing because they failed or did not answer before the deadline.
+const degradedBackendsMetaKey = "gate4ai/degradedBackends"
+
 // GetTools fetches tools from all subscribed backends for the user associated with inputMsg.
 // It handles combining results, resolving name conflicts, and caching.
+// The tools of further pages listed to the client are included, see pagination.go.
 func (c *GatewayCapability) GetTools(inputMsg *shared.Message, logger *zap.Logger) ([]*tool, error) {
+	tools, _, _, err := c.getTools(inputMsg, 0, logger)
+	if err != nil {
+		return nil, err
+	}
+	for _, paged := range loadPagedTools(inputMsg.Session.GetParams()) {
+		if !slices.ContainsFunc(tools, func(t *tool) bool { return t.Name == paged.Name }) {
+			tools = append(tools, paged)
+		}
+	}
+	return tools, nil
+}
+
+// getTools works like GetTools, but with a positive deadline it returns the tools of the
+// backends that answered in time, together with the IDs of the missing backends.
+// An incomplete list is not cached. Only the first pages of backends without
+// AggregatePagination are listed, the others are returned as pending pages.
+func (c *GatewayCapability) getTools(inputMsg *shared.Message, deadline time.Duration, logger *zap.Logger) ([]*tool, []string, []pendingPage, error) {
 	// Use a timeout for the overall operation
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second) // Adjusted timeout
+	ctx, cancel := context.WithTimeout(inputMsg.Context(), 15*time.Second) // Adjusted timeout
 	defer cancel()
 
 	sessionParams := inputMsg.Session.GetParams()
 
 	// Check for cached tools first
-	if cachedTools, timestamp, ok := GetCachedTools(sessionParams); ok && time.Since(timestamp) < defaultCacheExpiration {
+	if cachedTools, pending, timestamp, ok := GetCachedTools(sessionParams); ok && time.Since(timestamp) < defaultCacheExpiration {
 		logger.Debug("Returning cached tools", zap.Int("count", len(cachedTools)), zap.Time("cached_at", timestamp))
 		// Filter out nil tools from cache before returning
 		validCachedTools := make([]*tool, 0, len(cachedTools))
@@ -40,39 +66,59 @@ func (c *GatewayCapability) GetTools(inputMsg *shared.Message, logger *zap.Logge
 				validCachedTools = append(validCachedTools, t)
 			}
 		}
-		return validCachedTools, nil
+		return validCachedTools, nil, pending, nil
 	}
 	logger.Debug("Cache miss or expired, fetching fresh tools")
 
+	var pendingMu sync.Mutex
+	var pending []pendingPage
+
 	// Define the function to fetch tools from a single backend session
 	fetchToolsFunc := func(ctx context.Context, session *client.Session) ([]*tool, error) {
 		fetchLogger := logger.With(zap.String("server", session.Backend.ID))
 		fetchLogger.Debug("Getting tools from backend")
 
-		// GetTools now returns a channel GetToolsResult (using 2025 schema type)
-		toolsResultChan := session.GetTools(ctx)
-		select {
-		case result := <-toolsResultChan:
-			if result.Err != nil {
-				fetchLogger.Error("Failed to get tools from backend", zap.Error(result.Err))
-				return nil, result.Err // Propagate error
+		// Answered from the list cache of the backend, if it has one
+		var backendTools []schema.Tool
+		var err error
+		if backend, configErr := c.config.GetBackend(session.Backend.ID); configErr == nil && backend.AggregatePagination {
+			backendTools, err = cachedList(c, session, "tools/list", schema.ListToolsRequestParams{}, func() ([]schema.Tool, error) {
+				// GetTools now returns a channel GetToolsResult (using 2025 schema type)
+				select {
+				case result := <-session.GetTools(ctx):
+					return result.Tools, result.Err
+				case <-ctx.Done():
+					fetchLogger.Warn("Context cancelled while waiting for tools from backend", zap.Error(ctx.Err()))
+					return nil, ctx.Err()
+				}
+			})
+		} else {
+			var page listPage[schema.Tool]
+			page, err = c.fetchToolsPage(ctx, session, "")
+			if err == nil && page.NextCursor != nil {
+				pendingMu.Lock()
+				pending = append(pending, pendingPage{Backend: session.Backend.ID, Cursor: *page.NextCursor})
+				pendingMu.Unlock()
 			}
+			backendTools = page.Items
+		}
+		if err != nil {
+			fetchLogger.Error("Failed to get tools from backend", zap.Error(err))
+			return nil, err // Propagate error
+		}
 
-			results := make([]*tool, 0, len(result.Tools))
-			for _, t := range result.Tools {
-				tCopy := t // Create a copy of the tool struct
-				results = append(results, &tool{
-					Tool:         tCopy,
-					serverID:     session.Backend.ID,
-					originalName: tCopy.Name, // Store original name
-				})
-			}
-			fetchLogger.Debug("Received tools from backend", zap.Int("count", len(results)))
-			return results, nil
-		case <-ctx.Done():
-			fetchLogger.Warn("Context cancelled while waiting for tools from backend", zap.Error(ctx.Err()))
-			return nil, ctx.Err()
+		results := make([]*tool, 0, len(backendTools))
+		for _, t := range backendTools {
+			tCopy := t // Create a copy of the tool struct
+			tCopy.Name = c.listedName(session.Backend.ID, t.Name)
+			results = append(results, &tool{
+				Tool:         tCopy,
+				serverID:     session.Backend.ID,
+				originalName: t.Name, // Store original name
+			})
 		}
+		fetchLogger.Debug("Received tools from backend", zap.Int("count", len(results)))
+		return results, nil
 	}
 
 	// Define the function to get the key (name) from a tool
@@ -94,18 +140,88 @@ func (c *GatewayCapability) GetTools(inputMsg *shared.Message, logger *zap.Logge
 	}
 
 	// Use the generic function to fetch and combine tools
-	allTools, err := fetchAndCombineFromBackends(c, ctx, inputMsg.Session, fetchToolsFunc, getToolKeyFunc, modifyToolKeyFunc)
+	allTools, degraded, err := fetchAndCombineFromBackendsWithin(c, ctx, inputMsg.Session, deadline, "tools/list", fetchToolsFunc, getToolKeyFunc, modifyToolKeyFunc)
 	if err != nil {
 		logger.Error("Failed to fetch and combine tools", zap.Error(err))
-		return nil, fmt.Errorf("failed to get tools: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to get tools: %w", err)
 	}
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	slices.SortFunc(pending, func(a, b pendingPage) int { return strings.Compare(a.Backend, b.Backend) })
 
-	logger.Debug("Collected all tools", zap.Int("count", len(allTools)))
+	logger.Debug("Collected all tools", zap.Int("count", len(allTools)), zap.Strings("degradedBackends", degraded))
 
-	// Cache the combined and potentially modified tools
-	SaveCachedTools(sessionParams, allTools)
+	// Cache the combined and potentially modified tools, unless backends are missing
+	if len(degraded) == 0 {
+		SaveCachedTools(sessionParams, allTools, pending)
+	}
 
-	return allTools, nil
+	return allTools, degraded, pending, nil
+}
+
+// fetchToolsPage returns a page of the tools of the backend of session, from its list
+// cache if it has one.
+func (c *GatewayCapability) fetchToolsPage(ctx context.Context, session *client.Session, cursor string) (listPage[schema.Tool], error) {
+	params := schema.ListToolsRequestParams{}
+	if cursor != "" {
+		params.Cursor = &cursor
+	}
+	return cachedPage(c, session, "tools/list", params, func() (listPage[schema.Tool], error) {
+		select {
+		case result := <-session.GetToolsPage(ctx, cursor):
+			return listPage[schema.Tool]{Items: result.Tools, NextCursor: result.NextCursor}, result.Err
+		case <-ctx.Done():
+			return listPage[schema.Tool]{}, ctx.Err()
+		}
+	})
+}
+
+// toolsPage answers a tools/list request with a cursor of the gateway: it lists the next
+// page of the first backend named by the cursor.
+func (c *GatewayCapability) toolsPage(inputMsg *shared.Message, cursor string, logger *zap.Logger) (*schema.ListToolsResult, error) {
+	pages, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	next := pages[0]
+	logger = logger.With(zap.String("server", next.Backend))
+	session, err := c.getBackendSession(inputMsg.Session, next.Backend)
+	if err != nil {
+		logger.Warn("Failed to get backend session for the next page of tools", zap.Error(err))
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(inputMsg.Context(), c.backendTimeout(next.Backend, config.DefaultBackendRequestTimeout))
+	defer cancel()
+	page, err := c.fetchToolsPage(ctx, session, next.Cursor)
+	if err != nil {
+		logger.Error("Failed to get the next page of tools from backend", zap.Error(err))
+		return nil, fmt.Errorf("failed to get tools: %w", err)
+	}
+	pages = pages[1:]
+	if page.NextCursor != nil {
+		pages = append([]pendingPage{{Backend: next.Backend, Cursor: *page.NextCursor}}, pages...)
+	}
+
+	// Names taken by a tool of another backend are prefixed, like on the first page
+	known, err := c.GetTools(inputMsg, logger)
+	if err != nil {
+		return nil, err
+	}
+	tools := make([]*tool, 0, len(page.Items))
+	for _, backendTool := range page.Items {
+		t := &tool{Tool: backendTool, serverID: next.Backend, originalName: backendTool.Name}
+		t.Name = c.listedName(next.Backend, t.originalName)
+		if slices.ContainsFunc(known, func(k *tool) bool { return k.Name == t.Name && k.serverID != t.serverID }) {
+			t.Name = fmt.Sprintf("%s:%s", next.Backend, t.originalName)
+		}
+		tools = append(tools, t)
+	}
+	savePagedTools(inputMsg.Session.GetParams(), tools)
+	logger.Debug("Listing a further page of tools", zap.Int("count", len(tools)), zap.Int("pendingPages", len(pages)))
+
+	result := toListToolsResult(tools)
+	result.NextCursor = encodeCursor(pages)
+	return &result, nil
 }
 
 // gw_tools_list handles the "tools/list" request from the client.
@@ -113,15 +229,36 @@ func (c *GatewayCapability) gw_tools_list(inputMsg *shared.Message) (interface{}
 	logger := c.logger.With(zap.String("msgID", inputMsg.ID.String()), zap.String("method", "tools/list"))
 	logger.Debug("Processing request")
 
+	var params schema.ListToolsRequestParams
+	if inputMsg.Params != nil {
+		if err := json.Unmarshal(*inputMsg.Params, &params); err != nil {
+			return nil, &shared.JSONRPCError{Code: shared.JSONRPCErrorInvalidParams, Message: fmt.Sprintf("invalid parameters: %v", err)}
+		}
+	}
+	if params.Cursor != nil && *params.Cursor != "" {
+		return c.toolsPage(inputMsg, *params.Cursor, logger)
+	}
+
+	deadline, err := c.config.ToolsListDeadline()
+	if err != nil {
+		logger.Error("Failed to get tools list deadline from config", zap.Error(err))
+		deadline = 0
+	}
+
 	// Get combined list of tools (handles fetching, conflict resolution, caching)
-	tools, err := c.GetTools(inputMsg, logger)
+	tools, degraded, pending, err := c.getTools(inputMsg, deadline, logger)
 	if err != nil {
 		// Error already logged by GetTools
 		return nil, err
 	}
 
 	// Convert []*tool to schema.ListToolsResult
-	return toListToolsResult(tools), nil
+	result := toListToolsResult(tools)
+	result.NextCursor = encodeCursor(pending)
+	if len(degraded) > 0 {
+		result.Meta = schema.Meta{degradedBackendsMetaKey: degraded}
+	}
+	return result, nil
 }
 
 // toListToolsResult converts the internal representation to the 2025 schema result type.
@@ -144,32 +281,39 @@ const cachedToolsKey = "gw_cached_tools"
 
 // SavedValue defined in sessionParams.go or gateway/capability.go
 
-// SaveCachedTools stores tools with timestamp in session parameters.
-func SaveCachedTools(sessionParams *sync.Map, tools []*tool) {
+// cachedToolList is the cached tools list of a client session: the tools listed first
+// and the pages of backends still to be listed.
+type cachedToolList struct {
+	tools   []*tool
+	pending []pendingPage
+}
+
+// SaveCachedTools stores tools and the pending pages with timestamp in session parameters.
+func SaveCachedTools(sessionParams *sync.Map, tools []*tool, pending []pendingPage) {
 	sessionParams.Store(cachedToolsKey, &SavedValue{
-		Value:     tools,
+		Value:     cachedToolList{tools: tools, pending: pending},
 		Timestamp: time.Now(),
 	})
 }
 
 // GetCachedTools retrieves tools from cache if present and not expired.
-// Returns the cached tools, timestamp, and a boolean indicating success.
-func GetCachedTools(sessionParams *sync.Map) ([]*tool, time.Time, bool) {
+// Returns the cached tools, the pending pages, timestamp, and a boolean indicating success.
+func GetCachedTools(sessionParams *sync.Map) ([]*tool, []pendingPage, time.Time, bool) {
 	cachedValue, ok := sessionParams.Load(cachedToolsKey)
 	if !ok {
-		return nil, time.Time{}, false // Not found
+		return nil, nil, time.Time{}, false // Not found
 	}
 
 	cached, ok := cachedValue.(*SavedValue)
 	if !ok {
-		return nil, time.Time{}, false // Invalid cache type
+		return nil, nil, time.Time{}, false // Invalid cache type
 	}
 
 	// Type assert the cached value
-	tools, ok := cached.Value.([]*tool)
+	list, ok := cached.Value.(cachedToolList)
 	if !ok {
-		return nil, time.Time{}, false // Invalid data type in cache
+		return nil, nil, time.Time{}, false // Invalid data type in cache
 	}
 
-	return tools, cached.Timestamp, true
+	return list.tools, list.pending, cached.Timestamp, true
 }
@@ -0,0 +1,150 @@
+package capability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// handshakeProbeTimeout bounds a retry of the handshake with a backend that failed it.
+const handshakeProbeTimeout = 10 * time.Second
+
+// handshakeFailure is the state of a backend that failed the MCP handshake.
+type handshakeFailure struct {
+	err   error     // Last failure, a *client.HandshakeError
+	since time.Time // First failure
+}
+
+// recordHandshakeError marks the backend unhealthy if err is a failed handshake, and
+// starts retrying the handshake in the background until it succeeds.
+func (c *GatewayCapability) recordHandshakeError(serverID string, err error) {
+	var handshakeErr *client.HandshakeError
+	if !errors.As(err, &handshakeErr) {
+		return
+	}
+
+	c.handshakesMu.Lock()
+	defer c.handshakesMu.Unlock()
+	if _, failed := c.handshakes[serverID]; failed {
+		return
+	}
+	c.logger.Warn("Backend failed the MCP handshake, rejecting requests until it recovers", zap.String("serverID", serverID), zap.Error(err))
+	c.handshakes[serverID] = &handshakeFailure{err: handshakeErr, since: time.Now()}
+	go c.retryHandshake(serverID)
+}
+
+// checkHandshake returns an error describing the handshake failure of the backend, or
+// nil if the backend is healthy.
+func (c *GatewayCapability) checkHandshake(serverID string) error {
+	c.handshakesMu.Lock()
+	defer c.handshakesMu.Unlock()
+	failure, failed := c.handshakes[serverID]
+	if !failed {
+		return nil
+	}
+	return fmt.Errorf("%w (backend '%s', unhealthy since %s)", failure.err, serverID, failure.since.Format(time.RFC3339))
+}
+
+// UnhealthyBackends returns the reason of each backend currently failing the MCP handshake.
+func (c *GatewayCapability) UnhealthyBackends() map[string]string {
+	c.handshakesMu.Lock()
+	defer c.handshakesMu.Unlock()
+	reasons := make(map[string]string, len(c.handshakes))
+	for serverID, failure := range c.handshakes {
+		reasons[serverID] = failure.err.Error()
+	}
+	return reasons
+}
+
+// retryHandshake retries the handshake with the backend every HandshakeRetry until it
+// succeeds, the backend is removed from the configuration or the gateway stops. Sessions
+// of clients that failed the handshake are replaced once the backend has recovered.
+func (c *GatewayCapability) retryHandshake(serverID string) {
+	logger := c.logger.With(zap.String("serverID", serverID))
+	for {
+		backend, err := c.config.GetBackend(serverID)
+		if err != nil {
+			logger.Info("Backend removed from the configuration, forgetting its handshake failure", zap.Error(err))
+			c.clearHandshake(serverID)
+			return
+		}
+		interval := backend.HandshakeRetry
+		if interval <= 0 {
+			interval = config.DefaultBackendHandshakeRetry
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-c.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		err = c.probeHandshake(serverID, backend.URL, backend, handshakeProbeTimeout, logger)
+		if err == nil {
+			logger.Info("Backend handshake succeeded again, accepting requests")
+			c.clearHandshake(serverID)
+			return
+		}
+		logger.Warn("Backend handshake still failing", zap.Error(err), zap.Duration("retryIn", interval))
+		var handshakeErr *client.HandshakeError
+		if errors.As(err, &handshakeErr) {
+			c.handshakesMu.Lock()
+			if failure, failed := c.handshakes[serverID]; failed {
+				failure.err = handshakeErr
+			}
+			c.handshakesMu.Unlock()
+		}
+	}
+}
+
+// probeHandshake opens and closes a session with the backend at url, its URL or one of
+// its replicas, returning the error of the handshake or of a timeout after timeout.
+func (c *GatewayCapability) probeHandshake(serverID, url string, backend *config.Backend, timeout time.Duration, logger *zap.Logger) error {
+	backendClient, err := newBackendClient(serverID, url, backend, logger)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+	session := backendClient.NewSession(ctx, c.backendHTTPClient(serverID, backend), backend.Bearer)
+	defer session.Close()
+
+	select {
+	case err := <-session.Open():
+		if err == nil {
+			err = session.HandshakeError()
+		}
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("handshake timed out: %w", ctx.Err())
+	}
+}
+
+// recovered reports whether the session failed the handshake with a backend that has
+// recovered since, so the session has to be replaced.
+func (c *GatewayCapability) recovered(session *client.Session) bool {
+	return session.HandshakeError() != nil && c.checkHandshake(session.Backend.ID) == nil
+}
+
+// hasRecoveredSession reports whether any of the sessions has to be replaced.
+func (c *GatewayCapability) hasRecoveredSession(sessions []*client.Session) bool {
+	for _, session := range sessions {
+		if session != nil && session.Backend != nil && c.recovered(session) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *GatewayCapability) clearHandshake(serverID string) {
+	c.handshakesMu.Lock()
+	defer c.handshakesMu.Unlock()
+	delete(c.handshakes, serverID)
+}
@@ -0,0 +1,119 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	schema2024 "github.com/gate4ai/mcp/shared/mcp/2024/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+func rejectInitialize(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+	return nil, &shared.JSONRPCError{Code: shared.JSONRPCErrorInvalidParams, Message: "Unsupported protocol version"}
+}
+
+func acceptInitialize(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+	return json.RawMessage(`{"protocolVersion":"` + schema2024.PROTOCOL_VERSION + `","capabilities":{"tools":{}},"serverInfo":{"name":"fake","version":"0.0.1"}}`), nil
+}
+
+// callReportTool calls the "report" tool of the echo backend through the gateway.
+func callReportTool(t *testing.T, session *client.Session) client.CallToolResult {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return <-session.CallTool(ctx, "report", map[string]interface{}{"query": "q"})
+}
+
+// unhealthyBackends returns the unhealthy backends reported by the status endpoint of the gateway.
+func unhealthyBackends(t *testing.T, gwURL string) map[string]string {
+	t.Helper()
+	resp, err := http.Get(strings.TrimSuffix(gwURL, "/sse") + "/status")
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status struct {
+		UnhealthyBackends map[string]string `json:"unhealthy_backends"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	return status.UnhealthyBackends
+}
+
+func TestFailedHandshakeRejectsRequests(t *testing.T) {
+	cases := []struct {
+		name       string
+		initialize func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError)
+		reason     string
+	}{
+		{"rejected", rejectInitialize, "Unsupported protocol version"},
+		{"version mismatch", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+			return json.RawMessage(`{"protocolVersion":"1999-01-01","capabilities":{},"serverInfo":{"name":"fake","version":"0.0.1"}}`), nil
+		}, "unsupported protocol version '1999-01-01'"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := newEchoArgsBackend(t)
+			fb.Handle("initialize", tc.initialize)
+			cfg := testutil.NewConfigBuilder().
+				WithUser("u", "key-u", "reports").
+				WithBackend("reports", fb.URL()).
+				Build(t)
+			gwURL := startTestGateway(t, cfg)
+			session := openGatewaySession(t, gwURL, "key-u")
+
+			for i := 0; i < 2; i++ {
+				result := callReportTool(t, session)
+				if result.Error == nil {
+					t.Fatalf("Expected call %d to be rejected", i+1)
+				}
+				if !strings.Contains(result.Error.Error(), "backend handshake failed") || !strings.Contains(result.Error.Error(), tc.reason) {
+					t.Fatalf("Expected a handshake failure naming the reason, got: %v", result.Error)
+				}
+			}
+
+			if reason := unhealthyBackends(t, gwURL)["reports"]; !strings.Contains(reason, tc.reason) {
+				t.Fatalf("Expected the status to report the backend unhealthy, got %q", reason)
+			}
+		})
+	}
+}
+
+func TestHandshakeRetriedUntilBackendRecovers(t *testing.T) {
+	fb := newEchoArgsBackend(t)
+	fb.Handle("initialize", rejectInitialize)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "reports").
+		WithBackend("reports", fb.URL()).
+		WithBackendHandshakeRetry("reports", "100ms").
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+	session := openGatewaySession(t, gwURL, "key-u")
+
+	if result := callReportTool(t, session); result.Error == nil || !strings.Contains(result.Error.Error(), "backend handshake failed") {
+		t.Fatalf("Expected a handshake failure, got: %v", result.Error)
+	}
+
+	fb.Handle("initialize", acceptInitialize)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		result := callReportTool(t, session)
+		if result.Error == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Backend still rejected after it accepted the handshake again: %v", result.Error)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if unhealthy := unhealthyBackends(t, gwURL); len(unhealthy) != 0 {
+		t.Fatalf("Expected no unhealthy backends after the recovery, got %v", unhealthy)
+	}
+}
@@ -0,0 +1,177 @@
+package capability
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// HealthState is the result of the health checks of a backend.
+type HealthState struct {
+	LastCheck   time.Time // Zero until the backend was first checked
+	LastSuccess time.Time // Zero if no check succeeded yet
+	LastError   error     // Of the last check, nil if it succeeded
+}
+
+// Healthy reports whether the last health check succeeded.
+func (h HealthState) Healthy() bool {
+	return !h.LastCheck.IsZero() && h.LastError == nil
+}
+
+// CheckBackendHealth health checks each configured backend every BackendHealthInterval
+// until ctx is done. A check performs the MCP handshake with the backend, the same as a
+// client session would, so it fails for unreachable backends as well as for backends
+// rejecting the gateway's credentials or protocol version. It times out after the
+// backend's Timeout, or config.DefaultBackendRequestTimeout. The results are reported by
+// BackendHealth and counted in /status; requests are routed regardless of them, except
+// that replicas failing their checks are not chosen while another one passes them.
+func (c *GatewayCapability) CheckBackendHealth(ctx context.Context) {
+	for {
+		interval, err := c.config.BackendHealthInterval()
+		if err != nil {
+			c.logger.Error("Failed to get backend health interval from config, using the default", zap.Error(err))
+		}
+		if interval <= 0 {
+			interval = config.DefaultBackendHealthInterval
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		c.checkBackends()
+	}
+}
+
+// checkBackends health checks all configured backends at once and forgets the results
+// of backends removed from the configuration.
+func (c *GatewayCapability) checkBackends() {
+	backendIDs, err := c.config.BackendIDs()
+	if err != nil {
+		c.logger.Error("Failed to list backends for health checks", zap.Error(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, backendID := range backendIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.checkBackend(backendID)
+		}()
+	}
+	wg.Wait()
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	for backendID := range c.health {
+		if !slices.Contains(backendIDs, backendID) {
+			delete(c.health, backendID)
+		}
+	}
+}
+
+// checkBackend health checks the backend and records the result.
+func (c *GatewayCapability) checkBackend(backendID string) {
+	logger := c.logger.With(zap.String("serverID", backendID))
+	backend, err := c.config.GetBackend(backendID)
+	if err != nil {
+		logger.Error("Failed to get backend for health check", zap.Error(err))
+		return
+	}
+	timeout := c.backendTimeout(backendID, config.DefaultBackendRequestTimeout)
+	if rs := c.replicaSet(backendID, backend); rs != nil {
+		err = c.checkReplicas(rs, backendID, backend, timeout, logger)
+	} else {
+		err = c.probeHandshake(backendID, backend.URL, backend, timeout, logger)
+	}
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	state, checked := c.health[backendID]
+	if !checked {
+		state = &HealthState{}
+		c.health[backendID] = state
+	}
+	wasHealthy := state.Healthy()
+	state.LastCheck = time.Now()
+	state.LastError = err
+	if err == nil {
+		state.LastSuccess = state.LastCheck
+	}
+	switch {
+	case err != nil && (wasHealthy || !checked):
+		logger.Warn("Backend health check failed", zap.Error(err))
+	case err == nil && checked && !wasHealthy:
+		logger.Info("Backend health check succeeded again")
+	}
+}
+
+// checkReplicas health checks the URL and each replica of the backend at once, excluding
+// the failing ones from the choice of replicas and reinstating those passing again. The
+// backend is healthy if any of them passes.
+func (c *GatewayCapability) checkReplicas(rs *replicaSet, backendID string, backend *config.Backend, timeout time.Duration, logger *zap.Logger) error {
+	urls := backend.URLs()
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.probeHandshake(backendID, url, backend, timeout, logger)
+		}()
+	}
+	wg.Wait()
+
+	for i, url := range urls {
+		if !rs.setHealthy(url, errs[i] == nil) {
+			continue
+		}
+		if errs[i] != nil {
+			logger.Warn("Backend replica health check failed, excluding it", zap.String("replica", url), zap.Error(errs[i]))
+		} else {
+			logger.Info("Backend replica health check succeeded again, reinstating it", zap.String("replica", url))
+		}
+	}
+	if slices.Contains(errs, nil) {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// BackendHealth returns the result of the health checks of the backend. It is zero
+// until the backend was first checked, and config.ErrNotFound for unknown backends.
+func (c *GatewayCapability) BackendHealth(backendID string) (HealthState, error) {
+	if _, err := c.config.GetBackend(backendID); err != nil {
+		return HealthState{}, err
+	}
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	if state, checked := c.health[backendID]; checked {
+		return *state, nil
+	}
+	return HealthState{}, nil
+}
+
+// BackendHealthCounts returns the number of checked backends whose last health check
+// succeeded and failed.
+func (c *GatewayCapability) BackendHealthCounts() (healthy int, unhealthy int) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	for _, state := range c.health {
+		if state.Healthy() {
+			healthy++
+		} else {
+			unhealthy++
+		}
+	}
+	return healthy, unhealthy
+}
@@ -0,0 +1,111 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/capability"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// backendHealthCounts returns the backend health counts reported by the status endpoint of the gateway.
+func backendHealthCounts(t *testing.T, gwURL string) (healthy int, unhealthy int) {
+	t.Helper()
+	resp, err := http.Get(strings.TrimSuffix(gwURL, "/sse") + "/status")
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status struct {
+		BackendHealth struct {
+			Healthy   int `json:"healthy"`
+			Unhealthy int `json:"unhealthy"`
+		} `json:"backend_health"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	return status.BackendHealth.Healthy, status.BackendHealth.Unhealthy
+}
+
+// unreachableURL returns the URL of a server that is no longer listening.
+func unreachableURL(t *testing.T) string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL + "/sse"
+}
+
+func TestBackendHealthChecks(t *testing.T) {
+	fb := newFakeBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithBackendHealthInterval("50ms").
+		WithBackend("up", fb.URL()).
+		WithBackend("down", unreachableURL(t)).
+		WithBackendTimeout("down", "200ms").
+		Build(t)
+	gw := capability.NewGatewayCapability(LOGGER, cfg)
+
+	if state, err := gw.BackendHealth("up"); err != nil || !state.LastCheck.IsZero() {
+		t.Fatalf("BackendHealth before the first check = %+v, %v; want a zero state", state, err)
+	}
+	if _, err := gw.BackendHealth("unknown"); !errors.Is(err, config.ErrNotFound) {
+		t.Fatalf("BackendHealth of an unknown backend = %v, want ErrNotFound", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go gw.CheckBackendHealth(ctx)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		up, _ := gw.BackendHealth("up")
+		down, _ := gw.BackendHealth("down")
+		if up.Healthy() && !down.LastCheck.IsZero() {
+			if down.Healthy() || down.LastError == nil || !down.LastSuccess.IsZero() {
+				t.Fatalf("Unreachable backend has state %+v", down)
+			}
+			if up.LastSuccess.IsZero() || up.LastError != nil {
+				t.Fatalf("Reachable backend has state %+v", up)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Backends not checked in time: up = %+v, down = %+v", up, down)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if healthy, unhealthy := gw.BackendHealthCounts(); healthy != 1 || unhealthy != 1 {
+		t.Fatalf("BackendHealthCounts = %d, %d; want 1, 1", healthy, unhealthy)
+	}
+}
+
+func TestStatusCountsBackendHealth(t *testing.T) {
+	fb := newFakeBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithBackendHealthInterval("50ms").
+		WithBackend("up", fb.URL()).
+		WithBackend("down", unreachableURL(t)).
+		WithBackendTimeout("down", "200ms").
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+	waitListening(t, strings.TrimSuffix(gwURL, "/sse")+"/status")
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		healthy, unhealthy := backendHealthCounts(t, gwURL)
+		if healthy == 1 && unhealthy == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Status counts %d healthy and %d unhealthy backends, want 1 and 1", healthy, unhealthy)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,154 @@
+package capability
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// requestBudget counts the extra backend requests, retries and hedged ones, that a
+// request may still make, so that hedging and retrying together do not amplify load.
+type requestBudget struct {
+	mu   sync.Mutex
+	left int
+}
+
+func newRequestBudget(n int) *requestBudget {
+	return &requestBudget{left: n}
+}
+
+// take uses one extra request of the budget and reports false if none is left.
+func (b *requestBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.left <= 0 {
+		return false
+	}
+	b.left--
+	return true
+}
+
+// hedgeOutcome is the result of one attempt of a hedged request.
+type hedgeOutcome[T any] struct {
+	result  T
+	err     error
+	session *client.Session
+}
+
+// withHedging runs call on the backend session like withRetry. If the backend hedges the
+// method over its replicas and no answer arrived after its hedge delay, call is also run
+// on another healthy replica, up to the backend's maximum of hedged requests. The first
+// successful answer is returned and the other attempts are cancelled; if all of them
+// fail, the error of the first attempt is returned. Retries and hedged requests share
+// one requestBudget. The attempts run with the values of reqCtx, the context of the
+// client request, e.g. its span, and are cancelled when the gateway stops.
+func withHedging[T any](c *GatewayCapability, reqCtx context.Context, method string, clientSession shared.ISession, session *client.Session, logger *zap.Logger, call func(ctx context.Context, session *client.Session) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancel(reqCtx)
+	defer cancel()
+	defer context.AfterFunc(c.ctx, cancel)()
+
+	serverID := session.Backend.ID
+	backend, err := c.config.GetBackend(serverID)
+	var rs *replicaSet
+	if err == nil && slices.Contains(backend.HedgeMethods, method) {
+		rs = c.replicaSet(serverID, backend)
+	}
+	if rs == nil {
+		var result T
+		err := c.withRetry(ctx, method, session, logger, func(ctx context.Context) error {
+			var err error
+			result, err = call(ctx, session)
+			return err
+		})
+		return result, err
+	}
+
+	delay := backend.HedgeDelay
+	if delay <= 0 {
+		delay = config.DefaultBackendHedgeDelay
+	}
+	maxHedges := backend.HedgeMax
+	if maxHedges <= 0 {
+		maxHedges = config.DefaultBackendHedgeMax
+	}
+	budget := newRequestBudget(maxHedges)
+	if len(backend.RetryCodes) > 0 {
+		budget = newRequestBudget(retryAttempts(backend))
+	}
+
+	outcomes := make(chan hedgeOutcome[T], maxHedges+1)
+	attempt := func(s *client.Session) {
+		var result T
+		err := c.withRetryBudget(ctx, method, s, logger, budget, func(ctx context.Context) error {
+			var err error
+			result, err = call(ctx, s)
+			return err
+		})
+		outcomes <- hedgeOutcome[T]{result: result, err: err, session: s}
+	}
+	hedge := func(url string) {
+		s := c.pooledReplicaSession(clientSession, serverID, url, backend, logger)
+		if s == nil {
+			rs.markDown(url)
+			outcomes <- hedgeOutcome[T]{err: fmt.Errorf("failed to create session for replica '%s'", url)}
+			return
+		}
+		if err := openWithin(s, replicaOpenTimeout); err != nil {
+			rs.markDown(url)
+			outcomes <- hedgeOutcome[T]{err: err, session: s}
+			return
+		}
+		attempt(s)
+	}
+
+	inFlight := []string{replicaURL(session)}
+	go attempt(session)
+	running, hedges := 1, 0
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var firstErr error
+	for running > 0 {
+		select {
+		case outcome := <-outcomes:
+			running--
+			if outcome.err == nil {
+				if outcome.session != session {
+					logger.Debug("Hedged request answered first", zap.String("serverID", serverID), zap.String("replica", replicaURL(outcome.session)))
+				}
+				return outcome.result, nil
+			}
+			if outcome.session == session || firstErr == nil {
+				firstErr = outcome.err
+			}
+		case <-timer.C:
+			if hedges >= maxHedges || !budget.take() {
+				continue
+			}
+			healthy := slices.DeleteFunc(rs.healthy(backend.URLs()), func(url string) bool {
+				return slices.Contains(inFlight, url)
+			})
+			if len(healthy) == 0 {
+				continue
+			}
+			url := rs.next(healthy)
+			logger.Debug("Backend slow to answer, hedging request", zap.String("serverID", serverID), zap.String("replica", url), zap.Duration("delay", delay))
+			inFlight = append(inFlight, url)
+			running++
+			hedges++
+			go hedge(url)
+			if hedges < maxHedges {
+				timer.Reset(delay)
+			}
+		}
+	}
+	var zero T
+	return zero, firstErr
+}
@@ -0,0 +1,89 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newResourceReplica returns a backend listing resource "res://doc", read as name after delay.
+func newResourceReplica(t *testing.T, name string, delay time.Duration) *fakeBackend {
+	fb := newFakeBackend(t)
+	fb.Handle("resources/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"resources":[{"uri":"res://doc","name":"doc"}]}`), nil
+	})
+	fb.Handle("resources/read", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		time.Sleep(delay)
+		return json.RawMessage(`{"contents":[{"uri":"res://doc","text":"` + name + `"}]}`), nil
+	})
+	return fb
+}
+
+func TestHedgedReadIsAnsweredByFastReplica(t *testing.T) {
+	slow := newResourceReplica(t, "slow", 3*time.Second)
+	fast := newResourceReplica(t, "fast", 0)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "svc").
+		WithBackend("svc", slow.URL()).
+		WithBackendReplicas("svc", "none", fast.URL()).
+		WithBackendHedge("svc", "50ms", 1, "resources/read").
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	// Round-robin sends every other read to the slow replica first
+	for i := 0; i < 4; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		start := time.Now()
+		result := <-session.ReadResource(ctx, "res://doc")
+		cancel()
+		if result.Err != nil {
+			t.Fatalf("Read %d failed: %v", i+1, result.Err)
+		}
+		if text := *result.Result.Contents[0].Text; text != "fast" {
+			t.Fatalf("Read %d answered by %q replica, want the fast one", i+1, text)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("Read %d took %v, the hedged request should have answered", i+1, elapsed)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for slow.Notified("notifications/cancelled") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("The slow replica's request was not cancelled")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestReadIsNotHedgedWithoutConfiguration(t *testing.T) {
+	slow := newResourceReplica(t, "slow", 500*time.Millisecond)
+	fast := newResourceReplica(t, "fast", 0)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "svc").
+		WithBackend("svc", slow.URL()).
+		WithBackendReplicas("svc", "none", fast.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	answered := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		result := <-session.ReadResource(ctx, "res://doc")
+		cancel()
+		if result.Err != nil {
+			t.Fatalf("Read %d failed: %v", i+1, result.Err)
+		}
+		answered[*result.Result.Contents[0].Text]++
+	}
+	if answered["slow"] == 0 {
+		t.Fatalf("Expected unhedged reads to wait for the slow replica, got %v", answered)
+	}
+	if n := slow.Notified("notifications/cancelled"); n != 0 {
+		t.Fatalf("Unhedged reads sent %d cancellations", n)
+	}
+}
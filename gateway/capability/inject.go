@@ -0,0 +1,87 @@
+package capability
+
+import (
+	"strconv"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+// injectUserParams adds the calling user's params to the arguments of a tool call as
+// configured by the inject rules of the tool's backend. A value supplied by the client
+// is kept unless the rule overrides it. If the tool publishes an input schema, params
+// are only injected as arguments the schema declares, converted to the declared type.
+// Returns the (possibly new) arguments map.
+func (c *GatewayCapability) injectUserParams(clientSession shared.ISession, t *tool, args schema.Arguments, logger *zap.Logger) schema.Arguments {
+	backend, err := c.config.GetBackend(t.serverID)
+	if err != nil || backend == nil || len(backend.Inject) == 0 {
+		return args
+	}
+	userID := transport.GetUserId(clientSession.GetParams())
+	if userID == "" {
+		return args
+	}
+	userParams, err := c.config.GetUserParams(userID)
+	if err != nil {
+		logger.Error("Failed to get user params for argument injection", zap.String("userID", userID), zap.Error(err))
+		return args
+	}
+
+	for _, rule := range backend.Inject {
+		if rule.Tool != "" && rule.Tool != t.originalName {
+			continue
+		}
+		value, ok := userParams[rule.Param]
+		if !ok {
+			logger.Debug("User param for injection not set", zap.String("param", rule.Param))
+			continue
+		}
+		argument := rule.ArgumentName()
+		if _, supplied := args[argument]; supplied && !rule.Override {
+			logger.Debug("Keeping client-supplied argument", zap.String("argument", argument))
+			continue
+		}
+		converted, ok := convertForSchema(t.InputSchema, argument, value)
+		if !ok {
+			logger.Warn("User param does not match the tool's input schema, not injected",
+				zap.String("param", rule.Param), zap.String("argument", argument))
+			continue
+		}
+		if args == nil {
+			args = make(schema.Arguments)
+		}
+		args[argument] = converted
+		logger.Debug("Injected user param into tool arguments", zap.String("param", rule.Param), zap.String("argument", argument))
+	}
+	return args
+}
+
+// convertForSchema converts the param value to the type the input schema declares for the
+// argument. Without a schema (or declared properties) the value is used as a string.
+// Returns false if the schema does not declare the argument or the value does not fit its type.
+func convertForSchema(inputSchema *schema.JSONSchemaProperty, argument string, value string) (interface{}, bool) {
+	if inputSchema == nil || len(inputSchema.Properties) == 0 {
+		return value, true
+	}
+	property, declared := inputSchema.Properties[argument]
+	if !declared {
+		return nil, false
+	}
+	switch property.Type {
+	case "", "string":
+		return value, true
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		return n, err == nil
+	case "number":
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		return b, err == nil
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,93 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newEchoArgsBackend returns a backend whose "report" tool replies with its arguments as JSON text.
+func newEchoArgsBackend(t *testing.T) *fakeBackend {
+	fb := newFakeBackend(t)
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"report","inputSchema":{"type":"object","properties":{"query":{"type":"string"},"locale":{"type":"string"},"tenant":{"type":"integer"}}}}]}`), nil
+	})
+	fb.Handle("tools/call", func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		var p struct {
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		json.Unmarshal(params, &p)
+		text, _ := json.Marshal(string(p.Arguments))
+		return json.RawMessage(`{"content":[{"type":"text","text":` + string(text) + `}]}`), nil
+	})
+	return fb
+}
+
+func callReport(t *testing.T, injections []config.ArgumentInjection, args map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	fb := newEchoArgsBackend(t)
+	b := testutil.NewConfigBuilder().
+		WithUser("inj", "key-inj", "reports").
+		WithUserParam("inj", "locale", "de-DE").
+		WithUserParam("inj", "tenant_id", "42").
+		WithUserParam("inj", "secret", "not-in-schema").
+		WithBackend("reports", fb.URL())
+	for _, injection := range injections {
+		b.WithBackendInjection("reports", injection)
+	}
+	session := openGatewaySession(t, startTestGateway(t, b.Build(t)), "key-inj")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallTool(ctx, "report", args)
+	if result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+	var received map[string]interface{}
+	if err := json.Unmarshal([]byte(*result.Result.Content[0].Text), &received); err != nil {
+		t.Fatalf("Backend did not echo arguments: %v", err)
+	}
+	return received
+}
+
+func TestUserParamInjectedIntoToolArguments(t *testing.T) {
+	received := callReport(t, []config.ArgumentInjection{
+		{Tool: "report", Param: "locale"},
+		{Param: "tenant_id", Argument: "tenant"},
+		{Param: "secret"},
+	}, map[string]interface{}{"query": "sales"})
+
+	if received["query"] != "sales" {
+		t.Errorf("Client argument lost: %v", received)
+	}
+	if received["locale"] != "de-DE" {
+		t.Errorf("locale not injected: %v", received)
+	}
+	if received["tenant"] != float64(42) {
+		t.Errorf("tenant not injected as integer: %v", received)
+	}
+	if _, ok := received["secret"]; ok {
+		t.Errorf("Param not declared in the tool schema must not be injected: %v", received)
+	}
+}
+
+func TestInjectionKeepsClientSuppliedValue(t *testing.T) {
+	received := callReport(t, []config.ArgumentInjection{
+		{Param: "locale"},
+	}, map[string]interface{}{"locale": "fr-FR"})
+	if received["locale"] != "fr-FR" {
+		t.Errorf("Client-supplied value was clobbered: %v", received)
+	}
+
+	received = callReport(t, []config.ArgumentInjection{
+		{Param: "locale", Override: true},
+	}, map[string]interface{}{"locale": "fr-FR"})
+	if received["locale"] != "de-DE" {
+		t.Errorf("Override rule should replace the client value: %v", received)
+	}
+}
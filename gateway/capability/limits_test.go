@@ -0,0 +1,98 @@
+package capability_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/server/a2a"
+	serverCapabilities "github.com/gate4ai/mcp/server/mcp/capability"
+	"github.com/gate4ai/mcp/server/mcp/validators"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"go.uber.org/zap"
+)
+
+// getLimits asks the gateway for the limits of the session's user.
+func getLimits(t *testing.T, session *client.Session, params interface{}) serverCapabilities.LimitsResult {
+	t.Helper()
+	result := callRaw(t, session, validators.LimitsMethod, params)
+	if result.Error != nil {
+		t.Fatalf("%s failed: %v", validators.LimitsMethod, result.Error)
+	}
+	var limits serverCapabilities.LimitsResult
+	if err := json.Unmarshal(result.Result, &limits); err != nil {
+		t.Fatalf("Failed to decode limits %s: %v", result.Result, err)
+	}
+	return limits
+}
+
+func TestLimitsReportOwnUsersLimits(t *testing.T) {
+	fb := newFakeBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("alice", "key-alice", "svc").
+		WithUserParam("alice", validators.RPSParamKey, "5").
+		WithUserParam("alice", validators.RPMParamKey, "120").
+		WithUserParam("alice", a2a.UserParamMaxConcurrentTasks, "3").
+		WithUser("bob", "key-bob", "svc").
+		WithBackend("svc", fb.URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+
+	alice := openGatewaySession(t, gwURL, "key-alice")
+	callRaw(t, alice, "tools/list", map[string]interface{}{})
+	limits := getLimits(t, alice, map[string]interface{}{"user": "bob"})
+	if limits.RequestsPerSecond != 5 || limits.RequestsPerMinute != 120 || limits.MaxConcurrentTasks != 3 {
+		t.Fatalf("alice sees limits %+v, want her own", limits)
+	}
+	if limits.RemainingPerMinute >= 120 || limits.RemainingPerMinute < 100 {
+		t.Errorf("alice has %d requests of 120 left after using some", limits.RemainingPerMinute)
+	}
+	if limits.RemainingPerSecond > 5 {
+		t.Errorf("alice has %d requests of 5 left this second", limits.RemainingPerSecond)
+	}
+
+	bob := openGatewaySession(t, gwURL, "key-bob")
+	limits = getLimits(t, bob, map[string]interface{}{})
+	if limits.RequestsPerSecond != validators.DefaultRPS || limits.RequestsPerMinute != validators.DefaultRPM || limits.MaxConcurrentTasks != 0 {
+		t.Fatalf("bob sees limits %+v, want the defaults", limits)
+	}
+}
+
+func TestLimitsReflectReloadedConfig(t *testing.T) {
+	fb := newFakeBackend(t)
+	builder := testutil.NewConfigBuilder().
+		WithUser("alice", "key-alice", "svc").
+		WithUserParam("alice", validators.RPMParamKey, "120").
+		WithBackend("svc", fb.URL())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func() {
+		data, err := builder.Bytes()
+		if err != nil {
+			t.Fatalf("Failed to marshal config: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	writeConfig()
+	cfg, err := config.NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-alice")
+	if limits := getLimits(t, session, nil); limits.RequestsPerMinute != 120 {
+		t.Fatalf("RequestsPerMinute = %d before the reload, want 120", limits.RequestsPerMinute)
+	}
+
+	builder.WithUserParam("alice", validators.RPMParamKey, "300")
+	writeConfig()
+	if err := cfg.Update(); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if limits := getLimits(t, session, nil); limits.RequestsPerMinute != 300 {
+		t.Fatalf("RequestsPerMinute = %d after the reload, want 300", limits.RequestsPerMinute)
+	}
+}
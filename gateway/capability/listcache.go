@@ -0,0 +1,99 @@
+package capability
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/gateway/listcache"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// backendListCache is a list cache together with the settings it was created with.
+type backendListCache struct {
+	*listcache.Cache
+	settings string
+}
+
+// listCache returns the list cache of the backend, or nil if it caches no lists. The
+// cache is recreated, empty, when its configuration changes.
+func (c *GatewayCapability) listCache(serverID string) *listcache.Cache {
+	backend, err := c.config.GetBackend(serverID)
+	if err != nil || !backend.ListCache || backend.Passthrough {
+		return nil
+	}
+	ttl := backend.ListCacheTTL
+	if ttl <= 0 {
+		ttl = config.DefaultBackendListCacheTTL
+	}
+	maxEntries := backend.ListCacheMaxEntries
+	if maxEntries <= 0 {
+		maxEntries = config.DefaultBackendListCacheMaxEntries
+	}
+	settings := fmt.Sprint(ttl, maxEntries)
+
+	c.listCachesMu.Lock()
+	defer c.listCachesMu.Unlock()
+	if existing, ok := c.listCaches[serverID]; ok && existing.settings == settings {
+		return existing.Cache
+	}
+	cache := listcache.New(ttl, maxEntries)
+	c.listCaches[serverID] = &backendListCache{Cache: cache, settings: settings}
+	return cache
+}
+
+// cachedList returns the answer of the backend of session to the list method with params
+// from its list cache, or fetches it and caches it. Without a list cache it only fetches.
+func cachedList[T any](c *GatewayCapability, session *client.Session, method string, params any, fetch func() ([]T, error)) ([]T, error) {
+	cache := c.listCache(session.Backend.ID)
+	if cache == nil {
+		return fetch()
+	}
+	key := listcache.NewKey(method, params)
+	cached, generation, ok := cache.Get(key)
+	if items, isList := cached.([]T); ok && isList {
+		return slices.Clone(items), nil
+	}
+
+	items, err := fetch()
+	if err == nil {
+		cache.Put(key, slices.Clone(items), generation)
+	}
+	return items, err
+}
+
+// backendListChanged handles a list_changed notification of a backend: the cached
+// answers to the method are dropped, for the backend and for the client session of
+// the backend session, and the client is notified that the gateway's list changed.
+func (c *GatewayCapability) backendListChanged(backendSession shared.ISession, method string) {
+	params := backendSession.GetParams()
+	serverID, _, ok := GetServerID(params)
+	if !ok {
+		return
+	}
+	logger := c.logger.With(zap.String("serverID", serverID), zap.String("method", method))
+	logger.Debug("Backend list changed, dropping cached answers")
+
+	c.listCachesMu.Lock()
+	cache, cached := c.listCaches[serverID]
+	c.listCachesMu.Unlock()
+	if cached {
+		cache.Invalidate(method)
+	}
+
+	clientSession, _, ok := GetClientSession(params)
+	if !ok || clientSession == nil {
+		return
+	}
+	switch method {
+	case "tools/list":
+		clientSession.GetParams().Delete(cachedToolsKey)
+		clientSession.GetParams().Delete(pagedToolsKey)
+	case "resources/list":
+		clientSession.GetParams().Delete(cachedResourcesKey)
+	}
+	clientSession.SendNotification("notifications/"+strings.TrimSuffix(method, "/list")+"/list_changed", nil)
+}
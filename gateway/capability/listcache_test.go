@@ -0,0 +1,97 @@
+package capability_test
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newListingBackend returns a backend counting its tools/list requests, listing the
+// tool named by the returned value.
+func newListingBackend(t *testing.T) (*fakeBackend, *atomic.Int32, *atomic.Value) {
+	fb := newFakeBackend(t)
+	var lists atomic.Int32
+	var toolName atomic.Value
+	toolName.Store("first")
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		lists.Add(1)
+		return json.RawMessage(`{"tools":[{"name":"` + toolName.Load().(string) + `","inputSchema":{"type":"object"}}]}`), nil
+	})
+	return fb, &lists, &toolName
+}
+
+func TestListCacheSharedBySessions(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		cache bool
+		lists int32
+	}{
+		{"cached", true, 1},
+		{"not cached", false, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fb, lists, _ := newListingBackend(t)
+			builder := testutil.NewConfigBuilder().
+				WithUser("u", "key-u", "b1").
+				WithBackend("b1", fb.URL())
+			if tc.cache {
+				builder.WithBackendListCache("b1", "1m", 0)
+			}
+			gwURL := startTestGateway(t, builder.Build(t))
+
+			for i := 0; i < 2; i++ {
+				session := openGatewaySession(t, gwURL, "key-u")
+				if result := callRaw(t, session, "tools/list", map[string]interface{}{}); result.Error != nil {
+					t.Fatalf("tools/list of session %d failed: %v", i+1, result.Error)
+				}
+			}
+			if n := lists.Load(); n != tc.lists {
+				t.Fatalf("Expected the backend to be asked %d times, got %d", tc.lists, n)
+			}
+		})
+	}
+}
+
+func TestListChangedFlushesListCache(t *testing.T) {
+	fb, lists, toolName := newListingBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "b1").
+		WithBackend("b1", fb.URL()).
+		WithBackendListCache("b1", "1m", 0).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+	changed := make(chan string, 1)
+	session.SubscribeOnListChanged(func(_ shared.ISession, method string) { changed <- method })
+
+	if result := callRaw(t, session, "tools/list", map[string]interface{}{}); result.Error != nil {
+		t.Fatalf("tools/list failed: %v", result.Error)
+	}
+	toolName.Store("second")
+	fb.Notify("notifications/tools/list_changed")
+
+	// Forwarded to the client once the cached answers are dropped
+	select {
+	case method := <-changed:
+		if method != "tools/list" {
+			t.Fatalf("Expected the tools list to change, got %s", method)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("The list_changed notification was not forwarded to the client")
+	}
+
+	result := callRaw(t, session, "tools/list", map[string]interface{}{})
+	if result.Error != nil {
+		t.Fatalf("tools/list failed: %v", result.Error)
+	}
+	if n := lists.Load(); n != 2 {
+		t.Fatalf("Expected the backend to be asked again after the list changed, got %d requests", n)
+	}
+	if !strings.Contains(string(result.Result), `"second"`) {
+		t.Fatalf("Expected the changed list, got %s", result.Result)
+	}
+}
@@ -0,0 +1,72 @@
+package capability_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// callRaw sends a request through the session and returns its result.
+func callRaw(t *testing.T, session *client.Session, method string, params interface{}) client.RawResult {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return <-session.CallRaw(ctx, method, params)
+}
+
+// assertDisabled checks that the request was rejected as a disabled method.
+func assertDisabled(t *testing.T, result client.RawResult, method string) {
+	t.Helper()
+	var rpcErr *shared.JSONRPCError
+	if !errors.As(result.Error, &rpcErr) {
+		t.Fatalf("Expected %s to be rejected with a JSON-RPC error, got %v (result %s)", method, result.Error, result.Result)
+	}
+	if rpcErr.Code != shared.JSONRPCErrorMethodNotFound || !strings.Contains(rpcErr.Message, "disabled") {
+		t.Fatalf("Expected %s to be reported as disabled, got %d %q", method, rpcErr.Code, rpcErr.Message)
+	}
+}
+
+func TestDeniedMethodRejectedForEveryUser(t *testing.T) {
+	fb := newEchoArgsBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithMethodsDeny("tools/call", "resources/*").
+		WithUser("alice", "key-alice", "reports").
+		WithUser("bob", "key-bob", "reports").
+		WithBackend("reports", fb.URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+
+	for _, key := range []string{"key-alice", "key-bob"} {
+		session := openGatewaySession(t, gwURL, key)
+		assertDisabled(t, callRaw(t, session, "tools/call", map[string]interface{}{"name": "report", "arguments": map[string]interface{}{}}), "tools/call")
+		assertDisabled(t, callRaw(t, session, "resources/read", map[string]interface{}{"uri": "file:///etc/passwd"}), "resources/read")
+		if result := callRaw(t, session, "tools/list", map[string]interface{}{}); result.Error != nil {
+			t.Fatalf("Expected tools/list to stay available, got %v", result.Error)
+		}
+	}
+}
+
+func TestAllowListRestrictsMethods(t *testing.T) {
+	fb := newEchoArgsBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithMethodsAllow("tools/*").
+		WithUser("alice", "key-alice", "reports").
+		WithBackend("reports", fb.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-alice")
+
+	if result := callRaw(t, session, "tools/list", map[string]interface{}{}); result.Error != nil {
+		t.Fatalf("Expected tools/list to be allowed, got %v", result.Error)
+	}
+	if result := callRaw(t, session, "tools/call", map[string]interface{}{"name": "report", "arguments": map[string]interface{}{"query": "q"}}); result.Error != nil {
+		t.Fatalf("Expected tools/call to be allowed, got %v", result.Error)
+	}
+	assertDisabled(t, callRaw(t, session, "prompts/list", map[string]interface{}{}), "prompts/list")
+	assertDisabled(t, callRaw(t, session, "resources/list", map[string]interface{}{}), "resources/list")
+}
@@ -0,0 +1,99 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+func TestBackendRequestMetrics(t *testing.T) {
+	fb := newFakeBackend(t)
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"ok","inputSchema":{"type":"object"}},{"name":"bad","inputSchema":{"type":"object"}}]}`), nil
+	})
+	fb.Handle("tools/call", func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		if strings.Contains(string(params), `"bad"`) {
+			return nil, &shared.JSONRPCError{Code: -32602, Message: "invalid arguments"}
+		}
+		return json.RawMessage(`{"content":[{"type":"text","text":"done"}]}`), nil
+	})
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "metered").
+		WithBackend("metered", fb.URL()).
+		WithMetricsLatencyBuckets(0.5, 5).
+		WithMetrics("").
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+	session := openGatewaySession(t, gwURL, "key-u")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if result := <-session.CallTool(ctx, "ok", map[string]interface{}{}); result.Error != nil {
+		t.Fatalf("Call of 'ok' failed: %v", result.Error)
+	}
+	if result := <-session.CallTool(ctx, "bad", map[string]interface{}{}); result.Error == nil {
+		t.Fatal("Expected call of 'bad' to fail")
+	}
+
+	resp, err := http.Get(strings.TrimSuffix(gwURL, "/sse") + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, line := range []string{
+		`gate4ai_backend_request_duration_seconds_bucket{method="tools/call",backend="metered",le="+Inf"} 2`,
+		`gate4ai_backend_request_duration_seconds_count{method="tools/list",backend="metered"} 1`,
+		`gate4ai_backend_requests_total{method="tools/call",backend="metered",result="success"} 1`,
+		`gate4ai_backend_requests_total{method="tools/call",backend="metered",result="invalid_params"} 1`,
+		`gate4ai_requests_total{method="tools/call"} 2`,
+		`gate4ai_request_errors_total{method="tools/call",code="-32603"} 1`, // Backend errors reach clients as internal errors
+		`gate4ai_request_duration_seconds_count{method="tools/call"} 2`,
+		`gate4ai_request_duration_seconds_bucket{method="initialize",le="+Inf"} 1`,
+		`gate4ai_active_sse_streams 1`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("Missing line %q in metrics:\n%s", line, body)
+		}
+	}
+	if !strings.Contains(string(body), `le="0.5"`) || strings.Contains(string(body), `le="0.005"`) {
+		t.Errorf("Expected the configured buckets in metrics:\n%s", body)
+	}
+}
+
+func TestMetricsEndpointOptIn(t *testing.T) {
+	scrape := func(gwURL string, path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(strings.TrimSuffix(gwURL, "/sse") + path)
+		if err != nil {
+			t.Fatalf("Failed to scrape %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	disabled := startTestGateway(t, testutil.NewConfigBuilder().WithUser("u", "key-u").Build(t))
+	openGatewaySession(t, disabled, "key-u") // Waits for the gateway to listen
+	if status, _ := scrape(disabled, "/metrics"); status != http.StatusNotFound {
+		t.Errorf("Expected no metrics unless enabled, got status %d", status)
+	}
+
+	enabled := startTestGateway(t, testutil.NewConfigBuilder().WithUser("u", "key-u").WithMetrics("/internal/metrics").Build(t))
+	openGatewaySession(t, enabled, "key-u")
+	if status, _ := scrape(enabled, "/metrics"); status != http.StatusNotFound {
+		t.Errorf("Expected no metrics at the default path when another is configured, got status %d", status)
+	}
+	status, body := scrape(enabled, "/internal/metrics")
+	if status != http.StatusOK || !strings.Contains(body, "# TYPE gate4ai_requests_total counter\n") {
+		t.Errorf("Expected metrics at the configured path, got status %d:\n%s", status, body)
+	}
+}
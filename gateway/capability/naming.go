@@ -0,0 +1,36 @@
+package capability
+
+import (
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// nameSeparator returns the separator of the tool, prompt and resource names listed
+// prefixed with the ID of their backend, as in "weather.search". It reports false if
+// names are listed as the backends name them. Resource URIs are never prefixed.
+func (c *GatewayCapability) nameSeparator() (string, bool) {
+	prefix, err := c.config.PrefixNames()
+	if err != nil {
+		c.logger.Error("Failed to read name prefix setting", zap.Error(err))
+		return "", false
+	}
+	if !prefix {
+		return "", false
+	}
+	separator, err := c.config.NameSeparator()
+	if err != nil {
+		c.logger.Error("Failed to read name separator setting", zap.Error(err))
+	}
+	if separator == "" {
+		separator = config.DefaultNameSeparator
+	}
+	return separator, true
+}
+
+// listedName returns the name a tool, prompt or resource of the backend is listed with.
+func (c *GatewayCapability) listedName(serverID string, name string) string {
+	if separator, ok := c.nameSeparator(); ok {
+		return serverID + separator + name
+	}
+	return name
+}
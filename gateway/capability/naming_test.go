@@ -0,0 +1,80 @@
+package capability_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newSearchBackend returns a backend listing a "search" tool and a "brief" prompt, and
+// answering tools/call like newUnlistedToolBackend.
+func newSearchBackend(t *testing.T, name string) *fakeBackend {
+	fb := newUnlistedToolBackend(t, name)
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"search","inputSchema":{"type":"object"}}]}`), nil
+	})
+	fb.Handle("prompts/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"prompts":[{"name":"brief"}]}`), nil
+	})
+	return fb
+}
+
+func TestPrefixedNames(t *testing.T) {
+	for _, separator := range []string{"", "__"} {
+		t.Run(fmt.Sprintf("separator=%q", separator), func(t *testing.T) {
+			cfg := testutil.NewConfigBuilder().
+				WithUser("u", "key-u", "weather", "news.eu").
+				WithBackend("weather", newSearchBackend(t, "weather").URL()).
+				WithBackend("news.eu", newSearchBackend(t, "news.eu").URL()).
+				WithPrefixedNames(separator).
+				Build(t)
+			session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+			if separator == "" {
+				separator = "."
+			}
+
+			var tools []string
+			for _, tool := range listToolsPage(t, session, nil).Tools {
+				tools = append(tools, tool.Name)
+			}
+			slices.Sort(tools)
+			if want := []string{"news.eu" + separator + "search", "weather" + separator + "search"}; !slices.Equal(tools, want) {
+				t.Fatalf("Listed tools %v, want %v", tools, want)
+			}
+
+			result := callRaw(t, session, "prompts/list", map[string]interface{}{})
+			var prompts schema.ListPromptsResult
+			if result.Error != nil || json.Unmarshal(result.Result, &prompts) != nil {
+				t.Fatalf("prompts/list failed: %v %s", result.Error, result.Result)
+			}
+			var promptNames []string
+			for _, prompt := range prompts.Prompts {
+				promptNames = append(promptNames, prompt.Name)
+			}
+			slices.Sort(promptNames)
+			if want := []string{"news.eu" + separator + "brief", "weather" + separator + "brief"}; !slices.Equal(promptNames, want) {
+				t.Fatalf("Listed prompts %v, want %v", promptNames, want)
+			}
+
+			// Calls are routed to the backend of the prefix, with the prefix stripped
+			for name, want := range map[string]string{
+				"weather" + separator + "search": "weather/search",
+				"news.eu" + separator + "search": "news.eu/search",
+				"news.eu" + separator + "hidden": "news.eu/hidden", // Not listed
+			} {
+				text, err := callUnlistedTool(t, session, name)
+				if err != nil {
+					t.Fatalf("Calling %s failed: %v", name, err)
+				}
+				if text != want {
+					t.Fatalf("Calling %s answered %q, want %q", name, text, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package capability
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/gateway/listcache"
+	"github.com/gate4ai/mcp/shared"
+)
+
+// Pagination of tools/list: the tools of a backend with AggregatePagination are fetched
+// with all their pages and listed at once. Of the other backends, the gateway lists the
+// first pages and hands the client a cursor of its own naming the backends with further
+// pages and their cursors. A tools/list with it returns the next page of the first of
+// these backends, together with a cursor for the rest.
+
+// pendingPage is a page of a backend's list not listed to the client yet.
+type pendingPage struct {
+	Backend string `json:"b"`
+	Cursor  string `json:"c"` // Of the backend
+}
+
+// encodeCursor returns the gateway cursor of the pending pages, nil if there are none.
+func encodeCursor(pages []pendingPage) *string {
+	if len(pages) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(pages)
+	cursor := base64.RawURLEncoding.EncodeToString(data)
+	return &cursor
+}
+
+// decodeCursor returns the pending pages of a gateway cursor.
+func decodeCursor(cursor string) ([]pendingPage, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var pages []pendingPage
+	if err := json.Unmarshal(data, &pages); err != nil || len(pages) == 0 {
+		return nil, errInvalidCursor
+	}
+	return pages, nil
+}
+
+var errInvalidCursor = &shared.JSONRPCError{Code: shared.JSONRPCErrorInvalidParams, Message: "Invalid cursor"}
+
+// listPage is a page of a backend's list.
+type listPage[T any] struct {
+	Items      []T
+	NextCursor *string // Of the backend, nil on the last page
+}
+
+// cachedPage is cachedList for a page of a list, cached together with its next cursor.
+func cachedPage[T any](c *GatewayCapability, session *client.Session, method string, params any, fetch func() (listPage[T], error)) (listPage[T], error) {
+	cache := c.listCache(session.Backend.ID)
+	if cache == nil {
+		return fetch()
+	}
+	key := listcache.NewKey(method, params)
+	cached, generation, ok := cache.Get(key)
+	if page, isPage := cached.(listPage[T]); ok && isPage {
+		return listPage[T]{Items: slices.Clone(page.Items), NextCursor: page.NextCursor}, nil
+	}
+
+	page, err := fetch()
+	if err == nil {
+		cache.Put(key, listPage[T]{Items: slices.Clone(page.Items), NextCursor: page.NextCursor}, generation)
+	}
+	return page, err
+}
+
+const pagedToolsKey = "gw_paged_tools"
+
+// pagedTools are the tools of further pages listed to a client session. They are kept
+// until a backend's tools list changes, so that the client can call them.
+type pagedTools struct {
+	mu    sync.Mutex
+	tools []*tool
+}
+
+// loadPagedTools returns the tools of further pages listed to the client session.
+func loadPagedTools(sessionParams *sync.Map) []*tool {
+	value, ok := sessionParams.Load(pagedToolsKey)
+	if !ok {
+		return nil
+	}
+	paged := value.(*pagedTools)
+	paged.mu.Lock()
+	defer paged.mu.Unlock()
+	return slices.Clone(paged.tools)
+}
+
+// savePagedTools adds tools of a further page listed to the client session, replacing
+// those of the same name.
+func savePagedTools(sessionParams *sync.Map, tools []*tool) {
+	value, _ := sessionParams.LoadOrStore(pagedToolsKey, &pagedTools{})
+	paged := value.(*pagedTools)
+	paged.mu.Lock()
+	defer paged.mu.Unlock()
+	for _, t := range tools {
+		paged.tools = slices.DeleteFunc(paged.tools, func(known *tool) bool { return known.Name == t.Name })
+		paged.tools = append(paged.tools, t)
+	}
+}
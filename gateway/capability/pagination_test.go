@@ -0,0 +1,135 @@
+package capability_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newPaginatingBackend returns a backend listing its tools "t1" to "t<count>" two per
+// page, with endless pages if count is negative, and the number of pages it served.
+func newPaginatingBackend(t *testing.T, count int) (*fakeBackend, *atomic.Int32) {
+	t.Helper()
+	fb := newFakeBackend(t)
+	var pages atomic.Int32
+	fb.Handle("tools/list", func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		pages.Add(1)
+		var request schema.ListToolsRequestParams
+		json.Unmarshal(params, &request)
+		first := 1
+		if request.Cursor != nil {
+			first, _ = strconv.Atoi(*request.Cursor)
+		}
+		var page schema.ListToolsResult
+		page.Tools = []schema.Tool{}
+		for n := first; n < first+2 && (count < 0 || n <= count); n++ {
+			page.Tools = append(page.Tools, schema.Tool{Name: fmt.Sprintf("t%d", n), InputSchema: &schema.JSONSchemaProperty{Type: "object"}})
+		}
+		if count < 0 || first+2 <= count {
+			next := strconv.Itoa(first + 2)
+			page.NextCursor = &next
+		}
+		result, _ := json.Marshal(page)
+		return result, nil
+	})
+	fb.Handle("tools/call", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"content":[{"type":"text","text":"called"}]}`), nil
+	})
+	return fb, &pages
+}
+
+// listToolsPage requests a page of the gateway's tools list.
+func listToolsPage(t *testing.T, session *client.Session, cursor *string) schema.ListToolsResult {
+	t.Helper()
+	params := map[string]interface{}{}
+	if cursor != nil {
+		params["cursor"] = *cursor
+	}
+	result := callRaw(t, session, "tools/list", params)
+	if result.Error != nil {
+		t.Fatalf("tools/list failed: %v", result.Error)
+	}
+	var page schema.ListToolsResult
+	if err := json.Unmarshal(result.Result, &page); err != nil {
+		t.Fatalf("Invalid tools/list result %s: %v", result.Result, err)
+	}
+	return page
+}
+
+func TestToolsListCursorsPassedThrough(t *testing.T) {
+	fb, _ := newPaginatingBackend(t, 5)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "b1", "b2").
+		WithBackend("b1", fb.URL()).
+		WithBackend("b2", newFakeBackend(t).URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	var names []string
+	var cursor *string
+	pages := 0
+	for {
+		page := listToolsPage(t, session, cursor)
+		pages++
+		for _, tool := range page.Tools {
+			names = append(names, tool.Name)
+		}
+		if cursor = page.NextCursor; cursor == nil {
+			break
+		}
+		if pages > 3 {
+			t.Fatalf("Expected 3 pages, got more")
+		}
+	}
+	if pages != 3 || fmt.Sprint(names) != "[t1 t2 t3 t4 t5]" {
+		t.Fatalf("Expected t1 to t5 on 3 pages, got %v on %d", names, pages)
+	}
+
+	// A tool of a further page can be called, though the gateway cannot guess its backend
+	result := callRaw(t, session, "tools/call", map[string]interface{}{"name": "t5", "arguments": map[string]interface{}{}})
+	if result.Error != nil {
+		t.Fatalf("Calling a tool of the last page failed: %v", result.Error)
+	}
+
+	// A cursor the gateway did not hand out is rejected
+	result = callRaw(t, session, "tools/list", map[string]interface{}{"cursor": "not a cursor"})
+	if rpcErr, ok := result.Error.(*shared.JSONRPCError); !ok || rpcErr.Code != shared.JSONRPCErrorInvalidParams {
+		t.Fatalf("Expected an invalid params error for a forged cursor, got %v", result.Error)
+	}
+}
+
+func TestToolsListPagesAggregated(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		count int
+		want  int // Tools listed
+	}{
+		{"all pages", 5, 5},
+		{"endless pages", -1, 2 * shared.MaxListPages},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fb, pages := newPaginatingBackend(t, tc.count)
+			cfg := testutil.NewConfigBuilder().
+				WithUser("u", "key-u", "b1").
+				WithBackend("b1", fb.URL()).
+				WithBackendAggregatePagination("b1").
+				Build(t)
+			session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+			page := listToolsPage(t, session, nil)
+			if len(page.Tools) != tc.want || page.NextCursor != nil {
+				t.Fatalf("Expected %d tools on one page, got %d with next cursor %v", tc.want, len(page.Tools), page.NextCursor)
+			}
+			if tc.count < 0 && pages.Load() != shared.MaxListPages {
+				t.Fatalf("Expected the gateway to stop after %d pages, it fetched %d", shared.MaxListPages, pages.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package capability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	"go.uber.org/zap"
+)
+
+// isPassthrough reports whether responses of the given backend must be relayed verbatim.
+func (c *GatewayCapability) isPassthrough(serverID string) bool {
+	backend, err := c.config.GetBackend(serverID)
+	if err != nil || backend == nil {
+		return false
+	}
+	return backend.Passthrough
+}
+
+// forwardPassthrough sends the client's params to the backend, replacing only the
+// routing field (e.g. the tool name or resource URI) with the backend's original value,
+// and returns the backend's result bytes unchanged. A JSON-RPC error from the backend
+// is returned as is, so the client sees the backend's code, message and data.
+//
+// If the client and the backend negotiated different protocol versions, params and
+// result are converted by the version adapter registered for the pair; a pair without
+// an adapter fails the request. The versions and whether an adapter was applied are
+// attached to the request's log entries and, if enabled, counted in the metrics.
+func (c *GatewayCapability) forwardPassthrough(ctx context.Context, clientSession shared.ISession, backendSession *client.Session, method string, rawParams *json.RawMessage, field string, value string, timeout time.Duration, logger *zap.Logger) (interface{}, error) {
+	clientVersion := clientSession.GetNegotiatedVersion()
+	backendVersion := backendSession.GetNegotiatedVersion()
+	versionAdapter, err := c.adapters.Lookup(clientVersion, backendVersion)
+	if err != nil {
+		logger.Error("No protocol version adapter for passthrough request",
+			zap.String("clientVersion", clientVersion), zap.String("backendVersion", backendVersion), zap.Error(err))
+		return nil, err
+	}
+	adapted := versionAdapter.Translates()
+	logger = logger.With(zap.String("clientVersion", clientVersion), zap.String("backendVersion", backendVersion), zap.Bool("versionAdapted", adapted))
+
+	params := make(map[string]json.RawMessage)
+	if rawParams != nil {
+		if err := json.Unmarshal(*rawParams, &params); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", field, err)
+	}
+	params[field] = encodedValue
+	if err := dropChunkedResultOptIn(params); err != nil {
+		return nil, err
+	}
+	progressToken, err := replaceProgressToken(params, clientSession, backendSession)
+	if err != nil {
+		return nil, err
+	}
+	if progressToken != "" {
+		// Runs before the response is sent, so the client gets the relayed progress first
+		defer backendSession.ProgressCapability.Done(progressToken)
+	}
+
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parameters: %w", err)
+	}
+	adaptedParams, err := versionAdapter.AdaptRequest(method, encodedParams)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Forwarding request in passthrough mode", zap.String("backendMethod", method))
+	var result client.RawResult
+	err = c.withRetry(ctx, method, backendSession, logger, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		result = <-backendSession.CallRaw(ctx, method, adaptedParams)
+		return result.Error
+	})
+	if enabled, _ := c.config.MetricsVersionLabels(); enabled {
+		c.metrics.ObserveRelay(method, backendSession.Backend.ID, clientVersion, backendVersion, adapted)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return versionAdapter.AdaptResponse(method, result.Result)
+}
+
+// dropChunkedResultOptIn removes a client's request for chunked tool results from the
+// forwarded params: the gateway relays the result buffered, so the backend must not
+// stream it to the gateway's session.
+func dropChunkedResultOptIn(params map[string]json.RawMessage) error {
+	rawMeta, ok := params["_meta"]
+	if !ok {
+		return nil
+	}
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(rawMeta, &meta); err != nil {
+		return fmt.Errorf("invalid _meta: %w", err)
+	}
+	if _, ok := meta[shared.ChunkedResultMetaKey]; !ok {
+		return nil
+	}
+	delete(meta, shared.ChunkedResultMetaKey)
+	if len(meta) == 0 {
+		delete(params, "_meta")
+		return nil
+	}
+	encodedMeta, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode _meta: %w", err)
+	}
+	params["_meta"] = encodedMeta
+	return nil
+}
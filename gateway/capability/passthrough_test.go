@@ -0,0 +1,148 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway"
+	"github.com/gate4ai/mcp/shared"
+	schema2024 "github.com/gate4ai/mcp/shared/mcp/2024/schema"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/gate4ai/mcp/tests"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const oddToolResult = `{"zeta":1,"content":[{"type":"text","text":"hi","x-vendor":{"b":2,"a":1}}],"alpha":{"nested":true},"isError":false}`
+
+func newPassthroughBackend(t *testing.T) *fakeBackend {
+	fb := newFakeBackend(t)
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"odd","inputSchema":{"type":"object"}},{"name":"broken","inputSchema":{"type":"object"}}]}`), nil
+	})
+	fb.Handle("tools/call", func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		var p struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(params, &p)
+		if p.Name == "broken" {
+			return nil, &shared.JSONRPCError{Code: -32050, Message: "backend specific failure", Data: map[string]interface{}{"hint": "retry later"}}
+		}
+		return json.RawMessage(oddToolResult), nil
+	})
+	return fb
+}
+
+func TestPassthroughRelaysBackendResultUnaltered(t *testing.T) {
+	fb := newPassthroughBackend(t)
+
+	cfg := testutil.NewConfigBuilder().
+		WithUser("pt", "key-pt", "odd-backend").
+		WithBackend("odd-backend", fb.URL()).
+		WithBackendPassthrough("odd-backend").
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-pt")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallRaw(ctx, "tools/call", map[string]interface{}{"name": "odd", "arguments": map[string]interface{}{}})
+	if result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+	if string(result.Result) != oddToolResult {
+		t.Fatalf("Result was altered:\n got: %s\nwant: %s", result.Result, oddToolResult)
+	}
+
+	// Backend JSON-RPC errors are relayed with their original code and data
+	result = <-session.CallRaw(ctx, "tools/call", map[string]interface{}{"name": "broken"})
+	rpcErr, ok := result.Error.(*shared.JSONRPCError)
+	if !ok {
+		t.Fatalf("Expected JSON-RPC error, got: %v", result.Error)
+	}
+	if rpcErr.Code != -32050 || rpcErr.Message != "backend specific failure" {
+		t.Fatalf("Backend error was altered: %+v", rpcErr)
+	}
+}
+
+func TestPassthroughRelayCarriesProtocolVersions(t *testing.T) {
+	fb := newPassthroughBackend(t) // Speaks 2024-11-05, the gateway's client 2025-03-26
+	cfg := testutil.NewConfigBuilder().
+		WithUser("pt", "key-pt", "odd-backend").
+		WithBackend("odd-backend", fb.URL()).
+		WithBackendPassthrough("odd-backend").
+		WithMetricsVersionLabels().
+		WithMetrics("").
+		Build(t)
+
+	port, err := tests.FindAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to find available port: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	core, logs := observer.New(zapcore.DebugLevel)
+	if _, err := gateway.Start(ctx, zap.New(core), cfg, fmt.Sprintf(":%d", port)); err != nil {
+		t.Fatalf("Failed to start gateway: %v", err)
+	}
+	baseURL := "http://localhost:" + strconv.Itoa(port)
+	session := openGatewaySession(t, baseURL+"/sse", "key-pt")
+
+	callCtx, callCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer callCancel()
+	if result := <-session.CallRaw(callCtx, "tools/call", map[string]interface{}{"name": "odd", "arguments": map[string]interface{}{}}); result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+
+	relayed := logs.FilterMessage("Forwarding request in passthrough mode").All()
+	if len(relayed) != 1 {
+		t.Fatalf("Expected one relay log entry, got %d", len(relayed))
+	}
+	fields := relayed[0].ContextMap()
+	if fields["clientVersion"] != schema2025.PROTOCOL_VERSION || fields["backendVersion"] != schema2024.PROTOCOL_VERSION || fields["versionAdapted"] != true {
+		t.Fatalf("Expected the relay to carry the negotiated versions, got %v", fields)
+	}
+
+	resp, err := http.Get(baseURL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	line := `gate4ai_backend_relays_total{method="tools/call",backend="odd-backend",client_version="` + schema2025.PROTOCOL_VERSION +
+		`",backend_version="` + schema2024.PROTOCOL_VERSION + `",adapted="true"} 1`
+	if !strings.Contains(string(body), line+"\n") {
+		t.Errorf("Missing line %q in metrics:\n%s", line, body)
+	}
+}
+
+func TestNonPassthroughReencodesBackendResult(t *testing.T) {
+	fb := newPassthroughBackend(t)
+
+	cfg := testutil.NewConfigBuilder().
+		WithUser("np", "key-np", "odd-backend").
+		WithBackend("odd-backend", fb.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-np")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallRaw(ctx, "tools/call", map[string]interface{}{"name": "odd", "arguments": map[string]interface{}{}})
+	if result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+	if string(result.Result) == oddToolResult {
+		t.Fatalf("Expected the default mode to re-encode the result, got it verbatim")
+	}
+	if !strings.Contains(string(result.Result), `"text":"hi"`) {
+		t.Fatalf("Tool content was lost: %s", result.Result)
+	}
+}
@@ -0,0 +1,64 @@
+package capability_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/gate4ai/mcp/tests"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestStrictLogPrivacyHidesUserAndContent(t *testing.T) {
+	const userID = "alice-user-42"
+	const prompt = "please summarize my medical record"
+
+	fb := newEchoArgsBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithLogPrivacy("strict").
+		WithUser(userID, "key-alice", "reports").
+		WithBackend("reports", fb.URL()).
+		Build(t)
+
+	port, err := tests.FindAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to find available port: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	core, logs := observer.New(zapcore.DebugLevel)
+	if _, err := gateway.Start(ctx, zap.New(core), cfg, fmt.Sprintf(":%d", port)); err != nil {
+		t.Fatalf("Failed to start gateway: %v", err)
+	}
+	session := openGatewaySession(t, "http://localhost:"+strconv.Itoa(port)+"/sse", "key-alice")
+
+	callCtx, callCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer callCancel()
+	result := <-session.CallTool(callCtx, "report", map[string]interface{}{"query": prompt})
+	if result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+	if !strings.Contains(*result.Result.Content[0].Text, prompt) {
+		t.Fatalf("Expected the prompt to reach the backend, got %q", *result.Result.Content[0].Text)
+	}
+
+	if logs.Len() == 0 {
+		t.Fatalf("Expected the gateway to log")
+	}
+	for _, entry := range logs.All() {
+		logged := entry.Message + fmt.Sprint(entry.ContextMap())
+		if strings.Contains(logged, userID) {
+			t.Errorf("User ID logged: %s", logged)
+		}
+		if strings.Contains(logged, "medical record") {
+			t.Errorf("Prompt logged: %s", logged)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package capability
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	clientCapability "github.com/gate4ai/mcp/gateway/client/capability"
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+)
+
+// Progress of backend requests is relayed under the token of the client's request: the
+// gateway asks the backend for progress with a token of its own, unique on the backend
+// session, and forwards each notification on the client session with the client's token.
+
+// clientProgressToken returns the progress token of a client request, if it asked for progress.
+func clientProgressToken(meta schema.Meta) (schema.ProgressToken, bool) {
+	token, ok := meta["progressToken"]
+	return token, ok && token != nil
+}
+
+// relayProgress returns a ProgressFunc sending a backend's progress notifications to the
+// client session under the token of the client's request.
+func relayProgress(clientSession shared.ISession, clientToken schema.ProgressToken) clientCapability.ProgressFunc {
+	return func(params schema.ProgressNotificationParams) {
+		notification := map[string]any{
+			"progressToken": clientToken,
+			"progress":      params.Progress,
+		}
+		if params.Total != nil {
+			notification["total"] = *params.Total
+		}
+		if params.Message != nil {
+			notification["message"] = *params.Message
+		}
+		clientSession.SendNotification(shared.ProgressNotificationMethod, notification)
+	}
+}
+
+// replaceProgressToken replaces the progress token in the _meta of forwarded params by
+// one of the backend session relaying to the client. It returns the backend token, to
+// pass to Done once the backend has answered, or "" if the client did not ask for progress.
+func replaceProgressToken(params map[string]json.RawMessage, clientSession shared.ISession, backendSession *client.Session) (string, error) {
+	rawMeta, ok := params["_meta"]
+	if !ok {
+		return "", nil
+	}
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(rawMeta, &meta); err != nil {
+		return "", fmt.Errorf("invalid _meta: %w", err)
+	}
+	var clientToken schema.ProgressToken
+	if raw, ok := meta["progressToken"]; ok {
+		if err := json.Unmarshal(raw, &clientToken); err != nil {
+			return "", fmt.Errorf("invalid progress token: %w", err)
+		}
+	}
+	if clientToken == nil {
+		return "", nil
+	}
+
+	backendToken := backendSession.ProgressCapability.Expect(relayProgress(clientSession, clientToken))
+	meta["progressToken"], _ = json.Marshal(backendToken)
+	encodedMeta, err := json.Marshal(meta)
+	if err != nil {
+		backendSession.ProgressCapability.Done(backendToken)
+		return "", fmt.Errorf("failed to encode _meta: %w", err)
+	}
+	params["_meta"] = encodedMeta
+	return backendToken, nil
+}
@@ -0,0 +1,258 @@
+package capability_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server"
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/gate4ai/mcp/tests"
+	"go.uber.org/zap"
+)
+
+const (
+	progressSteps    = 3
+	progressInterval = 20 * time.Millisecond // Between steps, so concurrent calls overlap
+)
+
+// startProgressServer starts an MCP server with a "slow" tool reporting progressSteps
+// steps of progress when asked to, and returns its SSE URL.
+func startProgressServer(t *testing.T) string {
+	t.Helper()
+	port, err := tests.FindAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to find available port: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cfg := testutil.NewConfigBuilder().WithAuthorization("none").Build(t)
+	tools, _, _, _, err := server.StartServer(ctx, LOGGER.With(zap.String("s", t.Name()+"-server")), cfg, fmt.Sprintf(":%d", port))
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	err = tools.AddTool("slow", "Reports its progress", &schema.JSONSchemaProperty{Type: "object"}, nil,
+		func(msg *shared.Message, args schema.Arguments) (*schema.Meta, []schema.Content, error) {
+			var params struct {
+				Meta struct {
+					ProgressToken schema.ProgressToken `json:"progressToken"`
+				} `json:"_meta"`
+			}
+			json.Unmarshal(*msg.Params, &params)
+			if params.Meta.ProgressToken != nil {
+				for step := 1; step <= progressSteps; step++ {
+					time.Sleep(progressInterval)
+					msg.Session.SendNotification(shared.ProgressNotificationMethod, map[string]any{
+						"progressToken": params.Meta.ProgressToken,
+						"progress":      step,
+						"total":         progressSteps,
+						"message":       fmt.Sprintf("step %d", step),
+					})
+				}
+			}
+			return nil, schema.NewTextContent("done"), nil
+		})
+	if err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+	return fmt.Sprintf("http://localhost:%d/sse", port)
+}
+
+// startProgressGateway starts a gateway in front of a progress server and returns its SSE URL.
+func startProgressGateway(t *testing.T, passthrough bool) string {
+	t.Helper()
+	builder := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "progress").
+		WithBackend("progress", startProgressServer(t))
+	if passthrough {
+		builder.WithBackendPassthrough("progress")
+	}
+	return startTestGateway(t, builder.Build(t))
+}
+
+// postMCP sends a JSON-RPC message to the streamable HTTP endpoint of the gateway.
+func postMCP(t *testing.T, mcpURL, sessionID, accept, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, mcpURL, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Authorization", "Bearer key-u")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	return resp
+}
+
+// waitListening waits until the gateway accepts connections.
+func waitListening(t *testing.T, url string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Gateway not listening: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// initializeMCP opens a session on the streamable HTTP endpoint of the gateway and
+// returns its ID.
+func initializeMCP(t *testing.T, mcpURL string) string {
+	t.Helper()
+	resp := postMCP(t, mcpURL, "", "application/json",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+schema.PROTOCOL_VERSION+`","capabilities":{},"clientInfo":{"name":"progress-test","version":"1.0"}}}`)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize failed with status %d", resp.StatusCode)
+	}
+	resp = postMCP(t, mcpURL, sessionID, "application/json", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	return sessionID
+}
+
+func TestProgressRelayedOnResponseStream(t *testing.T) {
+	for _, passthrough := range []bool{false, true} {
+		t.Run(fmt.Sprintf("passthrough=%v", passthrough), func(t *testing.T) {
+			mcpURL := strings.TrimSuffix(startProgressGateway(t, passthrough), "/sse") + "/mcp"
+			waitListening(t, strings.TrimSuffix(mcpURL, "/mcp")+"/status")
+			sessionID := initializeMCP(t, mcpURL)
+
+			resp := postMCP(t, mcpURL, sessionID, "application/json, text/event-stream",
+				`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow","arguments":{},"_meta":{"progressToken":"client-token"}}}`)
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+				t.Fatalf("Expected an event stream, got status %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+
+			reader := shared.NewSSEReader(resp.Body)
+			for step := 1; step <= progressSteps; step++ {
+				event, err := reader.Next()
+				if err != nil {
+					t.Fatalf("Stream ended before progress step %d: %v", step, err)
+				}
+				var notification struct {
+					Method string                            `json:"method"`
+					Params schema.ProgressNotificationParams `json:"params"`
+				}
+				if err := json.Unmarshal([]byte(event.Data), &notification); err != nil || notification.Method != shared.ProgressNotificationMethod {
+					t.Fatalf("Expected progress step %d, got %s", step, event.Data)
+				}
+				if notification.Params.ProgressToken != "client-token" || notification.Params.Progress != float64(step) {
+					t.Fatalf("Expected step %d for the client's token, got %s", step, event.Data)
+				}
+				if notification.Params.Message == nil || *notification.Params.Message != fmt.Sprintf("step %d", step) {
+					t.Fatalf("Expected the message of step %d, got %s", step, event.Data)
+				}
+			}
+
+			event, err := reader.Next()
+			if err != nil {
+				t.Fatalf("Stream ended before the result: %v", err)
+			}
+			var response struct {
+				ID     int                    `json:"id"`
+				Result *schema.CallToolResult `json:"result"`
+			}
+			if err := json.Unmarshal([]byte(event.Data), &response); err != nil || response.ID != 2 || response.Result == nil {
+				t.Fatalf("Expected the tools/call result after the progress, got %s", event.Data)
+			}
+			if _, err := reader.Next(); err != io.EOF {
+				t.Fatalf("Expected the stream to close after the result, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCallToolWithProgressThroughGateway(t *testing.T) {
+	session := openGatewaySession(t, startProgressGateway(t, false), "key-u")
+
+	var mu sync.Mutex
+	var steps []float64
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallToolWithProgress(ctx, "slow", map[string]interface{}{}, func(params schema.ProgressNotificationParams) {
+		mu.Lock()
+		defer mu.Unlock()
+		steps = append(steps, params.Progress)
+	})
+	if result.Error != nil {
+		t.Fatalf("Tool call failed: %v", result.Error)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(steps) != "[1 2 3]" {
+		t.Fatalf("Expected progress 1, 2, 3 before the result, got %v", steps)
+	}
+}
+
+func TestProgressOfConcurrentClientsDoesNotCross(t *testing.T) {
+	mcpURL := strings.TrimSuffix(startProgressGateway(t, false), "/sse") + "/mcp"
+	waitListening(t, strings.TrimSuffix(mcpURL, "/mcp")+"/status")
+
+	// Both clients use the same token for calls running at the same time
+	const clients = 2
+	var wg sync.WaitGroup
+	received := make([][]string, clients) // Client -> data of the events of its stream
+	for i := 0; i < clients; i++ {
+		sessionID := initializeMCP(t, mcpURL)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp := postMCP(t, mcpURL, sessionID, "application/json, text/event-stream",
+				fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"slow","arguments":{},"_meta":{"progressToken":"shared-token"}}}`, 100+i))
+			defer resp.Body.Close()
+			reader := shared.NewSSEReader(resp.Body)
+			for {
+				event, err := reader.Next()
+				if err != nil {
+					return
+				}
+				received[i] = append(received[i], event.Data)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, events := range received {
+		if len(events) != progressSteps+1 {
+			t.Fatalf("Client %d received %d events, want %d steps and the result: %v", i, len(events), progressSteps, events)
+		}
+		for step := 1; step <= progressSteps; step++ {
+			var notification struct {
+				Params schema.ProgressNotificationParams `json:"params"`
+			}
+			if err := json.Unmarshal([]byte(events[step-1]), &notification); err != nil ||
+				notification.Params.ProgressToken != "shared-token" || notification.Params.Progress != float64(step) {
+				t.Fatalf("Client %d: expected progress step %d, got %s", i, step, events[step-1])
+			}
+		}
+		if !strings.Contains(events[progressSteps], fmt.Sprintf(`"id":%d`, 100+i)) {
+			t.Fatalf("Client %d: expected its result last, got %s", i, events[progressSteps])
+		}
+	}
+}
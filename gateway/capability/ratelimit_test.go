@@ -0,0 +1,42 @@
+package capability_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+func TestUserRateLimitSpansSessions(t *testing.T) {
+	fb := newFakeBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("alice", "key-alice", "svc").
+		WithUserRateLimit("alice", 0.01, 3).
+		WithUser("bob", "key-bob", "svc").
+		WithBackend("svc", fb.URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+
+	first := openGatewaySession(t, gwURL, "key-alice")
+	second := openGatewaySession(t, gwURL, "key-alice")
+	for i := range 3 {
+		if result := callRaw(t, first, "tools/list", map[string]interface{}{}); result.Error != nil {
+			t.Fatalf("Request %d within the burst failed: %v", i+1, result.Error)
+		}
+	}
+	var rpcErr *shared.JSONRPCError
+	if result := callRaw(t, second, "tools/list", map[string]interface{}{}); !errors.As(result.Error, &rpcErr) || rpcErr.Code != shared.JSONRPCErrorRateLimited {
+		t.Fatalf("Expected the request beyond the burst to be rate limited, got %v", result.Error)
+	}
+	if data, _ := rpcErr.Data.(map[string]interface{}); data["retryAfterMs"] == nil {
+		t.Errorf("Rate limit error without retryAfterMs: %v", rpcErr.Data)
+	}
+
+	bob := openGatewaySession(t, gwURL, "key-bob")
+	for i := range 10 {
+		if result := callRaw(t, bob, "tools/list", map[string]interface{}{}); result.Error != nil {
+			t.Fatalf("Request %d of a user without a rate limit failed: %v", i+1, result.Error)
+		}
+	}
+}
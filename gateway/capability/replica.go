@@ -0,0 +1,288 @@
+package capability
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/balancer"
+	"github.com/gate4ai/mcp/gateway/breaker"
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/gateway/metrics"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// replicaOpenTimeout bounds connecting to a replica before another one is tried.
+const replicaOpenTimeout = 10 * time.Second
+
+// replicaSet is the state of the replicas of a backend: the weighted round-robin, the
+// replicas skipped after a fault and those failing the health checks.
+type replicaSet struct {
+	settings string // Replicas, weights and fault settings the set was created with
+	urls     []string
+	weight   func(url string) int
+	cooldown time.Duration
+	faults   []string
+	balancer *balancer.SmoothWeighted
+
+	mu        sync.Mutex
+	downUntil map[string]time.Time // URL -> end of the cooldown
+	unhealthy map[string]bool      // URL -> failed its last health check
+}
+
+// replicaSet returns the replica state of the backend, or nil if it has no replicas. The
+// state is reset when the replicas, their weights or their fault settings change.
+func (c *GatewayCapability) replicaSet(serverID string, backend *config.Backend) *replicaSet {
+	if backend == nil || len(backend.Replicas) == 0 {
+		return nil
+	}
+	cooldown := backend.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = config.DefaultBackendBreakerCooldown
+	}
+	faults := backend.BreakerFaults
+	if len(faults) == 0 {
+		faults = breaker.DefaultFaults
+	}
+	settings := fmt.Sprint(backend.URLs(), backend.Weights, cooldown, faults)
+
+	c.replicasMu.Lock()
+	defer c.replicasMu.Unlock()
+	if existing, ok := c.replicas[serverID]; ok && existing.settings == settings {
+		return existing
+	}
+	rs := &replicaSet{
+		settings:  settings,
+		urls:      backend.URLs(),
+		weight:    backend.Weight,
+		cooldown:  cooldown,
+		faults:    faults,
+		balancer:  balancer.NewSmoothWeighted(),
+		downUntil: make(map[string]time.Time),
+		unhealthy: make(map[string]bool),
+	}
+	c.replicas[serverID] = rs
+	return rs
+}
+
+// healthy returns the URLs neither in their cooldown nor failing the health checks, or
+// all of them if none is.
+func (rs *replicaSet) healthy(urls []string) []string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	now := time.Now()
+	healthy := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if now.After(rs.downUntil[url]) && !rs.unhealthy[url] {
+			healthy = append(healthy, url)
+		}
+	}
+	if len(healthy) == 0 {
+		return urls
+	}
+	return healthy
+}
+
+// isDown reports whether the replica is in its cooldown, or fails the health checks
+// while another replica passes them.
+func (rs *replicaSet) isDown(url string) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if time.Now().Before(rs.downUntil[url]) {
+		return true
+	}
+	return rs.unhealthy[url] && slices.ContainsFunc(rs.urls, func(other string) bool { return !rs.unhealthy[other] })
+}
+
+// setHealthy records the result of a health check of the replica and reports whether it
+// changed since the previous one.
+func (rs *replicaSet) setHealthy(url string, healthy bool) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.unhealthy[url] == !healthy {
+		return false
+	}
+	if healthy {
+		delete(rs.unhealthy, url)
+	} else {
+		rs.unhealthy[url] = true
+	}
+	return true
+}
+
+// markDown skips the replica for the cooldown.
+func (rs *replicaSet) markDown(url string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.downUntil[url] = time.Now().Add(rs.cooldown)
+}
+
+// record marks the replica down if err is a fault.
+func (rs *replicaSet) record(url string, err error) bool {
+	if err == nil || !slices.Contains(rs.faults, metrics.ResultClass(err)) {
+		return false
+	}
+	rs.markDown(url)
+	return true
+}
+
+// next returns the next of the URLs by weighted round-robin.
+func (rs *replicaSet) next(urls []string) string {
+	return rs.balancer.Next(urls, rs.weight)
+}
+
+// pickReplica chooses the URL of the backend serving the next request of the client
+// session according to the backend's affinity and weights, skipping replicas after a
+// fault and while they fail the health checks.
+func (c *GatewayCapability) pickReplica(clientSession shared.ISession, serverID string, backend *config.Backend) string {
+	rs := c.replicaSet(serverID, backend)
+	if rs == nil {
+		return backend.URL
+	}
+	healthy := rs.healthy(backend.URLs())
+
+	switch backend.Affinity {
+	case config.BackendAffinitySession:
+		params := clientSession.GetParams()
+		if pinned, ok := LoadPinnedReplica(params, serverID); ok && slices.Contains(healthy, pinned) {
+			return pinned
+		}
+		url := rs.next(healthy)
+		SavePinnedReplica(params, serverID, url)
+		return url
+	case config.BackendAffinityHash:
+		return balancer.Rendezvous(clientSession.GetID(), healthy, rs.weight)
+	default:
+		return rs.next(healthy)
+	}
+}
+
+// replicaURL returns the URL the backend session is connected to.
+func replicaURL(session *client.Session) string {
+	if session == nil || session.Backend == nil || session.Backend.URL == nil {
+		return ""
+	}
+	return session.Backend.URL.String()
+}
+
+// recordReplicaResult marks the replica of the backend session down if err is a fault.
+func (c *GatewayCapability) recordReplicaResult(session *client.Session, err error) {
+	if err == nil || session == nil || session.Backend == nil {
+		return
+	}
+	backend, cfgErr := c.config.GetBackend(session.Backend.ID)
+	if cfgErr != nil {
+		return
+	}
+	if rs := c.replicaSet(session.Backend.ID, backend); rs != nil && rs.record(replicaURL(session), err) {
+		c.logger.Warn("Backend replica failed, skipping it for the cooldown", zap.String("serverID", session.Backend.ID), zap.String("replica", replicaURL(session)), zap.Error(err))
+	}
+}
+
+// markReplicaDown skips the replica of the backend session for the cooldown. It returns
+// false if the backend has no replicas.
+func (c *GatewayCapability) markReplicaDown(session *client.Session) bool {
+	if session == nil || session.Backend == nil {
+		return false
+	}
+	backend, err := c.config.GetBackend(session.Backend.ID)
+	if err != nil {
+		return false
+	}
+	rs := c.replicaSet(session.Backend.ID, backend)
+	if rs == nil {
+		return false
+	}
+	rs.markDown(replicaURL(session))
+	return true
+}
+
+// replicaFailed reports whether the backend session is connected to a replica that is
+// skipped after a fault, so the session has to be replaced.
+func (c *GatewayCapability) replicaFailed(session *client.Session) bool {
+	if session == nil || session.Backend == nil {
+		return false
+	}
+	backend, err := c.config.GetBackend(session.Backend.ID)
+	if err != nil {
+		return false
+	}
+	rs := c.replicaSet(session.Backend.ID, backend)
+	return rs != nil && rs.isDown(replicaURL(session))
+}
+
+// hasFailedReplica reports whether any of the sessions is connected to a failed replica.
+func (c *GatewayCapability) hasFailedReplica(sessions []*client.Session) bool {
+	return slices.ContainsFunc(sessions, c.replicaFailed)
+}
+
+// replicaSession returns an open backend session to the replica serving the request of
+// the client session, failing over to another replica if the chosen one cannot be
+// connected. listed is the session of the backend used for listings, reused when it is
+// connected to the chosen replica.
+func (c *GatewayCapability) replicaSession(clientSession shared.ISession, listed *client.Session, backend *config.Backend) (*client.Session, error) {
+	serverID := listed.Backend.ID
+	rs := c.replicaSet(serverID, backend)
+	logger := c.logger.With(zap.String("serverID", serverID))
+
+	var lastErr error
+	for attempt := 0; attempt < len(backend.URLs()); attempt++ {
+		url := c.pickReplica(clientSession, serverID, backend)
+		session := listed
+		if replicaURL(listed) != url {
+			session = c.pooledReplicaSession(clientSession, serverID, url, backend, logger)
+			if session == nil {
+				lastErr = fmt.Errorf("failed to create session for replica '%s'", url)
+				rs.markDown(url)
+				continue
+			}
+		}
+
+		lastErr = openWithin(session, replicaOpenTimeout)
+		if lastErr == nil {
+			return session, nil
+		}
+		logger.Warn("Failed to connect to backend replica, trying another one", zap.String("replica", url), zap.Error(lastErr))
+		rs.markDown(url)
+	}
+	return nil, fmt.Errorf("no replica of backend '%s' is available: %w", serverID, lastErr)
+}
+
+// pooledReplicaSession returns the backend session of the client session to the replica,
+// creating it if needed or if the stored one failed the handshake.
+func (c *GatewayCapability) pooledReplicaSession(clientSession shared.ISession, serverID, url string, backend *config.Backend, logger *zap.Logger) *client.Session {
+	params := clientSession.GetParams()
+	if session, ok := LoadReplicaSession(params, serverID, url); ok {
+		if session.HandshakeError() == nil {
+			return session
+		}
+		DeleteReplicaSession(params, serverID, url)
+	}
+	session := c.newBackendSessionTo(serverID, url, backend, clientSession, logger)
+	if session == nil {
+		return nil
+	}
+	if existing, loaded := SaveReplicaSession(params, serverID, url, session); loaded {
+		session.Close()
+		return existing
+	}
+	return session
+}
+
+// openWithin opens the session, waiting at most timeout for the handshake.
+func openWithin(session *client.Session, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-session.Open():
+		if err == nil {
+			err = session.HandshakeError()
+		}
+		return err
+	case <-timer.C:
+		return fmt.Errorf("connecting timed out after %s", timeout)
+	}
+}
@@ -0,0 +1,145 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newReplicaBackend returns a backend whose "whoami" tool replies with name.
+func newReplicaBackend(t *testing.T, name string) *fakeBackend {
+	fb := newFakeBackend(t)
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"whoami","inputSchema":{"type":"object"}}]}`), nil
+	})
+	fb.Handle("tools/call", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"content":[{"type":"text","text":"` + name + `"}]}`), nil
+	})
+	return fb
+}
+
+// startReplicatedGateway starts a gateway with backend "svc" served by replicas "a" and "b".
+func startReplicatedGateway(t *testing.T, affinity string) (string, map[string]*fakeBackend) {
+	t.Helper()
+	replicas := map[string]*fakeBackend{"a": newReplicaBackend(t, "a"), "b": newReplicaBackend(t, "b")}
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "svc").
+		WithBackend("svc", replicas["a"].URL()).
+		WithBackendReplicas("svc", affinity, replicas["b"].URL()).
+		Build(t)
+	return startTestGateway(t, cfg), replicas
+}
+
+// whoami returns the name of the replica serving the call, or "" if the call failed.
+func whoami(t *testing.T, session *client.Session) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallTool(ctx, "whoami", map[string]interface{}{})
+	if result.Error != nil {
+		return ""
+	}
+	return *result.Result.Content[0].Text
+}
+
+// replicasHit returns the replicas serving n calls of the session.
+func replicasHit(t *testing.T, session *client.Session, n int) map[string]int {
+	t.Helper()
+	hits := make(map[string]int)
+	for i := 0; i < n; i++ {
+		hits[whoami(t, session)]++
+	}
+	return hits
+}
+
+func TestSessionRequestsStickToReplica(t *testing.T) {
+	for _, affinity := range []string{"session", "hash"} {
+		t.Run(affinity, func(t *testing.T) {
+			gwURL, _ := startReplicatedGateway(t, affinity)
+			for i := 0; i < 3; i++ {
+				session := openGatewaySession(t, gwURL, "key-u")
+				if hits := replicasHit(t, session, 6); len(hits) != 1 || hits[""] != 0 {
+					t.Fatalf("Expected all requests of session %d to hit one replica, got %v", i+1, hits)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestsSpreadOverReplicasWithoutAffinity(t *testing.T) {
+	gwURL, _ := startReplicatedGateway(t, "none")
+	session := openGatewaySession(t, gwURL, "key-u")
+	if hits := replicasHit(t, session, 6); hits["a"] == 0 || hits["b"] == 0 {
+		t.Fatalf("Expected requests to be spread over both replicas, got %v", hits)
+	}
+}
+
+func TestSessionFailsOverFromUnhealthyReplica(t *testing.T) {
+	for _, affinity := range []string{"session", "hash"} {
+		t.Run(affinity, func(t *testing.T) {
+			gwURL, replicas := startReplicatedGateway(t, affinity)
+			session := openGatewaySession(t, gwURL, "key-u")
+			chosen := whoami(t, session)
+			if chosen == "" {
+				t.Fatal("First call failed")
+			}
+			other := map[string]string{"a": "b", "b": "a"}[chosen]
+
+			replicas[chosen].Server.CloseClientConnections()
+			replicas[chosen].Server.Close()
+
+			// The request running into the failure may fail, the following ones fail over
+			whoami(t, session)
+			if hits := replicasHit(t, session, 4); hits[other] != 4 {
+				t.Fatalf("Expected the session to fail over to replica %q, got %v", other, hits)
+			}
+		})
+	}
+}
+
+func TestRequestsFollowReplicaWeights(t *testing.T) {
+	replicas := map[string]*fakeBackend{"a": newReplicaBackend(t, "a"), "b": newReplicaBackend(t, "b")}
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "svc").
+		WithBackend("svc", replicas["a"].URL()).
+		WithBackendReplicas("svc", "none", replicas["b"].URL()).
+		WithBackendWeight("svc", replicas["a"].URL(), 3).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+	if hits := replicasHit(t, session, 12); hits["a"] != 9 || hits["b"] != 3 {
+		t.Fatalf("Expected the replicas to serve 9 and 3 requests by their weights, got %v", hits)
+	}
+}
+
+func TestUnhealthyReplicaExcludedUntilRecovered(t *testing.T) {
+	replicas := map[string]*fakeBackend{"a": newReplicaBackend(t, "a"), "b": newReplicaBackend(t, "b")}
+	replicas["b"].Handle("initialize", rejectInitialize)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "svc").
+		WithBackendHealthInterval("50ms").
+		WithBackend("svc", replicas["a"].URL()).
+		WithBackendReplicas("svc", "none", replicas["b"].URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+
+	// Give the health checks time to exclude b before any request could choose it
+	time.Sleep(500 * time.Millisecond)
+	session := openGatewaySession(t, gwURL, "key-u")
+	if hits := replicasHit(t, session, 6); hits["a"] != 6 {
+		t.Fatalf("Expected the replica failing its health checks to be excluded, got %v", hits)
+	}
+
+	replicas["b"].Handle("initialize", acceptInitialize)
+	deadline := time.Now().Add(10 * time.Second)
+	for whoami(t, session) != "b" {
+		if time.Now().After(deadline) {
+			t.Fatal("Recovered replica was not reinstated")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
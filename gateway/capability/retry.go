@@ -0,0 +1,187 @@
+package capability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/breaker"
+	"github.com/gate4ai/mcp/gateway/client"
+	serverextra "github.com/gate4ai/mcp/server/extra"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// withRetry runs call and repeats it while it fails with a JSON-RPC error code listed
+// in the backend's retry codes, waiting between attempts with exponential backoff capped
+// at config.MaxBackendRetryBackoff. The wait ends early with ctx.Err() if ctx is done.
+// Any other error, or the last retryable one once the attempts are used up, is returned.
+// The duration of all attempts and the final result are recorded in the metrics of method
+// and, if the backend has one, in its circuit breaker. While the breaker is open, call is
+// not run and a shared.JSONRPCErrorUnavailable error is returned. A fault of the final result
+// skips the replica of session, if the backend has replicas. All attempts are traced as
+// one span, a child of the span carried by ctx; call gets a context carrying it.
+func (c *GatewayCapability) withRetry(ctx context.Context, method string, session *client.Session, logger *zap.Logger, call func(ctx context.Context) error) error {
+	return c.withRetryBudget(ctx, method, session, logger, nil, call)
+}
+
+// withRetryBudget is withRetry taking each retry from budget, shared with the other
+// attempts of a hedged request. A nil budget allows the backend's retry attempts.
+func (c *GatewayCapability) withRetryBudget(ctx context.Context, method string, session *client.Session, logger *zap.Logger, budget *requestBudget, call func(ctx context.Context) error) (err error) {
+	serverID := session.Backend.ID
+	backend, err := c.config.GetBackend(serverID)
+	if err != nil {
+		backend = nil
+	}
+	cb := c.backendBreaker(serverID, backend)
+	if cb != nil {
+		if err := cb.Allow(); err != nil {
+			logger.Warn("Backend circuit breaker is open, rejecting request", zap.String("serverID", serverID), zap.String("method", method), zap.Error(err))
+			return unavailableError(serverID, err)
+		}
+	}
+
+	ctx, span := startBackendCall(ctx, method, serverID)
+	start := time.Now()
+	defer func() {
+		span.End(err)
+		if errors.Is(err, context.Canceled) {
+			return // Abandoned by the gateway, e.g. a hedged request answered by another replica
+		}
+		c.metrics.ObserveBackendRequest(method, serverID, time.Since(start), err)
+		c.recordReplicaResult(session, err)
+		if cb != nil && cb.Record(err) {
+			logger.Debug("Backend fault counted by circuit breaker", zap.String("serverID", serverID), zap.Error(err))
+		}
+	}()
+
+	if backend == nil || len(backend.RetryCodes) == 0 {
+		return call(ctx)
+	}
+	if budget == nil {
+		budget = newRequestBudget(retryAttempts(backend))
+	}
+	wait := backend.RetryBackoff
+	if wait <= 0 {
+		wait = config.DefaultBackendRetryBackoff
+	}
+
+	for retry := 1; ; retry++ {
+		err = call(ctx)
+		code, retryable := retryableCode(err, backend.RetryCodes)
+		if !retryable || !budget.take() {
+			return err
+		}
+		logger.Warn("Backend returned retryable error, retrying",
+			zap.String("serverID", serverID),
+			zap.Int("code", code),
+			zap.Int("retry", retry),
+			zap.Duration("delay", wait))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err() // The request was cancelled or timed out
+		case <-c.ctx.Done():
+			timer.Stop()
+			return err
+		}
+		wait = min(wait*2, config.MaxBackendRetryBackoff)
+	}
+}
+
+// retryAttempts returns the number of retries the backend allows per request.
+func retryAttempts(backend *config.Backend) int {
+	if backend.RetryAttempts <= 0 {
+		return config.DefaultBackendRetryAttempts
+	}
+	return backend.RetryAttempts
+}
+
+// retryableCode reports whether err carries a JSON-RPC error with one of the codes.
+func retryableCode(err error, codes []int) (int, bool) {
+	var rpcErr *shared.JSONRPCError
+	if err == nil || !errors.As(err, &rpcErr) {
+		return 0, false
+	}
+	for _, code := range codes {
+		if rpcErr.Code == code {
+			return code, true
+		}
+	}
+	return rpcErr.Code, false
+}
+
+// unavailableError is the error a request to a backend with an open circuit breaker
+// fails with, sent to the client as is.
+func unavailableError(serverID string, err error) *shared.JSONRPCError {
+	var retryIn time.Duration
+	var openErr *breaker.OpenError
+	if errors.As(err, &openErr) {
+		retryIn = openErr.RetryIn
+	}
+	retryAfterMs := (retryIn + time.Millisecond - 1).Milliseconds() // Rounded up
+	return &shared.JSONRPCError{
+		Code:    shared.JSONRPCErrorUnavailable,
+		Message: fmt.Sprintf("Backend '%s' unavailable: %v", serverID, err),
+		Data:    map[string]any{"backend": serverID, "retryAfterMs": retryAfterMs},
+	}
+}
+
+// unavailable returns err if it is the error of an open circuit breaker.
+func unavailable(err error) (*shared.JSONRPCError, bool) {
+	rpcErr, ok := err.(*shared.JSONRPCError)
+	return rpcErr, ok && rpcErr.Code == shared.JSONRPCErrorUnavailable
+}
+
+// backendBreaker is a circuit breaker together with the settings it was created with.
+type backendBreaker struct {
+	*breaker.Breaker
+	settings string
+}
+
+// CircuitBreakers returns the state of the circuit breakers in use, by backend ID. A
+// breaker is in use once a request was sent to a backend configured with one.
+func (c *GatewayCapability) CircuitBreakers() map[string]serverextra.CircuitBreakerStatus {
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+	states := make(map[string]serverextra.CircuitBreakerStatus, len(c.breakers))
+	for serverID, cb := range c.breakers {
+		if backend, err := c.config.GetBackend(serverID); err != nil || backend.BreakerThreshold <= 0 {
+			continue // Removed from the configuration or disabled since
+		}
+		status := cb.Status()
+		states[serverID] = serverextra.CircuitBreakerStatus{
+			State:     string(status.State),
+			Failures:  status.Failures,
+			RetryInMs: (status.RetryIn + time.Millisecond - 1).Milliseconds(),
+		}
+	}
+	return states
+}
+
+// backendBreaker returns the circuit breaker of the backend, or nil if it has none. The
+// breaker is recreated, closed, when its configuration changes.
+func (c *GatewayCapability) backendBreaker(serverID string, backend *config.Backend) *breaker.Breaker {
+	if backend == nil || backend.BreakerThreshold <= 0 {
+		return nil
+	}
+	cooldown := backend.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = config.DefaultBackendBreakerCooldown
+	}
+	settings := fmt.Sprint(backend.BreakerThreshold, cooldown, backend.BreakerFaults)
+
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+	if existing, ok := c.breakers[serverID]; ok && existing.settings == settings {
+		return existing.Breaker
+	}
+	cb := breaker.New(backend.BreakerThreshold, cooldown, backend.BreakerFaults)
+	c.breakers[serverID] = &backendBreaker{Breaker: cb, settings: settings}
+	return cb
+}
@@ -0,0 +1,111 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+const codeInitializing = -32002
+
+// newFlakyBackend returns a backend whose tools/call fails with code for the first
+// failures calls and then succeeds. The counter reports the number of calls received.
+func newFlakyBackend(t *testing.T, code int, failures int32) (*fakeBackend, *atomic.Int32) {
+	fb := newFakeBackend(t)
+	var calls atomic.Int32
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"flaky","inputSchema":{"type":"object"}}]}`), nil
+	})
+	fb.Handle("tools/call", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		if calls.Add(1) <= failures {
+			return nil, &shared.JSONRPCError{Code: code, Message: "service initializing"}
+		}
+		return json.RawMessage(`{"content":[{"type":"text","text":"ready"}]}`), nil
+	})
+	return fb, &calls
+}
+
+func callFlaky(t *testing.T, code int, failures int32, attempts int) (int32, error) {
+	t.Helper()
+	fb, calls := newFlakyBackend(t, code, failures)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "flaky-backend").
+		WithBackend("flaky-backend", fb.URL()).
+		WithBackendRetry("flaky-backend", []int{codeInitializing}, attempts, "10ms").
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallTool(ctx, "flaky", map[string]interface{}{})
+	return calls.Load(), result.Error
+}
+
+func TestRetryOnConfiguredErrorCode(t *testing.T) {
+	calls, err := callFlaky(t, codeInitializing, 2, 3)
+	if err != nil {
+		t.Fatalf("Expected the call to succeed after retries, got: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("Expected 3 backend calls, got %d", calls)
+	}
+}
+
+func TestRetryStopsAfterAttempts(t *testing.T) {
+	calls, err := callFlaky(t, codeInitializing, 10, 1)
+	if err == nil {
+		t.Fatalf("Expected the call to fail once retries are exhausted")
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 backend calls (1 retry), got %d", calls)
+	}
+}
+
+func TestNoRetryOnUnlistedErrorCode(t *testing.T) {
+	calls, err := callFlaky(t, -32050, 1, 3)
+	if err == nil {
+		t.Fatalf("Expected the unlisted error to be returned")
+	}
+	if calls != 1 {
+		t.Fatalf("Expected a single backend call, got %d", calls)
+	}
+}
+
+func TestRetryWaitEndsWhenRequestIsCancelled(t *testing.T) {
+	fb, calls := newFlakyBackend(t, codeInitializing, 10)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "flaky-backend").
+		WithBackend("flaky-backend", fb.URL()).
+		WithBackendRetry("flaky-backend", []int{codeInitializing}, 3, "300ms").
+		Build(t)
+	mcpURL := strings.TrimSuffix(startTestGateway(t, cfg), "/sse") + "/mcp"
+	waitListening(t, strings.TrimSuffix(mcpURL, "/mcp")+"/status")
+	sessionID := initializeMCP(t, mcpURL)
+
+	go func() {
+		resp := postMCP(t, mcpURL, sessionID, "application/json",
+			`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"flaky","arguments":{}}}`)
+		resp.Body.Close()
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("The tool call did not reach the backend")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	resp := postMCP(t, mcpURL, sessionID, "application/json",
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2,"reason":"user gave up"}}`)
+	resp.Body.Close()
+
+	time.Sleep(700 * time.Millisecond) // Past the first retry
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("Expected the cancelled request not to be retried, got %d backend calls", got)
+	}
+}
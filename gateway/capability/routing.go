@@ -0,0 +1,81 @@
+package capability
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// routeUntargeted chooses the backend for a tool/prompt name or resource URI that does not
+// match any item in the combined list. The target is resolved in this order:
+//  1. for tools and prompts, if names are listed prefixed, the "<backendID><separator>"
+//     prefix of one of the user's backends, or else an explicit "<backendID>:<name>"
+//     prefix naming one of the user's backends,
+//  2. the user's configured default backend,
+//  3. the only backend the user may access.
+//
+// With several backends and no default the request is rejected, asking the client to
+// specify the backend. Only backends the user may access (see config.UserBackends) can be
+// selected; a prefix naming another configured backend is rejected as access denied.
+// Returns the backend ID and the name to send to that backend.
+func (c *GatewayCapability) routeUntargeted(clientSession shared.ISession, kind string, name string, logger *zap.Logger) (string, string, error) {
+	userID := transport.GetUserId(clientSession.GetParams())
+	if userID == "" {
+		return "", "", fmt.Errorf("%s not found: %s", kind, name)
+	}
+	subscribes, err := config.UserBackends(c.config, userID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get user server subscriptions for user '%s': %w", userID, err)
+	}
+	isSubscribed := func(serverID string) bool {
+		return slices.Contains(subscribes, serverID)
+	}
+
+	if separator, ok := c.nameSeparator(); ok && kind != "resource" {
+		// Backend IDs may contain the separator, so the prefix is matched against them
+		for _, serverID := range subscribes {
+			if originalName, found := strings.CutPrefix(name, serverID+separator); found && originalName != "" {
+				logger.Debug("Routing by backend name prefix", zap.String("backendServerID", serverID))
+				return serverID, originalName, nil
+			}
+		}
+	}
+	if serverID, originalName, found := strings.Cut(name, ":"); found && originalName != "" {
+		if isSubscribed(serverID) {
+			logger.Debug("Routing by explicit backend prefix", zap.String("backendServerID", serverID))
+			return serverID, originalName, nil
+		}
+		if _, err := c.config.GetBackend(serverID); err == nil {
+			logger.Warn("Explicit backend prefix names a backend the user may not access", zap.String("backendServerID", serverID))
+			return "", "", accessDenied(serverID)
+		}
+	}
+
+	defaultBackend, err := c.config.GetUserDefaultBackend(userID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get default backend for user '%s': %w", userID, err)
+	}
+	if defaultBackend != "" {
+		if !isSubscribed(defaultBackend) {
+			logger.Warn("Configured default backend is not in user's subscriptions", zap.String("defaultBackend", defaultBackend))
+			return "", "", fmt.Errorf("%s not found: %s (default backend '%s' is not available to the user)", kind, name, defaultBackend)
+		}
+		logger.Debug("Routing to user's default backend", zap.String("backendServerID", defaultBackend))
+		return defaultBackend, name, nil
+	}
+
+	switch len(subscribes) {
+	case 0:
+		return "", "", fmt.Errorf("%s not found: %s (user has no backend subscriptions)", kind, name)
+	case 1:
+		logger.Debug("Routing to user's only backend", zap.String("backendServerID", subscribes[0]))
+		return subscribes[0], name, nil
+	default:
+		return "", "", fmt.Errorf("%s not found: %s (user is subscribed to %d backends and has no default backend; specify the target as '<backendID>:%s')", kind, name, len(subscribes), name)
+	}
+}
@@ -0,0 +1,122 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newUnlistedToolBackend returns a backend that lists no tools but answers any
+// tools/call with a text naming the backend and the called tool.
+func newUnlistedToolBackend(t *testing.T, name string) *fakeBackend {
+	fb := newFakeBackend(t)
+	fb.Handle("tools/call", func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		var p struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(params, &p)
+		text, _ := json.Marshal(name + "/" + p.Name)
+		return json.RawMessage(`{"content":[{"type":"text","text":` + string(text) + `}]}`), nil
+	})
+	return fb
+}
+
+func callUnlistedTool(t *testing.T, session *client.Session, name string) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallRaw(ctx, "tools/call", map[string]interface{}{"name": name, "arguments": map[string]interface{}{}})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	var decoded struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(result.Result, &decoded); err != nil || len(decoded.Content) != 1 {
+		t.Fatalf("Unexpected tools/call result: %s", result.Result)
+	}
+	return decoded.Content[0].Text, nil
+}
+
+func TestUntargetedRequestRoutesToSingleBackend(t *testing.T) {
+	fb := newUnlistedToolBackend(t, "only")
+
+	cfg := testutil.NewConfigBuilder().
+		WithUser("single", "key-single", "only").
+		WithBackend("only", fb.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-single")
+
+	text, err := callUnlistedTool(t, session, "hidden")
+	if err != nil {
+		t.Fatalf("Expected auto-routing to the only backend, got error: %v", err)
+	}
+	if text != "only/hidden" {
+		t.Fatalf("Unexpected routing result: %s", text)
+	}
+}
+
+func TestUntargetedRequestRoutesToConfiguredDefault(t *testing.T) {
+	first := newUnlistedToolBackend(t, "first")
+	second := newUnlistedToolBackend(t, "second")
+
+	cfg := testutil.NewConfigBuilder().
+		WithUser("default", "key-default", "first", "second").
+		WithUserDefaultBackend("default", "second").
+		WithBackend("first", first.URL()).
+		WithBackend("second", second.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-default")
+
+	text, err := callUnlistedTool(t, session, "hidden")
+	if err != nil {
+		t.Fatalf("Expected routing to the default backend, got error: %v", err)
+	}
+	if text != "second/hidden" {
+		t.Fatalf("Expected the configured default backend, got: %s", text)
+	}
+}
+
+func TestUntargetedRequestRejectedWhenAmbiguous(t *testing.T) {
+	first := newUnlistedToolBackend(t, "first")
+	second := newUnlistedToolBackend(t, "second")
+	forbidden := newUnlistedToolBackend(t, "forbidden")
+
+	cfg := testutil.NewConfigBuilder().
+		WithUser("multi", "key-multi", "first", "second").
+		WithBackend("first", first.URL()).
+		WithBackend("second", second.URL()).
+		WithBackend("forbidden", forbidden.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-multi")
+
+	_, err := callUnlistedTool(t, session, "hidden")
+	if err == nil {
+		t.Fatalf("Expected an error for an ambiguous untargeted request")
+	}
+	if !strings.Contains(err.Error(), "specify the target") {
+		t.Fatalf("Expected the error to ask for a target, got: %v", err)
+	}
+
+	// An explicit backend prefix resolves the ambiguity
+	text, err := callUnlistedTool(t, session, "first:hidden")
+	if err != nil {
+		t.Fatalf("Expected explicit routing to succeed, got error: %v", err)
+	}
+	if text != "first/hidden" {
+		t.Fatalf("Unexpected routing result: %s", text)
+	}
+
+	// Backends outside the user's subscriptions are never selected
+	if _, err := callUnlistedTool(t, session, "forbidden:hidden"); err == nil {
+		t.Fatalf("Expected a backend outside the subscriptions to be rejected")
+	}
+}
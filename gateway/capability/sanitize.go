@@ -0,0 +1,76 @@
+package capability
+
+import (
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+// sanitizeInbound reports whether text received from clients must be stripped of control sequences.
+func (c *GatewayCapability) sanitizeInbound() bool {
+	enabled, err := c.config.SanitizeInboundText()
+	if err != nil {
+		c.logger.Error("Failed to read inbound sanitization setting", zap.Error(err))
+		return false
+	}
+	return enabled
+}
+
+// sanitizeOutbound reports whether text returned to clients must be stripped of control sequences.
+func (c *GatewayCapability) sanitizeOutbound() bool {
+	enabled, err := c.config.SanitizeOutboundText()
+	if err != nil {
+		c.logger.Error("Failed to read outbound sanitization setting", zap.Error(err))
+		return false
+	}
+	return enabled
+}
+
+// sanitizeValue returns v with all strings (including nested ones) sanitized.
+func sanitizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return shared.SanitizeText(val)
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = sanitizeValue(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = sanitizeValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// sanitizeContent strips control sequences from text and embedded text resources in place.
+func sanitizeContent(content *schema.Content) {
+	if content.Text != nil {
+		text := shared.SanitizeText(*content.Text)
+		content.Text = &text
+	}
+	if res := content.Resource; res != nil && res.Text != nil {
+		text := shared.SanitizeText(*res.Text)
+		res.Text = &text
+	}
+}
+
+// sanitizeContents applies sanitizeContent to every element in place.
+func sanitizeContents(contents []schema.Content) {
+	for i := range contents {
+		sanitizeContent(&contents[i])
+	}
+}
+
+// sanitizeResourceContents strips control sequences from text resource contents in place.
+func sanitizeResourceContents(contents []schema.ResourceContent) {
+	for i := range contents {
+		if contents[i].Text != nil {
+			text := shared.SanitizeText(*contents[i].Text)
+			contents[i].Text = &text
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newEchoToolBackend returns a backend with an "echo" tool that returns its "text"
+// argument prefixed with an ANSI color sequence.
+func newEchoToolBackend(t *testing.T) *fakeBackend {
+	fb := newFakeBackend(t)
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"echo","inputSchema":{"type":"object"}}]}`), nil
+	})
+	fb.Handle("tools/call", func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		var p struct {
+			Arguments struct {
+				Text string `json:"text"`
+			} `json:"arguments"`
+		}
+		json.Unmarshal(params, &p)
+		text, _ := json.Marshal("\x1b[31m" + p.Arguments.Text)
+		return json.RawMessage(`{"content":[{"type":"text","text":` + string(text) + `}]}`), nil
+	})
+	return fb
+}
+
+func callEcho(t *testing.T, inbound, outbound bool, text string) string {
+	t.Helper()
+	fb := newEchoToolBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithSanitizeText(inbound, outbound).
+		WithUser("u", "key-u", "echo-backend").
+		WithBackend("echo-backend", fb.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallRaw(ctx, "tools/call", map[string]interface{}{"name": "echo", "arguments": map[string]interface{}{"text": text}})
+	if result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+	var decoded struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(result.Result, &decoded); err != nil || len(decoded.Content) != 1 {
+		t.Fatalf("Unexpected tools/call result: %s", result.Result)
+	}
+	return decoded.Content[0].Text
+}
+
+func TestSanitizeTextDisabledByDefault(t *testing.T) {
+	got := callEcho(t, false, false, "a\x1b]0;title\x07b")
+	if want := "\x1b[31ma\x1b]0;title\x07b"; got != want {
+		t.Fatalf("Expected text to pass unchanged, got %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeTextPerDirection(t *testing.T) {
+	// Inbound only: the argument is cleaned, the backend's color code reaches the client
+	got := callEcho(t, true, false, "line1\n\tline2\x1b]0;title\x07")
+	if want := "\x1b[31mline1\n\tline2"; got != want {
+		t.Fatalf("Inbound sanitization: got %q, want %q", got, want)
+	}
+
+	// Outbound only: the argument reaches the backend as is, the result is cleaned
+	got = callEcho(t, false, true, "line1\n\tline2\x1b[2K")
+	if want := "line1\n\tline2"; got != want {
+		t.Fatalf("Outbound sanitization: got %q, want %q", got, want)
+	}
+}
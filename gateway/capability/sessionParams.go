@@ -1,6 +1,7 @@
 package capability
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -13,6 +14,8 @@ const (
 	backendSessionsKey = "gw_backend_sessions"
 	clientSessionsKey  = "gw_client_sessions"
 	serverIDKey        = "gw_server_id"
+	pinnedReplicaKey   = "gw_pinned_replica:"  // + serverID
+	replicaSessionKey  = "gw_replica_session:" // + serverID + " " + replica URL
 )
 
 // SavedValue represents a cached value with its timestamp
@@ -55,6 +58,19 @@ func SaveClientSession(sessionParams *sync.Map, clientSession *mcp.Session) {
 	})
 }
 
+// BackendIDs returns the IDs of the backends the client session has sessions to, sorted.
+func BackendIDs(sessionParams *sync.Map) []string {
+	sessions, _, _ := LoadBackendSessions(sessionParams)
+	var ids []string
+	for _, session := range sessions {
+		if session != nil && session.Backend != nil && !slices.Contains(ids, session.Backend.ID) {
+			ids = append(ids, session.Backend.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
 // GetClientSession returns client session with timestamp and success indicator
 func GetClientSession(sessionParams *sync.Map) (*mcp.Session, time.Time, bool) {
 	savedValue, ok1 := sessionParams.Load(clientSessionsKey)
@@ -101,3 +117,58 @@ func GetServerID(sessionParams *sync.Map) (string, time.Time, bool) {
 
 	return serverID, saved.Timestamp, true
 }
+
+// SavePinnedReplica remembers the replica of the backend chosen for the client session.
+func SavePinnedReplica(sessionParams *sync.Map, serverID string, url string) {
+	sessionParams.Store(pinnedReplicaKey+serverID, &SavedValue{
+		Value:     url,
+		Timestamp: time.Now(),
+	})
+}
+
+// LoadPinnedReplica returns the replica of the backend chosen for the client session.
+func LoadPinnedReplica(sessionParams *sync.Map, serverID string) (string, bool) {
+	savedValue, ok := sessionParams.Load(pinnedReplicaKey + serverID)
+	if !ok {
+		return "", false
+	}
+	saved, ok := savedValue.(*SavedValue)
+	if !ok {
+		return "", false
+	}
+	url, ok := saved.Value.(string)
+	return url, ok
+}
+
+// SaveReplicaSession stores the backend session to a replica unless one is already
+// stored, which is then returned with loaded set.
+func SaveReplicaSession(sessionParams *sync.Map, serverID string, url string, session *client.Session) (*client.Session, bool) {
+	savedValue, loaded := sessionParams.LoadOrStore(replicaSessionKey+serverID+" "+url, &SavedValue{
+		Value:     session,
+		Timestamp: time.Now(),
+	})
+	if !loaded {
+		return session, false
+	}
+	existing, ok := savedValue.(*SavedValue).Value.(*client.Session)
+	return existing, ok
+}
+
+// LoadReplicaSession returns the backend session to a replica stored for the client session.
+func LoadReplicaSession(sessionParams *sync.Map, serverID string, url string) (*client.Session, bool) {
+	savedValue, ok := sessionParams.Load(replicaSessionKey + serverID + " " + url)
+	if !ok {
+		return nil, false
+	}
+	saved, ok := savedValue.(*SavedValue)
+	if !ok {
+		return nil, false
+	}
+	session, ok := saved.Value.(*client.Session)
+	return session, ok
+}
+
+// DeleteReplicaSession forgets the backend session to a replica stored for the client session.
+func DeleteReplicaSession(sessionParams *sync.Map, serverID string, url string) {
+	sessionParams.Delete(replicaSessionKey + serverID + " " + url)
+}
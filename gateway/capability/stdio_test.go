@@ -0,0 +1,144 @@
+package capability_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	schema2024 "github.com/gate4ai/mcp/shared/mcp/2024/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// fakeStdioBackendEnv makes the test binary run as a stdio MCP server, see
+// runFakeStdioBackend.
+const fakeStdioBackendEnv = "GATE4AI_FAKE_STDIO_BACKEND"
+
+// runFakeStdioBackend serves MCP over stdin and stdout. Its tools are "whoami",
+// returning the process ID and the GREETING environment variable, and "exit", making
+// the process exit without an answer.
+func runFakeStdioBackend() {
+	fmt.Fprintln(os.Stderr, "fake stdio backend ready")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Name string `json:"name"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil || len(req.ID) == 0 {
+			continue // Notifications and garbage
+		}
+		var result string
+		switch req.Method {
+		case "initialize":
+			result = `{"protocolVersion":"` + schema2024.PROTOCOL_VERSION + `","capabilities":{"tools":{}},"serverInfo":{"name":"fake-stdio","version":"0.0.1"}}`
+		case "tools/list":
+			result = `{"tools":[{"name":"whoami","inputSchema":{"type":"object"}},{"name":"exit","inputSchema":{"type":"object"}}]}`
+		case "tools/call":
+			if req.Params.Name == "exit" {
+				os.Exit(3)
+			}
+			text, _ := json.Marshal(fmt.Sprintf("%d %s", os.Getpid(), os.Getenv("GREETING")))
+			result = `{"content":[{"type":"text","text":` + string(text) + `}]}`
+		default:
+			fmt.Printf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"Method not found"}}`+"\n", req.ID)
+			continue
+		}
+		fmt.Printf(`{"jsonrpc":"2.0","id":%s,"result":%s}`+"\n", req.ID, result)
+	}
+}
+
+// callStdioTool calls a tool of the fake stdio backend and returns its text.
+func callStdioTool(t *testing.T, session *client.Session, name string) (string, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallTool(ctx, name, map[string]interface{}{})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return *result.Result.Content[0].Text, nil
+}
+
+func TestStdioBackendRestartsAfterExit(t *testing.T) {
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "local").
+		WithCommandBackend("local", os.Args[0], "-test.run=^$").
+		WithBackendEnv("local", fakeStdioBackendEnv, "1").
+		WithBackendEnv("local", "GREETING", "hello").
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	text, err := callStdioTool(t, session, "whoami")
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	pid, greeting, _ := strings.Cut(text, " ")
+	if pid == strconv.Itoa(os.Getpid()) || greeting != "hello" {
+		t.Fatalf("Expected the answer of a child process with the configured environment, got %q", text)
+	}
+
+	start := time.Now()
+	if _, err := callStdioTool(t, session, "exit"); err == nil || !strings.Contains(err.Error(), "backend process exited") {
+		t.Fatalf("Expected the call to fail with the exit of the process, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Expected the call to fail when the process exited, it took %v", elapsed)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		text, err := callStdioTool(t, session, "whoami")
+		if err == nil {
+			if restarted, _, _ := strings.Cut(text, " "); restarted == pid {
+				t.Fatalf("Expected a new process after the restart, got the old one %s", pid)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Backend process was not restarted: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestStdioBackendProcessStoppedWithSession(t *testing.T) {
+	backend, err := client.NewStdio("local", client.StdioCommand{
+		Args: []string{os.Args[0], "-test.run=^$"},
+		Env:  []string{fakeStdioBackendEnv + "=1"},
+	}, LOGGER)
+	if err != nil {
+		t.Fatalf("Failed to create stdio backend: %v", err)
+	}
+	session := backend.NewSession(context.Background(), nil, "")
+	if err := <-session.Open(); err != nil {
+		t.Fatalf("Failed to open stdio session: %v", err)
+	}
+	text, err := callStdioTool(t, session, "whoami")
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		t.Fatalf("Unexpected answer %q", text)
+	}
+
+	session.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Backend process %d still running after the session was closed", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,161 @@
+package capability
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/gateway/client"
+	"github.com/gate4ai/mcp/shared"
+	"go.uber.org/zap"
+)
+
+// subscriptionTimeout bounds the resources/subscribe and resources/unsubscribe requests
+// the gateway sends to backends.
+const subscriptionTimeout = 5 * time.Second
+
+const resourceSubscriptionsKey = "gw_resource_subscriptions"
+
+// resourceSubscription is a resource of a backend a client session subscribed to.
+type resourceSubscription struct {
+	serverID    string
+	originalURI string // Known by the backend
+}
+
+// resourceSubscriptions are the resources a client session subscribed to through the
+// gateway, by the URI the client subscribed with. Updates of other resources are not
+// relayed to the client, and the subscriptions are dropped on the backends when the
+// client session closes.
+type resourceSubscriptions struct {
+	mu    sync.Mutex
+	byURI map[string]resourceSubscription
+}
+
+// subscriptions returns the resource subscriptions of the client session. Once it has
+// any, they are unsubscribed on the backends when the session closes.
+func (c *GatewayCapability) subscriptions(clientSession shared.ISession) *resourceSubscriptions {
+	params := clientSession.GetParams()
+	value, loaded := params.LoadOrStore(resourceSubscriptionsKey, &resourceSubscriptions{byURI: make(map[string]resourceSubscription)})
+	subs := value.(*resourceSubscriptions)
+	if !loaded {
+		if closer, ok := clientSession.(interface{ OnClose(func()) }); ok {
+			closer.OnClose(func() { go c.unsubscribeAll(params, subs) })
+		}
+	}
+	return subs
+}
+
+// loadSubscriptions returns the resource subscriptions of the client session, nil if it
+// never subscribed to a resource.
+func loadSubscriptions(sessionParams *sync.Map) *resourceSubscriptions {
+	value, ok := sessionParams.Load(resourceSubscriptionsKey)
+	if !ok {
+		return nil
+	}
+	return value.(*resourceSubscriptions)
+}
+
+func (s *resourceSubscriptions) add(gatewayURI string, sub resourceSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byURI[gatewayURI] = sub
+}
+
+func (s *resourceSubscriptions) remove(gatewayURI string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.byURI, gatewayURI)
+}
+
+// get returns the subscription of the resource the client knows as gatewayURI.
+func (s *resourceSubscriptions) get(gatewayURI string) (resourceSubscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.byURI[gatewayURI]
+	return sub, ok
+}
+
+// gatewayURI returns the URI the client subscribed to the resource of the backend with.
+func (s *resourceSubscriptions) gatewayURI(serverID string, originalURI string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for gatewayURI, sub := range s.byURI {
+		if sub.serverID == serverID && sub.originalURI == originalURI {
+			return gatewayURI, true
+		}
+	}
+	return "", false
+}
+
+// ofServer returns the URIs known by the backend of the resources subscribed to on it.
+func (s *resourceSubscriptions) ofServer(serverID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var uris []string
+	for _, sub := range s.byURI {
+		if sub.serverID == serverID {
+			uris = append(uris, sub.originalURI)
+		}
+	}
+	return uris
+}
+
+// all removes and returns every subscription.
+func (s *resourceSubscriptions) all() []resourceSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]resourceSubscription, 0, len(s.byURI))
+	for gatewayURI, sub := range s.byURI {
+		subs = append(subs, sub)
+		delete(s.byURI, gatewayURI)
+	}
+	return subs
+}
+
+// restoreSubscriptions subscribes a new backend session of the client session, replacing
+// one that failed, to the resources the client subscribed to on its backend.
+func (c *GatewayCapability) restoreSubscriptions(clientSession shared.ISession, backendSession *client.Session) {
+	subs := loadSubscriptions(clientSession.GetParams())
+	if subs == nil {
+		return
+	}
+	uris := subs.ofServer(backendSession.Backend.ID)
+	if len(uris) == 0 {
+		return
+	}
+	go func() {
+		logger := c.logger.With(zap.String("server", backendSession.Backend.ID))
+		if err := <-backendSession.Open(); err != nil {
+			logger.Warn("Failed to open backend session to restore resource subscriptions", zap.Error(err))
+			return
+		}
+		for _, uri := range uris {
+			ctx, cancel := context.WithTimeout(c.ctx, subscriptionTimeout)
+			if err := backendSession.SubscribeResource(ctx, uri); err != nil {
+				logger.Warn("Failed to restore resource subscription", zap.String("originalURI", uri), zap.Error(err))
+			}
+			cancel()
+		}
+	}()
+}
+
+// unsubscribeAll drops the resource subscriptions of a closed client session on the
+// backends. Backend sessions are not created for it.
+func (c *GatewayCapability) unsubscribeAll(sessionParams *sync.Map, subs *resourceSubscriptions) {
+	backendSessions, _, _ := LoadBackendSessions(sessionParams)
+	for _, sub := range subs.all() {
+		logger := c.logger.With(zap.String("server", sub.serverID), zap.String("originalURI", sub.originalURI))
+		for _, session := range backendSessions {
+			if session == nil || session.Backend == nil || session.Backend.ID != sub.serverID {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(c.ctx, subscriptionTimeout)
+			if err := session.UnsubscribeResource(ctx, sub.originalURI); err != nil {
+				logger.Warn("Failed to drop resource subscription of closed client session", zap.Error(err))
+			} else {
+				logger.Debug("Dropped resource subscription of closed client session")
+			}
+			cancel()
+		}
+	}
+}
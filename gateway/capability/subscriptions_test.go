@@ -0,0 +1,93 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newSubscribableBackend returns a backend listing the resource "file:///forecast" and
+// channels receiving the URIs of its resources/subscribe and resources/unsubscribe requests.
+func newSubscribableBackend(t *testing.T) (fb *fakeBackend, subscribed, unsubscribed chan string) {
+	fb = newFakeBackend(t)
+	subscribed = make(chan string, 10)
+	unsubscribed = make(chan string, 10)
+	fb.Handle("resources/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"resources":[{"uri":"file:///forecast","name":"forecast"}]}`), nil
+	})
+	for method, uris := range map[string]chan string{"resources/subscribe": subscribed, "resources/unsubscribe": unsubscribed} {
+		fb.Handle(method, func(params json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+			var request schema.SubscribeRequestParams
+			json.Unmarshal(params, &request)
+			uris <- request.URI
+			return json.RawMessage(`{}`), nil
+		})
+	}
+	return fb, subscribed, unsubscribed
+}
+
+// expectURI waits for a URI on uris.
+func expectURI(t *testing.T, uris chan string, want string, what string) {
+	t.Helper()
+	select {
+	case uri := <-uris:
+		if uri != want {
+			t.Fatalf("Expected %s of %s, got %s", what, want, uri)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("No %s of %s", what, want)
+	}
+}
+
+func TestResourceSubscriptionsRelayed(t *testing.T) {
+	fb, subscribed, unsubscribed := newSubscribableBackend(t)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "b1").
+		WithBackend("b1", fb.URL()).
+		Build(t)
+	gwURL := startTestGateway(t, cfg)
+	session := openGatewaySession(t, gwURL, "key-u")
+	updates := make(chan string, 10)
+	session.SubscribeOnResourceUpdated(func(msg *shared.Message) {
+		var params schema.ResourceUpdatedNotificationParams
+		json.Unmarshal(*msg.Params, &params)
+		updates <- params.URI
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := session.SubscribeResource(ctx, "file:///forecast"); err != nil {
+		t.Fatalf("Subscribing failed: %v", err)
+	}
+	expectURI(t, subscribed, "file:///forecast", "backend subscription")
+
+	// Only updates of subscribed resources reach the client
+	fb.NotifyWithParams("notifications/resources/updated", `{"uri":"file:///other"}`)
+	fb.NotifyWithParams("notifications/resources/updated", `{"uri":"file:///forecast"}`)
+	expectURI(t, updates, "file:///forecast", "update")
+
+	if err := session.UnsubscribeResource(ctx, "file:///forecast"); err != nil {
+		t.Fatalf("Unsubscribing failed: %v", err)
+	}
+	expectURI(t, unsubscribed, "file:///forecast", "backend unsubscription")
+	fb.NotifyWithParams("notifications/resources/updated", `{"uri":"file:///forecast"}`)
+	select {
+	case uri := <-updates:
+		t.Fatalf("Update of %s relayed after unsubscribing", uri)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// A client disconnecting drops its subscriptions on the backend
+	other := openGatewaySession(t, gwURL, "key-u")
+	if err := other.SubscribeResource(ctx, "file:///forecast"); err != nil {
+		t.Fatalf("Subscribing failed: %v", err)
+	}
+	expectURI(t, subscribed, "file:///forecast", "backend subscription")
+	other.Close()
+	expectURI(t, unsubscribed, "file:///forecast", "backend unsubscription on disconnect")
+}
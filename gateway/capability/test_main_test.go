@@ -21,6 +21,10 @@ var (
 )
 
 func TestMain(m *testing.M) {
+	if os.Getenv(fakeStdioBackendEnv) != "" {
+		runFakeStdioBackend()
+		os.Exit(0)
+	}
 	var exitCode int
 	defer func() {
 		os.Exit(exitCode)
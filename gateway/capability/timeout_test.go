@@ -0,0 +1,54 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newSlowBackend returns a backend whose tool answers after delay.
+func newSlowBackend(t *testing.T, toolName string, delay time.Duration) *fakeBackend {
+	fb := newFakeBackend(t)
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		return json.RawMessage(`{"tools":[{"name":"` + toolName + `","inputSchema":{"type":"object"}}]}`), nil
+	})
+	fb.Handle("tools/call", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		time.Sleep(delay)
+		return json.RawMessage(`{"content":[{"type":"text","text":"done"}]}`), nil
+	})
+	return fb
+}
+
+func TestBackendTimeoutBoundsRequests(t *testing.T) {
+	fast := newSlowBackend(t, "lookup", 500*time.Millisecond)
+	llm := newSlowBackend(t, "generate", 500*time.Millisecond)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "fast", "llm").
+		WithBackend("fast", fast.URL()).
+		WithBackendTimeout("fast", "100ms").
+		WithBackend("llm", llm.URL()).
+		WithBackendTimeout("llm", "5s").
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	result := <-session.CallTool(ctx, "lookup", map[string]interface{}{})
+	if result.Error == nil {
+		t.Fatal("Expected the call to the fast backend to time out")
+	}
+	if elapsed := time.Since(start); elapsed >= 450*time.Millisecond {
+		t.Errorf("Call to the fast backend failed after %v, want about its 100ms timeout", elapsed)
+	}
+
+	result = <-session.CallTool(ctx, "generate", map[string]interface{}{})
+	if result.Error != nil {
+		t.Fatalf("Expected the call to the slow backend to finish within its timeout, got: %v", result.Error)
+	}
+}
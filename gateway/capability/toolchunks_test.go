@@ -0,0 +1,144 @@
+package capability_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server"
+	serverCapability "github.com/gate4ai/mcp/server/mcp/capability"
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/gate4ai/mcp/tests"
+	"go.uber.org/zap"
+)
+
+const streamedChunks = 50
+
+// startStreamingServer starts an MCP server with a "document" tool that streams a large
+// text result in chunks and returns its SSE URL. Chunked reports, per call, whether the
+// client asked for chunks.
+func startStreamingServer(t *testing.T) (url string, chunked func() []bool) {
+	t.Helper()
+	port, err := tests.FindAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to find available port: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cfg := testutil.NewConfigBuilder().WithAuthorization("none").Build(t)
+	tools, _, _, _, err := server.StartServer(ctx, LOGGER.With(zap.String("s", t.Name()+"-server")), cfg, fmt.Sprintf(":%d", port))
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	var mu sync.Mutex
+	var calls []bool
+	err = tools.AddStreamingTool("document", "Returns a large document", &schema.JSONSchemaProperty{Type: "object"}, nil,
+		func(msg *shared.Message, args schema.Arguments, stream *serverCapability.ToolResultStream) (*schema.Meta, []schema.Content, error) {
+			mu.Lock()
+			calls = append(calls, stream.Chunked())
+			mu.Unlock()
+			for i := 0; i < streamedChunks; i++ {
+				stream.Send(schema.NewTextContent(documentLine(i))...)
+			}
+			return nil, schema.NewTextContent("end"), nil
+		})
+	if err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+	return fmt.Sprintf("http://localhost:%d/sse", port), func() []bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]bool(nil), calls...)
+	}
+}
+
+func documentLine(i int) string {
+	return fmt.Sprintf("line %03d %s", i, strings.Repeat("x", 1000))
+}
+
+// assertDocument checks that the content is the whole document, in order.
+func assertDocument(t *testing.T, content []schema.Content) {
+	t.Helper()
+	if len(content) != streamedChunks+1 {
+		t.Fatalf("Expected %d content items, got %d", streamedChunks+1, len(content))
+	}
+	for i := 0; i < streamedChunks; i++ {
+		if content[i].Text == nil || *content[i].Text != documentLine(i) {
+			t.Fatalf("Content item %d out of order or corrupted", i)
+		}
+	}
+	if *content[streamedChunks].Text != "end" {
+		t.Fatalf("Expected the returned content last, got %q", *content[streamedChunks].Text)
+	}
+}
+
+func TestChunkedToolResultIsReassembled(t *testing.T) {
+	url, chunked := startStreamingServer(t)
+	session := openGatewaySession(t, url, "")
+
+	var mu sync.Mutex
+	var received []string
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := <-session.CallToolWithChunks(ctx, "document", map[string]interface{}{}, func(content []schema.Content) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, *content[0].Text)
+	})
+	if result.Error != nil {
+		t.Fatalf("Tool call failed: %v", result.Error)
+	}
+	if calls := chunked(); len(calls) != 1 || !calls[0] {
+		t.Fatalf("Expected the result to be streamed in chunks, got %v", calls)
+	}
+	assertDocument(t, result.Result.Content)
+	if result.Result.Meta != nil {
+		t.Errorf("Expected the chunk count to be removed from _meta, got %v", *result.Result.Meta)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != streamedChunks {
+		t.Fatalf("Expected %d chunks passed to the callback, got %d", streamedChunks, len(received))
+	}
+	for i, text := range received {
+		if text != documentLine(i) {
+			t.Fatalf("Chunk %d passed to the callback out of order", i)
+		}
+	}
+}
+
+func TestChunkedToolResultThroughGateway(t *testing.T) {
+	for _, passthrough := range []bool{false, true} {
+		t.Run(fmt.Sprintf("passthrough=%v", passthrough), func(t *testing.T) {
+			url, chunked := startStreamingServer(t)
+			builder := testutil.NewConfigBuilder().
+				WithUser("u", "key-u", "streaming").
+				WithBackend("streaming", url)
+			if passthrough {
+				builder.WithBackendPassthrough("streaming")
+			}
+			session := openGatewaySession(t, startTestGateway(t, builder.Build(t)), "key-u")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			result := <-session.CallTool(ctx, "document", map[string]interface{}{})
+			if result.Error != nil {
+				t.Fatalf("Tool call failed: %v", result.Error)
+			}
+			assertDocument(t, result.Result.Content)
+
+			// The gateway reassembles chunks itself; relaying the client's raw params
+			// must not make the backend stream to the gateway
+			if calls := chunked(); len(calls) != 1 || calls[0] == passthrough {
+				t.Fatalf("Expected chunked=%v at the backend, got %v", !passthrough, calls)
+			}
+		})
+	}
+}
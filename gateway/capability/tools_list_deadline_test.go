@@ -0,0 +1,91 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+// newToolsBackend returns a backend listing a single tool after delay.
+func newToolsBackend(t *testing.T, toolName string, delay time.Duration) *fakeBackend {
+	fb := newFakeBackend(t)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	fb.Handle("tools/list", func(json.RawMessage) (json.RawMessage, *shared.JSONRPCError) {
+		select {
+		case <-time.After(delay):
+		case <-release:
+		}
+		return json.RawMessage(`{"tools":[{"name":"` + toolName + `","inputSchema":{"type":"object"}}]}`), nil
+	})
+	return fb
+}
+
+func listToolsRaw(t *testing.T, cfg config.IConfig) (names []string, meta map[string]interface{}, elapsed time.Duration) {
+	t.Helper()
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-dl")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	start := time.Now()
+	result := <-session.CallRaw(ctx, "tools/list", map[string]interface{}{})
+	elapsed = time.Since(start)
+	if result.Error != nil {
+		t.Fatalf("tools/list failed: %v", result.Error)
+	}
+	var decoded struct {
+		Meta  map[string]interface{} `json:"_meta"`
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(result.Result, &decoded); err != nil {
+		t.Fatalf("Invalid tools/list result: %v", err)
+	}
+	for _, tool := range decoded.Tools {
+		names = append(names, tool.Name)
+	}
+	return names, decoded.Meta, elapsed
+}
+
+func deadlineConfig(t *testing.T, deadline string) *config.YamlConfig {
+	fast := newToolsBackend(t, "fast_tool", 0)
+	slow := newToolsBackend(t, "slow_tool", 3*time.Second)
+	return testutil.NewConfigBuilder().
+		WithToolsListDeadline(deadline).
+		WithUser("dl", "key-dl", "fast", "slow").
+		WithBackend("fast", fast.URL()).
+		WithBackend("slow", slow.URL()).
+		Build(t)
+}
+
+func TestToolsListDeadlineReturnsPartialResult(t *testing.T) {
+	names, meta, elapsed := listToolsRaw(t, deadlineConfig(t, "300ms"))
+
+	if len(names) != 1 || names[0] != "fast_tool" {
+		t.Fatalf("Expected only the fast backend's tool, got %v", names)
+	}
+	if elapsed > 2*time.Second {
+		t.Fatalf("tools/list waited for the slow backend: %v", elapsed)
+	}
+	degraded, _ := meta["gate4ai/degradedBackends"].([]interface{})
+	if len(degraded) != 1 || degraded[0] != "slow" {
+		t.Fatalf("Expected the slow backend to be marked degraded, got _meta %v", meta)
+	}
+}
+
+func TestToolsListWithoutDeadlineWaitsForAllBackends(t *testing.T) {
+	names, meta, _ := listToolsRaw(t, deadlineConfig(t, ""))
+
+	if len(names) != 2 {
+		t.Fatalf("Expected tools of both backends, got %v", names)
+	}
+	if _, ok := meta["gate4ai/degradedBackends"]; ok {
+		t.Fatalf("No backend should be degraded, got _meta %v", meta)
+	}
+}
@@ -0,0 +1,181 @@
+package capability_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/gate4ai/mcp/shared/tracing"
+)
+
+// exportedSpan is the part of an OTLP/HTTP JSON span the tests look at.
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Kind         int    `json:"kind"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+}
+
+func (s exportedSpan) attribute(key string) string {
+	for _, attribute := range s.Attributes {
+		if attribute.Key == key {
+			return attribute.Value.StringValue
+		}
+	}
+	return ""
+}
+
+// spanCollector is a fake OTLP/HTTP collector.
+type spanCollector struct {
+	URL   string
+	mu    sync.Mutex
+	spans []exportedSpan
+}
+
+func newSpanCollector(t *testing.T) *spanCollector {
+	t.Helper()
+	c := &spanCollector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []exportedSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	c.URL = server.URL
+	return c
+}
+
+// waitForSpan returns the first span received with the name and kind, waiting for the
+// gateway to export it.
+func (c *spanCollector) waitForSpan(t *testing.T, name string, kind tracing.SpanKind) exportedSpan {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.mu.Lock()
+		for _, span := range c.spans {
+			if span.Name == name && span.Kind == int(kind) {
+				c.mu.Unlock()
+				return span
+			}
+		}
+		c.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("No %q span of kind %d was exported", name, kind)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestToolCallTracedToBackend(t *testing.T) {
+	collector := newSpanCollector(t)
+	fb, _ := newFlakyBackend(t, codeInitializing, 0)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "b1").
+		WithBackend("b1", fb.URL()).
+		WithTracing(collector.URL, 1).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	// The client's own span, never ended, is the parent of the gateway's
+	clientTracer, err := tracing.New(collector.URL, "test-client", 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := clientTracer.Start("call flaky", tracing.KindClient, "")
+	traceID := strings.Split(parent.Traceparent(), "-")[1]
+
+	ctx, cancel := context.WithTimeout(tracing.ContextWithSpan(context.Background(), parent), 10*time.Second)
+	defer cancel()
+	if result := <-session.CallTool(ctx, "flaky", map[string]interface{}{}); result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+
+	server := collector.waitForSpan(t, "tools/call", tracing.KindServer)
+	if server.TraceID != traceID || server.ParentSpanID != strings.Split(parent.Traceparent(), "-")[2] {
+		t.Errorf("Expected the request span to continue the client's trace, got trace %s parent %s", server.TraceID, server.ParentSpanID)
+	}
+	if got := server.attribute(tracing.AttrMethod); got != "tools/call" {
+		t.Errorf("Expected the method attribute of the request span, got %q", got)
+	}
+
+	backend := collector.waitForSpan(t, "tools/call", tracing.KindClient)
+	if backend.TraceID != traceID || backend.ParentSpanID != server.SpanID {
+		t.Errorf("Expected the backend span to be a child of the request span, got trace %s parent %s", backend.TraceID, backend.ParentSpanID)
+	}
+	if got := backend.attribute(tracing.AttrBackend); got != "b1" {
+		t.Errorf("Expected the backend attribute of the backend span, got %q", got)
+	}
+	if got, want := fb.Header("tools/call").Get(tracing.TraceparentHeader), "00-"+traceID+"-"+backend.SpanID+"-01"; got != want {
+		t.Errorf("Expected the backend to receive traceparent %q, got %q", want, got)
+	}
+}
+
+func TestSSEStreamSpanEndsWithStream(t *testing.T) {
+	collector := newSpanCollector(t)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u").
+		WithTracing(collector.URL, 1).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	time.Sleep(1500 * time.Millisecond) // Longer than the export delay
+	collector.mu.Lock()
+	for _, span := range collector.spans {
+		if span.Name == "SSE /sse" {
+			t.Errorf("Expected the stream span to end with the stream, it was exported while open")
+		}
+	}
+	collector.mu.Unlock()
+
+	session.Close()
+	stream := collector.waitForSpan(t, "SSE /sse", tracing.KindServer)
+	if got := stream.attribute(tracing.AttrTransport); got != "sse" {
+		t.Errorf("Expected the transport attribute of the stream span, got %q", got)
+	}
+}
+
+func TestTracingDisabledByDefault(t *testing.T) {
+	fb, _ := newFlakyBackend(t, codeInitializing, 0)
+	cfg := testutil.NewConfigBuilder().
+		WithUser("u", "key-u", "b1").
+		WithBackend("b1", fb.URL()).
+		Build(t)
+	session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if result := <-session.CallTool(ctx, "flaky", map[string]interface{}{}); result.Error != nil {
+		t.Fatalf("tools/call failed: %v", result.Error)
+	}
+	if got := fb.Header("tools/call").Get(tracing.TraceparentHeader); got != "" {
+		t.Errorf("Expected no traceparent sent to the backend without tracing, got %q", got)
+	}
+}
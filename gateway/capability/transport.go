@@ -0,0 +1,47 @@
+package capability
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gate4ai/mcp/shared/config"
+)
+
+// backendTransport is the HTTP client of a backend with the settings it was created with.
+type backendTransport struct {
+	settings string
+	client   *http.Client
+}
+
+// backendHTTPClient returns the HTTP client for requests to the backend. Its transport
+// closes keep-alive connections idle for longer than the backend's IdleConnTimeout, so
+// that a request after a quiet period does not run into a connection dropped by a NAT or
+// load balancer. The client is replaced when the settings change.
+func (c *GatewayCapability) backendHTTPClient(serverID string, backend *config.Backend) *http.Client {
+	idleConnTimeout := backend.IdleConnTimeout
+	if idleConnTimeout <= 0 {
+		idleConnTimeout = config.DefaultBackendIdleConnTimeout
+	}
+	maxIdleConns := backend.MaxIdleConns
+	if maxIdleConns <= 0 {
+		maxIdleConns = config.DefaultBackendMaxIdleConns
+	}
+	settings := fmt.Sprint(idleConnTimeout, maxIdleConns)
+
+	c.transportsMu.Lock()
+	defer c.transportsMu.Unlock()
+	existing, ok := c.transports[serverID]
+	if ok && existing.settings == settings {
+		return existing.client
+	}
+	if ok {
+		existing.client.CloseIdleConnections()
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.IdleConnTimeout = idleConnTimeout
+	transport.MaxIdleConnsPerHost = maxIdleConns
+	client := &http.Client{Transport: transport}
+	c.transports[serverID] = &backendTransport{settings: settings, client: client}
+	return client
+}
@@ -0,0 +1,50 @@
+package capability_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+func TestIdleBackendConnectionsAreClosed(t *testing.T) {
+	const idleTimeout = 200 * time.Millisecond
+	for _, tc := range []struct {
+		name       string
+		timeout    string
+		wantReaped bool
+	}{
+		{"configured", idleTimeout.String(), true},
+		{"default", "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := newReplicaBackend(t, "idle")
+			cfg := testutil.NewConfigBuilder().
+				WithUser("u", "key-u", "svc").
+				WithBackend("svc", fb.URL()).
+				WithBackendIdleConns("svc", tc.timeout, 0).
+				Build(t)
+			session := openGatewaySession(t, startTestGateway(t, cfg), "key-u")
+
+			if whoami(t, session) != "idle" {
+				t.Fatal("First call failed")
+			}
+			if _, idle := fb.Conns(); idle == 0 {
+				t.Fatal("Expected an idle keep-alive connection after the call")
+			}
+
+			time.Sleep(3 * idleTimeout)
+			opened, idle := fb.Conns()
+			if reaped := idle == 0; reaped != tc.wantReaped {
+				t.Fatalf("Expected idle connections reaped = %v, %d idle", tc.wantReaped, idle)
+			}
+
+			if whoami(t, session) != "idle" {
+				t.Fatal("Call after the idle period failed")
+			}
+			if reopened, _ := fb.Conns(); tc.wantReaped && reopened <= opened {
+				t.Fatalf("Expected a fresh connection after the idle period, %d opened before and after", opened)
+			}
+		})
+	}
+}
@@ -14,9 +14,10 @@ import (
 )
 
 type Backend struct {
-	ID     string
-	URL    *url.URL
-	Logger *zap.Logger
+	ID      string
+	URL     *url.URL      // Nil for stdio backends
+	Command *StdioCommand // Set for stdio backends, see NewStdio
+	Logger  *zap.Logger
 }
 
 // New creates a new MCP SSE client
@@ -47,17 +48,24 @@ func (backend *Backend) NewSession(ctx context.Context, httpClient *http.Client,
 	baseSession := shared.NewBaseSession(backend.Logger, input, nil)
 	baseSession.Logger.Debug("Creating new client session")
 
-	sseClient := sse.NewClient(backend.URL.String())
-	// Assign logger to SSE client if it supports it (optional, depends on library)
-	// sseClient.Logger = sessionLogger // Example, adjust based on sse/v2 capabilities
-
-	sseClient.Headers = map[string]string{
-		"Accept":        "text/event-stream",
-		"Cache-Control": "no-cache",
-		"Connection":    "keep-alive", // Good practice for SSE
-	}
-	if authorizationBearer != "" {
-		sseClient.Headers["Authorization"] = "Bearer " + authorizationBearer
+	// Stdio backends have neither an SSE stream nor a bearer
+	var sseClient *sse.Client
+	var processDone chan struct{}
+	if backend.Command != nil {
+		processDone = make(chan struct{})
+	} else {
+		sseClient = sse.NewClient(backend.URL.String())
+		// Assign logger to SSE client if it supports it (optional, depends on library)
+		// sseClient.Logger = sessionLogger // Example, adjust based on sse/v2 capabilities
+
+		sseClient.Headers = map[string]string{
+			"Accept":        "text/event-stream",
+			"Cache-Control": "no-cache",
+			"Connection":    "keep-alive", // Good practice for SSE
+		}
+		if authorizationBearer != "" {
+			sseClient.Headers["Authorization"] = "Bearer " + authorizationBearer
+		}
 	}
 
 	// Use default client if nil is provided
@@ -74,8 +82,9 @@ func (backend *Backend) NewSession(ctx context.Context, httpClient *http.Client,
 		sseClient:      sseClient,
 		httpClient:     httpClient,
 		sseCh:          make(chan *sse.Event, 100), // Consider buffer size
-		closeCh:        make(chan struct{}),        // Initialize close channel
-		initialization: nil,                        // Start as nil, set in Open()
+		processDone:    processDone,
+		closeCh:        make(chan struct{}), // Initialize close channel
+		initialization: nil,                 // Start as nil, set in Open()
 		tools:          make([]schema.Tool, 0),
 		prompts:        make([]schema.Prompt, 0),
 		resources:      make([]schema.Resource, 0),
@@ -85,15 +94,25 @@ func (backend *Backend) NewSession(ctx context.Context, httpClient *http.Client,
 	resourcesCap := capability.NewResourcesCapability(backend.Logger, clientSession)
 	resourceTemplatesCap := capability.NewResourceTemplatesCapability(backend.Logger, clientSession)
 	samplingCap := capability.NewSamplingCapability(backend.Logger)
+	toolChunksCap := capability.NewToolChunksCapability(backend.Logger)
+	progressCap := capability.NewProgressCapability(backend.Logger)
+	listChangedCap := capability.NewListChangedCapability(backend.Logger, clientSession)
+	listChangedCap.SubscribeOnListChanged(clientSession.forgetList)
 
 	input.AddClientCapability(
 		resourcesCap,
 		resourceTemplatesCap,
-		samplingCap)
+		samplingCap,
+		toolChunksCap,
+		progressCap,
+		listChangedCap)
 
 	clientSession.ResourcesCapability = resourcesCap
 	clientSession.ResourceTemplatesCapability = resourceTemplatesCap
 	clientSession.SamplingCapability = samplingCap
+	clientSession.ToolChunksCapability = toolChunksCap
+	clientSession.ProgressCapability = progressCap
+	clientSession.ListChangedCapability = listChangedCap
 
 	go input.Process()
 	baseSession.Logger.Info("Client session created")
@@ -0,0 +1,76 @@
+package capability
+
+import (
+	"sync"
+
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+// listChangedMethods maps the list_changed notifications to the list method whose
+// answer changed.
+var listChangedMethods = map[string]string{
+	"notifications/tools/list_changed":     "tools/list",
+	"notifications/prompts/list_changed":   "prompts/list",
+	"notifications/resources/list_changed": "resources/list",
+}
+
+// ListChangedFunc is called when the server notifies that the answer to a list method,
+// e.g. "tools/list", changed. It runs on the processing loop of the session.
+type ListChangedFunc func(session shared.ISession, method string)
+
+var _ shared.IClientCapability = (*ListChangedCapability)(nil)
+
+// ListChangedCapability passes the list_changed notifications of the server to the
+// registered functions.
+type ListChangedCapability struct {
+	logger      *zap.Logger
+	session     shared.ISession
+	mu          sync.RWMutex
+	subscribers []ListChangedFunc
+	handlers    map[string]func(*shared.Message) (interface{}, error)
+}
+
+// NewListChangedCapability creates a new ListChangedCapability.
+func NewListChangedCapability(logger *zap.Logger, session shared.ISession) *ListChangedCapability {
+	lc := &ListChangedCapability{
+		logger:  logger,
+		session: session,
+	}
+	lc.handlers = make(map[string]func(*shared.Message) (interface{}, error), len(listChangedMethods))
+	for notification := range listChangedMethods {
+		lc.handlers[notification] = lc.handleListChanged
+	}
+	return lc
+}
+
+// GetHandlers returns the map of method handlers for this capability.
+func (lc *ListChangedCapability) GetHandlers() map[string]func(*shared.Message) (interface{}, error) {
+	return lc.handlers
+}
+
+// SetCapabilities implements the IClientCapability interface. Servers announce
+// list_changed notifications, clients have nothing to announce.
+func (lc *ListChangedCapability) SetCapabilities(s *schema.ClientCapabilities) {}
+
+// SubscribeOnListChanged registers a function called for each list_changed notification.
+func (lc *ListChangedCapability) SubscribeOnListChanged(f ListChangedFunc) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.subscribers = append(lc.subscribers, f)
+}
+
+// handleListChanged handles the "notifications/*/list_changed" notifications.
+func (lc *ListChangedCapability) handleListChanged(msg *shared.Message) (interface{}, error) {
+	method := listChangedMethods[shared.NilIfNil(msg.Method)]
+	lc.logger.Debug("Server list changed", zap.String("method", method))
+
+	lc.mu.RLock()
+	subscribers := append([]ListChangedFunc(nil), lc.subscribers...)
+	lc.mu.RUnlock()
+	for _, f := range subscribers {
+		f(lc.session, method)
+	}
+	return nil, nil
+}
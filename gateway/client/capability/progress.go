@@ -0,0 +1,122 @@
+package capability
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+// progressQueueSize bounds the progress notifications of a request waiting to be passed
+// to its ProgressFunc; further ones are dropped until it catches up.
+const progressQueueSize = 64
+
+// ProgressFunc receives the progress notifications of a request in order.
+type ProgressFunc func(params schema.ProgressNotificationParams)
+
+var _ shared.IClientCapability = (*ProgressCapability)(nil)
+
+// ProgressCapability passes the progress notifications the server sends for running
+// requests to the function registered for their progress token.
+type ProgressCapability struct {
+	logger   *zap.Logger
+	tokens   atomic.Int64
+	mu       sync.Mutex
+	pending  map[string]*progressRelay // token -> relay of a running request
+	handlers map[string]func(*shared.Message) (interface{}, error)
+}
+
+// progressRelay passes the notifications of one request to its ProgressFunc from its own
+// goroutine, so the processing loop of the session never waits for it.
+type progressRelay struct {
+	queue chan schema.ProgressNotificationParams
+	done  chan struct{} // Closed once the queue is drained
+}
+
+// NewProgressCapability creates a new ProgressCapability.
+func NewProgressCapability(logger *zap.Logger) *ProgressCapability {
+	pc := &ProgressCapability{
+		logger:  logger,
+		pending: make(map[string]*progressRelay),
+	}
+	pc.handlers = map[string]func(*shared.Message) (interface{}, error){
+		shared.ProgressNotificationMethod: pc.handleProgress,
+	}
+	return pc
+}
+
+// GetHandlers returns the map of method handlers for this capability.
+func (pc *ProgressCapability) GetHandlers() map[string]func(*shared.Message) (interface{}, error) {
+	return pc.handlers
+}
+
+// SetCapabilities implements the IClientCapability interface. Progress is requested per
+// request, so there is nothing to announce.
+func (pc *ProgressCapability) SetCapabilities(s *schema.ClientCapabilities) {}
+
+// Expect returns a new progress token to send in the _meta of a request. Progress
+// notifications carrying it are passed to onProgress until Done is called.
+func (pc *ProgressCapability) Expect(onProgress ProgressFunc) string {
+	token := fmt.Sprintf("progress-%d", pc.tokens.Add(1))
+	relay := &progressRelay{
+		queue: make(chan schema.ProgressNotificationParams, progressQueueSize),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(relay.done)
+		for params := range relay.queue {
+			onProgress(params)
+		}
+	}()
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.pending[token] = relay
+	return token
+}
+
+// Done forgets the token once its request has completed. It returns after the
+// notifications received so far have been passed to the ProgressFunc, so the caller
+// can relay the response after them.
+func (pc *ProgressCapability) Done(token string) {
+	pc.mu.Lock()
+	relay, ok := pc.pending[token]
+	delete(pc.pending, token)
+	if ok {
+		close(relay.queue)
+	}
+	pc.mu.Unlock()
+	if ok {
+		<-relay.done
+	}
+}
+
+// handleProgress handles the "notifications/progress" notification.
+func (pc *ProgressCapability) handleProgress(msg *shared.Message) (interface{}, error) {
+	if msg.Params == nil {
+		return nil, fmt.Errorf("progress notification without params")
+	}
+	var params schema.ProgressNotificationParams
+	if err := json.Unmarshal(*msg.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid progress notification: %w", err)
+	}
+	token, _ := params.ProgressToken.(string)
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	relay, ok := pc.pending[token]
+	if !ok {
+		pc.logger.Debug("Received progress of unknown request", zap.Any("progressToken", params.ProgressToken))
+		return nil, nil
+	}
+	select {
+	case relay.queue <- params:
+	default:
+		pc.logger.Warn("Progress notifications arrive faster than relayed, dropping one", zap.String("progressToken", token))
+	}
+	return nil, nil
+}
@@ -22,6 +22,7 @@ type ResourcesCapability struct {
 	logger                     *zap.Logger
 	mu                         sync.RWMutex
 	resourceUpdatedSubscribers []ResourceUpdatedFunc
+	subscribed                 map[string]bool // URIs subscribed to on the server
 	handlers                   map[string]func(*shared.Message) (interface{}, error)
 	session                    shared.ISession // Reference to the parent session
 }
@@ -31,6 +32,7 @@ func NewResourcesCapability(logger *zap.Logger, session shared.ISession) *Resour
 	rc := &ResourcesCapability{
 		logger:                     logger,
 		resourceUpdatedSubscribers: []ResourceUpdatedFunc{},
+		subscribed:                 make(map[string]bool),
 		session:                    session,
 	}
 	rc.handlers = map[string]func(*shared.Message) (interface{}, error){
@@ -111,12 +113,13 @@ func (rc *ResourcesCapability) SubscribeResource(ctx context.Context, uri string
 		URI: uri,
 	}
 
-	go func() {
-		msg := <-rc.session.SendRequestSync("resources/subscribe", params)
-		if msg.Error != nil {
-			logger.Error("Error in subscribe response", zap.Error(msg.Error))
-		}
-	}()
+	if err := rc.request(ctx, "resources/subscribe", params); err != nil {
+		logger.Error("Error in subscribe response", zap.Error(err))
+		return err
+	}
+	rc.mu.Lock()
+	rc.subscribed[uri] = true
+	rc.mu.Unlock()
 	return nil
 }
 
@@ -134,13 +137,42 @@ func (rc *ResourcesCapability) UnsubscribeResource(ctx context.Context, uri stri
 	params := &schema.UnsubscribeRequestParams{
 		URI: uri,
 	}
-	go func() {
-		msg := <-rc.session.SendRequestSync("resources/unsubscribe", params)
+	rc.mu.Lock()
+	delete(rc.subscribed, uri)
+	rc.mu.Unlock()
+	if err := rc.request(ctx, "resources/unsubscribe", params); err != nil {
+		logger.Error("Error in unsubscribe response", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SubscribedResources returns the URIs subscribed to on the server, to subscribe to them
+// again after the session is initialized anew.
+func (rc *ResourcesCapability) SubscribedResources() []string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	uris := make([]string, 0, len(rc.subscribed))
+	for uri := range rc.subscribed {
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+// request sends a request to the server and waits for its response until ctx is done.
+func (rc *ResourcesCapability) request(ctx context.Context, method string, params interface{}) error {
+	select {
+	case msg := <-rc.session.SendRequestSync(method, params):
+		if msg == nil {
+			return errors.New("no response to " + method)
+		}
 		if msg.Error != nil {
-			logger.Error("Error in unsubscribe response", zap.Error(msg.Error))
+			return msg.Error
 		}
-	}()
-	return nil
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // handleResourceUpdated handles incoming "notifications/resources/updated" messages.
@@ -0,0 +1,147 @@
+package capability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gate4ai/mcp/shared"
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+// ToolChunkFunc receives the chunks of a tool result in order, as they arrive.
+type ToolChunkFunc func(content []schema.Content)
+
+var _ shared.IClientCapability = (*ToolChunksCapability)(nil)
+
+// ToolChunksCapability collects the chunks of tool results the server streams to the
+// client (see shared.ToolResultChunkMethod).
+type ToolChunksCapability struct {
+	logger   *zap.Logger
+	mu       sync.Mutex
+	pending  map[string]*ToolResultChunks // token -> chunks of a running call
+	handlers map[string]func(*shared.Message) (interface{}, error)
+}
+
+// NewToolChunksCapability creates a new ToolChunksCapability.
+func NewToolChunksCapability(logger *zap.Logger) *ToolChunksCapability {
+	tc := &ToolChunksCapability{
+		logger:  logger,
+		pending: make(map[string]*ToolResultChunks),
+	}
+	tc.handlers = map[string]func(*shared.Message) (interface{}, error){
+		shared.ToolResultChunkMethod: tc.handleToolResultChunk,
+	}
+	return tc
+}
+
+// GetHandlers returns the map of method handlers for this capability.
+func (tc *ToolChunksCapability) GetHandlers() map[string]func(*shared.Message) (interface{}, error) {
+	return tc.handlers
+}
+
+// SetCapabilities implements the IClientCapability interface. Chunked results are
+// requested per call, so there is nothing to announce.
+func (tc *ToolChunksCapability) SetCapabilities(s *schema.ClientCapabilities) {}
+
+// Expect registers the token of a call about to be sent. Chunks carrying it are passed
+// to onChunk (which may be nil) and kept for Wait until Done is called.
+func (tc *ToolChunksCapability) Expect(token string, onChunk ToolChunkFunc) *ToolResultChunks {
+	chunks := &ToolResultChunks{
+		onChunk:  onChunk,
+		received: make(map[int][]schema.Content),
+		arrived:  make(chan struct{}, 1),
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.pending[token] = chunks
+	return chunks
+}
+
+// Done forgets the token once its call has completed.
+func (tc *ToolChunksCapability) Done(token string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	delete(tc.pending, token)
+}
+
+// handleToolResultChunk handles the "notifications/tools/result_chunk" notification.
+func (tc *ToolChunksCapability) handleToolResultChunk(msg *shared.Message) (interface{}, error) {
+	if msg.Params == nil {
+		return nil, fmt.Errorf("tool result chunk without params")
+	}
+	var chunk shared.ToolResultChunk
+	if err := json.Unmarshal(*msg.Params, &chunk); err != nil {
+		return nil, fmt.Errorf("invalid tool result chunk: %w", err)
+	}
+
+	tc.mu.Lock()
+	chunks, ok := tc.pending[chunk.Token]
+	tc.mu.Unlock()
+	if !ok {
+		tc.logger.Warn("Received chunk of unknown tool result", zap.String("token", chunk.Token), zap.Int("index", chunk.Index))
+		return nil, nil
+	}
+	chunks.add(chunk.Index, chunk.Content)
+	return nil, nil
+}
+
+// ToolResultChunks holds the chunks received for one tool call. Notifications are
+// processed concurrently, so chunks may arrive out of order and after the result.
+type ToolResultChunks struct {
+	onChunk ToolChunkFunc
+
+	mu       sync.Mutex
+	received map[int][]schema.Content // index -> content
+	next     int                      // Index of the next chunk to pass to onChunk
+	arrived  chan struct{}            // Signaled when a chunk is added
+}
+
+// add stores a chunk and passes the chunks now in order to onChunk.
+func (c *ToolResultChunks) add(index int, content []schema.Content) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, dup := c.received[index]; dup || index < 0 {
+		return
+	}
+	c.received[index] = content
+	for ; ; c.next++ {
+		ready, ok := c.received[c.next]
+		if !ok {
+			break
+		}
+		if c.onChunk != nil {
+			c.onChunk(ready)
+		}
+	}
+	select {
+	case c.arrived <- struct{}{}:
+	default:
+	}
+}
+
+// Wait returns the content of the first count chunks in order, once they have all
+// arrived, or an error when ctx is done first.
+func (c *ToolResultChunks) Wait(ctx context.Context, count int) ([]schema.Content, error) {
+	for {
+		c.mu.Lock()
+		if c.next >= count {
+			var content []schema.Content
+			for i := 0; i < count; i++ {
+				content = append(content, c.received[i]...)
+			}
+			c.mu.Unlock()
+			return content, nil
+		}
+		next := c.next
+		c.mu.Unlock()
+
+		select {
+		case <-c.arrived:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("received %d of %d result chunks: %w", next, count, ctx.Err())
+		}
+	}
+}
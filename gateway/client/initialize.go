@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/gate4ai/mcp/shared"
@@ -19,6 +20,44 @@ var clientSupportedVersions = map[string]bool{
 // The latest version this client prefers and advertises
 const clientLatestVersion = schema.PROTOCOL_VERSION
 
+// HandshakeError reports that the backend failed the MCP initialize handshake: it
+// rejected the request, answered with an invalid result or negotiated a protocol version
+// the client does not support. The session cannot be used, Open keeps returning it.
+type HandshakeError struct {
+	Err error // Reason of the failure
+}
+
+func (e *HandshakeError) Error() string {
+	return "backend handshake failed: " + e.Err.Error()
+}
+
+func (e *HandshakeError) Unwrap() error {
+	return e.Err
+}
+
+// HandshakeError returns the error of a failed handshake, or nil if the handshake
+// succeeded or has not completed yet.
+func (s *Session) HandshakeError() error {
+	s.Locker.RLock()
+	defer s.Locker.RUnlock()
+	if s.handshakeErr == nil {
+		return nil
+	}
+	return s.handshakeErr
+}
+
+// failHandshake records the failure of the handshake, signals it to the callers of Open
+// and closes the session.
+func (s *Session) failHandshake(reason error) {
+	err := &HandshakeError{Err: reason}
+	s.BaseSession.Logger.Error("Backend handshake failed", zap.Error(err))
+	s.Locker.Lock()
+	s.handshakeErr = err
+	s.Locker.Unlock()
+	s.writeInitializationErrorAndClose(err)
+	s.Close()
+}
+
 // sendInitialize initiates the MCP handshake with the backend.
 func (s *Session) sendInitialize() {
 	logger := s.BaseSession.Logger
@@ -36,15 +75,16 @@ func (s *Session) sendInitialize() {
 
 	logger.Debug("Initialize params being sent to backend", zap.Any("params", params))
 	msg := <-s.SendRequestSync("initialize", params)
+	if msg == nil {
+		s.failHandshake(errors.New("no response to initialize"))
+		return
+	}
 	if msg.Error != nil {
-		logger.Error("Failed to initialize backend", zap.Error(msg.Error))
-		s.writeInitializationErrorAndClose(msg.Error)
+		s.failHandshake(fmt.Errorf("initialize rejected: %w", msg.Error))
 		return
 	}
 	if msg.Result == nil {
-		err := fmt.Errorf("backend returned nil result")
-		logger.Error(err.Error())
-		s.writeInitializationErrorAndClose(err)
+		s.failHandshake(errors.New("backend returned nil result"))
 		return
 	}
 	var result schema.InitializeResult
@@ -53,8 +93,8 @@ func (s *Session) sendInitialize() {
 			zap.Error(err),
 			zap.ByteString("result", *msg.Result),
 		)
-		err = fmt.Errorf("failed to parse backend initialize response: %w", err)
-		s.writeInitializationErrorAndClose(err)
+		s.failHandshake(fmt.Errorf("failed to parse backend initialize response: %w", err))
+		return
 	}
 	msg.Processed = true
 
@@ -62,9 +102,8 @@ func (s *Session) sendInitialize() {
 	logger.Debug("Received initialize response from backend", zap.String("backendNegotiatedVersion", backendNegotiatedVersion))
 
 	if _, supported := clientSupportedVersions[backendNegotiatedVersion]; !supported {
-		err := fmt.Errorf("backend '%s' negotiated unsupported protocol version '%s'", s.Backend.ID, backendNegotiatedVersion)
-		logger.Error(err.Error())
-		s.writeInitializationErrorAndClose(err)
+		s.failHandshake(fmt.Errorf("backend '%s' negotiated unsupported protocol version '%s'", s.Backend.ID, backendNegotiatedVersion))
+		return
 	}
 
 	// Store negotiated version and server info for this backend connection
@@ -80,4 +119,5 @@ func (s *Session) sendInitialize() {
 	s.writeInitializationErrorAndClose(nil)
 
 	s.SendRequestSync("notifications/initialized", map[string]interface{}{})
+	s.resubscribeResources()
 }
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"github.com/gate4ai/mcp/gateway/client/capability"
+	"github.com/gate4ai/mcp/shared"
+)
+
+// SubscribeOnListChanged registers a function called when the backend notifies that the
+// answer to a list method, e.g. "tools/list", changed. The list kept by the session is
+// already forgotten when it is called, so the next request fetches it again.
+func (s *Session) SubscribeOnListChanged(f capability.ListChangedFunc) {
+	s.Locker.RLock()
+	lc := s.ListChangedCapability
+	s.Locker.RUnlock()
+	lc.SubscribeOnListChanged(f)
+}
+
+// forgetList forgets the list of the method fetched from the backend.
+func (s *Session) forgetList(_ shared.ISession, method string) {
+	s.Locker.Lock()
+	defer s.Locker.Unlock()
+	switch method {
+	case "tools/list":
+		s.toolsInitialized = false
+	case "prompts/list":
+		s.promptsInitialized = false
+	case "resources/list":
+		s.resourcesInitialized = false
+	}
+}
@@ -32,7 +32,9 @@ func (s *Session) UpdatePrompts(ctx context.Context) chan struct{} {
 
 	// Goroutine to handle initialization and request sending
 	go func() {
+		defer close(done)
 		allPrompts := make([]schema.Prompt, 0)
+		failed := false       // Whether the server answered a page with an error
 		initErr := <-s.Open() // Wait for session initialization
 		if initErr != nil {
 			logger.Error("Session initialization failed", zap.Error(initErr))
@@ -46,7 +48,12 @@ func (s *Session) UpdatePrompts(ctx context.Context) chan struct{} {
 				continue
 			}
 			if msg.Error != nil {
+				if msg.Error.Code == shared.JSONRPCErrorMethodNotFound {
+					logger.Debug("prompts/list - Not supported by the server")
+					continue
+				}
 				logger.Error("prompts/list - Failed to send initial prompts list request", zap.Error(msg.Error))
+				failed = true
 				continue
 			}
 			if msg.Result == nil {
@@ -63,11 +70,13 @@ func (s *Session) UpdatePrompts(ctx context.Context) chan struct{} {
 				logger.Debug("prompts/list - Appended prompts", zap.Int("count", len(listPromptsResult.Prompts)))
 			}
 		}
+		if failed {
+			return // Not initialized, the prompts are fetched again when next requested
+		}
 		s.Locker.Lock()
 		s.prompts = allPrompts
 		s.promptsInitialized = true
 		s.Locker.Unlock()
-		close(done)
 	}()
 
 	return done
@@ -137,31 +146,32 @@ func (s *Session) GetPrompt(ctx context.Context, name string, arguments map[stri
 
 	// Goroutine to handle request sending and response processing
 	go func() {
+		defer close(resultChan)
 		// Use 2025 schema request parameters
 		params := &schema.GetPromptRequestParams{
 			Name:      name,
 			Arguments: arguments,
 		}
+		done := make(chan GetPromptAsyncResult, 1)
 
 		// Define callback for the response
 		callback := func(msg *shared.Message) {
-			defer close(resultChan) // Ensure channel is closed on exit
 			responseLogger := s.BaseSession.Logger.With(zap.String("operation", "getPromptCallback"), zap.String("promptName", name))
 			if msg == nil {
 				responseLogger.Error("Received nil message")
-				resultChan <- GetPromptAsyncResult{Error: errors.New("protocol error: received nil response")}
+				done <- GetPromptAsyncResult{Error: errors.New("protocol error: received nil response")}
 				return
 			}
 
 			if msg.Error != nil {
 				responseLogger.Error("Backend returned error", zap.Error(msg.Error))
-				resultChan <- GetPromptAsyncResult{Error: fmt.Errorf("backend error: %w", msg.Error)}
+				done <- GetPromptAsyncResult{Error: fmt.Errorf("backend error: %w", msg.Error)}
 				return
 			}
 
 			if msg.Result == nil {
 				responseLogger.Error("Prompt result is nil")
-				resultChan <- GetPromptAsyncResult{Error: errors.New("protocol error: prompt result is nil")}
+				done <- GetPromptAsyncResult{Error: errors.New("protocol error: prompt result is nil")}
 				return
 			}
 
@@ -169,26 +179,29 @@ func (s *Session) GetPrompt(ctx context.Context, name string, arguments map[stri
 			promptResult := &schema.GetPromptResult{}
 			if err := json.Unmarshal(*msg.Result, promptResult); err != nil {
 				responseLogger.Error("Failed to unmarshal prompt result", zap.Error(err))
-				resultChan <- GetPromptAsyncResult{Error: fmt.Errorf("failed to parse backend response: %w", err)}
+				done <- GetPromptAsyncResult{Error: fmt.Errorf("failed to parse backend response: %w", err)}
 				return
 			}
 			msg.Processed = true
 			responseLogger.Debug("Successfully retrieved prompt")
-			resultChan <- GetPromptAsyncResult{Result: promptResult, Error: nil}
+			done <- GetPromptAsyncResult{Result: promptResult, Error: nil}
 		}
 
 		// Send the request
 		logger.Debug("Sending prompts/get request")
-		_, err := s.SendRequest("prompts/get", params, callback)
+		reqID, err := s.SendRequestContext(ctx, "prompts/get", params, callback)
 		if err != nil {
 			logger.Error("Failed to send prompt get request", zap.Error(err))
-			// Try to send error through channel if it's still open
-			select {
-			case resultChan <- GetPromptAsyncResult{Error: fmt.Errorf("failed to send request: %w", err)}:
-				defer close(resultChan) // Ensure channel is closed on exit
-			default:
-				logger.Error("Result channel closed before error could be sent", zap.Error(err))
-			}
+			resultChan <- GetPromptAsyncResult{Error: fmt.Errorf("failed to send request: %w", err)}
+			return
+		}
+
+		select {
+		case result := <-done:
+			resultChan <- result
+		case <-ctx.Done():
+			s.cancelRequest(reqID, ctx.Err().Error())
+			resultChan <- GetPromptAsyncResult{Error: fmt.Errorf("context cancelled: %w", ctx.Err())}
 		}
 	}()
 
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gate4ai/mcp/shared"
+	"go.uber.org/zap"
+)
+
+// RawResult contains the undecoded result of a request.
+// Exactly one of Result or Error is set.
+type RawResult struct {
+	Result json.RawMessage // Result bytes exactly as received from the backend
+	Error  error           // *shared.JSONRPCError if the backend replied with an error
+}
+
+// CallRaw sends a request and returns the backend's result without decoding it,
+// so unknown fields and key order survive. The request ID is assigned by the
+// session and correlated back to the caller via the request manager.
+func (s *Session) CallRaw(ctx context.Context, method string, params interface{}) chan RawResult {
+	logger := s.BaseSession.Logger.With(zap.String("operation", "CallRaw"), zap.String("method", method))
+	resultChan := make(chan RawResult, 1) // Buffered channel
+
+	go func() {
+		done := make(chan RawResult, 1)
+
+		callback := func(msg *shared.Message) {
+			if msg == nil {
+				done <- RawResult{Error: errors.New("protocol error: received nil response")}
+				return
+			}
+			if msg.Error != nil {
+				// Keep the backend's JSON-RPC error untouched
+				done <- RawResult{Error: msg.Error}
+				return
+			}
+			if msg.Result == nil {
+				done <- RawResult{Error: errors.New("protocol error: result is nil")}
+				return
+			}
+			msg.Processed = true
+			done <- RawResult{Result: append(json.RawMessage(nil), *msg.Result...)}
+		}
+
+		logger.Debug("Sending raw request")
+		reqID, err := s.SendRequestContext(ctx, method, params, callback)
+		if err != nil {
+			logger.Error("Failed to send raw request", zap.Error(err))
+			resultChan <- RawResult{Error: fmt.Errorf("failed to send request: %w", err)}
+			close(resultChan)
+			return
+		}
+
+		select {
+		case result := <-done:
+			resultChan <- result
+		case <-ctx.Done():
+			s.cancelRequest(reqID, ctx.Err().Error())
+			resultChan <- RawResult{Error: fmt.Errorf("context cancelled: %w", ctx.Err())}
+		}
+		close(resultChan)
+	}()
+
+	return resultChan
+}
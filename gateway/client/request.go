@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/tracing"
 
 	"go.uber.org/zap"
 )
@@ -21,6 +23,11 @@ func (s *Session) executeSendRequest(msg *shared.Message) {
 		zap.String("reqID", msg.ID.String()),
 	)
 
+	if s.Backend.Command != nil {
+		s.sendToProcess(msg, logger)
+		return
+	}
+
 	s.Locker.RLock()
 	endpoint := s.postEndpoint
 	httpClient := s.httpClient
@@ -28,10 +35,7 @@ func (s *Session) executeSendRequest(msg *shared.Message) {
 
 	// Prepare error message for RequestManager in case of failure
 	notifyError := func(err error) {
-		if msg.ID != nil && !msg.ID.IsEmpty() { // Only notify for requests, not notifications
-			// Simulate an error response to trigger cleanup in RequestManager
-			s.GetRequestManager().ProcessResponse(&shared.Message{ID: msg.ID, Error: shared.NewJSONRPCError(err), Session: s})
-		}
+		s.failRequest(msg.ID, err)
 	}
 
 	if endpoint == "" {
@@ -82,6 +86,9 @@ func (s *Session) executeSendRequest(msg *shared.Message) {
 	if authHeader != "" {
 		req.Header.Set("Authorization", authHeader)
 	}
+	if msg.TraceParent != "" {
+		req.Header.Set(tracing.TraceparentHeader, msg.TraceParent)
+	}
 
 	logger.Debug("Sending HTTP POST request", zap.String("endpoint", endpoint))
 
@@ -121,3 +128,22 @@ func (s *Session) executeSendRequest(msg *shared.Message) {
 		zap.Duration("duration", duration),
 	)
 }
+
+// failRequest ends a request sent to the backend with err, as if the backend had
+// answered with it. Notifications have no ID and are ignored.
+func (s *Session) failRequest(id *schema.RequestID, err error) {
+	if id != nil && !id.IsEmpty() {
+		// Simulate an error response to trigger cleanup in RequestManager
+		s.GetRequestManager().ProcessResponse(&shared.Message{ID: id, Error: shared.NewJSONRPCError(err), Session: s})
+	}
+}
+
+// cancelRequest tells the backend that the result of a request is no longer needed
+// (notifications/cancelled), so it can stop working on it.
+func (s *Session) cancelRequest(id *schema.RequestID, reason string) {
+	if id == nil {
+		return
+	}
+	s.BaseSession.Logger.Debug("Cancelling backend request", zap.String("reqID", id.String()), zap.String("reason", reason))
+	s.SendNotification("notifications/cancelled", map[string]any{"requestId": id, "reason": reason})
+}
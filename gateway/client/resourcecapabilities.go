@@ -2,11 +2,16 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/gate4ai/mcp/gateway/client/capability"
 	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
 )
 
+// resubscribeTimeout bounds each resources/subscribe sent again after a new handshake.
+const resubscribeTimeout = 5 * time.Second
+
 // Resources returns the resources capability instance
 func (s *Session) Resources() *capability.ResourcesCapability {
 	s.Locker.RLock()
@@ -28,6 +33,18 @@ func (s *Session) SubscribeResource(ctx context.Context, uri string) error {
 	return s.Resources().SubscribeResource(ctx, uri)
 }
 
+// resubscribeResources subscribes again to the resources subscribed to before the session
+// was initialized anew, as the server forgot them with its previous session.
+func (s *Session) resubscribeResources() {
+	for _, uri := range s.Resources().SubscribedResources() {
+		ctx, cancel := context.WithTimeout(s.ctx, resubscribeTimeout)
+		if err := s.SubscribeResource(ctx, uri); err != nil {
+			s.BaseSession.Logger.Warn("Failed to subscribe again to resource", zap.String("uri", uri), zap.Error(err))
+		}
+		cancel()
+	}
+}
+
 // UnsubscribeResource sends a request to unsubscribe from updates for a given resource URI.
 func (s *Session) UnsubscribeResource(ctx context.Context, uri string) error {
 	return s.Resources().UnsubscribeResource(ctx, uri)
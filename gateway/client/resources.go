@@ -16,7 +16,9 @@ func (s *Session) UpdateResources(ctx context.Context) chan struct{} {
 	done := make(chan struct{})
 
 	go func() {
+		defer close(done)
 		allResources := make([]schema.Resource, 0)
+		failed := false // Whether the server answered a page with an error
 		initErr := <-s.Open()
 		if initErr != nil {
 			logger.Error("Session initialization failed", zap.Error(initErr))
@@ -30,7 +32,12 @@ func (s *Session) UpdateResources(ctx context.Context) chan struct{} {
 				continue
 			}
 			if msg.Error != nil {
+				if msg.Error.Code == shared.JSONRPCErrorMethodNotFound {
+					logger.Debug("resources/list - Not supported by the server")
+					continue
+				}
 				logger.Error("resources/list - Failed to send initial resources list request", zap.Error(msg.Error))
+				failed = true
 				continue
 			}
 			if msg.Result == nil {
@@ -47,11 +54,13 @@ func (s *Session) UpdateResources(ctx context.Context) chan struct{} {
 				logger.Debug("resources/list - Appended resources", zap.Int("count", len(listResourcesResult.Resources)))
 			}
 		}
+		if failed {
+			return // Not initialized, the resources are fetched again when next requested
+		}
 		s.Locker.Lock()
 		s.resources = allResources
 		s.resourcesInitialized = true
 		s.Locker.Unlock()
-		close(done)
 	}()
 
 	return done
@@ -131,30 +140,31 @@ func (s *Session) ReadResource(ctx context.Context, uri string) chan ReadResourc
 	}
 
 	go func() {
+		defer close(resultChan)
 		// Use 2025 schema request parameters
 		params := &schema.ReadResourceRequestParams{
 			URI: uri,
 		}
+		done := make(chan ReadResourceResult, 1)
 
 		// Define callback for the response
 		callback := func(msg *shared.Message) {
-			defer close(resultChan) // Ensure channel is closed
 			responseLogger := s.BaseSession.Logger.With(zap.String("operation", "readResourceCallback"), zap.String("uri", uri))
 			if msg == nil {
 				responseLogger.Error("Received nil message")
-				resultChan <- ReadResourceResult{nil, errors.New("protocol error: received nil response")}
+				done <- ReadResourceResult{nil, errors.New("protocol error: received nil response")}
 				return
 			}
 
 			if msg.Error != nil {
 				responseLogger.Warn("Backend returned error", zap.Error(msg.Error))
-				resultChan <- ReadResourceResult{nil, fmt.Errorf("backend error: %w", msg.Error)}
+				done <- ReadResourceResult{nil, fmt.Errorf("backend error: %w", msg.Error)}
 				return
 			}
 
 			if msg.Result == nil {
 				responseLogger.Error("Resource read result is nil")
-				resultChan <- ReadResourceResult{nil, errors.New("protocol error: resource read result is nil")}
+				done <- ReadResourceResult{nil, errors.New("protocol error: resource read result is nil")}
 				return
 			}
 
@@ -162,26 +172,29 @@ func (s *Session) ReadResource(ctx context.Context, uri string) chan ReadResourc
 			var readResourceResult schema.ReadResourceResult
 			if err := json.Unmarshal(*msg.Result, &readResourceResult); err != nil {
 				responseLogger.Error("Failed to unmarshal resource read result", zap.Error(err))
-				resultChan <- ReadResourceResult{nil, fmt.Errorf("failed to parse backend response: %w", err)}
+				done <- ReadResourceResult{nil, fmt.Errorf("failed to parse backend response: %w", err)}
 				return
 			}
 			msg.Processed = true
 			responseLogger.Debug("Successfully read resource")
-			resultChan <- ReadResourceResult{&readResourceResult, nil}
+			done <- ReadResourceResult{&readResourceResult, nil}
 		}
 
 		// Send the request
 		logger.Debug("Sending resources/read request")
-		// Change: Ignore the first return value (requestID)
-		_, err := s.SendRequest("resources/read", params, callback)
+		reqID, err := s.SendRequestContext(ctx, "resources/read", params, callback)
 		if err != nil {
 			logger.Error("Failed to send resource read request", zap.Error(err))
-			// Try to send error through channel
-			select {
-			case resultChan <- ReadResourceResult{nil, fmt.Errorf("failed to send request: %w", err)}:
-			default:
-				logger.Error("Result channel closed before error could be sent", zap.Error(err))
-			}
+			resultChan <- ReadResourceResult{nil, fmt.Errorf("failed to send request: %w", err)}
+			return
+		}
+
+		select {
+		case result := <-done:
+			resultChan <- result
+		case <-ctx.Done():
+			s.cancelRequest(reqID, ctx.Err().Error())
+			resultChan <- ReadResourceResult{nil, fmt.Errorf("context cancelled: %w", ctx.Err())}
 		}
 	}()
 	return resultChan
@@ -30,8 +30,14 @@ type Session struct {
 	sseClient                    *sse.Client                             // SSE client instance
 	httpClient                   *http.Client                            // HTTP client for POST requests
 	sseCh                        chan *sse.Event                         // Channel for receiving SSE events
+	sseSubscribed                bool                                    // Whether sseCh is subscribed, it must be unsubscribed only once
+	sseCancel                    context.CancelFunc                      // Cancels the SSE subscription, including its reconnection attempts
+	process                      *stdioProcess                           // Running process of a stdio backend, nil while none runs
+	processDone                  chan struct{}                           // Closed once the process of a stdio backend is stopped for good, nil for HTTP backends
+	restartDelay                 time.Duration                           // Wait before the last restart of the process
 	closeCh                      chan struct{}                           // Channel to signal explicit session closure
 	initialization               chan error                              // Channel to signal completion/failure of initialization handshake
+	handshakeErr                 *HandshakeError                         // Set if the backend failed the initialize handshake
 	serverInfo                   *schema.Implementation                  // Backend server info (V2025 type)
 	tools                        []schema.Tool                           // Cached tools list (V2025 type)
 	toolsInitialized             bool                                    // Flag indicating if tools have been fetched
@@ -45,6 +51,9 @@ type Session struct {
 	SamplingCapability           *capability.SamplingCapability          // Sampling capability instance
 	ResourcesCapability          *capability.ResourcesCapability         // Resources capability instance
 	ResourceTemplatesCapability  *capability.ResourceTemplatesCapability // Resource templates capability instance
+	ToolChunksCapability         *capability.ToolChunksCapability        // Collects chunks of streamed tool results
+	ProgressCapability           *capability.ProgressCapability          // Relays progress of running requests
+	ListChangedCapability        *capability.ListChangedCapability       // Passes on the list_changed notifications of the backend
 }
 
 // writeInitializationErrorAndClose safely writes to the initialization channel and closes it.
@@ -98,6 +107,15 @@ func (s *Session) Open() chan error {
 
 	s.Locker.Lock() // Use client Locker
 
+	// 0. A session whose handshake failed stays unusable, report the failure to every caller
+	if s.handshakeErr != nil {
+		failedChan := make(chan error, 1)
+		failedChan <- s.handshakeErr
+		close(failedChan)
+		s.Locker.Unlock()
+		return failedChan
+	}
+
 	// 1. Check if initialization is already complete (connected)
 	if s.GetStatus() == shared.StatusConnected {
 		logger.Debug("Session already connected")
@@ -136,6 +154,10 @@ func (s *Session) Open() chan error {
 
 	s.Locker.Unlock() // Unlock before potentially blocking operations
 
+	if s.Backend.Command != nil {
+		return s.openStdio()
+	}
+
 	// Subscribe to SSE events
 	logger.Debug("Subscribing to SSE channel")
 	sseContext, sseCancel := context.WithCancel(s.ctx)
@@ -155,6 +177,10 @@ func (s *Session) Open() chan error {
 		s.writeInitializationErrorAndClose(fmt.Errorf("SSE subscription failed: %w", err))
 		return s.initialization
 	}
+	s.Locker.Lock()
+	s.sseSubscribed = true
+	s.sseCancel = sseCancel
+	s.Locker.Unlock()
 	logger.Debug("SSE subscription initiated")
 
 	if s.Input() == nil {
@@ -177,9 +203,8 @@ func (s *Session) processLoop() {
 		loopLogger.Info("Session processing loop ended")
 		// Cleanup resources when loop exits
 		s.Locker.Lock()
-		if s.sseClient != nil {
-			s.sseClient.Unsubscribe(s.sseCh) // Unsubscribe from SSE channel
-		}
+		s.unsubscribeSSE()
+		s.stopProcess()
 		s.Locker.Unlock()
 
 		// Set status back to New
@@ -274,6 +299,8 @@ func (s *Session) processLoop() {
 				}
 			case "ping":
 				loopLogger.Debug("Received ping event")
+			case "close":
+				loopLogger.Info("Backend closed the SSE stream, shutting down")
 			default:
 				loopLogger.Warn("Received unknown SSE event type", zap.String("eventName", string(event.Event)))
 			}
@@ -338,17 +365,28 @@ func (s *Session) Close() error {
 	// 5. Unsubscribe SSE client (important to stop potential reconnections)
 	// This might already be handled by context cancellation in SubscribeChanWithContext,
 	// but explicit unsubscribe here provides robustness.
+	// The process of a stdio backend is stopped and not restarted.
 	s.Locker.Lock()
-	if s.sseClient != nil {
-		// sseClient.Unsubscribe() might block or panic if called concurrently,
-		// but since the loop is signaled to stop, this should be relatively safe.
-		// Alternatively, rely solely on context cancellation passed to SubscribeChanWithContext.
-		// Let's keep explicit unsubscribe for clarity, assuming library handles it safely.
-		s.sseClient.Unsubscribe(s.sseCh) // Unsubscribe from the specific channel
+	if s.unsubscribeSSE() {
 		logger.Debug("Unsubscribed from SSE client channel")
 	}
+	s.stopProcess()
 	s.Locker.Unlock()
 
 	logger.Info("Session close process completed")
 	return baseErr // Return error from BaseSession.Close if any occurred
 }
+
+// unsubscribeSSE ends the subscription of sseCh to the SSE client if it is subscribed and
+// reports whether it was. The subscription context is canceled rather than calling
+// sseClient.Unsubscribe, which blocks until the client receives the signal: never when
+// called twice, and not before the next attempt while the client waits to reconnect to
+// an unreachable backend. The caller must hold s.Locker.
+func (s *Session) unsubscribeSSE() bool {
+	if !s.sseSubscribed {
+		return false
+	}
+	s.sseCancel()
+	s.sseSubscribed = false
+	return true
+}
@@ -0,0 +1,345 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+const (
+	// Wait before restarting a process that exited, doubled after each exit up to
+	// stdioMaxRestartDelay; a process running longer than that restarts the sequence
+	stdioRestartDelay    = 500 * time.Millisecond
+	stdioMaxRestartDelay = 30 * time.Second
+	// Max wait for a process to exit after its stdin was closed before it is killed, and
+	// for its output to be read after it exited
+	stdioStopTimeout = 2 * time.Second
+)
+
+// StdioCommand starts a backend speaking MCP over stdio: the process reads JSON-RPC
+// messages from its stdin and writes them to its stdout, one per line, and may log to
+// its stderr.
+type StdioCommand struct {
+	Args []string // Executable followed by its arguments
+	Env  []string // "KEY=value" variables added to the environment of the gateway
+	Dir  string   // Working directory, the gateway's if empty
+}
+
+// NewStdio creates a client of a backend run as a child process speaking MCP over stdio.
+// Each session of the backend starts its own process.
+func NewStdio(ID string, command StdioCommand, logger *zap.Logger) (*Backend, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if len(command.Args) == 0 || command.Args[0] == "" {
+		return nil, errors.New("stdio backend without a command")
+	}
+
+	logger = logger.With(zap.String("backendID", ID), zap.Strings("backendCommand", command.Args))
+	logger.Debug("Created new MCP stdio client backend")
+
+	return &Backend{
+		ID:      ID,
+		Command: &command,
+		Logger:  logger,
+	}, nil
+}
+
+// stdioProcess is a running process of a stdio backend.
+type stdioProcess struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	started time.Time
+	exited  chan struct{} // Closed once the process exited and its output was read
+
+	writeMu sync.Mutex // Serializes the messages written to stdin
+
+	pendingMu sync.Mutex
+	pending   map[string]*schema.RequestID // Requests written and not answered yet
+}
+
+// openStdio starts the process of a stdio backend and the MCP handshake with it.
+func (s *Session) openStdio() chan error {
+	if s.Input() == nil {
+		s.BaseSession.Logger.Error("Input is nil, cannot process messages")
+		s.writeInitializationErrorAndClose(errors.New("input is nil, cannot process messages"))
+		return s.initialization
+	}
+	if err := s.startProcess(); err != nil {
+		s.BaseSession.Logger.Warn("Failed to start backend process", zap.Error(err))
+		s.SetStatus(shared.StatusNew)
+		s.writeInitializationErrorAndClose(fmt.Errorf("failed to start backend process: %w", err))
+		return s.initialization
+	}
+
+	go s.processLoop()
+	go s.sendInitialize()
+
+	return s.initialization
+}
+
+// startProcess starts a process of the stdio backend and the goroutines reading its
+// output.
+func (s *Session) startProcess() error {
+	command := s.Backend.Command
+	cmd := exec.CommandContext(s.ctx, command.Args[0], command.Args[1:]...)
+	cmd.Env = append(os.Environ(), command.Env...)
+	cmd.Dir = command.Dir
+	// Output goes through pipes of our own, so Wait does not close them while they are
+	// read, and gives up on output held open by children of the process after WaitDelay
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+	cmd.WaitDelay = stdioStopTimeout
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	proc := &stdioProcess{
+		cmd:     cmd,
+		stdin:   stdin,
+		started: time.Now(),
+		exited:  make(chan struct{}),
+		pending: make(map[string]*schema.RequestID),
+	}
+	logger := s.BaseSession.Logger.With(zap.Int("pid", cmd.Process.Pid))
+	logger.Info("Backend process started")
+
+	s.Locker.Lock()
+	select {
+	case <-s.processDone:
+		err = errors.New("session closed")
+		go proc.stop()
+	default:
+		s.process = proc
+	}
+	s.Locker.Unlock()
+
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		s.readProcessOutput(proc, stdoutReader, logger)
+	}()
+	stderrDone := make(chan struct{})
+	go func() {
+		defer close(stderrDone)
+		logProcessStderr(stderrReader, logger)
+	}()
+	go func() {
+		err := cmd.Wait()
+		stdoutWriter.Close()
+		stderrWriter.Close()
+		<-outputDone
+		<-stderrDone
+		s.processExited(proc, err, logger)
+	}()
+	return err
+}
+
+// readProcessOutput passes the messages the process writes to its stdout to the session
+// until the stdout is closed.
+func (s *Session) readProcessOutput(proc *stdioProcess, stdout io.Reader, logger *zap.Logger) {
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			msgs, parseErr := shared.ParseMessages(s, line)
+			if parseErr != nil {
+				logger.Warn("Ignoring output of backend process that is not JSON-RPC", zap.Error(parseErr), zap.ByteString("data", line))
+			}
+			for _, msg := range msgs {
+				if msg.Method == nil && msg.ID != nil {
+					proc.answered(msg.ID)
+				}
+				s.Input().Put(msg)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// logProcessStderr logs each line the process writes to its stderr.
+func logProcessStderr(stderr io.Reader, logger *zap.Logger) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		logger.Info("Backend process stderr", zap.String("line", scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Warn("Failed to read stderr of backend process", zap.Error(err))
+		io.Copy(io.Discard, stderr) // Keep the process from blocking on a full pipe
+	}
+}
+
+// processExited fails the requests the process left unanswered and restarts it unless
+// the session stopped it.
+func (s *Session) processExited(proc *stdioProcess, exitErr error, logger *zap.Logger) {
+	s.Locker.Lock()
+	if s.process == proc {
+		s.process = nil
+	}
+	s.Locker.Unlock()
+	close(proc.exited)
+
+	for _, id := range proc.takePending() {
+		s.failRequest(id, errors.New("backend process exited"))
+	}
+
+	select {
+	case <-s.processDone:
+		logger.Info("Backend process stopped", zap.Error(exitErr))
+		return
+	case <-s.ctx.Done():
+		logger.Info("Backend process stopped", zap.Error(exitErr))
+		return
+	default:
+	}
+
+	s.Locker.Lock()
+	if s.restartDelay == 0 || time.Since(proc.started) > stdioMaxRestartDelay {
+		s.restartDelay = stdioRestartDelay
+	} else {
+		s.restartDelay = min(2*s.restartDelay, stdioMaxRestartDelay)
+	}
+	delay := s.restartDelay
+	s.Locker.Unlock()
+	logger.Warn("Backend process exited, restarting it", zap.Error(exitErr), zap.Duration("delay", delay))
+	s.SetStatus(shared.StatusConnecting)
+	s.restartProcess(delay)
+}
+
+// restartProcess starts the process again after delay, retrying with a doubled delay
+// until it starts or the session is closed, and repeats the MCP handshake.
+func (s *Session) restartProcess(delay time.Duration) {
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-s.processDone:
+			timer.Stop()
+			return
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		err := s.startProcess()
+		if err == nil {
+			go s.sendInitialize()
+			return
+		}
+		delay = min(2*delay, stdioMaxRestartDelay)
+		s.BaseSession.Logger.Error("Failed to restart backend process", zap.Error(err), zap.Duration("retryIn", delay))
+	}
+}
+
+// stopProcess stops the process of a stdio backend for good, it is not restarted. The
+// caller must hold s.Locker.
+func (s *Session) stopProcess() {
+	if s.processDone == nil {
+		return // Not a stdio backend
+	}
+	select {
+	case <-s.processDone:
+		return
+	default:
+	}
+	close(s.processDone)
+	if s.process != nil {
+		go s.process.stop()
+	}
+}
+
+// sendToProcess writes a message to the stdin of the process.
+func (s *Session) sendToProcess(msg *shared.Message, logger *zap.Logger) {
+	s.Locker.RLock()
+	proc := s.process
+	s.Locker.RUnlock()
+	if proc == nil {
+		err := errors.New("backend process is not running")
+		logger.Warn(err.Error())
+		s.failRequest(msg.ID, err)
+		return
+	}
+	// Until a restarted process completed the handshake it only gets initialize
+	if s.GetStatus() != shared.StatusConnected && shared.NilIfNil(msg.Method) != "initialize" {
+		err := errors.New("backend process is restarting")
+		logger.Warn(err.Error())
+		s.failRequest(msg.ID, err)
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		logger.Error("Failed to marshal JSON-RPC request", zap.Error(err))
+		s.failRequest(msg.ID, fmt.Errorf("internal error: failed to marshal JSON-RPC request for method '%s': %w", shared.NilIfNil(msg.Method), err))
+		return
+	}
+	isRequest := msg.Method != nil && msg.ID != nil && !msg.ID.IsEmpty()
+	if isRequest {
+		proc.pendingMu.Lock()
+		proc.pending[msg.ID.String()] = msg.ID
+		proc.pendingMu.Unlock()
+	}
+
+	logger.Debug("Writing message to backend process")
+	proc.writeMu.Lock()
+	_, err = proc.stdin.Write(append(data, '\n'))
+	proc.writeMu.Unlock()
+	if err != nil {
+		logger.Warn("Failed to write to backend process", zap.Error(err))
+		if isRequest {
+			proc.answered(msg.ID)
+		}
+		s.failRequest(msg.ID, fmt.Errorf("failed to write to backend process: %w", err))
+	}
+}
+
+// answered forgets a request once the process answered it.
+func (p *stdioProcess) answered(id *schema.RequestID) {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	delete(p.pending, id.String())
+}
+
+// takePending returns the requests not answered yet and forgets them.
+func (p *stdioProcess) takePending() []*schema.RequestID {
+	p.pendingMu.Lock()
+	defer p.pendingMu.Unlock()
+	ids := make([]*schema.RequestID, 0, len(p.pending))
+	for _, id := range p.pending {
+		ids = append(ids, id)
+	}
+	p.pending = make(map[string]*schema.RequestID)
+	return ids
+}
+
+// stop closes the stdin of the process, which tells MCP servers to exit, and kills the
+// process if it has not exited after stdioStopTimeout.
+func (p *stdioProcess) stop() {
+	p.stdin.Close()
+	timer := time.NewTimer(stdioStopTimeout)
+	defer timer.Stop()
+	select {
+	case <-p.exited:
+	case <-timer.C:
+		p.cmd.Process.Kill()
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync/atomic"
 
+	"github.com/gate4ai/mcp/gateway/client/capability"
 	"github.com/gate4ai/mcp/shared"
 	// Use 2025 schema
 	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
@@ -20,6 +22,7 @@ func (s *Session) UpdateTools(ctx context.Context) chan struct{} {
 	go func() {
 		defer close(done)
 		allTools := make([]schema.Tool, 0)
+		failed := false       // Whether the server answered a page with an error
 		initErr := <-s.Open() // Wait for session initialization
 		if initErr != nil {
 			logger.Error("Session initialization failed", zap.Error(initErr))
@@ -33,7 +36,12 @@ func (s *Session) UpdateTools(ctx context.Context) chan struct{} {
 				continue
 			}
 			if msg.Error != nil {
+				if msg.Error.Code == shared.JSONRPCErrorMethodNotFound {
+					logger.Debug("tools/list - Not supported by the server")
+					continue
+				}
 				logger.Error("tools/list - Failed to send initial tools list request", zap.Error(msg.Error))
+				failed = true
 				continue
 			}
 			if msg.Result == nil {
@@ -50,6 +58,9 @@ func (s *Session) UpdateTools(ctx context.Context) chan struct{} {
 				logger.Debug("tools/list - Appended tools", zap.Int("count", len(listToolsResult.Tools)))
 			}
 		}
+		if failed {
+			return // Not initialized, the tools are fetched again when next requested
+		}
 		s.Locker.Lock()
 		s.tools = allTools
 		s.toolsInitialized = true
@@ -111,6 +122,70 @@ func (s *Session) GetTools(ctx context.Context) chan GetToolsResult {
 	return resultChan
 }
 
+// GetToolsPageResult contains a page of the tools list of the server.
+type GetToolsPageResult struct {
+	Tools      []schema.Tool
+	NextCursor *string // Of the next page, nil on the last one
+	Err        error
+}
+
+// GetToolsPage requests one page of the tools of the server, the first one for an empty
+// cursor. Unlike GetTools it neither follows the cursor of the next page nor caches the
+// tools.
+func (s *Session) GetToolsPage(ctx context.Context, cursor string) chan GetToolsPageResult {
+	logger := s.BaseSession.Logger.With(zap.String("operation", "GetToolsPage"))
+	resultChan := make(chan GetToolsPageResult, 1)
+
+	go func() {
+		defer close(resultChan)
+		if err := <-s.Open(); err != nil {
+			logger.Error("Session initialization failed", zap.Error(err))
+			resultChan <- GetToolsPageResult{Err: fmt.Errorf("session initialization failed: %w", err)}
+			return
+		}
+
+		params := &schema.ListToolsRequestParams{}
+		if cursor != "" {
+			params.Cursor = &cursor
+		}
+		done := make(chan GetToolsPageResult, 1)
+		reqID, err := s.SendRequestContext(ctx, "tools/list", params, func(msg *shared.Message) {
+			msg.Processed = true
+			switch {
+			case msg.Error != nil:
+				done <- GetToolsPageResult{Err: msg.Error}
+			case msg.Result == nil:
+				done <- GetToolsPageResult{Err: errors.New("protocol error: result is nil")}
+			default:
+				var result schema.ListToolsResult
+				if err := json.Unmarshal(*msg.Result, &result); err != nil {
+					done <- GetToolsPageResult{Err: fmt.Errorf("failed to unmarshal tools list: %w", err)}
+					return
+				}
+				done <- GetToolsPageResult{Tools: result.Tools, NextCursor: result.NextCursor}
+			}
+		})
+		if err != nil {
+			logger.Error("Failed to send tools list request", zap.Error(err))
+			resultChan <- GetToolsPageResult{Err: fmt.Errorf("failed to send request: %w", err)}
+			return
+		}
+
+		select {
+		case result := <-done:
+			resultChan <- result
+		case <-ctx.Done():
+			s.cancelRequest(reqID, ctx.Err().Error())
+			resultChan <- GetToolsPageResult{Err: fmt.Errorf("context cancelled: %w", ctx.Err())}
+		}
+	}()
+
+	return resultChan
+}
+
+// chunkTokens numbers the chunked result tokens of tool calls.
+var chunkTokens atomic.Int64
+
 // CallToolResult contains the result of a tool call request (using 2025 schema).
 type CallToolResult struct {
 	Result *schema.CallToolResult // Use 2025 schema type
@@ -118,8 +193,25 @@ type CallToolResult struct {
 }
 
 // CallTool invokes a specific tool on the server by name with given arguments.
-// Returns a channel emitting a 2025 schema result.
+// Returns a channel emitting a 2025 schema result. Results the server streams in chunks
+// are reassembled before they are emitted.
 func (s *Session) CallTool(ctx context.Context, name string, arguments map[string]interface{}) chan CallToolResult {
+	return s.CallToolWithChunks(ctx, name, arguments, nil)
+}
+
+// CallToolWithChunks is CallTool with onChunk receiving the chunks of a streamed result
+// in order while the tool runs. The emitted result still contains the whole content.
+func (s *Session) CallToolWithChunks(ctx context.Context, name string, arguments map[string]interface{}, onChunk capability.ToolChunkFunc) chan CallToolResult {
+	return s.callTool(ctx, name, arguments, onChunk, nil)
+}
+
+// CallToolWithProgress is CallTool asking the server for progress notifications, which
+// are passed to onProgress in order while the tool runs and before the result is emitted.
+func (s *Session) CallToolWithProgress(ctx context.Context, name string, arguments map[string]interface{}, onProgress capability.ProgressFunc) chan CallToolResult {
+	return s.callTool(ctx, name, arguments, nil, onProgress)
+}
+
+func (s *Session) callTool(ctx context.Context, name string, arguments map[string]interface{}, onChunk capability.ToolChunkFunc, onProgress capability.ProgressFunc) chan CallToolResult {
 	logger := s.BaseSession.Logger.With(zap.String("operation", "CallTool"), zap.String("toolName", name))
 	resultChan := make(chan CallToolResult, 1) // Buffered channel
 
@@ -132,19 +224,33 @@ func (s *Session) CallTool(ctx context.Context, name string, arguments map[strin
 	}
 
 	go func() {
+		defer close(resultChan)
 		// Use 2025 schema request parameters
+		// Results are received over the SSE stream, so the server may send them in chunks
+		token := fmt.Sprintf("%s-%d", s.GetID(), chunkTokens.Add(1))
+		chunks := s.ToolChunksCapability.Expect(token, onChunk)
 		params := &schema.CallToolRequestParams{ // V2025 uses CallToolRequestParams
+			Meta:      schema.Meta{shared.ChunkedResultMetaKey: token},
 			Name:      name,
 			Arguments: arguments,
 		}
+		progressDone := func() {}
+		if onProgress != nil {
+			progressToken := s.ProgressCapability.Expect(onProgress)
+			params.Meta["progressToken"] = progressToken
+			progressDone = func() { s.ProgressCapability.Done(progressToken) }
+		}
+
+		done := make(chan CallToolResult, 1)
 
 		// Define callback for the response
 		callback := func(msg *shared.Message) {
-			defer close(resultChan) // Ensure channel is closed
+			defer s.ToolChunksCapability.Done(token)
+			progressDone() // Progress received before the response is passed on first
 			responseLogger := s.BaseSession.Logger.With(zap.String("operation", "callToolCallback"), zap.String("toolName", name))
 			if msg == nil {
 				responseLogger.Error("Received nil message")
-				resultChan <- CallToolResult{Error: errors.New("protocol error: received nil response")}
+				done <- CallToolResult{Error: errors.New("protocol error: received nil response")}
 				return
 			}
 
@@ -153,13 +259,13 @@ func (s *Session) CallTool(ctx context.Context, name string, arguments map[strin
 				// or a general JSON-RPC error.
 				responseLogger.Warn("Backend returned error for tool call", zap.Error(msg.Error))
 				// Assume generic error if msg.Error is set
-				resultChan <- CallToolResult{Error: fmt.Errorf("backend error: %w", msg.Error)}
+				done <- CallToolResult{Error: fmt.Errorf("backend error: %w", msg.Error)}
 				return
 			}
 
 			if msg.Result == nil {
 				responseLogger.Error("Tool call result is nil")
-				resultChan <- CallToolResult{Error: errors.New("protocol error: tool call result is nil")}
+				done <- CallToolResult{Error: errors.New("protocol error: tool call result is nil")}
 				return
 			}
 
@@ -167,35 +273,57 @@ func (s *Session) CallTool(ctx context.Context, name string, arguments map[strin
 			var callToolResult schema.CallToolResult
 			if err := json.Unmarshal(*msg.Result, &callToolResult); err != nil {
 				responseLogger.Error("Failed to unmarshal tool call result", zap.Error(err))
-				resultChan <- CallToolResult{Error: fmt.Errorf("failed to parse backend response: %w", err)}
+				done <- CallToolResult{Error: fmt.Errorf("failed to parse backend response: %w", err)}
 				return
 			}
 			msg.Processed = true
 
+			if count := shared.ChunkedResultCount(&callToolResult); count > 0 {
+				content, err := chunks.Wait(ctx, count)
+				if err != nil {
+					responseLogger.Error("Failed to receive tool result chunks", zap.Error(err))
+					done <- CallToolResult{Error: fmt.Errorf("incomplete chunked result: %w", err)}
+					return
+				}
+				callToolResult.Content = append(content, callToolResult.Content...)
+				delete(*callToolResult.Meta, shared.ChunkedResultMetaKey)
+				if len(*callToolResult.Meta) == 0 {
+					callToolResult.Meta = nil
+				}
+			}
+
 			// Check the IsError flag within the result structure
 			if callToolResult.IsError {
 				responseLogger.Warn("Tool call executed but resulted in an error")
 				// Construct an error message indicating the tool itself failed
 				toolErr := fmt.Errorf("tool '%s' execution failed on backend", name)
 				// Optionally try to extract more details from callToolResult.Content if available
-				resultChan <- CallToolResult{Result: &callToolResult, Error: toolErr}
+				done <- CallToolResult{Result: &callToolResult, Error: toolErr}
 			} else {
 				responseLogger.Debug("Successfully called tool")
-				resultChan <- CallToolResult{Result: &callToolResult, Error: nil}
+				done <- CallToolResult{Result: &callToolResult, Error: nil}
 			}
 		}
 
 		// Send the request
 		logger.Debug("Sending tools/call request")
-		_, err := s.SendRequest("tools/call", params, callback)
+		reqID, err := s.SendRequestContext(ctx, "tools/call", params, callback)
 		if err != nil {
+			s.ToolChunksCapability.Done(token)
+			progressDone()
 			logger.Error("Failed to send tool call request", zap.Error(err))
-			// Try to send error through channel
-			select {
-			case resultChan <- CallToolResult{Error: fmt.Errorf("failed to send request: %w", err)}:
-			default:
-				logger.Error("Result channel closed before error could be sent", zap.Error(err))
-			}
+			resultChan <- CallToolResult{Error: fmt.Errorf("failed to send request: %w", err)}
+			return
+		}
+
+		select {
+		case result := <-done:
+			resultChan <- result
+		case <-ctx.Done():
+			s.cancelRequest(reqID, ctx.Err().Error())
+			s.ToolChunksCapability.Done(token)
+			progressDone()
+			resultChan <- CallToolResult{Error: fmt.Errorf("context cancelled: %w", ctx.Err())}
 		}
 	}()
 
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gate4ai/mcp/gateway"
+	"github.com/gate4ai/mcp/shared/audit"
 	"github.com/gate4ai/mcp/shared/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -17,8 +19,11 @@ import (
 
 // Environment variable names
 const (
-	EnvDatabaseURL = "GATE4AI_DATABASE_URL"
-	EnvConfigYAML  = "GATE4AI_CONFIG_YAML"
+	EnvDatabaseURL     = "GATE4AI_DATABASE_URL"
+	EnvDatabaseRefresh = "GATE4AI_DATABASE_REFRESH"
+	EnvConfigYAML      = "GATE4AI_CONFIG_YAML"
+	EnvAuditLog        = "GATE4AI_AUDIT_LOG"
+	EnvAuditHMACKey    = "GATE4AI_AUDIT_HMAC_KEY"
 )
 
 func main() {
@@ -32,7 +37,9 @@ func main() {
 	defer logger.Sync()
 
 	configDB := flag.String("database-url", "", "PostgreSQL connection string for configuration")
-	configYAML := flag.String("config-yaml", "", "Path to YAML configuration file")
+	configYAML := flag.String("config-yaml", "", "Path to the configuration file (YAML, JSON or TOML, by extension), or a comma-separated list of files merged in order")
+	auditLog := flag.String("audit-log", "", "Path of the audit log recording configuration reloads, revoked keys and backends changed at runtime (YAML configuration)")
+	databaseRefresh := flag.Duration("database-refresh", config.DefaultDatabaseRefreshInterval, "How often users, keys and servers are reloaded from the database, 0 to query it on every lookup")
 	flag.Parse()
 
 	if configDB != nil && *configDB != "" && configYAML != nil && *configYAML != "" {
@@ -60,16 +67,28 @@ func main() {
 
 	// Create config based on available sources
 	if dbURL != "" {
-		logger.Info("Loading configuration from database", zap.String("url", dbURL))
-		cfg, err = config.NewDatabaseConfig(dbURL, logger)
+		options := config.DefaultDatabaseConfigOptions()
+		options.RefreshInterval = *databaseRefresh
+		if refresh := os.Getenv(EnvDatabaseRefresh); refresh != "" && !isFlagSet("database-refresh") {
+			if options.RefreshInterval, err = time.ParseDuration(refresh); err != nil {
+				logger.Fatal("Invalid "+EnvDatabaseRefresh, zap.String("value", refresh), zap.Error(err))
+			}
+		}
+		logger.Info("Loading configuration from database", zap.String("url", dbURL), zap.Duration("refresh", options.RefreshInterval))
+		cfg, err = config.NewDatabaseConfigWithOptions(dbURL, logger, options)
 		if err != nil {
 			logger.Fatal("Failed to create database config", zap.Error(err))
 		}
 	} else if yamlPath != "" {
-		logger.Info("Loading configuration from YAML file", zap.String("path", yamlPath))
-		cfg, err = config.NewYamlConfig(yamlPath, logger)
+		paths := strings.Split(yamlPath, ",")
+		logger.Info("Loading configuration from file", zap.Strings("paths", paths))
+		if len(paths) == 1 {
+			cfg, err = config.NewConfig(yamlPath, logger)
+		} else {
+			cfg, err = config.NewYamlConfigLayered(paths, logger)
+		}
 		if err != nil {
-			logger.Fatal("Failed to create YAML config", zap.Error(err))
+			logger.Fatal("Failed to create file config", zap.Error(err))
 		}
 	} else {
 		logger.Fatal("No configuration source specified")
@@ -111,6 +130,38 @@ func main() {
 		cancel()
 	}()
 
+	// Record configuration changes in the audit log, signed if a key is set
+	auditPath := os.Getenv(EnvAuditLog)
+	if *auditLog != "" {
+		auditPath = *auditLog
+	}
+	if auditPath != "" {
+		yamlCfg, ok := cfg.(*config.YamlConfig)
+		if !ok {
+			logger.Fatal("The audit log requires a configuration file", zap.String("path", auditPath))
+		}
+		var options []audit.Option
+		if key := os.Getenv(EnvAuditHMACKey); key != "" {
+			options = append(options, audit.WithSigner(audit.NewHMAC([]byte(key))))
+		}
+		auditLogger, auditFile, err := audit.OpenFile(auditPath, options...)
+		if err != nil {
+			logger.Fatal("Failed to open the audit log", zap.String("path", auditPath), zap.Error(err))
+		}
+		defer auditFile.Close()
+		yamlCfg.SetAuditLog(auditLogger)
+		logger.Info("Recording configuration changes in the audit log", zap.String("path", auditPath), zap.Bool("signed", len(options) > 0))
+	}
+
+	// Reload the YAML configuration when the file changes
+	if yamlCfg, ok := cfg.(*config.YamlConfig); ok {
+		go func() {
+			if err := yamlCfg.Watch(ctx); err != nil {
+				logger.Error("Stopped watching the configuration file, restart to apply changes", zap.Error(err))
+			}
+		}()
+	}
+
 	// Create and start the node
 	node, err := gateway.Start(ctx, logger, cfg, "")
 	if err != nil {
@@ -130,3 +181,14 @@ func main() {
 		logger.Warn("Gateway service shutdown timed out")
 	}
 }
+
+// isFlagSet reports whether the flag was given on the command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -17,7 +17,7 @@ func tryMCPDiscovery(ctx context.Context, targetURL string, authBearer string, l
 	logger.Debug("Attempting MCP discovery", zap.String("url", targetURL))
 
 	// Create a new MCP client for the target URL
-	mcpClient, err := client.New(shared.RandomID(), targetURL, logger.Named("mcp-discover-client"))
+	mcpClient, err := client.New(shared.NewID(), targetURL, logger.Named("mcp-discover-client"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MCP client for discovery: %w", err)
 	}
@@ -0,0 +1,164 @@
+package extra
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// BackendsPath is where the admin API for the backends is served: GET lists them, POST
+// and DELETE on BackendsPath + "/<id>" add or replace and remove one.
+const BackendsPath = "/admin/backends"
+
+// maxBackendSize limits the size of a backend definition sent to the admin API.
+const maxBackendSize = 1 << 20
+
+// BackendInfo describes a backend in the answers of the admin API. Secrets such as the
+// bearer token and the command of stdio backends are left out.
+type BackendInfo struct {
+	ID       string   `json:"id"`
+	URL      string   `json:"url,omitempty"`
+	Replicas []string `json:"replicas,omitempty"`
+	Stdio    bool     `json:"stdio,omitempty"`
+}
+
+// BackendsHandler serves the admin API changing the backends of editor, which is
+// usually cfg, at runtime. Requests must carry the API key of a user whose "role" param
+// is config.AdminRole as a bearer token, whatever the authorization type of the server.
+func BackendsHandler(cfg config.IConfig, editor config.BackendEditor, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeAdmin(cfg, w, r, logger)
+		if !ok {
+			return
+		}
+		backendID, hasID := strings.CutPrefix(r.URL.Path, BackendsPath+"/")
+		if !hasID {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", "GET")
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			listBackends(cfg, w, logger)
+			return
+		}
+		logger := logger.With(zap.String("userID", userID), zap.String("backend", backendID))
+
+		switch r.Method {
+		case http.MethodPost:
+			data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBackendSize))
+			if err != nil {
+				http.Error(w, "Failed to read backend: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			created, err := editor.PutBackend(backendID, data)
+			if errors.Is(err, config.ErrInvalidBackend) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				logger.Error("Failed to change backend", zap.Error(err))
+				http.Error(w, "Failed to change backend", http.StatusInternalServerError)
+				return
+			}
+			logger.Info("Backend changed with the admin API", zap.Bool("created", created))
+			backend, err := cfg.GetBackend(backendID)
+			if err != nil {
+				// Removed again in the meantime
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			status := http.StatusOK
+			if created {
+				status = http.StatusCreated
+			}
+			writeJSON(w, status, backendInfo(backendID, backend), logger)
+
+		case http.MethodDelete:
+			err := editor.RemoveBackend(backendID)
+			if errors.Is(err, config.ErrNotFound) {
+				http.Error(w, "Backend not found", http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				logger.Error("Failed to remove backend", zap.Error(err))
+				http.Error(w, "Failed to remove backend", http.StatusInternalServerError)
+				return
+			}
+			logger.Info("Backend removed with the admin API")
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.Header().Set("Allow", "POST, DELETE")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// authorizeAdmin returns the ID of the admin user sending the request, or answers it
+// with 401 or 403.
+func authorizeAdmin(cfg config.IConfig, w http.ResponseWriter, r *http.Request, logger *zap.Logger) (string, bool) {
+	key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || key == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Authorization required", http.StatusUnauthorized)
+		return "", false
+	}
+	userID, err := cfg.GetUserIDByKey(key)
+	if err != nil || userID == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return "", false
+	}
+	isAdmin, err := config.UserIsAdmin(cfg, userID)
+	if err != nil {
+		logger.Error("Failed to get user role", zap.String("userID", userID), zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return "", false
+	}
+	if !isAdmin {
+		http.Error(w, "Admin role required", http.StatusForbidden)
+		return "", false
+	}
+	return userID, true
+}
+
+// listBackends answers with the configured backends, sorted by ID.
+func listBackends(cfg config.IConfig, w http.ResponseWriter, logger *zap.Logger) {
+	backendIDs, err := cfg.BackendIDs()
+	if err != nil {
+		logger.Error("Failed to list backends", zap.Error(err))
+		http.Error(w, "Failed to list backends", http.StatusInternalServerError)
+		return
+	}
+	backends := make([]BackendInfo, 0, len(backendIDs))
+	for _, backendID := range backendIDs {
+		backend, err := cfg.GetBackend(backendID)
+		if err != nil {
+			continue // Removed in the meantime
+		}
+		backends = append(backends, backendInfo(backendID, backend))
+	}
+	writeJSON(w, http.StatusOK, map[string][]BackendInfo{"backends": backends}, logger)
+}
+
+func backendInfo(backendID string, backend *config.Backend) BackendInfo {
+	return BackendInfo{
+		ID:       backendID,
+		URL:      backend.URL,
+		Replicas: backend.Replicas,
+		Stdio:    len(backend.Command) > 0,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any, logger *zap.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Failed to encode admin API response", zap.Error(err))
+	}
+}
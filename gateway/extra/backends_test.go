@@ -0,0 +1,195 @@
+package extra_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gate4ai/mcp/gateway/extra"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"go.uber.org/zap"
+)
+
+func newBackendsServer(t *testing.T) (*httptest.Server, *config.YamlConfig) {
+	t.Helper()
+	cfg := testutil.NewConfigBuilder().
+		WithUser("root", "key-root").
+		WithUserParam("root", "role", "ADMIN").
+		WithUser("alice", "key-alice", config.AllBackends).
+		WithBackend("static", "http://static.example.com/sse").
+		Build(t)
+	handler := extra.BackendsHandler(cfg, cfg, zap.NewNop())
+	mux := http.NewServeMux()
+	mux.HandleFunc(extra.BackendsPath, handler)
+	mux.HandleFunc(extra.BackendsPath+"/", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, cfg
+}
+
+func adminRequest(t *testing.T, method string, url string, key string, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func listedBackends(t *testing.T, server *httptest.Server) []extra.BackendInfo {
+	t.Helper()
+	resp := adminRequest(t, http.MethodGet, server.URL+extra.BackendsPath, "key-root", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Listing backends: status %d", resp.StatusCode)
+	}
+	var list struct {
+		Backends []extra.BackendInfo `json:"backends"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	return list.Backends
+}
+
+func TestBackendsHandlerRequiresAdmin(t *testing.T) {
+	server, _ := newBackendsServer(t)
+
+	for _, tc := range []struct {
+		method, path, key string
+		want              int
+	}{
+		{http.MethodGet, extra.BackendsPath, "", http.StatusUnauthorized},
+		{http.MethodGet, extra.BackendsPath, "wrong-key", http.StatusUnauthorized},
+		// A user subscribed to every backend is no admin
+		{http.MethodPost, extra.BackendsPath + "/evil", "key-alice", http.StatusForbidden},
+	} {
+		if resp := adminRequest(t, tc.method, server.URL+tc.path, tc.key, "url: http://evil/sse"); resp.StatusCode != tc.want {
+			t.Errorf("%s %s with key %q: status %d, want %d", tc.method, tc.path, tc.key, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestBackendsHandlerAddsListsAndRemoves(t *testing.T) {
+	server, cfg := newBackendsServer(t)
+	url := server.URL + extra.BackendsPath + "/dynamic"
+
+	resp := adminRequest(t, http.MethodPost, url, "key-root", `{"url": "http://dynamic.example.com/sse", "replicas": ["http://dynamic-2.example.com/sse"], "bearer": "secret"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Adding a backend: status %d", resp.StatusCode)
+	}
+	var added extra.BackendInfo
+	if err := json.NewDecoder(resp.Body).Decode(&added); err != nil {
+		t.Fatal(err)
+	}
+	if want := (extra.BackendInfo{ID: "dynamic", URL: "http://dynamic.example.com/sse", Replicas: []string{"http://dynamic-2.example.com/sse"}}); !reflect.DeepEqual(added, want) {
+		t.Errorf("Added backend = %+v, want %+v", added, want)
+	}
+	if backend, err := cfg.GetBackend("dynamic"); err != nil || backend.Bearer != "secret" {
+		t.Errorf("Configured backend = %+v, %v", backend, err)
+	}
+
+	if resp := adminRequest(t, http.MethodPost, url, "key-root", "url: http://dynamic-3.example.com/sse\n"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Replacing a backend: status %d", resp.StatusCode)
+	}
+	// Definitions running processes or reading the host's files and variables are refused
+	for _, definition := range []string{"command: [mcp-server, --stdio]", "url: ${HOME}", "url: http://evil/sse\nbearer: file:/etc/passwd"} {
+		if resp := adminRequest(t, http.MethodPost, url, "key-root", definition); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Replacing a backend with %q: status %d, want 400", definition, resp.StatusCode)
+		}
+	}
+	want := []extra.BackendInfo{{ID: "dynamic", URL: "http://dynamic-3.example.com/sse"}, {ID: "static", URL: "http://static.example.com/sse"}}
+	if backends := listedBackends(t, server); !reflect.DeepEqual(backends, want) {
+		t.Errorf("Listed backends = %+v, want %+v", backends, want)
+	}
+
+	if resp := adminRequest(t, http.MethodPost, server.URL+extra.BackendsPath+"/broken", "key-root", "url: not-a-url"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Adding an invalid backend: status %d", resp.StatusCode)
+	}
+
+	if resp := adminRequest(t, http.MethodDelete, url, "key-root", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Removing a backend: status %d", resp.StatusCode)
+	}
+	if resp := adminRequest(t, http.MethodDelete, url, "key-root", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Removing an unknown backend: status %d", resp.StatusCode)
+	}
+	want = []extra.BackendInfo{{ID: "static", URL: "http://static.example.com/sse"}}
+	if backends := listedBackends(t, server); !reflect.DeepEqual(backends, want) {
+		t.Errorf("Listed backends after removal = %+v, want %+v", backends, want)
+	}
+
+	resp = adminRequest(t, http.MethodPut, url, "key-root", "")
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != "POST, DELETE" {
+		t.Errorf("PUT: status %d, Allow %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+}
+
+func TestBackendsHandlerConcurrentWithReads(t *testing.T) {
+	server, cfg := newBackendsServer(t)
+	send := func(method string, url string, body string) (int, error) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Authorization", "Bearer key-root")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				url := fmt.Sprintf("%s%s/b%d-%d", server.URL, extra.BackendsPath, i, j)
+				if status, err := send(http.MethodPost, url, fmt.Sprintf("url: http://b%d-%d/sse", i, j)); err != nil || status != http.StatusCreated {
+					t.Errorf("Adding %s: status %d, %v", url, status, err)
+					return
+				}
+				if j%2 == 1 {
+					if status, err := send(http.MethodDelete, url, ""); err != nil || status != http.StatusNoContent {
+						t.Errorf("Removing %s: status %d, %v", url, status, err)
+						return
+					}
+				}
+			}
+		}()
+		// Reads as the proxy does them while routing requests
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if backend, err := cfg.GetBackend("static"); err != nil || backend.URL != "http://static.example.com/sse" {
+					t.Errorf("GetBackend during changes = %+v, %v", backend, err)
+					return
+				}
+				if _, err := config.UserBackends(cfg, "alice"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if backends := listedBackends(t, server); len(backends) != 1+4*5 {
+		t.Errorf("%d backends after the changes, want %d", len(backends), 1+4*5)
+	}
+}
@@ -0,0 +1,133 @@
+package extra
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/server/mcp"
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// SessionsPath is where the admin API for the client sessions is served: GET lists the
+// active ones, and DELETE on SessionsPath + "/<id>" terminates one.
+const SessionsPath = "/admin/sessions"
+
+// KeysPath is where the admin API for the API keys is served: DELETE on
+// KeysPath + "/<hash>" revokes the key stored as hash, and with ?terminate_sessions=true
+// terminates the sessions of its user too.
+const KeysPath = "/admin/keys"
+
+// SessionInfo describes an active client session in the answers of the admin API.
+type SessionInfo struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userID,omitempty"`
+	Transport   string    `json:"transport,omitempty"` // See transport.GetTransportName
+	ConnectedAt time.Time `json:"connectedAt"`
+	Backends    []string  `json:"backends,omitempty"` // Connected to for the session
+}
+
+// RevokedKeyInfo answers the revocation of a key.
+type RevokedKeyInfo struct {
+	UserID             string `json:"userID"`
+	TerminatedSessions int    `json:"terminatedSessions"`
+}
+
+// SessionsHandler serves the admin API listing and terminating the client sessions of
+// manager. backends returns the backends a session is connected to from its params.
+// Terminated sessions lose their stream, and their further requests are rejected as
+// for any unknown session. Requests are authorized as by BackendsHandler.
+func SessionsHandler(cfg config.IConfig, manager *mcp.Manager, backends func(sessionParams *sync.Map) []string, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authorizeAdmin(cfg, w, r, logger)
+		if !ok {
+			return
+		}
+		sessionID, hasID := strings.CutPrefix(r.URL.Path, SessionsPath+"/")
+		if !hasID {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", "GET")
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			sessions := manager.Sessions()
+			infos := make([]SessionInfo, 0, len(sessions))
+			for _, session := range sessions {
+				infos = append(infos, SessionInfo{
+					ID:          session.GetID(),
+					UserID:      session.UserID,
+					Transport:   transport.GetTransportName(session.GetParams()),
+					ConnectedAt: session.CreatedAt,
+					Backends:    backends(session.GetParams()),
+				})
+			}
+			writeJSON(w, http.StatusOK, map[string][]SessionInfo{"sessions": infos}, logger)
+			return
+		}
+
+		if r.Method != http.MethodDelete {
+			w.Header().Set("Allow", "DELETE")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if _, err := manager.GetSession(sessionID); err != nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		manager.CloseSession(sessionID)
+		logger.Info("Session terminated with the admin API", zap.String("userID", userID), zap.String("sessionID", sessionID))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// KeysHandler serves the admin API revoking the API keys of revoker, which is usually
+// cfg, optionally terminating the sessions of their users in manager. Requests are
+// authorized as by BackendsHandler.
+func KeysHandler(cfg config.IConfig, revoker config.KeyRevoker, manager *mcp.Manager, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID, ok := authorizeAdmin(cfg, w, r, logger)
+		if !ok {
+			return
+		}
+		if r.Method != http.MethodDelete {
+			w.Header().Set("Allow", "DELETE")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		terminate := false
+		if value := r.URL.Query().Get("terminate_sessions"); value != "" {
+			var err error
+			if terminate, err = strconv.ParseBool(value); err != nil {
+				http.Error(w, "Invalid terminate_sessions: "+value, http.StatusBadRequest)
+				return
+			}
+		}
+
+		keyHash, hasHash := strings.CutPrefix(r.URL.Path, KeysPath+"/")
+		if !hasHash || keyHash == "" {
+			http.Error(w, "Key not found", http.StatusNotFound)
+			return
+		}
+		userID, err := revoker.RevokeKey(keyHash)
+		if errors.Is(err, config.ErrNotFound) {
+			http.Error(w, "Key not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to revoke key", zap.Error(err))
+			http.Error(w, "Failed to revoke key", http.StatusInternalServerError)
+			return
+		}
+		revoked := RevokedKeyInfo{UserID: userID}
+		if terminate {
+			revoked.TerminatedSessions = manager.CloseUserSessions(userID)
+		}
+		logger.Info("Key revoked with the admin API", zap.String("adminID", adminID), zap.String("userID", userID), zap.Int("terminatedSessions", revoked.TerminatedSessions))
+		writeJSON(w, http.StatusOK, revoked, logger)
+	}
+}
@@ -0,0 +1,126 @@
+// Package listcache implements the cache of the answers of a backend to read-only list
+// methods such as tools/list, shared by all client sessions of the gateway.
+package listcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Key identifies an answer of the backend: the method and a hash of the request params.
+type Key struct {
+	Method string
+	Params string
+}
+
+// NewKey returns the key of a request of method with params.
+func NewKey(method string, params any) Key {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		encoded = nil // Requests with params that cannot be encoded share one key
+	}
+	hash := sha256.Sum256(encoded)
+	return Key{Method: method, Params: hex.EncodeToString(hash[:])}
+}
+
+// Cache keeps the answers of a backend for a TTL, at most maxEntries of them; once full,
+// the oldest answer is dropped to make room. Invalidate drops the answers of a method
+// and also discards answers being fetched meanwhile, which might be stale: their Put is
+// ignored. A Cache is safe for concurrent use.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu          sync.Mutex
+	entries     map[Key]*entry
+	generations map[string]uint64 // Method -> number of invalidations
+}
+
+type entry struct {
+	value   any
+	expires time.Time
+}
+
+// New creates an empty cache.
+func New(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:         ttl,
+		maxEntries:  maxEntries,
+		now:         time.Now,
+		entries:     make(map[Key]*entry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Get returns the answer cached for key. On a miss it returns the generation to pass to
+// Put with the answer fetched from the backend.
+func (c *Cache) Get(key Key) (value any, generation uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, found := c.entries[key]; found {
+		if c.now().Before(e.expires) {
+			return e.value, 0, true
+		}
+		delete(c.entries, key)
+	}
+	return nil, c.generations[key.Method], false
+}
+
+// Put caches the answer for key, unless the method was invalidated since Get returned
+// generation.
+func (c *Cache) Put(key Key, value any, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[key.Method] != generation || c.maxEntries <= 0 {
+		return
+	}
+	now := c.now()
+	if _, found := c.entries[key]; !found && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = &entry{value: value, expires: now.Add(c.ttl)}
+}
+
+// evict drops the expired answers or, if there are none, the oldest one.
+func (c *Cache) evict(now time.Time) {
+	var oldest Key
+	var oldestExpires time.Time
+	expired := false
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+			expired = true
+			continue
+		}
+		if oldestExpires.IsZero() || e.expires.Before(oldestExpires) {
+			oldest, oldestExpires = key, e.expires
+		}
+	}
+	if !expired && !oldestExpires.IsZero() {
+		delete(c.entries, oldest)
+	}
+}
+
+// Invalidate drops the answers to the method, e.g. when the backend notifies that the
+// list changed.
+func (c *Cache) Invalidate(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[method]++
+	for key := range c.entries {
+		if key.Method == method {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Len returns the number of answers cached, including expired ones not dropped yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
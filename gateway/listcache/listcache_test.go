@@ -0,0 +1,89 @@
+package listcache
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestCache returns a cache with a clock the test advances.
+func newTestCache(maxEntries int) (*Cache, *time.Time) {
+	now := time.Unix(1000, 0)
+	c := New(time.Minute, maxEntries)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestHitUntilExpired(t *testing.T) {
+	c, now := newTestCache(10)
+	key := NewKey("tools/list", map[string]any{})
+	if _, _, ok := c.Get(key); ok {
+		t.Fatalf("Expected a miss on an empty cache")
+	}
+	_, generation, _ := c.Get(key)
+	c.Put(key, "tools", generation)
+
+	if value, _, ok := c.Get(key); !ok || value != "tools" {
+		t.Fatalf("Expected the cached answer, got %v, %v", value, ok)
+	}
+	if _, _, ok := c.Get(NewKey("tools/list", map[string]any{"cursor": "2"})); ok {
+		t.Fatalf("Expected other params to miss")
+	}
+	*now = now.Add(time.Minute)
+	if _, _, ok := c.Get(key); ok {
+		t.Fatalf("Expected the answer to expire after the TTL")
+	}
+}
+
+func TestInvalidateDropsMethod(t *testing.T) {
+	c, _ := newTestCache(10)
+	tools, prompts := NewKey("tools/list", nil), NewKey("prompts/list", nil)
+	c.Put(tools, "tools", 0)
+	c.Put(prompts, "prompts", 0)
+
+	c.Invalidate("tools/list")
+	if _, _, ok := c.Get(tools); ok {
+		t.Fatalf("Expected the invalidated answer to be dropped")
+	}
+	if _, _, ok := c.Get(prompts); !ok {
+		t.Fatalf("Expected the answers of other methods to be kept")
+	}
+}
+
+func TestPutAfterInvalidateIgnored(t *testing.T) {
+	c, _ := newTestCache(10)
+	key := NewKey("tools/list", nil)
+	_, generation, _ := c.Get(key)
+
+	// The list changes while its answer is being fetched
+	c.Invalidate("tools/list")
+	c.Put(key, "stale", generation)
+	if _, _, ok := c.Get(key); ok {
+		t.Fatalf("Expected an answer fetched before the invalidation not to be cached")
+	}
+
+	_, generation, _ = c.Get(key)
+	c.Put(key, "fresh", generation)
+	if value, _, ok := c.Get(key); !ok || value != "fresh" {
+		t.Fatalf("Expected the answer fetched after the invalidation, got %v", value)
+	}
+}
+
+func TestMaxEntriesEvictsOldest(t *testing.T) {
+	c, now := newTestCache(2)
+	first, second, third := NewKey("tools/list", 1), NewKey("tools/list", 2), NewKey("tools/list", 3)
+	c.Put(first, 1, 0)
+	*now = now.Add(time.Second)
+	c.Put(second, 2, 0)
+	*now = now.Add(time.Second)
+	c.Put(third, 3, 0)
+
+	if n := c.Len(); n != 2 {
+		t.Fatalf("Expected at most 2 entries, got %d", n)
+	}
+	if _, _, ok := c.Get(first); ok {
+		t.Fatalf("Expected the oldest answer to be evicted")
+	}
+	if _, _, ok := c.Get(third); !ok {
+		t.Fatalf("Expected the newest answer to be cached")
+	}
+}
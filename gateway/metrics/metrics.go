@@ -0,0 +1,427 @@
+// Package metrics records gateway request metrics and exposes them in the
+// Prometheus text exposition format: requests of clients, the requests the gateway
+// sends to backends for them, and open SSE streams.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	schema2024 "github.com/gate4ai/mcp/shared/mcp/2024/schema"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency histograms.
+// They cover fast calls such as tools/list (milliseconds) as well as slow tool calls
+// and generation tasks (minutes).
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// MaxSeries bounds the number of label combinations per metric. Backends come from the
+// configuration, but passthrough relays methods chosen by clients; once the limit is
+// reached, new methods are recorded as OtherMethod.
+const MaxSeries = 1000
+
+// OtherMethod is the method label of requests beyond MaxSeries.
+const OtherMethod = "other"
+
+// OtherCode is the code label of client request errors beyond MaxSeries, e.g. unusual
+// application codes relayed from backends.
+const OtherCode = "other"
+
+// OtherVersion is the version label of protocol versions not in KnownVersions, so that
+// versions sent by clients cannot grow the number of series.
+const OtherVersion = "other"
+
+// KnownVersions are the protocol versions used as version labels.
+var KnownVersions = []string{schema2024.PROTOCOL_VERSION, schema2025.PROTOCOL_VERSION}
+
+// Result classes of backend requests, used as the "result" label.
+const (
+	ResultSuccess        = "success"
+	ResultParseError     = "parse_error"      // -32700
+	ResultInvalidRequest = "invalid_request"  // -32600
+	ResultMethodNotFound = "method_not_found" // -32601
+	ResultInvalidParams  = "invalid_params"   // -32602
+	ResultInternalError  = "internal_error"   // -32603
+	ResultServerError    = "server_error"     // -32099..-32000, reserved for implementation-defined server errors
+	ResultApplication    = "application_error"
+	ResultTimeout        = "timeout"
+	ResultTransport      = "transport_error" // No JSON-RPC response, e.g. connection failures
+)
+
+// ResultClass maps the outcome of a request to one of the result classes.
+func ResultClass(err error) string {
+	if err == nil {
+		return ResultSuccess
+	}
+	var rpcErr *shared.JSONRPCError
+	if !errors.As(err, &rpcErr) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ResultTimeout
+		}
+		return ResultTransport
+	}
+	switch code := rpcErr.Code; {
+	case code == -32700:
+		return ResultParseError
+	case code == -32600:
+		return ResultInvalidRequest
+	case code == -32601:
+		return ResultMethodNotFound
+	case code == -32602:
+		return ResultInvalidParams
+	case code == -32603:
+		return ResultInternalError
+	case code >= -32099 && code <= -32000:
+		return ResultServerError
+	default:
+		return ResultApplication
+	}
+}
+
+type seriesKey struct {
+	method  string
+	backend string
+}
+
+type resultKey struct {
+	seriesKey
+	result string
+}
+
+type relayKey struct {
+	seriesKey
+	clientVersion  string
+	backendVersion string
+	adapted        bool
+}
+
+type requestErrorKey struct {
+	method string
+	code   string
+}
+
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative; the last one counts values above all bounds
+	sum    float64
+	count  uint64
+}
+
+// observe counts a duration in the bucket of the first bound not below it.
+func (h *histogram) observe(buckets []float64, duration time.Duration) {
+	seconds := duration.Seconds()
+	h.counts[sort.SearchFloat64s(buckets, seconds)]++
+	h.sum += seconds
+	h.count++
+}
+
+// Registry holds the metrics of client requests handled by the gateway and of the
+// backend requests made for them.
+type Registry struct {
+	buckets []float64
+
+	mu            sync.Mutex
+	latency       map[seriesKey]*histogram
+	results       map[resultKey]uint64
+	relays        map[relayKey]uint64
+	requests      map[string]*histogram // By method of the client request
+	requestErrors map[requestErrorKey]uint64
+	sseStreams    func() int64                         // Open SSE streams, nil if not reported
+	sseShutdown   func() (drained int64, forced int64) // Streams closed by a shutdown, nil if not reported
+}
+
+// NewRegistry creates an empty registry with the given latency buckets in seconds,
+// or DefaultLatencyBuckets if buckets is empty.
+func NewRegistry(buckets []float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	return &Registry{
+		buckets: append([]float64(nil), buckets...),
+		latency: make(map[seriesKey]*histogram),
+		results: make(map[resultKey]uint64),
+		relays:  make(map[relayKey]uint64),
+
+		requests:      make(map[string]*histogram),
+		requestErrors: make(map[requestErrorKey]uint64),
+	}
+}
+
+// ObserveRequest records the duration of a request of a client and, if it failed, the
+// JSON-RPC error code it was answered with. It implements shared.RequestObserver.
+func (r *Registry) ObserveRequest(method string, duration time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.requests[method]
+	if !ok {
+		if len(r.requests) >= MaxSeries {
+			method = OtherMethod
+			h = r.requests[method]
+		}
+		if h == nil {
+			h = &histogram{counts: make([]uint64, len(r.buckets)+1)}
+			r.requests[method] = h
+		}
+	}
+	h.observe(r.buckets, duration)
+	if err == nil {
+		return
+	}
+	key := requestErrorKey{method: method, code: strconv.Itoa(ErrorCode(err))}
+	if _, ok := r.requestErrors[key]; !ok && len(r.requestErrors) >= MaxSeries {
+		key.code = OtherCode
+	}
+	r.requestErrors[key]++
+}
+
+// ErrorCode returns the JSON-RPC error code a client is answered with for err: its code
+// if it is a *shared.JSONRPCError, otherwise the internal error code, as in
+// shared.BaseSession.SendResponse.
+func ErrorCode(err error) int {
+	if rpcErr, ok := err.(*shared.JSONRPCError); ok {
+		return rpcErr.Code
+	}
+	return shared.JSONRPCErrorInternal
+}
+
+// SetSSEStreams reports the number of open SSE streams returned by count.
+func (r *Registry) SetSSEStreams(count func() int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sseStreams = count
+}
+
+// SetSSEShutdownStreams reports the numbers of SSE streams closed by a shutdown returned
+// by count: those drained once their requests were answered and those closed earlier.
+func (r *Registry) SetSSEShutdownStreams(count func() (drained int64, forced int64)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sseShutdown = count
+}
+
+// ObserveBackendRequest records the duration and result of a request sent to a backend.
+func (r *Registry) ObserveBackendRequest(method, backend string, duration time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := seriesKey{method: method, backend: backend}
+	h, ok := r.latency[key]
+	if !ok {
+		if len(r.latency) >= MaxSeries {
+			key.method = OtherMethod
+			h = r.latency[key]
+		}
+		if h == nil {
+			h = &histogram{counts: make([]uint64, len(r.buckets)+1)}
+			r.latency[key] = h
+		}
+	}
+	h.observe(r.buckets, duration)
+	r.results[resultKey{seriesKey: key, result: ResultClass(err)}]++
+}
+
+// ObserveRelay counts a request relayed to a backend by the protocol versions the client
+// and the backend negotiated, and whether a version adapter converted the messages.
+func (r *Registry) ObserveRelay(method, backend, clientVersion, backendVersion string, adapted bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := relayKey{
+		seriesKey:      seriesKey{method: method, backend: backend},
+		clientVersion:  versionLabel(clientVersion),
+		backendVersion: versionLabel(backendVersion),
+		adapted:        adapted,
+	}
+	if _, ok := r.relays[key]; !ok && len(r.relays) >= MaxSeries {
+		key.method = OtherMethod
+	}
+	r.relays[key]++
+}
+
+// versionLabel returns the version, or OtherVersion if it is not one of KnownVersions.
+func versionLabel(version string) string {
+	for _, known := range KnownVersions {
+		if version == known {
+			return version
+		}
+	}
+	return OtherVersion
+}
+
+// Handler serves the metrics in the Prometheus text exposition format.
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		out := bufio.NewWriter(w)
+		r.write(out)
+		out.Flush()
+	}
+}
+
+// write renders all metrics, with series sorted by label values.
+func (r *Registry) write(out *bufio.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writeRequests(out)
+	if r.sseStreams != nil {
+		fmt.Fprintln(out, "# HELP gate4ai_active_sse_streams SSE streams and WebSockets open to clients.")
+		fmt.Fprintln(out, "# TYPE gate4ai_active_sse_streams gauge")
+		fmt.Fprintf(out, "gate4ai_active_sse_streams %d\n", r.sseStreams())
+	}
+	if r.sseShutdown != nil {
+		drained, forced := r.sseShutdown()
+		fmt.Fprintln(out, "# HELP gate4ai_sse_streams_shutdown_total SSE streams and WebSockets closed by a shutdown, drained once their requests were answered or forced.")
+		fmt.Fprintln(out, "# TYPE gate4ai_sse_streams_shutdown_total counter")
+		fmt.Fprintf(out, "gate4ai_sse_streams_shutdown_total{outcome=\"drained\"} %d\n", drained)
+		fmt.Fprintf(out, "gate4ai_sse_streams_shutdown_total{outcome=\"forced\"} %d\n", forced)
+	}
+
+	keys := make([]seriesKey, 0, len(r.latency))
+	for key := range r.latency {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].backend < keys[j].backend
+	})
+
+	fmt.Fprintln(out, "# HELP gate4ai_backend_request_duration_seconds Latency of requests sent to backends.")
+	fmt.Fprintln(out, "# TYPE gate4ai_backend_request_duration_seconds histogram")
+	for _, key := range keys {
+		r.writeHistogram(out, "gate4ai_backend_request_duration_seconds", seriesLabels(key), r.latency[key])
+	}
+
+	resultKeys := make([]resultKey, 0, len(r.results))
+	for key := range r.results {
+		resultKeys = append(resultKeys, key)
+	}
+	sort.Slice(resultKeys, func(i, j int) bool {
+		a, b := resultKeys[i], resultKeys[j]
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.backend != b.backend {
+			return a.backend < b.backend
+		}
+		return a.result < b.result
+	})
+
+	fmt.Fprintln(out, "# HELP gate4ai_backend_requests_total Requests sent to backends by result class.")
+	fmt.Fprintln(out, "# TYPE gate4ai_backend_requests_total counter")
+	for _, key := range resultKeys {
+		fmt.Fprintf(out, "gate4ai_backend_requests_total{%s,result=%q} %d\n", seriesLabels(key.seriesKey), key.result, r.results[key])
+	}
+
+	if len(r.relays) == 0 {
+		return
+	}
+	relayKeys := make([]relayKey, 0, len(r.relays))
+	for key := range r.relays {
+		relayKeys = append(relayKeys, key)
+	}
+	sort.Slice(relayKeys, func(i, j int) bool {
+		a, b := relayKeys[i], relayKeys[j]
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.backend != b.backend {
+			return a.backend < b.backend
+		}
+		if a.clientVersion != b.clientVersion {
+			return a.clientVersion < b.clientVersion
+		}
+		if a.backendVersion != b.backendVersion {
+			return a.backendVersion < b.backendVersion
+		}
+		return !a.adapted && b.adapted
+	})
+
+	fmt.Fprintln(out, "# HELP gate4ai_backend_relays_total Requests relayed to backends by negotiated protocol versions.")
+	fmt.Fprintln(out, "# TYPE gate4ai_backend_relays_total counter")
+	for _, key := range relayKeys {
+		fmt.Fprintf(out, "gate4ai_backend_relays_total{%s,client_version=%q,backend_version=%q,adapted=\"%t\"} %d\n",
+			seriesLabels(key.seriesKey), key.clientVersion, key.backendVersion, key.adapted, r.relays[key])
+	}
+}
+
+// writeRequests renders the metrics of client requests.
+func (r *Registry) writeRequests(out *bufio.Writer) {
+	methods := make([]string, 0, len(r.requests))
+	for method := range r.requests {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	fmt.Fprintln(out, "# HELP gate4ai_requests_total Requests of clients answered by the gateway.")
+	fmt.Fprintln(out, "# TYPE gate4ai_requests_total counter")
+	for _, method := range methods {
+		fmt.Fprintf(out, "gate4ai_requests_total{method=%s} %d\n", quoteLabel(method), r.requests[method].count)
+	}
+
+	errorKeys := make([]requestErrorKey, 0, len(r.requestErrors))
+	for key := range r.requestErrors {
+		errorKeys = append(errorKeys, key)
+	}
+	sort.Slice(errorKeys, func(i, j int) bool {
+		if errorKeys[i].method != errorKeys[j].method {
+			return errorKeys[i].method < errorKeys[j].method
+		}
+		return errorKeys[i].code < errorKeys[j].code
+	})
+	fmt.Fprintln(out, "# HELP gate4ai_request_errors_total Requests of clients answered with an error, by JSON-RPC error code.")
+	fmt.Fprintln(out, "# TYPE gate4ai_request_errors_total counter")
+	for _, key := range errorKeys {
+		fmt.Fprintf(out, "gate4ai_request_errors_total{method=%s,code=%q} %d\n", quoteLabel(key.method), key.code, r.requestErrors[key])
+	}
+
+	fmt.Fprintln(out, "# HELP gate4ai_request_duration_seconds Time the gateway took to answer requests of clients.")
+	fmt.Fprintln(out, "# TYPE gate4ai_request_duration_seconds histogram")
+	for _, method := range methods {
+		r.writeHistogram(out, "gate4ai_request_duration_seconds", "method="+quoteLabel(method), r.requests[method])
+	}
+}
+
+// writeHistogram renders the series of a histogram with the given labels.
+func (r *Registry) writeHistogram(out *bufio.Writer, name string, labels string, h *histogram) {
+	var cumulative uint64
+	for i, bound := range r.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(out, "%s_bucket{%s,le=%q} %d\n", name, labels, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(out, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(out, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(out, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+// seriesLabels renders the method and backend labels.
+func seriesLabels(key seriesKey) string {
+	return fmt.Sprintf("method=%s,backend=%s", quoteLabel(key.method), quoteLabel(key.backend))
+}
+
+// quoteLabel quotes a label value as required by the exposition format.
+func quoteLabel(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
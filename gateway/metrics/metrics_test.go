@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler()(rec, httptest.NewRequest("GET", "/metrics", nil))
+	return rec.Body.String()
+}
+
+func assertContains(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Missing line %q in:\n%s", line, body)
+		}
+	}
+}
+
+func TestHistogramObservesDurations(t *testing.T) {
+	r := NewRegistry([]float64{0.01, 0.1, 1})
+	r.ObserveBackendRequest("tools/list", "b1", 5*time.Millisecond, nil)
+	r.ObserveBackendRequest("tools/list", "b1", 100*time.Millisecond, nil) // On a bound: counted in it
+	r.ObserveBackendRequest("tools/list", "b1", 3*time.Second, nil)
+	r.ObserveBackendRequest("tools/call", "b2", 50*time.Millisecond, nil)
+
+	body := scrape(t, r)
+	assertContains(t, body,
+		"# TYPE gate4ai_backend_request_duration_seconds histogram",
+		`gate4ai_backend_request_duration_seconds_bucket{method="tools/list",backend="b1",le="0.01"} 1`,
+		`gate4ai_backend_request_duration_seconds_bucket{method="tools/list",backend="b1",le="0.1"} 2`,
+		`gate4ai_backend_request_duration_seconds_bucket{method="tools/list",backend="b1",le="1"} 2`,
+		`gate4ai_backend_request_duration_seconds_bucket{method="tools/list",backend="b1",le="+Inf"} 3`,
+		`gate4ai_backend_request_duration_seconds_sum{method="tools/list",backend="b1"} 3.105`,
+		`gate4ai_backend_request_duration_seconds_count{method="tools/list",backend="b1"} 3`,
+		`gate4ai_backend_request_duration_seconds_bucket{method="tools/call",backend="b2",le="0.01"} 0`,
+		`gate4ai_backend_request_duration_seconds_bucket{method="tools/call",backend="b2",le="0.1"} 1`,
+	)
+}
+
+func TestResultCountersPerCodeClass(t *testing.T) {
+	r := NewRegistry(nil)
+	observe := func(err error) { r.ObserveBackendRequest("tools/call", "b1", time.Millisecond, err) }
+	observe(nil)
+	observe(nil)
+	observe(&shared.JSONRPCError{Code: -32602, Message: "bad"})
+	observe(fmt.Errorf("wrapped: %w", &shared.JSONRPCError{Code: -32010, Message: "busy"}))
+	observe(&shared.JSONRPCError{Code: 42, Message: "tool failed"})
+	observe(context.DeadlineExceeded)
+	observe(errors.New("connection refused"))
+
+	assertContains(t, scrape(t, r),
+		"# TYPE gate4ai_backend_requests_total counter",
+		`gate4ai_backend_requests_total{method="tools/call",backend="b1",result="success"} 2`,
+		`gate4ai_backend_requests_total{method="tools/call",backend="b1",result="invalid_params"} 1`,
+		`gate4ai_backend_requests_total{method="tools/call",backend="b1",result="server_error"} 1`,
+		`gate4ai_backend_requests_total{method="tools/call",backend="b1",result="application_error"} 1`,
+		`gate4ai_backend_requests_total{method="tools/call",backend="b1",result="timeout"} 1`,
+		`gate4ai_backend_requests_total{method="tools/call",backend="b1",result="transport_error"} 1`,
+	)
+}
+
+func TestSeriesAreBounded(t *testing.T) {
+	r := NewRegistry(nil)
+	for i := 0; i < MaxSeries+10; i++ {
+		r.ObserveBackendRequest(fmt.Sprintf("custom/%d", i), "b1", time.Millisecond, nil)
+	}
+	if len(r.latency) > MaxSeries+1 {
+		t.Fatalf("Expected at most %d series, got %d", MaxSeries+1, len(r.latency))
+	}
+	assertContains(t, scrape(t, r),
+		`gate4ai_backend_request_duration_seconds_count{method="other",backend="b1"} 10`,
+	)
+}
+
+func TestRelaysCountedByVersions(t *testing.T) {
+	r := NewRegistry(nil)
+	r.ObserveRelay("tools/call", "b1", "2025-03-26", "2024-11-05", true)
+	r.ObserveRelay("tools/call", "b1", "2025-03-26", "2024-11-05", true)
+	r.ObserveRelay("tools/call", "b1", "2024-11-05", "2024-11-05", false)
+	r.ObserveRelay("tools/call", "b1", "1999-01-01", "2024-11-05", true) // Unknown versions are bounded
+
+	assertContains(t, scrape(t, r),
+		"# TYPE gate4ai_backend_relays_total counter",
+		`gate4ai_backend_relays_total{method="tools/call",backend="b1",client_version="2025-03-26",backend_version="2024-11-05",adapted="true"} 2`,
+		`gate4ai_backend_relays_total{method="tools/call",backend="b1",client_version="2024-11-05",backend_version="2024-11-05",adapted="false"} 1`,
+		`gate4ai_backend_relays_total{method="tools/call",backend="b1",client_version="other",backend_version="2024-11-05",adapted="true"} 1`,
+	)
+}
+
+func TestClientRequestsCountedByMethodAndCode(t *testing.T) {
+	r := NewRegistry([]float64{0.1, 1})
+	var _ shared.RequestObserver = r
+	r.ObserveRequest("tools/call", 50*time.Millisecond, nil)
+	r.ObserveRequest("tools/call", 2*time.Second, &shared.JSONRPCError{Code: -32602, Message: "bad"})
+	r.ObserveRequest("tools/call", time.Millisecond, errors.New("backend unavailable")) // Answered as an internal error
+	r.ObserveRequest("tools/list", 0, &shared.JSONRPCError{Code: -32029, Message: "rate limited"})
+
+	assertContains(t, scrape(t, r),
+		"# TYPE gate4ai_requests_total counter",
+		`gate4ai_requests_total{method="tools/call"} 3`,
+		`gate4ai_requests_total{method="tools/list"} 1`,
+		"# TYPE gate4ai_request_errors_total counter",
+		`gate4ai_request_errors_total{method="tools/call",code="-32602"} 1`,
+		`gate4ai_request_errors_total{method="tools/call",code="-32603"} 1`,
+		`gate4ai_request_errors_total{method="tools/list",code="-32029"} 1`,
+		"# TYPE gate4ai_request_duration_seconds histogram",
+		`gate4ai_request_duration_seconds_bucket{method="tools/call",le="0.1"} 2`,
+		`gate4ai_request_duration_seconds_bucket{method="tools/call",le="+Inf"} 3`,
+		`gate4ai_request_duration_seconds_count{method="tools/list"} 1`,
+	)
+}
+
+func TestClientRequestSeriesAreBounded(t *testing.T) {
+	r := NewRegistry(nil)
+	for i := 0; i < MaxSeries+10; i++ {
+		r.ObserveRequest(fmt.Sprintf("custom/%d", i), time.Millisecond, &shared.JSONRPCError{Code: -i, Message: "failed"})
+	}
+	if len(r.requests) > MaxSeries+1 || len(r.requestErrors) > MaxSeries+1 {
+		t.Fatalf("Expected at most %d series, got %d and %d", MaxSeries+1, len(r.requests), len(r.requestErrors))
+	}
+	assertContains(t, scrape(t, r),
+		`gate4ai_requests_total{method="other"} 10`,
+	)
+}
+
+func TestActiveSSEStreamsGauge(t *testing.T) {
+	r := NewRegistry(nil)
+	if body := scrape(t, r); strings.Contains(body, "gate4ai_active_sse_streams") {
+		t.Errorf("Expected no stream gauge until it is reported:\n%s", body)
+	}
+	streams := int64(3)
+	r.SetSSEStreams(func() int64 { return streams })
+	assertContains(t, scrape(t, r), "# TYPE gate4ai_active_sse_streams gauge", "gate4ai_active_sse_streams 3")
+	streams = 1
+	assertContains(t, scrape(t, r), "gate4ai_active_sse_streams 1")
+
+	r.SetSSEShutdownStreams(func() (int64, int64) { return 4, 1 })
+	assertContains(t, scrape(t, r), "# TYPE gate4ai_sse_streams_shutdown_total counter",
+		`gate4ai_sse_streams_shutdown_total{outcome="drained"} 4`,
+		`gate4ai_sse_streams_shutdown_total{outcome="forced"} 1`)
+}
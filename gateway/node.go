@@ -5,18 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	gwCapabilities "github.com/gate4ai/mcp/gateway/capability"
 	"github.com/gate4ai/mcp/gateway/discovering"
 	"github.com/gate4ai/mcp/gateway/extra"
+	"github.com/gate4ai/mcp/server/a2a"
+	"github.com/gate4ai/mcp/server/accesslog"
 	serverextra "github.com/gate4ai/mcp/server/extra"
 	"github.com/gate4ai/mcp/server/mcp"
 	serverCapabilities "github.com/gate4ai/mcp/server/mcp/capability"
 	"github.com/gate4ai/mcp/server/mcp/validators"
 	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
 	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/privacy"
+	"github.com/gate4ai/mcp/shared/tracing"
 	"go.uber.org/zap"
 )
 
@@ -26,16 +32,46 @@ type Node struct {
 	cfg             config.IConfig
 	serverTransport *transport.Transport
 	sessionManager  *mcp.Manager
-	httpServer      *http.Server   // Store the server instance
-	listenerErrChan <-chan error   // Channel for listener errors
-	shutdownWg      sync.WaitGroup // WaitGroup for shutdown
+	gateway         *gwCapabilities.GatewayCapability
+	a2aSkills       *a2a.SkillSet        // Skills announced in the A2A agent cards besides the configured ones
+	a2aTasks        *a2a.MemoryTaskStore // Tasks of the A2A agents, counted on /status
+	a2aProcessor    a2a.TaskProcessor    // Runs the A2A tasks, see WithA2ATaskProcessor
+	tracer          *tracing.Tracer      // Nil unless tracing is enabled
+	accessLogger    *zap.Logger          // Writes the access log, see WithAccessLogger
+	httpServer      *http.Server         // Store the server instance
+	listenerErrChan <-chan error         // Channel for listener errors
+	shutdownWg      sync.WaitGroup       // WaitGroup for shutdown
 }
 
 // NodeOption is a functional option for configuring the Node
 type NodeOption func(*Node) error
 
+// WithAccessLogger writes the access log, once enabled in the config, to logger instead
+// of the node's logger, e.g. to capture its entries in tests.
+func WithAccessLogger(logger *zap.Logger) NodeOption {
+	return func(n *Node) error {
+		if logger == nil {
+			return errors.New("access logger cannot be nil")
+		}
+		n.accessLogger = logger
+		return nil
+	}
+}
+
+// WithA2ATaskProcessor runs the tasks sent to the A2A agents of the node with processor.
+// Without it, every task fails.
+func WithA2ATaskProcessor(processor a2a.TaskProcessor) NodeOption {
+	return func(n *Node) error {
+		if processor == nil {
+			return errors.New("A2A task processor cannot be nil")
+		}
+		n.a2aProcessor = processor
+		return nil
+	}
+}
+
 // New creates a new gateway node with the provided logger and config
-func New(logger *zap.Logger, cfg config.IConfig) (*Node, error) {
+func New(logger *zap.Logger, cfg config.IConfig, options ...NodeOption) (*Node, error) {
 	if logger == nil {
 		// Default logger if needed, though Start usually provides one
 		logger, _ = zap.NewProduction()
@@ -43,28 +79,63 @@ func New(logger *zap.Logger, cfg config.IConfig) (*Node, error) {
 	if cfg == nil {
 		return nil, errors.New("config cannot be nil")
 	}
+	logPrivacy, err := cfg.LogPrivacy()
+	if err != nil {
+		logger.Error("Failed to get log privacy from config, logging unredacted", zap.Error(err))
+	}
+	logger = privacy.WrapLogger(logger, logPrivacy)
+	idGeneratorName, err := cfg.IDGenerator()
+	if err != nil {
+		logger.Error("Failed to get ID generator from config, using random IDs", zap.Error(err))
+	}
+	if idGenerator, err := shared.NewIDGenerator(idGeneratorName); err == nil {
+		shared.SetIDGenerator(idGenerator)
+	}
 	n := &Node{
-		logger: logger.Named("gateway-node"), // Add name for clarity
-		cfg:    cfg,
+		logger:       logger.Named("gateway-node"), // Add name for clarity
+		cfg:          cfg,
+		a2aSkills:    a2a.NewSkillSet(),
+		a2aProcessor: a2a.UnavailableProcessor,
 		// shutdownWg initialization needed
 	}
+	n.a2aTasks = a2a.NewMemoryTaskStore(cfg, n.logger)
+	for _, option := range options {
+		if err := option(n); err != nil {
+			return nil, fmt.Errorf("failed to apply node option: %w", err)
+		}
+	}
+	if n.accessLogger == nil {
+		n.accessLogger = n.logger.Named("access")
+	} else {
+		n.accessLogger = privacy.WrapLogger(n.accessLogger, logPrivacy)
+	}
 	n.shutdownWg.Add(1) // Initialize WaitGroup counter for the main server loop
 
-	var err error
 	n.sessionManager, err = mcp.NewManager(n.logger, n.cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session manager: %w", err)
 	}
-	// Add default validators and gateway-specific capabilities
-	n.sessionManager.AddValidator(validators.CreateDefaultValidators()...)
+	// Add validators, the method filter and user rate limit first, and gateway-specific capabilities
+	n.sessionManager.AddValidator(validators.NewMethodFilter(n.cfg, n.logger), validators.NewUserRateLimit(n.cfg, n.logger))
+	throttling := validators.NewUserThrottling(n.cfg, n.logger, validators.DefaultRPS, validators.DefaultRPM)
+	n.sessionManager.AddValidator(validators.CreateDefaultValidators(throttling)...)
+	n.gateway = gwCapabilities.NewGatewayCapability(n.logger, n.cfg)
 	n.sessionManager.AddCapability(
-		serverCapabilities.NewBase(n.logger, n.sessionManager), // Base MCP handlers
-		gwCapabilities.NewGatewayCapability(n.logger, n.cfg),   // Gateway routing logic
+		serverCapabilities.NewBase(n.logger, n.sessionManager),              // Base MCP handlers
+		serverCapabilities.NewLimitsCapability(n.logger, n.cfg, throttling), // Limits of the client's user
+		n.gateway, // Gateway routing logic
 	)
-	n.serverTransport, err = transport.New(n.sessionManager, n.logger, n.cfg)
+	n.sessionManager.AddRequestObserver(n.gateway.Metrics())
+	accessLog := accesslog.New(n.cfg, n.accessLogger)
+	n.sessionManager.AddRequestLogger(accessLog)
+	n.tracer = n.newTracer()
+	n.sessionManager.SetTracer(n.tracer)
+	n.serverTransport, err = transport.New(n.sessionManager, n.logger, n.cfg, transport.WithTracer(n.tracer), transport.WithStreamLogger(accessLog))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create server transport: %w", err)
 	}
+	n.gateway.Metrics().SetSSEStreams(n.serverTransport.ActiveSSEStreams)
+	n.gateway.Metrics().SetSSEShutdownStreams(n.serverTransport.ShutdownStreams)
 	return n, nil
 }
 
@@ -84,7 +155,43 @@ func (n *Node) Start(ctx context.Context, mux *http.ServeMux, overwriteListenAdd
 	}
 
 	n.logger.Info("Registering status handler", zap.String("path", "/status"))
-	mux.HandleFunc("/status", serverextra.StatusHandler(n.cfg, n.logger))
+	mux.HandleFunc("/status", serverextra.StatusHandler(n.cfg, n.logger, n.serverTransport, n.a2aTasks, n.gateway))
+
+	n.registerMetricsHandler(mux)
+
+	n.logger.Info("Registering schema handler", zap.String("path", "/schema"))
+	mux.HandleFunc("/schema", serverextra.SchemaHandler(n.logger))
+
+	if err := a2a.RegisterAgentCardHandlers(mux, n.cfg, n.a2aSkills, n.logger); err != nil {
+		n.shutdownWg.Done() // Decrement counter if startup fails
+		return fmt.Errorf("failed to register A2A agent cards: %w", err)
+	}
+	authenticator := transport.NewAuthenticator(n.cfg, n.logger)
+	authenticate := func(r *http.Request) (string, error) {
+		key, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		userID, _, err := authenticator.Authenticate(key, r.RemoteAddr)
+		return userID, err
+	}
+	if err := a2a.RegisterTaskHandlers(mux, n.cfg, n.a2aTasks, n.a2aProcessor, authenticate, n.logger); err != nil {
+		n.shutdownWg.Done()
+		return fmt.Errorf("failed to register A2A task handlers: %w", err)
+	}
+
+	// Backends can be changed at runtime if the configuration holds them in memory
+	if editor, ok := n.cfg.(config.BackendEditor); ok {
+		n.logger.Info("Registering admin backends handler", zap.String("path", extra.BackendsPath))
+		backendsHandler := extra.BackendsHandler(n.cfg, editor, n.logger)
+		mux.HandleFunc(extra.BackendsPath, backendsHandler)
+		mux.HandleFunc(extra.BackendsPath+"/", backendsHandler)
+	}
+	n.logger.Info("Registering admin sessions handler", zap.String("path", extra.SessionsPath))
+	sessionsHandler := extra.SessionsHandler(n.cfg, n.sessionManager, gwCapabilities.BackendIDs, n.logger)
+	mux.HandleFunc(extra.SessionsPath, sessionsHandler)
+	mux.HandleFunc(extra.SessionsPath+"/", sessionsHandler)
+	if revoker, ok := n.cfg.(config.KeyRevoker); ok {
+		n.logger.Info("Registering admin keys handler", zap.String("path", extra.KeysPath))
+		mux.HandleFunc(extra.KeysPath+"/", extra.KeysHandler(n.cfg, revoker, n.sessionManager, n.logger))
+	}
 
 	frontendAddress, err := n.cfg.FrontendAddressForProxy()
 	if err != nil {
@@ -131,20 +238,40 @@ func (n *Node) Start(ctx context.Context, mux *http.ServeMux, overwriteListenAdd
 		}
 	}()
 
+	// --- Backend health checks, stopped with the node ---
+	go n.gateway.CheckBackendHealth(ctx)
+
 	// --- Graceful Shutdown Logic ---
 	go func() {
 		<-ctx.Done() // Wait for cancellation signal (e.g., from main)
 		n.logger.Info("Shutdown signal received, stopping Gateway node...")
 
-		// Create shutdown context with timeout
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second) // Generous timeout
+		// Create shutdown context with timeout, leaving time to close connections after the drain
+		drainTimeout := n.serverTransport.DrainTimeout()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout+5*time.Second)
 		defer cancel()
 
-		// Close MCP sessions first
+		// Stop accepting connections while the open streams are drained, so the
+		// requests still running are answered to their clients
+		httpStopped := make(chan struct{})
+		go func() {
+			defer close(httpStopped)
+			transport.ShutdownHTTPServer(shutdownCtx, n.logger, n.httpServer)
+		}()
+		drainCtx, cancelDrain := context.WithTimeout(shutdownCtx, drainTimeout)
+		if err := n.serverTransport.Shutdown(drainCtx); err != nil {
+			n.logger.Warn("Closed streams before their requests were answered", zap.Error(err))
+		}
+		cancelDrain()
+
+		// Then close the MCP sessions and their backend sessions
 		n.sessionManager.CloseAllSessions()
+		<-httpStopped
 
-		// Shutdown HTTP server using the shared utility function
-		transport.ShutdownHTTPServer(shutdownCtx, n.logger, n.httpServer)
+		// Export the spans of the requests that were still running
+		if err := n.tracer.Shutdown(shutdownCtx); err != nil {
+			n.logger.Warn("Failed to export remaining spans", zap.Error(err))
+		}
 
 		// The server goroutine started by StartHTTPServer will detect ErrServerClosed
 		// and the listenerErrChan goroutine will then call shutdownWg.Done().
@@ -156,6 +283,12 @@ func (n *Node) Start(ctx context.Context, mux *http.ServeMux, overwriteListenAdd
 	return nil
 }
 
+// A2ASkills returns the skills announced in the A2A agent cards besides the configured
+// ones. Skills registered in it are served from the next request for a card on.
+func (n *Node) A2ASkills() *a2a.SkillSet {
+	return n.a2aSkills
+}
+
 // WaitForShutdown waits for the node's main server loop to finish.
 func (n *Node) WaitForShutdown(timeout time.Duration) bool {
 	doneChan := make(chan struct{})
@@ -178,8 +311,8 @@ func (n *Node) WaitForShutdown(timeout time.Duration) bool {
 }
 
 // Start is a convenience function to create and start the node
-func Start(ctx context.Context, logger *zap.Logger, cfg config.IConfig, overwriteListenAddr string) (*Node, error) {
-	node, err := New(logger, cfg)
+func Start(ctx context.Context, logger *zap.Logger, cfg config.IConfig, overwriteListenAddr string, options ...NodeOption) (*Node, error) {
+	node, err := New(logger, cfg, options...)
 	if err != nil {
 		// Use Fatalf only if called directly from main, otherwise return error
 		return nil, fmt.Errorf("failed to create gateway node: %w", err)
@@ -194,3 +327,54 @@ func Start(ctx context.Context, logger *zap.Logger, cfg config.IConfig, overwrit
 	}
 	return node, nil
 }
+
+// registerMetricsHandler serves the gateway metrics if they are enabled.
+func (n *Node) registerMetricsHandler(mux *http.ServeMux) {
+	enabled, err := n.cfg.MetricsEnabled()
+	if err != nil {
+		n.logger.Warn("Failed to get metrics enabled from config, metrics are not served", zap.Error(err))
+		return
+	}
+	if !enabled {
+		return
+	}
+	path, err := n.cfg.MetricsPath()
+	if err != nil {
+		n.logger.Warn("Failed to get metrics path from config, using the default", zap.Error(err))
+		path = ""
+	}
+	if path == "" {
+		path = config.DefaultMetricsPath
+	}
+	n.logger.Info("Registering metrics handler", zap.String("path", path))
+	mux.HandleFunc(path, n.gateway.Metrics().Handler())
+}
+
+// newTracer returns the tracer exporting spans to the configured OTLP endpoint, or nil if
+// tracing is disabled.
+func (n *Node) newTracer() *tracing.Tracer {
+	endpoint, err := n.cfg.TracingEndpoint()
+	if err != nil {
+		n.logger.Warn("Failed to get tracing endpoint from config, tracing is disabled", zap.Error(err))
+		return nil
+	}
+	if endpoint == "" {
+		return nil
+	}
+	ratio, err := n.cfg.TracingSampleRatio()
+	if err != nil {
+		n.logger.Warn("Failed to get tracing sample ratio from config, using the default", zap.Error(err))
+		ratio = config.DefaultTracingSampleRatio
+	}
+	serviceName, err := n.cfg.ServerName()
+	if err != nil || serviceName == "" {
+		serviceName = "gate4ai-gateway"
+	}
+	tracer, err := tracing.New(endpoint, serviceName, ratio, n.logger)
+	if err != nil {
+		n.logger.Error("Failed to create tracer, tracing is disabled", zap.Error(err))
+		return nil
+	}
+	n.logger.Info("Tracing enabled", zap.String("endpoint", endpoint), zap.Float64("sampleRatio", ratio))
+	return tracer
+}
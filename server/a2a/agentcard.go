@@ -0,0 +1,236 @@
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// SkillSet holds the skills registered at runtime for the A2A agents, by agent name.
+// They are announced in the agent cards after the configured skills. It is safe for
+// concurrent use; a nil SkillSet holds no skills.
+type SkillSet struct {
+	mu     sync.RWMutex
+	skills map[string][]a2aSchema.AgentSkill
+}
+
+// NewSkillSet creates an empty skill set.
+func NewSkillSet() *SkillSet {
+	return &SkillSet{skills: make(map[string][]a2aSchema.AgentSkill)}
+}
+
+// Register adds skills to the card of the named agent. A skill replaces the registered
+// or configured skill with the same ID.
+func (s *SkillSet) Register(agentName string, skills ...a2aSchema.AgentSkill) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	registered := s.skills[agentName]
+	for _, skill := range skills {
+		registered = append(removeSkill(registered, skill.ID), skill)
+	}
+	s.skills[agentName] = registered
+}
+
+// Unregister removes the skill with the ID from the card of the named agent.
+func (s *SkillSet) Unregister(agentName string, skillID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skills[agentName] = removeSkill(s.skills[agentName], skillID)
+}
+
+// Skills returns the skills registered for the named agent, in registration order.
+func (s *SkillSet) Skills(agentName string) []a2aSchema.AgentSkill {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]a2aSchema.AgentSkill(nil), s.skills[agentName]...)
+}
+
+// removeSkill returns the skills without the one with the ID.
+func removeSkill(skills []a2aSchema.AgentSkill, skillID string) []a2aSchema.AgentSkill {
+	kept := make([]a2aSchema.AgentSkill, 0, len(skills))
+	for _, skill := range skills {
+		if skill.ID != skillID {
+			kept = append(kept, skill)
+		}
+	}
+	return kept
+}
+
+// BuildAgentCard assembles the agent card of the named agent from its configured base info
+// and the skills registered for it in skills, which may be nil.
+// If the base info has no URL, the agent URL is derived from the request: the address the
+// card was requested at followed by config.DefaultA2AEndpointPath.
+func BuildAgentCard(cfg config.IConfig, agentName string, r *http.Request, skills *SkillSet) (*a2aSchema.AgentCard, error) {
+	info, err := cfg.GetA2ACardBaseInfo(agentName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card info of agent '%s': %w", agentName, err)
+	}
+
+	card := &a2aSchema.AgentCard{
+		Name:               agentName,
+		URL:                info.URL,
+		Version:            info.Version,
+		DefaultInputModes:  []string{"text"},
+		DefaultOutputModes: []string{"text"},
+		Skills:             agentSkills(info, skills.Skills(agentName)),
+		Capabilities:       agentCapabilities(info),
+	}
+	if card.URL == "" {
+		card.URL = requestBaseURL(r, cfg) + config.DefaultA2AEndpointPath
+	}
+	if card.Version == "" {
+		card.Version, _ = cfg.ServerVersion()
+	}
+	if info.Description != "" {
+		card.Description = &info.Description
+	}
+	if info.DocumentationURL != "" {
+		card.DocumentationURL = &info.DocumentationURL
+	}
+	if info.ProviderOrganization != "" {
+		card.Provider = &a2aSchema.AgentProvider{Organization: info.ProviderOrganization}
+		if info.ProviderURL != "" {
+			card.Provider.URL = &info.ProviderURL
+		}
+	}
+	return card, nil
+}
+
+// agentCapabilities returns the capabilities configured for an agent.
+func agentCapabilities(info config.A2ACardBaseInfo) a2aSchema.AgentCapabilities {
+	return a2aSchema.AgentCapabilities{
+		Streaming:              info.Streaming,
+		PushNotifications:      info.PushNotifications,
+		StateTransitionHistory: info.StateTransitionHistory,
+	}
+}
+
+// agentSkills returns the configured skills of an agent followed by the registered ones,
+// which replace configured skills with the same ID.
+func agentSkills(info config.A2ACardBaseInfo, registered []a2aSchema.AgentSkill) []a2aSchema.AgentSkill {
+	skills := make([]a2aSchema.AgentSkill, 0, len(info.Skills)+len(registered))
+	for _, configured := range info.Skills {
+		skill := a2aSchema.AgentSkill{
+			ID:          configured.ID,
+			Name:        configured.Name,
+			Tags:        configured.Tags,
+			Examples:    configured.Examples,
+			InputModes:  configured.InputModes,
+			OutputModes: configured.OutputModes,
+		}
+		if configured.Description != "" {
+			description := configured.Description
+			skill.Description = &description
+		}
+		skills = append(skills, skill)
+	}
+	for _, skill := range registered {
+		skills = append(removeSkill(skills, skill.ID), skill)
+	}
+	return skills
+}
+
+// AgentCardHandler serves the agent card of the named agent. With `?summary=true` or an
+// Accept header of a2aSchema.AgentCardSummaryMediaType only the capabilities summary is returned.
+func AgentCardHandler(cfg config.IConfig, agentName string, skills *SkillSet, logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handlerLogger := logger.With(zap.String("handler", "AgentCardHandler"), zap.String("agent", agentName))
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		card, err := BuildAgentCard(cfg, agentName, r, skills)
+		if err != nil {
+			handlerLogger.Error("Failed to build agent card", zap.Error(err))
+			http.Error(w, "Agent card not available", http.StatusNotFound)
+			return
+		}
+
+		var body interface{} = card
+		contentType := "application/json"
+		if wantsSummary(r) {
+			body = card.Summary()
+			contentType = a2aSchema.AgentCardSummaryMediaType
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Add("Vary", "Accept")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			handlerLogger.Error("Failed to encode agent card", zap.Error(err))
+		}
+	}
+}
+
+// RegisterAgentCardHandlers serves the card of every configured A2A agent at its path,
+// announcing the skills registered for it in skills, which may be nil.
+func RegisterAgentCardHandlers(mux *http.ServeMux, cfg config.IConfig, skills *SkillSet, logger *zap.Logger) error {
+	names, err := cfg.A2AAgentNames()
+	if err != nil {
+		return fmt.Errorf("failed to get A2A agents: %w", err)
+	}
+	agents := make(map[string]config.A2ACardBaseInfo, len(names))
+	for _, name := range names {
+		info, err := cfg.GetA2ACardBaseInfo(name)
+		if err != nil {
+			return fmt.Errorf("failed to get card info of agent '%s': %w", name, err)
+		}
+		agents[name] = info
+	}
+	if err := config.ValidateA2AAgents(agents); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		path := agents[name].CardPath()
+		logger.Info("Registering A2A agent card handler", zap.String("agent", name), zap.String("path", path))
+		mux.HandleFunc(path, AgentCardHandler(cfg, name, skills, logger))
+	}
+	return nil
+}
+
+// wantsSummary reports whether the request asks for the capabilities summary only.
+func wantsSummary(r *http.Request) bool {
+	if summary, err := strconv.ParseBool(r.URL.Query().Get("summary")); err == nil {
+		return summary
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), a2aSchema.AgentCardSummaryMediaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestBaseURL returns the scheme and host the request was sent to. Requests without
+// a Host header get the listen address, with localhost for an unspecified host.
+func requestBaseURL(r *http.Request, cfg config.IConfig) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+	if host == "" {
+		host, _ = cfg.ListenAddr()
+		if listenHost, port, err := net.SplitHostPort(host); err == nil {
+			if ip := net.ParseIP(listenHost); listenHost == "" || (ip != nil && ip.IsUnspecified()) {
+				host = net.JoinHostPort("localhost", port)
+			}
+		}
+	}
+	return scheme + "://" + host
+}
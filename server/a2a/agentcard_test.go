@@ -0,0 +1,329 @@
+package a2a_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aClient "github.com/gate4ai/mcp/shared/a2a/2025-draft/client"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAgentCardsServedPerAgentPath(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	cfg.ServerVersionValue = "9.9.9"
+	require.NoError(t, cfg.SetA2AAgent("alpha", config.A2ACardBaseInfo{
+		Path:                 "/agents/alpha/.well-known/agent.json",
+		Description:          "First agent",
+		URL:                  "https://alpha.example.com/a2a",
+		Version:              "1.0.0",
+		ProviderOrganization: "gate4ai",
+	}))
+	require.NoError(t, cfg.SetA2AAgent("beta", config.A2ACardBaseInfo{
+		Path: "/agents/beta/.well-known/agent.json",
+	}))
+
+	mux := http.NewServeMux()
+	require.NoError(t, a2a.RegisterAgentCardHandlers(mux, cfg, nil, zap.NewNop()))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	alpha, err := a2aClient.New(server.URL + "/agents/alpha")
+	require.NoError(t, err)
+	card, err := alpha.FetchAgentInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", card.Name)
+	assert.Equal(t, "https://alpha.example.com/a2a", card.URL)
+	assert.Equal(t, "1.0.0", card.Version)
+	require.NotNil(t, card.Description)
+	assert.Equal(t, "First agent", *card.Description)
+	require.NotNil(t, card.Provider)
+	assert.Equal(t, "gate4ai", card.Provider.Organization)
+
+	beta, err := a2aClient.New(server.URL + "/agents/beta/")
+	require.NoError(t, err)
+	card, err = beta.FetchAgentInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "beta", card.Name)
+	assert.Equal(t, server.URL+config.DefaultA2AEndpointPath, card.URL, "URL should be derived from the request when not configured")
+	assert.Equal(t, "9.9.9", card.Version, "Version should fall back to the server version")
+	assert.Nil(t, card.Description)
+	assert.Nil(t, card.Provider)
+
+	// No card at the default path since every agent has its own
+	other, err := a2aClient.New(server.URL)
+	require.NoError(t, err)
+	_, err = other.FetchAgentInfo(context.Background())
+	assert.Error(t, err)
+}
+
+func TestAgentCardPathsMustBeUnique(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	require.NoError(t, cfg.SetA2AAgent("alpha", config.A2ACardBaseInfo{}))
+	err := cfg.SetA2AAgent("beta", config.A2ACardBaseInfo{Path: config.DefaultA2AAgentCardPath})
+	require.Error(t, err, "Two agents at the default path must be rejected")
+	assert.Contains(t, err.Error(), config.DefaultA2AAgentCardPath)
+
+	err = cfg.SetA2AAgent("gamma", config.A2ACardBaseInfo{Path: "no-slash"})
+	assert.Error(t, err)
+
+	names, err := cfg.A2AAgentNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha"}, names, "Rejected agents must not be stored")
+
+	// Duplicate paths in a YAML config fail to load
+	_, err = loadYaml(t, `
+a2a:
+  agents:
+    one:
+      path: /same/.well-known/agent.json
+    two:
+      path: /same/.well-known/agent.json
+`)
+	assert.Error(t, err)
+}
+
+func TestAgentCardSummary(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	require.NoError(t, cfg.SetA2AAgent("alpha", config.A2ACardBaseInfo{
+		Description:          "First agent",
+		Version:              "1.0.0",
+		ProviderOrganization: "gate4ai",
+	}))
+	mux := http.NewServeMux()
+	require.NoError(t, a2a.RegisterAgentCardHandlers(mux, cfg, nil, zap.NewNop()))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetch := func(query string, accept string) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+config.DefaultA2AAgentCardPath+query, nil)
+		require.NoError(t, err)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp, body
+	}
+
+	for name, request := range map[string][2]string{
+		"query":  {"?summary=true", ""},
+		"accept": {"", a2aSchema.AgentCardSummaryMediaType + ", application/json;q=0.9"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, body := fetch(request[0], request[1])
+			assert.Equal(t, a2aSchema.AgentCardSummaryMediaType, resp.Header.Get("Content-Type"))
+			keys := make([]string, 0, len(body))
+			for key := range body {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, []string{"name", "version", "capabilities"}, keys)
+			assert.Equal(t, "alpha", body["name"])
+			assert.Equal(t, "1.0.0", body["version"])
+		})
+	}
+
+	_, body := fetch("?summary=false", "")
+	assert.Contains(t, body, "description", "summary=false should return the full card")
+
+	client, err := a2aClient.New(server.URL)
+	require.NoError(t, err)
+	summary, err := client.FetchAgentCapabilities(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", summary.Name)
+	assert.Equal(t, "1.0.0", summary.Version)
+}
+
+func TestFetchAgentCapabilitiesFallsBackToFullCard(t *testing.T) {
+	card := a2aSchema.AgentCard{
+		Name:         "legacy",
+		Version:      "0.1.0",
+		Capabilities: a2aSchema.AgentCapabilities{Streaming: true},
+		Skills:       []a2aSchema.AgentSkill{},
+	}
+	var summaryRequests int
+	serveCard := func(rejectSummary bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("summary") != "" {
+				summaryRequests++
+				if rejectSummary {
+					http.Error(w, "unknown parameter", http.StatusBadRequest)
+					return
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(card)
+		}))
+	}
+
+	for name, rejectSummary := range map[string]bool{"ignored": false, "rejected": true} {
+		t.Run(name, func(t *testing.T) {
+			summaryRequests = 0
+			server := serveCard(rejectSummary)
+			defer server.Close()
+
+			client, err := a2aClient.New(server.URL)
+			require.NoError(t, err)
+			summary, err := client.FetchAgentCapabilities(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, summaryRequests)
+			assert.Equal(t, &a2aSchema.AgentCardSummary{Name: "legacy", Version: "0.1.0", Capabilities: a2aSchema.AgentCapabilities{Streaming: true}}, summary)
+		})
+	}
+}
+
+func loadYaml(t *testing.T, content string) (*config.YamlConfig, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return config.NewYamlConfig(path, zap.NewNop())
+}
+
+// validateJSON checks that value, decoded from JSON, has the types and required properties
+// of schema, resolving references in defs.
+func validateJSON(t *testing.T, path string, value interface{}, schema jsonschema.Schema, defs map[string]jsonschema.Schema) {
+	t.Helper()
+	if ref, ok := schema["$ref"].(string); ok {
+		def, exists := defs[strings.TrimPrefix(ref, "#/$defs/")]
+		require.True(t, exists, "%s: unknown reference %s", path, ref)
+		schema = def
+	}
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		require.True(t, ok, "%s: expected an object, got %T", path, value)
+		required, _ := schema["required"].([]string)
+		for _, name := range required {
+			assert.Contains(t, object, name, "%s: missing required property", path)
+		}
+		properties, _ := schema["properties"].(map[string]jsonschema.Schema)
+		for name, property := range object {
+			propertySchema, known := properties[name]
+			if assert.True(t, known, "%s: unknown property %s", path, name) {
+				validateJSON(t, path+"."+name, property, propertySchema, defs)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		require.True(t, ok, "%s: expected an array, got %T", path, value)
+		for i, item := range items {
+			validateJSON(t, fmt.Sprintf("%s[%d]", path, i), item, schema["items"].(jsonschema.Schema), defs)
+		}
+	case "string":
+		assert.IsType(t, "", value, "%s: expected a string", path)
+	case "boolean":
+		assert.IsType(t, true, value, "%s: expected a boolean", path)
+	}
+}
+
+func TestAgentCardComposesConfiguredAndRegisteredSkills(t *testing.T) {
+	cfg, err := loadYaml(t, `
+server:
+  version: 2.0.0
+a2a:
+  agents:
+    assistant:
+      description: Answers questions
+      capabilities:
+        streaming: true
+      skills:
+        - id: search
+          name: Search
+          description: Searches the web
+          tags: [web]
+          examples: ["Find the A2A spec"]
+        - id: summarize
+          name: Summarize
+          output_modes: [text, file]
+`)
+	require.NoError(t, err)
+
+	skills := a2a.NewSkillSet()
+	skills.Register("assistant",
+		a2aSchema.AgentSkill{ID: "translate", Name: "Translate", Tags: []string{"language"}},
+		a2aSchema.AgentSkill{ID: "summarize", Name: "Summarize v2"},
+	)
+	mux := http.NewServeMux()
+	require.NoError(t, a2a.RegisterAgentCardHandlers(mux, cfg, skills, zap.NewNop()))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + config.DefaultA2AAgentCardPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	reflector := jsonschema.NewReflector()
+	schema := reflector.Reflect(reflect.TypeOf(a2aSchema.AgentCard{}))
+	validateJSON(t, "card", body, schema, reflector.Defs())
+
+	client, err := a2aClient.New(server.URL)
+	require.NoError(t, err)
+	card, err := client.FetchAgentInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+config.DefaultA2AEndpointPath, card.URL, "URL should be the address the card was fetched from")
+	assert.Equal(t, "2.0.0", card.Version)
+	assert.True(t, card.Capabilities.Streaming)
+	require.Len(t, card.Skills, 3)
+	assert.Equal(t, "search", card.Skills[0].ID)
+	require.NotNil(t, card.Skills[0].Description)
+	assert.Equal(t, "Searches the web", *card.Skills[0].Description)
+	assert.Equal(t, []string{"Find the A2A spec"}, card.Skills[0].Examples)
+	assert.Equal(t, "translate", card.Skills[1].ID)
+	assert.Equal(t, a2aSchema.AgentSkill{ID: "summarize", Name: "Summarize v2"}, card.Skills[2], "A registered skill should replace the configured one")
+
+	skills.Unregister("assistant", "translate")
+	card, err = client.FetchAgentInfo(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, card.Skills, 2)
+}
+
+func TestAgentCardURLOverride(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	cfg.SetListenAddr(":4000")
+	require.NoError(t, cfg.SetA2AAgent("derived", config.A2ACardBaseInfo{}))
+
+	req := httptest.NewRequest(http.MethodGet, config.DefaultA2AAgentCardPath, nil)
+	req.Host = ""
+	card, err := a2a.BuildAgentCard(cfg, "derived", req, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:4000/a2a", card.URL, "Requests without a host should get the listen address")
+	assert.Equal(t, []a2aSchema.AgentSkill{}, card.Skills)
+
+	require.NoError(t, cfg.SetA2AAgent("derived", config.A2ACardBaseInfo{URL: "https://agents.example.com/a2a"}))
+	req.Host = "10.0.0.5:4000"
+	card, err = a2a.BuildAgentCard(cfg, "derived", req, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://agents.example.com/a2a", card.URL, "The configured URL should override the derived one")
+
+	_, err = loadYaml(t, `
+a2a:
+  agents:
+    assistant:
+      skills:
+        - id: search
+          name: Search
+        - id: search
+          name: Search again
+`)
+	assert.Error(t, err, "Duplicate skill IDs must be rejected")
+}
@@ -0,0 +1,124 @@
+package a2a
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// ArtifactChecksummer adds sha256 checksums to the metadata of artifacts produced for a
+// task when a2a.artifact_checksums is enabled. Streamed artifacts are tracked by index:
+// a chunk with append=true extends the content of the previous chunks, and the checksum
+// of every chunk covers the whole content assembled so far, so the final chunk carries
+// the checksum of the complete artifact.
+type ArtifactChecksummer struct {
+	enabled bool
+
+	mu    sync.Mutex
+	parts map[int][]schema.Part // artifact index -> content assembled so far
+}
+
+// NewArtifactChecksummer creates a checksummer for the artifacts of one task.
+func NewArtifactChecksummer(cfg config.IConfig, logger *zap.Logger) *ArtifactChecksummer {
+	enabled, err := cfg.A2AArtifactChecksums()
+	if err != nil {
+		logger.Error("Failed to get artifact checksum setting from config", zap.Error(err))
+	}
+	return &ArtifactChecksummer{enabled: enabled, parts: make(map[int][]schema.Part)}
+}
+
+// Enabled reports whether checksums are added.
+func (c *ArtifactChecksummer) Enabled() bool {
+	return c.enabled
+}
+
+// Apply records the artifact content and, if enabled, stores the checksum in its metadata.
+func (c *ArtifactChecksummer) Apply(artifact *schema.Artifact) error {
+	if !c.enabled {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	parts := artifact.Parts
+	if artifact.Append != nil && *artifact.Append {
+		parts = append(append([]schema.Part(nil), c.parts[artifact.Index]...), artifact.Parts...)
+	}
+	if err := artifact.SetChecksum(parts); err != nil {
+		return err
+	}
+	if artifact.LastChunk != nil && *artifact.LastChunk {
+		delete(c.parts, artifact.Index)
+	} else {
+		c.parts[artifact.Index] = parts
+	}
+	return nil
+}
+
+// artifactStore is a TaskStore detecting the MIME types of and adding checksums to the
+// artifacts appended to the task of one message, before they are stored.
+type artifactStore struct {
+	TaskStore
+	mimeTypes *ArtifactMimeDetector
+	checksums *ArtifactChecksummer
+}
+
+// AppendArtifact detects the missing MIME types of the artifact and adds its checksum,
+// as enabled, and appends it to the task.
+func (s *artifactStore) AppendArtifact(scope TaskScope, taskID string, artifact schema.Artifact) (*schema.Task, error) {
+	if artifact.Metadata != nil {
+		metadata := make(map[string]interface{}, len(*artifact.Metadata)+1)
+		for key, value := range *artifact.Metadata {
+			metadata[key] = value
+		}
+		artifact.Metadata = &metadata // The processor keeps its metadata
+	}
+	if s.mimeTypes.Enabled() {
+		parts := make([]schema.Part, len(artifact.Parts))
+		copy(parts, artifact.Parts)
+		artifact.Parts = parts // The processor keeps its parts
+		if err := s.mimeTypes.Apply(&artifact); err != nil {
+			return nil, fmt.Errorf("failed to detect MIME types of artifact %d: %w", artifact.Index, err)
+		}
+	}
+	if err := s.checksums.Apply(&artifact); err != nil {
+		return nil, fmt.Errorf("failed to checksum artifact %d: %w", artifact.Index, err)
+	}
+	return s.TaskStore.AppendArtifact(scope, taskID, artifact)
+}
+
+// ArtifactMimeDetector fills in the MIME type of file parts of produced artifacts that do
+// not declare one, when a2a.detect_mime_types is enabled. Declared types are kept.
+type ArtifactMimeDetector struct {
+	enabled bool
+	logger  *zap.Logger
+}
+
+// NewArtifactMimeDetector creates a detector configured by cfg.
+func NewArtifactMimeDetector(cfg config.IConfig, logger *zap.Logger) *ArtifactMimeDetector {
+	enabled, err := cfg.A2ADetectArtifactMimeTypes()
+	if err != nil {
+		logger.Error("Failed to get MIME type detection setting from config", zap.Error(err))
+	}
+	return &ArtifactMimeDetector{enabled: enabled, logger: logger}
+}
+
+// Enabled reports whether MIME types are detected.
+func (d *ArtifactMimeDetector) Enabled() bool {
+	return d.enabled
+}
+
+// Apply detects the missing MIME types of the artifact's file parts, if enabled.
+func (d *ArtifactMimeDetector) Apply(artifact *schema.Artifact) error {
+	if !d.enabled {
+		return nil
+	}
+	updated, err := artifact.DetectMimeTypes()
+	if updated > 0 {
+		d.logger.Debug("Detected MIME types of artifact files", zap.Int("index", artifact.Index), zap.Int("parts", updated))
+	}
+	return err
+}
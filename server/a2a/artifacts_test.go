@@ -0,0 +1,273 @@
+package a2a_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aClient "github.com/gate4ai/mcp/shared/a2a/2025-draft/client"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newChecksummer(t *testing.T, enabled bool) *a2a.ArtifactChecksummer {
+	t.Helper()
+	cfg := config.NewInternalConfig()
+	cfg.SetA2AArtifactChecksums(enabled)
+	return a2a.NewArtifactChecksummer(cfg, zap.NewNop())
+}
+
+// transmit round-trips the artifact through JSON, as a client would receive it.
+func transmit(t *testing.T, artifact a2aSchema.Artifact) *a2aSchema.Artifact {
+	t.Helper()
+	data, err := json.Marshal(artifact)
+	require.NoError(t, err)
+	var received a2aSchema.Artifact
+	require.NoError(t, json.Unmarshal(data, &received))
+	return &received
+}
+
+func TestArtifactChecksumVerifies(t *testing.T) {
+	artifact := a2aSchema.Artifact{Parts: []a2aSchema.Part{
+		testutil.NewTextPart("hello "),
+		testutil.NewTextPart("world"),
+	}}
+	require.NoError(t, newChecksummer(t, true).Apply(&artifact))
+
+	sum := sha256.Sum256([]byte("hello world"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), artifact.Checksum())
+
+	received := transmit(t, artifact)
+	assert.NoError(t, a2aClient.VerifyArtifact(received))
+}
+
+func TestArtifactChecksumDetectsCorruption(t *testing.T) {
+	artifact := a2aSchema.Artifact{Parts: []a2aSchema.Part{
+		testutil.NewTextPart("hello"),
+		testutil.NewDataPart(map[string]interface{}{"answer": 42}),
+	}}
+	require.NoError(t, newChecksummer(t, true).Apply(&artifact))
+
+	received := transmit(t, artifact)
+	received.Parts[0] = testutil.NewTextPart("hellO")
+	err := a2aClient.VerifyArtifact(received)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, a2aSchema.ErrArtifactChecksumMismatch)
+}
+
+func TestArtifactChecksumDisabled(t *testing.T) {
+	artifact := a2aSchema.Artifact{Parts: []a2aSchema.Part{testutil.NewTextPart("x")}}
+	require.NoError(t, newChecksummer(t, false).Apply(&artifact))
+	assert.Nil(t, artifact.Metadata)
+	assert.NoError(t, a2aClient.VerifyArtifact(&artifact), "artifacts without checksum should pass")
+}
+
+func TestStreamedArtifactChecksumCoversAssembledContent(t *testing.T) {
+	checksummer := newChecksummer(t, true)
+	chunks := []a2aSchema.Artifact{
+		{Index: 1, Parts: []a2aSchema.Part{testutil.NewTextPart("one ")}, LastChunk: testutil.PointerTo(false)},
+		{Index: 1, Parts: []a2aSchema.Part{testutil.NewTextPart("two ")}, Append: testutil.PointerTo(true), LastChunk: testutil.PointerTo(false)},
+		{Index: 1, Parts: []a2aSchema.Part{testutil.NewTextPart("three")}, Append: testutil.PointerTo(true), LastChunk: testutil.PointerTo(true)},
+	}
+	for i := range chunks {
+		require.NoError(t, checksummer.Apply(&chunks[i]))
+	}
+	sum := sha256.Sum256([]byte("one two three"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), chunks[2].Checksum(), "final chunk must carry the checksum of the full artifact")
+
+	t.Run("intact", func(t *testing.T) {
+		assembler := a2aClient.NewArtifactAssembler()
+		for _, chunk := range chunks {
+			require.NoError(t, assembler.Add(transmit(t, chunk)))
+		}
+		assembled := assembler.Artifact(1)
+		require.NotNil(t, assembled)
+		assert.Len(t, assembled.Parts, 3)
+		assert.NoError(t, a2aClient.VerifyArtifact(assembled))
+	})
+
+	t.Run("corrupted chunk", func(t *testing.T) {
+		assembler := a2aClient.NewArtifactAssembler()
+		require.NoError(t, assembler.Add(transmit(t, chunks[0])))
+		corrupted := transmit(t, chunks[1])
+		corrupted.Parts[0] = testutil.NewTextPart("TWO ")
+		// The chunk's own checksum covers "one two ", so corruption is detected immediately.
+		assert.ErrorIs(t, assembler.Add(corrupted), a2aSchema.ErrArtifactChecksumMismatch)
+	})
+
+	t.Run("missing chunk", func(t *testing.T) {
+		assembler := a2aClient.NewArtifactAssembler()
+		require.NoError(t, assembler.Add(transmit(t, chunks[0])))
+		assert.ErrorIs(t, assembler.Add(transmit(t, chunks[2])), a2aSchema.ErrArtifactChecksumMismatch)
+	})
+}
+
+// streamChunks returns a processor adding the chunks to the task before completing it.
+func streamChunks(chunks ...a2aSchema.Artifact) a2a.TaskProcessor {
+	return a2a.TaskProcessorFunc(func(ctx context.Context, store a2a.TaskStore, scope a2a.TaskScope, task *a2aSchema.Task, message a2aSchema.Message) {
+		for _, chunk := range chunks {
+			if _, err := store.AppendArtifact(scope, task.ID, chunk); err != nil {
+				return
+			}
+		}
+		_, _ = store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateCompleted})
+	})
+}
+
+func TestTaskHandlerChecksumsStreamedArtifacts(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	cfg.SetA2AArtifactChecksums(true)
+	processor := streamChunks(
+		a2aSchema.Artifact{Index: 0, Parts: []a2aSchema.Part{testutil.NewTextPart("one ")}, LastChunk: testutil.PointerTo(false)},
+		a2aSchema.Artifact{Index: 0, Parts: []a2aSchema.Part{testutil.NewTextPart("two")}, Append: testutil.PointerTo(true), LastChunk: testutil.PointerTo(true)},
+	)
+	client := serveTaskHandler(t, newTaskStore(0, 0), processor, a2a.WithArtifactProcessing(cfg))
+	ctx := context.Background()
+
+	params := testutil.NewTaskSendParams("t1", "", "go")
+	events, err := client.SendTaskSubscribe(ctx, &params)
+	require.NoError(t, err)
+	assembler := a2aClient.NewArtifactAssembler()
+	received := 0
+	for event := range events {
+		require.NoError(t, event.Err)
+		if event.Artifact != nil {
+			assert.NotEmpty(t, event.Artifact.Artifact.Checksum(), "Every streamed chunk should carry a checksum")
+			require.NoError(t, assembler.Add(&event.Artifact.Artifact))
+			received++
+		}
+	}
+	require.Equal(t, 2, received, "Subscribers should receive the artifacts added to the task")
+	require.NoError(t, a2aClient.VerifyArtifact(assembler.Artifact(0)))
+
+	task, err := client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t1"})
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, 1)
+	sum := sha256.Sum256([]byte("one two"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), task.Artifacts[0].Checksum(), "The stored artifact should carry the checksum of its content")
+	assert.NoError(t, a2aClient.VerifyArtifact(&task.Artifacts[0]))
+
+	// Without the setting the artifacts are stored as produced
+	client = serveTaskHandler(t, newTaskStore(0, 0), processor, a2a.WithArtifactProcessing(config.NewInternalConfig()))
+	params = testutil.NewTaskSendParams("t1", "", "go")
+	task, err = client.SendTask(ctx, &params)
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, 1)
+	assert.Empty(t, task.Artifacts[0].Checksum())
+}
+
+// pngHeader is the start of a PNG image, enough for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+// newFileArtifact returns an artifact with one inline file part.
+func newFileArtifact(t *testing.T, name string, content []byte, mimeType string) a2aSchema.Artifact {
+	t.Helper()
+	file := a2aSchema.FileContent{Bytes: testutil.PointerTo(base64.StdEncoding.EncodeToString(content))}
+	if name != "" {
+		file.Name = &name
+	}
+	if mimeType != "" {
+		file.MimeType = &mimeType
+	}
+	part, err := json.Marshal(a2aSchema.FilePart{Type: "file", File: file})
+	require.NoError(t, err)
+	return a2aSchema.Artifact{Parts: []a2aSchema.Part{part}}
+}
+
+// fileMimeType returns the MIME type of the artifact's first part, "" if not set.
+func fileMimeType(t *testing.T, artifact *a2aSchema.Artifact) string {
+	t.Helper()
+	fp, err := a2aSchema.AsFilePart(artifact.Parts[0])
+	require.NoError(t, err)
+	if fp.File.MimeType == nil {
+		return ""
+	}
+	return *fp.File.MimeType
+}
+
+func newMimeDetector(enabled bool) *a2a.ArtifactMimeDetector {
+	cfg := config.NewInternalConfig()
+	cfg.SetA2ADetectArtifactMimeTypes(enabled)
+	return a2a.NewArtifactMimeDetector(cfg, zap.NewNop())
+}
+
+func TestArtifactMimeDetection(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  []byte
+		declared string
+		want     string
+	}{
+		{name: "html sniffed", content: []byte("<!DOCTYPE html><html><body>report</body></html>"), want: "text/html; charset=utf-8"},
+		{name: "png sniffed", content: pngHeader, want: "image/png"},
+		{name: "png declared kept", content: pngHeader, declared: "image/x-custom", want: "image/x-custom"},
+		{name: "extension refines plain text", fileName: "data.json", content: []byte(`{"rows":2}`), want: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact := newFileArtifact(t, tt.fileName, tt.content, tt.declared)
+			require.NoError(t, newMimeDetector(true).Apply(&artifact))
+			assert.Equal(t, tt.want, fileMimeType(t, transmit(t, artifact)))
+		})
+	}
+}
+
+func TestArtifactMimeDetectionDisabled(t *testing.T) {
+	artifact := newFileArtifact(t, "", pngHeader, "")
+	detector := newMimeDetector(false)
+	assert.False(t, detector.Enabled())
+	require.NoError(t, detector.Apply(&artifact))
+	assert.Equal(t, "", fileMimeType(t, &artifact))
+}
+
+func TestAssemblerMimeDetectionFallback(t *testing.T) {
+	html := newFileArtifact(t, "", []byte("<html><body>hi</body></html>"), "")
+	png := newFileArtifact(t, "", pngHeader, "image/png")
+	png.Index = 1
+
+	assembler := a2aClient.NewArtifactAssembler(a2aClient.WithMimeDetection())
+	require.NoError(t, assembler.Add(transmit(t, html)))
+	require.NoError(t, assembler.Add(transmit(t, png)))
+	assert.Equal(t, "text/html; charset=utf-8", fileMimeType(t, assembler.Artifact(0)))
+	assert.Equal(t, "image/png", fileMimeType(t, assembler.Artifact(1)))
+
+	plain := a2aClient.NewArtifactAssembler()
+	require.NoError(t, plain.Add(transmit(t, html)))
+	assert.Equal(t, "", fileMimeType(t, plain.Artifact(0)), "detection is off by default")
+}
+
+func TestTaskHandlerDetectsArtifactMimeTypes(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	cfg.SetA2ADetectArtifactMimeTypes(true)
+	produced := newFileArtifact(t, "", pngHeader, "")
+	client := serveTaskHandler(t, newTaskStore(0, 0), streamChunks(produced), a2a.WithArtifactProcessing(cfg))
+	ctx := context.Background()
+
+	params := testutil.NewTaskSendParams("t1", "", "draw")
+	events, err := client.SendTaskSubscribe(ctx, &params)
+	require.NoError(t, err)
+	var streamed *a2aSchema.Artifact
+	for event := range events {
+		require.NoError(t, event.Err)
+		if event.Artifact != nil {
+			streamed = &event.Artifact.Artifact
+		}
+	}
+	require.NotNil(t, streamed, "Subscribers should receive the artifact")
+	assert.Equal(t, "image/png", fileMimeType(t, streamed))
+
+	task, err := client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t1"})
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, 1)
+	assert.Equal(t, "image/png", fileMimeType(t, &task.Artifacts[0]), "The stored artifact should carry the detected type")
+	assert.Equal(t, "", fileMimeType(t, &produced), "The artifact of the processor should be left as is")
+}
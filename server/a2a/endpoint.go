@@ -0,0 +1,86 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// Authenticate returns the ID of the user sending a request to an A2A endpoint, or an
+// error if the request must be refused.
+type Authenticate func(r *http.Request) (userID string, err error)
+
+// RegisterTaskHandlers serves the A2A methods of every configured agent at its endpoint
+// path (see config.A2ACardBaseInfo.EndpointPath). Requests are authenticated with
+// authenticate and answered with 401 if it fails; methods the agent does not support are
+// rejected by MethodGuard, the others answered by a TaskHandler for store and processor,
+// sanitizing text and adding artifact metadata as configured (see WithTextSanitizing and
+// WithArtifactProcessing).
+// The agents share store, so the tasks of a user are reachable through each of them.
+func RegisterTaskHandlers(mux *http.ServeMux, cfg config.IConfig, store TaskStore, processor TaskProcessor, authenticate Authenticate, logger *zap.Logger) error {
+	names, err := cfg.A2AAgentNames()
+	if err != nil {
+		return fmt.Errorf("failed to get A2A agents: %w", err)
+	}
+	paths := make([]string, len(names))
+	agents := make(map[string]string, len(names)) // endpoint path -> agent name
+	for i, name := range names {
+		info, err := cfg.GetA2ACardBaseInfo(name)
+		if err != nil {
+			return fmt.Errorf("failed to get card info of agent '%s': %w", name, err)
+		}
+		paths[i] = info.EndpointPath()
+		if other, ok := agents[paths[i]]; ok {
+			return fmt.Errorf("a2a agents '%s' and '%s' both have their endpoint at '%s', set their url", other, name, paths[i])
+		}
+		agents[paths[i]] = name
+	}
+
+	for i, name := range names {
+		path := paths[i]
+		logger.Info("Registering A2A task handler", zap.String("agent", name), zap.String("path", path))
+		agentLogger := logger.With(zap.String("agent", name))
+		handler := NewTaskHandler(store, processor, requestScope, agentLogger, WithTextSanitizing(cfg), WithArtifactProcessing(cfg))
+		mux.Handle(path, authenticated(authenticate, MethodGuard(cfg, name, agentLogger, handler)))
+	}
+	return nil
+}
+
+// authenticated answers requests that authenticate refuses with 401 and passes the others
+// to next, with the user ID in their context (see requestScope).
+func authenticated(authenticate Authenticate, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userIDKey{}, userID)))
+	})
+}
+
+// userIDKey is the context key of the user ID of an authenticated request.
+type userIDKey struct{}
+
+// requestScope returns the scope of an authenticated request: its user, in no session
+// unless tasks/send names one.
+func requestScope(r *http.Request) TaskScope {
+	userID, _ := r.Context().Value(userIDKey{}).(string)
+	return TaskScope{UserID: userID}
+}
+
+// UnavailableProcessor fails every task, for agents without a TaskProcessor.
+var UnavailableProcessor = TaskProcessorFunc(func(_ context.Context, store TaskStore, scope TaskScope, task *schema.Task, _ schema.Message) {
+	text, _ := json.Marshal(schema.TextPart{Type: "text", Text: "The agent cannot process tasks"})
+	status := schema.TaskStatus{
+		State:   schema.TaskStateFailed,
+		Message: &schema.Message{Role: "agent", Parts: []schema.Part{schema.Part(text)}},
+	}
+	store.UpdateStatus(scope, task.ID, status)
+})
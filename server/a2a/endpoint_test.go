@@ -0,0 +1,54 @@
+package a2a_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRegisterTaskHandlers(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	require.NoError(t, cfg.SetA2AAgent("alpha", config.A2ACardBaseInfo{Path: "/agents/alpha.json"}))
+	require.NoError(t, cfg.SetA2AAgent("beta", config.A2ACardBaseInfo{Path: "/agents/beta.json"}))
+	authenticate := func(r *http.Request) (string, error) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			return "", errors.New("invalid key")
+		}
+		return testUser, nil
+	}
+	store := newTaskStore(0, 0)
+	err := a2a.RegisterTaskHandlers(http.NewServeMux(), cfg, store, echoProcessor, authenticate, zap.NewNop())
+	require.Error(t, err, "Two agents without a URL should not share the default endpoint")
+
+	require.NoError(t, cfg.SetA2AAgent("beta", config.A2ACardBaseInfo{Path: "/agents/beta.json", URL: "https://agents.example.com/beta"}))
+	mux := http.NewServeMux()
+	require.NoError(t, a2a.RegisterTaskHandlers(mux, cfg, store, a2a.UnavailableProcessor, authenticate, zap.NewNop()))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	send := func(path string, key string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tasks/send","params":{"id":"t1","message":{"role":"user","parts":[{"type":"text","text":"hi"}]}}}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	assert.Equal(t, http.StatusUnauthorized, send(config.DefaultA2AEndpointPath, "wrong").StatusCode)
+	assert.Equal(t, http.StatusOK, send(config.DefaultA2AEndpointPath, "key").StatusCode)
+	assert.Equal(t, http.StatusOK, send("/beta", "key").StatusCode)
+
+	task, err := store.Get(a2a.TaskScope{UserID: testUser}, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateFailed, task.Status.State, "Tasks should fail without a processor")
+}
@@ -0,0 +1,101 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// Methods every A2A agent accepts, and those depending on a capability of its card.
+var (
+	baseMethods      = []string{"tasks/send", "tasks/get", "tasks/cancel"}
+	streamingMethods = []string{"tasks/sendSubscribe", "tasks/resubscribe"}
+	pushMethods      = []string{"tasks/pushNotification/set", "tasks/pushNotification/get"}
+)
+
+// SupportedMethods returns the A2A methods an agent with the capabilities accepts.
+func SupportedMethods(caps a2aSchema.AgentCapabilities) []string {
+	methods := append([]string(nil), baseMethods...)
+	if caps.Streaming {
+		methods = append(methods, streamingMethods...)
+	}
+	if caps.PushNotifications {
+		methods = append(methods, pushMethods...)
+	}
+	return methods
+}
+
+// CheckMethod returns nil if an agent with the capabilities accepts the method. Otherwise
+// it returns the error to answer with: ErrorUnsupportedOperation for A2A methods that
+// need a capability the agent lacks, ErrorMethodNotFound for unknown methods. Both carry
+// an a2aSchema.UnsupportedMethodData listing what the agent supports.
+func CheckMethod(caps a2aSchema.AgentCapabilities, method string) *a2aSchema.JSONRPCError {
+	supported := SupportedMethods(caps)
+	for _, m := range supported {
+		if m == method {
+			return nil
+		}
+	}
+	code := a2aSchema.ErrorMethodNotFound
+	message := fmt.Sprintf("Method '%s' not found", method)
+	for _, m := range append(append([]string(nil), streamingMethods...), pushMethods...) {
+		if m == method {
+			code = a2aSchema.ErrorUnsupportedOperation
+			message = fmt.Sprintf("Method '%s' is not supported by this agent", method)
+			break
+		}
+	}
+	var data any = a2aSchema.UnsupportedMethodData{Method: method, SupportedMethods: supported, Capabilities: caps}
+	return &a2aSchema.JSONRPCError{Code: code, Message: message, Data: &data}
+}
+
+// MethodGuard answers JSON-RPC requests for methods the named agent does not support (see
+// CheckMethod) and passes all other requests to next. Requests that are not valid
+// JSON-RPC are passed on too, so next reports the problem.
+func MethodGuard(cfg config.IConfig, agentName string, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var req struct {
+			Method string `json:"method"`
+			ID     *any   `json:"id"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Method == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		info, err := cfg.GetA2ACardBaseInfo(agentName)
+		if err != nil {
+			logger.Error("Failed to get card info of agent", zap.String("agent", agentName), zap.Error(err))
+			next.ServeHTTP(w, r)
+			return
+		}
+		rpcErr := CheckMethod(agentCapabilities(info), req.Method)
+		if rpcErr == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		logger.Debug("Rejected unsupported A2A method", zap.String("agent", agentName), zap.String("method", req.Method))
+		w.Header().Set("Content-Type", "application/json")
+		resp := a2aSchema.JSONRPCResponse{JSONRPC: a2aSchema.JSONRPCVersion, Error: rpcErr, ID: req.ID}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.Error("Failed to encode unsupported method error", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package a2a_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aClient "github.com/gate4ai/mcp/shared/a2a/2025-draft/client"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newGuardedAgent serves an agent answering every request it receives with a working task,
+// behind the MethodGuard of the configured agent info.
+func newGuardedAgent(t *testing.T, info config.A2ACardBaseInfo) (*httptest.Server, *int) {
+	t.Helper()
+	cfg := config.NewInternalConfig()
+	require.NoError(t, cfg.SetA2AAgent("agent", info))
+	reached := new(int)
+	agent := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*reached++
+		var req struct {
+			ID any `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{"id":"t1","status":{"state":"working"}}}`, req.ID)
+	})
+	server := httptest.NewServer(a2a.MethodGuard(cfg, "agent", zap.NewNop(), agent))
+	t.Cleanup(server.Close)
+	return server, reached
+}
+
+func TestMethodGuardRejectsMethodsOfMissingCapabilities(t *testing.T) {
+	server, reached := newGuardedAgent(t, config.A2ACardBaseInfo{})
+	client, err := a2aClient.New(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.SetTaskPushNotification(context.Background(), &a2aSchema.TaskPushNotificationConfig{ID: "t1"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, a2aClient.ErrUnsupportedOperation), "error should map to ErrUnsupportedOperation: %v", err)
+	assert.Equal(t, 0, *reached, "unsupported methods must not reach the agent")
+
+	var rpcErr *a2aSchema.JSONRPCError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, a2aSchema.ErrorUnsupportedOperation, rpcErr.Code)
+	assert.Contains(t, rpcErr.Message, "tasks/pushNotification/set")
+	require.NotNil(t, rpcErr.Data)
+	encoded, err := json.Marshal(*rpcErr.Data)
+	require.NoError(t, err)
+	var data a2aSchema.UnsupportedMethodData
+	require.NoError(t, json.Unmarshal(encoded, &data))
+	assert.Equal(t, "tasks/pushNotification/set", data.Method)
+	assert.Equal(t, []string{"tasks/send", "tasks/get", "tasks/cancel"}, data.SupportedMethods)
+	assert.False(t, data.Capabilities.PushNotifications)
+
+	_, err = client.SendTask(context.Background(), &a2aSchema.TaskSendParams{ID: "t1"})
+	require.NoError(t, err, "base methods are always supported")
+	assert.Equal(t, 1, *reached)
+}
+
+func TestMethodGuardPassesMethodsOfConfiguredCapabilities(t *testing.T) {
+	server, reached := newGuardedAgent(t, config.A2ACardBaseInfo{PushNotifications: true})
+	client, err := a2aClient.New(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.GetTaskPushNotification(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, *reached)
+}
+
+func TestCheckMethodReportsUnknownMethods(t *testing.T) {
+	rpcErr := a2a.CheckMethod(a2aSchema.AgentCapabilities{Streaming: true}, "tasks/unknown")
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, a2aSchema.ErrorMethodNotFound, rpcErr.Code)
+	data := (*rpcErr.Data).(a2aSchema.UnsupportedMethodData)
+	assert.Contains(t, data.SupportedMethods, "tasks/sendSubscribe")
+
+	assert.Nil(t, a2a.CheckMethod(a2aSchema.AgentCapabilities{Streaming: true}, "tasks/resubscribe"))
+}
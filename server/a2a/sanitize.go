@@ -0,0 +1,76 @@
+package a2a
+
+import (
+	"encoding/json"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// sanitizePart returns the part with the control sequences stripped from its text if it
+// is a text part; other parts, and text parts without control sequences, are returned as is.
+func sanitizePart(part schema.Part) schema.Part {
+	text, err := schema.AsTextPart(part)
+	if err != nil {
+		return part
+	}
+	clean := shared.SanitizeText(text.Text)
+	if clean == text.Text {
+		return part
+	}
+	text.Text = clean
+	data, err := json.Marshal(text)
+	if err != nil {
+		return part
+	}
+	return schema.Part(data)
+}
+
+// sanitizeParts returns a copy of the parts with sanitizePart applied, leaving parts
+// shared with stored tasks untouched.
+func sanitizeParts(parts []schema.Part) []schema.Part {
+	if parts == nil {
+		return nil
+	}
+	sanitized := make([]schema.Part, len(parts))
+	for i, part := range parts {
+		sanitized[i] = sanitizePart(part)
+	}
+	return sanitized
+}
+
+// sanitizeMessage returns the message with its text parts sanitized.
+func sanitizeMessage(message schema.Message) schema.Message {
+	message.Parts = sanitizeParts(message.Parts)
+	return message
+}
+
+// sanitizeStatus returns the status with the text parts of its message sanitized.
+func sanitizeStatus(status schema.TaskStatus) schema.TaskStatus {
+	if status.Message != nil {
+		message := sanitizeMessage(*status.Message)
+		status.Message = &message
+	}
+	return status
+}
+
+// sanitizeTask returns a copy of the task with the text parts of its status message,
+// history and artifacts sanitized.
+func sanitizeTask(task *schema.Task) *schema.Task {
+	result := *task
+	result.Status = sanitizeStatus(task.Status)
+	if task.History != nil {
+		result.History = make([]schema.Message, len(task.History))
+		for i, message := range task.History {
+			result.History[i] = sanitizeMessage(message)
+		}
+	}
+	if task.Artifacts != nil {
+		result.Artifacts = make([]schema.Artifact, len(task.Artifacts))
+		for i, artifact := range task.Artifacts {
+			artifact.Parts = sanitizeParts(artifact.Parts)
+			result.Artifacts[i] = artifact
+		}
+	}
+	return &result
+}
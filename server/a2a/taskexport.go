@@ -0,0 +1,155 @@
+package a2a
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// ImportOption configures MemoryTaskStore.ImportTask.
+type ImportOption func(*importOptions)
+
+type importOptions struct {
+	overwrite bool
+}
+
+// OverwriteExisting replaces a stored task with the same ID instead of rejecting the import.
+func OverwriteExisting() ImportOption {
+	return func(o *importOptions) {
+		o.overwrite = true
+	}
+}
+
+// ExportTask serializes the full record of a stored task (status, message, artifacts,
+// history and metadata) to JSON. File parts are exported as stored: inline bytes stay
+// inline and files referenced by URI keep their URI, so the referenced content must be
+// reachable wherever the task is imported. The task ID is resolved in scope like by Get.
+func (s *MemoryTaskStore) ExportTask(scope TaskScope, taskID string) ([]byte, error) {
+	task, err := s.Get(scope, taskID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task '%s': %w", taskID, err)
+	}
+	return data, nil
+}
+
+// ImportTask stores a task exported by ExportTask as a task of the user, after validating
+// it. A task whose ID is already stored in the same scope is rejected with ErrTaskExists
+// unless OverwriteExisting is given. Imported tasks count towards the session limits like
+// created ones.
+func (s *MemoryTaskStore) ImportTask(userID string, data []byte, opts ...ImportOption) error {
+	var options importOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var task schema.Task
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&task); err != nil {
+		return fmt.Errorf("invalid task export: %w", err)
+	}
+	if err := ValidateTask(&task); err != nil {
+		return fmt.Errorf("invalid task export: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, exists := s.tasks[s.storedKey(userID, &task)]
+	if !exists {
+		return s.insert(userID, &task)
+	}
+	if !options.overwrite {
+		return fmt.Errorf("%w: '%s'", ErrTaskExists, task.ID)
+	}
+	previous := elem.Value.(*storedTask)
+	if storedSession(elem) == (sessionKey{userID: userID, sessionID: taskSessionID(&task)}) {
+		s.trackRunning(previous.userID, previous.task, nil)
+		s.trackRunning(userID, nil, &task)
+		elem.Value = &storedTask{task: &task, userID: userID}
+		s.sessions[storedSession(elem)].MoveToBack(elem)
+		s.enforceMaxTasks(storedSession(elem))
+		return nil
+	}
+	// Moving to another session: keep the previous task if the new session is full
+	s.remove(elem)
+	if err := s.insert(userID, &task); err != nil {
+		_ = s.insert(previous.userID, previous.task)
+		return err
+	}
+	return nil
+}
+
+// ValidateTask checks that a task is a well-formed A2A task record.
+func ValidateTask(task *schema.Task) error {
+	if task.ID == "" {
+		return errors.New("task has no ID")
+	}
+	switch task.Status.State {
+	case schema.TaskStateSubmitted, schema.TaskStateWorking, schema.TaskStateInputRequired,
+		schema.TaskStateCompleted, schema.TaskStateCanceled, schema.TaskStateFailed, schema.TaskStateUnknown:
+	default:
+		return fmt.Errorf("task '%s' has unknown state '%s'", task.ID, task.Status.State)
+	}
+	if task.Status.Message != nil {
+		if err := validateMessage(task.Status.Message); err != nil {
+			return fmt.Errorf("status message of task '%s': %w", task.ID, err)
+		}
+	}
+	for i := range task.History {
+		if err := validateMessage(&task.History[i]); err != nil {
+			return fmt.Errorf("history message %d of task '%s': %w", i, task.ID, err)
+		}
+	}
+	for i, artifact := range task.Artifacts {
+		if err := validateParts(artifact.Parts); err != nil {
+			return fmt.Errorf("artifact %d of task '%s': %w", i, task.ID, err)
+		}
+	}
+	return nil
+}
+
+// validateMessage checks the role and parts of a message.
+func validateMessage(msg *schema.Message) error {
+	if msg.Role != "user" && msg.Role != "agent" {
+		return fmt.Errorf("unknown role '%s'", msg.Role)
+	}
+	return validateParts(msg.Parts)
+}
+
+// validateParts checks that every part is a text, file or data part with its content.
+func validateParts(parts []schema.Part) error {
+	for i, part := range parts {
+		partType, err := schema.GetPartType(part)
+		if err != nil {
+			return fmt.Errorf("part %d: %w", i, err)
+		}
+		switch partType {
+		case "text":
+			_, err = schema.AsTextPart(part)
+		case "file":
+			var fp *schema.FilePart
+			if fp, err = schema.AsFilePart(part); err == nil && (fp.File.Bytes == nil) == (fp.File.URI == nil) {
+				err = errors.New("file must have either bytes or a URI")
+			}
+		case "data":
+			var dp *schema.DataPart
+			if dp, err = schema.AsDataPart(part); err == nil && dp.Data == nil {
+				err = errors.New("data part has no data")
+			}
+		default:
+			err = fmt.Errorf("unknown part type '%s'", partType)
+		}
+		if err != nil {
+			return fmt.Errorf("part %d: %w", i, err)
+		}
+	}
+	return nil
+}
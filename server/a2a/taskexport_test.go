@@ -0,0 +1,131 @@
+package a2a_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustPart(t *testing.T, part interface{}) a2aSchema.Part {
+	t.Helper()
+	data, err := json.Marshal(part)
+	require.NoError(t, err)
+	return a2aSchema.Part(data)
+}
+
+func newExportableTask(t *testing.T) *a2aSchema.Task {
+	uri := "https://files.example.com/report.pdf"
+	inline := "aGVsbG8="
+	metadata := map[string]interface{}{"origin": "test"}
+	task := newSessionTask("t1", "s1", a2aSchema.TaskStateCompleted)
+	task.Status.Message = &a2aSchema.Message{Role: "agent", Parts: []a2aSchema.Part{
+		mustPart(t, a2aSchema.TextPart{Type: "text", Text: "done"}),
+	}}
+	task.History = []a2aSchema.Message{
+		{Role: "user", Parts: []a2aSchema.Part{mustPart(t, a2aSchema.TextPart{Type: "text", Text: "make a report"})}},
+	}
+	task.Artifacts = []a2aSchema.Artifact{{Parts: []a2aSchema.Part{
+		mustPart(t, a2aSchema.FilePart{Type: "file", File: a2aSchema.FileContent{URI: &uri}}),
+		mustPart(t, a2aSchema.FilePart{Type: "file", File: a2aSchema.FileContent{Bytes: &inline}}),
+		mustPart(t, a2aSchema.DataPart{Type: "data", Data: map[string]interface{}{"pages": 3.0}}),
+	}}}
+	task.Metadata = &metadata
+	return task
+}
+
+func TestTaskExportImportRoundTrip(t *testing.T) {
+	source := newTaskStore(0, 0)
+	original := newExportableTask(t)
+	require.NoError(t, source.Create(testUser, original))
+
+	data, err := source.ExportTask(inSession("s1"), "t1")
+	require.NoError(t, err)
+
+	target := newTaskStore(0, 0)
+	require.NoError(t, target.ImportTask(testUser, data))
+	imported, err := target.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, original, imported)
+
+	// Files stored by reference keep their URI
+	file, err := a2aSchema.AsFilePart(imported.Artifacts[0].Parts[0])
+	require.NoError(t, err)
+	require.NotNil(t, file.File.URI)
+	assert.Equal(t, "https://files.example.com/report.pdf", *file.File.URI)
+	assert.Equal(t, map[string]int{"s1": 1}, target.SessionTaskCounts(testUser))
+}
+
+func TestTaskExportUnknownTask(t *testing.T) {
+	_, err := newTaskStore(0, 0).ExportTask(inSession("s1"), "missing")
+	var notFound *a2aSchema.TaskNotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestTaskImportDuplicates(t *testing.T) {
+	store := newTaskStore(0, 0)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	data, err := json.Marshal(newSessionTask("t1", "s1", a2aSchema.TaskStateCompleted))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, store.ImportTask(testUser, data), a2a.ErrTaskExists)
+	stored, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateWorking, stored.Status.State, "rejected import must not change the task")
+
+	require.NoError(t, store.ImportTask(testUser, data, a2a.OverwriteExisting()))
+	stored, err = store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCompleted, stored.Status.State)
+}
+
+func TestTaskImportOverwriteMovesTaskToAnotherSession(t *testing.T) {
+	// With per-session IDs the same ID in another session is another task, so moving
+	// needs a wider scope
+	store := newScopedTaskStore(config.A2ATaskIDScopeUser, 0, 0)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	moved, err := json.Marshal(newSessionTask("t1", "s2", a2aSchema.TaskStateCompleted))
+	require.NoError(t, err)
+	require.NoError(t, store.ImportTask(testUser, moved, a2a.OverwriteExisting()))
+	assert.Equal(t, map[string]int{"s2": 1}, store.SessionTaskCounts(testUser), "the emptied session should be dropped")
+}
+
+func TestTaskImportOverwriteKeepsTaskWhenSessionIsFull(t *testing.T) {
+	store := newScopedTaskStore(config.A2ATaskIDScopeUser, 0, 1)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t2", "s2", a2aSchema.TaskStateWorking)))
+	data, err := json.Marshal(newSessionTask("t1", "s2", a2aSchema.TaskStateWorking))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, store.ImportTask(testUser, data, a2a.OverwriteExisting()), a2a.ErrSessionTaskLimit)
+	stored, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", *stored.SessionID)
+}
+
+func TestTaskImportValidatesSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not JSON", `{"id":`},
+		{"unknown field", `{"id":"t1","status":{"state":"completed"},"owner":"x"}`},
+		{"missing ID", `{"status":{"state":"completed"}}`},
+		{"unknown state", `{"id":"t1","status":{"state":"paused"}}`},
+		{"unknown role", `{"id":"t1","status":{"state":"completed"},"history":[{"role":"system","parts":[]}]}`},
+		{"unknown part type", `{"id":"t1","status":{"state":"completed"},"artifacts":[{"index":0,"parts":[{"type":"video"}]}]}`},
+		{"file without content", `{"id":"t1","status":{"state":"completed"},"artifacts":[{"index":0,"parts":[{"type":"file","file":{}}]}]}`},
+		{"data part without data", `{"id":"t1","status":{"state":"completed","message":{"role":"agent","parts":[{"type":"data"}]}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTaskStore(0, 0)
+			assert.Error(t, store.ImportTask(testUser, []byte(tt.data)))
+			assert.Empty(t, store.SessionTaskCounts(testUser))
+		})
+	}
+}
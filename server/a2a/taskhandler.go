@@ -0,0 +1,353 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// TaskProcessor runs the tasks of an agent.
+type TaskProcessor interface {
+	// Process handles a message sent to a task, passed as stored, and reports progress
+	// with UpdateStatus and AppendArtifact of store until the task reaches a terminal or
+	// the input-required state. It should stop once UpdateStatus fails with
+	// ErrTaskTerminal, e.g. because the task was canceled.
+	Process(ctx context.Context, store TaskStore, scope TaskScope, task *schema.Task, message schema.Message)
+}
+
+// TaskProcessorFunc adapts a function to a TaskProcessor.
+type TaskProcessorFunc func(ctx context.Context, store TaskStore, scope TaskScope, task *schema.Task, message schema.Message)
+
+// Process calls f.
+func (f TaskProcessorFunc) Process(ctx context.Context, store TaskStore, scope TaskScope, task *schema.Task, message schema.Message) {
+	f(ctx, store, scope, task, message)
+}
+
+// TaskHandler answers the A2A task methods tasks/send, tasks/get, tasks/cancel,
+// tasks/sendSubscribe and tasks/resubscribe, keeping the tasks in a TaskStore and running
+// them with a TaskProcessor. Wrap it in MethodGuard to reject the methods an agent does
+// not support.
+type TaskHandler struct {
+	store     TaskStore
+	processor TaskProcessor
+	scope     func(r *http.Request) TaskScope
+	logger    *zap.Logger
+	sanitize  config.IConfig // Decides which text is stripped of control sequences, see WithTextSanitizing
+	artifacts config.IConfig // Decides which metadata is added to produced artifacts, see WithArtifactProcessing
+}
+
+// TaskHandlerOption configures a TaskHandler.
+type TaskHandlerOption func(*TaskHandler)
+
+// WithTextSanitizing strips terminal control sequences from the text parts of messages
+// sent to tasks if cfg enables inbound sanitization, and from those of the returned tasks
+// (status message, history and artifacts) and streamed updates if it enables
+// outbound sanitization. The settings are read for every request.
+func WithTextSanitizing(cfg config.IConfig) TaskHandlerOption {
+	return func(h *TaskHandler) {
+		h.sanitize = cfg
+	}
+}
+
+// WithArtifactProcessing fills in the missing MIME types of the file parts of the
+// artifacts the processor adds to a task if cfg enables a2a.detect_mime_types (see
+// ArtifactMimeDetector), and adds a sha256 checksum to their metadata if it enables
+// a2a.artifact_checksums (see ArtifactChecksummer). The settings are read for every
+// message sent to a task.
+func WithArtifactProcessing(cfg config.IConfig) TaskHandlerOption {
+	return func(h *TaskHandler) {
+		h.artifacts = cfg
+	}
+}
+
+// NewTaskHandler creates a handler for the tasks of store. scope returns the user and
+// session of a request; the sessionId sent with tasks/send takes precedence over the
+// session of the request. tasks/get, tasks/cancel and tasks/resubscribe, which only name
+// the task, find it in any session of the user (see TaskScope.AnySession).
+func NewTaskHandler(store TaskStore, processor TaskProcessor, scope func(r *http.Request) TaskScope, logger *zap.Logger, options ...TaskHandlerOption) *TaskHandler {
+	h := &TaskHandler{store: store, processor: processor, scope: scope, logger: logger}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+// ServeHTTP answers a JSON-RPC request for a task method.
+func (h *TaskHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req schema.JSONRPCRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, nil, &schema.JSONRPCError{Code: schema.ErrorParseError, Message: "Invalid JSON"})
+		return
+	}
+	var id any
+	if req.ID != nil {
+		id = *req.ID
+	}
+	scope := h.scope(r)
+	logger := h.logger.With(zap.String("method", req.Method), zap.Any("id", id))
+
+	switch req.Method {
+	case "tasks/send", "tasks/sendSubscribe":
+		var params schema.TaskSendParams
+		if !h.decodeParams(w, &req, &params) {
+			return
+		}
+		if h.sanitizeInbound() {
+			params.Message = sanitizeMessage(params.Message)
+		}
+		if params.SessionID != nil {
+			scope.SessionID = *params.SessionID
+		}
+		task, err := h.startTask(scope, &params)
+		if err != nil {
+			h.writeError(w, req.ID, rpcError(err))
+			return
+		}
+		if req.Method == "tasks/sendSubscribe" {
+			events, unsubscribe, err := h.store.Subscribe(scope, task.ID)
+			if err != nil {
+				h.writeError(w, req.ID, rpcError(err))
+				return
+			}
+			defer unsubscribe()
+			// The task keeps running if the client goes away, it may resubscribe
+			go h.processor.Process(context.WithoutCancel(r.Context()), h.processStore(), scope, task, params.Message)
+			if err := h.writeEvents(r.Context(), w, id, events); err != nil {
+				logger.Debug("Stopped streaming task events", zap.Error(err))
+			}
+			return
+		}
+		h.processor.Process(r.Context(), h.processStore(), scope, task, params.Message)
+		h.writeTask(w, req.ID, scope, task.ID, params.HistoryLength)
+
+	case "tasks/get":
+		scope.AnySession = true // The task may be in the session named by tasks/send
+		var params schema.TaskQueryParams
+		if h.decodeParams(w, &req, &params) {
+			h.writeTask(w, req.ID, scope, params.ID, params.HistoryLength)
+		}
+
+	case "tasks/cancel":
+		scope.AnySession = true
+		var params schema.TaskIdParams
+		if !h.decodeParams(w, &req, &params) {
+			return
+		}
+		task, err := h.store.UpdateStatus(scope, params.ID, schema.TaskStatus{State: schema.TaskStateCanceled})
+		if errors.Is(err, ErrTaskTerminal) {
+			err = &schema.TaskNotCancelableError{Code: schema.ErrorTaskNotCancelable, Message: "Task cannot be canceled"}
+		}
+		if err != nil {
+			h.writeError(w, req.ID, rpcError(err))
+			return
+		}
+		h.writeResult(w, req.ID, h.outboundTask(withHistory(task, nil)))
+
+	case "tasks/resubscribe":
+		scope.AnySession = true
+		var params schema.TaskQueryParams
+		if !h.decodeParams(w, &req, &params) {
+			return
+		}
+		events, unsubscribe, err := h.store.Subscribe(scope, params.ID)
+		if err != nil {
+			h.writeError(w, req.ID, rpcError(err))
+			return
+		}
+		defer unsubscribe()
+		if err := h.writeEvents(r.Context(), w, id, events); err != nil {
+			logger.Debug("Stopped streaming task events", zap.Error(err))
+		}
+
+	default:
+		h.writeError(w, req.ID, &schema.JSONRPCError{Code: schema.ErrorMethodNotFound, Message: "Method '" + req.Method + "' not found"})
+	}
+}
+
+// startTask creates the task a tasks/send request is sent to or, if it exists and is not
+// in a terminal state, adds the message to its history. It returns the task as stored.
+func (h *TaskHandler) startTask(scope TaskScope, params *schema.TaskSendParams) (*schema.Task, error) {
+	task, err := h.store.Get(scope, params.ID)
+	var notFound *schema.TaskNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		task = &schema.Task{
+			ID:       params.ID,
+			Status:   schema.TaskStatus{State: schema.TaskStateSubmitted, Timestamp: time.Now().UTC()},
+			History:  []schema.Message{params.Message},
+			Metadata: params.Metadata,
+		}
+		if scope.SessionID != "" {
+			sessionID := scope.SessionID
+			task.SessionID = &sessionID
+		}
+		if err := h.store.Create(scope.UserID, task); err != nil {
+			return nil, err
+		}
+		return task, nil
+	case err != nil:
+		return nil, err
+	case task.Status.State.IsFinal():
+		return nil, ErrTaskTerminal
+	}
+	return h.store.AppendMessage(scope, task.ID, params.Message)
+}
+
+// decodeParams decodes the params of the request, answering with an error if they are invalid.
+func (h *TaskHandler) decodeParams(w http.ResponseWriter, req *schema.JSONRPCRequest, params any) bool {
+	if req.Params == nil {
+		h.writeError(w, req.ID, &schema.JSONRPCError{Code: schema.ErrorInvalidParams, Message: "Missing params"})
+		return false
+	}
+	if err := json.Unmarshal(*req.Params, params); err != nil {
+		h.writeError(w, req.ID, &schema.JSONRPCError{Code: schema.ErrorInvalidParams, Message: "Invalid params: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeTask answers with the task ID accessed in scope, with up to historyLength messages
+// of its history.
+func (h *TaskHandler) writeTask(w http.ResponseWriter, id *any, scope TaskScope, taskID string, historyLength *int) {
+	task, err := h.store.Get(scope, taskID)
+	if err != nil {
+		h.writeError(w, id, rpcError(err))
+		return
+	}
+	h.writeResult(w, id, h.outboundTask(withHistory(task, historyLength)))
+}
+
+// processStore returns the store the processor reports the progress of a task to: the
+// store of the handler, adding metadata to the produced artifacts if configured.
+func (h *TaskHandler) processStore() TaskStore {
+	if h.artifacts == nil {
+		return h.store
+	}
+	return &artifactStore{
+		TaskStore: h.store,
+		mimeTypes: NewArtifactMimeDetector(h.artifacts, h.logger),
+		checksums: NewArtifactChecksummer(h.artifacts, h.logger),
+	}
+}
+
+// writeEvents streams the updates of a subscription, with the text of their messages and
+// artifacts sanitized if outbound sanitization is enabled.
+func (h *TaskHandler) writeEvents(ctx context.Context, w http.ResponseWriter, id any, events <-chan TaskUpdate) error {
+	if !h.sanitizeOutbound() {
+		return WriteTaskEvents(ctx, w, id, events)
+	}
+	return writeTaskEvents(ctx, w, id, events, func(event TaskUpdate) TaskUpdate {
+		if event.Status != nil {
+			status := *event.Status
+			status.Status = sanitizeStatus(status.Status)
+			event.Status = &status
+		}
+		if event.Artifact != nil {
+			artifact := *event.Artifact
+			artifact.Artifact.Parts = sanitizeParts(artifact.Artifact.Parts)
+			event.Artifact = &artifact
+		}
+		return event
+	})
+}
+
+// outboundTask returns the task to answer with: a sanitized copy if outbound sanitization
+// is enabled, the task itself otherwise.
+func (h *TaskHandler) outboundTask(task *schema.Task) *schema.Task {
+	if h.sanitizeOutbound() {
+		return sanitizeTask(task)
+	}
+	return task
+}
+
+// sanitizeInbound reports whether text sent by clients must be stripped of control sequences.
+func (h *TaskHandler) sanitizeInbound() bool {
+	if h.sanitize == nil {
+		return false
+	}
+	enabled, err := h.sanitize.SanitizeInboundText()
+	if err != nil {
+		h.logger.Error("Failed to read inbound sanitization setting", zap.Error(err))
+		return false
+	}
+	return enabled
+}
+
+// sanitizeOutbound reports whether text returned to clients must be stripped of control sequences.
+func (h *TaskHandler) sanitizeOutbound() bool {
+	if h.sanitize == nil {
+		return false
+	}
+	enabled, err := h.sanitize.SanitizeOutboundText()
+	if err != nil {
+		h.logger.Error("Failed to read outbound sanitization setting", zap.Error(err))
+		return false
+	}
+	return enabled
+}
+
+// withHistory returns the task with the last historyLength messages of its history, none
+// if historyLength is not set.
+func withHistory(task *schema.Task, historyLength *int) *schema.Task {
+	result := *task
+	switch {
+	case historyLength == nil || *historyLength <= 0:
+		result.History = nil
+	case *historyLength < len(task.History):
+		result.History = task.History[len(task.History)-*historyLength:]
+	}
+	return &result
+}
+
+// writeResult answers the request id with result.
+func (h *TaskHandler) writeResult(w http.ResponseWriter, id *any, result any) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		h.logger.Error("Failed to encode task result", zap.Error(err))
+		h.writeError(w, id, &schema.JSONRPCError{Code: schema.ErrorInternalError, Message: "Failed to encode result"})
+		return
+	}
+	raw := json.RawMessage(data)
+	h.write(w, schema.JSONRPCResponse{JSONRPC: schema.JSONRPCVersion, Result: &raw, ID: id})
+}
+
+// writeError answers the request id with rpcErr.
+func (h *TaskHandler) writeError(w http.ResponseWriter, id *any, rpcErr *schema.JSONRPCError) {
+	h.write(w, schema.JSONRPCResponse{JSONRPC: schema.JSONRPCVersion, Error: rpcErr, ID: id})
+}
+
+func (h *TaskHandler) write(w http.ResponseWriter, resp schema.JSONRPCResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode task response", zap.Error(err))
+	}
+}
+
+// rpcError returns the JSON-RPC error answering a request that failed with err.
+func rpcError(err error) *schema.JSONRPCError {
+	var notFound *schema.TaskNotFoundError
+	var notCancelable *schema.TaskNotCancelableError
+	switch {
+	case errors.As(err, &notFound):
+		return &schema.JSONRPCError{Code: notFound.Code, Message: notFound.Message}
+	case errors.As(err, &notCancelable):
+		return &schema.JSONRPCError{Code: notCancelable.Code, Message: notCancelable.Message}
+	case errors.Is(err, ErrTaskExists), errors.Is(err, ErrTaskTerminal):
+		return &schema.JSONRPCError{Code: schema.ErrorInvalidParams, Message: err.Error()}
+	case errors.Is(err, ErrUserTaskLimit), errors.Is(err, ErrSessionTaskLimit):
+		return &schema.JSONRPCError{Code: schema.ErrorInvalidRequest, Message: err.Error()}
+	default:
+		return &schema.JSONRPCError{Code: schema.ErrorInternalError, Message: err.Error()}
+	}
+}
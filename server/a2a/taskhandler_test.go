@@ -0,0 +1,255 @@
+package a2a_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aClient "github.com/gate4ai/mcp/shared/a2a/2025-draft/client"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// echoProcessor answers every message with an artifact and an agent message echoing it.
+var echoProcessor = a2a.TaskProcessorFunc(func(ctx context.Context, store a2a.TaskStore, scope a2a.TaskScope, task *a2aSchema.Task, message a2aSchema.Message) {
+	if _, err := store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking}); err != nil {
+		return
+	}
+	text := testutil.TextOf(&message)
+	if _, err := store.AppendArtifact(scope, task.ID, a2aSchema.Artifact{Parts: []a2aSchema.Part{testutil.NewTextPart(text)}}); err != nil {
+		return
+	}
+	reply := testutil.NewTextMessage("agent", "echo: "+text)
+	_, _ = store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateCompleted, Message: &reply})
+})
+
+// serveTaskHandler serves the tasks of store for requests of the test user.
+func serveTaskHandler(t *testing.T, store a2a.TaskStore, processor a2a.TaskProcessor, options ...a2a.TaskHandlerOption) *a2aClient.Client {
+	t.Helper()
+	scope := func(r *http.Request) a2a.TaskScope { return a2a.TaskScope{UserID: testUser} }
+	server := httptest.NewServer(a2a.NewTaskHandler(store, processor, scope, zap.NewNop(), options...))
+	t.Cleanup(server.Close)
+	client, err := a2aClient.New(server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestTaskHandlerSendGetCancel(t *testing.T) {
+	store := newTaskStore(0, 0)
+	client := serveTaskHandler(t, store, echoProcessor)
+	ctx := context.Background()
+
+	params := testutil.NewTaskSendParams("t1", "s1", "hello")
+	task, err := client.SendTask(ctx, &params)
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCompleted, task.Status.State)
+	require.Len(t, task.Artifacts, 1)
+	assert.Equal(t, testutil.NewTextPart("hello"), task.Artifacts[0].Parts[0])
+	assert.Nil(t, task.History, "History should only be returned when asked for")
+
+	stored, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err, "The task should be kept in the store")
+	assert.Equal(t, a2aSchema.TaskStateCompleted, stored.Status.State)
+
+	// tasks/get only names the task, which is found in the session tasks/send named
+	task, err = client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t1", HistoryLength: testutil.PointerTo(1)})
+	require.NoError(t, err)
+	assert.Equal(t, "s1", *task.SessionID)
+	_, err = store.Get(inSession(""), "t1")
+	require.Error(t, err, "Only scopes with AnySession look into other sessions")
+
+	params = testutil.NewTaskSendParams("t2", "", "second")
+	_, err = client.SendTask(ctx, &params)
+	require.NoError(t, err)
+	task, err = client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t2", HistoryLength: testutil.PointerTo(1)})
+	require.NoError(t, err)
+	require.Len(t, task.History, 1)
+	assert.Equal(t, "echo: second", testutil.TextOf(&task.History[0]))
+
+	_, err = client.CancelTask(ctx, "t2")
+	var rpcErr *a2aSchema.JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, a2aSchema.ErrorTaskNotCancelable, rpcErr.Code)
+
+	_, err = client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "missing"})
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, a2aSchema.ErrorTaskNotFound, rpcErr.Code)
+}
+
+func TestTaskHandlerStreamsAndCancelsTasks(t *testing.T) {
+	store := newTaskStore(0, 0)
+	started := make(chan struct{})
+	waitForCancel := a2a.TaskProcessorFunc(func(ctx context.Context, store a2a.TaskStore, scope a2a.TaskScope, task *a2aSchema.Task, message a2aSchema.Message) {
+		_, _ = store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking})
+		close(started)
+	})
+	client := serveTaskHandler(t, store, waitForCancel)
+	ctx := context.Background()
+
+	params := testutil.NewTaskSendParams("t1", "", "long")
+	events, err := client.SendTaskSubscribe(ctx, &params)
+	require.NoError(t, err)
+	<-started
+	task, err := client.CancelTask(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCanceled, task.Status.State)
+
+	var states []a2aSchema.TaskState
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				done = true
+				break
+			}
+			require.NoError(t, event.Err)
+			if event.Status != nil {
+				states = append(states, event.Status.Status.State)
+			}
+		case <-timeout:
+			t.Fatal("Subscription was not closed after cancellation")
+		}
+	}
+	assert.Equal(t, []a2aSchema.TaskState{a2aSchema.TaskStateWorking, a2aSchema.TaskStateCanceled}, states)
+}
+
+func TestTaskHandlerAddsFollowUpMessagesToHistory(t *testing.T) {
+	store := newTaskStore(0, 0)
+	askName := a2a.TaskProcessorFunc(func(ctx context.Context, store a2a.TaskStore, scope a2a.TaskScope, task *a2aSchema.Task, message a2aSchema.Message) {
+		if len(task.History) == 1 {
+			question := testutil.NewTextMessage("agent", "Who is asking?")
+			_, _ = store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateInputRequired, Message: &question})
+			return
+		}
+		reply := testutil.NewTextMessage("agent", "Hello "+testutil.TextOf(&message))
+		_, _ = store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateCompleted, Message: &reply})
+	})
+	client := serveTaskHandler(t, store, askName)
+	ctx := context.Background()
+
+	params := testutil.NewTaskSendParams("t1", "s1", "greet me")
+	task, err := client.SendTask(ctx, &params)
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateInputRequired, task.Status.State)
+	params = testutil.NewTaskSendParams("t1", "s1", "Ada")
+	task, err = client.SendTask(ctx, &params)
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCompleted, task.Status.State)
+
+	task, err = client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t1", HistoryLength: testutil.PointerTo(10)})
+	require.NoError(t, err)
+	var history []string
+	for _, message := range task.History {
+		history = append(history, message.Role+": "+testutil.TextOf(&message))
+	}
+	assert.Equal(t, []string{"user: greet me", "agent: Who is asking?", "user: Ada", "agent: Hello Ada"}, history)
+
+	// A completed task takes no more messages
+	params = testutil.NewTaskSendParams("t1", "s1", "again")
+	_, err = client.SendTask(ctx, &params)
+	var rpcErr *a2aSchema.JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	stored, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Len(t, stored.History, 4)
+}
+
+func TestTaskHandlerFindsTasksOfSentSession(t *testing.T) {
+	store := newTaskStore(0, 0)
+	keepWorking := a2a.TaskProcessorFunc(func(ctx context.Context, store a2a.TaskStore, scope a2a.TaskScope, task *a2aSchema.Task, message a2aSchema.Message) {
+		_, _ = store.UpdateStatus(scope, task.ID, a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking})
+	})
+	client := serveTaskHandler(t, store, keepWorking)
+	ctx := context.Background()
+
+	params := testutil.NewTaskSendParams("t1", "s1", "long")
+	task, err := client.SendTask(ctx, &params)
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateWorking, task.Status.State)
+
+	task, err = client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t1"})
+	require.NoError(t, err, "The task should be found outside the session of the request")
+	assert.Equal(t, a2aSchema.TaskStateWorking, task.Status.State)
+
+	task, err = client.CancelTask(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCanceled, task.Status.State)
+	stored, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCanceled, stored.Status.State)
+
+	// Another user does not find it in any session
+	_, err = store.Get(a2a.TaskScope{UserID: "other", AnySession: true}, "t1")
+	var notFound *a2aSchema.TaskNotFoundError
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestTaskHandlerSanitizesText(t *testing.T) {
+	const raw, clean = "red \x1b[31mtext\x1b[0m\u202e", "red text"
+	ctx := context.Background()
+
+	t.Run("inbound", func(t *testing.T) {
+		cfg := config.NewInternalConfig()
+		cfg.SetSanitizeText(true, false)
+		store := newTaskStore(0, 0)
+		client := serveTaskHandler(t, store, echoProcessor, a2a.WithTextSanitizing(cfg))
+
+		params := testutil.NewTaskSendParams("t1", "", raw)
+		_, err := client.SendTask(ctx, &params)
+		require.NoError(t, err)
+		stored, err := store.Get(inSession(""), "t1")
+		require.NoError(t, err)
+		assert.Equal(t, clean, testutil.TextOf(&stored.History[0]), "The processor should get the sanitized message")
+		assert.Equal(t, testutil.NewTextPart(clean), stored.Artifacts[0].Parts[0])
+	})
+
+	t.Run("outbound", func(t *testing.T) {
+		cfg := config.NewInternalConfig()
+		cfg.SetSanitizeText(false, true)
+		store := newTaskStore(0, 0)
+		client := serveTaskHandler(t, store, echoProcessor, a2a.WithTextSanitizing(cfg))
+
+		params := testutil.NewTaskSendParams("t1", "", raw)
+		task, err := client.SendTask(ctx, &params)
+		require.NoError(t, err)
+		assert.Equal(t, "echo: "+clean, testutil.TextOf(task.Status.Message))
+		assert.Equal(t, testutil.NewTextPart(clean), task.Artifacts[0].Parts[0])
+		task, err = client.GetTask(ctx, &a2aSchema.TaskQueryParams{ID: "t1", HistoryLength: testutil.PointerTo(2)})
+		require.NoError(t, err)
+		require.Len(t, task.History, 2)
+		assert.Equal(t, clean, testutil.TextOf(&task.History[0]))
+		stored, err := store.Get(inSession(""), "t1")
+		require.NoError(t, err)
+		assert.Equal(t, raw, testutil.TextOf(&stored.History[0]), "The stored task should be left as is")
+
+		params = testutil.NewTaskSendParams("t2", "", raw)
+		events, err := client.SendTaskSubscribe(ctx, &params)
+		require.NoError(t, err)
+		var final *a2aSchema.TaskStatusUpdateEvent
+		for event := range events {
+			require.NoError(t, event.Err)
+			if event.Status != nil && event.Status.Final {
+				final = event.Status
+			}
+		}
+		require.NotNil(t, final)
+		assert.Equal(t, "echo: "+clean, testutil.TextOf(final.Status.Message))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		client := serveTaskHandler(t, newTaskStore(0, 0), echoProcessor, a2a.WithTextSanitizing(config.NewInternalConfig()))
+		params := testutil.NewTaskSendParams("t1", "", raw)
+		task, err := client.SendTask(ctx, &params)
+		require.NoError(t, err)
+		assert.Equal(t, "echo: "+raw, testutil.TextOf(task.Status.Message))
+	})
+}
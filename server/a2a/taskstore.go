@@ -0,0 +1,600 @@
+package a2a
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// ErrSessionTaskLimit is returned when a task is created in a session that already
+// holds a2a.session_task_hard_limit tasks that cannot be evicted.
+var ErrSessionTaskLimit = errors.New("session task limit reached")
+
+// ErrUserTaskLimit is returned when a user who already has as many running tasks as the
+// concurrency limit creates another one.
+var ErrUserTaskLimit = errors.New("task concurrency limit reached")
+
+// UserParamMaxConcurrentTasks is the user param overriding a2a.max_concurrent_user_tasks
+// for a user, e.g. "10"; "0" lifts the limit.
+const UserParamMaxConcurrentTasks = "a2a_max_concurrent_tasks"
+
+// ErrTaskExists is returned when a task is created or imported with the ID of a task
+// stored in the same scope.
+var ErrTaskExists = errors.New("task already exists")
+
+// ErrTaskTerminal is returned when the status of a task that reached a terminal state
+// (completed, canceled or failed) is updated.
+var ErrTaskTerminal = errors.New("task already reached a terminal state")
+
+// TaskScope identifies who accesses a task by its ID: the authenticated user, "" for
+// anonymous requests, and the session of the request.
+type TaskScope struct {
+	UserID    string
+	SessionID string
+	// AnySession resolves the task ID in the other sessions of the user too, for methods
+	// naming a task without its session such as tasks/get: the task in SessionID is
+	// preferred, then the task with the ID the user stored last.
+	AnySession bool
+}
+
+// TaskStore keeps the A2A tasks of the agents served by the gateway. TaskHandler answers
+// the task methods through it, so tasks can be kept elsewhere than in memory, e.g. in the
+// database of config.DatabaseConfig.
+//
+// Implementations must be safe for concurrent use and return copies of the stored tasks.
+// Task IDs are resolved in a TaskScope, and tasks of other users must be reported as not
+// found with a *schema.TaskNotFoundError. Create fails with ErrTaskExists for an ID used in
+// the scope of the task, UpdateStatus and AppendMessage with ErrTaskTerminal for a task in
+// a terminal state.
+type TaskStore interface {
+	// Create stores a new task of the user.
+	Create(userID string, task *schema.Task) error
+	// Get returns the task ID accessed in scope.
+	Get(scope TaskScope, taskID string) (*schema.Task, error)
+	// UpdateStatus sets the status of the task ID accessed in scope and returns the task.
+	UpdateStatus(scope TaskScope, taskID string, status schema.TaskStatus) (*schema.Task, error)
+	// AppendArtifact adds an artifact to the task ID accessed in scope and returns the task.
+	AppendArtifact(scope TaskScope, taskID string, artifact schema.Artifact) (*schema.Task, error)
+	// AppendMessage adds a message sent to the task ID accessed in scope to its history and
+	// returns the task.
+	AppendMessage(scope TaskScope, taskID string, message schema.Message) (*schema.Task, error)
+	// List returns the tasks the user of scope created in its session.
+	List(scope TaskScope) ([]*schema.Task, error)
+	// Subscribe streams the status updates and added artifacts of the task ID accessed in
+	// scope until its final status update; the returned function ends the subscription early.
+	Subscribe(scope TaskScope, taskID string) (<-chan TaskUpdate, func(), error)
+}
+
+var _ TaskStore = (*MemoryTaskStore)(nil)
+
+// MemoryTaskStore is the default TaskStore, keeping tasks in memory grouped by session.
+// Sessions belong to the user who created the tasks: the same session ID sent by different
+// users names distinct sessions, and tasks without a session ID are grouped under the
+// empty session of their user. Beyond a2a.max_session_tasks tasks in a session, the least
+// recently used terminal tasks (completed, canceled or failed) are evicted; tasks that
+// are still running are never evicted. Once a session holds a2a.session_task_hard_limit
+// tasks, new tasks in it are rejected.
+//
+// Task IDs are chosen by clients, so they are resolved within a2a.task_id_scope: by
+// default the same ID in different sessions refers to distinct tasks, with "user" it
+// does in different sessions of a user, and with "global" a task whose ID is already
+// stored is rejected. Whatever the scope, a task is only found by the user who created it.
+//
+// A user with a2a.max_concurrent_user_tasks running (non-terminal) tasks cannot create
+// another one until one of them reaches a terminal state; the user param
+// UserParamMaxConcurrentTasks overrides the limit per user. Anonymous requests share
+// the limit of the empty user.
+type MemoryTaskStore struct {
+	cfg       config.IConfig
+	logger    *zap.Logger
+	maxTasks  int    // 0 means unlimited
+	hardLimit int    // 0 means unlimited
+	userLimit int    // Running tasks per user, 0 means unlimited
+	idScope   string // One of the config.A2ATaskIDScope values
+
+	mu       sync.Mutex
+	tasks    map[taskKey]*list.Element       // scoped task ID -> element of its session list
+	byUser   map[userTaskKey][]*list.Element // user and task ID -> elements in any session, oldest first
+	sessions map[sessionKey]*list.List       // session of a user -> tasks, least recently used first
+	running  map[string]int                  // user ID -> number of stored non-terminal tasks
+
+	subscribers map[taskKey][]*taskSubscriber // Update streams of running tasks, see Subscribe
+}
+
+// taskKey is the ID of a task within its scope.
+type taskKey struct {
+	userID    string
+	sessionID string
+	taskID    string
+}
+
+// userTaskKey is the ID of a task of a user, whatever its session.
+type userTaskKey struct {
+	userID string
+	taskID string
+}
+
+// sessionKey is a session of a user.
+type sessionKey struct {
+	userID    string
+	sessionID string
+}
+
+// storedTask is a task together with the user who created it.
+type storedTask struct {
+	task   *schema.Task
+	userID string
+}
+
+// NewMemoryTaskStore creates an empty store with the session limits of cfg.
+func NewMemoryTaskStore(cfg config.IConfig, logger *zap.Logger) *MemoryTaskStore {
+	maxTasks, err := cfg.A2AMaxSessionTasks()
+	if err != nil {
+		logger.Error("Failed to get max session tasks from config", zap.Error(err))
+	}
+	hardLimit, err := cfg.A2ASessionTaskHardLimit()
+	if err != nil {
+		logger.Error("Failed to get session task hard limit from config", zap.Error(err))
+	}
+	idScope, err := cfg.A2ATaskIDScope()
+	if err != nil {
+		logger.Error("Failed to get task ID scope from config", zap.Error(err))
+	}
+	if idScope == "" {
+		idScope = config.A2ATaskIDScopeSession
+	}
+	userLimit, err := cfg.A2AMaxConcurrentUserTasks()
+	if err != nil {
+		logger.Error("Failed to get max concurrent user tasks from config", zap.Error(err))
+	}
+	return &MemoryTaskStore{
+		cfg:       cfg,
+		logger:    logger,
+		maxTasks:  maxTasks,
+		hardLimit: hardLimit,
+		userLimit: userLimit,
+		idScope:   idScope,
+		tasks:     make(map[taskKey]*list.Element),
+		byUser:    make(map[userTaskKey][]*list.Element),
+		sessions:  make(map[sessionKey]*list.List),
+		running:   make(map[string]int),
+
+		subscribers: make(map[taskKey][]*taskSubscriber),
+	}
+}
+
+// key returns the key of a task ID accessed in scope.
+func (s *MemoryTaskStore) key(scope TaskScope, taskID string) taskKey {
+	switch s.idScope {
+	case config.A2ATaskIDScopeGlobal:
+		return taskKey{taskID: taskID}
+	case config.A2ATaskIDScopeUser:
+		return taskKey{userID: scope.UserID, taskID: taskID}
+	default:
+		return taskKey{userID: scope.UserID, sessionID: scope.SessionID, taskID: taskID}
+	}
+}
+
+// storedKey returns the key of a stored task created by userID.
+func (s *MemoryTaskStore) storedKey(userID string, task *schema.Task) taskKey {
+	return s.key(TaskScope{UserID: userID, SessionID: taskSessionID(task)}, task.ID)
+}
+
+// lookup returns the element of the task ID accessed in scope, if the task was created by
+// the user of the scope. The caller must hold s.mu.
+func (s *MemoryTaskStore) lookup(scope TaskScope, taskID string) (*list.Element, bool) {
+	elem, ok := s.tasks[s.key(scope, taskID)]
+	if ok && elem.Value.(*storedTask).userID == scope.UserID {
+		return elem, true
+	}
+	if scope.AnySession {
+		if elems := s.byUser[userTaskKey{userID: scope.UserID, taskID: taskID}]; len(elems) > 0 {
+			return elems[len(elems)-1], true
+		}
+	}
+	return nil, false // Tasks of other users are reported as not found
+}
+
+// forget removes a task that is no longer stored from the index of the tasks of its
+// user. The caller must hold s.mu.
+func (s *MemoryTaskStore) forget(elem *list.Element) {
+	stored := elem.Value.(*storedTask)
+	key := userTaskKey{userID: stored.userID, taskID: stored.task.ID}
+	elems := slices.DeleteFunc(s.byUser[key], func(other *list.Element) bool { return other == elem })
+	if len(elems) == 0 {
+		delete(s.byUser, key)
+	} else {
+		s.byUser[key] = elems
+	}
+}
+
+// Create stores a new task of the user. It fails with ErrTaskExists if the ID is used in
+// the task's scope, with ErrUserTaskLimit if the task is running and the user is at the
+// concurrency limit, and with ErrSessionTaskLimit if the session is at the hard limit and
+// none of its tasks is terminal.
+func (s *MemoryTaskStore) Create(userID string, task *schema.Task) error {
+	limit := s.userTaskLimit(userID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[s.storedKey(userID, task)]; exists {
+		return fmt.Errorf("%w: '%s'", ErrTaskExists, task.ID)
+	}
+	if running := s.running[userID]; limit > 0 && running >= limit && !task.Status.State.IsFinal() {
+		return fmt.Errorf("%w: %d of %d tasks running", ErrUserTaskLimit, running, limit)
+	}
+	return s.insert(userID, task)
+}
+
+// userTaskLimit returns the number of running tasks the user may have, 0 for no limit.
+func (s *MemoryTaskStore) userTaskLimit(userID string) int {
+	params, err := s.cfg.GetUserParams(userID)
+	if err != nil {
+		return s.userLimit
+	}
+	limit, ok, err := userParamTaskLimit(params)
+	if err != nil {
+		s.logger.Warn("Invalid task concurrency limit in user params, using the default",
+			zap.String("param", UserParamMaxConcurrentTasks), zap.String("value", params[UserParamMaxConcurrentTasks]))
+		return s.userLimit
+	}
+	if !ok {
+		return s.userLimit
+	}
+	return limit
+}
+
+// UserTaskLimit returns the number of running tasks the user may have according to the
+// current configuration, 0 for no limit: the user param UserParamMaxConcurrentTasks if
+// set and valid, a2a.max_concurrent_user_tasks otherwise.
+func UserTaskLimit(cfg config.IConfig, userID string) (int, error) {
+	if params, err := cfg.GetUserParams(userID); err == nil {
+		if limit, ok, err := userParamTaskLimit(params); ok && err == nil {
+			return limit, nil
+		}
+	}
+	return cfg.A2AMaxConcurrentUserTasks()
+}
+
+// userParamTaskLimit parses the UserParamMaxConcurrentTasks user param, reporting false
+// if it is not set.
+func userParamTaskLimit(params map[string]string) (int, bool, error) {
+	value := params[UserParamMaxConcurrentTasks]
+	if value == "" {
+		return 0, false, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, false, fmt.Errorf("invalid task concurrency limit '%s'", value)
+	}
+	return limit, true, nil
+}
+
+// trackRunning updates the running task count of the user for a stored task changing
+// from before to after; nil stands for a task that is not stored. The caller must hold s.mu.
+func (s *MemoryTaskStore) trackRunning(userID string, before, after *schema.Task) {
+	wasRunning := before != nil && !before.Status.State.IsFinal()
+	isRunning := after != nil && !after.Status.State.IsFinal()
+	switch {
+	case isRunning && !wasRunning:
+		s.running[userID]++
+	case wasRunning && !isRunning:
+		if s.running[userID]--; s.running[userID] <= 0 {
+			delete(s.running, userID)
+		}
+	}
+}
+
+// insert stores a task whose key is not stored yet. The caller must hold s.mu.
+func (s *MemoryTaskStore) insert(userID string, task *schema.Task) error {
+	session := sessionKey{userID: userID, sessionID: taskSessionID(task)}
+	if tasks := s.sessions[session]; tasks != nil && s.hardLimit > 0 && tasks.Len() >= s.hardLimit {
+		s.evictTerminal(session, tasks.Len()-s.hardLimit+1)
+		if tasks.Len() >= s.hardLimit {
+			return fmt.Errorf("%w: session '%s' holds %d running tasks", ErrSessionTaskLimit, session.sessionID, tasks.Len())
+		}
+	}
+	// Created after the eviction, which drops the list of a session it empties
+	tasks := s.sessions[session]
+	if tasks == nil {
+		tasks = list.New()
+		s.sessions[session] = tasks
+	}
+
+	stored := *task
+	elem := tasks.PushBack(&storedTask{task: &stored, userID: userID})
+	s.tasks[s.storedKey(userID, task)] = elem
+	key := userTaskKey{userID: userID, taskID: task.ID}
+	s.byUser[key] = append(s.byUser[key], elem)
+	s.trackRunning(userID, nil, &stored)
+	s.enforceMaxTasks(session)
+	return nil
+}
+
+// Get returns a copy of the task ID accessed in scope. Reading a task marks it as
+// recently used.
+func (s *MemoryTaskStore) Get(scope TaskScope, taskID string) (*schema.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.lookup(scope, taskID)
+	if !ok {
+		return nil, taskNotFound()
+	}
+	task := elem.Value.(*storedTask).task
+	s.sessions[storedSession(elem)].MoveToBack(elem)
+	stored := *task
+	return &stored, nil
+}
+
+// Update replaces a stored task of the user and marks it as recently used. If the task
+// became terminal, other terminal tasks of its session may be evicted.
+func (s *MemoryTaskStore) Update(userID string, task *schema.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.lookup(TaskScope{UserID: userID, SessionID: taskSessionID(task)}, task.ID)
+	if !ok {
+		return taskNotFound()
+	}
+	sessionID := taskSessionID(elem.Value.(*storedTask).task)
+	if taskSessionID(task) != sessionID {
+		return fmt.Errorf("task '%s' belongs to session '%s'", task.ID, sessionID)
+	}
+	stored := *task
+	s.trackRunning(userID, elem.Value.(*storedTask).task, &stored)
+	elem.Value.(*storedTask).task = &stored
+	s.publish(s.storedKey(userID, &stored), &stored)
+	s.sessions[storedSession(elem)].MoveToBack(elem)
+	s.enforceMaxTasks(storedSession(elem))
+	return nil
+}
+
+// UpdateStatus sets the status of the task ID accessed in scope, stamped with the current
+// time if it has none, and returns a copy of the task. The message of the status, if any,
+// is added to the history of the task. Subscribers receive the new status, and once it
+// is terminal other terminal tasks of the session may be evicted. A task in a terminal
+// state keeps it: updating it fails with ErrTaskTerminal.
+func (s *MemoryTaskStore) UpdateStatus(scope TaskScope, taskID string, status schema.TaskStatus) (*schema.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.lookup(scope, taskID)
+	if !ok {
+		return nil, taskNotFound()
+	}
+	task := elem.Value.(*storedTask).task
+	if task.Status.State.IsFinal() {
+		return nil, fmt.Errorf("%w: task '%s' is %s", ErrTaskTerminal, taskID, task.Status.State)
+	}
+	if status.Timestamp.IsZero() {
+		status.Timestamp = time.Now().UTC()
+	}
+	updated := *task
+	updated.Status = status
+	if status.Message != nil {
+		updated.History = append(append([]schema.Message(nil), task.History...), *status.Message)
+	}
+	s.trackRunning(scope.UserID, task, &updated)
+	elem.Value.(*storedTask).task = &updated
+	s.publish(s.storedKey(scope.UserID, &updated), &updated)
+	s.sessions[storedSession(elem)].MoveToBack(elem)
+	s.enforceMaxTasks(storedSession(elem))
+	result := updated
+	return &result, nil
+}
+
+// AppendArtifact adds an artifact to the task ID accessed in scope and returns a copy of
+// the task. An artifact with Append set extends the parts of the artifact with the same
+// index; otherwise it replaces the artifact with the same index, if any. Subscribers
+// receive the artifact as added.
+func (s *MemoryTaskStore) AppendArtifact(scope TaskScope, taskID string, artifact schema.Artifact) (*schema.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.lookup(scope, taskID)
+	if !ok {
+		return nil, taskNotFound()
+	}
+	task := elem.Value.(*storedTask).task
+	added := artifact
+	updated := *task
+	updated.Artifacts = append([]schema.Artifact(nil), task.Artifacts...) // Copies returned earlier keep theirs
+	replaced := false
+	for i, existing := range updated.Artifacts {
+		if existing.Index != artifact.Index {
+			continue
+		}
+		if artifact.Append != nil && *artifact.Append {
+			parts := append(append([]schema.Part(nil), existing.Parts...), artifact.Parts...)
+			artifact.Parts = parts
+			if artifact.Name == nil {
+				artifact.Name = existing.Name
+			}
+			if artifact.Description == nil {
+				artifact.Description = existing.Description
+			}
+		}
+		updated.Artifacts[i] = artifact
+		replaced = true
+		break
+	}
+	if !replaced {
+		updated.Artifacts = append(updated.Artifacts, artifact)
+	}
+	elem.Value.(*storedTask).task = &updated
+	s.publishArtifact(s.storedKey(scope.UserID, &updated), updated.ID, added)
+	s.sessions[storedSession(elem)].MoveToBack(elem)
+	result := updated
+	return &result, nil
+}
+
+// AppendMessage adds a message sent to the task ID accessed in scope to the history of the
+// task, marks it as recently used and returns a copy of it. A task in a terminal state
+// takes no more messages: appending to it fails with ErrTaskTerminal.
+func (s *MemoryTaskStore) AppendMessage(scope TaskScope, taskID string, message schema.Message) (*schema.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.lookup(scope, taskID)
+	if !ok {
+		return nil, taskNotFound()
+	}
+	task := elem.Value.(*storedTask).task
+	if task.Status.State.IsFinal() {
+		return nil, fmt.Errorf("%w: task '%s' is %s", ErrTaskTerminal, taskID, task.Status.State)
+	}
+	updated := *task
+	updated.History = append(append([]schema.Message(nil), task.History...), message) // Copies returned earlier keep theirs
+	elem.Value.(*storedTask).task = &updated
+	s.sessions[storedSession(elem)].MoveToBack(elem)
+	result := updated
+	return &result, nil
+}
+
+// List returns copies of the tasks the user of scope created in its session, least
+// recently used first. Listing does not mark the tasks as used.
+func (s *MemoryTaskStore) List(scope TaskScope) ([]*schema.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := []*schema.Task{}
+	session := s.sessions[sessionKey{userID: scope.UserID, sessionID: scope.SessionID}]
+	if session == nil {
+		return tasks, nil
+	}
+	for elem := session.Front(); elem != nil; elem = elem.Next() {
+		task := *elem.Value.(*storedTask).task
+		tasks = append(tasks, &task)
+	}
+	return tasks, nil
+}
+
+// Cancel marks the task ID accessed in scope as canceled and returns a copy of it. A task
+// that already reached a terminal state cannot be canceled.
+func (s *MemoryTaskStore) Cancel(scope TaskScope, taskID string) (*schema.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.lookup(scope, taskID)
+	if !ok {
+		return nil, taskNotFound()
+	}
+	task := elem.Value.(*storedTask).task
+	if task.Status.State.IsFinal() {
+		return nil, &schema.TaskNotCancelableError{Code: schema.ErrorTaskNotCancelable, Message: "Task cannot be canceled"}
+	}
+	canceled := *task
+	canceled.Status = schema.TaskStatus{State: schema.TaskStateCanceled, Timestamp: time.Now().UTC()}
+	s.trackRunning(scope.UserID, task, &canceled)
+	elem.Value.(*storedTask).task = &canceled
+	s.publish(s.storedKey(scope.UserID, &canceled), &canceled)
+	s.sessions[storedSession(elem)].MoveToBack(elem)
+	s.enforceMaxTasks(storedSession(elem))
+	result := canceled
+	return &result, nil
+}
+
+// SessionTaskCounts returns the number of stored tasks in every session of the user, by
+// session ID.
+func (s *MemoryTaskStore) SessionTaskCounts(userID string) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int)
+	for session, tasks := range s.sessions {
+		if session.userID == userID {
+			counts[session.sessionID] = tasks.Len()
+		}
+	}
+	return counts
+}
+
+// TaskCounts returns the number of sessions holding tasks and of stored tasks, of all
+// users.
+func (s *MemoryTaskStore) TaskCounts() (sessions int, tasks int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		sessions++
+		tasks += session.Len()
+	}
+	return sessions, tasks
+}
+
+// enforceMaxTasks evicts terminal tasks of the session beyond maxTasks.
+func (s *MemoryTaskStore) enforceMaxTasks(session sessionKey) {
+	if s.maxTasks <= 0 {
+		return
+	}
+	if excess := s.sessions[session].Len() - s.maxTasks; excess > 0 {
+		s.evictTerminal(session, excess)
+	}
+}
+
+// evictTerminal removes up to n terminal tasks of the session, least recently used first.
+func (s *MemoryTaskStore) evictTerminal(session sessionKey, n int) {
+	tasks := s.sessions[session]
+	for elem := tasks.Front(); elem != nil && n > 0; {
+		next := elem.Next()
+		stored := elem.Value.(*storedTask)
+		task := stored.task
+		if task.Status.State.IsFinal() {
+			tasks.Remove(elem)
+			delete(s.tasks, s.storedKey(stored.userID, task))
+			s.forget(elem)
+			n--
+			s.logger.Debug("Evicted terminal task", zap.String("session", session.sessionID), zap.String("task", task.ID), zap.String("state", string(task.Status.State)))
+		}
+		elem = next
+	}
+	s.dropEmptySession(session)
+}
+
+// remove deletes a stored task. The caller must hold s.mu.
+func (s *MemoryTaskStore) remove(elem *list.Element) {
+	stored := elem.Value.(*storedTask)
+	session := storedSession(elem)
+	s.sessions[session].Remove(elem)
+	s.dropEmptySession(session)
+	delete(s.tasks, s.storedKey(stored.userID, stored.task))
+	s.forget(elem)
+	s.trackRunning(stored.userID, stored.task, nil)
+}
+
+// dropEmptySession forgets a session once it holds no task, so that the sessions of
+// departed clients do not pile up. The caller must hold s.mu.
+func (s *MemoryTaskStore) dropEmptySession(session sessionKey) {
+	if tasks := s.sessions[session]; tasks != nil && tasks.Len() == 0 {
+		delete(s.sessions, session)
+	}
+}
+
+// taskNotFound returns the A2A error for an unknown task ID.
+func taskNotFound() *schema.TaskNotFoundError {
+	return &schema.TaskNotFoundError{Code: schema.ErrorTaskNotFound, Message: "Task not found"}
+}
+
+// storedSession returns the session a stored task is grouped under.
+func storedSession(elem *list.Element) sessionKey {
+	stored := elem.Value.(*storedTask)
+	return sessionKey{userID: stored.userID, sessionID: taskSessionID(stored.task)}
+}
+
+// taskSessionID returns the session the task is grouped under.
+func taskSessionID(task *schema.Task) string {
+	if task.SessionID == nil {
+		return ""
+	}
+	return *task.SessionID
+}
@@ -0,0 +1,317 @@
+package a2a_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testUser = "u1"
+
+func newTaskStore(maxTasks, hardLimit int) *a2a.MemoryTaskStore {
+	return newScopedTaskStore("", maxTasks, hardLimit)
+}
+
+func newScopedTaskStore(idScope string, maxTasks, hardLimit int) *a2a.MemoryTaskStore {
+	cfg := config.NewInternalConfig()
+	cfg.SetA2ASessionTaskLimits(maxTasks, hardLimit)
+	cfg.SetA2ATaskIDScope(idScope)
+	return a2a.NewMemoryTaskStore(cfg, zap.NewNop())
+}
+
+// inSession returns the scope of requests of the test user in the session.
+func inSession(sessionID string) a2a.TaskScope {
+	return a2a.TaskScope{UserID: testUser, SessionID: sessionID}
+}
+
+func newSessionTask(id, sessionID string, state a2aSchema.TaskState) *a2aSchema.Task {
+	return &a2aSchema.Task{
+		ID:        id,
+		SessionID: &sessionID,
+		Status:    a2aSchema.TaskStatus{State: state},
+	}
+}
+
+func TestTaskStoreEvictsLeastRecentlyUsedTerminalTasks(t *testing.T) {
+	store := newTaskStore(3, 0)
+
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateCompleted)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t2", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t3", "s1", a2aSchema.TaskStateFailed)))
+	// Reading t1 makes t3 the least recently used terminal task
+	_, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create(testUser, newSessionTask("t4", "s1", a2aSchema.TaskStateSubmitted)))
+
+	_, err = store.Get(inSession("s1"), "t3")
+	var notFound *a2aSchema.TaskNotFoundError
+	assert.ErrorAs(t, err, &notFound, "least recently used terminal task should be evicted")
+	_, err = store.Get(a2a.TaskScope{UserID: testUser, AnySession: true}, "t3")
+	assert.ErrorAs(t, err, &notFound, "evicted task should not be found in any session")
+	for _, id := range []string{"t1", "t2", "t4"} {
+		_, err := store.Get(inSession("s1"), id)
+		assert.NoError(t, err, "task %s should be kept", id)
+	}
+	assert.Equal(t, map[string]int{"s1": 3}, store.SessionTaskCounts(testUser))
+}
+
+func TestTaskStoreNeverEvictsRunningTasks(t *testing.T) {
+	store := newTaskStore(2, 0)
+
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, store.Create(testUser, newSessionTask(fmt.Sprintf("t%d", i), "s1", a2aSchema.TaskStateWorking)))
+	}
+	assert.Equal(t, map[string]int{"s1": 4}, store.SessionTaskCounts(testUser), "running tasks may exceed the soft cap")
+
+	// Completing a task makes it evictable
+	require.NoError(t, store.Update(testUser, newSessionTask("t2", "s1", a2aSchema.TaskStateCompleted)))
+	assert.Equal(t, map[string]int{"s1": 3}, store.SessionTaskCounts(testUser))
+	_, err := store.Get(inSession("s1"), "t2")
+	assert.Error(t, err)
+}
+
+func TestTaskStoreRejectsTasksAtHardLimit(t *testing.T) {
+	store := newTaskStore(0, 2)
+
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t2", "s1", a2aSchema.TaskStateInputRequired)))
+
+	err := store.Create(testUser, newSessionTask("t3", "s1", a2aSchema.TaskStateSubmitted))
+	require.ErrorIs(t, err, a2a.ErrSessionTaskLimit)
+	assert.Contains(t, err.Error(), "s1")
+
+	// Other sessions are not affected
+	require.NoError(t, store.Create(testUser, newSessionTask("t4", "s2", a2aSchema.TaskStateSubmitted)))
+
+	// A terminal task makes room for a new one
+	require.NoError(t, store.Update(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateCanceled)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t3", "s1", a2aSchema.TaskStateSubmitted)))
+	_, err = store.Get(inSession("s1"), "t1")
+	assert.Error(t, err, "terminal task should be evicted to admit the new one")
+	assert.Equal(t, map[string]int{"s1": 2, "s2": 1}, store.SessionTaskCounts(testUser))
+}
+
+func TestTaskStoreKeepsNewTaskWhenEvictionEmptiesSession(t *testing.T) {
+	store := newTaskStore(0, 1)
+
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateCompleted)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t2", "s1", a2aSchema.TaskStateSubmitted)))
+
+	tasks, err := store.List(inSession("s1"))
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "t2", tasks[0].ID)
+	assert.Equal(t, map[string]int{"s1": 1}, store.SessionTaskCounts(testUser))
+}
+
+func TestTaskStoreSeparatesSessionsOfUsers(t *testing.T) {
+	store := newTaskStore(1, 1)
+
+	// Another user sending the same session ID, or none, does not fill the session
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create("u2", newSessionTask("t2", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create(testUser, &a2aSchema.Task{ID: "t3", Status: a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking}}))
+	require.NoError(t, store.Create("u2", &a2aSchema.Task{ID: "t4", Status: a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking}}))
+	require.ErrorIs(t, store.Create(testUser, newSessionTask("t5", "s1", a2aSchema.TaskStateSubmitted)), a2a.ErrSessionTaskLimit)
+
+	assert.Equal(t, map[string]int{"s1": 1, "": 1}, store.SessionTaskCounts(testUser))
+	assert.Equal(t, map[string]int{"s1": 1, "": 1}, store.SessionTaskCounts("u2"))
+	tasks, err := store.List(a2a.TaskScope{UserID: "u2", SessionID: "s1"})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "t2", tasks[0].ID)
+	sessions, count := store.TaskCounts()
+	assert.Equal(t, 4, sessions)
+	assert.Equal(t, 4, count)
+}
+
+func TestTaskStoreLimitsConcurrentTasksPerUser(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	cfg.SetA2AMaxConcurrentUserTasks(2)
+	cfg.SetUserParam("vip", a2a.UserParamMaxConcurrentTasks, "3")
+	store := a2a.NewMemoryTaskStore(cfg, zap.NewNop())
+
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t2", "s2", a2aSchema.TaskStateSubmitted)))
+
+	err := store.Create(testUser, newSessionTask("t3", "s1", a2aSchema.TaskStateSubmitted))
+	require.ErrorIs(t, err, a2a.ErrUserTaskLimit)
+	assert.Contains(t, err.Error(), "2 of 2 tasks running")
+
+	// Other users have their own count and limit
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, store.Create("vip", newSessionTask(fmt.Sprintf("v%d", i), "s3", a2aSchema.TaskStateWorking)))
+	}
+	require.ErrorIs(t, store.Create("vip", newSessionTask("v4", "s3", a2aSchema.TaskStateWorking)), a2a.ErrUserTaskLimit)
+
+	// Completing or canceling a task frees a slot
+	require.NoError(t, store.Update(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateCompleted)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t3", "s1", a2aSchema.TaskStateSubmitted)))
+	_, err = store.Cancel(inSession("s2"), "t2")
+	require.NoError(t, err)
+	require.NoError(t, store.Create(testUser, newSessionTask("t4", "s2", a2aSchema.TaskStateSubmitted)))
+	require.ErrorIs(t, store.Create(testUser, newSessionTask("t5", "s2", a2aSchema.TaskStateSubmitted)), a2a.ErrUserTaskLimit)
+}
+
+func TestTaskStoreUnlimitedByDefault(t *testing.T) {
+	store := a2a.NewMemoryTaskStore(config.NewInternalConfig(), zap.NewNop())
+
+	for i := 0; i < 50; i++ {
+		require.NoError(t, store.Create(testUser, newSessionTask(fmt.Sprintf("t%d", i), "s1", a2aSchema.TaskStateCompleted)))
+	}
+	assert.Equal(t, map[string]int{"s1": 50}, store.SessionTaskCounts(testUser))
+	assert.Error(t, store.Create(testUser, newSessionTask("t0", "s1", a2aSchema.TaskStateSubmitted)), "duplicate task ID should be rejected")
+}
+
+func TestTaskStoreScopesIDsPerSessionByDefault(t *testing.T) {
+	store := newTaskStore(0, 0)
+
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s2", a2aSchema.TaskStateCompleted)), "same ID in another session is another task")
+
+	first, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateWorking, first.Status.State)
+	second, err := store.Get(inSession("s2"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCompleted, second.Status.State)
+
+	canceled, err := store.Cancel(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateCanceled, canceled.Status.State)
+	_, err = store.Cancel(inSession("s2"), "t1")
+	var notCancelable *a2aSchema.TaskNotCancelableError
+	assert.ErrorAs(t, err, &notCancelable, "completed task of the other session must not be canceled")
+	assert.Equal(t, map[string]int{"s1": 1, "s2": 1}, store.SessionTaskCounts(testUser))
+}
+
+func TestTaskStoreDeniesAccessToTasksOfOtherUsers(t *testing.T) {
+	for _, idScope := range []string{config.A2ATaskIDScopeSession, config.A2ATaskIDScopeUser, config.A2ATaskIDScopeGlobal} {
+		t.Run(idScope, func(t *testing.T) {
+			store := newScopedTaskStore(idScope, 0, 0)
+			require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+			other := a2a.TaskScope{UserID: "u2", SessionID: "s1"}
+
+			var notFound *a2aSchema.TaskNotFoundError
+			_, err := store.Get(other, "t1")
+			assert.ErrorAs(t, err, &notFound)
+			_, err = store.Cancel(other, "t1")
+			assert.ErrorAs(t, err, &notFound)
+			assert.ErrorAs(t, store.Update("u2", newSessionTask("t1", "s1", a2aSchema.TaskStateCompleted)), &notFound)
+			_, err = store.ExportTask(other, "t1")
+			assert.ErrorAs(t, err, &notFound)
+
+			task, err := store.Get(inSession("s1"), "t1")
+			require.NoError(t, err)
+			assert.Equal(t, a2aSchema.TaskStateWorking, task.Status.State, "task must be unchanged by the other user")
+		})
+	}
+}
+
+func TestTaskStoreIDScopes(t *testing.T) {
+	// Per user: the same ID in another session of the user is the same task
+	store := newScopedTaskStore(config.A2ATaskIDScopeUser, 0, 0)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	assert.ErrorIs(t, store.Create(testUser, newSessionTask("t1", "s2", a2aSchema.TaskStateWorking)), a2a.ErrTaskExists)
+	_, err := store.Get(inSession("s2"), "t1")
+	assert.NoError(t, err)
+	require.NoError(t, store.Create("u2", newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)), "other users have their own IDs")
+
+	// Global: any stored task with the ID rejects a new one
+	store = newScopedTaskStore(config.A2ATaskIDScopeGlobal, 0, 0)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	assert.ErrorIs(t, store.Create("u2", newSessionTask("t1", "s2", a2aSchema.TaskStateWorking)), a2a.ErrTaskExists)
+}
+
+func TestTaskStoreConcurrentStatusUpdatesAndArtifactAppends(t *testing.T) {
+	store := newTaskStore(0, 0)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateSubmitted)))
+	before, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+
+	const writers = 8
+	const chunks = 25
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < chunks; i++ {
+				_, err := store.AppendArtifact(inSession("s1"), "t1", a2aSchema.Artifact{
+					Index:  w,
+					Append: testutil.PointerTo(true),
+					Parts:  []a2aSchema.Part{testutil.NewTextPart(fmt.Sprintf("%d-%d", w, i))},
+				})
+				assert.NoError(t, err)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < chunks; i++ {
+				_, err := store.UpdateStatus(inSession("s1"), "t1", a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	task, err := store.Get(inSession("s1"), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, a2aSchema.TaskStateWorking, task.Status.State)
+	assert.False(t, task.Status.Timestamp.IsZero(), "Updates without a timestamp should be stamped")
+	require.Len(t, task.Artifacts, writers)
+	for _, artifact := range task.Artifacts {
+		require.Len(t, artifact.Parts, chunks, "No chunk of artifact %d should be lost", artifact.Index)
+		for i, part := range artifact.Parts {
+			assert.Equal(t, testutil.NewTextPart(fmt.Sprintf("%d-%d", artifact.Index, i)), part, "Chunks should keep their order")
+		}
+	}
+	assert.Empty(t, before.Artifacts, "Copies returned earlier must not change")
+
+	// A terminal task keeps its status
+	done := testutil.NewTextMessage("agent", "done")
+	task, err = store.UpdateStatus(inSession("s1"), "t1", a2aSchema.TaskStatus{State: a2aSchema.TaskStateCompleted, Message: &done})
+	require.NoError(t, err)
+	assert.Equal(t, []a2aSchema.Message{done}, task.History, "Status messages should be added to the history")
+	_, err = store.UpdateStatus(inSession("s1"), "t1", a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking})
+	assert.ErrorIs(t, err, a2a.ErrTaskTerminal)
+
+	// Replacing an artifact without Append
+	task, err = store.AppendArtifact(inSession("s1"), "t1", a2aSchema.Artifact{Index: 0, Parts: []a2aSchema.Part{testutil.NewTextPart("replaced")}})
+	require.NoError(t, err)
+	require.Len(t, task.Artifacts, writers)
+	for _, artifact := range task.Artifacts {
+		if artifact.Index == 0 {
+			assert.Len(t, artifact.Parts, 1)
+		}
+	}
+}
+
+func TestTaskStoreListsTasksOfUserInSession(t *testing.T) {
+	store := newTaskStore(0, 0)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t2", "s1", a2aSchema.TaskStateCompleted)))
+	require.NoError(t, store.Create(testUser, newSessionTask("t3", "s2", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Create("u2", newSessionTask("t4", "s1", a2aSchema.TaskStateWorking)))
+
+	tasks, err := store.List(inSession("s1"))
+	require.NoError(t, err)
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"t1", "t2"}, ids)
+
+	tasks, err = store.List(inSession("none"))
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
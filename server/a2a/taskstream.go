@@ -0,0 +1,176 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// taskEventBuffer is the number of updates buffered per subscriber. When a slow
+// subscriber's buffer is full, intermediate updates are dropped; the final one never is.
+// The artifacts of a task can always be read back with tasks/get.
+const taskEventBuffer = 16
+
+// TaskUpdate is an update of a stored task streamed to its subscribers: exactly one of
+// Status and Artifact is set.
+type TaskUpdate struct {
+	Status   *schema.TaskStatusUpdateEvent
+	Artifact *schema.TaskArtifactUpdateEvent
+}
+
+// Final reports whether the update is the final status update of the task.
+func (u TaskUpdate) Final() bool {
+	return u.Status != nil && u.Status.Final
+}
+
+// taskSubscriber receives the updates of a stored task.
+type taskSubscriber struct {
+	events chan TaskUpdate
+	closed bool
+}
+
+// Subscribe streams the status updates of the task ID accessed in scope, as made by
+// Update, UpdateStatus and Cancel, and the artifacts added by AppendArtifact. When the
+// task reaches a terminal state, e.g. because it was canceled, a final update is
+// delivered and the channel is closed. The returned function ends the subscription early;
+// it must be called once the caller stops reading.
+func (s *MemoryTaskStore) Subscribe(scope TaskScope, taskID string) (<-chan TaskUpdate, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.lookup(scope, taskID)
+	if !ok {
+		return nil, nil, taskNotFound()
+	}
+	stored := elem.Value.(*storedTask)
+	key := s.storedKey(stored.userID, stored.task)
+	sub := &taskSubscriber{events: make(chan TaskUpdate, taskEventBuffer)}
+	if stored.task.Status.State.IsFinal() {
+		sub.events <- TaskUpdate{Status: &schema.TaskStatusUpdateEvent{ID: taskID, Status: stored.task.Status, Final: true}}
+		close(sub.events)
+		return sub.events, func() {}, nil
+	}
+	s.subscribers[key] = append(s.subscribers[key], sub)
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		subs := s.subscribers[key]
+		for i, other := range subs {
+			if other == sub {
+				s.subscribers[key] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+		if len(s.subscribers[key]) == 0 {
+			delete(s.subscribers, key)
+		}
+		if !sub.closed {
+			sub.closed = true
+			close(sub.events)
+		}
+	}
+	return sub.events, unsubscribe, nil
+}
+
+// publish sends the status of a stored task to its subscribers, and closes their
+// channels after a terminal status. The caller must hold s.mu.
+func (s *MemoryTaskStore) publish(key taskKey, task *schema.Task) {
+	subs := s.subscribers[key]
+	if len(subs) == 0 {
+		return
+	}
+	final := task.Status.State.IsFinal()
+	event := TaskUpdate{Status: &schema.TaskStatusUpdateEvent{ID: task.ID, Status: task.Status, Final: final}}
+	for _, sub := range subs {
+		select {
+		case sub.events <- event:
+		default:
+			if !final {
+				continue // Slow subscriber, it will see a later update
+			}
+			select {
+			case <-sub.events: // Make room for the final update
+			default:
+			}
+			sub.events <- event
+		}
+		if final {
+			sub.closed = true
+			close(sub.events)
+		}
+	}
+	if final {
+		delete(s.subscribers, key)
+	}
+}
+
+// publishArtifact sends an artifact added to a stored task to its subscribers, dropping
+// it for slow subscribers. The caller must hold s.mu.
+func (s *MemoryTaskStore) publishArtifact(key taskKey, taskID string, artifact schema.Artifact) {
+	event := TaskUpdate{Artifact: &schema.TaskArtifactUpdateEvent{ID: taskID, Artifact: artifact}}
+	for _, sub := range s.subscribers[key] {
+		select {
+		case sub.events <- event:
+		default: // Slow subscriber
+		}
+	}
+}
+
+// WriteTaskEvents writes the updates of a subscription as the event stream of a
+// tasks/sendSubscribe or tasks/resubscribe request with the JSON-RPC ID id. It returns
+// after the final update, or when ctx is done.
+func WriteTaskEvents(ctx context.Context, w http.ResponseWriter, id any, events <-chan TaskUpdate) error {
+	return writeTaskEvents(ctx, w, id, events, nil)
+}
+
+// writeTaskEvents is WriteTaskEvents writing each update as returned by prepare, if not nil.
+func writeTaskEvents(ctx context.Context, w http.ResponseWriter, id any, events <-chan TaskUpdate, prepare func(TaskUpdate) TaskUpdate) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("response writer does not support streaming")
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if prepare != nil {
+				event = prepare(event)
+			}
+			var result []byte
+			var err error
+			if event.Artifact != nil {
+				result, err = json.Marshal(event.Artifact)
+			} else {
+				result, err = json.Marshal(event.Status)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to encode task event: %w", err)
+			}
+			raw := json.RawMessage(result)
+			data, err := json.Marshal(schema.JSONRPCResponse{JSONRPC: schema.JSONRPCVersion, Result: &raw, ID: &id})
+			if err != nil {
+				return fmt.Errorf("failed to encode task event: %w", err)
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return err
+			}
+			flusher.Flush()
+			if event.Final() {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package a2a_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/a2a"
+	a2aClient "github.com/gate4ai/mcp/shared/a2a/2025-draft/client"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveStreamingAgent answers tasks/sendSubscribe by streaming the task from the store
+// and tasks/cancel by canceling it there.
+func serveStreamingAgent(t *testing.T, store *a2a.MemoryTaskStore) *httptest.Server {
+	t.Helper()
+	scope := inSession("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                 `json:"method"`
+			Params a2aSchema.TaskIdParams `json:"params"`
+			ID     any                    `json:"id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.Method {
+		case "tasks/sendSubscribe":
+			require.NoError(t, store.Create(testUser, newSessionTask(req.Params.ID, "", a2aSchema.TaskStateWorking)))
+			events, unsubscribe, err := store.Subscribe(scope, req.Params.ID)
+			require.NoError(t, err)
+			defer unsubscribe()
+			_ = a2a.WriteTaskEvents(r.Context(), w, req.ID, events)
+		case "tasks/cancel":
+			task, err := store.Cancel(scope, req.Params.ID)
+			require.NoError(t, err)
+			result, _ := json.Marshal(task)
+			raw := json.RawMessage(result)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(a2aSchema.JSONRPCResponse{JSONRPC: a2aSchema.JSONRPCVersion, Result: &raw, ID: &req.ID})
+		default:
+			http.Error(w, "unknown method", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCancelDeliversFinalEventOnSubscription(t *testing.T) {
+	server := serveStreamingAgent(t, newTaskStore(0, 0))
+	client, err := a2aClient.New(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	events, err := client.SendTaskSubscribe(context.Background(), &a2aSchema.TaskSendParams{ID: "t1"})
+	require.NoError(t, err)
+	_, err = client.CancelTask(context.Background(), "t1")
+	require.NoError(t, err)
+
+	var received []a2aClient.TaskEvent
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				done = true
+				break
+			}
+			received = append(received, event)
+		case <-timeout:
+			t.Fatal("Subscription was not closed after cancellation")
+		}
+	}
+	require.NotEmpty(t, received)
+	last := received[len(received)-1]
+	require.NoError(t, last.Err)
+	require.NotNil(t, last.Status, "the last event should be a status update")
+	assert.Equal(t, a2aSchema.TaskStateCanceled, last.Status.Status.State)
+	assert.True(t, last.Status.Final)
+}
+
+func TestSubscribeDeliversUpdatesUntilTerminalState(t *testing.T) {
+	store := newTaskStore(0, 0)
+	require.NoError(t, store.Create(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateSubmitted)))
+	events, unsubscribe, err := store.Subscribe(inSession("s1"), "t1")
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	require.NoError(t, store.Update(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateWorking)))
+	require.NoError(t, store.Update(testUser, newSessionTask("t1", "s1", a2aSchema.TaskStateCompleted)))
+
+	var states []a2aSchema.TaskState
+	for event := range events {
+		states = append(states, event.Status.Status.State)
+	}
+	assert.Equal(t, []a2aSchema.TaskState{a2aSchema.TaskStateWorking, a2aSchema.TaskStateCompleted}, states)
+
+	// A terminal task yields its final status at once
+	events, _, err = store.Subscribe(inSession("s1"), "t1")
+	require.NoError(t, err)
+	event := <-events
+	assert.True(t, event.Final())
+	_, open := <-events
+	assert.False(t, open)
+}
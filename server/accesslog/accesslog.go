@@ -0,0 +1,153 @@
+// Package accesslog writes the access log of a server: one structured entry per request
+// answered to a client and per SSE stream when it closes, to correlate the activity of
+// clients. Request parameters and results are never logged.
+package accesslog
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logger writes access log entries to a zap logger. Whether they are written, their level
+// and their fields are read from the configuration on each entry, so changes apply at
+// once. It implements shared.RequestLogger and transport.StreamLogger.
+type Logger struct {
+	config config.IConfig
+	logger *zap.Logger
+}
+
+// New creates an access log written to logger, e.g. one capturing the entries in tests.
+func New(cfg config.IConfig, logger *zap.Logger) *Logger {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Logger{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// LogRequest writes the entry of a request answered to a client.
+func (l *Logger) LogRequest(msg *shared.Message, duration time.Duration, err error, responseBytes int) {
+	level, fields, ok := l.settings()
+	if !ok {
+		return
+	}
+	entry := l.logger.Check(level, "Request answered")
+	if entry == nil {
+		return
+	}
+
+	var logged []zap.Field
+	logged = appendSessionFields(logged, fields, msg.Session)
+	if slices.Contains(fields, config.AccessLogFieldMethod) {
+		logged = append(logged, zap.String("method", shared.NilIfNil(msg.Method)))
+	}
+	if backends := msg.Backends(); len(backends) > 0 && slices.Contains(fields, config.AccessLogFieldBackend) {
+		logged = append(logged, zap.Strings("backend", backends))
+	}
+	if slices.Contains(fields, config.AccessLogFieldDuration) {
+		logged = append(logged, zap.Duration("duration", duration))
+	}
+	if slices.Contains(fields, config.AccessLogFieldCode) {
+		logged = append(logged, zap.Int("code", errorCode(err)))
+	}
+	if slices.Contains(fields, config.AccessLogFieldBytes) {
+		logged = append(logged, zap.Int("bytes", responseBytes))
+	}
+	entry.Write(logged...)
+}
+
+// LogStream writes the entry of an SSE stream to a client when it closes.
+func (l *Logger) LogStream(session shared.ISession, stream transport.StreamStats) {
+	level, fields, ok := l.settings()
+	if !ok {
+		return
+	}
+	entry := l.logger.Check(level, "SSE stream closed")
+	if entry == nil {
+		return
+	}
+
+	logged := []zap.Field{zap.String("path", stream.Path)}
+	logged = appendSessionFields(logged, fields, session)
+	if slices.Contains(fields, config.AccessLogFieldDuration) {
+		logged = append(logged, zap.Duration("duration", stream.Duration))
+	}
+	if slices.Contains(fields, config.AccessLogFieldEvents) {
+		logged = append(logged, zap.Int("events", stream.Events))
+	}
+	if slices.Contains(fields, config.AccessLogFieldBytes) {
+		logged = append(logged, zap.Int64("bytes", stream.Bytes))
+	}
+	entry.Write(logged...)
+}
+
+// settings returns the level and fields of the entries, and false if the access log is
+// disabled.
+func (l *Logger) settings() (zapcore.Level, []string, bool) {
+	enabled, err := l.config.AccessLogEnabled()
+	if err != nil {
+		l.logger.Error("Failed to get access log enabled from config, not logging", zap.Error(err))
+		return 0, nil, false
+	}
+	if !enabled {
+		return 0, nil, false
+	}
+
+	level := zapcore.InfoLevel
+	levelName, err := l.config.AccessLogLevel()
+	if err != nil {
+		l.logger.Error("Failed to get access log level from config, using the default", zap.Error(err))
+		levelName = ""
+	}
+	if levelName == "" {
+		levelName = config.DefaultAccessLogLevel
+	}
+	if parsed, err := zapcore.ParseLevel(levelName); err == nil {
+		level = parsed
+	}
+
+	fields, err := l.config.AccessLogFields()
+	if err != nil {
+		l.logger.Error("Failed to get access log fields from config, logging all", zap.Error(err))
+		fields = nil
+	}
+	if len(fields) == 0 {
+		fields = config.AccessLogFields
+	}
+	return level, fields, true
+}
+
+// appendSessionFields appends the user and session ID of the session, if they are
+// logged. Unauthenticated sessions have no user.
+func appendSessionFields(logged []zap.Field, fields []string, session shared.ISession) []zap.Field {
+	if session == nil {
+		return logged
+	}
+	if userID := transport.GetUserId(session.GetParams()); userID != "" && slices.Contains(fields, config.AccessLogFieldUser) {
+		logged = append(logged, zap.String("userID", userID))
+	}
+	if slices.Contains(fields, config.AccessLogFieldSession) {
+		logged = append(logged, zap.String("sessionID", session.GetID()))
+	}
+	return logged
+}
+
+// errorCode returns the JSON-RPC error code a request was answered with, as sent by
+// shared.BaseSession.SendResponse, or 0 on success.
+func errorCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if rpcErr, ok := err.(*shared.JSONRPCError); ok {
+		return rpcErr.Code
+	}
+	return shared.JSONRPCErrorInternal
+}
@@ -0,0 +1,112 @@
+package accesslog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestLogger(t *testing.T, enabled bool, level string, fields ...string) (*Logger, *observer.ObservedLogs) {
+	t.Helper()
+	cfg := config.NewInternalConfig()
+	if err := cfg.SetAccessLog(enabled, level, fields); err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	return New(cfg, zap.New(core)), logs
+}
+
+func newRequest(userID string) *shared.Message {
+	session := shared.NewBaseSession(zap.NewNop(), nil, nil)
+	if userID != "" {
+		transport.SaveUserId(session.GetParams(), userID)
+	}
+	method := "tools/call"
+	id := schema.RequestID{}
+	return &shared.Message{ID: &id, Method: &method, Session: session}
+}
+
+func TestRequestEntry(t *testing.T) {
+	l, logs := newTestLogger(t, true, "")
+	msg := newRequest("alice")
+
+	l.LogRequest(msg, 1500*time.Millisecond, &shared.JSONRPCError{Code: -32602, Message: "bad"}, 42)
+	l.LogRequest(newRequest(""), time.Millisecond, errors.New("failed"), 0)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Level != zapcore.InfoLevel {
+		t.Errorf("Expected the default level info, got %v", entries[0].Level)
+	}
+	fields := entries[0].ContextMap()
+	want := map[string]interface{}{
+		"userID":    "alice",
+		"sessionID": msg.Session.GetID(),
+		"method":    "tools/call",
+		"duration":  1500 * time.Millisecond,
+		"code":      int64(-32602),
+		"bytes":     int64(42),
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("Field %s = %v, want %v", key, fields[key], value)
+		}
+	}
+	if _, ok := fields["backend"]; ok {
+		t.Errorf("Expected no backend for a request not sent to any")
+	}
+
+	fields = entries[1].ContextMap()
+	if _, ok := fields["userID"]; ok {
+		t.Errorf("Expected no user for an unauthenticated session, got %v", fields["userID"])
+	}
+	if fields["code"] != int64(shared.JSONRPCErrorInternal) {
+		t.Errorf("Expected other errors to be logged as internal errors, got %v", fields["code"])
+	}
+}
+
+func TestConfiguredLevelAndFields(t *testing.T) {
+	l, logs := newTestLogger(t, true, "debug", config.AccessLogFieldMethod, config.AccessLogFieldEvents)
+	msg := newRequest("alice")
+
+	l.LogRequest(msg, time.Second, nil, 10)
+	l.LogStream(msg.Session, transport.StreamStats{Path: "/sse", Duration: time.Minute, Events: 7, Bytes: 512})
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Level != zapcore.DebugLevel {
+			t.Errorf("Expected the configured level debug, got %v", entry.Level)
+		}
+	}
+	if fields := entries[0].ContextMap(); len(fields) != 1 || fields["method"] != "tools/call" {
+		t.Errorf("Expected only the method of the request, got %v", fields)
+	}
+	if fields := entries[1].ContextMap(); len(fields) != 2 || fields["path"] != "/sse" || fields["events"] != int64(7) {
+		t.Errorf("Expected only the path and events of the stream, got %v", fields)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	l, logs := newTestLogger(t, false, "")
+	msg := newRequest("alice")
+
+	l.LogRequest(msg, time.Second, nil, 10)
+	l.LogStream(msg.Session, transport.StreamStats{Path: "/sse"})
+
+	if n := logs.Len(); n != 0 {
+		t.Fatalf("Expected no entries while the access log is disabled, got %d", n)
+	}
+}
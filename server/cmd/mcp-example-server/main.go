@@ -57,10 +57,13 @@ func main() {
 		zap.String("address", add),
 		zap.String("config", *configPath))
 
-	if err := server.StartExample(ctx, logger, cfg, ""); err != nil {
+	// Closed once the open streams are drained after the shutdown signal
+	stopped := make(chan struct{})
+	if err := server.StartExample(ctx, logger, cfg, "", server.WithStopped(stopped)); err != nil {
 		logger.Fatal("Failed to start server", zap.Error(err))
 	}
 
 	<-ctx.Done()
+	<-stopped
 	logger.Info("Server stopped")
 }
@@ -0,0 +1,142 @@
+package extra
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gate4ai/mcp/shared"
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/jsonschema"
+	mcpSchema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+// Protocols of the described methods
+const (
+	ProtocolMCP = "mcp"
+	ProtocolA2A = "a2a"
+)
+
+// MethodSchema describes the params and result of a JSON-RPC method.
+// A nil Result means the method only acknowledges the request with an empty result.
+type MethodSchema struct {
+	Protocol  string
+	Method    string
+	Params    any // Zero value of the params type
+	Result    any // Zero value of the result type
+	Streaming bool
+	Events    []any // Zero values of the streamed event types, for streaming methods
+}
+
+// ErrorSchema describes a JSON-RPC error code returned by the methods.
+type ErrorSchema struct {
+	Protocol string `json:"protocol,omitempty"` // Empty for JSON-RPC errors shared by the protocols
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+}
+
+// Methods lists the MCP and A2A methods served through the gateway.
+var Methods = []MethodSchema{
+	{Protocol: ProtocolMCP, Method: "initialize", Params: mcpSchema.InitializeRequestParams{}, Result: mcpSchema.InitializeResult{}},
+	{Protocol: ProtocolMCP, Method: "ping"},
+	{Protocol: ProtocolMCP, Method: "tools/list", Params: mcpSchema.ListToolsRequestParams{}, Result: mcpSchema.ListToolsResult{}},
+	{Protocol: ProtocolMCP, Method: "tools/call", Params: mcpSchema.CallToolRequestParams{}, Result: mcpSchema.CallToolResult{}},
+	{Protocol: ProtocolMCP, Method: "prompts/list", Params: mcpSchema.ListPromptsRequestParams{}, Result: mcpSchema.ListPromptsResult{}},
+	{Protocol: ProtocolMCP, Method: "prompts/get", Params: mcpSchema.GetPromptRequestParams{}, Result: mcpSchema.GetPromptResult{}},
+	{Protocol: ProtocolMCP, Method: "resources/list", Params: mcpSchema.ListResourcesRequestParams{}, Result: mcpSchema.ListResourcesResult{}},
+	{Protocol: ProtocolMCP, Method: "resources/templates/list", Params: mcpSchema.ListResourceTemplatesRequestParams{}, Result: mcpSchema.ListResourceTemplatesResult{}},
+	{Protocol: ProtocolMCP, Method: "resources/read", Params: mcpSchema.ReadResourceRequestParams{}, Result: mcpSchema.ReadResourceResult{}},
+	{Protocol: ProtocolMCP, Method: "resources/subscribe", Params: mcpSchema.SubscribeRequestParams{}},
+	{Protocol: ProtocolMCP, Method: "resources/unsubscribe", Params: mcpSchema.UnsubscribeRequestParams{}},
+	{Protocol: ProtocolMCP, Method: "completion/complete", Params: mcpSchema.CompletionRequestParams{}, Result: mcpSchema.CompleteResult{}},
+	{Protocol: ProtocolMCP, Method: "logging/setLevel", Params: mcpSchema.SetLevelRequestParams{}},
+
+	{Protocol: ProtocolA2A, Method: "tasks/send", Params: a2aSchema.TaskSendParams{}, Result: a2aSchema.Task{}},
+	{Protocol: ProtocolA2A, Method: "tasks/sendSubscribe", Params: a2aSchema.TaskSendParams{}, Streaming: true,
+		Events: []any{a2aSchema.TaskStatusUpdateEvent{}, a2aSchema.TaskArtifactUpdateEvent{}}},
+	{Protocol: ProtocolA2A, Method: "tasks/resubscribe", Params: a2aSchema.TaskQueryParams{}, Streaming: true,
+		Events: []any{a2aSchema.TaskStatusUpdateEvent{}, a2aSchema.TaskArtifactUpdateEvent{}}},
+	{Protocol: ProtocolA2A, Method: "tasks/get", Params: a2aSchema.TaskQueryParams{}, Result: a2aSchema.Task{}},
+	{Protocol: ProtocolA2A, Method: "tasks/cancel", Params: a2aSchema.TaskIdParams{}, Result: a2aSchema.Task{}},
+	{Protocol: ProtocolA2A, Method: "tasks/pushNotification/set", Params: a2aSchema.TaskPushNotificationConfig{}, Result: a2aSchema.TaskPushNotificationConfig{}},
+	{Protocol: ProtocolA2A, Method: "tasks/pushNotification/get", Params: a2aSchema.TaskIdParams{}, Result: a2aSchema.TaskPushNotificationConfig{}},
+}
+
+// Errors lists the JSON-RPC error codes returned by the methods.
+var Errors = []ErrorSchema{
+	{Code: shared.JSONRPCErrorParseError, Message: "Invalid JSON payload"},
+	{Code: shared.JSONRPCErrorInvalidRequest, Message: "Request payload validation error"},
+	{Code: shared.JSONRPCErrorMethodNotFound, Message: "Method not found"},
+	{Code: shared.JSONRPCErrorInvalidParams, Message: "Invalid parameters"},
+	{Code: shared.JSONRPCErrorInternal, Message: "Internal error"},
+	{Code: shared.JSONRPCErrorServerError, Message: "Server error"},
+	{Protocol: ProtocolA2A, Code: a2aSchema.ErrorTaskNotFound, Message: "Task not found"},
+	{Protocol: ProtocolA2A, Code: a2aSchema.ErrorTaskNotCancelable, Message: "Task cannot be canceled"},
+	{Protocol: ProtocolA2A, Code: a2aSchema.ErrorPushNotificationNotSupported, Message: "Push Notification is not supported"},
+	{Protocol: ProtocolA2A, Code: a2aSchema.ErrorUnsupportedOperation, Message: "This operation is not supported"},
+	{Protocol: ProtocolA2A, Code: a2aSchema.ErrorContentTypeNotSupported, Message: "Incompatible content types"},
+}
+
+// MethodsSchema builds a JSON Schema document describing methods and errors. Each method
+// is listed under "methods" with the schemas of its params and result, the type
+// definitions they reference are under "$defs".
+func MethodsSchema(methods []MethodSchema, errors []ErrorSchema) jsonschema.Schema {
+	reflector := jsonschema.NewReflector()
+	describe := func(v any) jsonschema.Schema {
+		return reflector.Reflect(reflect.TypeOf(v))
+	}
+
+	described := make(map[string]jsonschema.Schema, len(methods))
+	for _, m := range methods {
+		method := jsonschema.Schema{"protocol": m.Protocol}
+		if m.Params != nil {
+			method["params"] = describe(m.Params)
+		}
+		switch {
+		case m.Streaming:
+			events := make([]jsonschema.Schema, 0, len(m.Events))
+			for _, event := range m.Events {
+				events = append(events, describe(event))
+			}
+			method["streaming"] = true
+			method["result"] = jsonschema.Schema{"oneOf": events}
+		case m.Result != nil:
+			method["result"] = describe(m.Result)
+		default:
+			method["result"] = jsonschema.Schema{"type": "object"}
+		}
+		described[m.Method] = method
+	}
+
+	return jsonschema.Schema{
+		"$schema": jsonschema.Draft,
+		"title":   "gate4ai methods",
+		"methods": described,
+		"errors":  errors,
+		"$defs":   reflector.Defs(),
+	}
+}
+
+// SchemaHandler creates an HTTP handler serving the JSON Schema of the supported methods
+// and their error codes.
+func SchemaHandler(logger *zap.Logger) http.HandlerFunc {
+	var (
+		once sync.Once
+		body []byte
+		err  error
+	)
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			body, err = json.MarshalIndent(MethodsSchema(Methods, Errors), "", "  ")
+		})
+		if err != nil {
+			logger.Error("Failed to encode methods schema", zap.Error(err))
+			http.Error(w, "failed to encode schema", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Write(body)
+	}
+}
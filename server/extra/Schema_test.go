@@ -0,0 +1,51 @@
+package extra_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gate4ai/mcp/server/extra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSchemaHandlerDescribesMethodsAndErrors(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	extra.SchemaHandler(zap.NewNop())(recorder, httptest.NewRequest(http.MethodGet, "/schema", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var doc struct {
+		Methods map[string]struct {
+			Protocol string         `json:"protocol"`
+			Params   map[string]any `json:"params"`
+			Result   map[string]any `json:"result"`
+		} `json:"methods"`
+		Errors []extra.ErrorSchema `json:"errors"`
+		Defs   map[string]struct {
+			Properties map[string]any `json:"properties"`
+			Required   []string       `json:"required"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+
+	send, ok := doc.Methods["tasks/send"]
+	require.True(t, ok, "tasks/send is not described")
+	assert.Equal(t, "a2a", send.Protocol)
+	assert.Equal(t, "#/$defs/TaskSendParams", send.Params["$ref"])
+	params := doc.Defs["TaskSendParams"]
+	assert.Contains(t, params.Properties, "id")
+	assert.Contains(t, params.Properties, "message")
+	assert.Contains(t, params.Required, "id")
+	assert.Contains(t, doc.Methods, "tools/call")
+
+	codes := make(map[int]string)
+	for _, e := range doc.Errors {
+		codes[e.Code] = e.Protocol
+	}
+	assert.Equal(t, "a2a", codes[-32001], "task not found error is not described")
+	assert.Equal(t, "a2a", codes[-32002], "task not cancelable error is not described")
+	assert.Contains(t, codes, -32601)
+}
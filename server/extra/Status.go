@@ -11,12 +11,60 @@ import (
 
 // StatusResponse represents the response structure for the status endpoint
 type StatusResponse struct {
-	Config string `json:"config"`
-	Portal string `json:"portal,omitempty"`
+	Config     string `json:"config"`
+	Portal     string `json:"portal,omitempty"`
+	SSEStreams *int64 `json:"sse_streams,omitempty"`
+	// A2ATasks is the number of stored A2A tasks and of the sessions holding them
+	A2ATasks *TaskCounts `json:"a2a_tasks,omitempty"`
+	// UnhealthyBackends maps the ID of each backend failing the MCP handshake to the reason
+	UnhealthyBackends map[string]string `json:"unhealthy_backends,omitempty"`
+	// BackendHealth counts the backends by the result of their last health check
+	BackendHealth *BackendHealthCounts `json:"backend_health,omitempty"`
+	// CircuitBreakers maps the ID of each backend with a circuit breaker in use to its state
+	CircuitBreakers map[string]CircuitBreakerStatus `json:"circuit_breakers,omitempty"`
 }
 
-// StatusHandler creates an HTTP handler for checking system status
-func StatusHandler(cfg config.IConfig, logger *zap.Logger) http.HandlerFunc {
+// BackendHealthCounts is the number of backends whose last health check succeeded or failed
+type BackendHealthCounts struct {
+	Healthy   int `json:"healthy"`
+	Unhealthy int `json:"unhealthy"`
+}
+
+// CircuitBreakerStatus is the state of the circuit breaker of a backend
+type CircuitBreakerStatus struct {
+	State     string `json:"state"`                 // "closed", "open" or "half_open"
+	Failures  int    `json:"failures"`              // Consecutive faults
+	RetryInMs int64  `json:"retry_in_ms,omitempty"` // While open, until a probe is let through
+}
+
+// TaskCounts is the number of stored A2A tasks and of the client sessions holding them
+type TaskCounts struct {
+	Sessions int `json:"sessions"`
+	Tasks    int `json:"tasks"`
+}
+
+// StreamCounter reports the number of currently open SSE streams
+type StreamCounter interface {
+	ActiveSSEStreams() int64
+}
+
+// TaskCounter reports the number of stored A2A tasks and of the sessions holding them
+type TaskCounter interface {
+	TaskCounts() (sessions int, tasks int)
+}
+
+// BackendHealth reports the backends of a gateway that cannot be used, the results
+// of their health checks and the state of their circuit breakers
+type BackendHealth interface {
+	UnhealthyBackends() map[string]string
+	BackendHealthCounts() (healthy int, unhealthy int)
+	CircuitBreakers() map[string]CircuitBreakerStatus
+}
+
+// StatusHandler creates an HTTP handler for checking system status.
+// streams, tasks and backends may be nil if the stream or task counts or the backend
+// health should not be reported.
+func StatusHandler(cfg config.IConfig, logger *zap.Logger, streams StreamCounter, tasks TaskCounter, backends BackendHealth) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		handlerLogger := logger.With(zap.String("handler", "StatusHandler"))
 		w.Header().Set("Content-Type", "application/json")
@@ -29,6 +77,21 @@ func StatusHandler(cfg config.IConfig, logger *zap.Logger) http.HandlerFunc {
 			Portal: "none",
 		}
 
+		if streams != nil {
+			count := streams.ActiveSSEStreams()
+			response.SSEStreams = &count
+		}
+		if tasks != nil {
+			sessions, count := tasks.TaskCounts()
+			response.A2ATasks = &TaskCounts{Sessions: sessions, Tasks: count}
+		}
+		if backends != nil {
+			response.UnhealthyBackends = backends.UnhealthyBackends()
+			healthy, unhealthy := backends.BackendHealthCounts()
+			response.BackendHealth = &BackendHealthCounts{Healthy: healthy, Unhealthy: unhealthy}
+			response.CircuitBreakers = backends.CircuitBreakers()
+		}
+
 		if err := cfg.Status(r.Context()); err != nil {
 			handlerLogger.Error("Failed to get config status", zap.Error(err))
 			response.Config = "error"
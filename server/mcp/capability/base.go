@@ -44,6 +44,7 @@ func NewBase(logger *zap.Logger, manager mcp.ISessionManager) *BaseCapability {
 		"initialize":                bc.handleInitialize,
 		"notifications/ping":        bc.handleNotificationPing,
 		"notifications/initialized": bc.handleNotificationInitialized,
+		"notifications/cancelled":   bc.handleNotificationCancelled,
 	}
 
 	return bc
@@ -219,6 +220,25 @@ func (bc *BaseCapability) handleNotificationInitialized(msg *shared.Message) (in
 	return nil, nil // Notifications expect no response content (nil result, nil error)
 }
 
+// handleNotificationCancelled handles the 'notifications/cancelled' notification from the
+// client: the context of the request is cancelled, stopping the work done for it.
+func (bc *BaseCapability) handleNotificationCancelled(msg *shared.Message) (interface{}, error) {
+	if msg.Params == nil {
+		return nil, fmt.Errorf("cancelled notification without params")
+	}
+	var params schema.CancelledNotificationParams
+	if err := json.Unmarshal(*msg.Params, &params); err != nil {
+		return nil, fmt.Errorf("invalid cancelled notification: %w", err)
+	}
+	logger := bc.logger.With(zap.String("sessionID", msg.Session.GetID()), zap.String("method", "notifications/cancelled"))
+	if msg.Session.Input().CancelRequest(msg.Session.GetID(), &params.RequestID) {
+		logger.Debug("Cancelled request", zap.String("reqID", params.RequestID.String()), zap.String("reason", params.Reason))
+	} else {
+		logger.Debug("Received cancellation of a request not in flight", zap.String("reqID", params.RequestID.String()))
+	}
+	return nil, nil
+}
+
 // handlePing handles the 'ping' request from the client.
 func (bc *BaseCapability) handlePing(msg *shared.Message) (interface{}, error) {
 	logger := bc.logger.With(zap.String("sessionID", msg.Session.GetID()), zap.String("method", "ping"))
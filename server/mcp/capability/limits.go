@@ -0,0 +1,80 @@
+package capability
+
+import (
+	"github.com/gate4ai/mcp/server/a2a"
+	"github.com/gate4ai/mcp/server/mcp/validators"
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+
+	// Use 2025 schema for the capabilities structure
+	schema "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+var _ shared.IServerCapability = (*LimitsCapability)(nil)
+
+// LimitsResult is the result of validators.LimitsMethod. Limits of 0 mean unlimited.
+type LimitsResult struct {
+	RequestsPerSecond  int `json:"requestsPerSecond"`  // Rate limit of the session
+	RequestsPerMinute  int `json:"requestsPerMinute"`  // Rate limit of the session
+	RemainingPerSecond int `json:"remainingPerSecond"` // Requests the session may make now within the per-second limit
+	RemainingPerMinute int `json:"remainingPerMinute"` // Requests the session may make now within the per-minute limit
+	MaxConcurrentTasks int `json:"maxConcurrentTasks"` // Running A2A tasks the user may have
+}
+
+// LimitsCapability answers validators.LimitsMethod with the limits of the user of the
+// requesting session and its remaining quota, so that clients can throttle themselves.
+// It takes no parameters: a client only ever sees the limits of its own user.
+type LimitsCapability struct {
+	logger     *zap.Logger
+	config     config.IConfig
+	throttling *validators.Throttling
+	handlers   map[string]func(*shared.Message) (interface{}, error) // Map method -> handler function
+}
+
+// NewLimitsCapability creates a capability reporting the rate limits of throttling and the
+// task concurrency limit of cfg, both read from the configuration on each request.
+func NewLimitsCapability(logger *zap.Logger, cfg config.IConfig, throttling *validators.Throttling) *LimitsCapability {
+	lc := &LimitsCapability{
+		logger:     logger,
+		config:     cfg,
+		throttling: throttling,
+	}
+	lc.handlers = map[string]func(*shared.Message) (interface{}, error){
+		validators.LimitsMethod: lc.handleLimits,
+	}
+	return lc
+}
+
+// GetHandlers returns a map of method names to handler functions
+func (lc *LimitsCapability) GetHandlers() map[string]func(*shared.Message) (interface{}, error) {
+	return lc.handlers
+}
+
+// SetCapabilities does nothing, the limits method is not an MCP capability
+func (lc *LimitsCapability) SetCapabilities(s *schema.ServerCapabilities) {}
+
+// GetCapabilityOptions returns the capability options for this capability
+func (lc *LimitsCapability) GetCapabilityOptions() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func (lc *LimitsCapability) handleLimits(msg *shared.Message) (interface{}, error) {
+	userID := transport.GetUserId(msg.Session.GetParams())
+	rates := lc.throttling.Limits(msg.Session)
+
+	maxTasks, err := a2a.UserTaskLimit(lc.config, userID)
+	if err != nil {
+		lc.logger.Error("Failed to get task concurrency limit from config", zap.String("sessionID", msg.Session.GetID()), zap.Error(err))
+		return nil, err
+	}
+
+	return &LimitsResult{
+		RequestsPerSecond:  rates.RPS,
+		RequestsPerMinute:  rates.RPM,
+		RemainingPerSecond: rates.RemainingRPS,
+		RemainingPerMinute: rates.RemainingRPM,
+		MaxConcurrentTasks: maxTasks,
+	}, nil
+}
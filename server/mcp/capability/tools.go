@@ -18,6 +18,11 @@ import (
 // It receives the message (containing session and arguments) and returns metadata, result content, and error.
 type ToolHandler func(msg *shared.Message, arguments schema.Arguments) (*schema.Meta, []schema.Content, error)
 
+// StreamingToolHandler handles calls of tools with large results. Content passed to
+// stream.Send is delivered in chunks ahead of the result if the client asked for chunked
+// results, and placed before the returned content otherwise.
+type StreamingToolHandler func(msg *shared.Message, arguments schema.Arguments, stream *ToolResultStream) (*schema.Meta, []schema.Content, error)
+
 // ToolsCapability handles tool registration and invocation.
 type ToolsCapability struct {
 	manager  *mcp.Manager
@@ -29,8 +34,58 @@ type ToolsCapability struct {
 
 // Tool represents a tool entity (using 2025 schema).
 type Tool struct {
-	schema.Tool // Embed the V2025 Tool definition (Name, Description, InputSchema, Annotations)
-	Handler     ToolHandler
+	schema.Tool      // Embed the V2025 Tool definition (Name, Description, InputSchema, Annotations)
+	Handler          ToolHandler
+	StreamingHandler StreamingToolHandler // Set instead of Handler for tools added with AddStreamingTool
+}
+
+// ToolResultStream sends the content of a tool result in chunks while the tool runs.
+type ToolResultStream struct {
+	msg   *shared.Message
+	token string // Chunked results token of the request, empty if the client did not opt in
+
+	mu       sync.Mutex
+	sent     int              // Chunks sent
+	buffered []schema.Content // Content kept for the response when not chunking
+}
+
+// Chunked reports whether content is sent to the client ahead of the result.
+func (s *ToolResultStream) Chunked() bool {
+	return s.token != ""
+}
+
+// Send delivers content as the next chunk of the result, or keeps it for the result if
+// the client did not ask for chunked results.
+func (s *ToolResultStream) Send(content ...schema.Content) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Chunked() {
+		s.buffered = append(s.buffered, content...)
+		return
+	}
+	s.msg.Session.SendNotification(shared.ToolResultChunkMethod, map[string]any{
+		"token":   s.token,
+		"index":   s.sent,
+		"content": content,
+	})
+	s.sent++
+}
+
+// finish completes the result returned by the handler: it records the number of chunks
+// sent, or prepends the content kept when not chunking.
+func (s *ToolResultStream) finish(meta *schema.Meta, content []schema.Content) (*schema.Meta, []schema.Content) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Chunked() {
+		return meta, append(s.buffered, content...)
+	}
+	if s.sent > 0 {
+		if meta == nil {
+			meta = &schema.Meta{}
+		}
+		(*meta)[shared.ChunkedResultMetaKey] = s.sent
+	}
+	return meta, content
 }
 
 // NewToolsCapability creates a new ToolsCapability.
@@ -84,6 +139,33 @@ func (tc *ToolsCapability) AddTool(name string, description string, inputSchema
 	return nil
 }
 
+// AddStreamingTool adds a new tool whose handler may stream its result in chunks.
+func (tc *ToolsCapability) AddStreamingTool(name string, description string, inputSchema *schema.JSONSchemaProperty, annotations *schema.ToolAnnotations, handler StreamingToolHandler) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if _, exists := tc.tools[name]; exists {
+		return fmt.Errorf("tool with name '%s' already exists", name)
+	}
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil for tool '%s'", name)
+	}
+
+	tc.tools[name] = &Tool{
+		Tool: schema.Tool{
+			Name:        name,
+			Description: description,
+			InputSchema: inputSchema,
+			Annotations: annotations,
+		},
+		StreamingHandler: handler,
+	}
+
+	tc.logger.Info("Added streaming tool", zap.String("name", name))
+	go tc.broadcastToolsChanged()
+	return nil
+}
+
 // UpdateTool updates an existing tool.
 func (tc *ToolsCapability) UpdateTool(name string, description string, inputSchema *schema.JSONSchemaProperty, annotations *schema.ToolAnnotations, handler ToolHandler) error {
 	tc.mu.Lock()
@@ -102,6 +184,7 @@ func (tc *ToolsCapability) UpdateTool(name string, description string, inputSche
 	tool.InputSchema = inputSchema
 	tool.Annotations = annotations
 	tool.Handler = handler
+	tool.StreamingHandler = nil
 	// tool.Name should not change ideally, as it's the key
 
 	tc.logger.Info("Updated tool", zap.String("name", name))
@@ -202,7 +285,17 @@ func (tc *ToolsCapability) handleToolsCall(msg *shared.Message) (interface{}, er
 
 	// Call the tool handler
 	startTime := time.Now()
-	meta, content, err := tool.Handler(msg, params.Arguments) // Pass message and arguments
+	var meta *schema.Meta
+	var content []schema.Content
+	var err error
+	if tool.StreamingHandler != nil {
+		token, _ := shared.ChunkedResultToken(&params)
+		stream := &ToolResultStream{msg: msg, token: token}
+		meta, content, err = tool.StreamingHandler(msg, params.Arguments, stream)
+		meta, content = stream.finish(meta, content)
+	} else {
+		meta, content, err = tool.Handler(msg, params.Arguments) // Pass message and arguments
+	}
 	duration := time.Since(startTime)
 
 	// Prepare V2025 result
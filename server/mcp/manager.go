@@ -2,11 +2,13 @@ package mcp
 
 import (
 	"errors"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/gate4ai/mcp/shared"
 	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/tracing"
 
 	// Use V2025 schema for manager's state
 	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
@@ -127,6 +129,37 @@ func (m *Manager) CloseSession(id string) {
 	}
 }
 
+// Sessions returns the active sessions, oldest first.
+func (m *Manager) Sessions() []*Session {
+	m.mu.RLock()
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	m.mu.RUnlock()
+	slices.SortFunc(sessions, func(a, b *Session) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return sessions
+}
+
+// CloseUserSessions closes the sessions of the user, e.g. once their key is revoked, and
+// returns how many there were.
+func (m *Manager) CloseUserSessions(userID string) int {
+	var ids []string
+	m.mu.RLock()
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.RUnlock()
+	for _, id := range ids {
+		m.CloseSession(id)
+	}
+	return len(ids)
+}
+
 func (m *Manager) CloseAllSessions() {
 	m.mu.Lock()
 	// Create a slice of IDs to close to avoid holding the lock during CloseSession calls
@@ -178,3 +211,18 @@ func (m *Manager) NotifyEligibleSessions(method string, params map[string]any) {
 func (m *Manager) AddValidator(validators ...shared.MessageValidator) {
 	m.inputProcessor.AddValidator(validators...)
 }
+
+// AddRequestObserver adds observers told about every request answered to clients
+func (m *Manager) AddRequestObserver(observers ...shared.RequestObserver) {
+	m.inputProcessor.AddRequestObserver(observers...)
+}
+
+// AddRequestLogger adds loggers told about every request answered to clients
+func (m *Manager) AddRequestLogger(loggers ...shared.RequestLogger) {
+	m.inputProcessor.AddRequestLogger(loggers...)
+}
+
+// SetTracer makes the manager trace the requests of clients
+func (m *Manager) SetTracer(tracer *tracing.Tracer) {
+	m.inputProcessor.SetTracer(tracer)
+}
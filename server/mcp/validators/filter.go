@@ -0,0 +1,102 @@
+package validators
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// lifecycleMethods are accepted whatever the allow list, so that clients can still connect.
+var lifecycleMethods = map[string]bool{
+	"initialize":                true,
+	"notifications/initialized": true,
+	"ping":                      true,
+}
+
+// notificationPrefix starts the methods of notifications, which the allow list does not
+// restrict: they accompany the allowed requests, e.g. notifications/cancelled for a
+// tools/call, and receive no answer telling the client they were rejected.
+const notificationPrefix = "notifications/"
+
+// MethodFilter rejects, for every user, the methods denied or not allowed by the
+// server configuration (see config.IConfig.MethodsDeny and MethodsAllow). Messages are
+// validated when they arrive, before any handler checks what the user may do.
+type MethodFilter struct {
+	config config.IConfig
+	logger *zap.Logger
+}
+
+// NewMethodFilter creates a method filter reading its lists from cfg on each message,
+// so changes to the configuration apply at once.
+func NewMethodFilter(cfg config.IConfig, logger *zap.Logger) *MethodFilter {
+	return &MethodFilter{
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Validate implements the MessageValidator interface
+func (f *MethodFilter) Validate(msg *shared.Message) error {
+	if msg.Method == nil {
+		return nil // Responses to requests of the server
+	}
+	method := *msg.Method
+
+	deny, err := f.config.MethodsDeny()
+	if err != nil {
+		f.logger.Error("Failed to get denied methods from config", zap.Error(err))
+		return fmt.Errorf("failed to check method %s: %w", method, err)
+	}
+	for _, pattern := range deny {
+		if MatchMethod(pattern, method) {
+			return disabledMethodError(method)
+		}
+	}
+
+	allow, err := f.config.MethodsAllow()
+	if err != nil {
+		f.logger.Error("Failed to get allowed methods from config", zap.Error(err))
+		return fmt.Errorf("failed to check method %s: %w", method, err)
+	}
+	if len(allow) == 0 || lifecycleMethods[method] || strings.HasPrefix(method, notificationPrefix) {
+		return nil
+	}
+	for _, pattern := range allow {
+		if MatchMethod(pattern, method) {
+			return nil
+		}
+	}
+	return disabledMethodError(method)
+}
+
+func disabledMethodError(method string) error {
+	return &shared.JSONRPCError{
+		Code:    shared.JSONRPCErrorMethodNotFound,
+		Message: fmt.Sprintf("Method %s is disabled on this server", method),
+	}
+}
+
+// MatchMethod reports whether method matches pattern, in which "*" matches any
+// characters, including "/": "resources/*" matches every resources method and
+// "*/list" every list method.
+func MatchMethod(pattern, method string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == method
+	}
+	if !strings.HasPrefix(method, parts[0]) {
+		return false
+	}
+	rest := method[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return strings.HasSuffix(rest, parts[len(parts)-1])
+}
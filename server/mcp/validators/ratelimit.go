@@ -0,0 +1,120 @@
+package validators
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// UserRateLimiter holds a token bucket per user. Time is read from its clock, so that
+// tests can advance it.
+type UserRateLimiter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	buckets map[string]*userBucket // userID -> bucket
+}
+
+// userBucket is the token bucket of a user and the limit it was created for.
+type userBucket struct {
+	limit   config.RateLimit
+	limiter *rate.Limiter
+}
+
+// NewUserRateLimiter creates a rate limiter reading the time from now, time.Now if nil.
+func NewUserRateLimiter(now func() time.Time) *UserRateLimiter {
+	if now == nil {
+		now = time.Now
+	}
+	return &UserRateLimiter{
+		now:     now,
+		buckets: make(map[string]*userBucket),
+	}
+}
+
+// Allow takes a token from the bucket of the user for a request and reports whether
+// there was one. If not, it returns how long until there is. A changed limit applies at
+// once, keeping the tokens left up to the new burst; a limit without a positive rate
+// allows every request, and one without a burst allows one request at once.
+func (l *UserRateLimiter) Allow(userID string, limit config.RateLimit) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if limit.RPS <= 0 {
+		delete(l.buckets, userID)
+		return true, 0
+	}
+	limit.Burst = max(limit.Burst, 1)
+	bucket, ok := l.buckets[userID]
+	if !ok {
+		bucket = &userBucket{limit: limit, limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)}
+		l.buckets[userID] = bucket
+	} else if bucket.limit != limit {
+		bucket.limiter.SetLimitAt(now, rate.Limit(limit.RPS))
+		bucket.limiter.SetBurstAt(now, limit.Burst)
+		bucket.limit = limit
+	}
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// UserRateLimit rejects the requests of users exceeding their rate limit (see
+// config.IConfig.GetUserRateLimit) across all their sessions with a
+// shared.JSONRPCErrorRateLimited error. Users without a limit, anonymous users,
+// notifications, responses and the lifecycle methods, such as initialize, are not limited.
+type UserRateLimit struct {
+	config  config.IConfig
+	logger  *zap.Logger
+	limiter *UserRateLimiter
+}
+
+// NewUserRateLimit creates a rate limit validator reading the limits from cfg on each
+// request, so changes to the configuration apply at once.
+func NewUserRateLimit(cfg config.IConfig, logger *zap.Logger) *UserRateLimit {
+	return &UserRateLimit{
+		config:  cfg,
+		logger:  logger,
+		limiter: NewUserRateLimiter(nil),
+	}
+}
+
+// Validate implements the MessageValidator interface
+func (r *UserRateLimit) Validate(msg *shared.Message) error {
+	if msg.Method == nil || msg.ID.IsEmpty() || lifecycleMethods[*msg.Method] {
+		return nil
+	}
+	userID := transport.GetUserId(msg.Session.GetParams())
+	if userID == "" {
+		return nil
+	}
+
+	limit, err := r.config.GetUserRateLimit(userID)
+	if err != nil {
+		r.logger.Warn("Failed to get user rate limit, not limiting the request", zap.String("userID", userID), zap.Error(err))
+		return nil
+	}
+	if ok, retryAfter := r.limiter.Allow(userID, limit); !ok {
+		return rateLimitedError(retryAfter)
+	}
+	return nil
+}
+
+func rateLimitedError(retryAfter time.Duration) error {
+	retryAfterMs := (retryAfter + time.Millisecond - 1).Milliseconds() // Rounded up
+	return &shared.JSONRPCError{
+		Code:    shared.JSONRPCErrorRateLimited,
+		Message: fmt.Sprintf("Rate limit exceeded, retry in %dms", retryAfterMs),
+		Data:    map[string]any{"retryAfterMs": retryAfterMs},
+	}
+}
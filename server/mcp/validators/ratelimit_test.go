@@ -0,0 +1,76 @@
+package validators
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+)
+
+// fakeClock is a clock that only moves when advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestUserRateLimiterTokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	limiter := NewUserRateLimiter(clock.Now)
+	limit := config.RateLimit{RPS: 2, Burst: 3}
+
+	for i := range 3 {
+		if ok, _ := limiter.Allow("alice", limit); !ok {
+			t.Fatalf("Request %d of the burst was rejected", i+1)
+		}
+	}
+	ok, retryAfter := limiter.Allow("alice", limit)
+	if ok || retryAfter != 500*time.Millisecond {
+		t.Fatalf("Request beyond the burst: Allow = %v, %v; want false, 500ms", ok, retryAfter)
+	}
+	if ok, _ := limiter.Allow("bob", limit); !ok {
+		t.Fatal("Another user was limited by the requests of alice")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if ok, _ := limiter.Allow("alice", limit); !ok {
+		t.Fatal("Request after the refill of a token was rejected")
+	}
+	if ok, _ := limiter.Allow("alice", limit); ok {
+		t.Fatal("Request without a token left was allowed")
+	}
+
+	// A lower burst caps the tokens refilled from then on
+	clock.Advance(10 * time.Second)
+	limit = config.RateLimit{RPS: 2, Burst: 1}
+	if ok, _ := limiter.Allow("alice", limit); !ok {
+		t.Fatal("Request after the limit changed was rejected")
+	}
+	if ok, _ := limiter.Allow("alice", limit); ok {
+		t.Fatal("Request beyond the lowered burst was allowed")
+	}
+
+	// Users without a limit are not limited
+	for range 100 {
+		if ok, _ := limiter.Allow("alice", config.RateLimit{}); !ok {
+			t.Fatal("Request of a user without a limit was rejected")
+		}
+	}
+}
+
+func TestRateLimitedError(t *testing.T) {
+	var rpcErr *shared.JSONRPCError
+	if err := rateLimitedError(1500 * time.Microsecond); !errors.As(err, &rpcErr) {
+		t.Fatalf("rateLimitedError = %T, want a JSON-RPC error", err)
+	}
+	if rpcErr.Code != shared.JSONRPCErrorRateLimited {
+		t.Errorf("Code = %d, want %d", rpcErr.Code, shared.JSONRPCErrorRateLimited)
+	}
+	if data, _ := rpcErr.Data.(map[string]any); data["retryAfterMs"] != int64(2) {
+		t.Errorf("Data = %v, want retryAfterMs rounded up to 2", rpcErr.Data)
+	}
+}
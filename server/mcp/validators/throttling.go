@@ -2,21 +2,39 @@ package validators
 
 import (
 	"errors"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/gate4ai/mcp/server/transport"
 	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
 
+// Default rate limits of a session.
+const (
+	DefaultRPS = 60  // Requests per second
+	DefaultRPM = 600 // Requests per minute
+)
+
+// userLimitsRefresh is how often the limits of a session's user are read from the
+// configuration again, so that changed limits apply without reading it for every message.
+const userLimitsRefresh = 10 * time.Second
+
 // Throttling limits the rate of messages per session using RPM (requests per minute) and RPS (requests per second)
 type Throttling struct {
 	// Default values to use if not specified in session parameters
 	defaultRPM int
 	defaultRPS int
+	config     config.IConfig // Source of per-user limits, nil for none
+	logger     *zap.Logger
 	mu         sync.RWMutex
 }
 
-// Constants for session parameter keys
+// Constants for session parameter keys. RPMParamKey and RPSParamKey are also the user
+// params setting the limits of a user, e.g. "1200".
 const (
 	RPMParamKey      = "throttling_rpm"
 	RPSParamKey      = "throttling_rps"
@@ -25,8 +43,21 @@ const (
 
 // limiterPair holds the RPS and RPM limiters for a session
 type limiterPair struct {
+	mu         sync.Mutex
+	rps        int
+	rpm        int
 	rpsLimiter *rate.Limiter
 	rpmLimiter *rate.Limiter
+	checked    time.Time // When the limits were last read
+}
+
+// RateLimits are the rate limits of a session and the requests it may make right now
+// without exceeding them. A limit of 0 means unlimited.
+type RateLimits struct {
+	RPS          int
+	RPM          int
+	RemainingRPS int
+	RemainingRPM int
 }
 
 // NewThrottling creates a new throttling validator
@@ -37,15 +68,34 @@ func NewThrottling(defaultRPS, defaultRPM int) *Throttling {
 	}
 }
 
-// getLimiters gets or creates rate limiters for a session
-func (t *Throttling) getLimiters(session shared.ISession) *limiterPair {
+// NewUserThrottling creates a throttling validator whose limits can be set per user with
+// the user params RPSParamKey and RPMParamKey in cfg. Changed limits apply to open
+// sessions within userLimitsRefresh.
+func NewUserThrottling(cfg config.IConfig, logger *zap.Logger, defaultRPS, defaultRPM int) *Throttling {
+	t := NewThrottling(defaultRPS, defaultRPM)
+	t.config = cfg
+	t.logger = logger
+	return t
+}
+
+// rates returns the limits of a session: its session parameters, else the user params of
+// its user, else the defaults.
+func (t *Throttling) rates(session shared.ISession) (rps int, rpm int) {
 	sessionParams := session.GetParams()
 
 	t.mu.RLock()
-	// Get RPM and RPS values from session parameters
-	rpm := t.defaultRPM
-	rps := t.defaultRPS
-	defer t.mu.RUnlock()
+	rpm = t.defaultRPM
+	rps = t.defaultRPS
+	t.mu.RUnlock()
+
+	if t.config != nil {
+		if userParams, err := t.config.GetUserParams(transport.GetUserId(sessionParams)); err != nil {
+			t.logger.Warn("Failed to get user params for throttling, using the default limits", zap.Error(err))
+		} else {
+			rps = t.userRate(userParams, RPSParamKey, rps)
+			rpm = t.userRate(userParams, RPMParamKey, rpm)
+		}
+	}
 
 	// Check if custom RPM is specified in the session
 	if rpmValue, ok := sessionParams.Load(RPMParamKey); ok {
@@ -60,42 +110,102 @@ func (t *Throttling) getLimiters(session shared.ISession) *limiterPair {
 			rps = rpsInt
 		}
 	}
+	return rps, rpm
+}
+
+// userRate returns the positive limit of the user param, or def if it is not set or invalid.
+func (t *Throttling) userRate(userParams map[string]string, key string, def int) int {
+	value := userParams[key]
+	if value == "" {
+		return def
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		t.logger.Warn("Invalid throttling limit in user params, using the default", zap.String("param", key), zap.String("value", value))
+		return def
+	}
+	return limit
+}
+
+// getLimiters gets or creates rate limiters for a session, reading its limits again
+// if refresh is set or they are older than userLimitsRefresh. The caller must hold
+// the mutex of the returned pair.
+func (t *Throttling) getLimiters(session shared.ISession, refresh bool) *limiterPair {
+	sessionParams := session.GetParams()
 
 	// Check if limiters already exist in session
-	value, ok := sessionParams.Load(LimitersParamKey)
-	pair, ok2 := value.(*limiterPair)
-	if ok && ok2 && pair != nil {
-		return pair
+	value, _ := sessionParams.LoadOrStore(LimitersParamKey, &limiterPair{})
+	pair := value.(*limiterPair)
+
+	pair.mu.Lock()
+	if refresh || pair.checked.IsZero() || (t.config != nil && time.Since(pair.checked) >= userLimitsRefresh) {
+		rps, rpm := t.rates(session)
+		pair.set(rps, rpm)
+		pair.checked = time.Now()
 	}
+	return pair
+}
 
-	// Create new limiters
-	pair = &limiterPair{}
+// set creates the limiters or changes their limits. The caller must hold p.mu.
+func (p *limiterPair) set(rps, rpm int) {
+	if rpm != p.rpm {
+		p.rpmLimiter = updateLimiter(p.rpmLimiter, rate.Limit(rpm)/60.0, rpm) // RPM converted to requests per second
+	}
+	if rps != p.rps {
+		p.rpsLimiter = updateLimiter(p.rpsLimiter, rate.Limit(rps), rps)
+	}
+	p.rps, p.rpm = rps, rpm
+}
 
-	if rpm > 0 {
-		// Convert RPM to requests per second for the limiter
-		pair.rpmLimiter = rate.NewLimiter(rate.Limit(rpm)/60.0, rpm)
+// updateLimiter applies the limit and burst to the limiter, creating it if needed. A
+// burst of 0 removes the limiter.
+func updateLimiter(limiter *rate.Limiter, limit rate.Limit, burst int) *rate.Limiter {
+	switch {
+	case burst <= 0:
+		return nil
+	case limiter == nil:
+		return rate.NewLimiter(limit, burst)
+	default:
+		limiter.SetLimit(limit)
+		limiter.SetBurst(burst)
+		return limiter
 	}
+}
 
-	if rps > 0 {
-		pair.rpsLimiter = rate.NewLimiter(rate.Limit(rps), rps)
+// remaining returns the whole tokens left in the limiter, 0 for no limiter.
+func remaining(limiter *rate.Limiter) int {
+	if limiter == nil {
+		return 0
 	}
+	return max(int(limiter.Tokens()), 0)
+}
 
-	sessionParams.Store(LimitersParamKey, pair)
-	return pair
+// Limits returns the current rate limits of the session, read from the configuration
+// at once, and how many requests the session may still make now.
+func (t *Throttling) Limits(session shared.ISession) RateLimits {
+	pair := t.getLimiters(session, true)
+	defer pair.mu.Unlock()
+	return RateLimits{
+		RPS:          pair.rps,
+		RPM:          pair.rpm,
+		RemainingRPS: remaining(pair.rpsLimiter),
+		RemainingRPM: remaining(pair.rpmLimiter),
+	}
 }
 
 // Validate implements the MessageValidator interface
 func (t *Throttling) Validate(msg *shared.Message) error {
 	// Get limiters for this session
-	pair := t.getLimiters(msg.Session)
+	pair := t.getLimiters(msg.Session, false)
+	defer pair.mu.Unlock()
 
 	// Check RPM limit
-	if !pair.rpmLimiter.Allow() {
+	if pair.rpmLimiter != nil && !pair.rpmLimiter.Allow() {
 		return errors.New("RPM throttling limit exceeded")
 	}
 
 	// Check RPS limit
-	if !pair.rpsLimiter.Allow() {
+	if pair.rpsLimiter != nil && !pair.rpsLimiter.Allow() {
 		return errors.New("RPS throttling limit exceeded")
 	}
 
@@ -7,6 +7,9 @@ import (
 	"github.com/gate4ai/mcp/shared"
 )
 
+// LimitsMethod reports the limits of the client's user, see capability.LimitsCapability.
+const LimitsMethod = "gate4ai/limits"
+
 // MethodValidator validates that the method in a message exists in the MCP specification
 type MethodValidator struct {
 	validMethods map[string]bool
@@ -34,7 +37,11 @@ func NewMethodValidator() *MethodValidator {
 
 			// Notifications from the client
 			"notifications/initialized":        true,
+			"notifications/cancelled":          true,
 			"notifications/roots/list_changed": true,
+
+			// Extensions
+			LimitsMethod: true,
 		},
 	}
 
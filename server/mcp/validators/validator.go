@@ -4,10 +4,14 @@ import (
 	"github.com/gate4ai/mcp/shared"
 )
 
-// CreateDefaultValidators returns the standard set of validators with default settings
-func CreateDefaultValidators() []shared.MessageValidator {
+// CreateDefaultValidators returns the standard set of validators with default settings,
+// throttling sessions with throttling, or with the default rates if it is nil
+func CreateDefaultValidators(throttling *Throttling) []shared.MessageValidator {
+	if throttling == nil {
+		throttling = NewThrottling(DefaultRPS, DefaultRPM) // 60 requests per second, 600 requests per minute
+	}
 	return []shared.MessageValidator{
-		NewThrottling(60, 600),          // 60 requests per second, 600 requests per minute
+		throttling,
 		NewMessageSizeValidator(102400), //100KB
 		NewMethodValidator(),
 	}
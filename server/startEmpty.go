@@ -13,24 +13,63 @@ import (
 	"github.com/gate4ai/mcp/server/mcp/capability"
 	"github.com/gate4ai/mcp/server/mcp/validators"
 	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
 	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/privacy"
 	"go.uber.org/zap"
 )
 
+// StartOption configures StartServer.
+type StartOption func(*startOptions)
+
+type startOptions struct {
+	stopped chan<- struct{} // Closed once the server shut down, nil if not needed
+}
+
+// WithStopped makes StartServer close stopped once the server shut down after ctx is
+// done, its open streams drained, so the process may exit.
+func WithStopped(stopped chan<- struct{}) StartOption {
+	return func(o *startOptions) {
+		o.stopped = stopped
+	}
+}
+
 // StartServer starts the MCP SSE server with the provided options
 // It now returns the capabilities for customization by callers like startExample.
-func StartServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig, overwriteListenAddr string) (
+// When ctx is done the server drains its open streams for up to server.sse.drain_timeout
+// before closing them.
+func StartServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig, overwriteListenAddr string, options ...StartOption) (
 	*capability.ToolsCapability,
 	*capability.ResourcesCapability,
 	*capability.PromptsCapability,
 	*capability.CompletionCapability,
 	error,
 ) {
+	var opts startOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	logPrivacy, err := cfg.LogPrivacy()
+	if err != nil {
+		logger.Error("Failed to get log privacy from config, logging unredacted", zap.Error(err))
+	}
+	logger = privacy.WrapLogger(logger, logPrivacy)
+	idGeneratorName, err := cfg.IDGenerator()
+	if err != nil {
+		logger.Error("Failed to get ID generator from config, using random IDs", zap.Error(err))
+	}
+	if idGenerator, err := shared.NewIDGenerator(idGeneratorName); err == nil {
+		shared.SetIDGenerator(idGenerator)
+	}
+
 	sessionManager, err := mcp.NewManager(logger, cfg)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to create session manager: %w", err)
 	}
-	sessionManager.AddValidator(validators.CreateDefaultValidators()...)
+	sessionManager.AddValidator(validators.NewMethodFilter(cfg, logger), validators.NewUserRateLimit(cfg, logger))
+	throttling := validators.NewUserThrottling(cfg, logger, validators.DefaultRPS, validators.DefaultRPM)
+	sessionManager.AddValidator(validators.CreateDefaultValidators(throttling)...)
 
 	// --- Initialize Capabilities ---
 	baseCapability := capability.NewBase(logger, sessionManager)
@@ -38,6 +77,7 @@ func StartServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig, ov
 	resourcesCapability := capability.NewResourcesCapability(sessionManager, logger)
 	promptsCapability := capability.NewPromptsCapability(logger, sessionManager)
 	completionCapability := capability.NewCompletionCapability(logger)
+	limitsCapability := capability.NewLimitsCapability(logger, cfg, throttling)
 	// Add more capabilities here if needed
 
 	// Register capabilities with the session manager's input processor
@@ -47,6 +87,7 @@ func StartServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig, ov
 		resourcesCapability,
 		promptsCapability,
 		completionCapability,
+		limitsCapability,
 	)
 
 	// --- Set up transport ---
@@ -61,7 +102,7 @@ func StartServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig, ov
 
 	// Register status handler
 	logger.Info("Registering status handler", zap.String("path", "/status"))
-	mux.HandleFunc("/status", extra.StatusHandler(cfg, logger))
+	mux.HandleFunc("/status", extra.StatusHandler(cfg, logger, sseTransport, nil, nil))
 
 	// --- Start HTTP Server using Shared Utility ---
 	serverInstance, listenerErrChan, startErr := transport.StartHTTPServer(
@@ -77,6 +118,9 @@ func StartServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig, ov
 
 	// --- Goroutine to handle listener errors and graceful shutdown ---
 	go func() {
+		if opts.stopped != nil {
+			defer close(opts.stopped)
+		}
 		select {
 		case err, ok := <-listenerErrChan:
 			if ok && err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -87,15 +131,26 @@ func StartServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig, ov
 			logger.Info("Server listener stopped.")
 		case <-ctx.Done():
 			logger.Info("Shutdown signal received, stopping server...")
-			// Create shutdown context with timeout
-			shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second) // Timeout for shutdown
+			drainTimeout := sseTransport.DrainTimeout()
+			// Create shutdown context with timeout, leaving time to close connections after the drain
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout+5*time.Second)
 			defer cancel()
 
+			// Stop accepting connections while the open streams are drained
+			httpStopped := make(chan struct{})
+			go func() {
+				defer close(httpStopped)
+				transport.ShutdownHTTPServer(shutdownCtx, logger, serverInstance)
+			}()
+			drainCtx, cancelDrain := context.WithTimeout(shutdownCtx, drainTimeout)
+			if err := sseTransport.Shutdown(drainCtx); err != nil {
+				logger.Warn("Closed streams before their requests were answered", zap.Error(err))
+			}
+			cancelDrain()
+
 			// Close MCP sessions
 			sessionManager.CloseAllSessions()
-
-			// Shutdown HTTP server using the shared utility function
-			transport.ShutdownHTTPServer(shutdownCtx, logger, serverInstance)
+			<-httpStopped
 			logger.Info("Server stopped.")
 		}
 	}()
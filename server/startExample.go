@@ -16,8 +16,8 @@ import (
 )
 
 // StartExample starts the MCP SSE server with example resources
-func StartExample(ctx context.Context, logger *zap.Logger, cfg config.IConfig, overwriteListenAddr string) error {
-	toolsCapability, resourcesCapability, promptsCapability, _ /*completionCapability*/, err := StartServer(ctx, logger, cfg, overwriteListenAddr)
+func StartExample(ctx context.Context, logger *zap.Logger, cfg config.IConfig, overwriteListenAddr string, options ...StartOption) error {
+	toolsCapability, resourcesCapability, promptsCapability, _ /*completionCapability*/, err := StartServer(ctx, logger, cfg, overwriteListenAddr, options...)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"crypto/x509"
 	"errors"
 	"sync"
 
@@ -41,12 +42,20 @@ func (a *Authenticator) Authenticate(authKey string, remoteAddr string) (string,
 		return "", nil, err
 	}
 
+	// Only client certificates authenticate users, see AuthenticateClientCert
+	if authType == config.AuthorizedByClientCert {
+		a.logger.Info("Client certificate is not set, NotAuthorized")
+		if authKey != "" {
+			return "", nil, errors.New("client certificate required, authorization keys are not accepted")
+		}
+		return "", nil, errors.New("client certificate required")
+	}
+
 	var userID string
 	// If authentication is not required everywhere
 	if authKey != "" {
-		// Hash the auth key before looking it up
-		keyHash := config.HashAPIKey(authKey)
-		userID, err = a.config.GetUserIDByKeyHash(keyHash)
+		// Compared with the stored key hashes using the configured hash algorithm
+		userID, err = a.config.GetUserIDByKey(authKey)
 		if err != nil {
 			return "", nil, err
 		}
@@ -64,10 +73,43 @@ func (a *Authenticator) Authenticate(authKey string, remoteAddr string) (string,
 	return userID, sessionParams, nil
 }
 
+// AuthenticateClientCert maps the identities of a verified client certificate to a user
+// (see CertIdentities); the first identity mapped to one wins.
+func (a *Authenticator) AuthenticateClientCert(cert *x509.Certificate, remoteAddr string) (string, *sync.Map, error) {
+	sessionParams := &sync.Map{}
+	sessionParams.Store("RemoteAddr", remoteAddr)
+
+	authType, err := a.config.AuthorizationType()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var userID string
+	for _, identity := range CertIdentities(cert) {
+		userID, err = a.config.GetUserIDByCertIdentity(identity)
+		if err != nil {
+			return "", nil, err
+		}
+		if userID != "" {
+			break
+		}
+	}
+
+	if userID == "" && (authType == config.AuthorizedUsersOnly || authType == config.AuthorizedByClientCert) {
+		a.logger.Info("Client certificate is not mapped to a user, NotAuthorized", zap.String("subject", cert.Subject.String()))
+		return "", nil, errors.New("client certificate is not mapped to a user")
+	}
+
+	SaveAuthKey(sessionParams, "")
+	SaveUserId(sessionParams, userID)
+	return userID, sessionParams, nil
+}
+
 // Constants for session parameter keys
 const (
-	UserIDKey  = "authenticator_user_id"
-	AuthKeyKey = "authenticator_auth_key"
+	UserIDKey    = "authenticator_user_id"
+	AuthKeyKey   = "authenticator_auth_key"
+	TransportKey = "transport_name" // Of the transport that created the session
 )
 
 func SaveUserId(sessionParams *sync.Map, userID string) {
@@ -93,3 +135,17 @@ func GetAuthKey(sessionParams *sync.Map) string {
 	}
 	return authKey.(string)
 }
+
+func SaveTransportName(sessionParams *sync.Map, name string) {
+	sessionParams.Store(TransportKey, name)
+}
+
+// GetTransportName returns the transport that created the session: TransportSSE,
+// TransportStreamableHTTP or TransportWebSocket.
+func GetTransportName(sessionParams *sync.Map) string {
+	name, ok := sessionParams.Load(TransportKey)
+	if !ok {
+		return ""
+	}
+	return name.(string)
+}
@@ -0,0 +1,164 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+)
+
+// errEmptyBatch is returned by parseBody for an empty JSON-RPC batch.
+var errEmptyBatch = errors.New("empty batch")
+
+// batchSlot is the answer due to an element of a POSTed JSON-RPC batch, in the order of
+// the batch: the response to a request, or the error an invalid element is answered
+// with. Notifications and responses of the client get no slot.
+type batchSlot struct {
+	requestID *schema.RequestID            // Of a request
+	invalid   *shared.JSONRPCErrorResponse // Of an element that is not a valid message
+}
+
+// parseBody parses the body of a POST: a single JSON-RPC message, parsed as by
+// shared.ParseMessages, or a batch of them. batch reports whether body is an array. An
+// element of a batch that is not a valid message does not fail the others: it gets a
+// slot answering it with an Invalid Request error, with its ID if it has a valid one.
+func parseBody(session shared.ISession, body []byte) (msgs []*shared.Message, slots []batchSlot, batch bool, err error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		msgs, err := shared.ParseMessages(session, body)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return msgs, requestSlots(msgs), false, nil
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(trimmed, &elements); err != nil {
+		return nil, nil, true, err
+	}
+	if len(elements) == 0 {
+		return nil, nil, true, errEmptyBatch
+	}
+	for _, element := range elements {
+		msg, ok := parseBatchElement(element)
+		if !ok {
+			slots = append(slots, batchSlot{invalid: &shared.JSONRPCErrorResponse{
+				JSONRPC: shared.JSONRPCVersion,
+				ID:      elementID(element),
+				Error:   &shared.JSONRPCError{Code: shared.JSONRPCErrorInvalidRequest, Message: "Invalid Request"},
+			}})
+			continue
+		}
+		msg.Session = session
+		msgs = append(msgs, msg)
+		if isRequestMessage(msg) {
+			slots = append(slots, batchSlot{requestID: msg.ID})
+		}
+	}
+	return msgs, slots, true, nil
+}
+
+// parseBatchElement parses an element of a batch, reporting whether it is a request, a
+// notification or a response.
+func parseBatchElement(element json.RawMessage) (*shared.Message, bool) {
+	if !bytes.HasPrefix(bytes.TrimSpace(element), []byte("{")) {
+		return nil, false
+	}
+	var msg shared.Message
+	if err := json.Unmarshal(element, &msg); err != nil {
+		return nil, false
+	}
+	isResponse := msg.ID != nil && !msg.ID.IsEmpty() && (msg.Result != nil || msg.Error != nil)
+	if (msg.Method == nil || *msg.Method == "") && !isResponse {
+		return nil, false
+	}
+	return &msg, true
+}
+
+// elementID returns the ID of an invalid batch element, nil if it has no valid one.
+func elementID(element json.RawMessage) *schema.RequestID {
+	var withID struct {
+		ID *schema.RequestID `json:"id"`
+	}
+	if json.Unmarshal(element, &withID) != nil || withID.ID == nil || withID.ID.IsEmpty() {
+		return nil
+	}
+	return withID.ID
+}
+
+// requestSlots returns a slot for each request of msgs.
+func requestSlots(msgs []*shared.Message) []batchSlot {
+	var slots []batchSlot
+	for _, msg := range msgs {
+		if isRequestMessage(msg) {
+			slots = append(slots, batchSlot{requestID: msg.ID})
+		}
+	}
+	return slots
+}
+
+// isRequestMessage reports whether msg is a request expecting a response.
+func isRequestMessage(msg *shared.Message) bool {
+	return msg.Method != nil && msg.ID != nil && !msg.ID.IsEmpty()
+}
+
+// invalidResponses returns the error responses of the invalid elements among slots.
+func invalidResponses(slots []batchSlot) []*shared.JSONRPCErrorResponse {
+	var responses []*shared.JSONRPCErrorResponse
+	for _, slot := range slots {
+		if slot.invalid != nil {
+			responses = append(responses, slot.invalid)
+		}
+	}
+	return responses
+}
+
+// initializePollInterval is how often awaitInitialize checks the status of the session.
+const initializePollInterval = 5 * time.Millisecond
+
+// awaitInitialize waits until the initialize request of a batch has been handled, so the
+// requests following it in the batch find the session initialized. It reports whether
+// the session was initialized in time.
+func awaitInitialize(r *http.Request, session shared.ISession) bool {
+	ticker := time.NewTicker(initializePollInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(responseTimeout)
+	defer timeout.Stop()
+	for session.GetStatus() == shared.StatusNew {
+		select {
+		case <-ticker.C:
+		case <-timeout.C:
+			return false
+		case <-r.Context().Done():
+			return false
+		}
+	}
+	return true
+}
+
+// rejectRequests answers the requests of msgs with an error in their slots instead of
+// passing them to the session.
+func rejectRequests(slots []batchSlot, msgs []*shared.Message, message string) {
+	rejected := make(map[string]*schema.RequestID)
+	for _, msg := range msgs {
+		if isRequestMessage(msg) {
+			rejected[msg.ID.String()] = msg.ID
+		}
+	}
+	for i, slot := range slots {
+		if slot.requestID == nil {
+			continue
+		}
+		if id, ok := rejected[slot.requestID.String()]; ok {
+			slots[i] = batchSlot{invalid: &shared.JSONRPCErrorResponse{
+				JSONRPC: shared.JSONRPCVersion,
+				ID:      id,
+				Error:   &shared.JSONRPCError{Code: shared.JSONRPCErrorInvalidRequest, Message: message},
+			}}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// certReloader serves the certificate of manual SSL mode for tls.Config.GetCertificate
+// and reloads it when the certificate or key file changes, so renewed certificates are
+// served without a restart. If the changed files fail to load, e.g. while a renewal
+// tool is halfway through writing them, the previous certificate is kept.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *zap.Logger
+	changed  chan struct{} // Signaled when a certificate replaced a previous one
+
+	mu    sync.RWMutex
+	cert  *tls.Certificate
+	stamp string // Modification times and sizes of the files at the last load
+}
+
+func newCertReloader(certFile string, keyFile string, logger *zap.Logger) *certReloader {
+	return &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// GetCertificate returns the last certificate loaded successfully.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := r.current(); cert != nil {
+		return cert, nil
+	}
+	return nil, errors.New("no certificate loaded")
+}
+
+// current returns the last certificate loaded successfully, nil if none was.
+func (r *certReloader) current() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// load loads the files if they changed since the last load and reports whether a new
+// certificate was loaded. Files failing to load are not tried again until they change.
+func (r *certReloader) load() (bool, error) {
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return false, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	r.mu.RLock()
+	unchanged := stamp == r.stamp
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	r.mu.Lock()
+	r.stamp = stamp
+	replaced := err == nil && r.cert != nil
+	if err == nil {
+		r.cert = &cert
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return false, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	if replaced {
+		select {
+		case r.changed <- struct{}{}:
+		default:
+		}
+	}
+	return true, nil
+}
+
+// fileStamp describes the current versions of the certificate and key files.
+func (r *certReloader) fileStamp() (string, error) {
+	var stamp string
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%d/%d;", info.ModTime().UnixNano(), info.Size())
+	}
+	return stamp, nil
+}
+
+// run checks the files for changes every interval until ctx is done.
+func (r *certReloader) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		loaded, err := r.load()
+		if err != nil {
+			r.logger.Error("Failed to reload the certificate, serving the previous one", zap.String("certFile", r.certFile), zap.Error(err))
+		} else if loaded {
+			r.logger.Info("Reloaded the certificate", zap.String("certFile", r.certFile), zap.Time("notAfter", r.current().Leaf.NotAfter))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package transport_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStartHTTPServer_ReloadsCertificate(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	newServerCert := func() *testCert {
+		return newTestCert(t, ca, &x509.Certificate{
+			Subject:     pkix.Name{CommonName: "gateway"},
+			IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			KeyUsage:    x509.KeyUsageDigitalSignature,
+		})
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	oldCert := newServerCert()
+	oldCert.writePEM(t, certFile, keyFile)
+
+	cfg := config.NewInternalConfig()
+	cfg.SSLEnabledValue = true
+	cfg.SSLCertFileValue = certFile
+	cfg.SSLKeyFileValue = keyFile
+	cfg.SSLReloadIntervalValue = 20 * time.Millisecond
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, _, err := transport.StartHTTPServer(ctx, zap.NewNop(), cfg, createDummyMux(), addr)
+	require.NoError(t, err)
+	defer server.Shutdown(context.Background())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	// servedSerial returns the serial number of the certificate the server presents.
+	servedSerial := func() string {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots})
+		if err != nil {
+			return ""
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.String()
+	}
+	require.Eventually(t, func() bool { return servedSerial() == oldCert.cert.SerialNumber.String() }, 2*time.Second, 10*time.Millisecond)
+
+	// A malformed file is logged and the previous certificate kept
+	require.NoError(t, os.WriteFile(certFile, []byte("-----BEGIN CERTIFICATE-----\ngarbage\n"), 0o600))
+	time.Sleep(100 * time.Millisecond) // Several reload intervals
+	assert.Equal(t, oldCert.cert.SerialNumber.String(), servedSerial())
+
+	newCert := newServerCert()
+	newCert.writePEM(t, certFile, keyFile)
+	assert.Eventually(t, func() bool { return servedSerial() == newCert.cert.SerialNumber.String() }, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,86 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gate4ai/mcp/shared/config"
+)
+
+// ClientCertAuthenticationManager is implemented by authentication managers that also
+// authenticate requests by their verified TLS client certificate.
+type ClientCertAuthenticationManager interface {
+	// AuthenticateClientCert returns the user the certificate identities are mapped to
+	// and the session parameters, like Authenticate does for an authorization key.
+	AuthenticateClientCert(cert *x509.Certificate, remoteAddr string) (userID string, sessionParams *sync.Map, err error)
+}
+
+var _ ClientCertAuthenticationManager = (*Authenticator)(nil)
+
+// CertIdentities returns the identities users are mapped by in the configuration: the
+// subject common name followed by the DNS, URI and email SANs of the certificate.
+func CertIdentities(cert *x509.Certificate) []string {
+	var identities []string
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	identities = append(identities, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	return append(identities, cert.EmailAddresses...)
+}
+
+// verifiedClientCert returns the verified client certificate of the request, nil if the
+// request is not over TLS or the client presented none.
+func verifiedClientCert(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// clientAuthTLSConfig sets up tlsConfig, or a new config if it is nil, to request
+// client certificates and verify them against the CA bundle of SSLClientCAFile. They
+// are required with config.AuthorizedByClientCert and optional otherwise. tlsConfig is
+// returned unchanged if no bundle is configured.
+func clientAuthTLSConfig(tlsConfig *tls.Config, cfg config.IConfig) (*tls.Config, error) {
+	authType, err := cfg.AuthorizationType()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorization type: %w", err)
+	}
+	caFile, err := cfg.SSLClientCAFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client CA file: %w", err)
+	}
+	if caFile == "" {
+		if authType == config.AuthorizedByClientCert {
+			return nil, errors.New("mtls authorization requires a client CA bundle (config key 'ssl_client_ca_file')")
+		}
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("client CA file '%s' contains no PEM certificates", caFile)
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if authType == config.AuthorizedByClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,241 @@
+package transport_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/mcp/capability"
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared/config"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/privacy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testCert is a certificate and its key, signed by parent or self-signed if parent is nil.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, parent *testCert, template *x509.Certificate) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func newTestCA(t *testing.T, name string) *testCert {
+	return newTestCert(t, nil, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	})
+}
+
+func newTestClientCert(t *testing.T, ca *testCert, commonName string, uris ...string) *testCert {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	}
+	for _, raw := range uris {
+		uri, err := url.Parse(raw)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, uri)
+	}
+	return newTestCert(t, ca, template)
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+// writePEM writes the certificate followed by the chain and, if keyFile is set, its key
+// to PEM files.
+func (c *testCert) writePEM(t *testing.T, certFile string, keyFile string, chain ...*testCert) {
+	t.Helper()
+	var certs []byte
+	for _, cert := range append([]*testCert{c}, chain...) {
+		certs = append(certs, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.cert.Raw})...)
+	}
+	require.NoError(t, os.WriteFile(certFile, certs, 0o600))
+	if keyFile != "" {
+		der, err := x509.MarshalECPrivateKey(c.key)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+	}
+}
+
+func TestCertIdentities(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "svc-a"},
+		DNSNames:       []string{"svc-a.mesh.local"},
+		URIs:           []*url.URL{{Scheme: "spiffe", Host: "mesh", Path: "/ns/default/sa/svc-a"}},
+		EmailAddresses: []string{"ops@example.com"},
+	}
+	assert.Equal(t, []string{"svc-a", "svc-a.mesh.local", "spiffe://mesh/ns/default/sa/svc-a", "ops@example.com"}, transport.CertIdentities(cert))
+}
+
+func TestMTLSAuthorization(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "mesh CA")
+	serverCert := newTestCert(t, ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "gateway"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	})
+	caFile, certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	ca.writePEM(t, caFile, "")
+	serverCert.writePEM(t, certFile, keyFile)
+
+	logger := zap.NewNop()
+	cfg := config.NewInternalConfig()
+	cfg.ServerNameValue = "TestServer"
+	cfg.AuthorizationTypeValue = config.AuthorizedByClientCert
+	cfg.SSLEnabledValue = true
+	cfg.SSLCertFileValue = certFile
+	cfg.SSLKeyFileValue = keyFile
+	cfg.SSLClientCAFileValue = caFile
+	cfg.SetUserCertIdentity("spiffe://mesh/ns/default/sa/svc-a", "svc-a")
+
+	mockManager := NewMockMCPManager(cfg, logger)
+	defer mockManager.CloseAllSessions()
+	mockManager.AddCapability(capability.NewBase(logger, mockManager), &MockTestCapability{})
+	tp, err := transport.New(mockManager, logger, cfg)
+	require.NoError(t, err)
+	tp.SetAuthManager(transport.NewAuthenticator(cfg, logger))
+	tp.NoStream2025 = true
+	mux := http.NewServeMux()
+	tp.RegisterHandlers(mux)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, _, err := transport.StartHTTPServer(ctx, logger, cfg, mux, addr)
+	require.NoError(t, err)
+	defer server.Shutdown(context.Background())
+	require.Equal(t, tls.RequireAndVerifyClientCert, server.TLSConfig.ClientAuth)
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	initialize := func(clientCert *testCert, authKey string) (*http.Response, error) {
+		tlsConfig := &tls.Config{RootCAs: roots}
+		if clientCert != nil {
+			tlsConfig.Certificates = []tls.Certificate{clientCert.tlsCertificate()}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		body := createJsonRpcRequestBody(1, "initialize", schema2025.InitializeRequestParams{
+			ProtocolVersion: schema2025.PROTOCOL_VERSION,
+			ClientInfo:      schema2025.Implementation{Name: "test-client", Version: "1.0"},
+		})
+		req, err := http.NewRequest(http.MethodPost, "https://"+addr+transport.PATH, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if authKey != "" {
+			req.Header.Set("Authorization", "Bearer "+authKey)
+		}
+		return client.Do(req)
+	}
+
+	t.Run("mapped certificate", func(t *testing.T) {
+		resp, err := initialize(newTestClientCert(t, ca, "svc-a", "spiffe://mesh/ns/default/sa/svc-a"), "")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		sessionID := resp.Header.Get(transport.MCP_SESSION_HEADER)
+		session, err := mockManager.GetSession(sessionID)
+		require.NoError(t, err, "Session %q not found", sessionID)
+		assert.Equal(t, "svc-a", transport.GetUserId(session.GetParams()))
+	})
+
+	t.Run("unmapped certificate", func(t *testing.T) {
+		resp, err := initialize(newTestClientCert(t, ca, "svc-b"), "")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("no certificate", func(t *testing.T) {
+		_, err := initialize(nil, "some-key")
+		assert.Error(t, err, "Expected the TLS handshake to fail without a client certificate")
+	})
+
+	t.Run("certificate of another CA", func(t *testing.T) {
+		rogue := newTestCA(t, "rogue CA")
+		_, err := initialize(newTestClientCert(t, rogue, "svc-a", "spiffe://mesh/ns/default/sa/svc-a"), "")
+		assert.Error(t, err, "Expected the TLS handshake to fail with an unknown issuer")
+	})
+}
+
+func TestStartHTTPServer_MTLSRequiresSSL(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	cfg.SetListenAddr("localhost:0")
+	cfg.AuthorizationTypeValue = config.AuthorizedByClientCert
+
+	_, _, err := transport.StartHTTPServer(context.Background(), zap.NewNop(), cfg, createDummyMux(), "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mtls authorization requires SSL")
+}
+
+func TestUnmappedClientCertSubjectIsRedacted(t *testing.T) {
+	cfg := config.NewInternalConfig()
+	cfg.AuthorizationTypeValue = config.AuthorizedByClientCert
+	core, logs := observer.New(zap.InfoLevel)
+	authenticator := transport.NewAuthenticator(cfg, privacy.WrapLogger(zap.New(core), config.LogPrivacyStrict))
+
+	ca := newTestCA(t, "test CA")
+	_, _, err := authenticator.AuthenticateClientCert(newTestClientCert(t, ca, "alice@example.com").cert, "127.0.0.1:1234")
+	require.Error(t, err)
+
+	entries := logs.FilterMessageSnippet("not mapped to a user").All()
+	require.Len(t, entries, 1)
+	subject, ok := entries[0].ContextMap()["subject"].(string)
+	require.True(t, ok, "Expected the subject field to be logged")
+	assert.NotContains(t, subject, "alice@example.com")
+}
@@ -0,0 +1,113 @@
+package transport
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+)
+
+// Content codings of compressed responses
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// acceptedEncoding returns the content coding a response to r is compressed with: gzip
+// or deflate, whichever the Accept-Encoding header of the client prefers (gzip on a
+// tie), or "" if it accepts neither.
+func acceptedEncoding(r *http.Request) string {
+	qualities := map[string]float64{}
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, entry := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(entry, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			q := 1.0
+			if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil {
+					continue
+				}
+				q = parsed
+			}
+			qualities[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		q, ok := qualities[coding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressionMinSize returns the size from which JSON responses are compressed, or -1
+// if compression is disabled.
+func (t *Transport) compressionMinSize(logger *zap.Logger) int {
+	size, err := t.config.CompressionMinSize()
+	if err != nil {
+		logger.Error("Failed to get compression min size from config, using the default", zap.Error(err))
+		return config.DefaultCompressionMinSize
+	}
+	switch {
+	case size < 0:
+		return -1
+	case size == 0:
+		return config.DefaultCompressionMinSize
+	}
+	return size
+}
+
+// writeJSON writes data as the JSON body of a response with statusCode. The body is
+// compressed if the client accepts gzip or deflate and it is at least
+// compressionMinSize bytes. SSE streams are never written through it: compressing them
+// would hold back events until the compressor flushes.
+func (t *Transport) writeJSON(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}, logger *zap.Logger) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("Failed to encode JSON response", zap.Error(err))
+		http.Error(w, `{"jsonrpc":"2.0", "error":{"code":-32603, "message":"Internal server error writing response"}}`, http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.Header().Add("Vary", "Accept-Encoding")
+	encoding := acceptedEncoding(r)
+	minSize := t.compressionMinSize(logger)
+	if encoding == "" || minSize < 0 || len(body) < minSize {
+		w.WriteHeader(statusCode)
+		if _, err := w.Write(body); err != nil {
+			logger.Debug("Failed to write JSON response", zap.Error(err))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(statusCode)
+	var compressor io.WriteCloser
+	if encoding == encodingGzip {
+		compressor = gzip.NewWriter(w)
+	} else {
+		compressor = zlib.NewWriter(w)
+	}
+	if _, err := compressor.Write(body); err != nil {
+		logger.Debug("Failed to write compressed JSON response", zap.Error(err))
+	}
+	if err := compressor.Close(); err != nil {
+		logger.Debug("Failed to write compressed JSON response", zap.Error(err))
+	}
+}
@@ -13,13 +13,20 @@ import (
 const (
 	sseEventEndpoint = "endpoint"
 	sseEventMessage  = "message"
-	sseEventPing     = "ping"
+	sseEventClose    = "close" // Last event of a stream closed by the server shutting down
 )
 
 // It handles V2024 initialization via SSE endpoint event and
 // V2024 persistent SSE stream opening on GET request.
 func (t *Transport) handle2024GET(w http.ResponseWriter, r *http.Request, logger *zap.Logger) {
 	logger = logger.With(zap.String("method", "handle2024GET"))
+	if !t.acquireStreamSlot(w, r, logger) {
+		return
+	}
+	defer t.releaseStreamSlot()
+	span := t.startStreamSpan(r)
+	defer span.End(nil)
+
 	session, err := t.getSession(w, r, logger, true)
 	if err != nil {
 		logger.Error("Failed to get session", zap.Error(err))
@@ -48,20 +55,41 @@ func (t *Transport) handle2024GET(w http.ResponseWriter, r *http.Request, logger
 		http.Error(w, "Streaming unsupported", statusInternalServerError)
 		return
 	}
+	stream := newCountingStream(w)
+	w = stream
+	defer t.logStream(session, r, stream)
 
 	// Send the mandatory 'endpoint' event for V2024
 	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", "endpoint-event-id", sseEventEndpoint, endpointPath)
+	stream.events++
 	flusher.Flush()
 	logger.Debug("Sent V2024 endpoint event", zap.String("sessionId", session.GetID()), zap.String("endpoint", endpointPath))
 
 	session.SetStatus(shared.StatusConnected)
 	logger.Info("Session status set to Connected", zap.String("sessionId", session.GetID()))
 
-	ticker := time.NewTicker(15 * time.Second) // Keepalive ticker
+	ticker := time.NewTicker(t.sseKeepAlive(logger))
 	defer ticker.Stop()
 	defer logger.Debug("Stopped forwarding session output to V2024 SSE stream", zap.String("sessionId", session.GetID()))
 
+	writeMessage := func(msg *shared.Message) {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			logger.Error("Failed to marshal message for SSE", zap.Error(err), zap.Any("msgId", msg.ID), zap.Stringp("method", msg.Method))
+			return // Skip message if marshalling fails
+		}
+
+		// Send as 'message' event
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", time.Now().UnixNano(), sseEventMessage, data)
+		stream.events++
+		flusher.Flush()
+		session.UpdateLastActivity()
+	}
+
+	drained := t.drained(r, session)
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		for {
 			select {
 			case <-r.Context().Done():
@@ -73,37 +101,41 @@ func (t *Transport) handle2024GET(w http.ResponseWriter, r *http.Request, logger
 					logger.Info("Session output channel closed", zap.String("sessionId", session.GetID()))
 					return
 				}
-				if msg == nil {
-					continue
+				if msg != nil {
+					writeMessage(msg)
 				}
-
-				data, err := json.Marshal(msg)
-				if err != nil {
-					logger.Error("Failed to marshal message for SSE", zap.Error(err), zap.Any("msgId", msg.ID), zap.Stringp("method", msg.Method))
-					continue // Skip message if marshalling fails
+			case <-drained:
+				// The responses to the last requests may still be waiting in the output
+				for pending := true; pending; {
+					select {
+					case msg, ok := <-output:
+						if pending = ok; ok && msg != nil {
+							writeMessage(msg)
+						}
+					default:
+						pending = false
+					}
 				}
-
-				// Send as 'message' event
-				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", time.Now().UnixNano(), sseEventMessage, data)
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseEventClose, "server shutting down")
+				stream.events++
 				flusher.Flush()
-				session.UpdateLastActivity()
+				logger.Info("Closed V2024 SSE stream, server shutting down", zap.String("sessionId", session.GetID()))
+				return
 			case <-ticker.C:
-				// Send keepalive ping event
 				// Double-check context before sending keepalive to avoid race condition on disconnect
 				select {
 				case <-r.Context().Done():
 					// Context was canceled, exit the loop silently
 					return
 				default:
-					// Context still active, send keepalive ping event
-					fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseEventPing, `{}`) // V2024 might not use ID for pings
-					flusher.Flush()
+					writeKeepAlive(w, flusher)
 				}
 			}
 		}
 	}()
 
-	// Keep the handler alive while the goroutine runs.
-	// The client disconnecting will cancel the request context.
-	<-r.Context().Done()
+	// Keep the handler alive while the goroutine runs, so it never writes to the
+	// response after the handler returned. The client disconnecting will cancel the
+	// request context.
+	<-done
 }
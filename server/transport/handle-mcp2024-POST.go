@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/tracing"
 	"go.uber.org/zap"
 )
 
@@ -42,6 +43,7 @@ func (t *Transport) handle2024POST(w http.ResponseWriter, r *http.Request, logge
 	for _, msg := range msgs {
 		msg.Session = session
 		msg.Timestamp = time.Now()
+		msg.TraceParent = r.Header.Get(tracing.TraceparentHeader)
 		if handleErr := session.Input().Put(msg); handleErr != nil {
 			logger.Error("Error handling message in V2024 POST", zap.Error(handleErr), zap.String("sessionId", session.GetID()), zap.Any("msgId", msg.ID))
 			// V2024 POST doesn't have a standard way to return errors for individual messages here.
@@ -2,6 +2,7 @@ package transport
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/gate4ai/mcp/shared"
 	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/tracing"
 	"go.uber.org/zap"
 )
 
@@ -37,7 +39,11 @@ func (t *Transport) handlePOST(w http.ResponseWriter, r *http.Request, logger *z
 	}
 	defer r.Body.Close()
 
-	msgs, err := shared.ParseMessages(session, bodyBytes)
+	msgs, slots, batch, err := parseBody(session, bodyBytes)
+	if errors.Is(err, errEmptyBatch) {
+		sendJSONRPCErrorResponse(w, nil, shared.JSONRPCErrorInvalidRequest, "Invalid Request", err.Error(), logger)
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to parse JSON-RPC message(s)", zap.Error(err), zap.ByteString("body", bodyBytes))
 		sendJSONRPCErrorResponse(w, nil, shared.JSONRPCErrorParseError, "Invalid JSON", err.Error(), logger)
@@ -68,15 +74,38 @@ func (t *Transport) handlePOST(w http.ResponseWriter, r *http.Request, logger *z
 		clientAcceptsSSE = true
 	}
 
+	// Reserve an SSE stream slot before any message is processed, so a rejected
+	// request can be safely retried by the client
+	traceParent := r.Header.Get(tracing.TraceparentHeader)
+	if clientAcceptsSSE && containsRequest(msgs) {
+		if !t.acquireStreamSlot(w, r, logger) {
+			return
+		}
+		defer t.releaseStreamSlot()
+		// The requests answered on the stream are traced as its children
+		span := t.startStreamSpan(r)
+		defer span.End(nil)
+		if span != nil {
+			traceParent = span.Traceparent()
+		}
+	}
+
 	// Determine message types in the batch
 	hasError := false
 	var requestIDs []*schema.RequestID // Store request IDs for potential use later
-	for _, msg := range msgs {
+	for i, msg := range msgs {
+		// The requests batched with initialize are handled once the session is initialized
+		if isInitializeRequest && i == 1 && !awaitInitialize(r, session) {
+			logger.Warn("Session not initialized by the initialize request of the batch", zap.String("sessionId", session.GetID()))
+			rejectRequests(slots, msgs[i:], "Session not initialized")
+			break
+		}
 		msg.Session = session
 		msg.Timestamp = time.Now()
+		msg.TraceParent = traceParent
 
 		// Check if this is a request (has ID and Method)
-		if msg.Method != nil && msg.ID != nil && !msg.ID.IsEmpty() {
+		if isRequestMessage(msg) {
 			requestIDs = append(requestIDs, msg.ID) // Store original request ID
 		} // Don't store IDs for notifications or responses
 
@@ -88,6 +117,14 @@ func (t *Transport) handlePOST(w http.ResponseWriter, r *http.Request, logger *z
 		}
 	}
 
+	// Invalid elements of a batch are answered with the responses to its requests
+	invalid := invalidResponses(slots)
+	if len(requestIDs) == 0 && len(invalid) > 0 {
+		w.Header().Set(MCP_SESSION_HEADER, session.GetID())
+		t.writeJSON(w, r, http.StatusOK, invalid, logger)
+		return
+	}
+
 	// If the input consists solely of notifications or there are no messages expecting responses, return 202 Accepted
 	if len(requestIDs) == 0 {
 		w.WriteHeader(http.StatusAccepted)
@@ -106,15 +143,16 @@ func (t *Transport) handlePOST(w http.ResponseWriter, r *http.Request, logger *z
 
 	// Decide whether to respond with JSON or SSE
 	if clientAcceptsSSE {
-		t.responseToStream(w, r, session, logger, requestIDs) // Keep stream open
+		t.responseToStream(w, r, session, logger, requestIDs, invalid) // Keep stream open
 		logger.Info("SSE connection handler finished", zap.String("sessionId", session.GetID()))
 	} else {
-		t.responseAndCloseConnection(w, r, session, logger, requestIDs)
+		t.responseAndCloseConnection(w, r, session, logger, requestIDs, slots, batch)
 	}
 }
 
-// responseAndCloseConnection handles sending JSON response for V2025 POST requests.
-func (t *Transport) responseAndCloseConnection(w http.ResponseWriter, r *http.Request, session shared.ISession, logger *zap.Logger, requestIDs []*schema.RequestID) {
+// responseAndCloseConnection handles sending JSON response for V2025 POST requests. The
+// responses to a batch are sent as an array in the order of slots.
+func (t *Transport) responseAndCloseConnection(w http.ResponseWriter, r *http.Request, session shared.ISession, logger *zap.Logger, requestIDs []*schema.RequestID, slots []batchSlot, batch bool) {
 	// Set necessary headers
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -126,21 +164,27 @@ func (t *Transport) responseAndCloseConnection(w http.ResponseWriter, r *http.Re
 	}
 
 	// Collect responses until all are received or timeout
-	responses := make([]interface{}, 0)
+	responses := make(map[string]interface{}) // Request ID -> response
+	pending := make(map[string]bool)
+	for _, id := range requestIDs {
+		pending[id.String()] = true
+	}
 	responseTimer := time.NewTimer(responseTimeout) // Use a timer for better control
 	defer responseTimer.Stop()
 
-	output, ok := session.AcquireOutput()
-	if !ok {
-		logger.Error("Failed to acquire output channel", zap.String("sessionId", session.GetID()))
+	waiter, err := t.waitForResponses(session, requestIDs, false)
+	if err != nil {
+		logger.Error("Failed to wait for responses", zap.String("sessionId", session.GetID()), zap.Error(err))
+		sendJSONRPCErrorResponse(w, nil, shared.JSONRPCErrorInternal, "Failed to wait for responses", err.Error(), logger)
+		return
 	}
-	defer session.ReleaseOutput()
+	defer t.stopWaiting(session, waiter)
 
 	// Collect responses loop
 collectLoop:
 	for {
 		select {
-		case respMsg, ok := <-output:
+		case respMsg, ok := <-waiter.messages:
 			if !ok {
 				logger.Info("Session output channel closed", zap.String("sessionId", session.GetID()))
 				break collectLoop
@@ -151,14 +195,19 @@ collectLoop:
 				continue
 			}
 
+			msgID := respMsg.ID.String()
+			if !pending[msgID] {
+				continue
+			}
+			delete(pending, msgID)
 			if respMsg.Error != nil {
 				// For error responses, add error response
 				logger.Debug("Adding error response", zap.Any("msgId", respMsg.ID), zap.Error(respMsg.Error))
-				responses = append(responses, shared.JSONRPCErrorResponse{
+				responses[msgID] = shared.JSONRPCErrorResponse{
 					JSONRPC: "2.0",
 					ID:      respMsg.ID,
 					Error:   respMsg.Error,
-				})
+				}
 			} else {
 				// For successful responses, marshal Result to RawMessage for proper JSON handling
 				var resultRaw json.RawMessage
@@ -173,24 +222,24 @@ collectLoop:
 				}
 
 				if err == nil {
-					responses = append(responses, shared.JSONRPCResponse{
+					responses[msgID] = shared.JSONRPCResponse{
 						JSONRPC: "2.0",
 						ID:      respMsg.ID,
 						Result:  &resultRaw, // Always use a non-nil Result pointer
-					})
+					}
 				} else {
 					logger.Error("Failed to unmarshal response payload", zap.Error(err))
 					// Create error response
-					responses = append(responses, shared.JSONRPCErrorResponse{
+					responses[msgID] = shared.JSONRPCErrorResponse{
 						JSONRPC: "2.0",
 						ID:      respMsg.ID,
 						Error:   &shared.JSONRPCError{Code: shared.JSONRPCErrorInternal, Message: "Failed to unmarshal response payload"},
-					})
+					}
 				}
 			}
 
 			// If we have collected all the expected responses, we can send them immediately
-			if len(responses) >= len(requestIDs) {
+			if len(pending) == 0 {
 				break collectLoop
 			}
 
@@ -200,29 +249,33 @@ collectLoop:
 
 		case <-r.Context().Done(): // Client disconnected while waiting
 			logger.Warn("Client disconnected while waiting for response", zap.String("sessionId", session.GetID()))
+			cancelPending(session, requestIDs, pending)
 			return // Stop processing
 		}
 	}
 
-	// Send responses
-	w.WriteHeader(http.StatusOK)
-
-	// Check if it was a single request or a batch
-	if len(requestIDs) == 1 && len(responses) == 1 {
-		// Encode single response directly
-		if err := json.NewEncoder(w).Encode(responses[0]); err != nil {
-			logger.Error("Failed to encode single response", zap.Error(err))
+	// Send responses, a single request gets its response directly and a batch the array
+	// of responses in request order. Requests not answered in time are left out.
+	ordered := make([]interface{}, 0, len(slots))
+	for _, slot := range slots {
+		if slot.invalid != nil {
+			ordered = append(ordered, slot.invalid)
+		} else if response, ok := responses[slot.requestID.String()]; ok {
+			ordered = append(ordered, response)
 		}
+	}
+	if !batch && len(ordered) == 1 {
+		t.writeJSON(w, r, http.StatusOK, ordered[0], logger)
 	} else {
-		// Encode the batch (slice) of responses
-		if err := json.NewEncoder(w).Encode(responses); err != nil {
-			logger.Error("Failed to encode batch response", zap.Error(err))
-		}
+		t.writeJSON(w, r, http.StatusOK, ordered, logger)
 	}
 }
 
-// responseToStream handles streaming responses via SSE for V2025 POST requests.
-func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, session shared.ISession, logger *zap.Logger, requestIDs []*schema.RequestID) {
+// responseToStream handles streaming responses via SSE for V2025 POST requests. The
+// stream stays open until every request of the POST got its response, however long
+// that takes; notifications and requests of the server are sent on it meanwhile. The
+// errors answering invalid elements of a batch are sent first.
+func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, session shared.ISession, logger *zap.Logger, requestIDs []*schema.RequestID, invalid []*shared.JSONRPCErrorResponse) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		logger.Error("Streaming unsupported for SSE", zap.String("sessionId", session.GetID()))
@@ -238,6 +291,17 @@ func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, ses
 		}
 	}
 
+	waiter, err := t.waitForResponses(session, requestIDs, true)
+	if err != nil {
+		logger.Error("Failed to wait for responses", zap.String("sessionId", session.GetID()), zap.Error(err))
+		sendJSONRPCErrorResponse(w, nil, shared.JSONRPCErrorInternal, "Failed to wait for responses", err.Error(), logger)
+		return
+	}
+	defer t.stopWaiting(session, waiter)
+	stream := newCountingStream(w)
+	w = stream
+	defer t.logStream(session, r, stream)
+
 	// Prepare SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -245,35 +309,35 @@ func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, ses
 	w.Header().Set("Access-Control-Allow-Origin", "*") // Consider restricting this
 	w.Header().Set(MCP_SESSION_HEADER, session.GetID())
 	w.WriteHeader(http.StatusOK)
+	eventID := time.Now().UnixNano() // Initial event ID for resumability
+	for _, response := range invalid {
+		eventData, err := json.Marshal(response)
+		if err != nil {
+			logger.Error("Failed to marshal SSE event data", zap.Error(err))
+			continue
+		}
+		fmt.Fprintf(w, "id: %d\ndata: %s\n\n", eventID, eventData)
+		eventID++
+		stream.events++
+	}
 	flusher.Flush()
 
-	ticker := time.NewTicker(15 * time.Second) // Keepalive ticker // TODO: Make configurable
+	ticker := time.NewTicker(t.sseKeepAlive(logger))
 	defer ticker.Stop()
 	defer logger.Debug("Exiting responseToStream goroutine", zap.String("sessionId", session.GetID()))
 
 	closeSSE := make(chan struct{})
-	timeout := time.After(3 * time.Second) // Timeout after 3 seconds
-
-	output, ok := session.AcquireOutput()
-	if !ok {
-		logger.Error("Failed to acquire output channel", zap.String("sessionId", session.GetID()))
-	}
-	defer session.ReleaseOutput()
 
 	go func() {
 		defer close(closeSSE)
 		ctx := r.Context() // Store the context for checking cancellation
 
-		eventID := time.Now().UnixNano() // Initial event ID for resumability
 		for {
 			select {
 			case <-ctx.Done(): // Use the handler's context for cancellation
 				logger.Info("responseToStream context cancelled", zap.String("sessionId", session.GetID()))
 				return
-			case <-timeout:
-				logger.Warn("Timeout waiting for responses", zap.String("sessionId", session.GetID()))
-				return
-			case msg, ok := <-output:
+			case msg, ok := <-waiter.messages:
 				if !ok {
 					logger.Info("Session output channel closed", zap.String("sessionId", session.GetID()))
 					return
@@ -284,7 +348,19 @@ func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, ses
 				}
 
 				// Process the message based on ID
-				if msg.ID != nil {
+				if msg.Method != nil {
+					// A notification or request of the server while requests are pending,
+					// e.g. their progress
+					eventData, err := json.Marshal(msg)
+					if err != nil {
+						logger.Error("Failed to marshal SSE message", zap.Error(err))
+						continue
+					}
+					fmt.Fprintf(w, "id: %d\ndata: %s\n\n", eventID, eventData)
+					eventID++
+					stream.events++
+					flusher.Flush()
+				} else if msg.ID != nil {
 					// Check if this is expected response
 					msgID := msg.ID.String()
 					if _, expected := pendingRequests[msgID]; expected {
@@ -335,6 +411,7 @@ func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, ses
 						// Send event with incrementing ID for potential resumption
 						fmt.Fprintf(w, "id: %d\ndata: %s\n\n", eventID, eventData)
 						eventID++
+						stream.events++
 						flusher.Flush()
 
 						// Remove from pending requests
@@ -348,15 +425,20 @@ func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, ses
 
 					return
 				}
+			case <-t.forcing:
+				// The server stopped waiting for the requests during its shutdown
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseEventClose, "server shutting down")
+				stream.events++
+				flusher.Flush()
+				logger.Warn("Closed SSE stream with requests still running, server shutting down", zap.String("sessionId", session.GetID()), zap.Int("pending", len(pendingRequests)))
+				return
 			case <-ticker.C:
-				// Send keepalive ping event
 				// Check context again before sending
 				select {
 				case <-ctx.Done():
 					return
 				default:
-					fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseEventPing, `{}`)
-					flusher.Flush()
+					writeKeepAlive(w, flusher)
 				}
 			}
 		}
@@ -370,9 +452,33 @@ func (t *Transport) responseToStream(w http.ResponseWriter, r *http.Request, ses
 	case <-closeSSE:
 		logger.Info("SSE response goroutine finished", zap.String("sessionId", session.GetID()))
 	}
+	<-closeSSE // Never write to the response after the handler returned
+	if r.Context().Err() != nil {
+		cancelPending(session, requestIDs, pendingRequests)
+	}
 	logger.Debug("responseToStream handler returning", zap.String("sessionId", session.GetID()))
 }
 
+// cancelPending cancels the requests of a POST whose client went away before they were
+// answered: nobody is left to receive their responses.
+func cancelPending(session shared.ISession, requestIDs []*schema.RequestID, pending map[string]bool) {
+	for _, id := range requestIDs {
+		if pending[id.String()] {
+			session.Input().CancelRequest(session.GetID(), id)
+		}
+	}
+}
+
+// containsRequest reports whether any of the messages expects a response.
+func containsRequest(msgs []*shared.Message) bool {
+	for _, msg := range msgs {
+		if isRequestMessage(msg) {
+			return true
+		}
+	}
+	return false
+}
+
 // extractAuthKey tries to get the auth key from Header or Query params.
 func (t *Transport) extractAuthKey(r *http.Request) string {
 	// Try Authorization header first
@@ -0,0 +1,178 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"go.uber.org/zap"
+)
+
+const (
+	// Default interval of pings on WebSocket connections; a client silent for two
+	// intervals, not even answering the pings, is disconnected
+	defaultWebSocketPingInterval = 15 * time.Second
+	// Max wait for the client to answer the close frame of the server
+	wsCloseTimeout = time.Second
+)
+
+// handleWebSocket serves MCP over a WebSocket. Every text message of the client holds a
+// JSON-RPC message or batch, as the body of a POST does, and the responses, notifications
+// and requests of the server are sent back as text messages. Like a V2024 SSE stream the
+// connection owns the output of its session, and the session is closed with it.
+func (t *Transport) handleWebSocket(w http.ResponseWriter, r *http.Request, logger *zap.Logger) {
+	logger = logger.With(zap.String("method", "handleWebSocket"))
+	if !isWebSocketUpgrade(r) {
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, "Upgrade Required: expected a WebSocket handshake", http.StatusUpgradeRequired)
+		return
+	}
+	if !t.acquireStreamSlot(w, r, logger) {
+		return
+	}
+	defer t.releaseStreamSlot()
+
+	session, err := t.getSession(w, r, logger, true)
+	if err != nil {
+		logger.Error("Failed to get session", zap.Error(err))
+		return
+	}
+	defer t.sessionManager.CloseSession(session.GetID())
+	logger = logger.With(zap.String("sessionId", session.GetID()))
+
+	output, ok := session.AcquireOutput()
+	if !ok {
+		logger.Error("Failed to acquire output channel for WebSocket")
+		http.Error(w, "Conflict: the session is in use by another connection", http.StatusConflict)
+		return
+	}
+	defer session.ReleaseOutput()
+
+	conn, err := upgradeWebSocket(w, r, http.Header{MCP_SESSION_HEADER: {session.GetID()}})
+	if err != nil {
+		logger.Warn("WebSocket handshake failed", zap.Error(err))
+		return
+	}
+	defer conn.close()
+	conn.readWait = 2 * t.wsPingInterval
+	logger.Info("WebSocket connected")
+
+	readDone := make(chan error, 1)
+	go func() {
+		readDone <- t.readWebSocket(conn, session, logger)
+	}()
+	// closeGracefully sends a close frame and waits a little for the client to answer it.
+	closeGracefully := func(code int, reason string) {
+		if err := conn.writeClose(code, reason); err != nil {
+			return
+		}
+		select {
+		case <-readDone:
+		case <-time.After(wsCloseTimeout):
+		}
+	}
+
+	writeMessage := func(msg *shared.Message) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			logger.Error("Failed to marshal message for WebSocket", zap.Error(err), zap.Any("msgId", msg.ID), zap.Stringp("method", msg.Method))
+			return nil
+		}
+		if err := conn.writeFrame(wsOpText, data); err != nil {
+			logger.Warn("Failed to write to WebSocket", zap.Error(err))
+			return err
+		}
+		session.UpdateLastActivity()
+		return nil
+	}
+
+	drained := t.drained(r, session)
+	ticker := time.NewTicker(t.wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			logger.Info("Closing WebSocket, server shutting down")
+			closeGracefully(wsCloseGoingAway, "server shutting down")
+			return
+		case err := <-readDone:
+			if errors.Is(err, io.EOF) {
+				logger.Info("WebSocket closed by client")
+			} else {
+				logger.Info("WebSocket connection lost", zap.Error(err))
+			}
+			return
+		case msg, ok := <-output:
+			if !ok {
+				logger.Info("Session output channel closed, closing WebSocket")
+				closeGracefully(wsCloseNormal, "session closed")
+				return
+			}
+			if msg == nil {
+				continue
+			}
+			if writeMessage(msg) != nil {
+				return
+			}
+		case <-drained:
+			// The responses to the last requests may still be waiting in the output
+			for pending := true; pending; {
+				select {
+				case msg, ok := <-output:
+					if pending = ok; ok && msg != nil && writeMessage(msg) != nil {
+						return
+					}
+				default:
+					pending = false
+				}
+			}
+			logger.Info("Closing WebSocket, server shutting down")
+			closeGracefully(wsCloseGoingAway, "server shutting down")
+			return
+		case <-ticker.C:
+			if err := conn.writeFrame(wsOpPing, nil); err != nil {
+				logger.Warn("Failed to ping WebSocket", zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
+// readWebSocket passes the messages of the client to the session until the connection
+// is closed or fails. Messages that are not valid JSON-RPC are answered with a parse
+// error.
+func (t *Transport) readWebSocket(conn *wsConn, session shared.ISession, logger *zap.Logger) error {
+	for {
+		opcode, data, err := conn.readMessage()
+		if err != nil {
+			return err
+		}
+		if opcode != wsOpText {
+			conn.writeClose(wsCloseUnsupportedData, "only text messages are supported")
+			return errors.New("binary message received")
+		}
+
+		msgs, err := shared.ParseMessages(session, data)
+		if err != nil {
+			logger.Warn("Failed to parse JSON-RPC message(s) from WebSocket", zap.Error(err), zap.ByteString("data", data))
+			errResp, _ := json.Marshal(shared.JSONRPCErrorResponse{
+				JSONRPC: shared.JSONRPCVersion,
+				Error:   &shared.JSONRPCError{Code: shared.JSONRPCErrorParseError, Message: "Invalid JSON", Data: err.Error()},
+			})
+			if err := conn.writeFrame(wsOpText, errResp); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			msg.Session = session
+			msg.Timestamp = time.Now()
+			if err := session.Input().Put(msg); err != nil {
+				logger.Error("Error handling WebSocket message", zap.Error(err), zap.Any("msgId", msg.ID))
+			}
+		}
+	}
+}
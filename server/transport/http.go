@@ -59,7 +59,9 @@ func StartHTTPServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig
 	}
 
 	var tlsConfig *tls.Config
-	var certFile, keyFile string // Only for manual mode
+	var certs *certReloader  // Only for manual mode
+	var stapler *ocspStapler // Only for manual mode with OCSP stapling
+	var reloadInterval time.Duration
 	isACME := false
 
 	if sslEnabled {
@@ -103,17 +105,41 @@ func StartHTTPServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig
 
 		} else {
 			// --- Manual Mode ---
-			certFile, err = cfg.SSLCertFile()
+			certFile, err := cfg.SSLCertFile()
 			if err != nil || certFile == "" {
 				return nil, nil, fmt.Errorf("manual SSL mode requires a certificate file path (config key 'ssl_cert_file'): %w", err)
 			}
-			keyFile, err = cfg.SSLKeyFile()
+			keyFile, err := cfg.SSLKeyFile()
 			if err != nil || keyFile == "" {
 				return nil, nil, fmt.Errorf("manual SSL mode requires a private key file path (config key 'ssl_key_file'): %w", err)
 			}
-			// Manual mode doesn't require a specific tls.Config here, ListenAndServeTLS handles it
+			reloadInterval, err = cfg.SSLReloadInterval()
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get certificate reload interval: %w", err)
+			}
+			if reloadInterval <= 0 {
+				reloadInterval = config.DefaultSSLReloadInterval
+			}
+			// The certificate is loaded when the listener starts and reloaded when the files change
+			certs = newCertReloader(certFile, keyFile, logger)
+			tlsConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+			if stapling, _ := cfg.SSLOCSPStapling(); stapling {
+				stapler = newOCSPStapler(certs, logger)
+				tlsConfig.GetCertificate = stapler.GetCertificate
+			}
+		}
+		tlsConfig, err = tlsVersionConfig(tlsConfig, cfg)
+		if err != nil {
+			return nil, nil, err
 		}
-		server.TLSConfig = tlsConfig // Assign TLS config if ACME, nil otherwise for manual
+		// Request and verify client certificates if a client CA bundle is configured
+		tlsConfig, err = clientAuthTLSConfig(tlsConfig, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		server.TLSConfig = tlsConfig
+	} else if authType, _ := cfg.AuthorizationType(); authType == config.AuthorizedByClientCert {
+		return nil, nil, errors.New("mtls authorization requires SSL to be enabled (config key 'ssl_enabled')")
 	}
 
 	// Channel to report listener errors occurring *after* startup
@@ -125,13 +151,19 @@ func StartHTTPServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig
 
 		if sslEnabled {
 			logger.Info("Starting HTTPS Server", zap.String("addr", listenAddr), zap.Bool("isACME", isACME))
-			var err error
-			if isACME {
-				// ListenAndServeTLS handles challenges via TLSConfig if set
-				err = server.ListenAndServeTLS("", "")
-			} else {
-				err = server.ListenAndServeTLS(certFile, keyFile)
+			if !isACME {
+				if _, err := certs.load(); err != nil {
+					logger.Error("HTTPS Server listener error", zap.Error(err))
+					listenerErrChan <- err
+					return
+				}
+				go certs.run(ctx, reloadInterval)
+				if stapler != nil {
+					go stapler.run(ctx)
+				}
 			}
+			// The certificate is served by GetCertificate of TLSConfig, in ACME mode with challenges
+			err := server.ListenAndServeTLS("", "")
 			if err != nil && !errors.Is(err, http.ErrServerClosed) {
 				logger.Error("HTTPS Server listener error", zap.Error(err))
 				listenerErrChan <- err
@@ -152,6 +184,32 @@ func StartHTTPServer(ctx context.Context, logger *zap.Logger, cfg config.IConfig
 	return server, listenerErrChan, nil
 }
 
+// tlsVersionConfig sets the minimum TLS version and cipher suites of the configuration
+// on tlsConfig, or on a new config if it is nil and either is set.
+func tlsVersionConfig(tlsConfig *tls.Config, cfg config.IConfig) (*tls.Config, error) {
+	minVersion, err := cfg.SSLMinVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get minimum TLS version: %w", err)
+	}
+	cipherSuites, err := cfg.SSLCipherSuites()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TLS cipher suites: %w", err)
+	}
+	if err := config.ValidateTLSSettings(minVersion, cipherSuites); err != nil {
+		return nil, fmt.Errorf("invalid TLS settings (config keys 'ssl_min_version', 'ssl_cipher_suites'): %w", err)
+	}
+	if minVersion == "" && len(cipherSuites) == 0 {
+		return tlsConfig, nil
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.MinVersion, _ = config.TLSMinVersion(minVersion)
+	tlsConfig.CipherSuites, _ = config.TLSCipherSuites(cipherSuites)
+	return tlsConfig, nil
+}
+
 // ShutdownHTTPServer attempts a graceful shutdown of the HTTP server.
 func ShutdownHTTPServer(ctx context.Context, logger *zap.Logger, server *http.Server) {
 	if server == nil {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gate4ai/mcp/server/transport"
 	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -26,9 +27,9 @@ func createDummyMux() *http.ServeMux {
 
 func TestStartHTTPServer_HTTPMode(t *testing.T) {
 	logger := zap.NewNop()
-	cfg := config.NewInternalConfig() // Use in-memory config
-	cfg.SetListenAddr("localhost:0")  // Use ephemeral port
-	cfg.SSLEnabledValue = false
+	cfg := testutil.NewConfigBuilder().
+		WithServer("localhost:0", "test-server", "0.0.1"). // Use ephemeral port, without SSL
+		Build(t)
 
 	mux := createDummyMux()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -113,7 +114,7 @@ func TestStartHTTPServer_ACMEMode(t *testing.T) {
 
 func TestStartHTTPServer_MissingParameters(t *testing.T) {
 	t.Run("NilLogger", func(t *testing.T) {
-		cfg := config.NewInternalConfig()
+		cfg := testutil.NewConfigBuilder().Build(t)
 		mux := createDummyMux()
 		_, _, err := transport.StartHTTPServer(context.Background(), nil, cfg, mux, "")
 		assert.Error(t, err)
@@ -130,7 +131,7 @@ func TestStartHTTPServer_MissingParameters(t *testing.T) {
 
 	t.Run("NilHandler", func(t *testing.T) {
 		logger := zap.NewNop()
-		cfg := config.NewInternalConfig()
+		cfg := testutil.NewConfigBuilder().Build(t)
 		_, _, err := transport.StartHTTPServer(context.Background(), logger, cfg, nil, "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "http handler")
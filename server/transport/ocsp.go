@@ -0,0 +1,210 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// ocspRetryInterval is the wait before fetching again after a failed fetch.
+	ocspRetryInterval = 5 * time.Minute
+	// ocspDefaultValidity is how long a response without a next update time is stapled.
+	ocspDefaultValidity = time.Hour
+	// ocspMinRefresh bounds the wait between fetches for short-lived responses.
+	ocspMinRefresh = time.Minute
+	// ocspMaxResponseSize limits the bytes read from the responder.
+	ocspMaxResponseSize = 1 << 20
+)
+
+// ocspStapler serves the certificate of a certReloader with a stapled OCSP response. The
+// response is fetched from the OCSP responder named in the certificate and refreshed
+// halfway through its validity, or right away when the certificate is reloaded. While
+// no valid response is at hand, e.g. because the responder is unreachable or the
+// certificate names none, the certificate is served without a staple.
+type ocspStapler struct {
+	logger *zap.Logger
+	client *http.Client
+	certs  *certReloader
+
+	mu        sync.RWMutex
+	issuer    *x509.Certificate // Downloaded from the issuer URL of issuerOf
+	issuerOf  *tls.Certificate
+	stapled   *tls.Certificate // stapledOf with the last good response, nil if none
+	stapledOf *tls.Certificate
+	expires   time.Time // When the stapled response is no longer valid
+}
+
+func newOCSPStapler(certs *certReloader, logger *zap.Logger) *ocspStapler {
+	return &ocspStapler{
+		logger: logger,
+		client: &http.Client{Timeout: 10 * time.Second},
+		certs:  certs,
+	}
+}
+
+// GetCertificate serves the certificate for tls.Config.GetCertificate, with the OCSP
+// response stapled while it is valid.
+func (s *ocspStapler) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, err := s.certs.GetCertificate(hello)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stapledOf == cert && time.Now().Before(s.expires) {
+		return s.stapled, nil
+	}
+	return cert, nil
+}
+
+// run refreshes the OCSP response until ctx is done. It is started after the first
+// certificate was loaded.
+func (s *ocspStapler) run(ctx context.Context) {
+	for {
+		timer := time.NewTimer(s.refresh(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.certs.changed: // The new certificate needs its own response
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// refresh fetches a new OCSP response for the current certificate and returns the wait
+// until the next refresh. A failed fetch keeps the previous response, which is served
+// until it expires.
+func (s *ocspStapler) refresh(ctx context.Context) time.Duration {
+	cert := s.certs.current()
+	resp, raw, err := s.fetch(ctx, cert)
+	if err != nil {
+		s.logger.Warn("Failed to fetch OCSP response", zap.Strings("responders", cert.Leaf.OCSPServer), zap.Error(err))
+		return ocspRetryInterval
+	}
+
+	expires := resp.NextUpdate
+	if expires.IsZero() {
+		expires = resp.ThisUpdate.Add(ocspDefaultValidity)
+	}
+	stapled := *cert
+	stapled.OCSPStaple = raw
+	s.mu.Lock()
+	s.stapled = &stapled
+	s.stapledOf = cert
+	s.expires = expires
+	s.mu.Unlock()
+	s.logger.Debug("Stapled OCSP response", zap.Time("thisUpdate", resp.ThisUpdate), zap.Time("expires", expires))
+
+	wait := time.Until(resp.ThisUpdate.Add(expires.Sub(resp.ThisUpdate) / 2))
+	return max(wait, ocspMinRefresh)
+}
+
+// fetch requests the status of the certificate from its OCSP responder. Only responses
+// saying the certificate is good are returned.
+func (s *ocspStapler) fetch(ctx context.Context, cert *tls.Certificate) (*ocsp.Response, []byte, error) {
+	leaf := cert.Leaf
+	if len(leaf.OCSPServer) == 0 {
+		return nil, nil, errors.New("certificate names no OCSP responder")
+	}
+	issuer, err := s.getIssuer(ctx, cert)
+	if err != nil {
+		return nil, nil, err
+	}
+	reqBody, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create OCSP request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, leaf.OCSPServer[0], bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+	raw, err := s.get(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid OCSP response: %w", err)
+	}
+	switch resp.Status {
+	case ocsp.Good:
+	case ocsp.Revoked:
+		return nil, nil, fmt.Errorf("certificate was revoked at %s", resp.RevokedAt.Format(time.RFC3339))
+	default:
+		return nil, nil, errors.New("certificate status is unknown to the responder")
+	}
+	if !resp.NextUpdate.IsZero() && !time.Now().Before(resp.NextUpdate) {
+		return nil, nil, fmt.Errorf("OCSP response expired at %s", resp.NextUpdate.Format(time.RFC3339))
+	}
+	return resp, raw, nil
+}
+
+// getIssuer returns the issuer of the certificate from the chain in the certificate file
+// or, if the file has no chain, downloaded from the issuer URL of the certificate.
+func (s *ocspStapler) getIssuer(ctx context.Context, cert *tls.Certificate) (*x509.Certificate, error) {
+	if len(cert.Certificate) > 1 {
+		issuer, err := x509.ParseCertificate(cert.Certificate[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse issuer certificate: %w", err)
+		}
+		return issuer, nil
+	}
+	s.mu.RLock()
+	issuer, issuerOf := s.issuer, s.issuerOf
+	s.mu.RUnlock()
+	if issuerOf == cert {
+		return issuer, nil
+	}
+	if len(cert.Leaf.IssuingCertificateURL) == 0 {
+		return nil, errors.New("certificate file has no issuer certificate and the certificate names no issuer URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cert.Leaf.IssuingCertificateURL[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	der, err := s.get(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download issuer certificate: %w", err)
+	}
+	if block, _ := pem.Decode(der); block != nil {
+		der = block.Bytes
+	}
+	if issuer, err = x509.ParseCertificate(der); err != nil {
+		return nil, fmt.Errorf("failed to parse issuer certificate: %w", err)
+	}
+	s.mu.Lock()
+	s.issuer, s.issuerOf = issuer, cert
+	s.mu.Unlock()
+	return issuer, nil
+}
+
+// get sends the request and returns the body of a 200 response.
+func (s *ocspStapler) get(req *http.Request) ([]byte, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", req.URL, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, ocspMaxResponseSize))
+}
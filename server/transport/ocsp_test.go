@@ -0,0 +1,117 @@
+package transport_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestStartHTTPServer_OCSPStapling(t *testing.T) {
+	ca := newTestCA(t, "public CA")
+	var failing atomic.Bool
+	var requests atomic.Int32
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if failing.Load() || err != nil {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}, ca.key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(resp)
+	}))
+	defer responder.Close()
+
+	serverCert := newTestCert(t, ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "gateway"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		OCSPServer:  []string{responder.URL},
+	})
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	serverCert.writePEM(t, certFile, keyFile, ca)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	// startServer starts an HTTPS server stapling OCSP responses and returns its address.
+	startServer := func(t *testing.T) string {
+		cfg := config.NewInternalConfig()
+		cfg.SSLEnabledValue = true
+		cfg.SSLCertFileValue = certFile
+		cfg.SSLKeyFileValue = keyFile
+		cfg.SSLOCSPStaplingValue = true
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		server, _, err := transport.StartHTTPServer(ctx, zap.NewNop(), cfg, createDummyMux(), addr)
+		require.NoError(t, err)
+		t.Cleanup(func() { server.Shutdown(context.Background()) })
+		return addr
+	}
+	// handshake returns the OCSP response stapled by the server at addr.
+	handshake := func(addr string) ([]byte, error) {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots})
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		return conn.ConnectionState().OCSPResponse, nil
+	}
+
+	t.Run("staples the response", func(t *testing.T) {
+		addr := startServer(t)
+		var staple []byte
+		require.Eventually(t, func() bool {
+			staple, _ = handshake(addr)
+			return len(staple) > 0
+		}, 5*time.Second, 20*time.Millisecond)
+
+		resp, err := ocsp.ParseResponseForCert(staple, serverCert.cert, ca.cert)
+		require.NoError(t, err)
+		assert.Equal(t, ocsp.Good, resp.Status)
+	})
+
+	t.Run("serves without a staple when the responder fails", func(t *testing.T) {
+		failing.Store(true)
+		before := requests.Load()
+		addr := startServer(t)
+		require.Eventually(t, func() bool { return requests.Load() > before }, 5*time.Second, 20*time.Millisecond)
+
+		staple, err := handshake(addr)
+		require.NoError(t, err, "Expected the handshake to succeed without a staple")
+		assert.Empty(t, staple)
+	})
+}
@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// defaultLoopbackOrigins are allowed when no origins are configured and the server
+// listens on a loopback address. Entries without a port match any port.
+var defaultLoopbackOrigins = []string{
+	"http://localhost", "https://localhost",
+	"http://127.0.0.1", "https://127.0.0.1",
+	"http://[::1]", "https://[::1]",
+}
+
+// checkOrigin validates the Origin and Host headers against server.sse.allowed_origins
+// to protect against DNS rebinding. Requests without an Origin header (non-browser
+// clients) are accepted. Returns false after writing a 403 response if the request is rejected.
+//
+// If the server listens on a loopback address, the Host header must name a loopback host
+// or the host of one of the allowed origins, and with no origins configured only
+// localhost origins are allowed. Otherwise, with no origins configured, no validation is
+// done. "*" allows any origin.
+func (t *Transport) checkOrigin(w http.ResponseWriter, r *http.Request, logger *zap.Logger) bool {
+	allowed, err := t.config.SSEAllowedOrigins()
+	if err != nil {
+		logger.Error("Failed to get allowed origins from config", zap.Error(err))
+		allowed = nil
+	}
+	loopback := t.listensOnLoopback(logger)
+	if len(allowed) == 0 {
+		if !loopback {
+			return true
+		}
+		allowed = defaultLoopbackOrigins
+	}
+	if loopback && !isLoopbackHost(r.Host) && !hostAllowed(r.Host, allowed) {
+		logger.Warn("Rejected request with foreign Host on loopback listener", zap.String("host", r.Host))
+		http.Error(w, "Forbidden: host not allowed", http.StatusForbidden)
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" || originAllowed(origin, allowed) {
+		return true
+	}
+	logger.Warn("Rejected request from disallowed origin", zap.String("origin", origin))
+	http.Error(w, "Forbidden: origin not allowed", http.StatusForbidden)
+	return false
+}
+
+// listensOnLoopback reports whether the configured listen address is a loopback address.
+func (t *Transport) listensOnLoopback(logger *zap.Logger) bool {
+	addr, err := t.config.ListenAddr()
+	if err != nil {
+		logger.Error("Failed to get listen address from config", zap.Error(err))
+		return false
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return host != "" && isLoopbackHost(host)
+}
+
+// originAllowed reports whether origin matches one of the allowed entries.
+func originAllowed(origin string, allowed []string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return false
+	}
+	for _, entry := range allowed {
+		entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+		if entry == "*" {
+			return true
+		}
+		want, err := url.Parse(entry)
+		if err != nil || !strings.EqualFold(want.Scheme, parsed.Scheme) || !strings.EqualFold(want.Hostname(), parsed.Hostname()) {
+			continue
+		}
+		if want.Port() == "" || want.Port() == parsed.Port() {
+			return true
+		}
+	}
+	return false
+}
+
+// hostAllowed reports whether host (optionally with a port) is the host of one of the
+// allowed origins. "*" names no host.
+func hostAllowed(host string, allowed []string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	for _, entry := range allowed {
+		want, err := url.Parse(strings.TrimSpace(entry))
+		if err == nil && want.Hostname() != "" && strings.EqualFold(want.Hostname(), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopbackHost reports whether host (optionally with a port) names the local machine.
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"errors"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"go.uber.org/zap"
+)
+
+// maxUnroutedMessages bounds the messages kept for the next SSE stream of a session
+// while it has none open
+const maxUnroutedMessages = 100
+
+// outputRouter owns the output of a V2025 session and shares it between the POST
+// requests waiting on it, so concurrent POSTs each get the responses to their own
+// requests. Notifications and requests of the server go to the oldest open SSE stream
+// and are kept for the next one while none is open. All fields are guarded by
+// Transport.routersMu.
+type outputRouter struct {
+	waiters  []*responseWaiter          // Oldest first
+	byID     map[string]*responseWaiter // By ID of the pending requests
+	unrouted []*shared.Message
+}
+
+// responseWaiter receives the output of a session routed to one POST request.
+type responseWaiter struct {
+	messages   chan *shared.Message // Closed when the session output is closed
+	gone       chan struct{}        // Closed when the POST stops waiting
+	stream     bool                 // Whether it also takes messages other than responses
+	requestIDs []string
+}
+
+// waitForResponses registers a POST waiting for the responses to requestIDs and, if
+// stream is set, for the other messages of the session. Every successful call must be
+// paired with stopWaiting.
+func (t *Transport) waitForResponses(session shared.ISession, requestIDs []*schema.RequestID, stream bool) (*responseWaiter, error) {
+	t.routersMu.Lock()
+	defer t.routersMu.Unlock()
+
+	router, ok := t.routers[session.GetID()]
+	if !ok {
+		output, acquired := session.AcquireOutput()
+		if !acquired {
+			return nil, errors.New("session output is not available")
+		}
+		router = &outputRouter{byID: make(map[string]*responseWaiter)}
+		t.routers[session.GetID()] = router
+		go t.routeOutput(session, router, output)
+	}
+
+	waiter := &responseWaiter{
+		messages: make(chan *shared.Message, maxUnroutedMessages),
+		gone:     make(chan struct{}),
+		stream:   stream,
+	}
+	for _, id := range requestIDs {
+		if !id.IsEmpty() {
+			key := id.String()
+			router.byID[key] = waiter
+			waiter.requestIDs = append(waiter.requestIDs, key)
+		}
+	}
+	router.waiters = append(router.waiters, waiter)
+	if stream {
+		// Fits, the buffer holds maxUnroutedMessages
+		for _, msg := range router.unrouted {
+			waiter.messages <- msg
+		}
+		router.unrouted = nil
+	}
+	return waiter, nil
+}
+
+// stopWaiting unregisters a waiter of waitForResponses. Responses to its requests
+// arriving later are dropped.
+func (t *Transport) stopWaiting(session shared.ISession, waiter *responseWaiter) {
+	t.routersMu.Lock()
+	defer t.routersMu.Unlock()
+
+	close(waiter.gone)
+	router, ok := t.routers[session.GetID()]
+	if !ok {
+		return
+	}
+	for i, w := range router.waiters {
+		if w == waiter {
+			router.waiters = append(router.waiters[:i], router.waiters[i+1:]...)
+			break
+		}
+	}
+	for _, id := range waiter.requestIDs {
+		if router.byID[id] == waiter {
+			delete(router.byID, id)
+		}
+	}
+}
+
+// routeOutput delivers the output of the session to its waiters until the output is
+// closed with the session.
+func (t *Transport) routeOutput(session shared.ISession, router *outputRouter, output <-chan *shared.Message) {
+	logger := t.logger.With(zap.String("sessionId", session.GetID()))
+	for msg := range output {
+		if msg == nil {
+			logger.Error("Received nil message from session output channel")
+			continue
+		}
+		t.routersMu.Lock()
+		waiter := router.route(msg, logger)
+		t.routersMu.Unlock()
+		if waiter == nil {
+			continue
+		}
+		select {
+		case waiter.messages <- msg:
+		case <-waiter.gone:
+			logger.Debug("POST stopped waiting, dropping message", zap.Any("msgId", msg.ID), zap.Stringp("method", msg.Method))
+		}
+	}
+
+	logger.Debug("Session output channel closed, closing waiting POST requests")
+	t.routersMu.Lock()
+	if t.routers[session.GetID()] == router {
+		delete(t.routers, session.GetID())
+	}
+	for _, waiter := range router.waiters {
+		close(waiter.messages)
+	}
+	router.waiters = nil
+	t.routersMu.Unlock()
+	session.ReleaseOutput()
+}
+
+// route returns the waiter a message goes to, nil if there is none.
+func (r *outputRouter) route(msg *shared.Message, logger *zap.Logger) *responseWaiter {
+	if msg.Method == nil {
+		if !msg.ID.IsEmpty() {
+			if waiter, ok := r.byID[msg.ID.String()]; ok {
+				return waiter
+			}
+		}
+		logger.Debug("No POST waits for the response, dropping it", zap.Any("msgId", msg.ID))
+		return nil
+	}
+	for _, waiter := range r.waiters {
+		if waiter.stream {
+			return waiter
+		}
+	}
+	if len(r.unrouted) >= maxUnroutedMessages {
+		logger.Warn("No SSE stream open, dropping message", zap.Any("msgId", msg.ID), zap.Stringp("method", msg.Method))
+		return nil
+	}
+	r.unrouted = append(r.unrouted, msg)
+	return nil
+}
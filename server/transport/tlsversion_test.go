@@ -0,0 +1,96 @@
+package transport_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStartHTTPServer_TLSVersionAndCipherSuites(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	serverCert := newTestCert(t, ca, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "gateway"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	})
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	serverCert.writePEM(t, certFile, keyFile)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	// startServer starts an HTTPS server with the TLS settings and returns its address.
+	startServer := func(t *testing.T, minVersion string, cipherSuites ...string) string {
+		cfg := config.NewInternalConfig()
+		cfg.SSLEnabledValue = true
+		cfg.SSLCertFileValue = certFile
+		cfg.SSLKeyFileValue = keyFile
+		cfg.SSLMinVersionValue = minVersion
+		cfg.SSLCipherSuitesValue = cipherSuites
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+		server, _, err := transport.StartHTTPServer(context.Background(), zap.NewNop(), cfg, createDummyMux(), addr)
+		require.NoError(t, err)
+		t.Cleanup(func() { server.Shutdown(context.Background()) })
+		require.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				conn.Close()
+			}
+			return err == nil
+		}, 2*time.Second, 10*time.Millisecond)
+		return addr
+	}
+	dial := func(addr string, maxVersion uint16, cipherSuites ...uint16) (tls.ConnectionState, error) {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, MaxVersion: maxVersion, CipherSuites: cipherSuites})
+		if err != nil {
+			return tls.ConnectionState{}, err
+		}
+		defer conn.Close()
+		return conn.ConnectionState(), nil
+	}
+
+	t.Run("minimum version", func(t *testing.T) {
+		addr := startServer(t, config.TLSVersion13)
+		_, err := dial(addr, tls.VersionTLS12)
+		assert.Error(t, err, "Expected a TLS 1.2 client to be rejected")
+		state, err := dial(addr, 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), state.Version)
+	})
+
+	t.Run("cipher suites", func(t *testing.T) {
+		addr := startServer(t, config.TLSVersion12, "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256")
+		_, err := dial(addr, tls.VersionTLS12, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256)
+		assert.Error(t, err, "Expected a client without the configured suite to be rejected")
+		state, err := dial(addr, tls.VersionTLS12)
+		require.NoError(t, err)
+		assert.Equal(t, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, state.CipherSuite)
+	})
+
+	t.Run("unknown cipher suite", func(t *testing.T) {
+		cfg := config.NewInternalConfig()
+		cfg.SSLEnabledValue = true
+		cfg.SSLCertFileValue = certFile
+		cfg.SSLKeyFileValue = keyFile
+		cfg.SSLCipherSuitesValue = []string{"RC4"}
+		_, _, err := transport.StartHTTPServer(context.Background(), zap.NewNop(), cfg, createDummyMux(), "127.0.0.1:0")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown cipher suite 'RC4', valid suites are: TLS_")
+	})
+}
@@ -1,16 +1,21 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gate4ai/mcp/server/mcp"
 	"github.com/gate4ai/mcp/shared"
 	"github.com/gate4ai/mcp/shared/config"
 	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/tracing"
 	"go.uber.org/zap"
 )
 
@@ -19,6 +24,7 @@ const (
 	AUTH_KEY2024       = "key"            // Query parameter for authentication key (for V2024 compatibility)
 	PATH2024           = "/sse"           // Unified endpoint path for V2024 (for V2024 compatibility)
 	PATH               = "/mcp"           // Unified endpoint path
+	PATH_WEBSOCKET     = "/ws"            // Endpoint path of the WebSocket transport
 	MCP_SESSION_HEADER = "Mcp-Session-Id" // Header for session ID
 
 	// Content Types
@@ -31,6 +37,15 @@ const (
 	statusMethodNotAllowed    = http.StatusMethodNotAllowed    // 405
 	statusUnauthorized        = http.StatusUnauthorized        // 401
 	statusInternalServerError = http.StatusInternalServerError // 500
+	statusServiceUnavailable  = http.StatusServiceUnavailable  // 503
+
+	// Seconds a client is asked to wait before retrying a rejected SSE stream
+	sseRetryAfterSeconds = 5
+
+	// How often a draining stream checks whether the requests of its session are answered
+	drainPollInterval = 20 * time.Millisecond
+	// Max wait for the streams to close once the drain is forced
+	forceCloseWait = time.Second
 )
 
 // Transport manages MCP HTTP connections supporting multiple protocol versions.
@@ -40,9 +55,27 @@ type Transport struct {
 	authManager     AuthenticationManager
 	config          config.IConfig
 	serverInfo      schema.Implementation
-	NoStream2025    bool          // Whether server supports streaming responses in V2
-	sessionTimeout  time.Duration // Idle timeout for sessions
-	cleanupInterval time.Duration // How often to check for idle sessions
+	NoStream2025    bool            // Whether server supports streaming responses in V2
+	sessionTimeout  time.Duration   // Idle timeout for sessions
+	cleanupInterval time.Duration   // How often to check for idle sessions
+	activeStreams   atomic.Int64    // Number of currently open SSE streams and WebSockets, changed under streamMu
+	wsPingInterval  time.Duration   // How often WebSocket connections are pinged
+	tracer          *tracing.Tracer // Traces SSE streams if not nil
+	streamLogger    StreamLogger    // Told about closed SSE streams if not nil
+
+	streamMu      sync.Mutex
+	streamFreed   chan struct{}   // Closed and replaced whenever a stream slot is released
+	streamWaiters []chan struct{} // Streams waiting for a slot, in arrival order; closed once granted one
+
+	routersMu sync.Mutex
+	routers   map[string]*outputRouter // Output routers of the V2025 sessions, by session ID
+
+	drainOnce      sync.Once
+	draining       chan struct{} // Closed when Shutdown starts
+	forceOnce      sync.Once
+	forcing        chan struct{} // Closed when Shutdown stops waiting for requests
+	drainedStreams atomic.Int64  // Streams closed by Shutdown once their requests were answered
+	forcedStreams  atomic.Int64  // Streams closed by Shutdown with requests still running
 }
 
 // TransportOption defines a function type for configuring the Transport.
@@ -67,6 +100,37 @@ func WithSessionTimeout(timeout time.Duration) TransportOption {
 	}
 }
 
+// WithWebSocketPingInterval sets how often WebSocket connections are pinged. Clients
+// silent for two intervals are disconnected.
+func WithWebSocketPingInterval(interval time.Duration) TransportOption {
+	return func(t *Transport) error {
+		if interval <= 0 {
+			return errors.New("WebSocket ping interval must be positive")
+		}
+		t.wsPingInterval = interval
+		return nil
+	}
+}
+
+// WithTracer makes the transport trace SSE streams from their opening to their closing.
+// The requests received continue the trace of the traceparent header of their HTTP
+// request, or of their SSE stream.
+func WithTracer(tracer *tracing.Tracer) TransportOption {
+	return func(t *Transport) error {
+		t.tracer = tracer
+		return nil
+	}
+}
+
+// WithStreamLogger makes the transport tell logger about every SSE stream to a client
+// when it closes.
+func WithStreamLogger(logger StreamLogger) TransportOption {
+	return func(t *Transport) error {
+		t.streamLogger = logger
+		return nil
+	}
+}
+
 // New creates a new MCP HTTP transport handler.
 func New(mcpManager mcp.ISessionManager, logger *zap.Logger, cfg config.IConfig, options ...TransportOption) (*Transport, error) {
 	if logger == nil {
@@ -99,6 +163,10 @@ func New(mcpManager mcp.ISessionManager, logger *zap.Logger, cfg config.IConfig,
 		},
 		cleanupInterval: 1 * time.Minute, // Default cleanup interval
 		sessionTimeout:  5 * time.Minute, // Default session timeout
+		wsPingInterval:  defaultWebSocketPingInterval,
+		routers:         make(map[string]*outputRouter),
+		draining:        make(chan struct{}),
+		forcing:         make(chan struct{}),
 	}
 
 	// Apply configuration options
@@ -130,7 +198,8 @@ func (t *Transport) SetAuthManager(authManager AuthenticationManager) {
 func (t *Transport) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc(PATH2024, t.Handle2024MCP())
 	mux.HandleFunc(PATH, t.HandleMCP())
-	t.logger.Info("Registered MCP handler", zap.String("path", PATH), zap.String("path2024", PATH2024))
+	mux.HandleFunc(PATH_WEBSOCKET, t.HandleWebSocket())
+	t.logger.Info("Registered MCP handler", zap.String("path", PATH), zap.String("path2024", PATH2024), zap.String("pathWebSocket", PATH_WEBSOCKET))
 }
 
 func (t *Transport) Handle2024MCP() http.HandlerFunc {
@@ -144,6 +213,10 @@ func (t *Transport) Handle2024MCP() http.HandlerFunc {
 			zap.String("query", r.URL.RawQuery),
 		)
 
+		if !t.checkOrigin(w, r, logger) {
+			return
+		}
+
 		// Handle based on HTTP method
 		switch r.Method {
 		case http.MethodGet:
@@ -171,6 +244,10 @@ func (t *Transport) HandleMCP() http.HandlerFunc {
 			zap.String("query", r.URL.RawQuery),
 		)
 
+		if !t.checkOrigin(w, r, logger) {
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			t.handleGET(w, r, logger)
@@ -188,6 +265,27 @@ func (t *Transport) HandleMCP() http.HandlerFunc {
 	}
 }
 
+// HandleWebSocket returns the handler of the WebSocket transport.
+func (t *Transport) HandleWebSocket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := t.logger
+
+		logger.Debug("Received request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.String("remoteAddr", r.RemoteAddr),
+			zap.String("query", r.URL.RawQuery),
+		)
+
+		// Browsers do not restrict cross-origin WebSockets, the origin check is all
+		// that keeps other sites from connecting in the name of the user
+		if !t.checkOrigin(w, r, logger) {
+			return
+		}
+		t.handleWebSocket(w, r, logger)
+	}
+}
+
 // startSessionCleanup periodically checks for idle sessions and closes them.
 func (t *Transport) startSessionCleanup() {
 	ticker := time.NewTicker(t.cleanupInterval)
@@ -202,6 +300,338 @@ func (t *Transport) startSessionCleanup() {
 	t.logger.Info("Session cleanup routine stopped")
 }
 
+// ActiveSSEStreams returns the number of currently open SSE streams and WebSockets.
+func (t *Transport) ActiveSSEStreams() int64 {
+	return t.activeStreams.Load()
+}
+
+// Shutdown drains the open SSE streams and WebSockets. New streams are rejected with 503
+// and Retry-After. Each open stream is closed with a final close event (a close frame on
+// WebSockets) once the requests of its session are answered; V2024 streams and
+// WebSockets stay open until then, V2025 streams close as usual once they carried their
+// responses. When ctx is done first, the remaining streams are closed at once and
+// Shutdown returns ctx.Err(). The HTTP server is not stopped: call http.Server.Shutdown
+// alongside, which stops accepting connections.
+func (t *Transport) Shutdown(ctx context.Context) error {
+	t.drainOnce.Do(func() { close(t.draining) })
+	t.logger.Info("Draining open streams", zap.Int64("streams", t.activeStreams.Load()))
+
+	err := t.waitForStreams(ctx)
+	if err != nil {
+		t.forceOnce.Do(func() { close(t.forcing) })
+		waitCtx, cancel := context.WithTimeout(context.Background(), forceCloseWait)
+		defer cancel()
+		if t.waitForStreams(waitCtx) != nil {
+			t.logger.Warn("Streams still open after closing them", zap.Int64("streams", t.activeStreams.Load()))
+		}
+	}
+	drained, forced := t.ShutdownStreams()
+	t.logger.Info("Open streams drained", zap.Int64("drained", drained), zap.Int64("forced", forced))
+	return err
+}
+
+// DrainTimeout returns how long Shutdown should wait for the requests answered on open
+// streams, server.sse.drain_timeout or config.DefaultSSEDrainTimeout.
+func (t *Transport) DrainTimeout() time.Duration {
+	timeout, err := t.config.SSEDrainTimeout()
+	if err != nil {
+		t.logger.Warn("Failed to read SSE drain timeout, using the default", zap.Error(err))
+	}
+	if err != nil || timeout <= 0 {
+		return config.DefaultSSEDrainTimeout
+	}
+	return timeout
+}
+
+// ShutdownStreams returns the number of streams Shutdown closed once the requests of
+// their sessions were answered, and of those it closed with requests still running
+// because its context was done.
+func (t *Transport) ShutdownStreams() (drained int64, forced int64) {
+	return t.drainedStreams.Load(), t.forcedStreams.Load()
+}
+
+// waitForStreams waits until no stream is open or ctx is done.
+func (t *Transport) waitForStreams(ctx context.Context) error {
+	for {
+		t.streamMu.Lock()
+		if t.activeStreams.Load() == 0 {
+			t.streamMu.Unlock()
+			return nil
+		}
+		if t.streamFreed == nil {
+			t.streamFreed = make(chan struct{})
+		}
+		freed := t.streamFreed
+		t.streamMu.Unlock()
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isClosed reports whether the channel is closed.
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// drained returns a channel closed once the server shuts down and the stream of r may be
+// closed: when the session has no request in flight any more, or the drain is forced.
+// It is never closed while the server keeps running.
+func (t *Transport) drained(r *http.Request, session shared.ISession) <-chan struct{} {
+	drained := make(chan struct{})
+	go func() {
+		select {
+		case <-t.draining:
+		case <-r.Context().Done():
+			return
+		}
+		ticker := time.NewTicker(drainPollInterval)
+		defer ticker.Stop()
+		for session.Input().RequestsInFlight() > 0 {
+			select {
+			case <-ticker.C:
+			case <-t.forcing:
+				close(drained)
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+		close(drained)
+	}()
+	return drained
+}
+
+// startStreamSpan starts the span of an SSE stream opened by r, to be ended when the
+// stream closes. It returns nil if streams are not traced.
+func (t *Transport) startStreamSpan(r *http.Request) *tracing.Span {
+	span := t.tracer.Start("SSE "+r.URL.Path, tracing.KindServer, r.Header.Get(tracing.TraceparentHeader))
+	span.SetAttribute(tracing.AttrTransport, "sse")
+	return span
+}
+
+// StreamLogger is told about every SSE stream to a client when it closes. It must not
+// block.
+type StreamLogger interface {
+	LogStream(session shared.ISession, stream StreamStats)
+}
+
+// StreamStats describes a closed SSE stream.
+type StreamStats struct {
+	Path     string        // URL path the stream was opened at
+	Duration time.Duration // Time the stream was open
+	Events   int           // Events written, keepalive comments excluded
+	Bytes    int64         // Bytes written, keepalive comments included
+}
+
+// countingStream counts what is written to an SSE stream, for its StreamLogger. Events
+// are counted by the writer of the stream.
+type countingStream struct {
+	http.ResponseWriter
+	start  time.Time
+	events int
+	bytes  int64
+}
+
+func newCountingStream(w http.ResponseWriter) *countingStream {
+	return &countingStream{ResponseWriter: w, start: time.Now()}
+}
+
+func (s *countingStream) Write(p []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += int64(n)
+	return n, err
+}
+
+func (s *countingStream) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// logStream tells the StreamLogger about a closed SSE stream of the session opened by r.
+func (t *Transport) logStream(session shared.ISession, r *http.Request, stream *countingStream) {
+	if t.streamLogger == nil {
+		return
+	}
+	t.streamLogger.LogStream(session, StreamStats{
+		Path:     r.URL.Path,
+		Duration: time.Since(stream.start),
+		Events:   stream.events,
+		Bytes:    stream.bytes,
+	})
+}
+
+// acquireStreamSlot reserves a slot for a new SSE stream. If the server-wide limit
+// is reached the stream waits in a queue of server.sse.queue_size streams for up to
+// server.sse.queue_wait; freed slots go to queued streams in arrival order, so new
+// streams do not take them while others wait. If queuing is disabled, the queue is
+// full or the wait times out it replies with 503 and Retry-After and returns false, as
+// it does once the server shuts down. It also returns false, without replying, if the
+// client goes away while queued.
+// Every successful call must be paired with releaseStreamSlot.
+func (t *Transport) acquireStreamSlot(w http.ResponseWriter, r *http.Request, logger *zap.Logger) bool {
+	maxStreams, err := t.config.SSEMaxStreams()
+	if err != nil {
+		logger.Warn("Failed to read SSE stream limit, assuming unlimited", zap.Error(err))
+		maxStreams = 0
+	}
+	queueSize, err := t.config.SSEQueueSize()
+	if err != nil {
+		logger.Warn("Failed to read SSE queue size, not queuing", zap.Error(err))
+		queueSize = 0
+	}
+	queueWait, err := t.config.SSEQueueWait()
+	if err != nil {
+		logger.Warn("Failed to read SSE queue wait, not queuing", zap.Error(err))
+		queueWait = 0
+	}
+
+	reject := func(reason string) bool {
+		logger.Warn("SSE stream limit reached, rejecting stream",
+			zap.String("reason", reason),
+			zap.Int("maxStreams", maxStreams),
+			zap.Int64("activeStreams", t.activeStreams.Load()),
+		)
+		w.Header().Set("Retry-After", strconv.Itoa(sseRetryAfterSeconds))
+		http.Error(w, "Service Unavailable: too many concurrent streams", statusServiceUnavailable)
+		return false
+	}
+	shuttingDown := func() bool {
+		logger.Info("Server shutting down, rejecting stream")
+		w.Header().Set("Retry-After", strconv.Itoa(sseRetryAfterSeconds))
+		http.Error(w, "Service Unavailable: server shutting down", statusServiceUnavailable)
+		return false
+	}
+
+	t.streamMu.Lock()
+	if isClosed(t.draining) {
+		t.streamMu.Unlock()
+		return shuttingDown()
+	}
+	// Slots freed by raising the limit go to the queued streams first
+	t.grantStreamSlots(maxStreams)
+	if len(t.streamWaiters) == 0 && (maxStreams <= 0 || t.activeStreams.Load() < int64(maxStreams)) {
+		t.activeStreams.Add(1)
+		t.streamMu.Unlock()
+		return true
+	}
+	if queueSize <= 0 || queueWait <= 0 {
+		t.streamMu.Unlock()
+		return reject("limit reached")
+	}
+	if len(t.streamWaiters) >= queueSize {
+		t.streamMu.Unlock()
+		return reject("queue full")
+	}
+	granted := make(chan struct{})
+	t.streamWaiters = append(t.streamWaiters, granted)
+	logger.Debug("SSE stream limit reached, queuing stream", zap.Int("queued", len(t.streamWaiters)), zap.Duration("maxWait", queueWait))
+	t.streamMu.Unlock()
+
+	timer := time.NewTimer(queueWait)
+	defer timer.Stop()
+	select {
+	case <-granted:
+		return true
+	case <-t.draining:
+		t.leaveStreamQueue(granted, maxStreams)
+		return shuttingDown()
+	case <-timer.C:
+		if t.leaveStreamQueue(granted, maxStreams) {
+			return reject("timed out in queue")
+		}
+		// The slot was granted as the wait ran out
+		return true
+	case <-r.Context().Done():
+		t.leaveStreamQueue(granted, maxStreams)
+		logger.Debug("Client went away while queued for an SSE stream slot")
+		return false
+	}
+}
+
+// leaveStreamQueue removes a queued stream from the queue and reports whether it was
+// still waiting. If it had been granted a slot meanwhile, the slot is freed again.
+func (t *Transport) leaveStreamQueue(granted chan struct{}, maxStreams int) bool {
+	t.streamMu.Lock()
+	defer t.streamMu.Unlock()
+	for i, waiter := range t.streamWaiters {
+		if waiter == granted {
+			t.streamWaiters = append(t.streamWaiters[:i], t.streamWaiters[i+1:]...)
+			return true
+		}
+	}
+	t.freeStreamSlot(maxStreams)
+	return false
+}
+
+// grantStreamSlots hands free slots to queued streams in arrival order. It must be
+// called with streamMu held.
+func (t *Transport) grantStreamSlots(maxStreams int) {
+	for len(t.streamWaiters) > 0 && (maxStreams <= 0 || t.activeStreams.Load() < int64(maxStreams)) {
+		t.activeStreams.Add(1)
+		close(t.streamWaiters[0])
+		t.streamWaiters = t.streamWaiters[1:]
+	}
+}
+
+// freeStreamSlot releases a slot and hands it to the first queued stream unless the
+// server shuts down. It must be called with streamMu held.
+func (t *Transport) freeStreamSlot(maxStreams int) {
+	t.activeStreams.Add(-1)
+	if t.streamFreed != nil {
+		close(t.streamFreed)
+		t.streamFreed = nil
+	}
+	if !isClosed(t.draining) {
+		t.grantStreamSlots(maxStreams)
+	}
+}
+
+// releaseStreamSlot frees a slot reserved by acquireStreamSlot and passes it on to the
+// first queued stream. During a shutdown it counts the stream as drained or forced.
+func (t *Transport) releaseStreamSlot() {
+	maxStreams, err := t.config.SSEMaxStreams()
+	if err != nil {
+		maxStreams = 0
+	}
+	t.streamMu.Lock()
+	defer t.streamMu.Unlock()
+	switch {
+	case isClosed(t.forcing):
+		t.forcedStreams.Add(1)
+	case isClosed(t.draining):
+		t.drainedStreams.Add(1)
+	}
+	t.freeStreamSlot(maxStreams)
+}
+
+// sseKeepAlive returns the interval of keepalive comments on open SSE streams.
+func (t *Transport) sseKeepAlive(logger *zap.Logger) time.Duration {
+	interval, err := t.config.SSEKeepAlive()
+	if err != nil {
+		logger.Warn("Failed to read SSE keepalive interval, using the default", zap.Error(err))
+	}
+	if err != nil || interval <= 0 {
+		return config.DefaultSSEKeepAlive
+	}
+	return interval
+}
+
+// writeKeepAlive writes an SSE comment that keeps proxies from closing an idle stream.
+// Clients ignore comments. It must be called from the goroutine writing the events of
+// the stream, so it never lands inside a partially written event.
+func writeKeepAlive(w http.ResponseWriter, flusher http.Flusher) {
+	fmt.Fprint(w, ": ping\n\n")
+	flusher.Flush()
+}
+
 // --- Helper to send JSON responses ---
 func sendJSONResponse(w http.ResponseWriter, statusCode int, data interface{}, logger *zap.Logger) {
 	w.Header().Set("Content-Type", contentTypeJSON)
@@ -257,14 +687,45 @@ func (t *Transport) getSession(w http.ResponseWriter, r *http.Request, logger *z
 			return nil, errors.New("session not found")
 		}
 
-		authKey := t.extractAuthKey(r)
-		userID, sessionParams, err := t.authManager.Authenticate(authKey, r.RemoteAddr)
+		userID, sessionParams, err := t.authenticate(r)
 		if err != nil {
 			logger.Warn("Authentication failed for V2024 SSE connection", zap.String("remoteAddr", r.RemoteAddr), zap.Error(err))
 			http.Error(w, "Authentication failed: "+err.Error(), statusUnauthorized)
 			return nil, err
 		}
 
+		SaveTransportName(sessionParams, transportName(r))
 		return t.sessionManager.CreateSession(userID, sessionParams), nil
 	}
 }
+
+// Names of the transports creating sessions, see GetTransportName
+const (
+	TransportSSE            = "sse"             // V2024 SSE stream
+	TransportStreamableHTTP = "streamable-http" // V2025 POST
+	TransportWebSocket      = "websocket"
+)
+
+// transportName returns the transport of a request creating a session.
+func transportName(r *http.Request) string {
+	switch {
+	case isWebSocketUpgrade(r):
+		return TransportWebSocket
+	case r.Method == http.MethodGet:
+		return TransportSSE
+	default:
+		return TransportStreamableHTTP
+	}
+}
+
+// authenticate authenticates a request creating a session by its verified client
+// certificate if it has one and no authorization key, and by the key otherwise.
+func (t *Transport) authenticate(r *http.Request) (string, *sync.Map, error) {
+	authKey := t.extractAuthKey(r)
+	if certAuth, ok := t.authManager.(ClientCertAuthenticationManager); ok && authKey == "" {
+		if cert := verifiedClientCert(r); cert != nil {
+			return certAuth.AuthenticateClientCert(cert, r.RemoteAddr)
+		}
+	}
+	return t.authManager.Authenticate(authKey, r.RemoteAddr)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 
@@ -324,3 +325,139 @@ func Test_SRV_25_HTTP_POS_05_PostBatchRequestNotificationReturnsResponse(t *test
 		assert.ErrorIs(t, errRead, io.EOF, "Expected SSE stream to close after batch responses")
 	})
 }
+
+// Specification requirement: The server MAY send JSON-RPC _requests_ and _notifications_ before sending a JSON-RPC _response_... The server SHOULD NOT close the SSE stream before sending a JSON-RPC _response_ per each received JSON-RPC _request_.
+// Initialize followed by two long-running requests posted at the same time: each stream
+// stays open, past the time the other one is answered, until its own response is sent.
+func Test_SRV_25_HTTP_POS_06_StreamStaysOpenUntilPendingRequestsAnswered(t *testing.T) {
+	tp, _, _, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	tp.NoStream2025 = false
+
+	initBody := createJsonRpcRequestBody(1, "initialize", schema2025.InitializeRequestParams{
+		ProtocolVersion: schema2025.PROTOCOL_VERSION,
+		ClientInfo:      schema2025.Implementation{Name: "test-client", Version: "1.0"},
+		Capabilities:    schema2025.ClientCapabilities{},
+	})
+	respInit, err := makePostRequest(t, server.URL+transport.PATH, initBody, nil)
+	require.NoError(t, err)
+	initEvents := readAllSseEvents(t, respInit.Body, 3*time.Second)
+	require.Len(t, initEvents, 1, "Expected only the initialize response on its stream")
+	sessionIDHeader := map[string]string{transport.MCP_SESSION_HEADER: respInit.Header.Get(transport.MCP_SESSION_HEADER)}
+
+	// readResponses reads a stream to its end and returns the IDs of the responses on it,
+	// it runs outside the test goroutine and only asserts.
+	readResponses := func(resp *http.Response) []string {
+		defer resp.Body.Close()
+		var ids []string
+		reader := bufio.NewReader(resp.Body)
+		for {
+			event, data, _, err := readNextSseEvent(t, reader)
+			if err == io.EOF || !assert.NoError(t, err) {
+				return ids
+			}
+			if event != "message" {
+				continue
+			}
+			var msg shared.Message
+			if assert.NoError(t, json.Unmarshal([]byte(data), &msg)) && assert.Nil(t, msg.Error, "Unexpected error response: %s", data) {
+				ids = append(ids, msg.ID.String())
+			}
+		}
+	}
+
+	requests := map[int]int{2: 2000, 3: 200} // Delay in ms by request ID
+	type result struct {
+		id      int
+		ids     []string
+		elapsed time.Duration
+	}
+	results := make(chan result, len(requests))
+	start := time.Now()
+	for id, delay := range requests {
+		body := createJsonRpcRequestBody(id, "test/slow", map[string]int{"delayMs": delay})
+		go func() {
+			resp, err := makePostRequest(t, server.URL+transport.PATH, body, sessionIDHeader)
+			if !assert.NoError(t, err) {
+				results <- result{id: id}
+				return
+			}
+			assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+			results <- result{id: id, ids: readResponses(resp), elapsed: time.Since(start)}
+		}()
+	}
+
+	for range requests {
+		select {
+		case res := <-results:
+			assert.Equal(t, []string{strconv.Itoa(res.id)}, res.ids, "Stream of request %d should carry exactly its response", res.id)
+			assert.GreaterOrEqual(t, res.elapsed, time.Duration(requests[res.id])*time.Millisecond, "Stream of request %d closed before its response", res.id)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timed out waiting for the streams of the long-running requests")
+		}
+	}
+}
+
+// Specification requirement (JSON-RPC 2.0 batch): The server answers a batch with an array
+// of the responses to its requests in request order, none for notifications, and an
+// Invalid Request error for each element that is not a valid message.
+func Test_SRV_25_HTTP_POS_07_BatchAnsweredInRequestOrder(t *testing.T) {
+	tp, _, _, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	tp.NoStream2025 = true // Force JSON responses
+
+	// initialize and a request in one round trip
+	initialize := createJsonRpcRequestBody(1, "initialize", schema2025.InitializeRequestParams{
+		ProtocolVersion: schema2025.PROTOCOL_VERSION,
+		ClientInfo:      schema2025.Implementation{Name: "test-client", Version: "1.0"},
+		Capabilities:    schema2025.ClientCapabilities{},
+	})
+	resp, err := makePostRequest(t, server.URL+transport.PATH, createJsonRpcBatchRequestBody(initialize, createJsonRpcRequestBody(2, "test/method", nil)), nil)
+	require.NoError(t, err)
+	sessionID := resp.Header.Get(transport.MCP_SESSION_HEADER)
+	require.NotEmpty(t, sessionID)
+	sessionIDHeader := map[string]string{transport.MCP_SESSION_HEADER: sessionID}
+	responses := assertJsonRpcBatchResponse(t, resp.Body, 2)
+	resp.Body.Close()
+	assert.Contains(t, string(responses[0]), `"id":1`)
+	assert.Contains(t, string(responses[0]), `"protocolVersion"`)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{"status":"ok"}}`, string(responses[1]))
+
+	// A slow request answered last still comes first; malformed elements do not fail the others
+	batch := createJsonRpcBatchRequestBody(
+		createJsonRpcRequestBody(10, "test/slow", map[string]int{"delayMs": 200}),
+		createJsonRpcNotificationBody("notifications/initialized", nil),
+		`42`,
+		`{"jsonrpc":"2.0","id":12}`,
+		createJsonRpcRequestBody(11, "ping", nil),
+	)
+	resp, err = makePostRequest(t, server.URL+transport.PATH, batch, sessionIDHeader)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	responses = assertJsonRpcBatchResponse(t, resp.Body, 4)
+	resp.Body.Close()
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":10,"result":{"delayMs":200}}`, string(responses[0]))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"}}`, string(responses[1]))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":12,"error":{"code":-32600,"message":"Invalid Request"}}`, string(responses[2]))
+	assert.Contains(t, string(responses[3]), `"id":11`)
+	assert.NotContains(t, string(responses[3]), `"error"`)
+
+	// A batch of one request is answered with an array
+	resp, err = makePostRequest(t, server.URL+transport.PATH, createJsonRpcBatchRequestBody(createJsonRpcRequestBody(20, "ping", nil)), sessionIDHeader)
+	require.NoError(t, err)
+	assertJsonRpcBatchResponse(t, resp.Body, 1)
+	resp.Body.Close()
+
+	// Notifications and invalid elements only: the errors are answered at once
+	resp, err = makePostRequest(t, server.URL+transport.PATH, createJsonRpcBatchRequestBody(createJsonRpcNotificationBody("notify/1", nil), `"x"`), sessionIDHeader)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assertJsonRpcBatchResponse(t, resp.Body, 1)
+	resp.Body.Close()
+
+	// An empty batch is an Invalid Request
+	resp, err = makePostRequest(t, server.URL+transport.PATH, `[]`, sessionIDHeader)
+	require.NoError(t, err)
+	assertJsonRpcError(t, resp.Body, shared.JSONRPCErrorInvalidRequest, "Invalid Request")
+	resp.Body.Close()
+}
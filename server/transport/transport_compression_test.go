@@ -0,0 +1,120 @@
+package transport_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postLarge asks for a response with size bytes of data, accepting acceptEncoding, and
+// returns the response with its raw body.
+func postLarge(t *testing.T, serverURL string, sessionID string, size int, acceptEncoding string) (*http.Response, []byte) {
+	t.Helper()
+	headers := map[string]string{transport.MCP_SESSION_HEADER: sessionID}
+	if acceptEncoding != "" {
+		// Set explicitly, so the client does not decompress the body itself
+		headers["Accept-Encoding"] = acceptEncoding
+	}
+	resp, err := makePostRequest(t, serverURL+transport.PATH, createJsonRpcRequestBody(2, "test/large", map[string]int{"size": size}), headers)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, "Body: %s", body)
+	return resp, body
+}
+
+// assertLargeResult checks that body holds the response to postLarge.
+func assertLargeResult(t *testing.T, body io.Reader, size int) {
+	t.Helper()
+	var resp shared.JSONRPCResponse
+	require.NoError(t, json.NewDecoder(body).Decode(&resp))
+	require.NotNil(t, resp.Result)
+	var result struct {
+		Data string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(*resp.Result, &result))
+	assert.Len(t, result.Data, size)
+}
+
+// JSON responses are compressed for clients accepting gzip or deflate once they reach
+// the configured size; SSE streams never are.
+func Test_SRV_COMPRESSION_01_CompressesJsonResponses(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	tp.NoStream2025 = true
+	cfg.SetCompressionMinSize(4096)
+
+	initBody := createJsonRpcRequestBody(1, "initialize", schema2025.InitializeRequestParams{
+		ProtocolVersion: schema2025.PROTOCOL_VERSION,
+		ClientInfo:      schema2025.Implementation{Name: "test-client", Version: "1.0"},
+	})
+	respInit, err := makePostRequest(t, server.URL+transport.PATH, initBody, nil)
+	require.NoError(t, err)
+	respInit.Body.Close()
+	sessionID := respInit.Header.Get(transport.MCP_SESSION_HEADER)
+
+	t.Run("gzip", func(t *testing.T) {
+		resp, body := postLarge(t, server.URL, sessionID, 100000, "gzip")
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+		assert.Contains(t, resp.Header.Values("Vary"), "Accept-Encoding")
+		assert.Less(t, len(body), 10000, "Expected the body to be compressed")
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		assertLargeResult(t, reader, 100000)
+	})
+
+	t.Run("deflate preferred", func(t *testing.T) {
+		resp, body := postLarge(t, server.URL, sessionID, 100000, "gzip;q=0.5, deflate")
+		assert.Equal(t, "deflate", resp.Header.Get("Content-Encoding"))
+		reader, err := zlib.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		assertLargeResult(t, reader, 100000)
+	})
+
+	t.Run("below min size", func(t *testing.T) {
+		resp, body := postLarge(t, server.URL, sessionID, 100, "gzip")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assertLargeResult(t, bytes.NewReader(body), 100)
+	})
+
+	t.Run("not accepted", func(t *testing.T) {
+		resp, body := postLarge(t, server.URL, sessionID, 100000, "gzip;q=0, br")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assertLargeResult(t, bytes.NewReader(body), 100000)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg.SetCompressionMinSize(-1)
+		defer cfg.SetCompressionMinSize(4096)
+		resp, body := postLarge(t, server.URL, sessionID, 100000, "gzip")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assertLargeResult(t, bytes.NewReader(body), 100000)
+	})
+
+	t.Run("SSE stream", func(t *testing.T) {
+		tp.NoStream2025 = false
+		defer func() { tp.NoStream2025 = true }()
+		resp, err := makePostRequest(t, server.URL+transport.PATH, createJsonRpcRequestBody(3, "test/large", map[string]int{"size": 100000}),
+			map[string]string{transport.MCP_SESSION_HEADER: sessionID, "Accept-Encoding": "gzip"})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		events := readAllSseEvents(t, resp.Body, 2*time.Second)
+		require.Len(t, events, 1)
+		assertLargeResult(t, strings.NewReader(events[0]["data"]), 100000)
+	})
+}
@@ -0,0 +1,106 @@
+package transport_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Configured allowlist: browser requests from other origins are rejected with 403,
+// allowed origins and non-browser clients (no Origin header) pass.
+func Test_SRV_ORIGIN_01_ConfiguredAllowlist(t *testing.T) {
+	_, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetSSEAllowedOrigins([]string{"https://app.example.com", "http://localhost"})
+	sseURL := server.URL + transport.PATH2024 + "?key=valid-key"
+
+	resp, err := makeSseGetRequest(t, sseURL, map[string]string{"Origin": "https://evil.example.com"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = makePostRequest(t, server.URL+transport.PATH, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, map[string]string{"Origin": "https://evil.example.com"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	for _, origin := range []string{"https://app.example.com", "http://localhost:5173", ""} {
+		headers := map[string]string{}
+		if origin != "" {
+			headers["Origin"] = origin
+		}
+		resp, err = makeSseGetRequest(t, sseURL, headers)
+		require.NoError(t, err, "origin %q", origin)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "origin %q should be allowed", origin)
+	}
+}
+
+// Loopback listener without configuration: only localhost origins and loopback Host headers are accepted.
+func Test_SRV_ORIGIN_02_LoopbackDefault(t *testing.T) {
+	_, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetListenAddr("127.0.0.1:8080")
+	sseURL := server.URL + transport.PATH2024 + "?key=valid-key"
+
+	resp, err := makeSseGetRequest(t, sseURL, map[string]string{"Origin": "http://attacker.example.com"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = makeSseGetRequest(t, sseURL, map[string]string{"Origin": "http://localhost:3000"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// DNS rebinding: the browser sends the attacker's host name
+	req, err := http.NewRequest(http.MethodGet, sseURL, nil)
+	require.NoError(t, err)
+	req.Host = "attacker.example.com"
+	resp, err = (&http.Client{Timeout: 3 * time.Second}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+// Loopback listener with an allowlist: the Host header must still name a loopback host or
+// the host of an allowed origin.
+func Test_SRV_ORIGIN_04_LoopbackHostWithAllowlist(t *testing.T) {
+	_, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetListenAddr("127.0.0.1:8080")
+	cfg.SetSSEAllowedOrigins([]string{"https://app.example.com", "*"})
+	sseURL := server.URL + transport.PATH2024 + "?key=valid-key"
+
+	for host, want := range map[string]int{
+		"attacker.example.com":      http.StatusForbidden,
+		"attacker.example.com:8080": http.StatusForbidden,
+		"app.example.com:8080":      http.StatusOK,
+		"localhost:8080":            http.StatusOK,
+		"[::1]:8080":                http.StatusOK,
+	} {
+		req, err := http.NewRequest(http.MethodGet, sseURL, nil)
+		require.NoError(t, err)
+		req.Host = host
+		req.Header.Set("Origin", "https://app.example.com")
+		resp, err := (&http.Client{Timeout: 3 * time.Second}).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, want, resp.StatusCode, "host %q", host)
+	}
+}
+
+// Non-loopback listener without configuration keeps accepting any origin.
+func Test_SRV_ORIGIN_03_NoValidationByDefault(t *testing.T) {
+	_, _, _, server, cleanup := setupServerTest(t)
+	defer cleanup()
+
+	resp, err := makeSseGetRequest(t, server.URL+transport.PATH2024+"?key=valid-key", map[string]string{"Origin": "https://anywhere.example.com"})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -71,6 +71,31 @@ func (m *MockTestCapability) GetHandlers() map[string]func(*shared.Message) (int
 		"test/method": func(msg *shared.Message) (interface{}, error) {
 			return map[string]string{"status": "ok"}, nil
 		},
+		// Long-running request, answers after params.delayMs milliseconds
+		"test/slow": func(msg *shared.Message) (interface{}, error) {
+			var params struct {
+				DelayMs int `json:"delayMs"`
+			}
+			if msg.Params != nil {
+				if err := json.Unmarshal(*msg.Params, &params); err != nil {
+					return nil, err
+				}
+			}
+			time.Sleep(time.Duration(params.DelayMs) * time.Millisecond)
+			return map[string]int{"delayMs": params.DelayMs}, nil
+		},
+		// Answers with params.size bytes of data
+		"test/large": func(msg *shared.Message) (interface{}, error) {
+			var params struct {
+				Size int `json:"size"`
+			}
+			if msg.Params != nil {
+				if err := json.Unmarshal(*msg.Params, &params); err != nil {
+					return nil, err
+				}
+			}
+			return map[string]string{"data": strings.Repeat("x", params.Size)}, nil
+		},
 		// Echo handler for test - returns client ID derived from requestID
 		"test": func(msg *shared.Message) (interface{}, error) {
 			// ID is 100 more than client ID in multiclient test
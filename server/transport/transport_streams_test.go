@@ -0,0 +1,411 @@
+package transport_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/transport"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSseStream opens a V2024 SSE stream and waits for the endpoint event.
+func openSseStream(t *testing.T, serverURL string) *http.Response {
+	t.Helper()
+	resp, err := makeSseGetRequest(t, serverURL+transport.PATH2024+"?key=valid-key", nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	event, _, _, err := readNextSseEvent(t, bufio.NewReader(resp.Body))
+	require.NoError(t, err)
+	require.Equal(t, "endpoint", event)
+	return resp
+}
+
+// Server-wide limit: streams beyond server.sse.max_streams are rejected with 503 and Retry-After,
+// and closing an open stream frees a slot.
+func Test_SRV_SSE_LIMIT_01_RejectsStreamsOverMaxAndFreesSlotOnClose(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetSSEMaxStreams(2)
+
+	first := openSseStream(t, server.URL)
+	defer first.Body.Close()
+	second := openSseStream(t, server.URL)
+	defer second.Body.Close()
+	assert.Equal(t, int64(2), tp.ActiveSSEStreams())
+
+	// Third stream exceeds the cap
+	rejected, err := makeSseGetRequest(t, server.URL+transport.PATH2024+"?key=valid-key", nil)
+	require.NoError(t, err)
+	rejected.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, rejected.StatusCode)
+	assert.NotEmpty(t, rejected.Header.Get("Retry-After"), "Retry-After header should be set")
+	assert.Equal(t, int64(2), tp.ActiveSSEStreams(), "Rejected stream must not be counted")
+
+	// Closing one stream frees a slot
+	first.Body.Close()
+	require.Eventually(t, func() bool {
+		return tp.ActiveSSEStreams() == 1
+	}, 2*time.Second, 50*time.Millisecond, "Slot should be released after client disconnect")
+
+	third := openSseStream(t, server.URL)
+	defer third.Body.Close()
+	assert.Equal(t, int64(2), tp.ActiveSSEStreams())
+}
+
+// Server-wide limit: POST requests asking for an SSE response are rejected before processing when the cap is reached.
+func Test_SRV_SSE_LIMIT_02_RejectsPostStreamOverMax(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	tp.NoStream2025 = false
+	cfg.SetSSEMaxStreams(1)
+
+	open := openSseStream(t, server.URL)
+	defer open.Body.Close()
+
+	initBody := createJsonRpcRequestBody(1, "initialize", schema2025.InitializeRequestParams{
+		ProtocolVersion: schema2025.PROTOCOL_VERSION,
+		ClientInfo:      schema2025.Implementation{Name: "test-client", Version: "1.0"},
+	})
+	resp, err := makePostRequest(t, server.URL+transport.PATH, initBody, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	assert.Equal(t, int64(1), tp.ActiveSSEStreams())
+}
+
+// sseResult is the outcome of a stream request sent in the background.
+type sseResult struct {
+	resp    *http.Response
+	err     error
+	elapsed time.Duration
+}
+
+func requestSseStreamAsync(t *testing.T, serverURL string) <-chan sseResult {
+	t.Helper()
+	results := make(chan sseResult, 1)
+	go func() {
+		start := time.Now()
+		resp, err := makeSseGetRequest(t, serverURL+transport.PATH2024+"?key=valid-key", nil)
+		results <- sseResult{resp: resp, err: err, elapsed: time.Since(start)}
+	}()
+	return results
+}
+
+// Queue: streams over the limit wait for a slot for up to server.sse.queue_wait and are
+// admitted as slots are freed.
+func Test_SRV_SSE_LIMIT_03_QueuedStreamsGetFreedSlots(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetSSEMaxStreams(1)
+	cfg.SetSSEQueue(2, 5*time.Second)
+
+	first := openSseStream(t, server.URL)
+	queued1 := requestSseStreamAsync(t, server.URL)
+	queued2 := requestSseStreamAsync(t, server.URL)
+	time.Sleep(200 * time.Millisecond) // Let both requests enter the queue
+
+	first.Body.Close()
+	var admitted sseResult
+	select {
+	case admitted = <-queued1:
+	case admitted = <-queued2:
+		queued2 = queued1
+	case <-time.After(5 * time.Second):
+		t.Fatal("No queued stream was admitted after a slot was freed")
+	}
+	require.NoError(t, admitted.err)
+	assert.Equal(t, http.StatusOK, admitted.resp.StatusCode)
+	assert.Equal(t, int64(1), tp.ActiveSSEStreams())
+
+	admitted.resp.Body.Close()
+	second := <-queued2
+	require.NoError(t, second.err)
+	defer second.resp.Body.Close()
+	assert.Equal(t, http.StatusOK, second.resp.StatusCode)
+}
+
+// Queue: streams beyond the queue size are rejected at once, queued streams are rejected
+// once the wait times out.
+func Test_SRV_SSE_LIMIT_04_RejectsBurstBeyondQueueAndWait(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetSSEMaxStreams(1)
+	wait := 300 * time.Millisecond
+	cfg.SetSSEQueue(1, wait)
+
+	open := openSseStream(t, server.URL)
+	defer open.Body.Close()
+
+	queued := requestSseStreamAsync(t, server.URL)
+	time.Sleep(100 * time.Millisecond) // Let the request take the only queue place
+	overflow := <-requestSseStreamAsync(t, server.URL)
+	require.NoError(t, overflow.err)
+	overflow.resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, overflow.resp.StatusCode)
+	assert.Less(t, overflow.elapsed, wait, "A stream finding the queue full should be rejected at once")
+
+	timedOut := <-queued
+	require.NoError(t, timedOut.err)
+	timedOut.resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, timedOut.resp.StatusCode)
+	assert.NotEmpty(t, timedOut.resp.Header.Get("Retry-After"))
+	assert.GreaterOrEqual(t, timedOut.elapsed, wait-50*time.Millisecond, "A queued stream should wait before being rejected")
+	assert.Equal(t, int64(1), tp.ActiveSSEStreams())
+}
+
+// Queue: freed slots go to queued streams in arrival order, and a new stream does not
+// take a slot while others are queued.
+func Test_SRV_SSE_LIMIT_05_QueuedStreamsAdmittedInArrivalOrder(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetSSEMaxStreams(1)
+	cfg.SetSSEQueue(2, 5*time.Second)
+
+	first := openSseStream(t, server.URL)
+	queued1 := requestSseStreamAsync(t, server.URL)
+	time.Sleep(100 * time.Millisecond) // Let the requests enter the queue in order
+	queued2 := requestSseStreamAsync(t, server.URL)
+	time.Sleep(100 * time.Millisecond)
+
+	admit := func(results <-chan sseResult, name string) *http.Response {
+		t.Helper()
+		select {
+		case result := <-results:
+			require.NoError(t, result.err)
+			require.Equal(t, http.StatusOK, result.resp.StatusCode)
+			return result.resp
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s was not admitted", name)
+			return nil
+		}
+	}
+	waiting := func(results <-chan sseResult, name string) {
+		t.Helper()
+		select {
+		case result := <-results:
+			if result.resp != nil {
+				result.resp.Body.Close()
+			}
+			t.Fatalf("%s was answered out of order", name)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	first.Body.Close()
+	second := admit(queued1, "The first queued stream")
+	waiting(queued2, "The second queued stream")
+
+	// A new stream queues behind the waiting one instead of taking the next slot
+	latecomer := requestSseStreamAsync(t, server.URL)
+	time.Sleep(100 * time.Millisecond)
+	second.Body.Close()
+	third := admit(queued2, "The second queued stream")
+	defer third.Body.Close()
+	waiting(latecomer, "The new stream")
+	assert.Equal(t, int64(1), tp.ActiveSSEStreams())
+
+	third.Body.Close()
+	last := admit(latecomer, "The new stream")
+	defer last.Body.Close()
+}
+
+// Queue: slots freed by raising server.sse.max_streams go to the queued streams before
+// new ones.
+func Test_SRV_SSE_LIMIT_06_RaisedLimitAdmitsQueuedStreamsFirst(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	cfg.SetSSEMaxStreams(1)
+	cfg.SetSSEQueue(2, 5*time.Second)
+
+	first := openSseStream(t, server.URL)
+	defer first.Body.Close()
+	queued := requestSseStreamAsync(t, server.URL)
+	time.Sleep(100 * time.Millisecond) // Let the request enter the queue
+
+	cfg.SetSSEMaxStreams(2)
+	latecomer := requestSseStreamAsync(t, server.URL)
+	select {
+	case result := <-queued:
+		require.NoError(t, result.err)
+		defer result.resp.Body.Close()
+		assert.Equal(t, http.StatusOK, result.resp.StatusCode)
+	case result := <-latecomer:
+		require.NoError(t, result.err)
+		result.resp.Body.Close()
+		t.Fatal("A new stream took the slot of the queued one")
+	case <-time.After(2 * time.Second):
+		t.Fatal("The queued stream was not admitted")
+	}
+	assert.Equal(t, int64(2), tp.ActiveSSEStreams())
+
+	first.Body.Close()
+	result := <-latecomer
+	require.NoError(t, result.err)
+	defer result.resp.Body.Close()
+	assert.Equal(t, http.StatusOK, result.resp.StatusCode)
+}
+
+// readKeepAlives reads lines of a stream until count keepalive comments arrived and
+// returns the other lines read meanwhile.
+func readKeepAlives(t *testing.T, reader *bufio.Reader, count int) []string {
+	t.Helper()
+	var others []string
+	for count > 0 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSpace(line)
+		switch {
+		case line == ": ping":
+			count--
+		case line != "":
+			others = append(others, line)
+		}
+	}
+	return others
+}
+
+// Keepalive: open streams get an SSE comment every server.sse.keepalive, so proxies do
+// not close them while they are silent.
+func Test_SRV_SSE_KEEPALIVE_01_CommentsOnSilentStreams(t *testing.T) {
+	tp, _, cfg, server, cleanup := setupServerTest(t)
+	defer cleanup()
+	tp.NoStream2025 = false
+	cfg.SetSSEKeepAlive(50 * time.Millisecond)
+
+	t.Run("V2024 stream", func(t *testing.T) {
+		resp, err := makeSseGetRequest(t, server.URL+transport.PATH2024+"?key=valid-key", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		reader := bufio.NewReader(resp.Body)
+		event, _, _, err := readNextSseEvent(t, reader)
+		require.NoError(t, err)
+		require.Equal(t, "endpoint", event)
+
+		start := time.Now()
+		assert.Empty(t, readKeepAlives(t, reader, 3), "Expected nothing but keepalives on the silent stream")
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("V2025 response stream", func(t *testing.T) {
+		initBody := createJsonRpcRequestBody(1, "initialize", schema2025.InitializeRequestParams{
+			ProtocolVersion: schema2025.PROTOCOL_VERSION,
+			ClientInfo:      schema2025.Implementation{Name: "test-client", Version: "1.0"},
+		})
+		respInit, err := makePostRequest(t, server.URL+transport.PATH, initBody, nil)
+		require.NoError(t, err)
+		respInit.Body.Close()
+		sessionIDHeader := map[string]string{transport.MCP_SESSION_HEADER: respInit.Header.Get(transport.MCP_SESSION_HEADER)}
+
+		body := createJsonRpcRequestBody(2, "test/slow", map[string]int{"delayMs": 500})
+		resp, err := makePostRequest(t, server.URL+transport.PATH, body, sessionIDHeader)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		reader := bufio.NewReader(resp.Body)
+		assert.Empty(t, readKeepAlives(t, reader, 3), "Expected keepalives before the response")
+
+		event, data, _, err := readNextSseEvent(t, reader)
+		require.NoError(t, err)
+		assert.Equal(t, "message", event)
+		assert.Contains(t, data, `"delayMs":500`)
+	})
+}
+
+// openSessionStream opens a V2024 SSE stream and returns its reader and the URL to post
+// the requests of its session to.
+func openSessionStream(t *testing.T, serverURL string) (*http.Response, *bufio.Reader, string) {
+	t.Helper()
+	resp, err := makeSseGetRequest(t, serverURL+transport.PATH2024+"?key=valid-key", nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	reader := bufio.NewReader(resp.Body)
+	event, endpoint, _, err := readNextSseEvent(t, reader)
+	require.NoError(t, err)
+	require.Equal(t, "endpoint", event)
+	return resp, reader, serverURL + endpoint
+}
+
+// postSlow posts a test/slow request answered after delay on the stream of the session.
+func postSlow(t *testing.T, postURL string, id int, delay time.Duration) {
+	t.Helper()
+	body := createJsonRpcRequestBody(id, "test/slow", map[string]int64{"delayMs": delay.Milliseconds()})
+	resp, err := makePostRequest(t, postURL, body, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+}
+
+// Shutdown: open streams get the answers of their running requests, then a close event,
+// and new streams are rejected meanwhile.
+func Test_SRV_SSE_SHUTDOWN_01_DrainsStreamsOnceRequestsAnswered(t *testing.T) {
+	tp, _, _, server, cleanup := setupServerTest(t)
+	defer cleanup()
+
+	resp, reader, postURL := openSessionStream(t, server.URL)
+	defer resp.Body.Close()
+	postSlow(t, postURL, 1, 300*time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // Let the request start
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- tp.Shutdown(ctx) }()
+
+	require.Eventually(t, func() bool {
+		rejected, err := makeSseGetRequest(t, server.URL+transport.PATH2024+"?key=valid-key", nil)
+		if err != nil {
+			return false
+		}
+		rejected.Body.Close()
+		return rejected.StatusCode == http.StatusServiceUnavailable && rejected.Header.Get("Retry-After") != ""
+	}, 2*time.Second, 20*time.Millisecond, "New streams should be rejected during the shutdown")
+
+	event, data, _, err := readNextSseEvent(t, reader)
+	require.NoError(t, err)
+	assert.Equal(t, "message", event)
+	assert.Contains(t, data, `"delayMs":300`)
+	event, _, _, err = readNextSseEvent(t, reader)
+	require.NoError(t, err)
+	assert.Equal(t, "close", event)
+
+	require.NoError(t, <-shutdownErr)
+	drained, forced := tp.ShutdownStreams()
+	assert.Equal(t, int64(1), drained)
+	assert.Equal(t, int64(0), forced)
+	assert.Equal(t, int64(0), tp.ActiveSSEStreams())
+}
+
+// Shutdown: streams whose requests are still running when the context is done are closed
+// at once and counted as forced.
+func Test_SRV_SSE_SHUTDOWN_02_ForcesStreamsAtDeadline(t *testing.T) {
+	tp, _, _, server, cleanup := setupServerTest(t)
+	defer cleanup()
+
+	idle := openSseStream(t, server.URL)
+	defer idle.Body.Close()
+	resp, reader, postURL := openSessionStream(t, server.URL)
+	defer resp.Body.Close()
+	postSlow(t, postURL, 1, 2*time.Second)
+	time.Sleep(50 * time.Millisecond) // Let the request start
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := tp.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second, "Shutdown should not wait for the running request")
+
+	event, _, _, err := readNextSseEvent(t, reader)
+	require.NoError(t, err)
+	assert.Equal(t, "close", event)
+
+	drained, forced := tp.ShutdownStreams()
+	assert.Equal(t, int64(1), drained, "The idle stream should be drained")
+	assert.Equal(t, int64(1), forced)
+}
@@ -0,0 +1,272 @@
+package transport_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/server/mcp/capability"
+	"github.com/gate4ai/mcp/server/transport"
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/config"
+	schema2025 "github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// wsTestClient is a bare WebSocket client that exposes every frame, control frames
+// included.
+type wsTestClient struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	sessionID string
+}
+
+// dialWebSocket opens a WebSocket to the transport at serverURL.
+func dialWebSocket(t *testing.T, serverURL string) *wsTestClient {
+	t.Helper()
+	host := strings.TrimPrefix(serverURL, "http://")
+	conn, err := net.Dial("tcp", host)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	_, err = io.WriteString(conn, "GET "+transport.PATH_WEBSOCKET+"?key=valid-key HTTP/1.1\r\nHost: "+host+
+		"\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: "+key+"\r\n\r\n")
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	digest := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	require.Equal(t, base64.StdEncoding.EncodeToString(digest[:]), resp.Header.Get("Sec-WebSocket-Accept"))
+	sessionID := resp.Header.Get(transport.MCP_SESSION_HEADER)
+	require.NotEmpty(t, sessionID, "Mcp-Session-Id header missing in handshake response")
+	return &wsTestClient{conn: conn, reader: reader, sessionID: sessionID}
+}
+
+// writeFrame writes a masked frame.
+func (c *wsTestClient) writeFrame(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.conn.Write(frame)
+	require.NoError(t, err)
+}
+
+// readFrame reads the next frame, failing with a timeout after wait.
+func (c *wsTestClient) readFrame(wait time.Duration) (byte, []byte, error) {
+	c.conn.SetReadDeadline(time.Now().Add(wait))
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return 0, nil, err
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	_, err := io.ReadFull(c.reader, payload)
+	return head[0] & 0x0F, payload, err
+}
+
+// send writes a JSON-RPC message as a text frame.
+func (c *wsTestClient) send(t *testing.T, body string) {
+	t.Helper()
+	c.writeFrame(t, 0x1, []byte(body))
+}
+
+// receive returns the next JSON-RPC message, skipping pings.
+func (c *wsTestClient) receive(t *testing.T) shared.Message {
+	t.Helper()
+	for {
+		opcode, payload, err := c.readFrame(3 * time.Second)
+		require.NoError(t, err)
+		if opcode == 0x9 {
+			continue
+		}
+		require.Equal(t, byte(0x1), opcode, "Expected a text frame, got opcode %d: %s", opcode, payload)
+		var msg shared.Message
+		require.NoError(t, json.Unmarshal(payload, &msg), "Invalid JSON-RPC message: %s", payload)
+		return msg
+	}
+}
+
+// expectClose reads frames until the close frame and returns its status code.
+func (c *wsTestClient) expectClose(t *testing.T, wait time.Duration) int {
+	t.Helper()
+	for {
+		opcode, payload, err := c.readFrame(wait)
+		require.NoError(t, err, "Expected a close frame")
+		if opcode == 0x8 {
+			require.GreaterOrEqual(t, len(payload), 2)
+			return int(binary.BigEndian.Uint16(payload))
+		}
+	}
+}
+
+func initializeWebSocket(t *testing.T, client *wsTestClient) {
+	t.Helper()
+	client.send(t, createJsonRpcRequestBody(1, "initialize", schema2025.InitializeRequestParams{
+		ProtocolVersion: schema2025.PROTOCOL_VERSION,
+		ClientInfo:      schema2025.Implementation{Name: "test-client", Version: "1.0"},
+	}))
+	resp := client.receive(t)
+	require.Equal(t, "1", resp.ID.String())
+	require.Nil(t, resp.Error)
+	client.send(t, createJsonRpcNotificationBody("notifications/initialized", nil))
+}
+
+// setupWebSocketServerTest starts a transport pinging WebSockets every pingInterval,
+// serving with ctx as the base context of requests.
+func setupWebSocketServerTest(t *testing.T, ctx context.Context, pingInterval time.Duration) (*MockMCPManager, *httptest.Server) {
+	t.Helper()
+	logger := zap.NewNop()
+	cfg := config.NewInternalConfig()
+	cfg.ServerNameValue = "TestServer"
+	mockManager := NewMockMCPManager(cfg, logger)
+	mockManager.AddCapability(capability.NewBase(logger, mockManager), &MockTestCapability{})
+	tp, err := transport.New(mockManager, logger, cfg, transport.WithWebSocketPingInterval(pingInterval))
+	require.NoError(t, err)
+	tp.SetAuthManager(&MockAuthenticator{Users: map[string]string{"valid-key": "test-user"}})
+
+	mux := http.NewServeMux()
+	tp.RegisterHandlers(mux)
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.BaseContext = func(net.Listener) context.Context { return ctx }
+	server.Start()
+	t.Cleanup(func() {
+		server.Close()
+		mockManager.CloseAllSessions()
+	})
+	return mockManager, server
+}
+
+// WebSocket transport: JSON-RPC messages of text frames are dispatched like those of a
+// POST, and the responses come back as text frames.
+func Test_SRV_WS_01_EchoesRequests(t *testing.T) {
+	mockManager, server := setupWebSocketServerTest(t, context.Background(), time.Minute)
+	client := dialWebSocket(t, server.URL)
+	initializeWebSocket(t, client)
+
+	// The echo handler answers with the request ID less 100
+	client.send(t, createJsonRpcRequestBody(105, "test", nil))
+	resp := client.receive(t)
+	assert.Equal(t, "105", resp.ID.String())
+	require.NotNil(t, resp.Result)
+	assert.JSONEq(t, `{"clientId":5}`, string(*resp.Result))
+
+	// Batches get a response per request
+	client.send(t, createJsonRpcBatchRequestBody(
+		createJsonRpcRequestBody(106, "test", nil),
+		createJsonRpcNotificationBody("notify/1", nil),
+		createJsonRpcRequestBody(107, "test", nil),
+	))
+	ids := []string{client.receive(t).ID.String(), client.receive(t).ID.String()}
+	assert.ElementsMatch(t, []string{"106", "107"}, ids)
+
+	client.send(t, "{not json")
+	parseErr := client.receive(t)
+	require.NotNil(t, parseErr.Error)
+	assert.Equal(t, shared.JSONRPCErrorParseError, parseErr.Error.Code)
+
+	// Closing the connection closes the session
+	client.writeFrame(t, 0x8, binary.BigEndian.AppendUint16(nil, 1000))
+	assert.Equal(t, 1000, client.expectClose(t, 3*time.Second))
+	require.Eventually(t, func() bool {
+		_, err := mockManager.GetSession(client.sessionID)
+		return err != nil
+	}, 3*time.Second, 10*time.Millisecond, "Session should be closed with its WebSocket")
+}
+
+// WebSocket keepalive: the server pings, clients answering stay connected and silent
+// ones are disconnected.
+func Test_SRV_WS_02_PingsAndDropsSilentClients(t *testing.T) {
+	_, server := setupWebSocketServerTest(t, context.Background(), 50*time.Millisecond)
+
+	t.Run("answering client", func(t *testing.T) {
+		client := dialWebSocket(t, server.URL)
+		for range 5 {
+			opcode, payload, err := client.readFrame(time.Second)
+			require.NoError(t, err)
+			require.Equal(t, byte(0x9), opcode, "Expected a ping")
+			client.writeFrame(t, 0xA, payload)
+		}
+		initializeWebSocket(t, client)
+	})
+
+	t.Run("silent client", func(t *testing.T) {
+		client := dialWebSocket(t, server.URL)
+		start := time.Now()
+		for {
+			_, _, err := client.readFrame(2 * time.Second)
+			if err != nil {
+				assert.ErrorIs(t, err, io.EOF, "Expected the server to drop the connection")
+				break
+			}
+		}
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+// WebSocket shutdown: connections get a going away close frame when the server context
+// is cancelled.
+func Test_SRV_WS_03_ClosesOnContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, server := setupWebSocketServerTest(t, ctx, time.Minute)
+	client := dialWebSocket(t, server.URL)
+	initializeWebSocket(t, client)
+
+	cancel()
+	assert.Equal(t, 1001, client.expectClose(t, 3*time.Second))
+}
+
+func Test_SRV_WS_04_RejectsPlainRequests(t *testing.T) {
+	_, server := setupWebSocketServerTest(t, context.Background(), time.Minute)
+	resp, err := http.Get(server.URL + transport.PATH_WEBSOCKET + "?key=valid-key")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+	assert.Equal(t, "websocket", resp.Header.Get("Upgrade"))
+}
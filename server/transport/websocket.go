@@ -0,0 +1,278 @@
+package transport
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server side of the WebSocket protocol (RFC 6455), as far as the WebSocket transport
+// needs it: the opening handshake, messages of any size up to wsMaxMessageSize in one or
+// more frames, ping/pong and the closing handshake. Extensions are not supported.
+
+const (
+	wsAcceptGUID  = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11" // Appended to the key of the client for Sec-WebSocket-Accept
+	wsSubprotocol = "mcp"                                  // Selected if the client offers it
+
+	// Opcodes
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	// Close status codes
+	wsCloseNormal          = 1000
+	wsCloseGoingAway       = 1001
+	wsCloseProtocolError   = 1002
+	wsCloseUnsupportedData = 1003
+	wsCloseMessageTooBig   = 1009
+
+	// Max size of a message of the client, in one or more frames
+	wsMaxMessageSize = 4 << 20
+	// Max wait for a frame to be written
+	wsWriteTimeout = 10 * time.Second
+	// Max payload of a control frame
+	wsMaxControlPayload = 125
+)
+
+// wsConn is a WebSocket connection taken over from an HTTP request. Frames may be written
+// from several goroutines; messages must be read from one.
+type wsConn struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	readWait time.Duration // Max silence of the client before reads fail, 0 for no limit
+
+	writeMu   sync.Mutex
+	closeSent bool // Guarded by writeMu
+}
+
+// isWebSocketUpgrade reports whether a request opens a WebSocket.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet && headerHasToken(r.Header, "Connection", "upgrade") && headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+// headerHasToken reports whether a comma separated header contains a token, ignoring case.
+func headerHasToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgradeWebSocket completes the opening handshake of a WebSocket, sending header with the
+// 101 response, and takes over the connection. If the handshake fails it replies with an
+// error status.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, header http.Header) (*wsConn, error) {
+	if !isWebSocketUpgrade(r) {
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, "Upgrade Required: expected a WebSocket handshake", http.StatusUpgradeRequired)
+		return nil, errors.New("not a WebSocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Bad Request: unsupported WebSocket version", statusBadRequest)
+		return nil, fmt.Errorf("unsupported WebSocket version '%s'", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "Bad Request: invalid Sec-WebSocket-Key", statusBadRequest)
+		return nil, errors.New("invalid Sec-WebSocket-Key")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket unsupported", statusInternalServerError)
+		return nil, errors.New("connection cannot be taken over")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take over the connection: %w", err)
+	}
+
+	digest := sha1.Sum([]byte(key + wsAcceptGUID))
+	var response strings.Builder
+	response.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	fmt.Fprintf(&response, "Sec-WebSocket-Accept: %s\r\n", base64.StdEncoding.EncodeToString(digest[:]))
+	if headerHasToken(r.Header, "Sec-WebSocket-Protocol", wsSubprotocol) {
+		fmt.Fprintf(&response, "Sec-WebSocket-Protocol: %s\r\n", wsSubprotocol)
+	}
+	for name, values := range header {
+		for _, value := range values {
+			fmt.Fprintf(&response, "%s: %s\r\n", name, value)
+		}
+	}
+	response.WriteString("\r\n")
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if _, err := io.WriteString(conn, response.String()); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send the handshake response: %w", err)
+	}
+	conn.SetWriteDeadline(time.Time{})
+	return &wsConn{conn: conn, reader: rw.Reader}, nil
+}
+
+// readMessage returns the opcode and payload of the next text or binary message. It
+// answers pings, and every frame received extends the read deadline by readWait. It
+// returns io.EOF once the client closed the connection; on a protocol violation it
+// starts the closing handshake and returns an error.
+func (c *wsConn) readMessage() (byte, []byte, error) {
+	var opcode byte
+	var message []byte
+	for {
+		if c.readWait > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.readWait))
+		}
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			code := wsCloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.writeClose(code, "")
+			return 0, nil, io.EOF
+		case wsOpText, wsOpBinary:
+			if opcode != 0 {
+				return 0, nil, c.fail(wsCloseProtocolError, "new message before the previous one ended")
+			}
+			opcode, message = op, payload
+		case wsOpContinuation:
+			if opcode == 0 {
+				return 0, nil, c.fail(wsCloseProtocolError, "continuation without a message")
+			}
+			if len(message)+len(payload) > wsMaxMessageSize {
+				return 0, nil, c.fail(wsCloseMessageTooBig, "message too big")
+			}
+			message = append(message, payload...)
+		default:
+			return 0, nil, c.fail(wsCloseProtocolError, fmt.Sprintf("unknown opcode %d", op))
+		}
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// readFrame reads and unmasks one frame of the client.
+func (c *wsConn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := head[0] & 0x0F
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(wsCloseProtocolError, "reserved bits set")
+	}
+	if head[1]&0x80 == 0 {
+		return false, 0, nil, c.fail(wsCloseProtocolError, "frames of the client must be masked")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= wsOpClose && (!fin || length > wsMaxControlPayload) {
+		return false, 0, nil, c.fail(wsCloseProtocolError, "invalid control frame")
+	}
+	if length > wsMaxMessageSize {
+		return false, 0, nil, c.fail(wsCloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes an unfragmented frame. Nothing is written after the close frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return net.ErrClosed
+	}
+	if opcode == wsOpClose {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|opcode)
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	frame = append(frame, payload...)
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// writeClose starts or completes the closing handshake.
+func (c *wsConn) writeClose(code int, reason string) error {
+	if len(reason) > wsMaxControlPayload-2 {
+		reason = reason[:wsMaxControlPayload-2]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return c.writeFrame(wsOpClose, append(payload, reason...))
+}
+
+// fail closes the connection for a protocol violation of the client.
+func (c *wsConn) fail(code int, reason string) error {
+	c.writeClose(code, reason)
+	return fmt.Errorf("websocket protocol error: %s", reason)
+}
+
+// close closes the connection without a closing handshake.
+func (c *wsConn) close() error {
+	return c.conn.Close()
+}
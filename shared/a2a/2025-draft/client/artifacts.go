@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// DefaultMaxFileSize is the size limit of DownloadArtifactFile unless set with
+// WithMaxFileSize.
+const DefaultMaxFileSize = 32 << 20
+
+// ErrFileTooLarge is returned by DownloadArtifactFile for files exceeding the size limit.
+var ErrFileTooLarge = errors.New("file exceeds the size limit")
+
+// WithMaxFileSize sets the size limit in bytes of files returned by DownloadArtifactFile,
+// by default DefaultMaxFileSize.
+func WithMaxFileSize(size int64) Option {
+	return func(c *Client) {
+		c.maxFileSize = size
+	}
+}
+
+// DownloadArtifactFile returns the content and MIME type of a file part of a message or
+// artifact. Inline content is decoded; content referenced by URI is downloaded with the
+// client's HTTP client, relative URIs being resolved against the agent URL. The
+// credentials of the client (see WithAuth) are only sent to the agent's own origin, so
+// they do not leak to file hosts named by the agent. The MIME type is the declared one,
+// else the Content-Type of the download, else detected from the content. Files larger
+// than the size limit (see WithMaxFileSize) fail with ErrFileTooLarge without being
+// read completely.
+func (c *Client) DownloadArtifactFile(ctx context.Context, part schema.Part) ([]byte, string, error) {
+	if partType, _ := schema.GetPartType(part); partType != "file" {
+		return nil, "", fmt.Errorf("part is not a file part")
+	}
+	fp, err := schema.AsFilePart(part)
+	if err != nil {
+		return nil, "", err
+	}
+	limit := c.maxFileSize
+	if limit <= 0 {
+		limit = DefaultMaxFileSize
+	}
+	mimeType := ""
+	if fp.File.MimeType != nil {
+		mimeType = *fp.File.MimeType
+	}
+
+	var data []byte
+	switch {
+	case fp.File.Bytes != nil:
+		if int64(base64.StdEncoding.DecodedLen(len(*fp.File.Bytes))) > limit+2 { // DecodedLen counts padding
+			return nil, "", fmt.Errorf("%w: inline content of about %d bytes, limit %d", ErrFileTooLarge, base64.StdEncoding.DecodedLen(len(*fp.File.Bytes)), limit)
+		}
+		if data, err = base64.StdEncoding.DecodeString(*fp.File.Bytes); err != nil {
+			return nil, "", fmt.Errorf("invalid base64 file content: %w", err)
+		}
+		if int64(len(data)) > limit {
+			return nil, "", fmt.Errorf("%w: inline content of %d bytes, limit %d", ErrFileTooLarge, len(data), limit)
+		}
+	case fp.File.URI != nil:
+		var contentType string
+		if data, contentType, err = c.downloadFile(ctx, *fp.File.URI, limit); err != nil {
+			return nil, "", err
+		}
+		if mimeType == "" {
+			mimeType, _, _ = mime.ParseMediaType(contentType)
+		}
+	default:
+		return nil, "", fmt.Errorf("file part has neither content nor URI")
+	}
+
+	if mimeType == "" {
+		mimeType = schema.DetectFileMimeType(schema.FileContent{Name: fp.File.Name, URI: fp.File.URI})
+	}
+	if mimeType == "" && len(data) > 0 {
+		mimeType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	return data, mimeType, nil
+}
+
+// downloadFile gets the file at rawURI and returns its content and Content-Type.
+func (c *Client) downloadFile(ctx context.Context, rawURI string, limit int64) ([]byte, string, error) {
+	base, err := url.Parse(c.agentURL + "/")
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid agent URL: %w", err)
+	}
+	ref, err := url.Parse(rawURI)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid file URI: %w", err)
+	}
+	fileURL := base.ResolveReference(ref)
+	if fileURL.Scheme != "http" && fileURL.Scheme != "https" {
+		return nil, "", fmt.Errorf("unsupported file URI scheme '%s'", fileURL.Scheme)
+	}
+
+	ctx, cancel := c.withTimeout(ctx, "")
+	defer cancel()
+	reqCtx, done, err := c.begin(ctx, false)
+	if err != nil {
+		return nil, "", err
+	}
+	defer done()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, fileURL.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create file request: %w", err)
+	}
+	if strings.EqualFold(fileURL.Scheme, base.Scheme) && strings.EqualFold(fileURL.Host, base.Host) {
+		if err := c.authorize(req); err != nil {
+			return nil, "", err
+		}
+		c.addMetadata(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", c.closedError(fmt.Errorf("file request failed: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", newStatusError("file", fileURL.String(), resp)
+	}
+	if resp.ContentLength > limit {
+		return nil, "", fmt.Errorf("%w: %s has %d bytes, limit %d", ErrFileTooLarge, fileURL, resp.ContentLength, limit)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", c.closedError(fmt.Errorf("failed to read file %s: %w", fileURL, err))
+	}
+	if int64(len(data)) > limit {
+		return nil, "", fmt.Errorf("%w: %s has more than %d bytes", ErrFileTooLarge, fileURL, limit)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// VerifyArtifact checks a complete (non-streamed) artifact against the checksum in its
+// metadata. Artifacts without a checksum pass. A mismatch returns an error wrapping
+// schema.ErrArtifactChecksumMismatch.
+func VerifyArtifact(artifact *schema.Artifact) error {
+	_, err := schema.VerifyArtifactChecksum(artifact, artifact.Parts)
+	return err
+}
+
+// ArtifactAssembler joins streamed artifact chunks by index and verifies the checksum of
+// every chunk that carries one against the content assembled so far.
+type ArtifactAssembler struct {
+	detectMimeTypes bool
+
+	mu        sync.Mutex
+	artifacts map[int]*schema.Artifact
+}
+
+// AssemblerOption configures an ArtifactAssembler.
+type AssemblerOption func(*ArtifactAssembler)
+
+// WithMimeDetection fills in the MIME type of assembled file parts that do not declare
+// one, for agents that do not detect it themselves. Declared types are kept.
+func WithMimeDetection() AssemblerOption {
+	return func(a *ArtifactAssembler) {
+		a.detectMimeTypes = true
+	}
+}
+
+// NewArtifactAssembler creates an empty assembler.
+func NewArtifactAssembler(opts ...AssemblerOption) *ArtifactAssembler {
+	a := &ArtifactAssembler{artifacts: make(map[int]*schema.Artifact)}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Add merges the chunk into the artifact with the same index and verifies the result.
+// On a checksum mismatch the chunk is not merged and an error wrapping
+// schema.ErrArtifactChecksumMismatch is returned.
+func (a *ArtifactAssembler) Add(chunk *schema.Artifact) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	parts := chunk.Parts
+	existing, exists := a.artifacts[chunk.Index]
+	if exists && chunk.Append != nil && *chunk.Append {
+		parts = append(append([]schema.Part(nil), existing.Parts...), chunk.Parts...)
+	}
+	if _, err := schema.VerifyArtifactChecksum(chunk, parts); err != nil {
+		return err
+	}
+
+	assembled := *chunk
+	assembled.Parts = parts
+	assembled.Append = nil
+	if a.detectMimeTypes {
+		assembled.Parts = append([]schema.Part(nil), parts...) // Detection rewrites parts, keep the chunk's intact
+		if _, err := assembled.DetectMimeTypes(); err != nil {
+			return err
+		}
+	}
+	a.artifacts[chunk.Index] = &assembled
+	return nil
+}
+
+// Artifact returns the artifact assembled at index, or nil if no chunk was added.
+func (a *ArtifactAssembler) Artifact(index int) *schema.Artifact {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.artifacts[index]
+}
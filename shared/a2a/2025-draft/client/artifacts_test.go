@@ -0,0 +1,121 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// filePart returns a file part with the given JSON file content.
+func filePart(file string) schema.Part {
+	return schema.Part(fmt.Sprintf(`{"type":"file","file":%s}`, file))
+}
+
+func TestDownloadArtifactFileInline(t *testing.T) {
+	c, err := New("http://agent.example", WithMaxFileSize(16))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+
+	for _, tc := range []struct {
+		name     string
+		part     schema.Part
+		data     []byte
+		mimeType string
+	}{
+		{
+			name:     "declared type",
+			part:     filePart(fmt.Sprintf(`{"mimeType":"text/csv","bytes":%q}`, base64.StdEncoding.EncodeToString([]byte("a,b")))),
+			data:     []byte("a,b"),
+			mimeType: "text/csv",
+		},
+		{
+			name:     "sniffed type",
+			part:     filePart(fmt.Sprintf(`{"bytes":%q}`, base64.StdEncoding.EncodeToString(png))),
+			data:     png,
+			mimeType: "image/png",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, mimeType, err := c.DownloadArtifactFile(context.Background(), tc.part)
+			if err != nil {
+				t.Fatalf("DownloadArtifactFile failed: %v", err)
+			}
+			if !bytes.Equal(data, tc.data) || mimeType != tc.mimeType {
+				t.Fatalf("Got %q (%s), want %q (%s)", data, mimeType, tc.data, tc.mimeType)
+			}
+		})
+	}
+
+	tooLarge := filePart(fmt.Sprintf(`{"bytes":%q}`, base64.StdEncoding.EncodeToString(make([]byte, 17))))
+	if _, _, err := c.DownloadArtifactFile(context.Background(), tooLarge); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("Expected ErrFileTooLarge, got %v", err)
+	}
+	if _, _, err := c.DownloadArtifactFile(context.Background(), schema.Part(`{"type":"text","text":"hi"}`)); err == nil {
+		t.Fatal("Expected an error for a text part")
+	}
+}
+
+func TestDownloadArtifactFileRemote(t *testing.T) {
+	var authorization []string
+	fileHost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/files/report.json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"ok":true}`))
+		case "/files/large":
+			w.Write(make([]byte, 64))
+		case "/files/streamed":
+			w.Write(make([]byte, 10))
+			w.(http.Flusher).Flush() // No Content-Length
+			w.Write(make([]byte, 54))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(fileHost.Close)
+	otherHost := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		w.Write([]byte("elsewhere"))
+	}))
+	t.Cleanup(otherHost.Close)
+
+	c, err := New(fileHost.URL, WithAuth(BearerToken("secret")), WithMaxFileSize(32))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	data, mimeType, err := c.DownloadArtifactFile(context.Background(), filePart(`{"uri":"files/report.json"}`))
+	if err != nil {
+		t.Fatalf("DownloadArtifactFile failed: %v", err)
+	}
+	if string(data) != `{"ok":true}` || mimeType != "application/json" {
+		t.Fatalf("Got %q (%s)", data, mimeType)
+	}
+
+	data, _, err = c.DownloadArtifactFile(context.Background(), filePart(fmt.Sprintf(`{"uri":%q}`, otherHost.URL+"/file.txt")))
+	if err != nil || string(data) != "elsewhere" {
+		t.Fatalf("DownloadArtifactFile from another host failed: %q, %v", data, err)
+	}
+	if len(authorization) != 2 || authorization[0] != "Bearer secret" || authorization[1] != "" {
+		t.Fatalf("Credentials must be sent to the agent's origin only, got %q", authorization)
+	}
+
+	for _, uri := range []string{"/files/large", "/files/streamed"} {
+		if _, _, err := c.DownloadArtifactFile(context.Background(), filePart(fmt.Sprintf(`{"uri":%q}`, uri))); !errors.Is(err, ErrFileTooLarge) {
+			t.Errorf("Expected ErrFileTooLarge for %s, got %v", uri, err)
+		}
+	}
+	if _, _, err := c.DownloadArtifactFile(context.Background(), filePart(`{"uri":"/files/missing"}`)); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
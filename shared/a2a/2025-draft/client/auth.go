@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// Auth supplies the credentials sent with every request to the agent: agent card
+// fetches, JSON-RPC calls and task subscriptions alike.
+type Auth interface {
+	// Header returns the header fields to add to a request made with ctx.
+	Header(ctx context.Context) (http.Header, error)
+}
+
+// AuthFunc is an Auth called for every request, e.g. to send short-lived tokens.
+type AuthFunc func(ctx context.Context) (http.Header, error)
+
+// Header calls f.
+func (f AuthFunc) Header(ctx context.Context) (http.Header, error) {
+	return f(ctx)
+}
+
+// StaticHeader is an Auth sending the same header fields with every request.
+type StaticHeader http.Header
+
+// Header returns a copy of the fields.
+func (h StaticHeader) Header(context.Context) (http.Header, error) {
+	return http.Header(h).Clone(), nil
+}
+
+// BearerToken returns an Auth sending token in an Authorization: Bearer header.
+func BearerToken(token string) Auth {
+	return StaticHeader{"Authorization": {"Bearer " + token}}
+}
+
+// APIKeyHeader is the header carrying credentials of the "apiKey" scheme.
+const APIKeyHeader = "X-API-Key"
+
+// WithAuth sets the credentials of requests to the agent. It takes precedence over
+// WithCredentials.
+func WithAuth(auth Auth) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// WithCredentials sets credentials sent in the authentication scheme the agent card
+// advertises, once FetchAgentInfo fetched it: "bearer" (the default), "apiKey" (in the
+// APIKeyHeader header) or "basic" (credentials being "user:password"). Without
+// WithAuth or WithCredentials, the credentials advertised in the card, if any, are sent.
+func WithCredentials(credentials string) Option {
+	return func(c *Client) {
+		c.credentials = credentials
+	}
+}
+
+// authorize adds the credentials of the client to req.
+func (c *Client) authorize(req *http.Request) error {
+	var header http.Header
+	if c.auth != nil {
+		var err error
+		if header, err = c.auth.Header(req.Context()); err != nil {
+			return fmt.Errorf("failed to get credentials: %w", err)
+		}
+	} else {
+		var advertised *schema.AgentAuthentication
+		if card := c.fetchedCard(); card != nil {
+			advertised = card.Authentication
+		}
+		header = schemeHeader(advertised, c.credentials)
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	return nil
+}
+
+// schemeHeader returns the header fields sending credentials, or those advertised if
+// credentials is empty, in the first advertised scheme the client supports.
+func schemeHeader(advertised *schema.AgentAuthentication, credentials string) http.Header {
+	if credentials == "" && advertised != nil && advertised.Credentials != nil {
+		credentials = *advertised.Credentials
+	}
+	if credentials == "" {
+		return nil
+	}
+	var schemes []string
+	if advertised != nil {
+		schemes = advertised.Schemes
+	}
+	for _, scheme := range schemes {
+		switch strings.ToLower(scheme) {
+		case "bearer":
+			return http.Header{"Authorization": {"Bearer " + credentials}}
+		case "apikey":
+			return http.Header{APIKeyHeader: {credentials}}
+		case "basic":
+			return http.Header{"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))}}
+		}
+	}
+	return http.Header{"Authorization": {"Bearer " + credentials}}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// authAgent is a mockAgent serving its card at the well-known path and recording the
+// credentials of every request.
+type authAgent struct {
+	*mockAgent
+	card string // JSON of the agent card
+
+	mu          sync.Mutex
+	credentials map[string][]string // Request kind -> Authorization and API key headers
+}
+
+func newAuthAgent(t *testing.T, card string) *authAgent {
+	t.Helper()
+	agent := &authAgent{mockAgent: &mockAgent{}, card: card, credentials: make(map[string][]string)}
+	agent.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kind := "rpc"
+		if r.Method == http.MethodGet {
+			kind = "card"
+		} else if r.Header.Get("Accept") == "text/event-stream" {
+			kind = "subscribe"
+		}
+		agent.mu.Lock()
+		agent.credentials[kind] = append(agent.credentials[kind], r.Header.Get("Authorization")+r.Header.Get(APIKeyHeader))
+		agent.mu.Unlock()
+		if kind == "card" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, agent.card)
+			return
+		}
+		agent.serve(w, r)
+	}))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+// received returns the credentials of the requests of kind "card", "rpc" or "subscribe".
+func (a *authAgent) received(kind string) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.credentials[kind]...)
+}
+
+// exercise fetches the agent card, gets a task and subscribes to one.
+func exercise(t *testing.T, c *Client) {
+	t.Helper()
+	if _, err := c.FetchAgentInfo(context.Background()); err != nil {
+		t.Fatalf("FetchAgentInfo failed: %v", err)
+	}
+	if _, err := c.GetTask(context.Background(), &schema.TaskQueryParams{ID: "t"}); err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	expectWorking(t, events)
+}
+
+func TestAuthAppliesToAllRequests(t *testing.T) {
+	var calls atomic.Int32
+	for name, auth := range map[string]Auth{
+		"bearer token":  BearerToken("secret"),
+		"static header": StaticHeader{"Authorization": {"Bearer secret"}},
+		"callback": AuthFunc(func(ctx context.Context) (http.Header, error) {
+			calls.Add(1)
+			return http.Header{"Authorization": {"Bearer secret"}}, nil
+		}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			agent := newAuthAgent(t, `{"name":"agent"}`)
+			c, err := New(agent.URL, WithAuth(auth))
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			t.Cleanup(func() { c.Close() })
+			exercise(t, c)
+
+			for _, kind := range []string{"card", "rpc", "subscribe"} {
+				if got := agent.received(kind); len(got) != 1 || got[0] != "Bearer secret" {
+					t.Fatalf("Expected the bearer token with the %s request, got %q", kind, got)
+				}
+			}
+		})
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("Expected the callback to be called for each of the 3 requests, got %d", n)
+	}
+}
+
+func TestAuthCallbackErrorFailsRequest(t *testing.T) {
+	agent := newAuthAgent(t, `{"name":"agent"}`)
+	errNoToken := errors.New("token expired")
+	c, err := New(agent.URL, WithAuth(AuthFunc(func(ctx context.Context) (http.Header, error) {
+		return nil, errNoToken
+	})))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	if _, err := c.GetTask(context.Background(), &schema.TaskQueryParams{ID: "t"}); !errors.Is(err, errNoToken) {
+		t.Fatalf("Expected the callback error, got %v", err)
+	}
+	if got := agent.received("rpc"); len(got) != 0 {
+		t.Fatalf("No request must be sent without credentials, got %q", got)
+	}
+}
+
+func TestCredentialsUseAdvertisedScheme(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		card        string
+		opts        []Option
+		credentials string
+	}{
+		{
+			name:        "api key",
+			card:        `{"name":"agent","authentication":{"schemes":["oauth2","apiKey"]}}`,
+			opts:        []Option{WithCredentials("secret")},
+			credentials: "secret",
+		},
+		{
+			name:        "basic",
+			card:        `{"name":"agent","authentication":{"schemes":["basic"]}}`,
+			opts:        []Option{WithCredentials("user:pass")},
+			credentials: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:        "unknown scheme",
+			card:        `{"name":"agent","authentication":{"schemes":["oauth2"]}}`,
+			opts:        []Option{WithCredentials("secret")},
+			credentials: "Bearer secret",
+		},
+		{
+			name:        "advertised credentials",
+			card:        `{"name":"agent","authentication":{"schemes":["apiKey"],"credentials":"public"}}`,
+			credentials: "public",
+		},
+		{
+			name:        "configured auth wins",
+			card:        `{"name":"agent","authentication":{"schemes":["apiKey"],"credentials":"public"}}`,
+			opts:        []Option{WithAuth(BearerToken("secret")), WithCredentials("other")},
+			credentials: "Bearer secret",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := newAuthAgent(t, tc.card)
+			c, err := New(agent.URL, tc.opts...)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			t.Cleanup(func() { c.Close() })
+			exercise(t, c)
+
+			for _, kind := range []string{"rpc", "subscribe"} {
+				if got := agent.received(kind); len(got) != 1 || got[0] != tc.credentials {
+					t.Fatalf("Expected %q with the %s request, got %q", tc.credentials, kind, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"go.uber.org/zap"
+)
+
+// errBatchRejected is returned by getTasksBatch when the agent does not accept JSON-RPC
+// batch requests.
+var errBatchRejected = errors.New("agent rejected the batch request")
+
+// BatchError is returned by GetTasks when some of the tasks could not be retrieved.
+// Errs is aligned with the queried tasks and holds nil for each task retrieved.
+type BatchError struct {
+	Errs []error
+}
+
+func (e *BatchError) Error() string {
+	var first error
+	failed := 0
+	for _, err := range e.Errs {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d of %d tasks failed: %v", failed, len(e.Errs), first)
+}
+
+// Unwrap returns the errors of the tasks that failed.
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// GetTasks retrieves the current state of several tasks (tasks/get) in a single JSON-RPC
+// batch request. The tasks are returned in the order of params, nil for each task that
+// could not be retrieved, e.g. because the agent does not know it; the errors of those
+// tasks are returned as a *BatchError. A failure of the whole request is returned as is.
+// The history length of each task is requested and applied as in GetTask.
+//
+// If the agent rejects the batch, answering 400 or 501 or with a single JSON-RPC error
+// instead of an array of responses, the tasks are retrieved one by one with GetTask,
+// and so are the tasks of later calls. The requests of a batch get generated IDs, also
+// with ContextWithRequestID. The batch is retried like GetTask, see WithRetry.
+func (c *Client) GetTasks(ctx context.Context, params []schema.TaskQueryParams) ([]*schema.Task, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	for i := range params {
+		if err := checkHistoryLength(params[i].HistoryLength); err != nil {
+			return nil, fmt.Errorf("task '%s': %w", params[i].ID, err)
+		}
+	}
+	if !c.batchRejected.Load() {
+		tasks, errs, err := c.getTasksBatch(ctx, params)
+		if err == nil {
+			return tasks, batchError(errs)
+		}
+		if !errors.Is(err, errBatchRejected) {
+			return nil, err
+		}
+		c.batchRejected.Store(true)
+		c.logger.Debug("Agent does not support batch requests, getting tasks one by one", zap.Error(err))
+	}
+
+	tasks := make([]*schema.Task, len(params))
+	errs := make([]error, len(params))
+	for i := range params {
+		tasks[i], errs[i] = c.GetTask(ctx, &params[i])
+	}
+	return tasks, batchError(errs)
+}
+
+// batchError returns a *BatchError of errs, nil if all of them are nil.
+func batchError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return &BatchError{Errs: errs}
+		}
+	}
+	return nil
+}
+
+// getTasksBatch sends tasks/get for each of params in a batch request and returns the
+// tasks and the errors of the individual requests, aligned with params.
+func (c *Client) getTasksBatch(ctx context.Context, params []schema.TaskQueryParams) ([]*schema.Task, []error, error) {
+	const method = "tasks/get"
+	requests := make([]schema.JSONRPCRequest, len(params))
+	indexes := make(map[string]int, len(params)) // Encoded request ID -> index in params
+	for i := range params {
+		encodedParams, err := json.Marshal(&params[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		rawParams := json.RawMessage(encodedParams)
+		var id any = requestID.Add(1)
+		requests[i] = schema.JSONRPCRequest{JSONRPC: schema.JSONRPCVersion, Method: method, Params: &rawParams, ID: &id}
+		indexes[fmt.Sprint(id)] = i
+	}
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode %s batch: %w", method, err)
+	}
+
+	ctx, cancel := c.withTimeout(ctx, method)
+	defer cancel()
+	var responses []json.RawMessage
+	retryable := func(err error) bool {
+		return transientError(err) && !errors.Is(err, errBatchRejected)
+	}
+	err = c.retry(ctx, method, retryable, func() error {
+		var err error
+		responses, err = c.postBatch(ctx, method, body, len(requests))
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tasks := make([]*schema.Task, len(params))
+	errs := make([]error, len(params))
+	answered := make([]bool, len(params))
+	for _, response := range responses {
+		var envelope struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal(response, &envelope); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode JSON-RPC response: %w", err)
+		}
+		var id any
+		_ = json.Unmarshal(envelope.ID, &id)
+		i, ok := indexes[fmt.Sprint(id)]
+		if !ok || answered[i] {
+			c.logger.Debug("Ignoring response of unknown ID in batch", zap.ByteString("id", envelope.ID))
+			continue
+		}
+		answered[i] = true
+		raw, err := decodeResponse(bytes.NewReader(response))
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		var task schema.Task
+		if err := json.Unmarshal(raw, &task); err != nil {
+			errs[i] = fmt.Errorf("failed to decode %s result: %w", method, err)
+			continue
+		}
+		limitHistory(&task, params[i].HistoryLength)
+		c.trackSession(&task)
+		c.trackTask(task.ID, task.Status.State)
+		tasks[i] = &task
+	}
+	if len(responses) > 0 && !slices.Contains(answered, true) {
+		// Typically an Invalid Request error with a null ID for each request
+		return nil, nil, fmt.Errorf("%w: no response matches a request", errBatchRejected)
+	}
+	for i := range params {
+		if !answered[i] {
+			errs[i] = fmt.Errorf("agent sent no response for task '%s'", params[i].ID)
+		}
+	}
+	return tasks, errs, nil
+}
+
+// postBatch sends a batch of n requests and returns the responses of the agent.
+func (c *Client) postBatch(ctx context.Context, method string, body []byte, n int) ([]json.RawMessage, error) {
+	reqCtx, done, err := c.begin(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	c.logger.Debug("Sending A2A batch request", zap.String("method", method), zap.Int("requests", n))
+	resp, err := c.postBody(reqCtx, method, body, http.Header{"Accept": {"application/json"}})
+	if err != nil {
+		var status *StatusError
+		if errors.As(err, &status) && (status.StatusCode == http.StatusBadRequest || status.StatusCode == http.StatusNotImplemented) {
+			return nil, fmt.Errorf("%w: %w", errBatchRejected, err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, c.closedError(fmt.Errorf("failed to read JSON-RPC response: %w", err))
+	}
+	if c.onResponse != nil {
+		c.onResponse(ctx, method, data)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '[' {
+		// A single response, typically a parse or invalid request error
+		if _, err := decodeResponse(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("%w: %w", errBatchRejected, err)
+		}
+		return nil, fmt.Errorf("%w: agent answered with a single response", errBatchRejected)
+	}
+	var responses []json.RawMessage
+	if err := json.Unmarshal(data, &responses); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON-RPC batch response: %w", err)
+	}
+	return responses, nil
+}
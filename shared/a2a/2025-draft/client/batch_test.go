@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// newBatchAgent serves tasks/get in JSON-RPC batches, answering in reverse order and
+// with a task-not-found error for the task "missing". It counts the HTTP requests.
+func newBatchAgent(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var batch []struct {
+			Method string                 `json:"method"`
+			Params schema.TaskQueryParams `json:"params"`
+			ID     json.RawMessage        `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var responses []string
+		for i := len(batch) - 1; i >= 0; i-- {
+			req := batch[i]
+			if req.Params.ID == "missing" {
+				responses = append(responses, fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":%d,"message":"Task not found"}}`, req.ID, schema.ErrorTaskNotFound))
+				continue
+			}
+			result, _ := json.Marshal(schema.Task{ID: req.Params.ID, Status: schema.TaskStatus{State: schema.TaskStateCompleted}})
+			responses = append(responses, fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "["+strings.Join(responses, ",")+"]")
+	}))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+func TestGetTasksBatch(t *testing.T) {
+	var requests atomic.Int32
+	agent := newBatchAgent(t, &requests)
+	c, err := New(agent.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tasks, err := c.GetTasks(context.Background(), []schema.TaskQueryParams{{ID: "a"}, {ID: "missing"}, {ID: "b"}})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("GetTasks error = %v, want a *BatchError", err)
+	}
+	if len(tasks) != 3 || tasks[0] == nil || tasks[0].ID != "a" || tasks[1] != nil || tasks[2] == nil || tasks[2].ID != "b" {
+		t.Fatalf("GetTasks = %+v, want a, nil, b", tasks)
+	}
+	var rpcErr *schema.JSONRPCError
+	if batchErr.Errs[0] != nil || !errors.As(batchErr.Errs[1], &rpcErr) || rpcErr.Code != schema.ErrorTaskNotFound || batchErr.Errs[2] != nil {
+		t.Errorf("BatchError.Errs = %v, want the task-not-found error of the second task only", batchErr.Errs)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Agent received %d requests, want a single batch", n)
+	}
+
+	if tasks, err := c.GetTasks(context.Background(), []schema.TaskQueryParams{{ID: "c"}}); err != nil || tasks[0].ID != "c" {
+		t.Errorf("GetTasks without failures = %+v, %v", tasks, err)
+	}
+}
+
+func TestGetTasksFallsBackToSingleRequests(t *testing.T) {
+	agent := newMockAgent(t) // Rejects batches with 400, as it decodes a single request
+	var requests atomic.Int32
+	c, err := New(agent.URL, WithOnRequest(func(ctx context.Context, method string, body []byte) {
+		requests.Add(1)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	params := []schema.TaskQueryParams{{ID: "a"}, {ID: "b"}}
+	tasks, err := c.GetTasks(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != "a" || tasks[1].ID != "b" {
+		t.Fatalf("GetTasks = %+v, want a and b in order", tasks)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("Client sent %d requests, want the rejected batch and one per task", n)
+	}
+
+	// The agent is not sent batches again
+	requests.Store(0)
+	if _, err := c.GetTasks(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Client sent %d requests, want one per task", n)
+	}
+}
+
+func TestGetTasksFallsBackOnSingleErrorResponse(t *testing.T) {
+	var batches atomic.Int32
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params schema.TaskQueryParams `json:"params"`
+			ID     json.RawMessage        `json:"id"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			// A batch, answered with a single JSON-RPC error as some agents do
+			batches.Add(1)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":null,"error":{"code":%d,"message":"Invalid Request"}}`, schema.ErrorInvalidRequest)
+			return
+		}
+		result, _ := json.Marshal(schema.Task{ID: req.Params.ID, Status: schema.TaskStatus{State: schema.TaskStateWorking}})
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer agent.Close()
+	c, err := New(agent.URL, WithRetry(3, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tasks, err := c.GetTasks(context.Background(), []schema.TaskQueryParams{{ID: "a"}, {ID: "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks[0].ID != "a" || tasks[1].ID != "b" {
+		t.Errorf("GetTasks = %+v, want a and b in order", tasks)
+	}
+	if n := batches.Load(); n != 1 {
+		t.Errorf("Agent received %d batches, want the rejected one only", n)
+	}
+}
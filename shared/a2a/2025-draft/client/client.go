@@ -0,0 +1,362 @@
+// Package client implements a client for agents speaking the A2A protocol.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"go.uber.org/zap"
+)
+
+// AgentCardWellKnownPath is the standard path of an agent card relative to the agent URL.
+const AgentCardWellKnownPath = "/.well-known/agent.json"
+
+// Client talks to a single A2A agent.
+type Client struct {
+	agentURL     string
+	cardPath     string // Custom agent card path or URL, tried before the standard locations
+	httpClient   *http.Client
+	logger       *zap.Logger
+	logSSEFrames bool               // Log heartbeats and skipped frames of event streams
+	insecure     bool               // Skip TLS certificate verification
+	idGenerator  shared.IDGenerator // IDs of sent tasks without one
+	onRequest    MessageHook        // See WithOnRequest
+	onResponse   MessageHook        // See WithOnResponse
+
+	metadata func(ctx context.Context) map[string]string // See WithMetadata
+
+	// Credentials of requests, see WithAuth and WithCredentials
+	auth        Auth
+	credentials string
+	card        *schema.AgentCard // Last fetched by FetchAgentInfo, nil before
+
+	// Establishing task subscriptions, see WithSubscribeConnectRetry
+	connectRetries int
+	connectBackoff time.Duration
+	connectTimeout time.Duration
+
+	// Re-establishing dropped task subscriptions, see WithStreamReconnect
+	reconnectAttempts int
+	reconnectBackoff  time.Duration
+
+	maxFileSize int64 // Of DownloadArtifactFile, see WithMaxFileSize
+
+	// Bounding calls without a deadline, see WithDefaultTimeout
+	defaultTimeout time.Duration
+	methodTimeouts map[string]time.Duration
+
+	// Retrying failed calls, see WithRetry
+	retryAttempts  int
+	retryBaseDelay time.Duration
+
+	batchRejected atomic.Bool // The agent rejected a batch request, see GetTasks
+
+	// Falling back to polling when event streams do not get through, see WithPollingFallback
+	firstEventTimeout time.Duration // 0 disables the fallback
+	pollInterval      time.Duration
+
+	// Lifecycle of requests and subscriptions, see Close
+	ctx           context.Context // Canceled by Close
+	cancel        context.CancelFunc
+	cancelOnClose bool // Send tasks/cancel for tracked non-terminal tasks on Close
+	mu            sync.Mutex
+	closed        bool
+	polling       bool              // Event streams of the agent deliver nothing, poll instead
+	openTasks     map[string]bool   // IDs of tasks last seen in a non-terminal state
+	sessions      map[string]string // Task ID -> session ID of open tasks, for ContinueTask
+	subscriptions sync.WaitGroup    // Running subscription readers
+
+	// Cancellation of streamed tasks, see CancelTask
+	streamed map[string]int           // Task ID -> number of running subscriptions
+	canceled map[string]*streamCancel // Task ID -> CancelTask call made while streamed
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithLogger sets the logger of the client.
+func WithLogger(logger *zap.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithSSEFrameLogging logs heartbeat and skipped frames of event streams received from
+// the agent at debug level, with running counts. Useful to tell a stalled stream from a
+// quiet one; off by default.
+func WithSSEFrameLogging() Option {
+	return func(c *Client) {
+		c.logSSEFrames = true
+	}
+}
+
+// WithAgentCardPath sets where the agent serves its card, for agents not following the
+// well-known path convention. path is either relative to the agent URL or a full URL.
+func WithAgentCardPath(path string) Option {
+	return func(c *Client) {
+		c.cardPath = path
+	}
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification for this client, so
+// agents with self-signed certificates can be reached during development. It is
+// INSECURE: any server can impersonate the agent. Never use it in production.
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) {
+		c.insecure = true
+	}
+}
+
+// WithHTTPClient sets the HTTP client of all requests to the agent: agent card fetches,
+// JSON-RPC calls and task subscriptions, e.g. to route them through a proxy, pin TLS
+// certificates or tune connection pooling. By default http.DefaultClient is used. A
+// Timeout of the client also cuts task subscriptions; bound calls with
+// WithDefaultTimeout instead.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithIDGenerator sets the generator of the IDs given to tasks sent without one, by
+// default shared.DefaultIDGenerator.
+func WithIDGenerator(gen shared.IDGenerator) Option {
+	return func(c *Client) {
+		c.idGenerator = gen
+	}
+}
+
+// New creates a client for the agent at agentURL.
+func New(agentURL string, opts ...Option) (*Client, error) {
+	if agentURL == "" {
+		return nil, fmt.Errorf("agent URL is required")
+	}
+	c := &Client{
+		agentURL:   strings.TrimSuffix(agentURL, "/"),
+		httpClient: http.DefaultClient,
+		logger:     zap.NewNop(),
+		openTasks:  make(map[string]bool),
+		sessions:   make(map[string]string),
+		streamed:   make(map[string]int),
+		canceled:   make(map[string]*streamCancel),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.idGenerator == nil {
+		c.idGenerator = shared.DefaultIDGenerator()
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.insecure {
+		c.logger.Warn("INSECURE: TLS certificate verification is disabled for the A2A agent, use only for development", zap.String("agent", c.agentURL))
+		if err := c.skipVerify(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// skipVerify replaces the HTTP client with a copy not verifying TLS certificates. The
+// client set with WithHTTPClient is left untouched.
+func (c *Client) skipVerify() error {
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	baseTransport, ok := base.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("WithInsecureSkipVerify requires an *http.Transport, the HTTP client has a %T", base)
+	}
+	transport := baseTransport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.InsecureSkipVerify = true
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	return nil
+}
+
+// FetchAgentInfo retrieves the agent card. The card is looked up at, in order:
+//  1. the path set with WithAgentCardPath, if any;
+//  2. the well-known path below the agent URL (AgentCardWellKnownPath);
+//  3. the agent URL itself, for agents configured with the full card URL.
+//
+// The first location returning a valid card wins; if none does, the errors of all
+// attempts are returned.
+//
+// With WithRetry, the lookup is repeated if any location failed with a transient error.
+func (c *Client) FetchAgentInfo(ctx context.Context) (*schema.AgentCard, error) {
+	ctx, cancel := c.withTimeout(ctx, "")
+	defer cancel()
+	var card *schema.AgentCard
+	err := c.retry(ctx, "agent card lookup", transientError, func() error {
+		var errs []error
+		for _, cardURL := range c.cardURLs() {
+			var err error
+			if card, err = c.fetchAgentCard(ctx, cardURL); err == nil {
+				c.setCard(card)
+				return nil
+			}
+			c.logger.Debug("Agent card not found", zap.String("url", cardURL), zap.Error(err))
+			errs = append(errs, err)
+		}
+		return fmt.Errorf("agent card of %s not found: %w", c.agentURL, errors.Join(errs...))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// fetchAgentCard retrieves the agent card from cardURL.
+func (c *Client) fetchAgentCard(ctx context.Context, cardURL string) (*schema.AgentCard, error) {
+	logger := c.logger.With(zap.String("url", cardURL))
+	logger.Debug("Fetching agent card")
+
+	resp, err := c.getAgentCard(ctx, cardURL, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var card schema.AgentCard
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		return nil, fmt.Errorf("failed to decode agent card from %s: %w", cardURL, err)
+	}
+	if card.Name == "" {
+		// Some other JSON document, e.g. an error of the agent's RPC endpoint
+		return nil, fmt.Errorf("response of %s is not an agent card", cardURL)
+	}
+	logger.Debug("Fetched agent card", zap.String("name", card.Name))
+	return &card, nil
+}
+
+// setCard remembers the agent card fetched last, for WithCredentials and the capability
+// checks of push notification calls.
+func (c *Client) setCard(card *schema.AgentCard) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.card = card
+}
+
+// fetchedCard returns the agent card fetched last, nil if none was.
+func (c *Client) fetchedCard() *schema.AgentCard {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.card
+}
+
+// cardURLs returns the locations of the agent card in the order they are tried.
+func (c *Client) cardURLs() []string {
+	urls := make([]string, 0, 3)
+	if c.cardPath != "" {
+		if strings.Contains(c.cardPath, "://") {
+			urls = append(urls, c.cardPath)
+		} else {
+			urls = append(urls, c.agentURL+"/"+strings.TrimPrefix(c.cardPath, "/"))
+		}
+	}
+	return append(urls, c.agentURL+AgentCardWellKnownPath, c.agentURL)
+}
+
+// FetchAgentCapabilities retrieves only the name, version and capabilities of the agent.
+// It asks the first card location (see FetchAgentInfo) for the card summary and falls
+// back to reducing the full card if the agent does not support summaries.
+func (c *Client) FetchAgentCapabilities(ctx context.Context) (*schema.AgentCardSummary, error) {
+	ctx, cancel := c.withTimeout(ctx, "")
+	defer cancel()
+	summaryURL := addQueryParam(c.cardURLs()[0], "summary=true")
+	logger := c.logger.With(zap.String("url", summaryURL))
+	logger.Debug("Fetching agent card summary")
+
+	resp, err := c.getAgentCard(ctx, summaryURL, schema.AgentCardSummaryMediaType+", application/json;q=0.9")
+	if err != nil {
+		logger.Debug("Agent card summary not available, fetching full card", zap.Error(err))
+		card, err := c.FetchAgentInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return card.Summary(), nil
+	}
+	defer resp.Body.Close()
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != schema.AgentCardSummaryMediaType {
+		// The agent ignored the summary request and returned its full card
+		var card schema.AgentCard
+		if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+			return nil, fmt.Errorf("failed to decode agent card: %w", err)
+		}
+		return card.Summary(), nil
+	}
+
+	var summary schema.AgentCardSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode agent card summary: %w", err)
+	}
+	logger.Debug("Fetched agent card summary", zap.String("name", summary.Name))
+	return &summary, nil
+}
+
+// addQueryParam appends a query parameter to rawURL.
+func addQueryParam(rawURL string, param string) string {
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + param
+	}
+	return rawURL + "?" + param
+}
+
+// newEventReader returns a reader of an event stream received from the agent.
+func (c *Client) newEventReader(body io.Reader) *shared.SSEReader {
+	if !c.logSSEFrames {
+		return shared.NewSSEReader(body)
+	}
+	return shared.NewSSEReader(body, shared.WithSSEFrameLogging(c.logger.With(zap.String("agent", c.agentURL))))
+}
+
+// getAgentCard sends a GET request for an agent card and returns the response if its
+// status is 200 OK. The caller must close the response body.
+func (c *Client) getAgentCard(ctx context.Context, cardURL string, accept string) (*http.Response, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, ErrClientClosed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent card request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+	c.addMetadata(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("agent card request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newStatusError("agent card", cardURL, resp)
+	}
+	return resp, nil
+}
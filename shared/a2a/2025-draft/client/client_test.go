@@ -0,0 +1,244 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSSEFrameLoggingOption(t *testing.T) {
+	stream := ": keepalive\n\nevent: ping\ndata: {}\n\ndata: {\"id\":\"t1\"}\n\n"
+
+	for name, enabled := range map[string]bool{"default": false, "enabled": true} {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			opts := []Option{WithLogger(zap.New(core))}
+			if enabled {
+				opts = append(opts, WithSSEFrameLogging())
+			}
+			c, err := New("http://agent.example", opts...)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			reader := c.newEventReader(strings.NewReader(stream))
+			event, err := reader.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if event.Data != `{"id":"t1"}` {
+				t.Fatalf("Heartbeats must not be surfaced as events, got %+v", event)
+			}
+			if reader.Heartbeats() != 2 {
+				t.Fatalf("Heartbeats = %d, want 2", reader.Heartbeats())
+			}
+
+			logged := logs.FilterMessage("SSE heartbeat received").Len()
+			if enabled && logged != 2 {
+				t.Fatalf("Expected 2 heartbeat log entries, got %d", logged)
+			}
+			if !enabled && logged != 0 {
+				t.Fatalf("Heartbeats must not be logged by default, got %d entries", logged)
+			}
+		})
+	}
+}
+
+// newCardServer starts an agent serving its card only at path.
+func newCardServer(t *testing.T, path string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(schema.AgentCard{Name: "agent at " + path, URL: server.URL, Version: "1.0"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchAgentInfoResolvesCardLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		cardPath  string // Path the agent serves its card at
+		agentPath string // Path appended to the server URL to form the agent URL
+		opts      func(serverURL string) []Option
+	}{
+		{name: "well-known path", cardPath: AgentCardWellKnownPath},
+		{name: "base URL", cardPath: "/agents/card.json", agentPath: "/agents/card.json"},
+		{
+			name:     "custom path",
+			cardPath: "/meta/card.json",
+			opts:     func(string) []Option { return []Option{WithAgentCardPath("meta/card.json")} },
+		},
+		{
+			name:     "custom full URL",
+			cardPath: "/meta/card.json",
+			opts:     func(serverURL string) []Option { return []Option{WithAgentCardPath(serverURL + "/meta/card.json")} },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newCardServer(t, tt.cardPath)
+			var opts []Option
+			if tt.opts != nil {
+				opts = tt.opts(server.URL)
+			}
+			c, err := New(server.URL+tt.agentPath, opts...)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			card, err := c.FetchAgentInfo(context.Background())
+			if err != nil {
+				t.Fatalf("FetchAgentInfo failed: %v", err)
+			}
+			if card.Name != "agent at "+tt.cardPath {
+				t.Fatalf("Unexpected card %q", card.Name)
+			}
+		})
+	}
+}
+
+func TestFetchAgentInfoFailsWithoutCard(t *testing.T) {
+	server := newCardServer(t, "/elsewhere.json")
+	c, err := New(server.URL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	_, err = c.FetchAgentInfo(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error when no location serves the card")
+	}
+	for _, tried := range []string{server.URL + AgentCardWellKnownPath, server.URL} {
+		if !strings.Contains(err.Error(), tried) {
+			t.Errorf("Error should mention the attempt at %s: %v", tried, err)
+		}
+	}
+}
+
+func TestInsecureSkipVerifyOption(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(schema.AgentCard{Name: "dev-agent", URL: "https://" + r.Host})
+	}))
+	defer server.Close()
+
+	strict, err := New(server.URL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := strict.FetchAgentInfo(context.Background()); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("Default client must reject the self-signed certificate, got %v", err)
+	}
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	insecure, err := New(server.URL, WithLogger(zap.New(core)), WithInsecureSkipVerify())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if logs.FilterMessageSnippet("INSECURE").Len() != 1 {
+		t.Fatalf("Expected a warning about disabled verification, got %v", logs.All())
+	}
+	card, err := insecure.FetchAgentInfo(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAgentInfo with verification disabled failed: %v", err)
+	}
+	if card.Name != "dev-agent" {
+		t.Fatalf("Unexpected card %q", card.Name)
+	}
+	if http.DefaultTransport.(*http.Transport).TLSClientConfig != nil && http.DefaultTransport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Fatal("The option must not affect the default transport")
+	}
+}
+
+// countingTransport counts the requests it sends per Accept header.
+type countingTransport struct {
+	base http.RoundTripper
+	mu   sync.Mutex
+	sent map[string]int
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.mu.Lock()
+	ct.sent[req.Header.Get("Accept")]++
+	ct.mu.Unlock()
+	return ct.base.RoundTrip(req)
+}
+
+func TestHTTPClientOption(t *testing.T) {
+	agent := newMockAgent(t)
+	transport := &countingTransport{base: http.DefaultTransport, sent: make(map[string]int)}
+	c, err := New(agent.URL, WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	c.FetchAgentInfo(context.Background()) // The mock agent serves no card, the attempts count
+	if _, err := c.GetTask(context.Background(), &schema.TaskQueryParams{ID: "t"}); err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	expectWorking(t, events)
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	for accept, want := range map[string]int{"application/json": 3, "text/event-stream": 1} {
+		if transport.sent[accept] != want {
+			t.Errorf("Expected %d requests accepting %s through the custom client, got %d", want, accept, transport.sent[accept])
+		}
+	}
+}
+
+func TestInsecureSkipVerifyKeepsCustomTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(schema.AgentCard{Name: "dev-agent"})
+	}))
+	defer server.Close()
+
+	var proxied atomic.Int32
+	custom := http.DefaultTransport.(*http.Transport).Clone()
+	custom.Proxy = func(*http.Request) (*url.URL, error) {
+		proxied.Add(1)
+		return nil, nil
+	}
+	httpClient := &http.Client{Transport: custom}
+	c, err := New(server.URL, WithHTTPClient(httpClient), WithInsecureSkipVerify())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := c.FetchAgentInfo(context.Background()); err != nil {
+		t.Fatalf("FetchAgentInfo failed: %v", err)
+	}
+	if proxied.Load() == 0 {
+		t.Error("Expected the proxy settings of the custom transport to be used")
+	}
+	if httpClient.Transport != custom || custom.TLSClientConfig != nil && custom.TLSClientConfig.InsecureSkipVerify {
+		t.Error("The option must not modify the custom client")
+	}
+
+	if _, err := New(server.URL, WithHTTPClient(&http.Client{Transport: &countingTransport{}}), WithInsecureSkipVerify()); err == nil {
+		t.Error("Expected an error for a transport whose TLS config cannot be changed")
+	}
+}
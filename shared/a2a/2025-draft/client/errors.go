@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// maxErrorBody limits how much of the body of an error response is read, and
+// maxErrorSnippet how much of it StatusError keeps.
+const (
+	maxErrorBody    = 64 << 10
+	maxErrorSnippet = 512
+)
+
+// StatusError is returned when the agent answers a request with a status other than
+// 200 OK: a JSON-RPC call, an agent card fetch or an artifact file download.
+type StatusError struct {
+	Method     string // The JSON-RPC method, "agent card" or "file"
+	URL        string // Of the request
+	StatusCode int
+	// Body is the start of the response body without surrounding whitespace, cut to
+	// 512 bytes, empty if the agent sent none.
+	Body string
+	// RPCError is the error of the JSON-RPC error response the agent sent as body,
+	// nil if the body is something else, e.g. an HTML error page of a proxy.
+	RPCError *schema.JSONRPCError
+
+	title string // Of an HTML body
+	html  bool
+}
+
+// newStatusError reads the body of resp, which failed with a status other than 200 OK,
+// into a *StatusError. The caller closes the body.
+func newStatusError(method, url string, resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	body = bytes.TrimSpace(body)
+	e := &StatusError{Method: method, URL: url, StatusCode: resp.StatusCode, Body: snippet(body)}
+
+	var envelope schema.JSONRPCResponse
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		e.RPCError = envelope.Error
+		return e
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	lower := bytes.ToLower(body[:min(len(body), 64)])
+	if mediaType == "text/html" || bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.HasPrefix(lower, []byte("<html")) {
+		e.html = true
+		if match := htmlTitle.FindSubmatch(body); match != nil {
+			e.title = strings.Join(strings.Fields(string(match[1])), " ")
+		}
+	}
+	return e
+}
+
+// htmlTitle matches the title of an HTML page.
+var htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// snippet returns body cut to maxErrorSnippet bytes, at a character boundary.
+func snippet(body []byte) string {
+	if len(body) <= maxErrorSnippet {
+		return string(body)
+	}
+	cut := maxErrorSnippet
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return string(body[:cut]) + "..."
+}
+
+func (e *StatusError) Error() string {
+	var detail string
+	switch {
+	case e.RPCError != nil:
+		detail = fmt.Sprintf("JSON-RPC error %d: %s", e.RPCError.Code, e.RPCError.Message)
+	case e.html && e.title != "":
+		detail = fmt.Sprintf("HTML page %q", e.title)
+	case e.html:
+		detail = "HTML page"
+	case e.Body == "":
+		detail = "empty body"
+	default:
+		detail = e.Body
+	}
+	msg := fmt.Sprintf("%s request to %s failed with status %d: %s", e.Method, e.URL, e.StatusCode, detail)
+	if e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden {
+		msg += " (check the credentials of the client, see WithAuth and WithCredentials)"
+	}
+	return msg
+}
+
+// Unwrap returns the JSON-RPC error of the body, if any.
+func (e *StatusError) Unwrap() error {
+	if e.RPCError == nil {
+		return nil
+	}
+	return e.RPCError
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantRPCCode int      // 0 if the body is not a JSON-RPC error
+		wantIn      []string // Parts of the error message
+		wantNotIn   []string
+	}{
+		{
+			name:        "JSON-RPC error body",
+			status:      http.StatusInternalServerError,
+			contentType: "application/json",
+			body:        `{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"store unavailable"}}`,
+			wantRPCCode: schema.ErrorInternalError,
+			wantIn:      []string{"status 500", "JSON-RPC error -32603: store unavailable"},
+			wantNotIn:   []string{"credentials"},
+		},
+		{
+			name:        "HTML error page",
+			status:      http.StatusForbidden,
+			contentType: "text/html; charset=utf-8",
+			body:        "<!DOCTYPE html>\n<html><head><title>403\n Forbidden</title></head><body>" + strings.Repeat("x", 2000) + "</body></html>",
+			wantIn:      []string{"status 403", `HTML page "403 Forbidden"`, "check the credentials"},
+			wantNotIn:   []string{"<body>"},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusUnauthorized,
+			wantIn: []string{"status 401: empty body", "check the credentials"},
+		},
+		{
+			name:        "plain text",
+			status:      http.StatusBadGateway,
+			contentType: "text/plain",
+			body:        "upstream connect error\n",
+			wantIn:      []string{"status 502: upstream connect error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer agent.Close()
+			c, err := New(agent.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			_, err = c.GetTask(context.Background(), &schema.TaskQueryParams{ID: "t1"})
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("GetTask error = %v, want a *StatusError", err)
+			}
+			if statusErr.StatusCode != tt.status || statusErr.URL != agent.URL || statusErr.Method != "tasks/get" {
+				t.Errorf("StatusError = %+v", statusErr)
+			}
+			if len(statusErr.Body) > maxErrorSnippet+len("...") {
+				t.Errorf("Body of %d bytes, want a snippet", len(statusErr.Body))
+			}
+			var rpcErr *schema.JSONRPCError
+			if tt.wantRPCCode != 0 && (!errors.As(err, &rpcErr) || rpcErr.Code != tt.wantRPCCode) {
+				t.Errorf("JSON-RPC error = %v, want code %d", rpcErr, tt.wantRPCCode)
+			}
+			if tt.wantRPCCode == 0 && statusErr.RPCError != nil {
+				t.Errorf("RPCError = %v, want none", statusErr.RPCError)
+			}
+			for _, want := range tt.wantIn {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Error %q does not contain %q", err, want)
+				}
+			}
+			for _, unwanted := range tt.wantNotIn {
+				if strings.Contains(err.Error(), unwanted) {
+					t.Errorf("Error %q contains %q", err, unwanted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MessageHook receives a JSON-RPC message exchanged with the agent as marshalled on the
+// wire, together with the context of the call and the method. Hooks are called
+// synchronously and must not modify body.
+type MessageHook func(ctx context.Context, method string, body []byte)
+
+// WithOnRequest calls hook with every JSON-RPC request before it is sent to the agent,
+// e.g. to log full exchanges or to assert on the wire format in tests.
+func WithOnRequest(hook MessageHook) Option {
+	return func(c *Client) {
+		c.onRequest = hook
+	}
+}
+
+// WithOnResponse calls hook with every JSON-RPC response received from the agent: the
+// responses of calls and every event of task subscriptions.
+func WithOnResponse(hook MessageHook) Option {
+	return func(c *Client) {
+		c.onResponse = hook
+	}
+}
+
+// requestIDKey is the context key of the JSON-RPC request ID set by ContextWithRequestID.
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context making calls of the client send id as their
+// JSON-RPC request ID, e.g. to find the request in the agent's logs. id must be a
+// string or a number. Retries of the call (see WithRetry) send it again. Without it,
+// every request gets a generated ID.
+func ContextWithRequestID(ctx context.Context, id any) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// nextRequestID returns the JSON-RPC ID of a request made with ctx.
+func nextRequestID(ctx context.Context) any {
+	if id := ctx.Value(requestIDKey{}); id != nil {
+		return id
+	}
+	return requestID.Add(1)
+}
+
+// readResponse reads a JSON-RPC response of method and returns its result, or its error.
+func (c *Client) readResponse(ctx context.Context, method string, body io.Reader) (json.RawMessage, error) {
+	if c.onResponse == nil {
+		return decodeResponse(body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON-RPC response: %w", err)
+	}
+	c.onResponse(ctx, method, data)
+	return decodeResponse(bytes.NewReader(data))
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// exchangeLog records the messages passed to the hooks.
+type exchangeLog struct {
+	mu        sync.Mutex
+	requests  []string
+	responses []string
+}
+
+func (l *exchangeLog) options() []Option {
+	record := func(messages *[]string) MessageHook {
+		return func(ctx context.Context, method string, body []byte) {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			*messages = append(*messages, method+" "+string(body))
+		}
+	}
+	return []Option{WithOnRequest(record(&l.requests)), WithOnResponse(record(&l.responses))}
+}
+
+// requestIDOf returns the JSON-RPC ID of a logged request.
+func requestIDOf(t *testing.T, logged string) any {
+	t.Helper()
+	var req schema.JSONRPCRequest
+	if err := json.Unmarshal([]byte(logged[strings.Index(logged, " ")+1:]), &req); err != nil {
+		t.Fatalf("Logged request is not JSON-RPC: %v", err)
+	}
+	return *req.ID
+}
+
+func TestExchangeHooks(t *testing.T) {
+	agent := newMockAgent(t)
+	var log exchangeLog
+	c, err := New(agent.URL, log.options()...)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	if _, err := c.GetTask(context.Background(), &schema.TaskQueryParams{ID: "t1"}); err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t2"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	expectWorking(t, events)
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if len(log.requests) != 2 || !strings.HasPrefix(log.requests[0], `tasks/get {"jsonrpc":"2.0","method":"tasks/get","params":{"id":"t1"}`) ||
+		!strings.HasPrefix(log.requests[1], "tasks/sendSubscribe ") {
+		t.Fatalf("Unexpected requests %q", log.requests)
+	}
+	if len(log.responses) != 2 || !strings.Contains(log.responses[0], `"id":"t1"`) ||
+		!strings.HasPrefix(log.responses[1], "tasks/sendSubscribe ") || !strings.Contains(log.responses[1], `"state":"working"`) {
+		t.Fatalf("Unexpected responses %q", log.responses)
+	}
+	if first, second := requestIDOf(t, log.requests[0]), requestIDOf(t, log.requests[1]); first == second {
+		t.Fatalf("Generated request IDs must differ, got %v twice", first)
+	}
+}
+
+func TestContextWithRequestID(t *testing.T) {
+	agent := newMockAgent(t)
+	var log exchangeLog
+	c, err := New(agent.URL, log.options()...)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	ctx := ContextWithRequestID(context.Background(), "debug-42")
+	if _, err := c.SendTask(ctx, &schema.TaskSendParams{ID: "t"}); err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if id := requestIDOf(t, log.requests[0]); id != "debug-42" {
+		t.Fatalf("Expected the supplied request ID, got %v", id)
+	}
+	if !strings.Contains(log.responses[0], `"id":"debug-42"`) {
+		t.Fatalf("Expected the agent to answer with the supplied ID, got %q", log.responses[0])
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// historyTexts returns the text of the first part of each message of the task history.
+func historyTexts(t *testing.T, task *schema.Task) []string {
+	t.Helper()
+	var texts []string
+	for _, message := range task.History {
+		part, err := schema.AsTextPart(message.Parts[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, part.Text)
+	}
+	return texts
+}
+
+func TestHistoryLength(t *testing.T) {
+	history := []schema.Message{answer("first"), answer("second"), answer("third")}
+	var mu sync.Mutex
+	var requested []*int // historyLength of each request
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Params schema.TaskQueryParams `json:"params"`
+			ID     json.RawMessage        `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requested = append(requested, req.Params.HistoryLength)
+		mu.Unlock()
+		task := schema.Task{ID: req.Params.ID, Status: schema.TaskStatus{State: schema.TaskStateWorking}}
+		switch {
+		case req.Params.ID == "ignores-length":
+			task.History = history
+		case req.Params.HistoryLength != nil:
+			task.History = history[len(history)-min(*req.Params.HistoryLength, len(history)):]
+		}
+		result, _ := json.Marshal(task)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer agent.Close()
+	c, err := New(agent.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	length := func(n int) *int { return &n }
+
+	task, err := c.GetTask(ctx, &schema.TaskQueryParams{ID: "t1", HistoryLength: length(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts := historyTexts(t, task); !slices.Equal(texts, []string{"second", "third"}) {
+		t.Errorf("GetTask history = %v, want the last two messages", texts)
+	}
+
+	task, err = c.SendTask(ctx, &schema.TaskSendParams{ID: "t1", Message: answer("fourth"), HistoryLength: length(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts := historyTexts(t, task); !slices.Equal(texts, []string{"first", "second", "third"}) {
+		t.Errorf("SendTask history = %v, want all three messages", texts)
+	}
+
+	// Without a history length, nothing is asked for
+	task, err = c.GetTask(ctx, &schema.TaskQueryParams{ID: "t1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.History != nil {
+		t.Errorf("GetTask history = %+v, want none", task.History)
+	}
+
+	// An agent returning more than asked for is cut to the requested length
+	task, err = c.GetTask(ctx, &schema.TaskQueryParams{ID: "ignores-length", HistoryLength: length(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts := historyTexts(t, task); !slices.Equal(texts, []string{"third"}) {
+		t.Errorf("GetTask history = %v, want the last message", texts)
+	}
+
+	mu.Lock()
+	if len(requested) != 4 || *requested[0] != 2 || *requested[1] != 10 || requested[2] != nil || *requested[3] != 1 {
+		t.Errorf("Agent received history lengths %v", requested)
+	}
+	mu.Unlock()
+
+	if _, err := c.GetTask(ctx, &schema.TaskQueryParams{ID: "t1", HistoryLength: length(-1)}); err == nil {
+		t.Error("GetTask with a negative history length succeeded")
+	}
+}
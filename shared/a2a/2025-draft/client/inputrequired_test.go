@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// questioningAgent asks which language to use before completing a task. The answer is
+// the text of the follow-up message; the session of the question must be kept.
+type questioningAgent struct {
+	*httptest.Server
+	mu       sync.Mutex
+	answers  []string
+	sessions []string
+	answered chan struct{} // Closed when the answer arrived, ends the subscription
+}
+
+func newQuestioningAgent(t *testing.T) *questioningAgent {
+	t.Helper()
+	agent := &questioningAgent{answered: make(chan struct{})}
+	question := schema.Message{Role: "agent", Parts: []schema.Part{schema.Part(`{"type":"text","text":"Which language?"}`)}}
+	session := "s1"
+	status := func(taskID string, state schema.TaskState) schema.TaskStatusUpdateEvent {
+		event := schema.TaskStatusUpdateEvent{ID: taskID, Status: schema.TaskStatus{State: state}, Final: state.IsFinal()}
+		if state == schema.TaskStateInputRequired {
+			event.Status.Message = &question
+		}
+		return event
+	}
+	agent.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                `json:"method"`
+			Params schema.TaskSendParams `json:"params"`
+			ID     any                   `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "tasks/send":
+			state := schema.TaskStateInputRequired
+			agent.mu.Lock()
+			if len(req.Params.Message.Parts) > 0 && req.Params.SessionID != nil {
+				text, _ := schema.AsTextPart(req.Params.Message.Parts[0])
+				agent.answers = append(agent.answers, text.Text)
+				agent.sessions = append(agent.sessions, *req.Params.SessionID)
+				state = schema.TaskStateCompleted
+				close(agent.answered)
+			}
+			agent.mu.Unlock()
+			result, _ := json.Marshal(schema.Task{ID: req.Params.ID, SessionID: &session, Status: status(req.Params.ID, state).Status})
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":%s}`, req.ID, result)
+		case "tasks/sendSubscribe":
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, state := range []schema.TaskState{schema.TaskStateWorking, schema.TaskStateInputRequired} {
+				update, _ := json.Marshal(status(req.Params.ID, state))
+				fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":%s}\n\n", req.ID, update)
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-agent.answered:
+			case <-r.Context().Done():
+				return
+			}
+			update, _ := json.Marshal(status(req.Params.ID, schema.TaskStateCompleted))
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":%s}\n\n", req.ID, update)
+		}
+	}))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+// answer returns a user message with the text.
+func answer(text string) schema.Message {
+	return schema.Message{Parts: []schema.Part{schema.Part(fmt.Sprintf(`{"type":"text","text":%q}`, text))}}
+}
+
+func TestContinueTaskAnswersQuestion(t *testing.T) {
+	agent := newQuestioningAgent(t)
+	c, err := New(agent.URL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	task, err := c.SendTask(context.Background(), &schema.TaskSendParams{ID: "t", Message: answer("Write a parser")})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if task.Status.State != schema.TaskStateInputRequired || task.Status.Message == nil {
+		t.Fatalf("Expected the task to ask for input, got %+v", task.Status)
+	}
+
+	task, err = c.ContinueTask(context.Background(), "t", answer("Go"))
+	if err != nil {
+		t.Fatalf("ContinueTask failed: %v", err)
+	}
+	if task.Status.State != schema.TaskStateCompleted {
+		t.Fatalf("Expected the task to complete after the answer, got %+v", task.Status)
+	}
+	if len(agent.answers) != 1 || agent.answers[0] != "Go" || agent.sessions[0] != "s1" {
+		t.Fatalf("Expected the answer in the session of the task, got %q in %q", agent.answers, agent.sessions)
+	}
+}
+
+func TestSubscribeSurfacesInputRequired(t *testing.T) {
+	agent := newQuestioningAgent(t)
+	c, err := New(agent.URL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	session := "s1"
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t", SessionID: &session, Message: answer("Write a parser")})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	var states []schema.TaskState
+	questions := 0
+	for event := range events {
+		if event.Err != nil {
+			t.Fatalf("Subscription failed: %v", event.Err)
+		}
+		if event.Status == nil {
+			continue
+		}
+		states = append(states, event.Status.Status.State)
+		if event.InputRequired() {
+			questions++
+			if _, err := c.ContinueTask(context.Background(), "t", answer("Go")); err != nil {
+				t.Fatalf("ContinueTask failed: %v", err)
+			}
+		}
+	}
+	if questions != 1 {
+		t.Fatalf("Expected one question, got %d", questions)
+	}
+	if last := states[len(states)-1]; last != schema.TaskStateCompleted {
+		t.Fatalf("Expected the subscription to end completed, got %v", states)
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// MetadataHeaderPrefix prefixes the header fields carrying the metadata set with
+// WithMetadata: the key "Correlation-Id" is sent as "X-A2A-Meta-Correlation-Id".
+const MetadataHeaderPrefix = "X-A2A-Meta-"
+
+// WithMetadata sends the key/values metadata returns for the context of each request as
+// header fields named MetadataHeaderPrefix + key, e.g. to pass a correlation or tenant
+// ID of the caller on to the agent for tracing or routing. metadata is called for every
+// request to the agent, including task subscriptions, their reconnects and polls, agent
+// card fetches and downloads of artifact files served by the agent, so values derived
+// from ctx stay current. Keys must be valid header field names and values must not
+// contain line breaks; invalid pairs are logged and left out.
+func WithMetadata(metadata func(ctx context.Context) map[string]string) Option {
+	return func(c *Client) {
+		c.metadata = metadata
+	}
+}
+
+// addMetadata adds the metadata of the request's context to req, see WithMetadata.
+func (c *Client) addMetadata(req *http.Request) {
+	if c.metadata == nil {
+		return
+	}
+	for key, value := range c.metadata(req.Context()) {
+		if !validHeaderName(key) || strings.ContainsAny(value, "\r\n\x00") {
+			c.logger.Warn("Leaving out invalid A2A request metadata", zap.String("key", key))
+			continue
+		}
+		req.Header.Set(MetadataHeaderPrefix+key, value)
+	}
+}
+
+// validHeaderName reports whether name is a non-empty HTTP token (RFC 9110).
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
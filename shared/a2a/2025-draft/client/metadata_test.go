@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+type correlationIDKey struct{}
+
+func TestMetadataHeaders(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]http.Header) // Method -> header of its last request
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string              `json:"method"`
+			Params schema.TaskIdParams `json:"params"`
+			ID     json.RawMessage     `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received[req.Method] = r.Header.Clone()
+		mu.Unlock()
+		update := schema.TaskStatusUpdateEvent{ID: req.Params.ID, Status: schema.TaskStatus{State: schema.TaskStateCompleted}, Final: true}
+		result, _ := json.Marshal(update)
+		if req.Method == "tasks/sendSubscribe" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":%s}\n\n", req.ID, result)
+			return
+		}
+		result, _ = json.Marshal(schema.Task{ID: req.Params.ID, Status: update.Status})
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer agent.Close()
+
+	c, err := New(agent.URL, WithAuth(BearerToken("secret")), WithMetadata(func(ctx context.Context) map[string]string {
+		metadata := map[string]string{"Tenant-Id": "acme", "bad key": "left out"}
+		if id, ok := ctx.Value(correlationIDKey{}).(string); ok {
+			metadata["Correlation-Id"] = id
+		}
+		return metadata
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx := context.WithValue(context.Background(), correlationIDKey{}, "req-1")
+	if _, err := c.GetTask(ctx, &schema.TaskQueryParams{ID: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	// Values are computed for each call
+	ctx = context.WithValue(context.Background(), correlationIDKey{}, "req-2")
+	events, err := c.SendTaskSubscribe(ctx, &schema.TaskSendParams{ID: "t2", Message: schema.Message{Role: "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range events {
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for method, want := range map[string]string{"tasks/get": "req-1", "tasks/sendSubscribe": "req-2"} {
+		header := received[method]
+		if got := header.Get("X-A2A-Meta-Correlation-Id"); got != want {
+			t.Errorf("%s: correlation ID = %q, want %q", method, got, want)
+		}
+		if got := header.Get("X-A2A-Meta-Tenant-Id"); got != "acme" {
+			t.Errorf("%s: tenant ID = %q", method, got)
+		}
+		if got := header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("%s: Authorization = %q, want the credentials kept", method, got)
+		}
+		metadataFields := 0
+		for name := range header {
+			if strings.HasPrefix(name, "X-A2a-Meta-") {
+				metadataFields++
+			}
+		}
+		if metadataFields != 2 {
+			t.Errorf("%s: %d metadata fields sent, want the invalid key left out", method, metadataFields)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"go.uber.org/zap"
+)
+
+// Defaults of WithPollingFallback.
+const (
+	DefaultFirstEventTimeout = 5 * time.Second
+	DefaultPollInterval      = time.Second
+)
+
+// WithPollingFallback detects event streams broken by proxies that buffer responses: if
+// the stream of SendTaskSubscribe delivers no event within firstEventTimeout although
+// the agent accepted the request, the client stops reading it and polls the task with
+// tasks/get every pollInterval instead, streaming the changes it sees. The decision is
+// remembered, so later subscriptions send tasks/send and poll right away. Agents should
+// send the first status update at once for the detection to be reliable. Zero values
+// select DefaultFirstEventTimeout and DefaultPollInterval.
+func WithPollingFallback(firstEventTimeout, pollInterval time.Duration) Option {
+	return func(c *Client) {
+		if firstEventTimeout <= 0 {
+			firstEventTimeout = DefaultFirstEventTimeout
+		}
+		if pollInterval <= 0 {
+			pollInterval = DefaultPollInterval
+		}
+		c.firstEventTimeout = firstEventTimeout
+		c.pollInterval = pollInterval
+	}
+}
+
+// Polling reports whether the client fell back to polling because an event stream of
+// the agent delivered nothing, see WithPollingFallback.
+func (c *Client) Polling() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.polling
+}
+
+// fallBackToPolling remembers that event streams of the agent do not get through.
+func (c *Client) fallBackToPolling(taskID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.polling {
+		c.logger.Warn("Task event stream delivered no event, falling back to polling; a proxy may be buffering the stream",
+			zap.String("agent", c.agentURL), zap.String("task", taskID), zap.Duration("firstEventTimeout", c.firstEventTimeout))
+	}
+	c.polling = true
+}
+
+// sendTaskPolling is SendTaskSubscribe for an agent whose event streams do not get
+// through: it sends tasks/send and polls the task until it reaches a final state.
+func (c *Client) sendTaskPolling(reqCtx context.Context, done func(), params *schema.TaskSendParams) (<-chan TaskEvent, error) {
+	var task schema.Task
+	if err := c.call(reqCtx, "tasks/send", params, &task); err != nil {
+		done()
+		c.subscriptions.Done()
+		return nil, err
+	}
+	c.trackTask(task.ID, task.Status.State)
+	c.beginStream(params.ID)
+
+	events := make(chan TaskEvent)
+	go func() {
+		defer c.subscriptions.Done()
+		defer done()
+		defer close(events)
+		defer c.endStream(params.ID)
+
+		send := func(event TaskEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-reqCtx.Done():
+				return false
+			}
+		}
+		var poll taskPoll
+		if poll.update(&task, send) {
+			c.pollTask(reqCtx, params.ID, &poll, true, send)
+		}
+	}()
+	return events, nil
+}
+
+// taskPoll is what a polled task has been reported as so far.
+type taskPoll struct {
+	seen      bool
+	status    schema.TaskStatus
+	artifacts int
+}
+
+// update sends the artifacts and status of the task that changed since the last update
+// and reports whether polling should go on.
+func (p *taskPoll) update(task *schema.Task, send func(TaskEvent) bool) bool {
+	for ; p.artifacts < len(task.Artifacts); p.artifacts++ {
+		if !send(TaskEvent{Artifact: &schema.TaskArtifactUpdateEvent{ID: task.ID, Artifact: task.Artifacts[p.artifacts]}}) {
+			return false
+		}
+	}
+	final := task.Status.State.IsFinal()
+	if !p.seen || task.Status.State != p.status.State || !task.Status.Timestamp.Equal(p.status.Timestamp) || final {
+		p.seen = true
+		p.status = task.Status
+		if !send(TaskEvent{Status: &schema.TaskStatusUpdateEvent{ID: task.ID, Status: task.Status, Final: final}}) {
+			return false
+		}
+	}
+	return !final
+}
+
+// pollTask gets the task every poll interval, at once unless wait is set, and sends its
+// changes until it reaches a final state, the request fails or reqCtx is done. The
+// interval is DefaultPollInterval unless set with WithPollingFallback.
+func (c *Client) pollTask(reqCtx context.Context, taskID string, poll *taskPoll, wait bool, send func(TaskEvent) bool) {
+	interval := c.pollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if wait {
+			select {
+			case <-ticker.C:
+			case <-reqCtx.Done():
+				return
+			}
+		}
+		wait = true
+
+		task, err := c.GetTask(reqCtx, &schema.TaskQueryParams{ID: taskID})
+		if err != nil {
+			if reqCtx.Err() == nil {
+				send(TaskEvent{Err: err})
+			}
+			return
+		}
+		if !poll.update(task, send) {
+			return
+		}
+	}
+}
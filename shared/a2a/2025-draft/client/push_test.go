@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// pushAgent stores the push notification configs it receives with tasks/send and
+// tasks/pushNotification/set. Without the capability, it rejects them with
+// ErrorPushNotificationNotSupported.
+type pushAgent struct {
+	*httptest.Server
+	mu      sync.Mutex
+	configs map[string]schema.PushNotificationConfig
+}
+
+func newPushAgent(t *testing.T, supported bool) *pushAgent {
+	t.Helper()
+	agent := &pushAgent{configs: make(map[string]schema.PushNotificationConfig)}
+	agent.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"name":"pusher","capabilities":{"pushNotifications":%v}}`, supported)
+			return
+		}
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     any             `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		respond := func(result any) {
+			raw, _ := json.Marshal(result)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":%s}`, req.ID, raw)
+		}
+		if !supported && req.Method != "tasks/get" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"error":{"code":%d,"message":"Push Notification is not supported"}}`, req.ID, schema.ErrorPushNotificationNotSupported)
+			return
+		}
+
+		agent.mu.Lock()
+		defer agent.mu.Unlock()
+		switch req.Method {
+		case "tasks/send":
+			var params schema.TaskSendParams
+			_ = json.Unmarshal(req.Params, &params)
+			if params.PushNotification != nil {
+				agent.configs[params.ID] = *params.PushNotification
+			}
+			respond(schema.Task{ID: params.ID, Status: schema.TaskStatus{State: schema.TaskStateWorking}})
+		case "tasks/pushNotification/set":
+			var params schema.TaskPushNotificationConfig
+			_ = json.Unmarshal(req.Params, &params)
+			agent.configs[params.ID] = params.PushNotificationConfig
+			respond(params)
+		case "tasks/pushNotification/get":
+			var params schema.TaskIdParams
+			_ = json.Unmarshal(req.Params, &params)
+			respond(schema.TaskPushNotificationConfig{ID: params.ID, PushNotificationConfig: agent.configs[params.ID]})
+		}
+	}))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+func TestPushNotificationConfig(t *testing.T) {
+	agent := newPushAgent(t, true)
+	c, err := New(agent.URL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	if _, err := c.FetchAgentInfo(context.Background()); err != nil {
+		t.Fatalf("FetchAgentInfo failed: %v", err)
+	}
+	token := "callback-token"
+
+	if _, err := c.SendTask(context.Background(), &schema.TaskSendParams{
+		ID:               "long",
+		PushNotification: &schema.PushNotificationConfig{URL: "https://client.example/hooks/long", Token: &token},
+	}); err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	got, err := c.GetTaskPushNotification(context.Background(), "long")
+	if err != nil {
+		t.Fatalf("GetTaskPushNotification failed: %v", err)
+	}
+	if got.PushNotificationConfig.URL != "https://client.example/hooks/long" || got.PushNotificationConfig.Token == nil || *got.PushNotificationConfig.Token != token {
+		t.Fatalf("Expected the config sent with the task, got %+v", got)
+	}
+
+	set, err := c.SetTaskPushNotification(context.Background(), &schema.TaskPushNotificationConfig{
+		ID:                     "long",
+		PushNotificationConfig: schema.PushNotificationConfig{URL: "https://client.example/hooks/other"},
+	})
+	if err != nil {
+		t.Fatalf("SetTaskPushNotification failed: %v", err)
+	}
+	if set.PushNotificationConfig.URL != "https://client.example/hooks/other" {
+		t.Fatalf("Unexpected config %+v", set)
+	}
+	if got, _ := c.GetTaskPushNotification(context.Background(), "long"); got.PushNotificationConfig.URL != "https://client.example/hooks/other" {
+		t.Fatalf("Expected the updated config, got %+v", got)
+	}
+}
+
+func TestPushNotificationNotSupported(t *testing.T) {
+	config := &schema.PushNotificationConfig{URL: "https://client.example/hooks/t"}
+
+	t.Run("advertised in the card", func(t *testing.T) {
+		agent := newPushAgent(t, false)
+		c, err := New(agent.URL)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		t.Cleanup(func() { c.Close() })
+		if _, err := c.FetchAgentInfo(context.Background()); err != nil {
+			t.Fatalf("FetchAgentInfo failed: %v", err)
+		}
+		if _, err := c.SendTask(context.Background(), &schema.TaskSendParams{ID: "t", PushNotification: config}); !errors.Is(err, ErrUnsupportedOperation) {
+			t.Fatalf("Expected ErrUnsupportedOperation, got %v", err)
+		}
+		if _, err := c.GetTaskPushNotification(context.Background(), "t"); !errors.Is(err, ErrUnsupportedOperation) {
+			t.Fatalf("Expected ErrUnsupportedOperation, got %v", err)
+		}
+	})
+
+	t.Run("rejected by the agent", func(t *testing.T) {
+		agent := newPushAgent(t, false)
+		c, err := New(agent.URL)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		t.Cleanup(func() { c.Close() })
+		_, err = c.SetTaskPushNotification(context.Background(), &schema.TaskPushNotificationConfig{ID: "t", PushNotificationConfig: *config})
+		var rpcErr *schema.JSONRPCError
+		if !errors.Is(err, ErrUnsupportedOperation) || !errors.As(err, &rpcErr) {
+			t.Fatalf("Expected ErrUnsupportedOperation wrapping the agent's error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"go.uber.org/zap"
+)
+
+// StreamReconnect is sent on a task subscription when its event stream ended before the
+// final status update and the client is about to re-establish it, see
+// WithStreamReconnect.
+type StreamReconnect struct {
+	Attempt int   // 1 for the first attempt after the stream ended
+	Err     error // Why the stream ended or, after the first attempt, why the previous one failed
+}
+
+// WithStreamReconnect re-establishes the event stream of SendTaskSubscribe with
+// tasks/resubscribe when it ends before the final status update, e.g. when a proxy cuts
+// idle connections. The event ID last received is sent in the Last-Event-ID header. Up
+// to attempts attempts are made until the new stream delivers an event; the first
+// waits backoff and each further one twice as long. A StreamReconnect event precedes
+// each attempt. If the agent does not support tasks/resubscribe, the task is polled
+// with tasks/get until it reaches a final state, at the poll interval of
+// WithPollingFallback if set.
+func WithStreamReconnect(attempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.reconnectAttempts = attempts
+		c.reconnectBackoff = backoff
+	}
+}
+
+// reconnectStream re-establishes the event stream of a task that ended with cause and
+// returns its body. attempts counts the attempts made since the last received event. It
+// fails with ErrUnsupportedOperation if the agent does not support tasks/resubscribe.
+func (c *Client) reconnectStream(reqCtx context.Context, taskID string, lastEventID string, attempts *int, cause error, send func(TaskEvent) bool) (io.ReadCloser, error) {
+	for *attempts < c.reconnectAttempts {
+		*attempts++
+		if !send(TaskEvent{Reconnecting: &StreamReconnect{Attempt: *attempts, Err: cause}}) {
+			return nil, reqCtx.Err()
+		}
+		wait := c.reconnectBackoff << (*attempts - 1)
+		c.logger.Debug("Reconnecting task event stream", zap.String("task", taskID), zap.Int("attempt", *attempts), zap.Duration("backoff", wait), zap.Error(cause))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-reqCtx.Done():
+			timer.Stop()
+			return nil, reqCtx.Err()
+		}
+
+		body, err := c.resubscribe(reqCtx, taskID, lastEventID)
+		if err == nil {
+			return body, nil
+		}
+		var rpcErr *schema.JSONRPCError
+		if errors.As(err, &rpcErr) || reqCtx.Err() != nil {
+			return nil, err
+		}
+		cause = err
+	}
+	return nil, fmt.Errorf("failed to reconnect after %d attempts: %w", *attempts, cause)
+}
+
+// resubscribe sends tasks/resubscribe and returns the body of the event stream.
+func (c *Client) resubscribe(reqCtx context.Context, taskID string, lastEventID string) (io.ReadCloser, error) {
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if lastEventID != "" {
+		header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := c.postWithHeader(reqCtx, "tasks/resubscribe", &schema.TaskQueryParams{ID: taskID}, header)
+	if err != nil {
+		return nil, err
+	}
+	if mediaType := resp.Header.Get("Content-Type"); !strings.HasPrefix(mediaType, "text/event-stream") {
+		defer resp.Body.Close()
+		if _, err := c.readResponse(reqCtx, "tasks/resubscribe", resp.Body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("agent did not open an event stream (Content-Type %q)", mediaType)
+	}
+	return resp.Body, nil
+}
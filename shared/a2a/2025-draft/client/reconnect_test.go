@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// droppingAgent streams a working status update with the event ID "1" and then drops
+// the stream. tasks/resubscribe is answered as set by resubscribe.
+type droppingAgent struct {
+	*httptest.Server
+	mu          sync.Mutex
+	lastEventID []string // Last-Event-ID of each tasks/resubscribe request
+}
+
+func newDroppingAgent(t *testing.T, resubscribe func(w http.ResponseWriter, id any, taskID string)) *droppingAgent {
+	t.Helper()
+	agent := &droppingAgent{}
+	agent.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string              `json:"method"`
+			Params schema.TaskIdParams `json:"params"`
+			ID     any                 `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "tasks/sendSubscribe":
+			w.Header().Set("Content-Type", "text/event-stream")
+			writeStatusEvent(w, req.ID, "1", req.Params.ID, schema.TaskStateWorking)
+		case "tasks/resubscribe":
+			agent.mu.Lock()
+			agent.lastEventID = append(agent.lastEventID, r.Header.Get("Last-Event-ID"))
+			agent.mu.Unlock()
+			resubscribe(w, req.ID, req.Params.ID)
+		case "tasks/get":
+			result, _ := json.Marshal(schema.Task{
+				ID:        req.Params.ID,
+				Status:    schema.TaskStatus{State: schema.TaskStateCompleted},
+				Artifacts: []schema.Artifact{{Parts: []schema.Part{schema.Part(`{"type":"text","text":"done"}`)}}},
+			})
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":%s}`, req.ID, result)
+		}
+	}))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+// writeStatusEvent writes a status update of the task to an event stream.
+func writeStatusEvent(w http.ResponseWriter, id any, eventID string, taskID string, state schema.TaskState) {
+	update, _ := json.Marshal(schema.TaskStatusUpdateEvent{ID: taskID, Status: schema.TaskStatus{State: state}, Final: state.IsFinal()})
+	fmt.Fprintf(w, "id: %s\ndata: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":%s}\n\n", eventID, id, update)
+	w.(http.Flusher).Flush()
+}
+
+// subscribe sends the task "t" to the agent with stream reconnection enabled.
+func subscribe(t *testing.T, agentURL string, opts ...Option) <-chan TaskEvent {
+	t.Helper()
+	c, err := New(agentURL, opts...)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	return events
+}
+
+func TestStreamReconnectResubscribes(t *testing.T) {
+	agent := newDroppingAgent(t, func(w http.ResponseWriter, id any, taskID string) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeStatusEvent(w, id, "2", taskID, schema.TaskStateCompleted)
+	})
+	events := collectEvents(t, subscribe(t, agent.URL, WithStreamReconnect(3, time.Millisecond)))
+
+	if len(events) != 3 {
+		t.Fatalf("Expected working, reconnecting and completed events, got %+v", events)
+	}
+	if reconnect := events[1].Reconnecting; reconnect == nil || reconnect.Attempt != 1 {
+		t.Errorf("Expected a reconnecting notice, got %+v", events[1])
+	}
+	if last := events[2].Status; last == nil || last.Status.State != schema.TaskStateCompleted || !last.Final {
+		t.Errorf("Expected the final status of the new stream, got %+v", events[2])
+	}
+	if len(agent.lastEventID) != 1 || agent.lastEventID[0] != "1" {
+		t.Errorf("Expected Last-Event-ID 1, got %q", agent.lastEventID)
+	}
+}
+
+func TestStreamReconnectDisabledByDefault(t *testing.T) {
+	agent := newDroppingAgent(t, func(w http.ResponseWriter, id any, taskID string) {
+		t.Error("Unexpected tasks/resubscribe")
+	})
+	if events := collectEvents(t, subscribe(t, agent.URL)); len(events) != 1 {
+		t.Fatalf("Expected only the working status, got %+v", events)
+	}
+}
+
+func TestStreamReconnectPollsWithoutResubscribe(t *testing.T) {
+	agent := newDroppingAgent(t, func(w http.ResponseWriter, id any, taskID string) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"error":{"code":%d,"message":"Method not found"}}`, id, schema.ErrorMethodNotFound)
+	})
+	events := collectEvents(t, subscribe(t, agent.URL, WithStreamReconnect(3, time.Millisecond)))
+
+	if reconnect := events[1].Reconnecting; reconnect == nil {
+		t.Errorf("Expected a reconnecting notice, got %+v", events[1])
+	}
+	assertCompleted(t, events)
+	if len(events) != 4 {
+		t.Errorf("Expected working, reconnecting, artifact and completed events, got %+v", events)
+	}
+}
+
+func TestStreamReconnectGivesUp(t *testing.T) {
+	agent := newDroppingAgent(t, func(w http.ResponseWriter, id any, taskID string) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	events := subscribe(t, agent.URL, WithStreamReconnect(2, time.Millisecond))
+
+	var attempts []int
+	var last TaskEvent
+	for event := range events {
+		if event.Reconnecting != nil {
+			attempts = append(attempts, event.Reconnecting.Attempt)
+		}
+		last = event
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("Expected 2 reconnect attempts, got %v", attempts)
+	}
+	if last.Err == nil || !strings.Contains(last.Err.Error(), "after 2 attempts") || !strings.Contains(last.Err.Error(), "status 503") {
+		t.Fatalf("Expected the error of the last attempt, got %+v", last)
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WithRetry retries failed calls until maxAttempts attempts have been made. GetTask,
+// CancelTask and FetchAgentInfo are retried when the agent cannot be reached, the
+// connection fails or the agent answers with a 5xx status. SendTask is not idempotent
+// and is only retried when the connection could not be established, so the agent
+// cannot have received the task. The first retry waits about baseDelay, each further
+// one about twice as long, with random jitter so clients do not retry in lockstep. No
+// retry is started that would outlast the deadline of the call's context; the error of
+// the last attempt is returned, wrapped with the number of attempts.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retryAttempts = maxAttempts
+		c.retryBaseDelay = baseDelay
+	}
+}
+
+// retry calls attempt until it succeeds, fails with an error retryable rejects or the
+// attempts set with WithRetry are used up.
+func (c *Client) retry(ctx context.Context, method string, retryable func(error) bool, attempt func() error) error {
+	delay := c.retryBaseDelay
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		if n >= c.retryAttempts || !retryable(err) || errors.Is(err, ErrClientClosed) || ctx.Err() != nil {
+			return retriedError(method, n, err)
+		}
+		wait := jitter(delay)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return retriedError(method, n, err)
+		}
+		c.logger.Debug("Retrying A2A request", zap.String("method", method), zap.Int("attempt", n), zap.Duration("backoff", wait), zap.Error(err))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return retriedError(method, n, err)
+		case <-c.ctx.Done():
+			timer.Stop()
+			return c.closedError(retriedError(method, n, err))
+		}
+		delay *= 2
+	}
+}
+
+// retriedError wraps the error of the last attempt of a retried call.
+func retriedError(method string, attempts int, err error) error {
+	if attempts == 1 {
+		return err
+	}
+	return fmt.Errorf("%s failed after %d attempts: %w", method, attempts, err)
+}
+
+// jitter returns a random wait between half and one and a half times delay.
+func jitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay)
+}
+
+// transientError reports whether an idempotent call may succeed when tried again: the
+// agent could not be reached, the connection failed or the agent answered with a 5xx
+// status.
+func transientError(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// dialError reports whether err is a failure to connect to the agent, so no byte of the
+// request has been sent.
+func dialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
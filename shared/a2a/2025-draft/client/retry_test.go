@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// flakyAgent is a mockAgent failing its first requests, by answering with 503 or, with
+// drop set, by closing the connection without an answer.
+type flakyAgent struct {
+	*mockAgent
+	failures atomic.Int32 // Requests still to fail
+	requests atomic.Int32
+	drop     bool
+}
+
+func newFlakyAgent(t *testing.T, failures int32, drop bool) *flakyAgent {
+	t.Helper()
+	agent := &flakyAgent{mockAgent: &mockAgent{}, drop: drop}
+	agent.failures.Store(failures)
+	agent.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent.requests.Add(1)
+		if agent.failures.Add(-1) < 0 {
+			agent.serve(w, r)
+			return
+		}
+		if !agent.drop {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+func TestRetryGetTaskRecoversFromFailures(t *testing.T) {
+	for name, drop := range map[string]bool{"503": false, "dropped connection": true} {
+		t.Run(name, func(t *testing.T) {
+			agent := newFlakyAgent(t, 2, drop)
+			c, err := New(agent.URL, WithRetry(3, time.Millisecond))
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			t.Cleanup(func() { c.Close() })
+
+			task, err := c.GetTask(context.Background(), &schema.TaskQueryParams{ID: "t"})
+			if err != nil {
+				t.Fatalf("GetTask failed: %v", err)
+			}
+			if task.ID != "t" {
+				t.Fatalf("Unexpected task %+v", task)
+			}
+			if n := agent.requests.Load(); n != 3 {
+				t.Fatalf("Expected 3 attempts, got %d", n)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	agent := newFlakyAgent(t, 10, false)
+	c, err := New(agent.URL, WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	_, err = c.CancelTask(context.Background(), "t")
+	var status *StatusError
+	if !errors.As(err, &status) || status.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the 503 error of the last attempt, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("Expected the number of attempts in the error, got %v", err)
+	}
+	if n := agent.requests.Load(); n != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryFetchAgentInfo(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, "overloaded", http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path != AgentCardWellKnownPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"flaky"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithRetry(2, time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	card, err := c.FetchAgentInfo(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAgentInfo failed: %v", err)
+	}
+	if card.Name != "flaky" {
+		t.Fatalf("Unexpected card %+v", card)
+	}
+}
+
+func TestRetrySendTaskOnlyBeforeConnecting(t *testing.T) {
+	t.Run("not after the request was sent", func(t *testing.T) {
+		for name, drop := range map[string]bool{"503": false, "dropped connection": true} {
+			t.Run(name, func(t *testing.T) {
+				agent := newFlakyAgent(t, 1, drop)
+				c, err := New(agent.URL, WithRetry(3, time.Millisecond))
+				if err != nil {
+					t.Fatalf("New failed: %v", err)
+				}
+				t.Cleanup(func() { c.Close() })
+
+				if _, err := c.SendTask(context.Background(), &schema.TaskSendParams{ID: "t"}); err == nil {
+					t.Fatal("Expected SendTask to fail")
+				}
+				if n := agent.requests.Load(); n != 1 {
+					t.Fatalf("SendTask must not be repeated once sent, got %d attempts", n)
+				}
+			})
+		}
+	})
+
+	t.Run("refused connection", func(t *testing.T) {
+		// Reserve an address nobody listens on yet, as when the agent is still starting
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen failed: %v", err)
+		}
+		addr := listener.Addr().String()
+		listener.Close()
+
+		c, err := New("http://"+addr, WithRetry(8, 25*time.Millisecond))
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		t.Cleanup(func() { c.Close() })
+
+		agent := &mockAgent{}
+		agent.Server = httptest.NewUnstartedServer(http.HandlerFunc(agent.serve))
+		started := make(chan struct{})
+		time.AfterFunc(100*time.Millisecond, func() {
+			defer close(started)
+			if agent.Listener, err = net.Listen("tcp", addr); err != nil {
+				return
+			}
+			agent.Start()
+		})
+		t.Cleanup(func() {
+			<-started
+			if agent.Listener != nil {
+				agent.Close()
+			}
+		})
+
+		task, sendErr := c.SendTask(context.Background(), &schema.TaskSendParams{ID: "cold-start"})
+		<-started
+		if err != nil {
+			t.Skipf("Address taken before the agent started: %v", err)
+		}
+		if sendErr != nil {
+			t.Fatalf("SendTask failed: %v", sendErr)
+		}
+		if task.Status.State != schema.TaskStateWorking {
+			t.Fatalf("Unexpected task %+v", task)
+		}
+	})
+}
+
+func TestRetryRespectsContextDeadline(t *testing.T) {
+	agent := newFlakyAgent(t, 10, false)
+	c, err := New(agent.URL, WithRetry(5, time.Hour))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.GetTask(ctx, &schema.TaskQueryParams{ID: "t"}); err == nil {
+		t.Fatal("Expected GetTask to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Waited for a retry outlasting the deadline: %v", elapsed)
+	}
+	if n := agent.requests.Load(); n != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", n)
+	}
+}
+
+func TestRetryStopsOnClose(t *testing.T) {
+	agent := newFlakyAgent(t, 10, false)
+	c, err := New(agent.URL, WithRetry(5, time.Hour))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	time.AfterFunc(50*time.Millisecond, func() { c.Close() })
+	if _, err := c.GetTask(context.Background(), &schema.TaskQueryParams{ID: "t"}); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("Expected ErrClientClosed, got %v", err)
+	}
+}
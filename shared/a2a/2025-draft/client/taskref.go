@@ -0,0 +1,63 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// taskRefFragmentKey is the key of the task ID in the fragment of a formatted TaskRef.
+const taskRefFragmentKey = "task"
+
+// TaskRef references a task of an agent independently of the client that created it, so
+// that another client, possibly in another process, can fetch the task later. Its string
+// form is the agent URL with the task ID in the fragment, e.g.
+// "https://agent.example.com/a2a#task=3f2c", which stays a valid URL of the agent.
+type TaskRef struct {
+	AgentURL string
+	TaskID   string
+}
+
+// TaskRef returns the reference of a task of the client's agent.
+func (c *Client) TaskRef(task *schema.Task) TaskRef {
+	return TaskRef{AgentURL: c.agentURL, TaskID: task.ID}
+}
+
+// String formats the reference; ParseTaskRef reverses it.
+func (r TaskRef) String() string {
+	fragment := url.Values{taskRefFragmentKey: {r.TaskID}}.Encode()
+	return strings.TrimSuffix(r.AgentURL, "/") + "#" + fragment
+}
+
+// ParseTaskRef parses a reference formatted by TaskRef.String.
+func ParseTaskRef(s string) (TaskRef, error) {
+	agentURL, fragment, found := strings.Cut(s, "#")
+	if !found {
+		return TaskRef{}, fmt.Errorf("task reference %q has no task ID", s)
+	}
+	parsed, err := url.Parse(agentURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return TaskRef{}, fmt.Errorf("task reference %q has an invalid agent URL", s)
+	}
+	values, err := url.ParseQuery(fragment)
+	if err != nil {
+		return TaskRef{}, fmt.Errorf("task reference %q: %w", s, err)
+	}
+	taskID := values.Get(taskRefFragmentKey)
+	if taskID == "" {
+		return TaskRef{}, fmt.Errorf("task reference %q has no task ID", s)
+	}
+	return TaskRef{AgentURL: agentURL, TaskID: taskID}, nil
+}
+
+// NewFromTaskRef creates a client for the agent of the reference.
+func NewFromTaskRef(ref TaskRef, opts ...Option) (*Client, error) {
+	return New(ref.AgentURL, opts...)
+}
+
+// QueryParams returns the params fetching the referenced task with GetTask.
+func (r TaskRef) QueryParams() *schema.TaskQueryParams {
+	return &schema.TaskQueryParams{ID: r.TaskID}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+func TestTaskRefFetchesTaskFromFreshClient(t *testing.T) {
+	agent := newMockAgent(t)
+	sender, err := New(agent.URL + "/")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer sender.Close()
+	ctx := context.Background()
+
+	task, err := sender.SendTask(ctx, &schema.TaskSendParams{ID: "report/42?x=1"})
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	formatted := sender.TaskRef(task).String()
+
+	ref, err := ParseTaskRef(formatted)
+	if err != nil {
+		t.Fatalf("ParseTaskRef(%q) failed: %v", formatted, err)
+	}
+	if ref.AgentURL != agent.URL || ref.TaskID != task.ID {
+		t.Fatalf("ParseTaskRef(%q) = %+v", formatted, ref)
+	}
+	fetcher, err := NewFromTaskRef(ref)
+	if err != nil {
+		t.Fatalf("NewFromTaskRef failed: %v", err)
+	}
+	defer fetcher.Close()
+	fetched, err := fetcher.GetTask(ctx, ref.QueryParams())
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if fetched.ID != task.ID {
+		t.Fatalf("Fetched task %q, want %q", fetched.ID, task.ID)
+	}
+}
+
+func TestParseTaskRefRejectsInvalidReferences(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"https://agent.example.com",
+		"https://agent.example.com#other=1",
+		"agent.example.com#task=1",
+	} {
+		if ref, err := ParseTaskRef(s); err == nil {
+			t.Errorf("ParseTaskRef(%q) = %+v, want an error", s, ref)
+		}
+	}
+}
@@ -0,0 +1,714 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"go.uber.org/zap"
+)
+
+// ErrClientClosed is returned by calls made after Close, and by requests Close aborted.
+var ErrClientClosed = errors.New("a2a client is closed")
+
+// ErrUnsupportedOperation is returned when the agent rejects a method it does not support,
+// either because it lacks the capability or does not know the method. The agent's
+// *schema.JSONRPCError is wrapped too; its Data usually lists what the agent supports.
+var ErrUnsupportedOperation = errors.New("operation not supported by the agent")
+
+// cancelOnCloseTimeout bounds each tasks/cancel request sent by Close.
+const cancelOnCloseTimeout = 5 * time.Second
+
+// requestID numbers the JSON-RPC requests of all clients.
+var requestID atomic.Int64
+
+// WithCancelTasksOnClose makes Close send tasks/cancel for every task the client last saw
+// in a non-terminal state, so the agent stops work nobody waits for anymore.
+func WithCancelTasksOnClose() Option {
+	return func(c *Client) {
+		c.cancelOnClose = true
+	}
+}
+
+// WithSubscribeConnectRetry retries establishing the event stream of SendTaskSubscribe
+// up to retries times when the agent cannot be reached or answers 502, 503 or 504, e.g.
+// while it is still starting. The first retry waits backoff and each further one twice
+// as long. A positive timeout bounds each attempt until the agent starts answering.
+// Since tasks/sendSubscribe is not idempotent, a request that may have reached the agent
+// is never sent again: an attempt failing or timing out once connected is not retried.
+// Attempts stop when the context of the call is done; streams that have started are
+// never retried, so events are not repeated.
+func WithSubscribeConnectRetry(retries int, backoff time.Duration, timeout time.Duration) Option {
+	return func(c *Client) {
+		c.connectRetries = retries
+		c.connectBackoff = backoff
+		c.connectTimeout = timeout
+	}
+}
+
+// errConnectTimeout is returned when an attempt to establish an event stream exceeds the
+// timeout set with WithSubscribeConnectRetry.
+var errConnectTimeout = errors.New("connection timed out")
+
+// TaskEvent is an update received on a task subscription. Exactly one field is set;
+// an event with Err is the last one before the channel is closed.
+type TaskEvent struct {
+	Status       *schema.TaskStatusUpdateEvent
+	Artifact     *schema.TaskArtifactUpdateEvent
+	Reconnecting *StreamReconnect // See WithStreamReconnect
+	Err          error
+}
+
+// InputRequired reports whether the event is a status update of a task waiting for
+// input. The question of the agent is in Status.Status.Message; answer it with
+// ContinueTask. The subscription stays open for the updates following the answer unless
+// the agent ends the stream.
+func (e TaskEvent) InputRequired() bool {
+	return e.Status != nil && e.Status.Status.State == schema.TaskStateInputRequired
+}
+
+// SendTask sends a message to a task (tasks/send) and returns the task as the agent
+// reports it after processing. If params.ID is empty, it is set to a generated ID.
+// params.PushNotification asks the agent to post updates of the task to a callback URL
+// instead, see SetTaskPushNotification. params.HistoryLength asks for the last messages
+// of the task in Task.History, see GetTask.
+func (c *Client) SendTask(ctx context.Context, params *schema.TaskSendParams) (*schema.Task, error) {
+	if err := checkHistoryLength(params.HistoryLength); err != nil {
+		return nil, err
+	}
+	if params.PushNotification != nil {
+		if err := c.checkPushNotification(); err != nil {
+			return nil, err
+		}
+	}
+	c.ensureTaskID(params)
+	var task schema.Task
+	if err := c.callRetrying(ctx, "tasks/send", params, &task, dialError); err != nil {
+		return nil, err
+	}
+	if task.SessionID == nil {
+		task.SessionID = params.SessionID
+	}
+	limitHistory(&task, params.HistoryLength)
+	c.trackSession(&task)
+	c.trackTask(task.ID, task.Status.State)
+	return &task, nil
+}
+
+// ContinueTask sends a follow-up message to a task, typically the answer to a task
+// waiting in the input-required state, and returns the task as the agent reports it
+// after processing. The message is sent in the session of the task if the client saw
+// it, and as the user's unless message.Role is set.
+func (c *Client) ContinueTask(ctx context.Context, taskID string, message schema.Message) (*schema.Task, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("task ID is required")
+	}
+	if message.Role == "" {
+		message.Role = "user"
+	}
+	params := &schema.TaskSendParams{ID: taskID, Message: message}
+	c.mu.Lock()
+	if sessionID, ok := c.sessions[taskID]; ok {
+		params.SessionID = &sessionID
+	}
+	c.mu.Unlock()
+	return c.SendTask(ctx, params)
+}
+
+// GetTask retrieves the current state of a task (tasks/get). If params.HistoryLength is
+// set, the agent is asked for the last that many messages of the task, returned in
+// Task.History; agents returning more are cut to the requested length. Without it, the
+// history is returned as the agent sends it, usually not at all.
+func (c *Client) GetTask(ctx context.Context, params *schema.TaskQueryParams) (*schema.Task, error) {
+	if err := checkHistoryLength(params.HistoryLength); err != nil {
+		return nil, err
+	}
+	var task schema.Task
+	if err := c.callRetrying(ctx, "tasks/get", params, &task, transientError); err != nil {
+		return nil, err
+	}
+	limitHistory(&task, params.HistoryLength)
+	c.trackSession(&task)
+	c.trackTask(task.ID, task.Status.State)
+	return &task, nil
+}
+
+// CancelTask asks the agent to cancel a task (tasks/cancel). Subscriptions of the task
+// end with a final canceled status update: the one sent by the agent or, if the agent
+// just closes the stream, one carrying the canceled status returned here.
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*schema.Task, error) {
+	cancel := c.beginCancel(taskID)
+	var task schema.Task
+	if err := c.callRetrying(ctx, "tasks/cancel", &schema.TaskIdParams{ID: taskID}, &task, transientError); err != nil {
+		cancel.finish(nil)
+		return nil, err
+	}
+	c.trackTask(task.ID, task.Status.State)
+	cancel.finish(&task.Status)
+	return &task, nil
+}
+
+// checkHistoryLength rejects a negative history length before it is sent.
+func checkHistoryLength(historyLength *int) error {
+	if historyLength != nil && *historyLength < 0 {
+		return fmt.Errorf("invalid history length %d", *historyLength)
+	}
+	return nil
+}
+
+// limitHistory keeps the last historyLength messages of the history of task, if set.
+func limitHistory(task *schema.Task, historyLength *int) {
+	if historyLength != nil && len(task.History) > *historyLength {
+		task.History = task.History[len(task.History)-*historyLength:]
+	}
+}
+
+// streamCancel is a CancelTask call for a task with running subscriptions. The agent may
+// close the streams before the call returns, so they wait for its outcome.
+type streamCancel struct {
+	done   chan struct{}
+	status *schema.TaskStatus // Set before done is closed, nil if the call failed
+}
+
+// beginCancel records a CancelTask call if the task is streamed, nil otherwise.
+func (c *Client) beginCancel(taskID string) *streamCancel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.streamed[taskID] == 0 {
+		return nil
+	}
+	cancel := &streamCancel{done: make(chan struct{})}
+	c.canceled[taskID] = cancel
+	return cancel
+}
+
+// finish records the status returned by the call, nil if it failed.
+func (sc *streamCancel) finish(status *schema.TaskStatus) {
+	if sc == nil {
+		return
+	}
+	sc.status = status
+	close(sc.done)
+}
+
+// SetTaskPushNotification sets where the agent pushes updates of a task
+// (tasks/pushNotification/set): the callback URL and, optionally, the token the agent
+// sends with each update.
+func (c *Client) SetTaskPushNotification(ctx context.Context, params *schema.TaskPushNotificationConfig) (*schema.TaskPushNotificationConfig, error) {
+	if err := c.checkPushNotification(); err != nil {
+		return nil, err
+	}
+	var config schema.TaskPushNotificationConfig
+	if err := c.call(ctx, "tasks/pushNotification/set", params, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// checkPushNotification fails with ErrUnsupportedOperation if the agent card fetched by
+// FetchAgentInfo does not advertise push notifications. Without a fetched card, the
+// agent decides.
+func (c *Client) checkPushNotification() error {
+	if card := c.fetchedCard(); card != nil && !card.Capabilities.PushNotifications {
+		return fmt.Errorf("%w: agent %s does not advertise push notifications", ErrUnsupportedOperation, c.agentURL)
+	}
+	return nil
+}
+
+// GetTaskPushNotification returns where the agent pushes updates of a task
+// (tasks/pushNotification/get).
+func (c *Client) GetTaskPushNotification(ctx context.Context, taskID string) (*schema.TaskPushNotificationConfig, error) {
+	if err := c.checkPushNotification(); err != nil {
+		return nil, err
+	}
+	var config schema.TaskPushNotificationConfig
+	if err := c.call(ctx, "tasks/pushNotification/get", &schema.TaskIdParams{ID: taskID}, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SendTaskSubscribe sends a message to a task (tasks/sendSubscribe) and streams its
+// updates. The channel is closed after the final status update, at the end of the
+// stream, when ctx is done or when the client is closed. If params.ID is empty, it is
+// set to a generated ID. With WithPollingFallback, updates may be polled instead.
+func (c *Client) SendTaskSubscribe(ctx context.Context, params *schema.TaskSendParams) (<-chan TaskEvent, error) {
+	c.ensureTaskID(params)
+	reqCtx, done, err := c.begin(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if c.Polling() {
+		return c.sendTaskPolling(reqCtx, done, params)
+	}
+	resp, err := c.connectSubscription(reqCtx, params)
+	if err != nil {
+		done()
+		c.subscriptions.Done()
+		return nil, err
+	}
+	if mediaType := resp.Header.Get("Content-Type"); !strings.HasPrefix(mediaType, "text/event-stream") {
+		// The agent answered with a plain JSON-RPC response, typically an error
+		defer resp.Body.Close()
+		defer c.subscriptions.Done()
+		defer done()
+		if _, err := c.readResponse(reqCtx, "tasks/sendSubscribe", resp.Body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("agent did not open an event stream (Content-Type %q)", mediaType)
+	}
+	c.trackTask(params.ID, schema.TaskStateSubmitted)
+	c.trackSession(&schema.Task{ID: params.ID, SessionID: params.SessionID})
+	c.beginStream(params.ID)
+
+	events := make(chan TaskEvent)
+	go func() {
+		defer c.subscriptions.Done()
+		defer done()
+		body := resp.Body
+		defer func() { body.Close() }()
+		defer close(events)
+		defer c.endStream(params.ID)
+
+		send := func(event TaskEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-reqCtx.Done():
+				return false
+			}
+		}
+		var firstEvent atomic.Int32 // firstEventPending, firstEventReceived or firstEventMissed
+		if c.firstEventTimeout > 0 {
+			timer := time.AfterFunc(c.firstEventTimeout, func() {
+				if firstEvent.CompareAndSwap(firstEventPending, firstEventMissed) {
+					resp.Body.Close() // Unblocks the reader
+				}
+			})
+			defer timer.Stop()
+		}
+		reader := c.newEventReader(body)
+		var streamed taskPoll // What was streamed, for polling after a failed reconnect
+		var lastEventID string
+		reconnects := 0
+		method := "tasks/sendSubscribe" // Of the stream read, for WithOnResponse
+		for {
+			sse, err := reader.Next()
+			if err != nil {
+				if firstEvent.Load() == firstEventMissed && reqCtx.Err() == nil {
+					// The agent accepted the task, but its events do not get through
+					c.fallBackToPolling(params.ID)
+					c.pollTask(reqCtx, params.ID, &taskPoll{}, false, send)
+				} else if status, ok := c.canceledStatus(reqCtx, params.ID); ok {
+					// The agent closed the stream of the canceled task without a final update
+					send(TaskEvent{Status: &schema.TaskStatusUpdateEvent{ID: params.ID, Status: status, Final: true}})
+				} else if err == io.EOF && streamed.status.State == schema.TaskStateInputRequired {
+					// The agent ended the stream until the task gets its input, see ContinueTask
+				} else if c.reconnectAttempts > 0 && reqCtx.Err() == nil {
+					// The stream dropped before the final update, the first event is no longer awaited
+					firstEvent.CompareAndSwap(firstEventPending, firstEventReceived)
+					newBody, err := c.reconnectStream(reqCtx, params.ID, lastEventID, &reconnects, err, send)
+					if err == nil {
+						body.Close()
+						body = newBody
+						reader = c.newEventReader(body)
+						method = "tasks/resubscribe"
+						continue
+					}
+					if errors.Is(err, ErrUnsupportedOperation) {
+						c.logger.Debug("Agent does not support tasks/resubscribe, polling the task", zap.String("task", params.ID))
+						c.pollTask(reqCtx, params.ID, &streamed, false, send)
+					} else if reqCtx.Err() == nil {
+						send(TaskEvent{Err: fmt.Errorf("task event stream failed: %w", err)})
+					}
+				} else if err != io.EOF && reqCtx.Err() == nil {
+					send(TaskEvent{Err: fmt.Errorf("task event stream failed: %w", err)})
+				}
+				return
+			}
+			firstEvent.CompareAndSwap(firstEventPending, firstEventReceived)
+			reconnects = 0
+			if sse.ID != "" {
+				lastEventID = sse.ID
+			}
+			if c.onResponse != nil {
+				c.onResponse(reqCtx, method, []byte(sse.Data))
+			}
+			event, final, err := decodeTaskEvent([]byte(sse.Data))
+			if err != nil {
+				send(TaskEvent{Err: err})
+				return
+			}
+			if event.Status != nil {
+				c.trackTask(params.ID, event.Status.Status.State)
+				streamed.seen, streamed.status = true, event.Status.Status
+			}
+			if event.Artifact != nil {
+				streamed.artifacts = max(streamed.artifacts, event.Artifact.Artifact.Index+1)
+			}
+			if !send(event) || final {
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// States of the first event of a task event stream, see WithPollingFallback.
+const (
+	firstEventPending int32 = iota
+	firstEventReceived
+	firstEventMissed
+)
+
+// connectSubscription sends tasks/sendSubscribe, retrying as set with
+// WithSubscribeConnectRetry until the agent answers.
+func (c *Client) connectSubscription(ctx context.Context, params *schema.TaskSendParams) (*http.Response, error) {
+	wait := c.connectBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := c.connectOnce(ctx, params)
+		if err == nil || attempt > c.connectRetries || !retryableConnectError(err) || ctx.Err() != nil {
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, err
+		}
+		c.logger.Debug("Retrying task subscription", zap.String("task", params.ID), zap.Int("attempt", attempt), zap.Duration("backoff", wait), zap.Error(err))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, c.closedError(err)
+		}
+		wait *= 2
+	}
+}
+
+// connectOnce sends tasks/sendSubscribe once, giving up when the agent does not answer
+// within the connect timeout.
+func (c *Client) connectOnce(ctx context.Context, params *schema.TaskSendParams) (*http.Response, error) {
+	if c.connectTimeout <= 0 {
+		return c.post(ctx, "tasks/sendSubscribe", params, "text/event-stream")
+	}
+	attemptCtx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(c.connectTimeout, cancel)
+	resp, err := c.post(attemptCtx, "tasks/sendSubscribe", params, "text/event-stream")
+	if !timer.Stop() && ctx.Err() == nil {
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		if dialError(err) {
+			return nil, fmt.Errorf("tasks/sendSubscribe connection failed after %v: %w", c.connectTimeout, err)
+		}
+		return nil, fmt.Errorf("tasks/sendSubscribe request failed after %v: %w", c.connectTimeout, errConnectTimeout)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The stream lives on the attempt context, released with the body
+	resp.Body = &cancelingBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelingBody cancels the context of its request when closed.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// retryableConnectError reports whether establishing an event stream may be tried again
+// without the agent running the task twice: the agent could not be reached (see
+// dialError), or answered that it is temporarily unavailable.
+func retryableConnectError(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode == http.StatusBadGateway || status.StatusCode == http.StatusServiceUnavailable || status.StatusCode == http.StatusGatewayTimeout
+	}
+	return dialError(err)
+}
+
+// Close cancels all subscriptions and in-flight requests of the client and returns once
+// every subscription channel is closed. With WithCancelTasksOnClose it then asks the
+// agent to cancel the tasks still running. Later calls fail with ErrClientClosed.
+// Close may be called more than once.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	var openTasks []string
+	if c.cancelOnClose {
+		for taskID := range c.openTasks {
+			openTasks = append(openTasks, taskID)
+		}
+	}
+	c.mu.Unlock()
+
+	c.cancel()
+	c.subscriptions.Wait()
+
+	sort.Strings(openTasks)
+	var errs []error
+	for _, taskID := range openTasks {
+		c.logger.Debug("Canceling task on close", zap.String("task", taskID))
+		ctx, cancel := context.WithTimeout(context.Background(), cancelOnCloseTimeout)
+		resp, err := c.post(ctx, "tasks/cancel", &schema.TaskIdParams{ID: taskID}, "application/json")
+		if err == nil {
+			_, err = c.readResponse(ctx, "tasks/cancel", resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel task '%s': %w", taskID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// begin returns the context of a new request, canceled when ctx is done or the client
+// is closed, and the function releasing it. A subscription is also counted until the
+// caller marks it done, so Close can wait for it.
+func (c *Client) begin(ctx context.Context, subscription bool) (context.Context, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, nil, ErrClientClosed
+	}
+	if subscription {
+		c.subscriptions.Add(1)
+	}
+	reqCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.ctx, cancel)
+	return reqCtx, func() {
+		stop()
+		cancel()
+	}, nil
+}
+
+// call sends a JSON-RPC request to the agent and decodes the result into result.
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	ctx, cancel := c.withTimeout(ctx, method)
+	defer cancel()
+	reqCtx, done, err := c.begin(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	resp, err := c.post(reqCtx, method, params, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := c.readResponse(ctx, method, resp.Body)
+	if err != nil {
+		return c.closedError(err)
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// callRetrying is call, retried as set with WithRetry while it fails with errors
+// retryable accepts.
+func (c *Client) callRetrying(ctx context.Context, method string, params interface{}, result interface{}, retryable func(error) bool) error {
+	ctx, cancel := c.withTimeout(ctx, method)
+	defer cancel()
+	return c.retry(ctx, method, retryable, func() error {
+		return c.call(ctx, method, params, result)
+	})
+}
+
+// post sends a JSON-RPC request to the agent URL and returns the response if its
+// status is 200 OK. The caller must close the response body.
+func (c *Client) post(ctx context.Context, method string, params interface{}, accept string) (*http.Response, error) {
+	return c.postWithHeader(ctx, method, params, http.Header{"Accept": {accept}})
+}
+
+// postWithHeader is post sending the given header fields.
+func (c *Client) postWithHeader(ctx context.Context, method string, params interface{}, header http.Header) (*http.Response, error) {
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+	}
+	rawParams := json.RawMessage(encodedParams)
+	id := nextRequestID(ctx)
+	body, err := json.Marshal(schema.JSONRPCRequest{JSONRPC: schema.JSONRPCVersion, Method: method, Params: &rawParams, ID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	c.logger.Debug("Sending A2A request", zap.String("method", method), zap.Any("id", id))
+	return c.postBody(ctx, method, body, header)
+}
+
+// postBody sends an encoded JSON-RPC request, or batch of requests, of method to the
+// agent URL, as postWithHeader.
+func (c *Client) postBody(ctx context.Context, method string, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.agentURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+	c.addMetadata(req)
+
+	if c.onRequest != nil {
+		c.onRequest(ctx, method, body)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, c.closedError(fmt.Errorf("%s request failed: %w", method, err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newStatusError(method, c.agentURL, resp)
+	}
+	return resp, nil
+}
+
+// closedError marks err as caused by Close if the client has been closed.
+func (c *Client) closedError(err error) error {
+	if c.ctx.Err() != nil && !errors.Is(err, ErrClientClosed) {
+		return fmt.Errorf("%w: %w", ErrClientClosed, err)
+	}
+	return err
+}
+
+// ensureTaskID gives a task sent without an ID one of the client's ID generator.
+func (c *Client) ensureTaskID(params *schema.TaskSendParams) {
+	if params.ID == "" {
+		params.ID = c.idGenerator.NewID()
+	}
+}
+
+// beginStream records a running subscription of the task, for CancelTask.
+func (c *Client) beginStream(taskID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streamed[taskID]++
+}
+
+// endStream records the end of a subscription of the task.
+func (c *Client) endStream(taskID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.streamed[taskID]--; c.streamed[taskID] <= 0 {
+		delete(c.streamed, taskID)
+		delete(c.canceled, taskID)
+	}
+}
+
+// canceledStatus returns the canceled status CancelTask received for a streamed task,
+// waiting for a call still in progress unless ctx is done.
+func (c *Client) canceledStatus(ctx context.Context, taskID string) (schema.TaskStatus, bool) {
+	c.mu.Lock()
+	cancel := c.canceled[taskID]
+	c.mu.Unlock()
+	if cancel == nil {
+		return schema.TaskStatus{}, false
+	}
+	select {
+	case <-cancel.done:
+	case <-ctx.Done():
+		return schema.TaskStatus{}, false
+	}
+	if cancel.status == nil || cancel.status.State != schema.TaskStateCanceled {
+		return schema.TaskStatus{}, false
+	}
+	return *cancel.status, true
+}
+
+// trackTask records whether the task is still running, for WithCancelTasksOnClose.
+func (c *Client) trackTask(taskID string, state schema.TaskState) {
+	if taskID == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if state.IsFinal() {
+		delete(c.openTasks, taskID)
+		delete(c.sessions, taskID)
+	} else {
+		c.openTasks[taskID] = true
+	}
+}
+
+// trackSession records the session of a task, for ContinueTask.
+func (c *Client) trackSession(task *schema.Task) {
+	if task.ID == "" || task.SessionID == nil || task.Status.State.IsFinal() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions[task.ID] = *task.SessionID
+}
+
+// decodeResponse reads a JSON-RPC response and returns its result, or its error.
+func decodeResponse(body io.Reader) (json.RawMessage, error) {
+	var resp schema.JSONRPCResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON-RPC response: %w", err)
+	}
+	if resp.Error != nil {
+		switch resp.Error.Code {
+		case schema.ErrorUnsupportedOperation, schema.ErrorMethodNotFound, schema.ErrorPushNotificationNotSupported:
+			return nil, fmt.Errorf("%w: %w", ErrUnsupportedOperation, resp.Error)
+		}
+		return nil, resp.Error
+	}
+	if resp.Result == nil {
+		return json.RawMessage("null"), nil
+	}
+	return *resp.Result, nil
+}
+
+// decodeTaskEvent decodes a JSON-RPC response received on a task event stream and
+// reports whether it is the final status update.
+func decodeTaskEvent(data []byte) (TaskEvent, bool, error) {
+	raw, err := decodeResponse(bytes.NewReader(data))
+	if err != nil {
+		return TaskEvent{}, false, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return TaskEvent{}, false, fmt.Errorf("failed to decode task event: %w", err)
+	}
+	if _, ok := fields["artifact"]; ok {
+		var artifact schema.TaskArtifactUpdateEvent
+		if err := json.Unmarshal(raw, &artifact); err != nil {
+			return TaskEvent{}, false, fmt.Errorf("failed to decode artifact update: %w", err)
+		}
+		return TaskEvent{Artifact: &artifact}, false, nil
+	}
+	var status schema.TaskStatusUpdateEvent
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return TaskEvent{}, false, fmt.Errorf("failed to decode status update: %w", err)
+	}
+	return TaskEvent{Status: &status}, status.Final || status.Status.State.IsFinal(), nil
+}
@@ -0,0 +1,596 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared"
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// mockAgent serves tasks/send, tasks/get, tasks/sendSubscribe and tasks/cancel. Requests
+// for the task "slow" and all subscriptions stay open until the client goes away.
+type mockAgent struct {
+	*httptest.Server
+	mu       sync.Mutex
+	canceled []string
+}
+
+func newMockAgent(t *testing.T) *mockAgent {
+	t.Helper()
+	agent := &mockAgent{}
+	agent.Server = httptest.NewServer(http.HandlerFunc(agent.serve))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+func (a *mockAgent) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string              `json:"method"`
+		Params schema.TaskIdParams `json:"params"`
+		ID     any                 `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, _ := json.Marshal(req.ID)
+	respond := func(state schema.TaskState) string {
+		result, _ := json.Marshal(schema.Task{ID: req.Params.ID, Status: schema.TaskStatus{State: state}})
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, id, result)
+	}
+
+	switch req.Method {
+	case "tasks/send":
+		if req.Params.ID == "slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, respond(schema.TaskStateWorking))
+	case "tasks/sendSubscribe":
+		w.Header().Set("Content-Type", "text/event-stream")
+		update, _ := json.Marshal(schema.TaskStatusUpdateEvent{ID: req.Params.ID, Status: schema.TaskStatus{State: schema.TaskStateWorking}})
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\"result\":%s}\n\n", id, update)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	case "tasks/get":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, respond(schema.TaskStateWorking))
+	case "tasks/cancel":
+		a.mu.Lock()
+		a.canceled = append(a.canceled, req.Params.ID)
+		a.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, respond(schema.TaskStateCanceled))
+	default:
+		http.Error(w, "unknown method", http.StatusNotFound)
+	}
+}
+
+func (a *mockAgent) canceledTasks() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.canceled...)
+}
+
+func TestCloseTerminatesSubscriptionsAndRequests(t *testing.T) {
+	agent := newMockAgent(t)
+	c, err := New(agent.URL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+
+	events, err := c.SendTaskSubscribe(ctx, &schema.TaskSendParams{ID: "streamed"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	if event := <-events; event.Status == nil || event.Status.Status.State != schema.TaskStateWorking {
+		t.Fatalf("Expected a working status update, got %+v", event)
+	}
+
+	inFlight := make(chan error, 1)
+	go func() {
+		_, err := c.SendTask(ctx, &schema.TaskSendParams{ID: "slow"})
+		inFlight <- err
+	}()
+	time.Sleep(50 * time.Millisecond) // Let the request reach the agent
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case event, ok := <-events:
+		if ok {
+			t.Fatalf("Expected the subscription channel to be closed, got %+v", event)
+		}
+	default:
+		t.Fatal("Close returned before the subscription channel was closed")
+	}
+	select {
+	case err := <-inFlight:
+		if !errors.Is(err, ErrClientClosed) {
+			t.Fatalf("Expected the in-flight request to fail with ErrClientClosed, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("In-flight request was not aborted by Close")
+	}
+
+	if _, err := c.SendTask(ctx, &schema.TaskSendParams{ID: "t2"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("SendTask after Close: expected ErrClientClosed, got %v", err)
+	}
+	if _, err := c.SendTaskSubscribe(ctx, &schema.TaskSendParams{ID: "t3"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("SendTaskSubscribe after Close: expected ErrClientClosed, got %v", err)
+	}
+	if _, err := c.FetchAgentInfo(ctx); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("FetchAgentInfo after Close: expected ErrClientClosed, got %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+	if canceled := agent.canceledTasks(); len(canceled) != 0 {
+		t.Errorf("Tasks must not be canceled without the option, got %v", canceled)
+	}
+}
+
+func TestCloseCancelsOpenTasks(t *testing.T) {
+	agent := newMockAgent(t)
+	c, err := New(agent.URL, WithCancelTasksOnClose())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.SendTask(ctx, &schema.TaskSendParams{ID: "working"}); err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if _, err := c.SendTaskSubscribe(ctx, &schema.TaskSendParams{ID: "streamed"}); err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	if _, err := c.SendTask(ctx, &schema.TaskSendParams{ID: "done"}); err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if _, err := c.CancelTask(ctx, "done"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	canceled := agent.canceledTasks()
+	want := []string{"done", "streamed", "working"} // "done" by the explicit CancelTask
+	if fmt.Sprint(canceled) != fmt.Sprint(want) {
+		t.Errorf("Canceled tasks = %v, want %v", canceled, want)
+	}
+}
+
+// expectWorking fails the test unless the first event of the subscription is a working
+// status update.
+func expectWorking(t *testing.T, events <-chan TaskEvent) {
+	t.Helper()
+	select {
+	case event := <-events:
+		if event.Status == nil || event.Status.Status.State != schema.TaskStateWorking {
+			t.Fatalf("Expected a working status update, got %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No event received")
+	}
+}
+
+func TestSubscribeRetriesRefusedConnection(t *testing.T) {
+	// Reserve an address nobody listens on yet, as when the agent is still starting
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	c, err := New("http://"+addr, WithSubscribeConnectRetry(6, 25*time.Millisecond, 0))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	agent := &mockAgent{}
+	agent.Server = httptest.NewUnstartedServer(http.HandlerFunc(agent.serve))
+	started := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() {
+		defer close(started)
+		if agent.Listener, err = net.Listen("tcp", addr); err != nil {
+			return
+		}
+		agent.Start()
+	})
+	t.Cleanup(func() {
+		<-started
+		if agent.Listener != nil {
+			agent.Close()
+		}
+	})
+	t.Cleanup(func() { c.Close() }) // Before the agent closes, ending the stream
+
+	events, subErr := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "cold-start"})
+	<-started
+	if err != nil {
+		t.Skipf("Address taken before the agent started: %v", err)
+	}
+	if subErr != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", subErr)
+	}
+	expectWorking(t, events)
+}
+
+func TestSubscribeWithoutRetryFailsOnRefusedConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	c, err := New("http://" + addr)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	if _, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"}); err == nil {
+		t.Fatal("Expected SendTaskSubscribe to fail")
+	}
+}
+
+func TestSubscribeConnectRetryGivesUp(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "starting", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithSubscribeConnectRetry(2, time.Millisecond, 0))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	_, err = c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"})
+	if err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Fatalf("Expected the last 503 error, got %v", err)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", n)
+	}
+}
+
+func TestSubscribeConnectTimeoutDoesNotResendReceivedRequest(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		io.Copy(io.Discard, r.Body) // Lets the server notice when the client gives up
+		<-r.Context().Done()        // The agent received the task but never answers
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithSubscribeConnectRetry(3, time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	_, err = c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"})
+	if !errors.Is(err, errConnectTimeout) {
+		t.Fatalf("Expected the attempt to time out, got %v", err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("Expected the received request not to be sent again, got %d attempts", n)
+	}
+}
+
+func TestSubscribeConnectRetryDoesNotResendAfterDroppedConnection(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		io.Copy(io.Discard, r.Body)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close() // The agent goes away after receiving the task
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithSubscribeConnectRetry(3, time.Millisecond, 0))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	if _, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"}); err == nil {
+		t.Fatal("Expected SendTaskSubscribe to fail")
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("Expected the received request not to be sent again, got %d attempts", n)
+	}
+}
+
+func TestSubscribeConnectTimeoutKeepsEstablishedStream(t *testing.T) {
+	agent := newMockAgent(t)
+	c, err := New(agent.URL, WithSubscribeConnectRetry(1, time.Millisecond, 100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	expectWorking(t, events)
+
+	// The connect timeout must not cut the established stream
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case event, ok := <-events:
+		t.Fatalf("Expected the stream to stay open, got %+v (open: %v)", event, ok)
+	default:
+	}
+}
+
+func TestSubscribeConnectRetryRespectsContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	c, err := New("http://"+addr, WithSubscribeConnectRetry(100, 50*time.Millisecond, 0))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.SendTaskSubscribe(ctx, &schema.TaskSendParams{ID: "t"}); err == nil {
+		t.Fatal("Expected SendTaskSubscribe to fail")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Retries outlived the context deadline: %v", elapsed)
+	}
+}
+
+func TestSendTaskGeneratesMissingIDs(t *testing.T) {
+	agent := newMockAgent(t)
+	var n atomic.Int32
+	c, err := New(agent.URL, WithIDGenerator(shared.IDGeneratorFunc(func() string {
+		return fmt.Sprintf("generated-%d", n.Add(1))
+	})))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	params := &schema.TaskSendParams{}
+	task, err := c.SendTask(context.Background(), params)
+	if err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if params.ID != "generated-1" || task.ID != "generated-1" {
+		t.Errorf("Task ID = %q (params %q), want the generated ID", task.ID, params.ID)
+	}
+	if _, err := c.SendTask(context.Background(), &schema.TaskSendParams{ID: "chosen"}); err != nil {
+		t.Fatalf("SendTask failed: %v", err)
+	}
+	if n.Load() != 1 {
+		t.Errorf("Generator called %d times, want only for the task without an ID", n.Load())
+	}
+}
+
+func TestCancelTaskEndsSubscriptionWithCanceledEvent(t *testing.T) {
+	// The agent closes the stream of a canceled task without sending a final update
+	canceled := make(chan struct{})
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			ID     any    `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "tasks/sendSubscribe":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-canceled
+		case "tasks/cancel":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":{"id":"t1","status":{"state":"canceled"}}}`, req.ID)
+			close(canceled)
+		}
+	}))
+	defer agent.Close()
+	c, err := New(agent.URL)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t1"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	if _, err := c.CancelTask(context.Background(), "t1"); err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+
+	event, ok := <-events
+	if !ok || event.Status == nil || event.Status.Status.State != schema.TaskStateCanceled || !event.Status.Final {
+		t.Fatalf("Expected a final canceled status update, got %+v (open %v)", event, ok)
+	}
+	if _, ok := <-events; ok {
+		t.Error("Subscription should be closed after the final update")
+	}
+}
+
+// bufferingProxyAgent accepts task subscriptions, but like an agent behind a proxy that
+// buffers responses, the events it writes never reach the client. Polled tasks complete
+// with an artifact at the third tasks/get.
+type bufferingProxyAgent struct {
+	*httptest.Server
+	calls sync.Map // method -> *atomic.Int32
+}
+
+func newBufferingProxyAgent(t *testing.T) *bufferingProxyAgent {
+	t.Helper()
+	agent := &bufferingProxyAgent{}
+	polls := make(map[string]int)
+	var mu sync.Mutex
+	agent.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string              `json:"method"`
+			Params schema.TaskIdParams `json:"params"`
+			ID     any                 `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		counter, _ := agent.calls.LoadOrStore(req.Method, new(atomic.Int32))
+		counter.(*atomic.Int32).Add(1)
+		respond := func(task schema.Task) {
+			result, _ := json.Marshal(task)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%v,"result":%s}`, req.ID, result)
+		}
+		working := schema.Task{ID: req.Params.ID, Status: schema.TaskStatus{State: schema.TaskStateWorking}}
+
+		switch req.Method {
+		case "tasks/sendSubscribe":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			update, _ := json.Marshal(schema.TaskStatusUpdateEvent{ID: req.Params.ID, Status: working.Status})
+			fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":%s}\n\n", req.ID, update) // Never flushed
+			<-r.Context().Done()
+		case "tasks/send":
+			respond(working)
+		case "tasks/get":
+			mu.Lock()
+			polls[req.Params.ID]++
+			n := polls[req.Params.ID]
+			mu.Unlock()
+			if n < 3 {
+				respond(working)
+				return
+			}
+			respond(schema.Task{
+				ID:        req.Params.ID,
+				Status:    schema.TaskStatus{State: schema.TaskStateCompleted},
+				Artifacts: []schema.Artifact{{Parts: []schema.Part{schema.Part(`{"type":"text","text":"done"}`)}}},
+			})
+		}
+	}))
+	t.Cleanup(agent.Close)
+	return agent
+}
+
+// count returns the number of requests of the method the agent received.
+func (a *bufferingProxyAgent) count(method string) int32 {
+	if counter, ok := a.calls.Load(method); ok {
+		return counter.(*atomic.Int32).Load()
+	}
+	return 0
+}
+
+// collectEvents reads the events of a subscription until it is closed.
+func collectEvents(t *testing.T, events <-chan TaskEvent) []TaskEvent {
+	t.Helper()
+	var collected []TaskEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return collected
+			}
+			if event.Err != nil {
+				t.Fatalf("Subscription failed: %v", event.Err)
+			}
+			collected = append(collected, event)
+		case <-timeout:
+			t.Fatalf("Subscription did not end, got %d events", len(collected))
+		}
+	}
+}
+
+// assertCompleted checks that the events end with the artifact and final completed status.
+func assertCompleted(t *testing.T, events []TaskEvent) {
+	t.Helper()
+	if len(events) < 2 {
+		t.Fatalf("Expected an artifact and a final status, got %d events", len(events))
+	}
+	if artifact := events[len(events)-2].Artifact; artifact == nil || !strings.Contains(string(artifact.Artifact.Parts[0]), "done") {
+		t.Errorf("Expected the artifact before the final status, got %+v", events[len(events)-2])
+	}
+	last := events[len(events)-1].Status
+	if last == nil || last.Status.State != schema.TaskStateCompleted || !last.Final {
+		t.Errorf("Expected a final completed status, got %+v", events[len(events)-1])
+	}
+}
+
+func TestSubscribeFallsBackToPollingBehindBufferingProxy(t *testing.T) {
+	agent := newBufferingProxyAgent(t)
+	c, err := New(agent.URL, WithPollingFallback(100*time.Millisecond, 20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t1"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	assertCompleted(t, collectEvents(t, events))
+	if !c.Polling() {
+		t.Fatal("Client should remember that event streams of the agent do not get through")
+	}
+
+	// The next task is polled without trying a stream first
+	events, err = c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t2"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	collected := collectEvents(t, events)
+	if first := collected[0].Status; first == nil || first.Status.State != schema.TaskStateWorking {
+		t.Errorf("Expected the status returned by tasks/send first, got %+v", collected[0])
+	}
+	assertCompleted(t, collected)
+	if n := agent.count("tasks/sendSubscribe"); n != 1 {
+		t.Errorf("Agent received %d stream requests, want only the first", n)
+	}
+	if n := agent.count("tasks/send"); n != 1 {
+		t.Errorf("Agent received %d tasks/send requests, want 1", n)
+	}
+}
+
+func TestSubscribeKeepsStreamDeliveringEvents(t *testing.T) {
+	agent := newMockAgent(t)
+	c, err := New(agent.URL, WithPollingFallback(50*time.Millisecond, 0))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer c.Close()
+
+	events, err := c.SendTaskSubscribe(context.Background(), &schema.TaskSendParams{ID: "t1"})
+	if err != nil {
+		t.Fatalf("SendTaskSubscribe failed: %v", err)
+	}
+	expectWorking(t, events)
+	time.Sleep(150 * time.Millisecond) // Past the first event timeout
+	if c.Polling() {
+		t.Fatal("Client fell back to polling although the stream delivered an event")
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// WithDefaultTimeout bounds calls to the agent made with a context without a deadline:
+// JSON-RPC calls and agent card fetches, including their retries (see WithRetry).
+// Task subscriptions are not bounded, as streams may last as long as their task.
+//
+// The timeout of a call is, in order of precedence:
+//  1. the deadline of the context passed to the call, never extended;
+//  2. the timeout of the method set with WithMethodTimeout;
+//  3. the default timeout set with WithDefaultTimeout.
+//
+// Without any of them, calls are not bounded.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.defaultTimeout = d
+	}
+}
+
+// WithMethodTimeout sets the timeout of calls of a JSON-RPC method, e.g. "tasks/send",
+// made with a context without a deadline. It takes precedence over WithDefaultTimeout.
+func WithMethodTimeout(method string, d time.Duration) Option {
+	return func(c *Client) {
+		if c.methodTimeouts == nil {
+			c.methodTimeouts = make(map[string]time.Duration)
+		}
+		c.methodTimeouts[method] = d
+	}
+}
+
+// withTimeout returns ctx bounded by the timeout of method, unless ctx has a deadline
+// or no timeout applies.
+func (c *Client) withTimeout(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	timeout, ok := c.methodTimeouts[method]
+	if !ok {
+		timeout = c.defaultTimeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// sendSlowTask sends the task the mock agent never answers and returns how long the
+// call took and its error.
+func sendSlowTask(t *testing.T, ctx context.Context, opts ...Option) (time.Duration, error) {
+	t.Helper()
+	agent := newMockAgent(t)
+	c, err := New(agent.URL, opts...)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	start := time.Now()
+	_, err = c.SendTask(ctx, &schema.TaskSendParams{ID: "slow"})
+	return time.Since(start), err
+}
+
+func TestTimeoutPrecedence(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		ctx      time.Duration // Timeout of the caller's context, 0 for none
+		opts     []Option
+		min, max time.Duration
+	}{
+		{
+			name: "client default",
+			opts: []Option{WithDefaultTimeout(50 * time.Millisecond)},
+			max:  time.Second,
+		},
+		{
+			name: "method timeout over client default",
+			opts: []Option{WithDefaultTimeout(time.Hour), WithMethodTimeout("tasks/send", 50*time.Millisecond)},
+			max:  time.Second,
+		},
+		{
+			name: "other method timeout",
+			opts: []Option{WithDefaultTimeout(50 * time.Millisecond), WithMethodTimeout("tasks/get", time.Hour)},
+			max:  time.Second,
+		},
+		{
+			name: "tighter caller deadline",
+			ctx:  50 * time.Millisecond,
+			opts: []Option{WithDefaultTimeout(time.Hour), WithMethodTimeout("tasks/send", time.Hour)},
+			max:  time.Second,
+		},
+		{
+			name: "looser caller deadline",
+			ctx:  300 * time.Millisecond,
+			opts: []Option{WithDefaultTimeout(10 * time.Millisecond), WithMethodTimeout("tasks/send", 10*time.Millisecond)},
+			min:  300 * time.Millisecond,
+			max:  2 * time.Second,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.ctx > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.ctx)
+				defer cancel()
+			}
+			elapsed, err := sendSlowTask(t, ctx, tc.opts...)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Expected the call to time out, got %v", err)
+			}
+			if elapsed < tc.min || elapsed > tc.max {
+				t.Fatalf("Call took %v, want between %v and %v", elapsed, tc.min, tc.max)
+			}
+		})
+	}
+}
+
+func TestNoTimeoutByDefault(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	elapsed, err := sendSlowTask(t, ctx)
+	if !errors.Is(err, context.Canceled) || elapsed < 200*time.Millisecond {
+		t.Fatalf("Expected the call to run until canceled, got %v after %v", err, elapsed)
+	}
+}
@@ -70,3 +70,23 @@ type AgentCard struct {
 	// List of specific skills the agent offers.
 	Skills []AgentSkill `json:"skills"`
 }
+
+// AgentCardSummaryMediaType is the media type of an AgentCardSummary. It is a gate4ai
+// extension and not part of the A2A specification.
+const AgentCardSummaryMediaType = "application/vnd.gate4ai.agent-card-summary+json"
+
+// AgentCardSummary is the reduced agent card returned for `?summary=true` or an Accept
+// header of AgentCardSummaryMediaType, for clients only probing capabilities.
+type AgentCardSummary struct {
+	// Human-readable name of the agent.
+	Name string `json:"name"`
+	// Version of the agent or its API.
+	Version string `json:"version"`
+	// Capabilities supported by the agent.
+	Capabilities AgentCapabilities `json:"capabilities"`
+}
+
+// Summary returns the capabilities summary of the card.
+func (c *AgentCard) Summary() *AgentCardSummary {
+	return &AgentCardSummary{Name: c.Name, Version: c.Version, Capabilities: c.Capabilities}
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ArtifactChecksumMetadataKey is the artifact metadata key holding the hex encoded
+// SHA-256 checksum of the artifact content.
+const ArtifactChecksumMetadataKey = "sha256"
+
+// ErrArtifactChecksumMismatch is returned when artifact content does not match its checksum.
+var ErrArtifactChecksumMismatch = errors.New("artifact checksum mismatch")
+
+// ArtifactChecksum returns the hex encoded SHA-256 of the content of the parts, in order:
+// the text of text parts, the decoded bytes (or URI) of file parts and the JSON encoding
+// (with sorted keys) of data parts. Metadata is not included.
+func ArtifactChecksum(parts []Part) (string, error) {
+	hasher := sha256.New()
+	for i, part := range parts {
+		partType, err := GetPartType(part)
+		if err != nil {
+			return "", fmt.Errorf("part %d: %w", i, err)
+		}
+		switch partType {
+		case "text":
+			tp, err := AsTextPart(part)
+			if err != nil {
+				return "", fmt.Errorf("part %d: %w", i, err)
+			}
+			hasher.Write([]byte(tp.Text))
+		case "file":
+			fp, err := AsFilePart(part)
+			if err != nil {
+				return "", fmt.Errorf("part %d: %w", i, err)
+			}
+			switch {
+			case fp.File.Bytes != nil:
+				data, err := base64.StdEncoding.DecodeString(*fp.File.Bytes)
+				if err != nil {
+					return "", fmt.Errorf("part %d: invalid base64 file content: %w", i, err)
+				}
+				hasher.Write(data)
+			case fp.File.URI != nil:
+				hasher.Write([]byte(*fp.File.URI))
+			}
+		case "data":
+			dp, err := AsDataPart(part)
+			if err != nil {
+				return "", fmt.Errorf("part %d: %w", i, err)
+			}
+			data, err := json.Marshal(dp.Data)
+			if err != nil {
+				return "", fmt.Errorf("part %d: %w", i, err)
+			}
+			hasher.Write(data)
+		default:
+			return "", fmt.Errorf("part %d: unsupported part type '%s'", i, partType)
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// Checksum returns the checksum stored in the artifact metadata, or "" if there is none.
+func (a *Artifact) Checksum() string {
+	if a.Metadata == nil {
+		return ""
+	}
+	checksum, _ := (*a.Metadata)[ArtifactChecksumMetadataKey].(string)
+	return checksum
+}
+
+// SetChecksum stores the checksum of parts in the artifact metadata. Parts is the full
+// content of the artifact, which for streamed artifacts includes earlier chunks.
+func (a *Artifact) SetChecksum(parts []Part) error {
+	checksum, err := ArtifactChecksum(parts)
+	if err != nil {
+		return err
+	}
+	if a.Metadata == nil {
+		a.Metadata = &map[string]interface{}{}
+	}
+	(*a.Metadata)[ArtifactChecksumMetadataKey] = checksum
+	return nil
+}
+
+// VerifyArtifactChecksum checks parts against the checksum in the artifact metadata.
+// It returns false if the artifact carries no checksum and ErrArtifactChecksumMismatch
+// (wrapped) if the content does not match.
+func VerifyArtifactChecksum(a *Artifact, parts []Part) (bool, error) {
+	want := a.Checksum()
+	if want == "" {
+		return false, nil
+	}
+	got, err := ArtifactChecksum(parts)
+	if err != nil {
+		return false, err
+	}
+	if got != want {
+		return false, fmt.Errorf("%w: artifact %d: expected %s, got %s", ErrArtifactChecksumMismatch, a.Index, want, got)
+	}
+	return true, nil
+}
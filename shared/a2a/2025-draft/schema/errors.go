@@ -1,5 +1,14 @@
 package schema
 
+// A2A-specific error codes, carried by the error types below.
+const (
+	ErrorTaskNotFound                 = -32001
+	ErrorTaskNotCancelable            = -32002
+	ErrorPushNotificationNotSupported = -32003
+	ErrorUnsupportedOperation         = -32004
+	ErrorContentTypeNotSupported      = -32005
+)
+
 // PushNotificationNotSupportedError indicates the agent does not support push notifications.
 type PushNotificationNotSupportedError struct {
 	Code    int    `json:"code"`    // Always -32003
@@ -56,3 +65,11 @@ type ContentTypeNotSupportedError struct {
 func (e *ContentTypeNotSupportedError) Error() string {
 	return e.Message
 }
+
+// UnsupportedMethodData is the data of the error returned for a method the agent does not
+// support, so clients can adapt to what the agent offers.
+type UnsupportedMethodData struct {
+	Method           string            `json:"method"`           // The rejected method
+	SupportedMethods []string          `json:"supportedMethods"` // Methods the agent accepts
+	Capabilities     AgentCapabilities `json:"capabilities"`     // Capabilities of the agent card
+}
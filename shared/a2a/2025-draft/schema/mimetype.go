@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// DetectFileMimeType guesses the MIME type of file content without a declared type.
+// Inline bytes are sniffed; when sniffing finds nothing more specific than plain text or
+// binary data, the extension of the file name (or URI path) decides if it is known.
+// It returns "" if no type can be determined.
+func DetectFileMimeType(file FileContent) string {
+	var sniffed string
+	if file.Bytes != nil {
+		if data, err := base64.StdEncoding.DecodeString(*file.Bytes); err == nil && len(data) > 0 {
+			sniffed = http.DetectContentType(data)
+			if !isGenericMimeType(sniffed) {
+				return sniffed
+			}
+		}
+	}
+	if byExtension := mimeTypeByName(file); byExtension != "" {
+		return byExtension
+	}
+	return sniffed
+}
+
+// DetectMimeTypes sets the MIME type of file parts that do not declare one, keeping
+// declared types. It returns the number of parts updated.
+func (a *Artifact) DetectMimeTypes() (int, error) {
+	updated := 0
+	for i, part := range a.Parts {
+		if partType, _ := GetPartType(part); partType != "file" {
+			continue
+		}
+		fp, err := AsFilePart(part)
+		if err != nil {
+			return updated, fmt.Errorf("part %d: %w", i, err)
+		}
+		if fp.File.MimeType != nil && *fp.File.MimeType != "" {
+			continue
+		}
+		detected := DetectFileMimeType(fp.File)
+		if detected == "" {
+			continue
+		}
+		fp.File.MimeType = &detected
+		data, err := json.Marshal(fp)
+		if err != nil {
+			return updated, fmt.Errorf("part %d: %w", i, err)
+		}
+		a.Parts[i] = Part(data)
+		updated++
+	}
+	return updated, nil
+}
+
+// mimeTypeByName returns the type registered for the extension of the file name or,
+// without a name, of the URI path.
+func mimeTypeByName(file FileContent) string {
+	name := ""
+	if file.Name != nil {
+		name = *file.Name
+	} else if file.URI != nil {
+		if u, err := url.Parse(*file.URI); err == nil {
+			name = u.Path
+		}
+	}
+	ext := path.Ext(name)
+	if ext == "" {
+		return ""
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// isGenericMimeType reports whether a sniffed type says no more than "text" or "binary".
+func isGenericMimeType(mimeType string) bool {
+	return mimeType == "application/octet-stream" || strings.HasPrefix(mimeType, "text/plain")
+}
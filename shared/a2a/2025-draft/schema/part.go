@@ -0,0 +1,25 @@
+package schema
+
+import "fmt"
+
+// MarshalJSON returns the part's raw JSON, or null for a nil part.
+//
+// Part is a defined type, so it does not inherit the JSON methods of json.RawMessage:
+// without MarshalJSON and UnmarshalJSON encoding/json treats it as a []byte, writes every
+// part as a base64 string, which no A2A agent or client accepts, and fails to decode the
+// JSON objects peers send.
+func (p Part) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+// UnmarshalJSON stores a copy of the raw JSON of the part.
+func (p *Part) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return fmt.Errorf("schema.Part: UnmarshalJSON on nil pointer")
+	}
+	*p = append((*p)[0:0], data...)
+	return nil
+}
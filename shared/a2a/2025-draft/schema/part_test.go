@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPartIsEncodedAsJSONObject(t *testing.T) {
+	message := Message{Role: "user", Parts: []Part{Part(`{"type":"text","text":"hi"}`)}}
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if want := `{"role":"user","parts":[{"type":"text","text":"hi"}]}`; string(data) != want {
+		t.Fatalf("Got %s, want %s", data, want)
+	}
+
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	text, err := AsTextPart(decoded.Parts[0])
+	if err != nil || text.Text != "hi" {
+		t.Fatalf("Decoded part %s: %v", decoded.Parts[0], err)
+	}
+
+	data, err = json.Marshal([]Part{nil})
+	if err != nil || string(data) != "[null]" {
+		t.Fatalf("Nil part encoded as %s: %v", data, err)
+	}
+}
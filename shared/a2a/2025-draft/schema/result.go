@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// TextResult concatenates the text parts of the task's status message, which holds the
+// final response of a completed task. It returns "" if there is no message or no text.
+func (t *Task) TextResult() string {
+	if t.Status.Message == nil {
+		return ""
+	}
+	var text string
+	for _, part := range t.Status.Message.Parts {
+		if tp, err := AsTextPart(part); err == nil {
+			text += tp.Text
+		}
+	}
+	return text
+}
+
+// FileArtifacts returns the artifacts of the task containing at least one file part.
+func (t *Task) FileArtifacts() []Artifact {
+	var artifacts []Artifact
+	for _, artifact := range t.Artifacts {
+		for _, part := range artifact.Parts {
+			if partType, _ := GetPartType(part); partType == "file" {
+				artifacts = append(artifacts, artifact)
+				break
+			}
+		}
+	}
+	return artifacts
+}
+
+// DataResults returns the raw JSON data of the data parts of the task's status message
+// followed by those of its artifacts, in order.
+func (t *Task) DataResults() []json.RawMessage {
+	var results []json.RawMessage
+	if t.Status.Message != nil {
+		results = appendDataResults(results, t.Status.Message.Parts)
+	}
+	for _, artifact := range t.Artifacts {
+		results = appendDataResults(results, artifact.Parts)
+	}
+	return results
+}
+
+// appendDataResults appends the data of the data parts among parts to results.
+func appendDataResults(results []json.RawMessage, parts []Part) []json.RawMessage {
+	for _, part := range parts {
+		var dp struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(part, &dp); err != nil || dp.Type != "data" || dp.Data == nil {
+			continue
+		}
+		results = append(results, dp.Data)
+	}
+	return results
+}
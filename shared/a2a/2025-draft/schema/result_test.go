@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaskResultHelpers(t *testing.T) {
+	var task Task
+	err := json.Unmarshal([]byte(`{
+		"id": "t1",
+		"status": {
+			"state": "completed",
+			"message": {"role": "agent", "parts": [
+				{"type": "text", "text": "Report "},
+				{"type": "data", "data": {"rows": 2}},
+				{"type": "text", "text": "ready"}
+			]},
+			"timestamp": "2025-01-01T00:00:00Z"
+		},
+		"artifacts": [
+			{"index": 0, "parts": [{"type": "text", "text": "summary"}]},
+			{"index": 1, "parts": [{"type": "text", "text": "see file"}, {"type": "file", "file": {"name": "report.csv", "uri": "https://example.com/report.csv"}}]},
+			{"index": 2, "parts": [{"type": "data", "data": {"total": 42}}]}
+		]
+	}`), &task)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if got := task.TextResult(); got != "Report ready" {
+		t.Errorf("TextResult() = %q, want %q", got, "Report ready")
+	}
+
+	files := task.FileArtifacts()
+	if len(files) != 1 || files[0].Index != 1 {
+		t.Errorf("FileArtifacts() = %+v, want the artifact with index 1", files)
+	}
+
+	data := task.DataResults()
+	want := []string{`{"rows": 2}`, `{"total": 42}`}
+	if len(data) != len(want) {
+		t.Fatalf("DataResults() returned %d results, want %d", len(data), len(want))
+	}
+	for i := range want {
+		if string(data[i]) != want[i] {
+			t.Errorf("DataResults()[%d] = %s, want %s", i, data[i], want[i])
+		}
+	}
+}
+
+func TestTaskResultHelpersWithoutContent(t *testing.T) {
+	task := &Task{ID: "t1", Status: TaskStatus{State: TaskStateCompleted}}
+
+	if got := task.TextResult(); got != "" {
+		t.Errorf("TextResult() = %q, want empty", got)
+	}
+	if got := task.FileArtifacts(); len(got) != 0 {
+		t.Errorf("FileArtifacts() = %+v, want none", got)
+	}
+	if got := task.DataResults(); len(got) != 0 {
+		t.Errorf("DataResults() = %v, want none", got)
+	}
+}
@@ -24,6 +24,11 @@ const (
 	TaskStateUnknown TaskState = "unknown"
 )
 
+// IsFinal reports whether the state is terminal (completed, canceled or failed).
+func (s TaskState) IsFinal() bool {
+	return s == TaskStateCompleted || s == TaskStateCanceled || s == TaskStateFailed
+}
+
 // TaskStatus represents the status of a task at a specific point in time.
 type TaskStatus struct {
 	// The current state of the task.
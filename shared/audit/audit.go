@@ -0,0 +1,256 @@
+// Package audit writes tamper-evident audit logs: each entry carries the hash of the
+// previous one, so that altering, removing or reordering entries breaks the chain, and
+// may be signed so that a rewritten chain is detected as well.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is one line of an audit log, encoded as JSON.
+type Entry struct {
+	Seq       uint64         `json:"seq"`
+	Time      time.Time      `json:"time"`
+	Event     string         `json:"event"`
+	Fields    map[string]any `json:"fields,omitempty"`
+	PrevHash  string         `json:"prev_hash"`           // Hash of the previous entry, "" for the first one
+	Hash      string         `json:"hash"`                // SHA-256 of the entry without Hash and Signature
+	Signature string         `json:"signature,omitempty"` // Signature of Hash, if the log is signed
+}
+
+// digest returns the SHA-256 of the entry without its Hash and Signature.
+func (e Entry) digest() ([]byte, error) {
+	e.Hash = ""
+	e.Signature = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], nil
+}
+
+// normalize returns the fields as they are decoded from the log, so that the hash of an
+// entry does not depend on the Go types of its field values (e.g. the order of struct fields).
+func normalize(fields map[string]any) (map[string]any, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]any
+	return normalized, decode(data, &normalized)
+}
+
+// decode unmarshals data keeping numbers as they are written.
+func decode(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// Logger appends chained entries to a writer. It is safe for concurrent use.
+type Logger struct {
+	mu       sync.Mutex
+	w        io.Writer
+	signer   Signer
+	seq      uint64
+	prevHash string
+}
+
+// Option configures a Logger.
+type Option func(*Logger)
+
+// WithSigner signs the hash of each entry with signer.
+func WithSigner(signer Signer) Option {
+	return func(l *Logger) {
+		l.signer = signer
+	}
+}
+
+// WithResume continues the chain of an existing log whose last entry is last, e.g. after
+// a restart appending to the same file.
+func WithResume(last Entry) Option {
+	return func(l *Logger) {
+		l.seq = last.Seq
+		l.prevHash = last.Hash
+	}
+}
+
+// NewLogger creates a logger writing one JSON entry per line to w.
+func NewLogger(w io.Writer, opts ...Option) *Logger {
+	l := &Logger{w: w}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// OpenFile opens the log at path for appending, creating it if needed, and returns a
+// logger continuing the chain of its last entry. The caller closes the file.
+func OpenFile(path string, opts ...Option) (*Logger, *os.File, error) {
+	last, err := lastEntry(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+	if last != nil {
+		opts = append(opts, WithResume(*last))
+	}
+	return NewLogger(file, opts...), file, nil
+}
+
+// lastEntry returns the last entry of the log at path, nil if it is missing or empty.
+func lastEntry(path string) (*Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var last []byte
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if last == nil {
+		return nil, nil
+	}
+	var entry Entry
+	if err := decode(last, &entry); err != nil {
+		return nil, fmt.Errorf("invalid last entry of audit log %s: %w", path, err)
+	}
+	return &entry, nil
+}
+
+// Log appends an entry for event and returns it.
+func (l *Logger) Log(event string, fields map[string]any) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fields, err := normalize(fields)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode audit entry fields: %w", err)
+	}
+	entry := Entry{
+		Seq:      l.seq + 1,
+		Time:     time.Now().UTC(),
+		Event:    event,
+		Fields:   fields,
+		PrevHash: l.prevHash,
+	}
+	digest, err := entry.digest()
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	entry.Hash = hex.EncodeToString(digest)
+	if l.signer != nil {
+		signature, err := l.signer.Sign(digest)
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to sign audit entry: %w", err)
+		}
+		entry.Signature = hex.EncodeToString(signature)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := l.w.Write(append(line, '\n')); err != nil {
+		return Entry{}, fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	l.seq = entry.Seq
+	l.prevHash = entry.Hash
+	return entry, nil
+}
+
+// VerifyError reports the first entry of a log failing verification.
+type VerifyError struct {
+	Line   int    // Line of the entry in the log, starting at 1
+	Seq    uint64 // Sequence number of the entry, 0 if it could not be decoded
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("audit log entry %d (line %d): %s", e.Seq, e.Line, e.Reason)
+}
+
+// Verify reads a log written by a Logger and checks that every entry matches its hash and
+// follows the previous one. With a verifier, every entry must also carry a valid
+// signature. It returns the number of verified entries, and a *VerifyError for the first
+// entry failing the checks. Entries removed from the end of the log cannot be detected,
+// compare the returned count or the last hash with a copy kept elsewhere for that.
+func Verify(r io.Reader, verifier Verifier) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var prev *Entry
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry Entry
+		if err := decode(scanner.Bytes(), &entry); err != nil {
+			return line - 1, &VerifyError{Line: line, Reason: fmt.Sprintf("invalid entry: %v", err)}
+		}
+		fail := func(reason string) (int, error) {
+			return line - 1, &VerifyError{Line: line, Seq: entry.Seq, Reason: reason}
+		}
+
+		digest, err := entry.digest()
+		if err != nil {
+			return fail(fmt.Sprintf("failed to encode entry: %v", err))
+		}
+		if hex.EncodeToString(digest) != entry.Hash {
+			return fail("hash does not match the entry")
+		}
+		if prev == nil && (entry.Seq != 1 || entry.PrevHash != "") {
+			return fail("the log does not start with the first entry")
+		}
+		if prev != nil {
+			if entry.PrevHash != prev.Hash {
+				return fail("previous hash does not match the previous entry")
+			}
+			if entry.Seq != prev.Seq+1 {
+				return fail(fmt.Sprintf("sequence number follows %d", prev.Seq))
+			}
+		}
+		if verifier != nil {
+			signature, err := hex.DecodeString(entry.Signature)
+			if err != nil || entry.Signature == "" {
+				return fail("missing or invalid signature")
+			}
+			if err := verifier.Verify(digest, signature); err != nil {
+				return fail(err.Error())
+			}
+		}
+		prev = &entry
+	}
+	if err := scanner.Err(); err != nil {
+		return line, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return line, nil
+}
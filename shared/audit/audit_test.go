@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLog logs n tool calls and returns the log.
+func writeLog(t *testing.T, n int, opts ...Option) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, opts...)
+	for i := 0; i < n; i++ {
+		fields := map[string]any{
+			"user":  "alice",
+			"tool":  "report",
+			"args":  struct{ Query string }{Query: "q"},
+			"limit": i + 1,
+		}
+		if _, err := logger.Log("tools/call", fields); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// assertVerifyFails checks that verification fails at the given line.
+func assertVerifyFails(t *testing.T, log []byte, verifier Verifier, line int) {
+	t.Helper()
+	_, err := Verify(bytes.NewReader(log), verifier)
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("Expected a verification error, got %v", err)
+	}
+	if verifyErr.Line != line {
+		t.Fatalf("Expected the verification to fail at line %d, got %v", line, err)
+	}
+}
+
+func TestIntactChainVerifies(t *testing.T) {
+	count, err := Verify(bytes.NewReader(writeLog(t, 3)), nil)
+	if err != nil || count != 3 {
+		t.Fatalf("Expected 3 verified entries, got %d, %v", count, err)
+	}
+}
+
+func TestAlteredEntryFailsVerification(t *testing.T) {
+	log := writeLog(t, 3)
+	altered := bytes.Replace(log, []byte(`"limit":2`), []byte(`"limit":20`), 1)
+	if bytes.Equal(log, altered) {
+		t.Fatal("Test log does not contain the altered field")
+	}
+	assertVerifyFails(t, altered, nil, 2)
+}
+
+func TestRemovedEntryFailsVerification(t *testing.T) {
+	lines := strings.SplitAfter(string(writeLog(t, 3)), "\n")
+	assertVerifyFails(t, []byte(lines[0]+lines[2]), nil, 2)
+	assertVerifyFails(t, []byte(lines[1]+lines[2]), nil, 1)
+}
+
+func TestRehashedEntryFailsSignatureVerification(t *testing.T) {
+	key := NewHMAC([]byte("audit-key"))
+	log := writeLog(t, 3, WithSigner(key))
+	if _, err := Verify(bytes.NewReader(log), key); err != nil {
+		t.Fatalf("Expected the signed log to verify, got %v", err)
+	}
+
+	// An attacker without the key rewrites the whole chain from the second entry on
+	lines := strings.SplitAfter(string(log), "\n")
+	var forged bytes.Buffer
+	forged.WriteString(lines[0])
+	first, err := Verify(strings.NewReader(lines[0]), nil)
+	if err != nil || first != 1 {
+		t.Fatalf("Failed to verify the first entry: %v", err)
+	}
+	var resume Entry
+	if err := decode([]byte(lines[0]), &resume); err != nil {
+		t.Fatal(err)
+	}
+	attacker := NewLogger(&forged, WithResume(resume), WithSigner(NewHMAC([]byte("guessed-key"))))
+	attacker.Log("tools/call", map[string]any{"user": "mallory"})
+
+	if _, err := Verify(bytes.NewReader(forged.Bytes()), nil); err != nil {
+		t.Fatalf("Expected the forged chain to be consistent, got %v", err)
+	}
+	assertVerifyFails(t, forged.Bytes(), key, 2)
+}
+
+func TestEd25519SignedLogVerifiesWithPublicKey(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := writeLog(t, 2, WithSigner(NewEd25519Signer(private)))
+	if count, err := Verify(bytes.NewReader(log), NewEd25519Verifier(public)); err != nil || count != 2 {
+		t.Fatalf("Expected 2 verified entries, got %d, %v", count, err)
+	}
+
+	otherPublic, _, _ := ed25519.GenerateKey(nil)
+	assertVerifyFails(t, log, NewEd25519Verifier(otherPublic), 1)
+	assertVerifyFails(t, writeLog(t, 1), NewEd25519Verifier(public), 1) // Unsigned
+}
+
+func TestOpenFileContinuesChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for i := 0; i < 2; i++ {
+		logger, file, err := OpenFile(path)
+		if err != nil {
+			t.Fatalf("OpenFile failed: %v", err)
+		}
+		if _, err := logger.Log("config_reloaded", nil); err != nil {
+			t.Fatalf("Log failed: %v", err)
+		}
+		file.Close()
+	}
+
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := Verify(bytes.NewReader(log), nil)
+	if err != nil || count != 2 {
+		t.Fatalf("Expected the reopened log to verify with 2 entries, got %d, %v", count, err)
+	}
+}
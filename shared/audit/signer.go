@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrInvalidSignature is returned by verifiers for signatures not matching the entry.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Signer signs the hashes of audit entries.
+type Signer interface {
+	Sign(digest []byte) ([]byte, error)
+}
+
+// Verifier checks the signatures of audit entries, returning ErrInvalidSignature for
+// signatures not matching the hash.
+type Verifier interface {
+	Verify(digest []byte, signature []byte) error
+}
+
+// HMAC signs and verifies entries with a shared secret key.
+type HMAC struct {
+	key []byte
+}
+
+var (
+	_ Signer   = (*HMAC)(nil)
+	_ Verifier = (*HMAC)(nil)
+)
+
+// NewHMAC creates an HMAC-SHA256 signer and verifier using key.
+func NewHMAC(key []byte) *HMAC {
+	return &HMAC{key: append([]byte(nil), key...)}
+}
+
+func (h *HMAC) Sign(digest []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write(digest)
+	return mac.Sum(nil), nil
+}
+
+func (h *HMAC) Verify(digest []byte, signature []byte) error {
+	expected, _ := h.Sign(digest)
+	if !hmac.Equal(expected, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Ed25519Signer signs entries with a private key, so that the log can be verified by
+// anyone holding the public key without being able to sign entries.
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewEd25519Signer creates a signer using the private key.
+func NewEd25519Signer(key ed25519.PrivateKey) *Ed25519Signer {
+	return &Ed25519Signer{key: key}
+}
+
+func (s *Ed25519Signer) Sign(digest []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, digest), nil
+}
+
+// Ed25519Verifier verifies entries signed by an Ed25519Signer.
+type Ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+// NewEd25519Verifier creates a verifier using the public key.
+func NewEd25519Verifier(key ed25519.PublicKey) *Ed25519Verifier {
+	return &Ed25519Verifier{key: key}
+}
+
+func (v *Ed25519Verifier) Verify(digest []byte, signature []byte) error {
+	if !ed25519.Verify(v.key, digest, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultA2AAgentCardPath is the standard A2A discovery path of an agent card.
+const DefaultA2AAgentCardPath = "/.well-known/agent.json"
+
+// DefaultA2AEndpointPath is the path the A2A methods of an agent are served at if its URL
+// has no path.
+const DefaultA2AEndpointPath = "/a2a"
+
+// A2ACardBaseInfo is the configured part of an A2A agent card served by the gateway.
+// The card's name is the agent's name in the configuration.
+type A2ACardBaseInfo struct {
+	Path                 string // Path the card is served at, DefaultA2AAgentCardPath if empty
+	Description          string
+	URL                  string // A2A endpoint announced in the card; derived from the request if empty, see EndpointPath
+	Version              string
+	DocumentationURL     string
+	ProviderOrganization string
+	ProviderURL          string
+	// Capabilities announced in the card; they also decide which A2A methods the agent accepts
+	Streaming              bool // tasks/sendSubscribe and tasks/resubscribe
+	PushNotifications      bool // tasks/pushNotification/set and tasks/pushNotification/get
+	StateTransitionHistory bool
+	Skills                 []A2ASkill // Skills announced in the card, followed by those registered at runtime
+}
+
+// A2ASkill is a skill announced in an A2A agent card.
+type A2ASkill struct {
+	ID          string // Unique within the agent
+	Name        string
+	Description string
+	Tags        []string
+	Examples    []string
+	InputModes  []string // Override the default input modes of the agent
+	OutputModes []string // Override the default output modes of the agent
+}
+
+// CardPath returns the path the card is served at.
+func (i A2ACardBaseInfo) CardPath() string {
+	if i.Path == "" {
+		return DefaultA2AAgentCardPath
+	}
+	return i.Path
+}
+
+// EndpointPath returns the path the A2A methods of the agent are served at: the path of
+// its URL, or DefaultA2AEndpointPath if the URL is empty or has no path.
+func (i A2ACardBaseInfo) EndpointPath() string {
+	if u, err := url.Parse(i.URL); err == nil && u.Path != "" && u.Path != "/" {
+		return u.Path
+	}
+	return DefaultA2AEndpointPath
+}
+
+// ValidateA2AAgents checks that every agent has a non-empty name, that
+// no two agents are served at the same path and that the skills of each
+// agent have an ID and a name, their IDs unique within the agent.
+func ValidateA2AAgents(agents map[string]A2ACardBaseInfo) error {
+	names := sortedA2AAgentNames(agents)
+	paths := make(map[string]string) // path -> agent name
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("a2a agent name must not be empty")
+		}
+		path := agents[name].CardPath()
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("a2a agent '%s': path '%s' must start with '/'", name, path)
+		}
+		if other, exists := paths[path]; exists {
+			return fmt.Errorf("a2a agents '%s' and '%s' are both served at '%s'", other, name, path)
+		}
+		paths[path] = name
+
+		skillIDs := make(map[string]bool)
+		for _, skill := range agents[name].Skills {
+			if strings.TrimSpace(skill.ID) == "" || strings.TrimSpace(skill.Name) == "" {
+				return fmt.Errorf("a2a agent '%s': skills must have an id and a name", name)
+			}
+			if skillIDs[skill.ID] {
+				return fmt.Errorf("a2a agent '%s': skill '%s' is defined twice", name, skill.ID)
+			}
+			skillIDs[skill.ID] = true
+		}
+	}
+	return nil
+}
+
+// sortedA2AAgentNames returns the agent names in a stable order.
+func sortedA2AAgentNames(agents map[string]A2ACardBaseInfo) []string {
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
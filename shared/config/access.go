@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// AllBackends in the subscriptions of a user gives them access to every configured
+// backend, e.g. for administrators.
+const AllBackends = "*"
+
+// AdminRole as the "role" param of a user lets them use the admin API of the gateway.
+// The portal stores it as "ADMIN"; roles are compared ignoring case.
+const AdminRole = "admin"
+
+// UserBackends returns the IDs of the backends the user may send requests to: the
+// backends they are subscribed to, or every configured backend if their subscriptions
+// include AllBackends.
+func UserBackends(cfg IConfig, userID string) ([]string, error) {
+	subscribes, err := cfg.GetUserSubscribes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(subscribes, AllBackends) {
+		return cfg.BackendIDs()
+	}
+	return subscribes, nil
+}
+
+// UserCanAccessBackend reports whether the user may send requests to the backend: the
+// backend is configured and the user is subscribed to it or to AllBackends.
+func UserCanAccessBackend(cfg IConfig, userID string, backendID string) (bool, error) {
+	_, err := cfg.GetBackend(backendID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	subscribes, err := cfg.GetUserSubscribes(userID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(subscribes, backendID) || slices.Contains(subscribes, AllBackends), nil
+}
+
+// UserIsAdmin reports whether the "role" param of the user is AdminRole.
+func UserIsAdmin(cfg IConfig, userID string) (bool, error) {
+	params, err := cfg.GetUserParams(userID)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(params["role"], AdminRole), nil
+}
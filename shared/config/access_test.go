@@ -0,0 +1,48 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUserCanAccessBackend(t *testing.T) {
+	cfg := NewInternalConfig()
+	cfg.SetBackend("b1", "http://b1/sse")
+	cfg.SetBackend("b2", "http://b2/sse")
+	cfg.SetUserSubscribes("alice", []string{"b1", "gone"})
+	cfg.SetUserSubscribes("admin", []string{AllBackends})
+
+	for _, tc := range []struct {
+		userID, backendID string
+		want              bool
+	}{
+		{"alice", "b1", true},
+		{"alice", "b2", false},
+		{"alice", "gone", false}, // Subscribed, but not configured
+		{"admin", "b1", true},
+		{"admin", "b2", true},
+		{"admin", "unknown", false},
+		{"nobody", "b1", false},
+	} {
+		got, err := UserCanAccessBackend(cfg, tc.userID, tc.backendID)
+		if err != nil || got != tc.want {
+			t.Errorf("UserCanAccessBackend(%q, %q) = %v, %v; want %v", tc.userID, tc.backendID, got, err, tc.want)
+		}
+	}
+
+	if backends, err := UserBackends(cfg, "admin"); err != nil || !slices.Equal(backends, []string{"b1", "b2"}) {
+		t.Errorf("UserBackends of a wildcard user = %v, %v", backends, err)
+	}
+}
+
+func TestUserIsAdmin(t *testing.T) {
+	cfg := NewInternalConfig()
+	cfg.SetUserParam("root", "role", "ADMIN")
+	cfg.SetUserParam("alice", "role", "user")
+
+	for userID, want := range map[string]bool{"root": true, "alice": false, "nobody": false} {
+		if got, err := UserIsAdmin(cfg, userID); err != nil || got != want {
+			t.Errorf("UserIsAdmin(%q) = %v, %v; want %v", userID, got, err, want)
+		}
+	}
+}
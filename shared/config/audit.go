@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/gate4ai/mcp/shared/audit"
+	"go.uber.org/zap"
+)
+
+// Events recorded in the audit log of a YamlConfig, see SetAuditLog.
+const (
+	AuditConfigReloaded = "config_reloaded" // The changed file was applied
+	AuditConfigRejected = "config_rejected" // The changed file failed to load and was ignored
+	AuditKeyRevoked     = "key_revoked"     // An API key was revoked at runtime
+	AuditBackendPut     = "backend_put"     // A backend was added or replaced at runtime
+	AuditBackendRemoved = "backend_removed" // A backend was removed at runtime
+)
+
+// SetAuditLog records the reloads of the watched files, the keys revoked and the
+// backends changed at runtime in log, nil to stop recording them.
+func (c *YamlConfig) SetAuditLog(log *audit.Logger) {
+	c.auditLog.Store(log)
+}
+
+// recordAudit appends an entry to the audit log, if any. A failed write is logged, the
+// change it records stays in effect.
+func (c *YamlConfig) recordAudit(event string, fields map[string]any) {
+	log := c.auditLog.Load()
+	if log == nil {
+		return
+	}
+	if _, err := log.Log(event, fields); err != nil {
+		c.logger.Error("Failed to write audit log entry", zap.String("event", event), zap.Error(err))
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/audit"
+	"go.uber.org/zap"
+)
+
+func TestAuditLogRecordsReloadsAndRuntimeChanges(t *testing.T) {
+	path := writeYaml(t, `users:
+  alice:
+    keys: ['`+HashAPIKey("key-old")+`']
+backends:
+  static:
+    url: http://static/sse
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	signer := audit.NewHMAC([]byte("audit-key"))
+	cfg.SetAuditLog(audit.NewLogger(&buf, audit.WithSigner(signer)))
+
+	if _, err := cfg.RevokeKey(HashAPIKey("key-old")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.PutBackend("dynamic", []byte("url: http://dynamic/sse\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.RemoveBackend("dynamic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("backends:\n  static:\n    url: http://static/v2/sse\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.reload()
+	if err := os.WriteFile(path, []byte("server:\n  authorization: sometimes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg.reload()
+
+	count, err := audit.Verify(bytes.NewReader(buf.Bytes()), signer)
+	if err != nil {
+		t.Fatalf("Audit log failed verification: %v", err)
+	}
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry audit.Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, entry.Event)
+		if entry.Event == AuditKeyRevoked && entry.Fields["userID"] != "alice" {
+			t.Errorf("Expected the revoked key of alice, got %v", entry.Fields)
+		}
+	}
+	want := []string{AuditKeyRevoked, AuditBackendPut, AuditBackendRemoved, AuditConfigReloaded, AuditConfigRejected}
+	if count != len(want) || strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected audit events %v, got %d: %v", want, count, events)
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// BackendEditor is implemented by configurations whose backends can be added, replaced
+// and removed at runtime, e.g. by the admin API of the gateway.
+type BackendEditor interface {
+	// PutBackend adds the backend backendID, or replaces it if it exists, from its
+	// definition in data: the fields of a backend in the configuration file, as YAML or
+	// JSON. It reports whether the backend was added.
+	PutBackend(backendID string, data []byte) (created bool, err error)
+	// RemoveBackend removes the backend backendID, ErrNotFound if there is none.
+	RemoveBackend(backendID string) error
+}
+
+var _ BackendEditor = (*YamlConfig)(nil)
+
+// ErrInvalidBackend is returned for backend IDs and definitions that cannot be used.
+var ErrInvalidBackend = errors.New("invalid backend")
+
+// backendIDPattern matches the backend IDs accepted at runtime, which appear in URLs and
+// in the "<backendID>:<name>" form of tool, prompt and resource names.
+var backendIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// PutBackend adds or replaces a backend as if it were in the backends section of the
+// file. Unknown fields are rejected. Unless server.admin.allow_host_access is set,
+// definitions touching the host of the gateway are rejected as well: stdio backends
+// (command, env and dir), ${VAR} placeholders and "file:" or "env:" bearer references.
+// With server.admin.persist_backends the backend is written to the file too, and nothing
+// changes if that fails; otherwise the change lasts until the file is reloaded. Sessions
+// already connected to a replaced backend keep their connections until they are renewed.
+func (c *YamlConfig) PutBackend(backendID string, data []byte) (bool, error) {
+	if !backendIDPattern.MatchString(backendID) {
+		return false, fmt.Errorf("%w: ID '%s' must consist of letters, digits, '.', '-' and '_'", ErrInvalidBackend, backendID)
+	}
+	c.mu.RLock()
+	allowHostAccess := c.adminAllowHostAccess
+	c.mu.RUnlock()
+	lookupEnv := os.LookupEnv
+	var placeholders []string
+	if !allowHostAccess {
+		lookupEnv = func(name string) (string, bool) {
+			placeholders = append(placeholders, name)
+			return "", false
+		}
+	}
+	expanded, err := ExpandEnv(data, lookupEnv)
+	if len(placeholders) > 0 {
+		return false, fmt.Errorf("%w '%s': environment variable placeholders are not accepted without server.admin.allow_host_access", ErrInvalidBackend, backendID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidBackend, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(expanded))
+	decoder.KnownFields(true)
+	var yamlBackend yamlBackend
+	if err := decoder.Decode(&yamlBackend); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("empty definition")
+		}
+		return false, fmt.Errorf("%w '%s': %w", ErrInvalidBackend, backendID, err)
+	}
+	if !allowHostAccess {
+		if err := checkHostAccess(yamlBackend); err != nil {
+			return false, fmt.Errorf("%w '%s': %w", ErrInvalidBackend, backendID, err)
+		}
+	}
+	backend, err := c.parseBackend(backendID, yamlBackend)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidBackend, err)
+	}
+	if err := errors.Join(validateBackend(backendID, backend)...); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidBackend, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.adminPersistBackends {
+		// The definition is written as sent, keeping its environment variable placeholders
+		var value any
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return false, fmt.Errorf("%w '%s': %w", ErrInvalidBackend, backendID, err)
+		}
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return false, err
+		}
+		if err := c.persistBackend(backendID, &node); err != nil {
+			return false, err
+		}
+	}
+	_, exists := c.backends[backendID]
+	c.backends[backendID] = backend
+	c.logger.Info("Backend changed at runtime", zap.String("backend", backendID), zap.Bool("created", !exists), zap.Bool("persisted", c.adminPersistBackends))
+	c.recordAudit(AuditBackendPut, map[string]any{"backend": backendID, "created": !exists, "persisted": c.adminPersistBackends})
+	return !exists, nil
+}
+
+// checkHostAccess returns an error if the backend would run a process on the host of
+// the gateway or read its files or environment, which definitions sent at runtime may
+// only do with server.admin.allow_host_access.
+func checkHostAccess(backend yamlBackend) error {
+	if len(backend.Command) > 0 || len(backend.Env) > 0 || backend.Dir != "" {
+		return errors.New("stdio backends (command, env and dir) are not accepted without server.admin.allow_host_access")
+	}
+	if strings.HasPrefix(backend.Bearer, SecretFilePrefix) || strings.HasPrefix(backend.Bearer, SecretEnvPrefix) {
+		return errors.New("bearer: file and environment variable references are not accepted without server.admin.allow_host_access")
+	}
+	return nil
+}
+
+// RemoveBackend removes a backend, from the file too with server.admin.persist_backends.
+// Requests routed to it fail from then on as for any unknown backend.
+func (c *YamlConfig) RemoveBackend(backendID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.backends[backendID]; !exists {
+		return ErrNotFound
+	}
+	if c.adminPersistBackends {
+		if err := c.persistBackend(backendID, nil); err != nil {
+			return err
+		}
+	}
+	delete(c.backends, backendID)
+	c.logger.Info("Backend removed at runtime", zap.String("backend", backendID), zap.Bool("persisted", c.adminPersistBackends))
+	c.recordAudit(AuditBackendRemoved, map[string]any{"backend": backendID, "persisted": c.adminPersistBackends})
+	return nil
+}
+
+// persistBackend sets the backend backendID in the backends section of the file to
+// value, or removes it if value is nil, keeping the rest of the file. The file is
+// replaced by renaming a new one over it. Callers hold c.mu.
+func (c *YamlConfig) persistBackend(backendID string, value *yaml.Node) error {
+	info, err := os.Stat(c.configPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(c.configPath)
+	if err != nil {
+		return err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", c.configPath, err)
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to update %s: not a mapping", c.configPath)
+	}
+
+	backends := mappingValue(doc, "backends")
+	if backends == nil {
+		if value == nil {
+			return nil
+		}
+		backends = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "backends"}, backends)
+	} else if backends.Kind != yaml.MappingNode {
+		// e.g. "backends:" without entries
+		*backends = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	setMappingValue(backends, backendID, value)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.configPath), "."+filepath.Base(c.configPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.configPath)
+}
+
+// mappingValue returns the value of key in the mapping node, nil if it has none.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets key in the mapping node to value, appending it if it is new, or
+// removes key if value is nil.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		if value == nil {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		} else {
+			mapping.Content[i+1] = value
+		}
+		return
+	}
+	if value != nil {
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPutBackendAddsReplacesAndRemoves(t *testing.T) {
+	path := writeYaml(t, `backends:
+  static:
+    url: http://static/sse
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := cfg.PutBackend("dynamic", []byte("url: http://dynamic/sse\ntimeout: 5s\n"))
+	if err != nil || !created {
+		t.Fatalf("PutBackend of a new backend = %v, %v", created, err)
+	}
+	backend, err := cfg.GetBackend("dynamic")
+	if err != nil || backend.URL != "http://dynamic/sse" || backend.Timeout.String() != "5s" {
+		t.Fatalf("GetBackend after PutBackend = %+v, %v", backend, err)
+	}
+
+	// JSON is accepted as well
+	created, err = cfg.PutBackend("dynamic", []byte(`{"urls": ["http://r1/sse", "http://r2/sse"]}`))
+	if err != nil || created {
+		t.Fatalf("PutBackend of an existing backend = %v, %v", created, err)
+	}
+	if backend, _ := cfg.GetBackend("dynamic"); backend.URL != "http://r1/sse" || len(backend.Replicas) != 1 || backend.Timeout != 0 {
+		t.Errorf("Replaced backend = %+v", backend)
+	}
+	if ids, _ := cfg.BackendIDs(); strings.Join(ids, ",") != "dynamic,static" {
+		t.Errorf("BackendIDs = %v", ids)
+	}
+
+	if err := cfg.RemoveBackend("dynamic"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.GetBackend("dynamic"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBackend of a removed backend: %v", err)
+	}
+	if err := cfg.RemoveBackend("dynamic"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RemoveBackend of an unknown backend: %v", err)
+	}
+
+	// Not persisted, so the file is unchanged
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "dynamic") {
+		t.Errorf("File changed without persist_backends:\n%s", data)
+	}
+}
+
+func TestPutBackendRejectsInvalidBackends(t *testing.T) {
+	cfg, err := NewYamlConfig(writeYaml(t, "backends: {}\n"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct{ id, data string }{
+		"bad ID":          {"a:b", "url: http://a/sse"},
+		"empty":           {"a", ""},
+		"unknown field":   {"a", "url: http://a/sse\nurll: typo"},
+		"relative URL":    {"a", "url: /sse"},
+		"invalid timeout": {"a", "url: http://a/sse\ntimeout: soon"},
+		"url and command": {"a", "url: http://a/sse\ncommand: [server]"},
+	} {
+		if _, err := cfg.PutBackend(tc.id, []byte(tc.data)); !errors.Is(err, ErrInvalidBackend) {
+			t.Errorf("%s: PutBackend error = %v, want ErrInvalidBackend", name, err)
+		}
+	}
+	if ids, _ := cfg.BackendIDs(); len(ids) != 0 {
+		t.Errorf("Invalid backends were added: %v", ids)
+	}
+}
+
+func TestPutBackendRejectsHostAccess(t *testing.T) {
+	t.Setenv("GATEWAY_SECRET", "s3cret")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("s3cret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	definitions := map[string]string{
+		"command":            "command: [sh, -c, id]",
+		"env":                "command: [server]\nenv: {A: b}",
+		"dir":                "command: [server]\ndir: /tmp",
+		"placeholder":        "url: http://a/${GATEWAY_SECRET}/sse",
+		"placeholder unset":  "url: ${GATEWAY_UNSET:-http://a/sse}",
+		"file bearer":        "url: http://a/sse\nbearer: file:" + secretFile,
+		"environment bearer": "url: http://a/sse\nbearer: env:GATEWAY_SECRET",
+	}
+
+	cfg, err := NewYamlConfig(writeYaml(t, "backends: {}\n"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range definitions {
+		_, err := cfg.PutBackend("a", []byte(data))
+		if !errors.Is(err, ErrInvalidBackend) || !strings.Contains(err.Error(), "allow_host_access") {
+			t.Errorf("%s: PutBackend error = %v, want a refusal naming allow_host_access", name, err)
+		}
+	}
+	if ids, _ := cfg.BackendIDs(); len(ids) != 0 {
+		t.Errorf("Backends touching the host were added: %v", ids)
+	}
+	if _, err := cfg.PutBackend("a", []byte("url: http://a/sse\nbearer: literal-$$-token")); err != nil {
+		t.Errorf("Literal bearer refused: %v", err)
+	}
+
+	// The operator may allow it
+	cfg, err = NewYamlConfig(writeYaml(t, "server:\n  admin:\n    allow_host_access: true\nbackends: {}\n"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range definitions {
+		if _, err := cfg.PutBackend("a", []byte(data)); err != nil {
+			t.Errorf("%s: PutBackend with allow_host_access: %v", name, err)
+		}
+	}
+}
+
+func TestPutBackendPersistsToFile(t *testing.T) {
+	path := writeYaml(t, `server:
+  admin:
+    persist_backends: true
+    allow_host_access: true # For the placeholder
+users:
+  alice:
+    subscribes: [static] # kept
+backends:
+  static:
+    url: http://static/sse
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.PutBackend("dynamic", []byte("url: ${DYNAMIC_URL:-http://dynamic/sse}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.RemoveBackend("static"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file struct {
+		Users    map[string]any               `yaml:"users"`
+		Backends map[string]map[string]string `yaml:"backends"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Backends) != 1 || file.Backends["dynamic"]["url"] != "${DYNAMIC_URL:-http://dynamic/sse}" {
+		t.Errorf("Persisted backends = %v", file.Backends)
+	}
+	if file.Users["alice"] == nil || !strings.Contains(string(data), "# kept") {
+		t.Errorf("Rest of the file not kept:\n%s", data)
+	}
+
+	// The persisted file loads with the same backends
+	reloaded, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend, err := reloaded.GetBackend("dynamic"); err != nil || backend.URL != "http://dynamic/sse" {
+		t.Errorf("Reloaded backend = %+v, %v", backend, err)
+	}
+	if _, err := reloaded.GetBackend("static"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Removed backend reloaded: %v", err)
+	}
+}
+
+func TestPersistBackendsRequiresYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"server": {"admin": {"persist_backends": true}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "persist_backends") {
+		t.Errorf("Loading a JSON file with persist_backends: %v", err)
+	}
+}
+
+func TestPutBackendConcurrentWithReads(t *testing.T) {
+	cfg, err := NewYamlConfig(writeYaml(t, "backends:\n  static:\n    url: http://static/sse\n"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				id := fmt.Sprintf("b%d-%d", i, j)
+				if _, err := cfg.PutBackend(id, []byte("url: http://"+id+"/sse")); err != nil {
+					t.Error(err)
+					return
+				}
+				if j%2 == 0 {
+					if err := cfg.RemoveBackend(id); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				if backend, err := cfg.GetBackend("static"); err != nil || backend.URL != "http://static/sse" {
+					t.Errorf("GetBackend during changes = %+v, %v", backend, err)
+					return
+				}
+				if _, err := cfg.BackendIDs(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ids, _ := cfg.BackendIDs(); len(ids) != 1+4*25 {
+		t.Errorf("%d backends after the changes, want %d", len(ids), 1+4*25)
+	}
+}
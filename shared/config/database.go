@@ -6,6 +6,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
 
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
@@ -13,20 +17,35 @@ import (
 
 var _ IConfig = (*DatabaseConfig)(nil)
 
-// DatabaseConfig implements all configuration interfaces with PostgreSQL database-based storage
+// DatabaseConfig implements all configuration interfaces with PostgreSQL database-based storage.
+// Users, API keys, servers and subscriptions are cached and reloaded every
+// RefreshInterval; entries missing from the cache, e.g. a key created since the last
+// refresh, are read from the database. Settings are always read from the database.
 type DatabaseConfig struct {
 	logger             *zap.Logger
 	dbConnectionString string
+	db                 *sql.DB // Connection pool shared by all queries
+
+	mu    sync.RWMutex
+	cache *databaseCache // nil until loaded, or if caching is disabled
+
+	stopRefresh context.CancelFunc
+	refreshDone chan struct{}
 }
 
 // DatabaseConfigOptions contains options for configuring the DatabaseConfig
 type DatabaseConfigOptions struct {
-	// Optional parameters for database configuration
+	// RefreshInterval is how often the cached users, API keys, servers and subscriptions
+	// are reloaded. 0 disables the cache: every lookup queries the database.
+	RefreshInterval time.Duration
 }
 
+// DefaultDatabaseRefreshInterval is how often DatabaseConfig reloads its cache by default.
+const DefaultDatabaseRefreshInterval = 30 * time.Second
+
 // DefaultDatabaseConfigOptions returns the default options for DatabaseConfig
 func DefaultDatabaseConfigOptions() DatabaseConfigOptions {
-	return DatabaseConfigOptions{}
+	return DatabaseConfigOptions{RefreshInterval: DefaultDatabaseRefreshInterval}
 }
 
 // NewDatabaseConfig creates a new DatabaseConfig instance with default options
@@ -34,20 +53,46 @@ func NewDatabaseConfig(dbConnectionString string, logger *zap.Logger) (*Database
 	return NewDatabaseConfigWithOptions(dbConnectionString, logger, DefaultDatabaseConfigOptions())
 }
 
-// NewDatabaseConfigWithOptions creates a new DatabaseConfig instance with the specified options
+// NewDatabaseConfigWithOptions creates a new DatabaseConfig instance with the specified
+// options. With a RefreshInterval, the cache is loaded at once and then refreshed in the
+// background until Close; a database that cannot be reached yet is logged, not returned,
+// and lookups query it directly until a refresh succeeds.
 func NewDatabaseConfigWithOptions(dbConnectionString string, logger *zap.Logger, options DatabaseConfigOptions) (*DatabaseConfig, error) {
+	if options.RefreshInterval < 0 {
+		return nil, fmt.Errorf("invalid database refresh interval %s", options.RefreshInterval)
+	}
+	db, err := sql.Open("postgres", dbConnectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
 	// Create a new database config
 	config := &DatabaseConfig{
 		dbConnectionString: dbConnectionString,
 		logger:             logger,
+		db:                 db,
+	}
+
+	if options.RefreshInterval > 0 {
+		if err := config.Update(); err != nil {
+			logger.Error("Failed to load configuration from database, querying it until a refresh succeeds", zap.Error(err))
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		config.stopRefresh = cancel
+		config.refreshDone = make(chan struct{})
+		go config.refreshLoop(ctx, options.RefreshInterval)
 	}
 
 	return config, nil
 }
 
-// Close closes any resources held by the config
+// Close stops refreshing the cache and closes the connection pool
 func (c *DatabaseConfig) Close() error {
-	return nil
+	if c.stopRefresh != nil {
+		c.stopRefresh()
+		<-c.refreshDone
+	}
+	return c.db.Close()
 }
 
 // ServerConfig interface implementation
@@ -75,23 +120,22 @@ func (c *DatabaseConfig) AuthorizationType() (AuthorizationType, error) {
 
 // UsersConfig interface implementation
 
-// GetUserIDByKeyHash returns the user ID for the given key hash by querying the database
+// GetUserIDByKeyHash returns the user ID for the given key hash, querying the database
+// if it is not cached
 func (c *DatabaseConfig) GetUserIDByKeyHash(keyHash string) (string, error) {
 	if keyHash == "" {
 		return "", fmt.Errorf("token hash not found")
 	}
-
-	// Open a connection to the database
-	db, err := sql.Open("postgres", c.dbConnectionString)
-	if err != nil {
-		return "", fmt.Errorf("failed to connect to database: %w", err)
+	if cache := c.cached(); cache != nil {
+		if userID, ok := cache.keyUsers[keyHash]; ok {
+			return userID, nil
+		}
 	}
-	defer db.Close()
 
 	// Query to get user ID from the API key hash
 	query := `SELECT "userId" FROM "ApiKey" WHERE "keyHash" = $1 LIMIT 1`
 	var userID string
-	err = db.QueryRow(query, keyHash).Scan(&userID)
+	err := c.db.QueryRow(query, keyHash).Scan(&userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("token not found")
@@ -102,14 +146,19 @@ func (c *DatabaseConfig) GetUserIDByKeyHash(keyHash string) (string, error) {
 	return userID, nil
 }
 
+// GetUserIDByKey returns the user ID for the given plaintext key. The portal stores
+// SHA-256 key hashes, so the key is hashed with HashAPIKey and looked up.
+func (c *DatabaseConfig) GetUserIDByKey(key string) (string, error) {
+	return c.GetUserIDByKeyHash(HashAPIKey(key))
+}
+
 // GetUserParams returns the parameters for the given user ID
 func (c *DatabaseConfig) GetUserParams(userID string) (map[string]string, error) {
-	// Open a connection to the database
-	db, err := sql.Open("postgres", c.dbConnectionString)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	if cache := c.cached(); cache != nil {
+		if params, ok := cache.userParams[userID]; ok {
+			return maps.Clone(params), nil
+		}
 	}
-	defer db.Close()
 
 	// Query to get user parameters from the database
 	query := `SELECT name, status, role, company FROM "User" WHERE id = $1 LIMIT 1`
@@ -118,7 +167,7 @@ func (c *DatabaseConfig) GetUserParams(userID string) (map[string]string, error)
 	var role string
 	var company sql.NullString
 
-	err = db.QueryRow(query, userID).Scan(&name, &status, &role, &company)
+	err := c.db.QueryRow(query, userID).Scan(&name, &status, &role, &company)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return make(map[string]string), nil
@@ -147,12 +196,11 @@ func (c *DatabaseConfig) GetUserParams(userID string) (map[string]string, error)
 
 // GetUserSubscribes returns the server IDs that the user is subscribed to
 func (c *DatabaseConfig) GetUserSubscribes(userID string) ([]string, error) {
-	// Open a connection to the database
-	db, err := sql.Open("postgres", c.dbConnectionString)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	if cache := c.cached(); cache != nil {
+		if serverIDs, ok := cache.userSubscribes[userID]; ok {
+			return slices.Clone(serverIDs), nil
+		}
 	}
-	defer db.Close()
 
 	// Get user role first
 	userParams, err := c.GetUserParams(userID)
@@ -173,7 +221,7 @@ func (c *DatabaseConfig) GetUserSubscribes(userID string) ([]string, error) {
 	case "ADMIN", "SECURITY":
 		// Admins and security users get all servers
 		query = `SELECT id FROM "Server"`
-		rows, err = db.Query(query)
+		rows, err = c.db.Query(query)
 	default:
 		// Owners get servers they own + active subscriptions
 		query = `
@@ -186,7 +234,7 @@ func (c *DatabaseConfig) GetUserSubscribes(userID string) ([]string, error) {
 			AND "Subscription"."status" = 'ACTIVE'
 			AND "Server"."status" = 'ACTIVE'
 		`
-		rows, err = db.Query(query, userID)
+		rows, err = c.db.Query(query, userID)
 	}
 
 	if err != nil {
@@ -210,21 +258,37 @@ func (c *DatabaseConfig) GetUserSubscribes(userID string) ([]string, error) {
 	return serverIDs, nil
 }
 
+// GetUserDefaultBackend returns "" because the portal schema has no per-user default backend.
+// Users with a single subscription are still routed to it by the gateway.
+func (c *DatabaseConfig) GetUserDefaultBackend(userID string) (string, error) {
+	return "", nil
+}
+
+// GetUserRateLimit returns no limit because the portal schema has no per-user rate limit.
+func (c *DatabaseConfig) GetUserRateLimit(userID string) (RateLimit, error) {
+	return RateLimit{}, nil
+}
+
+// GetUserIDByCertIdentity returns "" because the portal schema has no client certificate
+// identities, so client certificates authenticate no user.
+func (c *DatabaseConfig) GetUserIDByCertIdentity(identity string) (string, error) {
+	return "", nil
+}
+
 // ServersConfig interface implementation
 
 // GetServer returns the URL for the given server ID
 func (c *DatabaseConfig) GetBackend(backendID string) (*Backend, error) {
-	// Open a connection to the database
-	db, err := sql.Open("postgres", c.dbConnectionString)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	if cache := c.cached(); cache != nil {
+		if backend, ok := cache.backends[backendID]; ok {
+			return backend, nil
+		}
 	}
-	defer db.Close()
 
 	// Query to get server details from the database
 	query := `SELECT "serverUrl" FROM "Server" WHERE id = $1 LIMIT 1`
 	var serverURL string
-	err = db.QueryRow(query, backendID).Scan(&serverURL)
+	err := c.db.QueryRow(query, backendID).Scan(&serverURL)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -240,6 +304,34 @@ func (c *DatabaseConfig) GetBackend(backendID string) (*Backend, error) {
 	}, nil
 }
 
+// BackendIDs returns the IDs of all servers in the database, sorted
+func (c *DatabaseConfig) BackendIDs() ([]string, error) {
+	if cache := c.cached(); cache != nil {
+		return slices.Clone(cache.backendIDs), nil
+	}
+
+	rows, err := c.db.Query(`SELECT id FROM "Server" ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan server id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// BackendHealthInterval returns how often backends are health checked from the
+// 'gateway_backend_health_interval' setting, a duration such as "30s" (0 if not set)
+func (c *DatabaseConfig) BackendHealthInterval() (time.Duration, error) {
+	return c.getSettingDuration("gateway_backend_health_interval")
+}
+
 func (c *DatabaseConfig) ServerName() (string, error) {
 	return c.getSettingString("gateway_server_name")
 }
@@ -255,17 +347,10 @@ func (c *DatabaseConfig) LogLevel() (string, error) {
 
 // DiscoveringHandlerPath returns the information handler path from settings
 func (c *DatabaseConfig) DiscoveringHandlerPath() (string, error) {
-	// Open a connection to the database
-	db, err := sql.Open("postgres", c.dbConnectionString)
-	if err != nil {
-		return "", fmt.Errorf("failed to connect to database: %w", err)
-	}
-	defer db.Close()
-
 	// Query to get info handler setting
 	query := `SELECT value FROM "Settings" WHERE key = 'path_for_discovering_handler'`
 	var valueStr string
-	err = db.QueryRow(query).Scan(&valueStr)
+	err := c.db.QueryRow(query).Scan(&valueStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil // Return empty string if setting doesn't exist
@@ -293,35 +378,574 @@ func (c *DatabaseConfig) FrontendAddressForProxy() (string, error) {
 	return c.getSettingString("url_how_gateway_proxy_connect_to_the_portal")
 }
 
-func (c *DatabaseConfig) Status(ctx context.Context) error {
-	db, err := sql.Open("postgres", c.dbConnectionString)
+// SSEMaxStreams returns the server-wide limit of concurrent SSE streams (0 if not set)
+func (c *DatabaseConfig) SSEMaxStreams() (int, error) {
+	value, err := c.getSettingJSON("gateway_sse_max_streams")
 	if err != nil {
-		c.logger.Error("Failed to connect to database", zap.Error(err))
-		return err
-	} else {
-		err = db.PingContext(ctx)
-		if err != nil {
-			c.logger.Error("Failed to ping database", zap.Error(err))
-			return err
+		if errors.Is(err, ErrNotFound) {
+			return 0, nil
 		}
-		db.Close()
+		c.logger.Error("Error reading gateway_sse_max_streams", zap.Error(err))
+		return 0, err
 	}
-	return nil
+	floatValue, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("setting 'gateway_sse_max_streams' value is not a number")
+	}
+	return int(floatValue), nil
 }
 
-// getSettingString retrieves a string value from the Settings table
-func (c *DatabaseConfig) getSettingString(key string) (string, error) {
-	// Open a connection to the database
-	db, err := sql.Open("postgres", c.dbConnectionString)
+// SSEAllowedOrigins returns the browser origins allowed to connect (empty if not set)
+func (c *DatabaseConfig) SSEAllowedOrigins() ([]string, error) {
+	value, err := c.getSettingJSON("gateway_sse_allowed_origins")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return []string{}, nil
+		}
+		c.logger.Error("Error reading gateway_sse_allowed_origins", zap.Error(err))
+		return []string{}, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return []string{}, fmt.Errorf("setting 'gateway_sse_allowed_origins' has invalid format, expected JSON array of strings")
+	}
+	origins := make([]string, 0, len(items))
+	for _, item := range items {
+		origin, ok := item.(string)
+		if !ok {
+			return []string{}, fmt.Errorf("setting 'gateway_sse_allowed_origins' has invalid format, expected JSON array of strings")
+		}
+		origins = append(origins, origin)
+	}
+	return origins, nil
+}
+
+// SanitizeInboundText reports whether control sequences are stripped from client text (false if not set)
+func (c *DatabaseConfig) SanitizeInboundText() (bool, error) {
+	val, err := c.getSettingBool("gateway_sanitize_inbound_text")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_sanitize_inbound_text", zap.Error(err))
+	}
+	return val, nil
+}
+
+// SanitizeOutboundText reports whether control sequences are stripped from text returned to clients (false if not set)
+func (c *DatabaseConfig) SanitizeOutboundText() (bool, error) {
+	val, err := c.getSettingBool("gateway_sanitize_outbound_text")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_sanitize_outbound_text", zap.Error(err))
+	}
+	return val, nil
+}
+
+// ToolsListDeadline returns how long tools/list waits for backends from the
+// 'gateway_tools_list_deadline' setting, a duration such as "2s" (0 if not set)
+func (c *DatabaseConfig) ToolsListDeadline() (time.Duration, error) {
+	return c.getSettingDuration("gateway_tools_list_deadline")
+}
+
+// SSEQueueSize returns how many streams may wait for a slot once the stream limit is reached (0 if not set)
+func (c *DatabaseConfig) SSEQueueSize() (int, error) {
+	return c.getSettingInt("gateway_sse_queue_size")
+}
+
+// SSEQueueWait returns how long a queued stream waits for a slot from the
+// 'gateway_sse_queue_wait' setting, a duration such as "2s" (0 if not set)
+func (c *DatabaseConfig) SSEQueueWait() (time.Duration, error) {
+	return c.getSettingDuration("gateway_sse_queue_wait")
+}
+
+// SSEKeepAlive returns the interval of keepalive comments on open SSE streams from the
+// 'gateway_sse_keepalive' setting, a duration such as "15s" (0 if not set)
+func (c *DatabaseConfig) SSEKeepAlive() (time.Duration, error) {
+	return c.getSettingDuration("gateway_sse_keepalive")
+}
+
+// SSEDrainTimeout returns how long open SSE streams are drained on shutdown from the
+// 'gateway_sse_drain_timeout' setting, a duration such as "15s" (0 if not set)
+func (c *DatabaseConfig) SSEDrainTimeout() (time.Duration, error) {
+	return c.getSettingDuration("gateway_sse_drain_timeout")
+}
+
+// CompressionMinSize returns the min size of compressed JSON responses from the
+// 'gateway_compression_min_size' setting, 0 (the default) if it is not set.
+func (c *DatabaseConfig) CompressionMinSize() (int, error) {
+	return c.getSettingInt("gateway_compression_min_size")
+}
+
+// getSettingDuration reads a setting holding a duration string such as "2s", 0 if it is not set.
+func (c *DatabaseConfig) getSettingDuration(key string) (time.Duration, error) {
+	value, err := c.getSettingJSON(key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, nil
+		}
+		c.logger.Error("Error reading "+key, zap.Error(err))
+		return 0, err
+	}
+	strValue, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("setting '%s' value is not a string", key)
+	}
+	if strValue == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(strValue)
+	if err != nil {
+		return 0, fmt.Errorf("setting '%s' value is not a duration: %w", key, err)
+	}
+	return duration, nil
+}
+
+// LogPrivacy returns the redaction level of user data in logs from the
+// 'gateway_log_privacy' setting ("none" if not set)
+func (c *DatabaseConfig) LogPrivacy() (string, error) {
+	value, err := c.getSettingJSON("gateway_log_privacy")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return LogPrivacyNone, nil
+		}
+		c.logger.Error("Error reading gateway_log_privacy", zap.Error(err))
+		return LogPrivacyNone, err
+	}
+	level, ok := value.(string)
+	if !ok {
+		return LogPrivacyNone, fmt.Errorf("setting 'gateway_log_privacy' value is not a string")
+	}
+	if err := ValidateLogPrivacy(level); err != nil {
+		return LogPrivacyNone, fmt.Errorf("setting 'gateway_log_privacy': %w", err)
+	}
+	return level, nil
+}
+
+// IDGenerator returns the scheme of generated session and task IDs from the
+// 'gateway_id_generator' setting ("random" if not set)
+func (c *DatabaseConfig) IDGenerator() (string, error) {
+	value, err := c.getSettingJSON("gateway_id_generator")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return IDGeneratorRandom, nil
+		}
+		c.logger.Error("Error reading gateway_id_generator", zap.Error(err))
+		return IDGeneratorRandom, err
+	}
+	name, ok := value.(string)
+	if !ok {
+		return IDGeneratorRandom, fmt.Errorf("setting 'gateway_id_generator' value is not a string")
+	}
+	if err := ValidateIDGenerator(name); err != nil {
+		return IDGeneratorRandom, fmt.Errorf("setting 'gateway_id_generator': %w", err)
+	}
+	return name, nil
+}
+
+// MethodsDeny returns the patterns of methods rejected for everyone from the
+// 'gateway_methods_deny' setting, a JSON array of strings (empty if not set)
+func (c *DatabaseConfig) MethodsDeny() ([]string, error) {
+	return c.getSettingMethodPatterns("gateway_methods_deny")
+}
+
+// MethodsAllow returns the patterns of methods accepted from the
+// 'gateway_methods_allow' setting, a JSON array of strings (empty accepts all not denied)
+func (c *DatabaseConfig) MethodsAllow() ([]string, error) {
+	return c.getSettingMethodPatterns("gateway_methods_allow")
+}
+
+// PrefixNames reports whether listed names are prefixed with the ID of their backend
+// from the 'gateway_prefix_names' setting (false if not set)
+func (c *DatabaseConfig) PrefixNames() (bool, error) {
+	val, err := c.getSettingBool("gateway_prefix_names")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_prefix_names", zap.Error(err))
+	}
+	return val, nil
+}
+
+// NameSeparator returns the separator of prefixed names from the
+// 'gateway_name_separator' setting (empty, for DefaultNameSeparator, if not set)
+func (c *DatabaseConfig) NameSeparator() (string, error) {
+	value, err := c.getSettingJSON("gateway_name_separator")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+		c.logger.Error("Error reading gateway_name_separator", zap.Error(err))
+		return "", err
+	}
+	separator, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("setting 'gateway_name_separator' value is not a string")
+	}
+	return separator, nil
+}
+
+// getSettingMethodPatterns reads a setting holding a JSON array of method patterns, empty if it is not set.
+func (c *DatabaseConfig) getSettingMethodPatterns(key string) ([]string, error) {
+	patterns, err := c.getSettingStrings(key)
+	if err != nil {
+		return []string{}, err
+	}
+	if err := ValidateMethodPatterns(patterns); err != nil {
+		return []string{}, fmt.Errorf("setting '%s': %w", key, err)
+	}
+	return patterns, nil
+}
+
+// MetricsVersionLabels reports whether relayed requests are counted by protocol versions (false if not set)
+func (c *DatabaseConfig) MetricsVersionLabels() (bool, error) {
+	val, err := c.getSettingBool("gateway_metrics_version_labels")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_metrics_version_labels", zap.Error(err))
+	}
+	return val, nil
+}
+
+// MetricsEnabled reports whether metrics are served (false if not set)
+func (c *DatabaseConfig) MetricsEnabled() (bool, error) {
+	val, err := c.getSettingBool("gateway_metrics_enabled")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_metrics_enabled", zap.Error(err))
+	}
+	return val, nil
+}
+
+// MetricsPath returns the path of the metrics endpoint (empty if not set)
+func (c *DatabaseConfig) MetricsPath() (string, error) {
+	value, err := c.getSettingJSON("gateway_metrics_path")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+		c.logger.Error("Error reading gateway_metrics_path", zap.Error(err))
+		return "", err
+	}
+	path, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("setting 'gateway_metrics_path' value is not a string")
+	}
+	if err := ValidateMetricsPath(path); err != nil {
+		return "", fmt.Errorf("setting 'gateway_metrics_path': %w", err)
+	}
+	return path, nil
+}
+
+// TracingEndpoint returns the base URL of the OTLP/HTTP collector from the
+// 'gateway_tracing_endpoint' setting (empty, disabling tracing, if not set)
+func (c *DatabaseConfig) TracingEndpoint() (string, error) {
+	value, err := c.getSettingJSON("gateway_tracing_endpoint")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+		c.logger.Error("Error reading gateway_tracing_endpoint", zap.Error(err))
+		return "", err
+	}
+	endpoint, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("setting 'gateway_tracing_endpoint' value is not a string")
+	}
+	return endpoint, nil
+}
+
+// TracingSampleRatio returns the share of the traces started by the gateway that are
+// sampled from the 'gateway_tracing_sample_ratio' setting (DefaultTracingSampleRatio if not set)
+func (c *DatabaseConfig) TracingSampleRatio() (float64, error) {
+	value, err := c.getSettingJSON("gateway_tracing_sample_ratio")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return DefaultTracingSampleRatio, nil
+		}
+		c.logger.Error("Error reading gateway_tracing_sample_ratio", zap.Error(err))
+		return DefaultTracingSampleRatio, err
+	}
+	ratio, ok := value.(float64)
+	if !ok {
+		return DefaultTracingSampleRatio, fmt.Errorf("setting 'gateway_tracing_sample_ratio' value is not a number")
+	}
+	if err := ValidateTracingSampleRatio(ratio); err != nil {
+		return DefaultTracingSampleRatio, fmt.Errorf("setting 'gateway_tracing_sample_ratio': %w", err)
+	}
+	return ratio, nil
+}
+
+// AccessLogEnabled reports whether requests and SSE streams are logged in the access log (false if not set)
+func (c *DatabaseConfig) AccessLogEnabled() (bool, error) {
+	val, err := c.getSettingBool("gateway_access_log_enabled")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_access_log_enabled", zap.Error(err))
+	}
+	return val, nil
+}
+
+// AccessLogLevel returns the level of the access log entries from the
+// 'gateway_access_log_level' setting (empty, for DefaultAccessLogLevel, if not set)
+func (c *DatabaseConfig) AccessLogLevel() (string, error) {
+	value, err := c.getSettingJSON("gateway_access_log_level")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+		c.logger.Error("Error reading gateway_access_log_level", zap.Error(err))
+		return "", err
+	}
+	level, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("setting 'gateway_access_log_level' value is not a string")
+	}
+	if err := ValidateAccessLogLevel(level); err != nil {
+		return "", fmt.Errorf("setting 'gateway_access_log_level': %w", err)
+	}
+	return level, nil
+}
+
+// AccessLogFields returns the fields of the access log entries from the
+// 'gateway_access_log_fields' setting, a JSON array of strings (empty, for all, if not set)
+func (c *DatabaseConfig) AccessLogFields() ([]string, error) {
+	fields, err := c.getSettingStrings("gateway_access_log_fields")
+	if err != nil {
+		return []string{}, err
+	}
+	if err := ValidateAccessLogFields(fields); err != nil {
+		return []string{}, fmt.Errorf("setting 'gateway_access_log_fields': %w", err)
+	}
+	return fields, nil
+}
+
+// MetricsLatencyBuckets returns the latency histogram bounds from the
+// 'gateway_metrics_latency_buckets' setting, a JSON array of seconds (empty if not set)
+func (c *DatabaseConfig) MetricsLatencyBuckets() ([]float64, error) {
+	value, err := c.getSettingJSON("gateway_metrics_latency_buckets")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		c.logger.Error("Error reading gateway_metrics_latency_buckets", zap.Error(err))
+		return nil, err
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("setting 'gateway_metrics_latency_buckets' value is not an array")
+	}
+	buckets := make([]float64, 0, len(values))
+	for _, v := range values {
+		bound, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("setting 'gateway_metrics_latency_buckets' contains a non-numeric value")
+		}
+		buckets = append(buckets, bound)
+	}
+	if err := ValidateLatencyBuckets(buckets); err != nil {
+		return nil, fmt.Errorf("setting 'gateway_metrics_latency_buckets': %w", err)
+	}
+	return buckets, nil
+}
+
+// A2AAgentNames returns the names of the A2A agents stored in the 'gateway_a2a_agents' setting
+func (c *DatabaseConfig) A2AAgentNames() ([]string, error) {
+	agents, err := c.getA2AAgents()
+	if err != nil {
+		return nil, err
+	}
+	return sortedA2AAgentNames(agents), nil
+}
+
+// GetA2ACardBaseInfo returns the card info of the A2A agent from the 'gateway_a2a_agents' setting
+func (c *DatabaseConfig) GetA2ACardBaseInfo(agentName string) (A2ACardBaseInfo, error) {
+	agents, err := c.getA2AAgents()
+	if err != nil {
+		return A2ACardBaseInfo{}, err
+	}
+	info, exists := agents[agentName]
+	if !exists {
+		return A2ACardBaseInfo{}, ErrNotFound
+	}
+	return info, nil
+}
+
+// A2AArtifactChecksums reports whether a sha256 checksum is added to produced artifacts (false if not set)
+func (c *DatabaseConfig) A2AArtifactChecksums() (bool, error) {
+	val, err := c.getSettingBool("gateway_a2a_artifact_checksums")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_a2a_artifact_checksums", zap.Error(err))
+	}
+	return val, nil
+}
+
+// A2ADetectArtifactMimeTypes reports whether missing MIME types of file artifacts are detected (false if not set)
+func (c *DatabaseConfig) A2ADetectArtifactMimeTypes() (bool, error) {
+	val, err := c.getSettingBool("gateway_a2a_detect_mime_types")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_a2a_detect_mime_types", zap.Error(err))
+	}
+	return val, nil
+}
+
+// A2AMaxSessionTasks returns the number of tasks kept per session before terminal ones are evicted (0 if not set)
+func (c *DatabaseConfig) A2AMaxSessionTasks() (int, error) {
+	return c.getSettingInt("gateway_a2a_max_session_tasks")
+}
+
+// A2ASessionTaskHardLimit returns the number of tasks per session at which new tasks are rejected (0 if not set)
+func (c *DatabaseConfig) A2ASessionTaskHardLimit() (int, error) {
+	return c.getSettingInt("gateway_a2a_session_task_hard_limit")
+}
+
+// A2AMaxConcurrentUserTasks returns the number of running tasks per user at which new tasks are rejected (0 if not set)
+func (c *DatabaseConfig) A2AMaxConcurrentUserTasks() (int, error) {
+	return c.getSettingInt("gateway_a2a_max_concurrent_user_tasks")
+}
+
+// A2ATaskIDScope returns the scope in which A2A task IDs are unique from the
+// 'gateway_a2a_task_id_scope' setting ("session" if not set)
+func (c *DatabaseConfig) A2ATaskIDScope() (string, error) {
+	value, err := c.getSettingJSON("gateway_a2a_task_id_scope")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return A2ATaskIDScopeSession, nil
+		}
+		c.logger.Error("Error reading gateway_a2a_task_id_scope", zap.Error(err))
+		return A2ATaskIDScopeSession, err
+	}
+	scope, ok := value.(string)
+	if !ok {
+		return A2ATaskIDScopeSession, fmt.Errorf("setting 'gateway_a2a_task_id_scope' value is not a string")
+	}
+	if err := ValidateA2ATaskIDScope(scope); err != nil {
+		return A2ATaskIDScopeSession, fmt.Errorf("setting 'gateway_a2a_task_id_scope': %w", err)
+	}
+	return scope, nil
+}
+
+// SSLReloadInterval returns how often the certificate files are checked for changes from
+// the 'gateway_ssl_reload_interval' setting, a duration such as "1m" (0 if not set)
+func (c *DatabaseConfig) SSLReloadInterval() (time.Duration, error) {
+	return c.getSettingDuration("gateway_ssl_reload_interval")
+}
+
+// getSettingStrings reads a setting holding a JSON array of strings, empty if it is not set.
+func (c *DatabaseConfig) getSettingStrings(key string) ([]string, error) {
+	value, err := c.getSettingJSON(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to database: %w", err)
+		if errors.Is(err, ErrNotFound) {
+			return []string{}, nil
+		}
+		c.logger.Error("Error reading "+key, zap.Error(err))
+		return []string{}, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return []string{}, fmt.Errorf("setting '%s' has invalid format, expected JSON array of strings", key)
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return []string{}, fmt.Errorf("setting '%s' has invalid format, expected JSON array of strings", key)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// getSettingInt reads a numeric setting, 0 if it is not set.
+func (c *DatabaseConfig) getSettingInt(key string) (int, error) {
+	value, err := c.getSettingJSON(key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, nil
+		}
+		c.logger.Error("Error reading "+key, zap.Error(err))
+		return 0, err
+	}
+	floatValue, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("setting '%s' value is not a number", key)
+	}
+	return int(floatValue), nil
+}
+
+// getA2AAgents reads the 'gateway_a2a_agents' setting, a JSON object of agent name to
+// {"path", "description", "url", "version", "documentationUrl", "providerOrganization", "providerUrl",
+// "streaming", "pushNotifications", "stateTransitionHistory", "skills"}, each skill being
+// {"id", "name", "description", "tags", "examples", "inputModes", "outputModes"}.
+func (c *DatabaseConfig) getA2AAgents() (map[string]A2ACardBaseInfo, error) {
+	value, err := c.getSettingJSON("gateway_a2a_agents")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return map[string]A2ACardBaseInfo{}, nil
+		}
+		c.logger.Error("Error reading gateway_a2a_agents", zap.Error(err))
+		return nil, err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode setting 'gateway_a2a_agents': %w", err)
+	}
+	var stored map[string]struct {
+		Path                   string `json:"path"`
+		Description            string `json:"description"`
+		URL                    string `json:"url"`
+		Version                string `json:"version"`
+		DocumentationURL       string `json:"documentationUrl"`
+		ProviderOrganization   string `json:"providerOrganization"`
+		ProviderURL            string `json:"providerUrl"`
+		Streaming              bool   `json:"streaming"`
+		PushNotifications      bool   `json:"pushNotifications"`
+		StateTransitionHistory bool   `json:"stateTransitionHistory"`
+		Skills                 []struct {
+			ID          string   `json:"id"`
+			Name        string   `json:"name"`
+			Description string   `json:"description"`
+			Tags        []string `json:"tags"`
+			Examples    []string `json:"examples"`
+			InputModes  []string `json:"inputModes"`
+			OutputModes []string `json:"outputModes"`
+		} `json:"skills"`
 	}
-	defer db.Close()
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		return nil, fmt.Errorf("setting 'gateway_a2a_agents' has invalid format: %w", err)
+	}
+	agents := make(map[string]A2ACardBaseInfo, len(stored))
+	for name, agent := range stored {
+		var skills []A2ASkill
+		for _, skill := range agent.Skills {
+			skills = append(skills, A2ASkill(skill))
+		}
+		agents[name] = A2ACardBaseInfo{
+			Path:                   agent.Path,
+			Description:            agent.Description,
+			URL:                    agent.URL,
+			Version:                agent.Version,
+			DocumentationURL:       agent.DocumentationURL,
+			ProviderOrganization:   agent.ProviderOrganization,
+			ProviderURL:            agent.ProviderURL,
+			Streaming:              agent.Streaming,
+			PushNotifications:      agent.PushNotifications,
+			StateTransitionHistory: agent.StateTransitionHistory,
+			Skills:                 skills,
+		}
+	}
+	if err := ValidateA2AAgents(agents); err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// Status pings the database
+func (c *DatabaseConfig) Status(ctx context.Context) error {
+	if err := c.db.PingContext(ctx); err != nil {
+		c.logger.Error("Failed to ping database", zap.Error(err))
+		return err
+	}
+	return nil
+}
 
+// getSettingString retrieves a string value from the Settings table
+func (c *DatabaseConfig) getSettingString(key string) (string, error) {
 	// Query to get the setting
 	query := `SELECT value FROM "Settings" WHERE key = $1 LIMIT 1`
 	var valueStr string
-	err = db.QueryRow(query, key).Scan(&valueStr)
+	err := c.db.QueryRow(query, key).Scan(&valueStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", fmt.Errorf("setting not found: %s", key)
@@ -441,21 +1065,57 @@ func (c *DatabaseConfig) SSLAcmeCacheDir() (string, error) {
 	return val, nil
 }
 
-// --- Helper functions to get typed settings ---
+func (c *DatabaseConfig) SSLClientCAFile() (string, error) {
+	val, err := c.getSettingString("gateway_ssl_client_ca_file")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_ssl_client_ca_file", zap.Error(err))
+	}
+	return val, nil // Return "" on error or not found
+}
 
-// getSettingJSON retrieves a raw JSON value from the Settings table
-func (c *DatabaseConfig) getSettingJSON(key string) (interface{}, error) {
-	db, err := sql.Open("postgres", c.dbConnectionString)
+func (c *DatabaseConfig) SSLOCSPStapling() (bool, error) {
+	val, err := c.getSettingBool("gateway_ssl_ocsp_stapling")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_ssl_ocsp_stapling", zap.Error(err))
+	}
+	return val, nil // Disabled on error or not found
+}
+
+// SSLMinVersion returns the 'gateway_ssl_min_version' setting, "1.2" or "1.3" ("" if not set)
+func (c *DatabaseConfig) SSLMinVersion() (string, error) {
+	val, err := c.getSettingString("gateway_ssl_min_version")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.logger.Error("Error reading gateway_ssl_min_version", zap.Error(err))
+		return "", err
+	}
+	if _, err := TLSMinVersion(val); err != nil {
+		return "", fmt.Errorf("setting 'gateway_ssl_min_version': %w", err)
+	}
+	return val, nil
+}
+
+// SSLCipherSuites returns the names of the cipher suites from the 'gateway_ssl_cipher_suites'
+// setting, a JSON array of strings (empty for the defaults)
+func (c *DatabaseConfig) SSLCipherSuites() ([]string, error) {
+	names, err := c.getSettingStrings("gateway_ssl_cipher_suites")
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return []string{}, err
 	}
-	defer db.Close()
+	if _, err := TLSCipherSuites(names); err != nil {
+		return []string{}, fmt.Errorf("setting 'gateway_ssl_cipher_suites': %w", err)
+	}
+	return names, nil
+}
 
+// --- Helper functions to get typed settings ---
+
+// getSettingJSON retrieves a raw JSON value from the Settings table
+func (c *DatabaseConfig) getSettingJSON(key string) (interface{}, error) {
 	query := `SELECT value FROM "Settings" WHERE key = $1 LIMIT 1`
 	var valueStr string
 
 	ctx := context.Background() // Replace with c.ctx if watcher is active
-	err = db.QueryRowContext(ctx, query, key).Scan(&valueStr)
+	err := c.db.QueryRowContext(ctx, query, key).Scan(&valueStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound // Use specific error for not found
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// databaseCache holds the users, API keys, servers and subscriptions of the database as
+// of its last load.
+type databaseCache struct {
+	keyUsers       map[string]string            // keyHash -> userID
+	userParams     map[string]map[string]string // userID -> params, as GetUserParams returns them
+	userSubscribes map[string][]string          // userID -> serverIDs, an entry for every user
+	backends       map[string]*Backend          // serverID -> Backend
+	backendIDs     []string                     // Sorted
+}
+
+// Update reloads the cached users, API keys, servers and subscriptions from the
+// database. The previous cache stays in use if loading fails.
+func (c *DatabaseConfig) Update() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cache, err := c.loadCache(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.cache = cache
+	c.mu.Unlock()
+	c.logger.Debug("Reloaded configuration from database",
+		zap.Int("users", len(cache.userParams)),
+		zap.Int("keys", len(cache.keyUsers)),
+		zap.Int("servers", len(cache.backends)))
+	return nil
+}
+
+// refreshLoop calls Update every interval until ctx is done.
+func (c *DatabaseConfig) refreshLoop(ctx context.Context, interval time.Duration) {
+	defer close(c.refreshDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Update(); err != nil {
+				c.logger.Error("Failed to refresh configuration from database, keeping the previous one", zap.Error(err))
+			}
+		}
+	}
+}
+
+// cached returns the cache, nil if it is disabled or not loaded yet.
+func (c *DatabaseConfig) cached() *databaseCache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cache
+}
+
+// loadCache reads the users, API keys, servers and subscriptions from the database.
+func (c *DatabaseConfig) loadCache(ctx context.Context) (*databaseCache, error) {
+	cache := &databaseCache{
+		keyUsers:       make(map[string]string),
+		userParams:     make(map[string]map[string]string),
+		userSubscribes: make(map[string][]string),
+		backends:       make(map[string]*Backend),
+	}
+
+	err := c.queryRows(ctx, `SELECT "keyHash", "userId" FROM "ApiKey"`, func(rows *sql.Rows) error {
+		var keyHash, userID string
+		if err := rows.Scan(&keyHash, &userID); err != nil {
+			return err
+		}
+		cache.keyUsers[keyHash] = userID
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+
+	roles := make(map[string]string)
+	err = c.queryRows(ctx, `SELECT id, name, status, role, company FROM "User"`, func(rows *sql.Rows) error {
+		var userID, status, role string
+		var name, company sql.NullString
+		if err := rows.Scan(&userID, &name, &status, &role, &company); err != nil {
+			return err
+		}
+		params := map[string]string{"status": status, "role": role}
+		if name.Valid {
+			params["name"] = name.String
+		}
+		if company.Valid {
+			params["company"] = company.String
+		}
+		cache.userParams[userID] = params
+		roles[userID] = role
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	err = c.queryRows(ctx, `SELECT id, "serverUrl" FROM "Server"`, func(rows *sql.Rows) error {
+		var serverID, serverURL string
+		if err := rows.Scan(&serverID, &serverURL); err != nil {
+			return err
+		}
+		cache.backends[serverID] = &Backend{URL: serverURL}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load servers: %w", err)
+	}
+	cache.backendIDs = slices.Sorted(maps.Keys(cache.backends))
+
+	// The same servers as GetUserSubscribes queries for each user
+	subscribes := make(map[string][]string)
+	err = c.queryRows(ctx, `
+		SELECT "userId", "serverId" FROM "ServerOwner"
+		UNION
+		SELECT "Subscription"."userId", "Subscription"."serverId" FROM "Subscription"
+		JOIN "Server" ON "Subscription"."serverId" = "Server"."id"
+		WHERE "Subscription"."status" = 'ACTIVE'
+		AND "Server"."status" = 'ACTIVE'
+	`, func(rows *sql.Rows) error {
+		var userID, serverID string
+		if err := rows.Scan(&userID, &serverID); err != nil {
+			return err
+		}
+		subscribes[userID] = append(subscribes[userID], serverID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	for userID, role := range roles {
+		switch role {
+		case "ADMIN", "SECURITY":
+			// Admins and security users get all servers
+			cache.userSubscribes[userID] = slices.Clone(cache.backendIDs)
+		default:
+			serverIDs := subscribes[userID]
+			slices.Sort(serverIDs)
+			cache.userSubscribes[userID] = serverIDs
+		}
+	}
+
+	return cache, nil
+}
+
+// queryRows runs query and calls scan for each row.
+func (c *DatabaseConfig) queryRows(ctx context.Context, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
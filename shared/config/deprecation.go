@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Deprecation describes a YAML field that is still accepted but will be removed.
+type Deprecation struct {
+	// Path of the field, with "*" matching any key of a mapping, e.g. "users.*.keys"
+	Path string
+	// Replacement describes what to use instead, e.g. "users.*.labeled_keys"
+	Replacement string
+}
+
+// Deprecations lists the deprecated YAML fields. Using one of them is reported by Lint and
+// logged as a warning when the configuration is loaded, without failing. Add an entry
+// when deprecating a field and remove it together with the field.
+var Deprecations = []Deprecation{
+	{Path: "backends.*.breaker.threshold", Replacement: "backends.*.breaker.failure_threshold"},
+	{Path: "backends.*.breaker.cooldown", Replacement: "backends.*.breaker.open_duration"},
+}
+
+// LintIssue is a problem of a configuration that does not prevent loading it.
+type LintIssue struct {
+	Path    string // Field the issue is about, e.g. "users.alice.keys"
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return i.Path + ": " + i.Message
+}
+
+// Lint checks a YAML configuration for deprecated fields.
+func Lint(data []byte) ([]LintIssue, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var issues []LintIssue
+	for _, deprecation := range Deprecations {
+		for _, path := range findPaths(&root, strings.Split(deprecation.Path, "."), nil) {
+			issues = append(issues, LintIssue{
+				Path:    path,
+				Message: fmt.Sprintf("deprecated, use %s instead", deprecation.Replacement),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// findPaths returns the paths of the fields of node matching the pattern segments.
+func findPaths(node *yaml.Node, pattern []string, prefix []string) []string {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return findPaths(node.Content[0], pattern, prefix)
+	}
+	if len(pattern) == 0 {
+		return []string{strings.Join(prefix, ".")}
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var paths []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if pattern[0] != "*" && pattern[0] != key {
+			continue
+		}
+		path := append(append([]string(nil), prefix...), key)
+		paths = append(paths, findPaths(node.Content[i+1], pattern[1:], path)...)
+	}
+	return paths
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// withDeprecations replaces the registry for the duration of the test.
+func withDeprecations(t *testing.T, deprecations ...Deprecation) {
+	saved := Deprecations
+	Deprecations = deprecations
+	t.Cleanup(func() { Deprecations = saved })
+}
+
+func TestLintReportsDeprecatedFields(t *testing.T) {
+	withDeprecations(t,
+		Deprecation{Path: "users.*.keys", Replacement: "users.*.labeled_keys"},
+		Deprecation{Path: "server.old_name", Replacement: "server.name"},
+	)
+	issues, err := Lint([]byte("users:\n  alice:\n    keys: [k1]\n  bob:\n    subscribes: [b]\nserver:\n  name: s\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Path != "users.alice.keys" || issues[0].Message != "deprecated, use users.*.labeled_keys instead" {
+		t.Fatalf("Expected one issue for users.alice.keys, got %v", issues)
+	}
+}
+
+func TestLoadingDeprecatedFieldWarns(t *testing.T) {
+	withDeprecations(t, Deprecation{Path: "users.*.keys", Replacement: "users.*.labeled_keys"})
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("users:\n  alice:\n    keys: [k1]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zapcore.WarnLevel)
+
+	cfg, err := NewYamlConfig(path, zap.New(core))
+	if err != nil {
+		t.Fatalf("Expected a deprecated field not to fail loading, got %v", err)
+	}
+	warnings := logs.FilterMessage("Configuration uses a deprecated field").All()
+	if len(warnings) != 1 || warnings[0].ContextMap()["field"] != "users.alice.keys" {
+		t.Fatalf("Expected a warning naming users.alice.keys, got %v", logs.All())
+	}
+	if issues := cfg.Lint(); len(issues) != 1 || issues[0].String() != "users.alice.keys: deprecated, use users.*.labeled_keys instead" {
+		t.Fatalf("Expected the issue in the lint output, got %v", issues)
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExpandEnv replaces the placeholders in the string values of a YAML document with
+// environment variables looked up with lookup, e.g. os.LookupEnv:
+//   - ${VAR} is replaced with the value of VAR, which must be set;
+//   - ${VAR:-default} is replaced with the value of VAR, or default if it is unset or empty;
+//   - $$ is replaced with a single $, so values can contain "${" literally.
+//
+// Any other $ is kept. Mapping keys and comments are left alone. A variable that is not
+// set and has no default fails with an error naming the key of the value.
+func ExpandEnv(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	if !bytes.Contains(data, []byte("$")) {
+		return data, nil
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	changed, err := expandNode(&root, nil, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return data, nil
+	}
+	return yaml.Marshal(&root)
+}
+
+// expandNode expands the placeholders in the scalar values below node, whose key path is
+// path, and reports whether any value changed.
+func expandNode(node *yaml.Node, path []string, lookup func(string) (string, bool)) (bool, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		changed := false
+		for _, child := range node.Content {
+			c, err := expandNode(child, path, lookup)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+		return changed, nil
+	case yaml.MappingNode:
+		changed := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			c, err := expandNode(node.Content[i+1], append(path, node.Content[i].Value), lookup)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+		return changed, nil
+	case yaml.SequenceNode:
+		changed := false
+		for i, child := range node.Content {
+			c, err := expandNode(child, append(path, strconv.Itoa(i)), lookup)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+		return changed, nil
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return false, nil
+		}
+		value, err := expandValue(node.Value, lookup)
+		if err != nil {
+			return false, fmt.Errorf("config key '%s': %w", strings.Join(path, "."), err)
+		}
+		if value == node.Value {
+			return false, nil
+		}
+		node.Value = value
+		if node.Style == 0 && node.Tag == "!!str" {
+			node.Tag = "" // Resolve the type of an unquoted value from its expansion, e.g. a port
+		}
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// expandValue expands the placeholders of a single value.
+func expandValue(value string, lookup func(string) (string, bool)) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			out.WriteByte(value[i])
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			out.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated placeholder in %q", value)
+			}
+			placeholder := value[i+2 : i+2+end]
+			name, def, hasDefault := strings.Cut(placeholder, ":-")
+			if !validEnvName(name) {
+				return "", fmt.Errorf("invalid environment variable name in ${%s}", placeholder)
+			}
+			env, ok := lookup(name)
+			switch {
+			case ok && (env != "" || !hasDefault):
+				out.WriteString(env)
+			case hasDefault:
+				out.WriteString(def)
+			default:
+				return "", fmt.Errorf("environment variable %s is not set and has no default", name)
+			}
+			i += 2 + end
+		default:
+			out.WriteByte('$')
+		}
+	}
+	return out.String(), nil
+}
+
+// validEnvName reports whether name is a valid environment variable name.
+func validEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
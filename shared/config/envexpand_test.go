@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// writeYaml writes a configuration file and returns its path.
+func writeYaml(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUpdateExpandsEnvironmentVariables(t *testing.T) {
+	t.Setenv("GW_BACKEND_HOST", "backend.internal")
+	t.Setenv("GW_BEARER", "s3cret")
+	t.Setenv("GW_MAX_STREAMS", "12")
+	t.Setenv("GW_EMPTY", "")
+	path := writeYaml(t, `# Values like ${UNSET} in comments are left alone
+server:
+  sse:
+    max_streams: ${GW_MAX_STREAMS}
+  ssl:
+    acme_email: ${GW_ACME_EMAIL:-ops@example.com}
+    cert_file: "${GW_EMPTY:-/etc/certs/gw.pem}"
+backends:
+  b1:
+    url: "http://${GW_BACKEND_HOST}:8080/sse"
+    bearer: ${GW_BEARER}
+  b2:
+    url: http://b2/sse
+    bearer: "pa$$word${GW_EMPTY}$1"
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	b1, err := cfg.GetBackend("b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b1.URL != "http://backend.internal:8080/sse" || b1.Bearer != "s3cret" {
+		t.Errorf("Backend b1 = %q, %q", b1.URL, b1.Bearer)
+	}
+	b2, _ := cfg.GetBackend("b2")
+	if b2.Bearer != "pa$word$1" {
+		t.Errorf("Escaped bearer = %q, want pa$word$1", b2.Bearer)
+	}
+	if max, _ := cfg.SSEMaxStreams(); max != 12 {
+		t.Errorf("SSEMaxStreams = %d, want 12", max)
+	}
+	if email, _ := cfg.SSLAcmeEmail(); email != "ops@example.com" {
+		t.Errorf("SSLAcmeEmail = %q, want the default", email)
+	}
+	if certFile, _ := cfg.SSLCertFile(); certFile != "/etc/certs/gw.pem" {
+		t.Errorf("SSLCertFile = %q, want the default of the empty variable", certFile)
+	}
+}
+
+func TestUpdateFailsOnMissingEnvironmentVariable(t *testing.T) {
+	path := writeYaml(t, "backends:\n  b1:\n    url: http://b1/sse\n    bearer: ${GW_SURELY_UNSET_TOKEN}\n")
+	_, err := NewYamlConfig(path, zap.NewNop())
+	if err == nil {
+		t.Fatal("Expected loading to fail")
+	}
+	for _, want := range []string{"backends.b1.bearer", "GW_SURELY_UNSET_TOKEN"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error %q does not name %s", err, want)
+		}
+	}
+}
+
+func TestExpandEnvErrors(t *testing.T) {
+	lookup := func(string) (string, bool) { return "", false }
+	for _, value := range []string{"${UNTERMINATED", "${1BAD}", "${}"} {
+		if _, err := ExpandEnv([]byte("users:\n  - keys: ['"+value+"']\n"), lookup); err == nil || !strings.Contains(err.Error(), "users.0.keys.0") {
+			t.Errorf("ExpandEnv(%q) = %v, want an error naming the key", value, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration file formats, detected from the file extension by FileFormat.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+// FileFormat returns the format of the configuration file at path: FormatJSON for
+// .json files, FormatTOML for .toml files and FormatYAML for anything else, including
+// .yaml and .yml files.
+func FileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatYAML
+	}
+}
+
+// readConfigFile reads the configuration file at path and returns it as a YAML
+// document. JSON and TOML files are converted, so that environment variable expansion,
+// linting and decoding work the same for every format, with the keys of the YAML schema.
+func readConfigFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return toYAML(data, FileFormat(path))
+}
+
+// toYAML converts a configuration document of the given format to YAML.
+func toYAML(data []byte, format string) ([]byte, error) {
+	var doc map[string]any
+	switch format {
+	case FormatYAML:
+		return data, nil
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown configuration format '%s'", format)
+	}
+	if doc == nil {
+		return nil, nil
+	}
+	return yaml.Marshal(jsonNumbers(doc))
+}
+
+// jsonNumbers replaces the json.Number values below v with int64 or float64 values, so
+// that integers are not written as floats or strings in YAML.
+func jsonNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = jsonNumbers(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = jsonNumbers(value)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return v
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// Equivalent configurations in each supported format.
+var formatFixtures = map[string]string{
+	"config.yaml": `server:
+  authorization: marked_methods
+  sse:
+    max_streams: 7
+backends:
+  search:
+    url: https://search.example.com/sse
+    bearer: ${GW_FORMAT_BEARER}
+    timeout: 5s
+    replicas: [https://search-2.example.com/sse]
+`,
+	"config.json": `{
+	"server": {"authorization": "marked_methods", "sse": {"max_streams": 7}},
+	"backends": {
+		"search": {
+			"url": "https://search.example.com/sse",
+			"bearer": "${GW_FORMAT_BEARER}",
+			"timeout": "5s",
+			"replicas": ["https://search-2.example.com/sse"]
+		}
+	}
+}
+`,
+	"config.toml": `[server]
+authorization = "marked_methods"
+
+[server.sse]
+max_streams = 7
+
+[backends.search]
+url = "https://search.example.com/sse"
+bearer = "${GW_FORMAT_BEARER}"
+timeout = "5s"
+replicas = ["https://search-2.example.com/sse"]
+`,
+}
+
+func TestNewConfigLoadsEveryFormat(t *testing.T) {
+	t.Setenv("GW_FORMAT_BEARER", "s3cret")
+	dir := t.TempDir()
+
+	var want *Backend
+	for _, name := range []string{"config.yaml", "config.json", "config.toml"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(formatFixtures[name]), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := NewConfig(path, zap.NewNop())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		authType, err := cfg.AuthorizationType()
+		if err != nil || authType != NotAuthorizedToMarkedMethods {
+			t.Errorf("%s: AuthorizationType() = %v, %v", name, authType, err)
+		}
+		if streams, err := cfg.SSEMaxStreams(); err != nil || streams != 7 {
+			t.Errorf("%s: SSEMaxStreams() = %d, %v", name, streams, err)
+		}
+		backend, err := cfg.GetBackend("search")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if backend.Bearer != "s3cret" {
+			t.Errorf("%s: Bearer = %q, want the expanded variable", name, backend.Bearer)
+		}
+		if want == nil {
+			want = backend
+		} else if !reflect.DeepEqual(backend, want) {
+			t.Errorf("%s: GetBackend() = %+v, want %+v as loaded from YAML", name, backend, want)
+		}
+	}
+}
+
+func TestFileFormat(t *testing.T) {
+	for path, want := range map[string]string{
+		"config.yaml":     FormatYAML,
+		"config.yml":      FormatYAML,
+		"/etc/gw/gateway": FormatYAML,
+		"config.JSON":     FormatJSON,
+		"dir.d/cfg.toml":  FormatTOML,
+	} {
+		if got := FileFormat(path); got != want {
+			t.Errorf("FileFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
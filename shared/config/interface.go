@@ -4,6 +4,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
 // AuthorizationType represents different authorization strategies
@@ -16,11 +22,231 @@ const (
 	NotAuthorizedToMarkedMethods
 	// NotAuthorizedEverywhere allows all requests without authentication
 	NotAuthorizedEverywhere
+	// AuthorizedByClientCert requires a TLS client certificate verified against the CA
+	// bundle of SSLClientCAFile, whose identity is mapped to a user (see
+	// GetUserIDByCertIdentity). API keys are not accepted.
+	AuthorizedByClientCert
 )
 
 type Backend struct {
 	URL    string
 	Bearer string
+	// Command runs the backend as a child process speaking MCP over stdio instead of
+	// connecting to URL: the executable followed by its arguments. A process is started
+	// for each backend session and restarted when it exits. Env lists "KEY=value"
+	// variables added to the gateway's environment for it, Dir is its working directory.
+	Command []string
+	Env     []string
+	Dir     string
+	// Passthrough relays the backend's result bytes to the client unchanged
+	// (unknown fields and key order are kept). It disables any response
+	// transforms and caching for this backend.
+	Passthrough bool
+	// Timeout bounds each request to the backend, e.g. 2 minutes for a slow LLM backend.
+	// If 0, tools/call requests use DefaultBackendToolTimeout and all others
+	// DefaultBackendRequestTimeout.
+	Timeout time.Duration
+	// RetryCodes lists JSON-RPC error codes the backend returns for transient failures
+	// (e.g. "service initializing"). Requests failing with one of them are retried up to
+	// RetryAttempts times, waiting RetryBackoff before the first retry and doubling the
+	// wait after each one. Other errors are returned immediately.
+	RetryCodes    []int
+	RetryAttempts int           // DefaultBackendRetryAttempts if 0
+	RetryBackoff  time.Duration // DefaultBackendRetryBackoff if 0
+	// BreakerThreshold is the number of consecutive faults after which requests to the
+	// backend are rejected for BreakerCooldown, 0 disables the circuit breaker. They are
+	// the failure_threshold and open_duration of the breaker in the YAML configuration.
+	// BreakerFaults lists the result classes counted as faults (the "result" label of
+	// the backend metrics); when empty, timeouts, transport failures and JSON-RPC
+	// internal and server errors count, while client errors such as invalid params don't.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration // DefaultBackendBreakerCooldown if 0
+	BreakerFaults    []string
+	// HandshakeRetry is how often the MCP handshake is retried after the backend failed
+	// it (e.g. rejected initialize or negotiated an unsupported protocol version); until
+	// it succeeds again, requests routed to the backend are rejected.
+	HandshakeRetry time.Duration // DefaultBackendHandshakeRetry if 0
+	// Replicas lists further URLs serving the same backend. Requests are spread over URL
+	// and the replicas according to Affinity; a replica whose request fails with a fault
+	// (see BreakerFaults) or that cannot be connected is skipped for BreakerCooldown, and
+	// one failing the health checks until it passes them again.
+	Replicas []string
+	// Weights maps URL and replicas to their share of the requests relative to each
+	// other; a URL of weight 3 is sent three times the requests of one of weight 1.
+	// Missing URLs weigh 1.
+	Weights  map[string]int
+	Affinity string // BackendAffinity*, BackendAffinityNone if empty
+	// IdleConnTimeout is how long a keep-alive connection to the backend may stay idle
+	// before it is closed, so that connections dropped by NATs or load balancers are not
+	// reused. MaxIdleConns bounds the idle connections kept per backend host.
+	IdleConnTimeout time.Duration // DefaultBackendIdleConnTimeout if 0
+	MaxIdleConns    int           // DefaultBackendMaxIdleConns if 0
+	// HedgeMethods lists idempotent methods (see HedgeableMethods) whose requests are
+	// hedged over the replicas: when a request has not been answered after HedgeDelay,
+	// another replica is asked too, up to HedgeMax more, and the first answer is used.
+	// Hedged requests and retries share the retry budget of a request: RetryAttempts
+	// extra requests if RetryCodes are set, HedgeMax otherwise.
+	HedgeMethods []string
+	HedgeDelay   time.Duration // DefaultBackendHedgeDelay if 0
+	HedgeMax     int           // DefaultBackendHedgeMax if 0
+	// ListCache caches the backend's answers to the list methods (see CachedListMethods)
+	// for ListCacheTTL, shared by all client sessions, so that a new session does not ask
+	// the backend again. At most ListCacheMaxEntries answers are kept. The answers to a
+	// method are dropped when the backend notifies that its list changed. Passthrough
+	// backends are never cached.
+	ListCache           bool
+	ListCacheTTL        time.Duration // DefaultBackendListCacheTTL if 0
+	ListCacheMaxEntries int           // DefaultBackendListCacheMaxEntries if 0
+	// AggregatePagination makes the gateway fetch all pages of the backend's tools list,
+	// up to shared.MaxListPages, and list them at once. Otherwise the gateway lists the
+	// first page and passes the cursors of the further ones on to the client.
+	AggregatePagination bool
+	// Inject lists user params added to the arguments of tool calls sent to the backend
+	Inject []ArgumentInjection
+}
+
+// URLs returns the URL of the backend followed by its replicas.
+func (b *Backend) URLs() []string {
+	return append([]string{b.URL}, b.Replicas...)
+}
+
+// Weight returns the weight of the URL or replica of the backend.
+func (b *Backend) Weight(url string) int {
+	if weight := b.Weights[url]; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// ArgumentInjection copies a parameter of the calling user into a tool call's arguments,
+// e.g. the user's locale or tenant database, without the client knowing about it.
+type ArgumentInjection struct {
+	Tool     string // Backend tool name, empty for every tool of the backend
+	Param    string // Name of the user param
+	Argument string // Name of the tool argument, Param if empty
+	Override bool   // Replace a value supplied by the client instead of keeping it
+}
+
+// ArgumentName returns the name of the tool argument the param is injected as.
+func (i ArgumentInjection) ArgumentName() string {
+	if i.Argument == "" {
+		return i.Param
+	}
+	return i.Argument
+}
+
+// Default timeouts of requests to a backend without a Timeout.
+const (
+	DefaultBackendToolTimeout    = 30 * time.Second // tools/call
+	DefaultBackendRequestTimeout = 10 * time.Second // Other requests, e.g. resources/read
+)
+
+// Defaults of the retry settings of a backend with RetryCodes. The wait between retries
+// doubles after each one up to MaxBackendRetryBackoff.
+const (
+	DefaultBackendRetryAttempts = 3
+	DefaultBackendRetryBackoff  = 200 * time.Millisecond
+	MaxBackendRetryBackoff      = 5 * time.Second
+)
+
+// DefaultBackendBreakerCooldown is how long an open circuit breaker rejects requests.
+const DefaultBackendBreakerCooldown = 30 * time.Second
+
+// DefaultBackendHandshakeRetry is how often the handshake of a backend that failed it is retried.
+const DefaultBackendHandshakeRetry = 30 * time.Second
+
+// DefaultBackendIdleConnTimeout is how long idle connections to a backend are kept. It is
+// below the idle timeouts of common NATs and load balancers (60 seconds and more).
+const DefaultBackendIdleConnTimeout = 30 * time.Second
+
+// DefaultBackendHealthInterval is how often the gateway health checks each backend.
+const DefaultBackendHealthInterval = 30 * time.Second
+
+// DefaultSSEKeepAlive is how often a keepalive comment is written on an open SSE stream,
+// well below the idle timeouts of common proxies and load balancers (60 seconds and more).
+const DefaultSSEKeepAlive = 15 * time.Second
+
+// DefaultSSEDrainTimeout is how long a server shutting down waits for the requests still
+// running to be answered on the open SSE streams before it closes them.
+const DefaultSSEDrainTimeout = 15 * time.Second
+
+// DefaultCompressionMinSize is the size from which JSON responses are compressed for
+// clients accepting it; smaller ones gain too little to pay for the compression.
+const DefaultCompressionMinSize = 1024
+
+// DefaultTracingSampleRatio is the share of the traces started by the gateway that are
+// sampled once tracing is enabled.
+const DefaultTracingSampleRatio = 1.0
+
+// DefaultAccessLogLevel is the level access log entries are written at unless configured.
+const DefaultAccessLogLevel = "info"
+
+// Fields of the access log entries, see IConfig.AccessLogFields
+const (
+	AccessLogFieldUser     = "user"     // ID of the authenticated user
+	AccessLogFieldSession  = "session"  // Session ID
+	AccessLogFieldMethod   = "method"   // JSON-RPC method of a request
+	AccessLogFieldBackend  = "backend"  // IDs of the backends a request was sent to
+	AccessLogFieldDuration = "duration" // Time to answer a request, or that a stream was open
+	AccessLogFieldCode     = "code"     // JSON-RPC error code, 0 on success
+	AccessLogFieldBytes    = "bytes"    // Size of the response, or written to a stream
+	AccessLogFieldEvents   = "events"   // Events written to a stream
+)
+
+// AccessLogFields lists every access log field; all of them are logged unless configured.
+var AccessLogFields = []string{
+	AccessLogFieldUser, AccessLogFieldSession, AccessLogFieldMethod, AccessLogFieldBackend,
+	AccessLogFieldDuration, AccessLogFieldCode, AccessLogFieldBytes, AccessLogFieldEvents,
+}
+
+// DefaultNameSeparator separates the backend ID from the name of a tool, prompt or
+// resource listed with PrefixNames, as in "weather.search".
+const DefaultNameSeparator = "."
+
+// DefaultMetricsPath is where the gateway serves its metrics once they are enabled.
+const DefaultMetricsPath = "/metrics"
+
+// DefaultSSLReloadInterval is how often the certificate and key files of manual SSL mode
+// are checked for changes.
+const DefaultSSLReloadInterval = time.Minute
+
+// DefaultBackendMaxIdleConns is the number of idle connections kept per backend host.
+const DefaultBackendMaxIdleConns = 8
+
+// Defaults of the hedging settings of a backend with HedgeMethods.
+const (
+	DefaultBackendHedgeDelay = 100 * time.Millisecond
+	DefaultBackendHedgeMax   = 1
+)
+
+// Defaults of the list cache settings of a backend with ListCache.
+const (
+	DefaultBackendListCacheTTL        = 30 * time.Second
+	DefaultBackendListCacheMaxEntries = 100
+)
+
+// CachedListMethods are the read-only methods whose answers a backend with ListCache caches.
+var CachedListMethods = []string{"tools/list", "prompts/list", "resources/list"}
+
+// RateLimit is a token bucket limiting the requests of a user across all their sessions:
+// up to Burst requests at once, refilled at RPS requests per second. The zero value
+// means unlimited.
+type RateLimit struct {
+	RPS   float64
+	Burst int
+}
+
+// HedgeableMethods are the idempotent methods whose requests may be hedged.
+var HedgeableMethods = []string{"resources/read", "prompts/get"}
+
+// ValidateHedgeMethods checks that every method may be hedged.
+func ValidateHedgeMethods(methods []string) error {
+	for _, method := range methods {
+		if !slices.Contains(HedgeableMethods, method) {
+			return fmt.Errorf("method '%s' cannot be hedged, only %s", method, strings.Join(HedgeableMethods, ", "))
+		}
+	}
+	return nil
 }
 
 type IConfig interface {
@@ -32,29 +258,209 @@ type IConfig interface {
 	LogLevel() (string, error)
 	DiscoveringHandlerPath() (string, error)
 	FrontendAddressForProxy() (string, error)
+	SSEMaxStreams() (int, error)               // Server-wide cap on concurrent SSE streams, 0 means unlimited
+	SSEAllowedOrigins() ([]string, error)      // Origins allowed to open SSE/POST connections, empty means the default policy
+	SSEQueueSize() (int, error)                // Streams that may wait for a slot when SSEMaxStreams is reached, 0 means reject at once
+	SSEQueueWait() (time.Duration, error)      // Max wait of a queued stream for a slot
+	SSEKeepAlive() (time.Duration, error)      // Interval of keepalive comments on open SSE streams, 0 means DefaultSSEKeepAlive
+	SSEDrainTimeout() (time.Duration, error)   // Max wait on shutdown for requests answered on open SSE streams, 0 means DefaultSSEDrainTimeout
+	CompressionMinSize() (int, error)          // Min bytes of a JSON response compressed for clients accepting it, 0 means DefaultCompressionMinSize, negative disables compression
+	SanitizeInboundText() (bool, error)        // Strip terminal control sequences from text sent by clients
+	SanitizeOutboundText() (bool, error)       // Strip terminal control sequences from text returned to clients
+	ToolsListDeadline() (time.Duration, error) // Max wait for backends in tools/list, 0 means wait for all
+	MetricsLatencyBuckets() ([]float64, error) // Upper bounds in seconds of the latency histograms, empty means the defaults
+	MetricsVersionLabels() (bool, error)       // Count relayed requests by negotiated protocol versions and adapter use
+	MetricsEnabled() (bool, error)             // Serve metrics in the Prometheus text format at MetricsPath
+	MetricsPath() (string, error)              // Path of the metrics endpoint, empty means DefaultMetricsPath
+	TracingEndpoint() (string, error)          // Base URL of the OTLP/HTTP collector spans are exported to, empty disables tracing
+	TracingSampleRatio() (float64, error)      // Share of the traces started by the gateway that are sampled, 0 to 1
+	AccessLogEnabled() (bool, error)           // Log an entry per answered request and closed SSE stream
+	AccessLogLevel() (string, error)           // Level of the access log entries, empty means DefaultAccessLogLevel
+	AccessLogFields() ([]string, error)        // Fields of the access log entries, empty means all AccessLogFields
+	LogPrivacy() (string, error)               // Redaction of user data in logs: "none" (or empty), "partial" or "strict"
+	IDGenerator() (string, error)              // Scheme of generated session and task IDs: "random" (or empty), "uuid" or "ulid"
+	MethodsDeny() ([]string, error)            // Method patterns rejected for everyone, "*" matches any characters
+	MethodsAllow() ([]string, error)           // Method patterns accepted, empty means all not denied
+	PrefixNames() (bool, error)                // List every tool, prompt and resource prefixed with the ID of its backend
+	NameSeparator() (string, error)            // Between the backend ID and the name of prefixed names, empty means DefaultNameSeparator
 
 	// User & Auth Settings
 	GetUserIDByKeyHash(keyHash string) (userID string, err error)
+	GetUserIDByKey(key string) (userID string, err error) // Verifies a plaintext key against the stored key hashes, "" if none matches
 	GetUserParams(userID string) (params map[string]string, err error)
 	GetUserSubscribes(userID string) (backends []string, err error)
+	GetUserDefaultBackend(userID string) (backendID string, err error)  // Target for requests not matching any listed item, "" if not set
+	GetUserRateLimit(userID string) (limit RateLimit, err error)        // Zero if the user is not rate limited
+	GetUserIDByCertIdentity(identity string) (userID string, err error) // User of a client certificate CN or SAN, "" if none
 
 	// Backend & Subscription Settings
 	GetBackend(backendID string) (backendCfg *Backend, err error)
+	BackendIDs() ([]string, error)                 // IDs of all configured backends, sorted
+	BackendHealthInterval() (time.Duration, error) // How often backends are health checked, 0 means DefaultBackendHealthInterval
+
+	// A2A Settings
+	A2AAgentNames() ([]string, error)                                      // Names of the A2A agents whose cards are served
+	GetA2ACardBaseInfo(agentName string) (info A2ACardBaseInfo, err error) // ErrNotFound for an unknown agent
+	A2AArtifactChecksums() (bool, error)                                   // Add a sha256 checksum to the metadata of produced artifacts
+	A2ADetectArtifactMimeTypes() (bool, error)                             // Detect the MIME type of file artifacts that do not declare one
+	A2AMaxSessionTasks() (int, error)                                      // Tasks kept per session before terminal ones are evicted, 0 means unlimited
+	A2ASessionTaskHardLimit() (int, error)                                 // Tasks per session at which new tasks are rejected, 0 means unlimited
+	A2ATaskIDScope() (string, error)                                       // Scope in which task IDs are unique: "session" (or empty), "user" or "global"
+	A2AMaxConcurrentUserTasks() (int, error)                               // Running tasks per user at which new tasks are rejected, 0 means unlimited
 
 	// SSL Settings
 	SSLEnabled() (bool, error)
-	SSLMode() (string, error)          // Returns "manual" or "acme"
-	SSLCertFile() (string, error)      // Path to certificate file (manual mode)
-	SSLKeyFile() (string, error)       // Path to private key file (manual mode)
-	SSLAcmeDomains() ([]string, error) // List of domains for ACME
-	SSLAcmeEmail() (string, error)     // Contact email for ACME
-	SSLAcmeCacheDir() (string, error)  // Directory to cache ACME certificates
+	SSLMode() (string, error)                  // Returns "manual" or "acme"
+	SSLCertFile() (string, error)              // Path to certificate file (manual mode)
+	SSLKeyFile() (string, error)               // Path to private key file (manual mode)
+	SSLAcmeDomains() ([]string, error)         // List of domains for ACME
+	SSLAcmeEmail() (string, error)             // Contact email for ACME
+	SSLAcmeCacheDir() (string, error)          // Directory to cache ACME certificates
+	SSLClientCAFile() (string, error)          // PEM bundle of CAs verifying client certificates, "" to not request them
+	SSLOCSPStapling() (bool, error)            // Staple OCSP responses for the certificate (manual mode)
+	SSLMinVersion() (string, error)            // Minimum TLS version, TLSVersion12 or TLSVersion13, "" for the crypto/tls default
+	SSLCipherSuites() ([]string, error)        // Names of the accepted TLS 1.2 cipher suites, empty for the crypto/tls defaults
+	SSLReloadInterval() (time.Duration, error) // How often the certificate files are checked for changes, 0 means DefaultSSLReloadInterval
 
 	// Lifecycle & Status
 	Status(ctx context.Context) error
 	Close() error
 }
 
+// ValidateLatencyBuckets checks that histogram bucket bounds are positive and ascending.
+func ValidateLatencyBuckets(buckets []float64) error {
+	for i, bound := range buckets {
+		if bound <= 0 || (i > 0 && bound <= buckets[i-1]) {
+			return fmt.Errorf("latency buckets must be positive and ascending, got %v", buckets)
+		}
+	}
+	return nil
+}
+
+// ValidateMetricsPath checks the path of the metrics endpoint; empty is the default.
+func ValidateMetricsPath(path string) error {
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("metrics path must start with '/', got '%s'", path)
+	}
+	return nil
+}
+
+// ValidateTracingSampleRatio checks that a sample ratio is between 0 and 1.
+func ValidateTracingSampleRatio(ratio float64) error {
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("sample ratio must be between 0 and 1, got %v", ratio)
+	}
+	return nil
+}
+
+// ValidateAccessLogLevel checks the level of the access log entries; empty is the default.
+func ValidateAccessLogLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	if _, err := zapcore.ParseLevel(level); err != nil {
+		return fmt.Errorf("invalid access log level '%s'", level)
+	}
+	return nil
+}
+
+// ValidateAccessLogFields checks that the fields are AccessLogFields.
+func ValidateAccessLogFields(fields []string) error {
+	for _, field := range fields {
+		if !slices.Contains(AccessLogFields, field) {
+			return fmt.Errorf("unknown access log field '%s', expected one of %s", field, strings.Join(AccessLogFields, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateMethodPatterns checks the patterns of a method allow or deny list.
+func ValidateMethodPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("method pattern must not be empty")
+		}
+	}
+	return nil
+}
+
+// Log privacy levels, see IConfig.LogPrivacy.
+const (
+	LogPrivacyNone    = "none"
+	LogPrivacyPartial = "partial"
+	LogPrivacyStrict  = "strict"
+)
+
+// ValidateLogPrivacy checks that level is one of the log privacy levels or empty.
+func ValidateLogPrivacy(level string) error {
+	switch level {
+	case "", LogPrivacyNone, LogPrivacyPartial, LogPrivacyStrict:
+		return nil
+	default:
+		return fmt.Errorf("log privacy must be %q, %q or %q, got %q", LogPrivacyNone, LogPrivacyPartial, LogPrivacyStrict, level)
+	}
+}
+
+// Schemes of generated IDs, see IConfig.IDGenerator.
+const (
+	// IDGeneratorRandom generates 32 random bytes in URL-safe base64
+	IDGeneratorRandom = "random"
+	// IDGeneratorUUID generates random (version 4) UUIDs
+	IDGeneratorUUID = "uuid"
+	// IDGeneratorULID generates ULIDs, which sort by creation time
+	IDGeneratorULID = "ulid"
+)
+
+// ValidateIDGenerator checks that name is one of the ID generator schemes or empty.
+func ValidateIDGenerator(name string) error {
+	switch name {
+	case "", IDGeneratorRandom, IDGeneratorUUID, IDGeneratorULID:
+		return nil
+	default:
+		return fmt.Errorf("ID generator must be %q, %q or %q, got %q", IDGeneratorRandom, IDGeneratorUUID, IDGeneratorULID, name)
+	}
+}
+
+// Scopes of A2A task IDs, see IConfig.A2ATaskIDScope.
+const (
+	// A2ATaskIDScopeSession makes the same ID in different sessions refer to distinct tasks
+	A2ATaskIDScopeSession = "session"
+	// A2ATaskIDScopeUser makes the same ID of different users refer to distinct tasks
+	A2ATaskIDScopeUser = "user"
+	// A2ATaskIDScopeGlobal rejects a task whose ID is used by any stored task
+	A2ATaskIDScopeGlobal = "global"
+)
+
+// ValidateA2ATaskIDScope checks that scope is one of the task ID scopes or empty.
+func ValidateA2ATaskIDScope(scope string) error {
+	switch scope {
+	case "", A2ATaskIDScopeSession, A2ATaskIDScopeUser, A2ATaskIDScopeGlobal:
+		return nil
+	default:
+		return fmt.Errorf("task ID scope must be %q, %q or %q, got %q", A2ATaskIDScopeSession, A2ATaskIDScopeUser, A2ATaskIDScopeGlobal, scope)
+	}
+}
+
+// Ways of choosing the replica of a backend for the requests of a client session, see Backend.Affinity.
+const (
+	// BackendAffinityNone spreads requests over the replicas round-robin
+	BackendAffinityNone = "none"
+	// BackendAffinitySession sends the requests of a session to the replica chosen for its first one
+	BackendAffinitySession = "session"
+	// BackendAffinityHash sends the requests of a session to a replica chosen by hashing the session ID
+	BackendAffinityHash = "hash"
+)
+
+// ValidateBackendAffinity checks that affinity is one of the backend affinities or empty.
+func ValidateBackendAffinity(affinity string) error {
+	switch affinity {
+	case "", BackendAffinityNone, BackendAffinitySession, BackendAffinityHash:
+		return nil
+	default:
+		return fmt.Errorf("affinity must be %q, %q or %q, got %q", BackendAffinityNone, BackendAffinitySession, BackendAffinityHash, affinity)
+	}
+}
+
 // HashAPIKey converts a plaintext API key to its SHA-256 hash representation
 func HashAPIKey(key string) string {
 	if key == "" {
@@ -3,7 +3,10 @@ package config
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 )
 
 var _ IConfig = (*InternalConfig)(nil)
@@ -11,27 +14,69 @@ var ErrNotFound = errors.New("not found")
 
 // InternalConfig implements all configuration interfaces with in-memory storage
 type InternalConfig struct {
-	mu                          sync.RWMutex
-	ServerAddress               string
-	ServerNameValue             string
-	ServerVersionValue          string
-	AuthorizationTypeValue      AuthorizationType
-	LogLevelValue               string
-	DiscoveringHandlerPathValue string
-	FrontendAddressValue        string
-	UserKeyHashes               map[string]string            // keyHash -> userID (new, secure)
-	userParams                  map[string]map[string]string // userID -> paramName -> paramValue
-	UserSubscribes              map[string][]string          // userID -> BackendIDs
-	Backends                    map[string]*Backend          // serverID -> Server
+	mu                             sync.RWMutex
+	ServerAddress                  string
+	ServerNameValue                string
+	ServerVersionValue             string
+	AuthorizationTypeValue         AuthorizationType
+	LogLevelValue                  string
+	DiscoveringHandlerPathValue    string
+	FrontendAddressValue           string
+	SSEMaxStreamsValue             int // 0 means unlimited
+	SSEAllowedOriginsValue         []string
+	SSEQueueSizeValue              int           // 0 rejects streams over the limit at once
+	SSEQueueWaitValue              time.Duration // Max wait of a queued stream
+	SSEKeepAliveValue              time.Duration // 0 means DefaultSSEKeepAlive
+	SSEDrainTimeoutValue           time.Duration // 0 means DefaultSSEDrainTimeout
+	CompressionMinSizeValue        int           // 0 means DefaultCompressionMinSize, negative disables compression
+	SanitizeInboundTextValue       bool
+	SanitizeOutboundTextValue      bool
+	ToolsListDeadlineValue         time.Duration // 0 waits for all backends
+	BackendHealthIntervalValue     time.Duration // 0 means DefaultBackendHealthInterval
+	MetricsLatencyBucketsValue     []float64     // Seconds, empty for the defaults
+	MetricsVersionLabelsValue      bool
+	MetricsEnabledValue            bool
+	MetricsPathValue               string // Empty means DefaultMetricsPath
+	TracingEndpointValue           string // Empty disables tracing
+	TracingSampleRatioValue        float64
+	AccessLogEnabledValue          bool
+	AccessLogLevelValue            string   // Empty means DefaultAccessLogLevel
+	AccessLogFieldsValue           []string // Empty means all AccessLogFields
+	LogPrivacyValue                string   // Empty means LogPrivacyNone
+	IDGeneratorValue               string   // Empty means IDGeneratorRandom
+	MethodsDenyValue               []string // Method patterns rejected for everyone
+	MethodsAllowValue              []string // Empty accepts all methods not denied
+	PrefixNamesValue               bool
+	NameSeparatorValue             string                       // Empty means DefaultNameSeparator
+	UserKeyHashes                  map[string]string            // keyHash -> userID (new, secure)
+	HashAlgorithmValue             string                       // Of the hashes in UserKeyHashes, "" means HashAlgorithmSHA256
+	userParams                     map[string]map[string]string // userID -> paramName -> paramValue
+	UserSubscribes                 map[string][]string          // userID -> BackendIDs
+	UserDefaultBackends            map[string]string            // userID -> BackendID
+	UserRateLimits                 map[string]RateLimit         // userID -> RateLimit
+	UserCertIdentities             map[string]string            // Client certificate CN or SAN -> userID
+	Backends                       map[string]*Backend          // serverID -> Server
+	A2AAgents                      map[string]A2ACardBaseInfo   // agentName -> card base info
+	A2AArtifactChecksumsValue      bool
+	A2ADetectMimeTypesValue        bool
+	A2AMaxSessionTasksValue        int    // 0 means unlimited
+	A2ASessionTaskHardLimitValue   int    // 0 means unlimited
+	A2AMaxConcurrentUserTasksValue int    // 0 means unlimited
+	A2ATaskIDScopeValue            string // Empty means A2ATaskIDScopeSession
 
 	// SSL Fields
-	SSLEnabledValue      bool
-	SSLModeValue         string
-	SSLCertFileValue     string
-	SSLKeyFileValue      string
-	SSLAcmeDomainsValue  []string
-	SSLAcmeEmailValue    string
-	SSLAcmeCacheDirValue string
+	SSLEnabledValue        bool
+	SSLModeValue           string
+	SSLCertFileValue       string
+	SSLKeyFileValue        string
+	SSLAcmeDomainsValue    []string
+	SSLAcmeEmailValue      string
+	SSLAcmeCacheDirValue   string
+	SSLClientCAFileValue   string
+	SSLOCSPStaplingValue   bool
+	SSLMinVersionValue     string
+	SSLCipherSuitesValue   []string
+	SSLReloadIntervalValue time.Duration // 0 means DefaultSSLReloadInterval
 }
 
 // NewInternalConfig creates a new in-memory configuration
@@ -43,10 +88,14 @@ func NewInternalConfig() *InternalConfig {
 		LogLevelValue:        "info",
 		FrontendAddressValue: "http://localhost:3000",
 
-		UserKeyHashes:  make(map[string]string),
-		userParams:     make(map[string]map[string]string),
-		UserSubscribes: make(map[string][]string),
-		Backends:       make(map[string]*Backend),
+		UserKeyHashes:       make(map[string]string),
+		userParams:          make(map[string]map[string]string),
+		UserSubscribes:      make(map[string][]string),
+		UserDefaultBackends: make(map[string]string),
+		UserRateLimits:      make(map[string]RateLimit),
+		UserCertIdentities:  make(map[string]string),
+		Backends:            make(map[string]*Backend),
+		A2AAgents:           make(map[string]A2ACardBaseInfo),
 
 		// Default SSL settings
 		SSLEnabledValue:      false,
@@ -56,6 +105,8 @@ func NewInternalConfig() *InternalConfig {
 		SSLAcmeDomainsValue:  []string{},
 		SSLAcmeEmailValue:    "",
 		SSLAcmeCacheDirValue: "./.autocert-cache", // Default cache dir
+
+		TracingSampleRatioValue: DefaultTracingSampleRatio,
 	}
 }
 
@@ -126,6 +177,347 @@ func (c *InternalConfig) SetFrontendAddressForProxy(address string) {
 	c.FrontendAddressValue = address
 }
 
+// SSEMaxStreams returns the server-wide limit of concurrent SSE streams
+func (c *InternalConfig) SSEMaxStreams() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSEMaxStreamsValue, nil
+}
+
+// SetSSEMaxStreams sets the server-wide limit of concurrent SSE streams
+func (c *InternalConfig) SetSSEMaxStreams(max int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.SSEMaxStreamsValue = max
+}
+
+// SSEQueueSize returns how many streams may wait for a slot once the stream limit is reached
+func (c *InternalConfig) SSEQueueSize() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSEQueueSizeValue, nil
+}
+
+// SSEQueueWait returns how long a queued stream waits for a slot
+func (c *InternalConfig) SSEQueueWait() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSEQueueWaitValue, nil
+}
+
+// SetSSEQueue sets how many streams may wait for a slot, and for how long
+func (c *InternalConfig) SetSSEQueue(size int, wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.SSEQueueSizeValue = size
+	c.SSEQueueWaitValue = wait
+}
+
+// SSEKeepAlive returns the interval of keepalive comments on open SSE streams (0 for the default)
+func (c *InternalConfig) SSEKeepAlive() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSEKeepAliveValue, nil
+}
+
+// SetSSEKeepAlive sets the interval of keepalive comments on open SSE streams
+func (c *InternalConfig) SetSSEKeepAlive(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.SSEKeepAliveValue = interval
+}
+
+// SSEDrainTimeout returns how long open SSE streams are drained on shutdown (0 for the default)
+func (c *InternalConfig) SSEDrainTimeout() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSEDrainTimeoutValue, nil
+}
+
+// SetSSEDrainTimeout sets how long open SSE streams are drained on shutdown
+func (c *InternalConfig) SetSSEDrainTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.SSEDrainTimeoutValue = timeout
+}
+
+// CompressionMinSize returns the min size of compressed JSON responses (0 for the default)
+func (c *InternalConfig) CompressionMinSize() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.CompressionMinSizeValue, nil
+}
+
+// SetCompressionMinSize sets the min size of compressed JSON responses, negative disables compression
+func (c *InternalConfig) SetCompressionMinSize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.CompressionMinSizeValue = size
+}
+
+// SSEAllowedOrigins returns the browser origins allowed to connect
+func (c *InternalConfig) SSEAllowedOrigins() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	origins := make([]string, len(c.SSEAllowedOriginsValue))
+	copy(origins, c.SSEAllowedOriginsValue)
+	return origins, nil
+}
+
+// SetSSEAllowedOrigins sets the browser origins allowed to connect
+func (c *InternalConfig) SetSSEAllowedOrigins(origins []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.SSEAllowedOriginsValue = append([]string(nil), origins...)
+}
+
+// SanitizeInboundText reports whether control sequences are stripped from client text
+func (c *InternalConfig) SanitizeInboundText() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SanitizeInboundTextValue, nil
+}
+
+// SanitizeOutboundText reports whether control sequences are stripped from text returned to clients
+func (c *InternalConfig) SanitizeOutboundText() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SanitizeOutboundTextValue, nil
+}
+
+// SetSanitizeText enables or disables control sequence stripping per direction
+func (c *InternalConfig) SetSanitizeText(inbound bool, outbound bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.SanitizeInboundTextValue = inbound
+	c.SanitizeOutboundTextValue = outbound
+}
+
+// ToolsListDeadline returns how long tools/list waits for backends (0 waits for all)
+func (c *InternalConfig) ToolsListDeadline() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ToolsListDeadlineValue, nil
+}
+
+// SetToolsListDeadline sets how long tools/list waits for backends
+func (c *InternalConfig) SetToolsListDeadline(deadline time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ToolsListDeadlineValue = deadline
+}
+
+// MetricsLatencyBuckets returns the bucket bounds of the latency histograms (empty for the defaults)
+func (c *InternalConfig) MetricsLatencyBuckets() ([]float64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]float64(nil), c.MetricsLatencyBucketsValue...), nil
+}
+
+// SetMetricsLatencyBuckets sets the bucket bounds of the latency histograms
+func (c *InternalConfig) SetMetricsLatencyBuckets(buckets []float64) error {
+	if err := ValidateLatencyBuckets(buckets); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.MetricsLatencyBucketsValue = append([]float64(nil), buckets...)
+	return nil
+}
+
+// MetricsVersionLabels reports whether relayed requests are counted by protocol versions
+func (c *InternalConfig) MetricsVersionLabels() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.MetricsVersionLabelsValue, nil
+}
+
+// SetMetricsVersionLabels sets whether relayed requests are counted by protocol versions
+func (c *InternalConfig) SetMetricsVersionLabels(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.MetricsVersionLabelsValue = enabled
+}
+
+// MetricsEnabled reports whether metrics are served
+func (c *InternalConfig) MetricsEnabled() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.MetricsEnabledValue, nil
+}
+
+// MetricsPath returns the path of the metrics endpoint (empty for DefaultMetricsPath)
+func (c *InternalConfig) MetricsPath() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.MetricsPathValue, nil
+}
+
+// TracingEndpoint returns the base URL of the OTLP/HTTP collector (empty if tracing is disabled)
+func (c *InternalConfig) TracingEndpoint() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.TracingEndpointValue, nil
+}
+
+// TracingSampleRatio returns the share of the traces started by the gateway that are sampled
+func (c *InternalConfig) TracingSampleRatio() (float64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.TracingSampleRatioValue, nil
+}
+
+// SetTracing exports spans to the OTLP/HTTP collector at endpoint, sampling ratio of the
+// traces started by the gateway. An empty endpoint disables tracing.
+func (c *InternalConfig) SetTracing(endpoint string, ratio float64) error {
+	if err := ValidateTracingSampleRatio(ratio); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.TracingEndpointValue = endpoint
+	c.TracingSampleRatioValue = ratio
+	return nil
+}
+
+// AccessLogEnabled reports whether requests and SSE streams are logged in the access log
+func (c *InternalConfig) AccessLogEnabled() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AccessLogEnabledValue, nil
+}
+
+// AccessLogLevel returns the level of the access log entries (empty for DefaultAccessLogLevel)
+func (c *InternalConfig) AccessLogLevel() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AccessLogLevelValue, nil
+}
+
+// AccessLogFields returns the fields of the access log entries (empty for all)
+func (c *InternalConfig) AccessLogFields() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AccessLogFieldsValue, nil
+}
+
+// SetAccessLog enables or disables the access log, with entries at level holding fields.
+// Empty level and fields mean the defaults.
+func (c *InternalConfig) SetAccessLog(enabled bool, level string, fields []string) error {
+	if err := ValidateAccessLogLevel(level); err != nil {
+		return err
+	}
+	if err := ValidateAccessLogFields(fields); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.AccessLogEnabledValue = enabled
+	c.AccessLogLevelValue = level
+	c.AccessLogFieldsValue = fields
+	return nil
+}
+
+// SetMetrics enables or disables the metrics endpoint at path, DefaultMetricsPath if empty
+func (c *InternalConfig) SetMetrics(enabled bool, path string) error {
+	if err := ValidateMetricsPath(path); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.MetricsEnabledValue = enabled
+	c.MetricsPathValue = path
+	return nil
+}
+
+// LogPrivacy returns the redaction level of user data in logs
+func (c *InternalConfig) LogPrivacy() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.LogPrivacyValue, nil
+}
+
+// SetLogPrivacy sets the redaction level of user data in logs
+func (c *InternalConfig) SetLogPrivacy(level string) error {
+	if err := ValidateLogPrivacy(level); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.LogPrivacyValue = level
+	return nil
+}
+
+// IDGenerator returns the scheme of generated session and task IDs
+func (c *InternalConfig) IDGenerator() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.IDGeneratorValue, nil
+}
+
+// SetIDGenerator sets the scheme of generated session and task IDs
+func (c *InternalConfig) SetIDGenerator(name string) error {
+	if err := ValidateIDGenerator(name); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.IDGeneratorValue = name
+	return nil
+}
+
+// MethodsDeny returns the patterns of methods rejected for everyone
+func (c *InternalConfig) MethodsDeny() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.MethodsDenyValue...), nil
+}
+
+// MethodsAllow returns the patterns of methods accepted (empty accepts all not denied)
+func (c *InternalConfig) MethodsAllow() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.MethodsAllowValue...), nil
+}
+
+// SetMethods sets the patterns of methods accepted and rejected for everyone
+func (c *InternalConfig) SetMethods(allow, deny []string) error {
+	if err := ValidateMethodPatterns(allow); err != nil {
+		return err
+	}
+	if err := ValidateMethodPatterns(deny); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.MethodsAllowValue = append([]string(nil), allow...)
+	c.MethodsDenyValue = append([]string(nil), deny...)
+	return nil
+}
+
+// PrefixNames reports whether listed names are prefixed with the ID of their backend
+func (c *InternalConfig) PrefixNames() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.PrefixNamesValue, nil
+}
+
+// NameSeparator returns the separator of prefixed names (empty for DefaultNameSeparator)
+func (c *InternalConfig) NameSeparator() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.NameSeparatorValue, nil
+}
+
+// SetPrefixNames enables or disables prefixing listed names with the ID of their
+// backend, separated by separator (empty for DefaultNameSeparator)
+func (c *InternalConfig) SetPrefixNames(prefix bool, separator string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.PrefixNamesValue = prefix
+	c.NameSeparatorValue = separator
+}
+
 // UsersConfig implementation
 
 func (c *InternalConfig) GetUserIDByKeyHash(keyHash string) (string, error) {
@@ -140,6 +532,31 @@ func (c *InternalConfig) GetUserIDByKeyHash(keyHash string) (string, error) {
 	return c.UserKeyHashes[keyHash], nil
 }
 
+func (c *InternalConfig) GetUserIDByKey(key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if key == "" {
+		return "", nil
+	}
+	hash, _, err := matchKeyHash(c.HashAlgorithmValue, c.UserKeyHashes, key, nil)
+	if err != nil {
+		return "", err
+	}
+	return c.UserKeyHashes[hash], nil
+}
+
+// SetHashAlgorithm sets the algorithm of the key hashes in UserKeyHashes
+func (c *InternalConfig) SetHashAlgorithm(algorithm string) error {
+	if err := ValidateHashAlgorithm(algorithm); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.HashAlgorithmValue = algorithm
+	return nil
+}
+
 func (c *InternalConfig) GetUserParams(userID string) (map[string]string, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -199,6 +616,58 @@ func (c *InternalConfig) SetUserSubscribes(userID string, servers []string) {
 	c.UserSubscribes[userID] = serversCopy
 }
 
+func (c *InternalConfig) GetUserDefaultBackend(userID string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.UserDefaultBackends[userID], nil
+}
+
+func (c *InternalConfig) SetUserDefaultBackend(userID string, backendID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if backendID == "" {
+		delete(c.UserDefaultBackends, userID)
+		return
+	}
+	c.UserDefaultBackends[userID] = backendID
+}
+
+func (c *InternalConfig) GetUserRateLimit(userID string) (RateLimit, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.UserRateLimits[userID], nil
+}
+
+func (c *InternalConfig) SetUserRateLimit(userID string, limit RateLimit) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if limit == (RateLimit{}) {
+		delete(c.UserRateLimits, userID)
+		return
+	}
+	c.UserRateLimits[userID] = limit
+}
+
+func (c *InternalConfig) GetUserIDByCertIdentity(identity string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.UserCertIdentities[identity], nil
+}
+
+// SetUserCertIdentity maps a client certificate CN or SAN to the user, "" removes it.
+func (c *InternalConfig) SetUserCertIdentity(identity string, userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if userID == "" {
+		delete(c.UserCertIdentities, identity)
+		return
+	}
+	c.UserCertIdentities[identity] = userID
+}
+
 // ServersConfig implementation
 
 func (c *InternalConfig) GetBackend(serverID string) (*Backend, error) {
@@ -213,6 +682,27 @@ func (c *InternalConfig) GetBackend(serverID string) (*Backend, error) {
 	return server, nil
 }
 
+// BackendIDs returns the IDs of all configured backends, sorted
+func (c *InternalConfig) BackendIDs() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.Backends)), nil
+}
+
+// BackendHealthInterval returns how often backends are health checked (0 for the default)
+func (c *InternalConfig) BackendHealthInterval() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.BackendHealthIntervalValue, nil
+}
+
+// SetBackendHealthInterval sets how often backends are health checked
+func (c *InternalConfig) SetBackendHealthInterval(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.BackendHealthIntervalValue = interval
+}
+
 func (c *InternalConfig) SetBackend(serverID, url string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -226,6 +716,22 @@ func (c *InternalConfig) SetBackend(serverID, url string) {
 	c.Backends[serverID] = backend
 }
 
+// SetBackendCommand makes the backend a child process speaking MCP over stdio, started
+// in dir with the "KEY=value" variables of env added to the gateway's environment
+func (c *InternalConfig) SetBackendCommand(backendID string, dir string, env []string, command ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.Command = append([]string(nil), command...)
+	server.Env = append([]string(nil), env...)
+	server.Dir = dir
+}
+
 func (c *InternalConfig) SetBackendBearer(backendID, bearer string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -239,6 +745,274 @@ func (c *InternalConfig) SetBackendBearer(backendID, bearer string) {
 	c.Backends[backendID] = server
 }
 
+func (c *InternalConfig) SetBackendPassthrough(backendID string, passthrough bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		c.Backends[backendID] = &Backend{Passthrough: passthrough}
+		return
+	}
+	server.Passthrough = passthrough
+	c.Backends[backendID] = server
+}
+
+// SetBackendRetry sets the JSON-RPC error codes retried for the backend and the retry limits
+func (c *InternalConfig) SetBackendRetry(backendID string, codes []int, attempts int, backoff time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.RetryCodes = append([]int(nil), codes...)
+	server.RetryAttempts = attempts
+	server.RetryBackoff = backoff
+}
+
+// SetBackendBreaker sets the circuit breaker of the backend; a zero threshold disables it
+func (c *InternalConfig) SetBackendBreaker(backendID string, threshold int, cooldown time.Duration, faults []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.BreakerThreshold = threshold
+	server.BreakerCooldown = cooldown
+	server.BreakerFaults = append([]string(nil), faults...)
+}
+
+// SetBackendListCache sets whether the answers of the backend to list methods are
+// cached, for how long and how many of them
+func (c *InternalConfig) SetBackendListCache(backendID string, enabled bool, ttl time.Duration, maxEntries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.ListCache = enabled
+	server.ListCacheTTL = ttl
+	server.ListCacheMaxEntries = maxEntries
+}
+
+// SetBackendAggregatePagination sets whether all pages of the backend's tools list are
+// listed at once
+func (c *InternalConfig) SetBackendAggregatePagination(backendID string, enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.AggregatePagination = enabled
+}
+
+// SetBackendHandshakeRetry sets how often a failed handshake with the backend is retried
+func (c *InternalConfig) SetBackendHandshakeRetry(backendID string, interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.HandshakeRetry = interval
+}
+
+// SetBackendReplicas sets the further URLs of the backend and how they are chosen for client sessions
+func (c *InternalConfig) SetBackendReplicas(backendID string, affinity string, replicas ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.Affinity = affinity
+	server.Replicas = append([]string(nil), replicas...)
+}
+
+// SetBackendWeights sets the share of the requests of the URL and replicas of the backend
+func (c *InternalConfig) SetBackendWeights(backendID string, weights map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.Weights = maps.Clone(weights)
+}
+
+// SetBackendTimeout sets the timeout of each request to the backend
+func (c *InternalConfig) SetBackendTimeout(backendID string, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.Timeout = timeout
+}
+
+// SetBackendIdleConns sets how long idle connections to the backend are kept and how many
+func (c *InternalConfig) SetBackendIdleConns(backendID string, timeout time.Duration, maxIdle int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.IdleConnTimeout = timeout
+	server.MaxIdleConns = maxIdle
+}
+
+// SetBackendInjections sets the user params injected into tool call arguments for the backend
+func (c *InternalConfig) SetBackendInjections(backendID string, injections []ArgumentInjection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	server, exists := c.Backends[backendID]
+	if !exists {
+		server = &Backend{}
+		c.Backends[backendID] = server
+	}
+	server.Inject = append([]ArgumentInjection(nil), injections...)
+}
+
+// A2AConfig implementation
+
+func (c *InternalConfig) A2AAgentNames() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return sortedA2AAgentNames(c.A2AAgents), nil
+}
+
+func (c *InternalConfig) GetA2ACardBaseInfo(agentName string) (A2ACardBaseInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	info, exists := c.A2AAgents[agentName]
+	if !exists {
+		return A2ACardBaseInfo{}, ErrNotFound
+	}
+	return info, nil
+}
+
+// SetA2AAgent adds or replaces an A2A agent definition. The agent set must stay valid
+// (see ValidateA2AAgents), otherwise the change is rejected.
+func (c *InternalConfig) SetA2AAgent(agentName string, info A2ACardBaseInfo) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agents := make(map[string]A2ACardBaseInfo, len(c.A2AAgents)+1)
+	for name, existing := range c.A2AAgents {
+		agents[name] = existing
+	}
+	agents[agentName] = info
+	if err := ValidateA2AAgents(agents); err != nil {
+		return err
+	}
+	c.A2AAgents = agents
+	return nil
+}
+
+// A2AArtifactChecksums reports whether a sha256 checksum is added to the metadata of produced artifacts
+func (c *InternalConfig) A2AArtifactChecksums() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.A2AArtifactChecksumsValue, nil
+}
+
+// SetA2AArtifactChecksums enables or disables checksums on produced artifacts
+func (c *InternalConfig) SetA2AArtifactChecksums(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.A2AArtifactChecksumsValue = enabled
+}
+
+// A2ADetectArtifactMimeTypes reports whether missing MIME types of file artifacts are detected
+func (c *InternalConfig) A2ADetectArtifactMimeTypes() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.A2ADetectMimeTypesValue, nil
+}
+
+// SetA2ADetectArtifactMimeTypes enables or disables MIME type detection of file artifacts
+func (c *InternalConfig) SetA2ADetectArtifactMimeTypes(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.A2ADetectMimeTypesValue = enabled
+}
+
+// A2AMaxSessionTasks returns the number of tasks kept per session before terminal ones are evicted
+func (c *InternalConfig) A2AMaxSessionTasks() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.A2AMaxSessionTasksValue, nil
+}
+
+// A2ASessionTaskHardLimit returns the number of tasks per session at which new tasks are rejected
+func (c *InternalConfig) A2ASessionTaskHardLimit() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.A2ASessionTaskHardLimitValue, nil
+}
+
+// SetA2ASessionTaskLimits sets the per-session task eviction threshold and hard limit
+func (c *InternalConfig) SetA2ASessionTaskLimits(maxTasks int, hardLimit int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.A2AMaxSessionTasksValue = maxTasks
+	c.A2ASessionTaskHardLimitValue = hardLimit
+}
+
+// A2ATaskIDScope returns the scope in which A2A task IDs are unique
+func (c *InternalConfig) A2ATaskIDScope() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.A2ATaskIDScopeValue, nil
+}
+
+// SetA2ATaskIDScope sets the scope in which A2A task IDs are unique
+func (c *InternalConfig) SetA2ATaskIDScope(scope string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.A2ATaskIDScopeValue = scope
+}
+
+// A2AMaxConcurrentUserTasks returns the number of running tasks per user at which new tasks are rejected
+func (c *InternalConfig) A2AMaxConcurrentUserTasks() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.A2AMaxConcurrentUserTasksValue, nil
+}
+
+// SetA2AMaxConcurrentUserTasks sets the number of running tasks per user at which new tasks are rejected
+func (c *InternalConfig) SetA2AMaxConcurrentUserTasks(limit int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.A2AMaxConcurrentUserTasksValue = limit
+}
+
 func (c *InternalConfig) Close() error {
 	return nil
 }
@@ -293,3 +1067,33 @@ func (c *InternalConfig) SSLAcmeCacheDir() (string, error) {
 	defer c.mu.RUnlock()
 	return c.SSLAcmeCacheDirValue, nil
 }
+
+func (c *InternalConfig) SSLClientCAFile() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSLClientCAFileValue, nil
+}
+
+func (c *InternalConfig) SSLOCSPStapling() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSLOCSPStaplingValue, nil
+}
+
+func (c *InternalConfig) SSLMinVersion() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSLMinVersionValue, nil
+}
+
+func (c *InternalConfig) SSLCipherSuites() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.SSLCipherSuitesValue...), nil
+}
+
+func (c *InternalConfig) SSLReloadInterval() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.SSLReloadIntervalValue, nil
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"container/list"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithms hashing the API keys of users in the configuration.
+const (
+	// HashAlgorithmSHA256 stores keys as hex encoded SHA-256 hashes (see HashAPIKey). It
+	// is the default.
+	HashAlgorithmSHA256 = "sha256"
+	// HashAlgorithmBcrypt stores keys as bcrypt hashes, e.g. "$2a$10$...".
+	HashAlgorithmBcrypt = "bcrypt"
+	// HashAlgorithmArgon2id stores keys as argon2id PHC strings, e.g.
+	// "$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>".
+	HashAlgorithmArgon2id = "argon2id"
+)
+
+// Parameters of the argon2id hashes made by HashAPIKeyWith. Stored hashes are verified
+// with their own parameters.
+const (
+	argon2idTime    = 3
+	argon2idMemory  = 64 * 1024 // KiB
+	argon2idThreads = 4
+	argon2idSaltLen = 16
+	argon2idKeyLen  = 32
+)
+
+// ValidateHashAlgorithm checks an API key hash algorithm setting; empty means HashAlgorithmSHA256.
+func ValidateHashAlgorithm(algorithm string) error {
+	switch algorithm {
+	case "", HashAlgorithmSHA256, HashAlgorithmBcrypt, HashAlgorithmArgon2id:
+		return nil
+	default:
+		return fmt.Errorf("hash algorithm must be %q, %q or %q, got %q", HashAlgorithmSHA256, HashAlgorithmBcrypt, HashAlgorithmArgon2id, algorithm)
+	}
+}
+
+// HashAPIKeyWith hashes a plaintext API key with the algorithm, for storing it in the
+// configuration. Bcrypt and argon2id hashes are salted, so hashing the same key twice
+// gives different hashes.
+func HashAPIKeyWith(algorithm string, key string) (string, error) {
+	switch algorithm {
+	case "", HashAlgorithmSHA256:
+		return HashAPIKey(key), nil
+	case HashAlgorithmBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(key), bcrypt.DefaultCost)
+		return string(hash), err
+	case HashAlgorithmArgon2id:
+		salt := make([]byte, argon2idSaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		hash := argon2.IDKey([]byte(key), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2idMemory, argon2idTime, argon2idThreads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+	default:
+		return "", ValidateHashAlgorithm(algorithm)
+	}
+}
+
+// VerifyAPIKey reports whether the plaintext API key matches the hash stored with the
+// algorithm. It fails if a stored bcrypt or argon2id hash is malformed.
+func VerifyAPIKey(algorithm string, key string, stored string) (bool, error) {
+	switch algorithm {
+	case "", HashAlgorithmSHA256:
+		return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(stored)) == 1, nil
+	case HashAlgorithmBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(key))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	case HashAlgorithmArgon2id:
+		params, salt, hash, err := parseArgon2id(stored)
+		if err != nil {
+			return false, err
+		}
+		computed := argon2.IDKey([]byte(key), salt, params.time, params.memory, params.threads, uint32(len(hash)))
+		return subtle.ConstantTimeCompare(computed, hash) == 1, nil
+	default:
+		return false, ValidateHashAlgorithm(algorithm)
+	}
+}
+
+// keyVerifications bounds the bcrypt and argon2id verifications running at once in the
+// process, so that requests with unknown keys cannot take more cores, and with argon2id
+// more memory, than that.
+var keyVerifications = make(chan struct{}, max(1, runtime.GOMAXPROCS(0)))
+
+// verifyAPIKey verifies a key against a stored slow hash; tests count the calls.
+var verifyAPIKey = VerifyAPIKey
+
+// matchKeyHash returns the first key hash of keyHashes matching the plaintext key with
+// the algorithm, and whether there is one. With bcrypt and argon2id, a hash whose prefix
+// as returned by prefixOf (nil for none) does not start the key is skipped without being
+// verified, so a key costs a single slow hash if the stored keys have distinct prefixes.
+func matchKeyHash[V any](algorithm string, keyHashes map[string]V, key string, prefixOf func(V) string) (string, bool, error) {
+	if algorithm == "" || algorithm == HashAlgorithmSHA256 {
+		hash := HashAPIKey(key)
+		_, found := keyHashes[hash]
+		return hash, found, nil
+	}
+	keyVerifications <- struct{}{}
+	defer func() { <-keyVerifications }()
+	for stored, value := range keyHashes {
+		if prefixOf != nil && !strings.HasPrefix(key, prefixOf(value)) {
+			continue
+		}
+		match, err := verifyAPIKey(algorithm, key, stored)
+		if err != nil {
+			return "", false, err
+		}
+		if match {
+			return stored, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// maxKeyMisses is the number of keys matching no stored hash a keyCache remembers.
+const maxKeyMisses = 4096
+
+// keyCache remembers the outcome of verifying plaintext keys against slow hashes, by the
+// SHA-256 hash of the key: the stored hash a key matched, or that it matched none. Only
+// the maxKeyMisses most recently used misses are kept, so that random keys cannot grow it.
+type keyCache struct {
+	mu      sync.Mutex
+	matched map[string]string        // HashAPIKey(key) -> stored hash
+	misses  map[string]*list.Element // HashAPIKey(key) -> element of order
+	order   *list.List               // Of the misses, least recently used first
+}
+
+func newKeyCache() *keyCache {
+	return &keyCache{matched: make(map[string]string), misses: make(map[string]*list.Element), order: list.New()}
+}
+
+// lookup returns the stored hash the key of cacheKey matched and whether it matched one,
+// and whether the key was verified before at all.
+func (k *keyCache) lookup(cacheKey string) (stored string, found bool, cached bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if stored, ok := k.matched[cacheKey]; ok {
+		return stored, true, true
+	}
+	if elem, ok := k.misses[cacheKey]; ok {
+		k.order.MoveToBack(elem)
+		return "", false, true
+	}
+	return "", false, false
+}
+
+// add remembers the outcome of verifying the key of cacheKey.
+func (k *keyCache) add(cacheKey string, stored string, found bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if found {
+		k.matched[cacheKey] = stored
+		return
+	}
+	if _, ok := k.misses[cacheKey]; ok {
+		return
+	}
+	k.misses[cacheKey] = k.order.PushBack(cacheKey)
+	if k.order.Len() > maxKeyMisses {
+		oldest := k.order.Front()
+		k.order.Remove(oldest)
+		delete(k.misses, oldest.Value.(string))
+	}
+}
+
+// forget drops the keys that matched the stored hash, e.g. once it is revoked.
+func (k *keyCache) forget(stored string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for cacheKey, hash := range k.matched {
+		if hash == stored {
+			delete(k.matched, cacheKey)
+		}
+	}
+}
+
+// validateStoredKeyHash checks that a key hash of the configuration is a hash of the
+// algorithm. SHA-256 hashes are compared as strings and not checked.
+func validateStoredKeyHash(algorithm string, stored string) error {
+	switch algorithm {
+	case "", HashAlgorithmSHA256:
+		return nil
+	case HashAlgorithmBcrypt:
+		if _, err := bcrypt.Cost([]byte(stored)); err != nil {
+			return fmt.Errorf("not a bcrypt hash: %w", err)
+		}
+		return nil
+	case HashAlgorithmArgon2id:
+		_, _, _, err := parseArgon2id(stored)
+		return err
+	default:
+		return ValidateHashAlgorithm(algorithm)
+	}
+}
+
+// argon2idParams are the cost parameters of an argon2id hash.
+type argon2idParams struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// parseArgon2id parses an argon2id PHC string into its parameters, salt and hash.
+func parseArgon2id(stored string) (argon2idParams, []byte, []byte, error) {
+	var params argon2idParams
+	invalid := func(reason string) (argon2idParams, []byte, []byte, error) {
+		return params, nil, nil, fmt.Errorf("not an argon2id PHC string: %s", reason)
+	}
+
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != HashAlgorithmArgon2id {
+		return invalid("expected $argon2id$v=...$m=...,t=...,p=...$<salt>$<hash>")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return invalid(fmt.Sprintf("unsupported version '%s'", parts[2]))
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return invalid(fmt.Sprintf("invalid parameters '%s'", parts[3]))
+	}
+	if params.memory == 0 || params.time == 0 || params.threads == 0 {
+		return invalid(fmt.Sprintf("invalid parameters '%s'", parts[3]))
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return invalid("invalid salt encoding")
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(hash) == 0 {
+		return invalid("invalid hash encoding")
+	}
+	return params, salt, hash, nil
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hashes of the key "test-key-user1", as stored in a configuration.
+var knownKeyHashes = map[string]string{
+	HashAlgorithmSHA256:   "002d3590657193543ca073cbf1f43b51963e3478137a73c150782522cae93875",
+	HashAlgorithmBcrypt:   "$2a$04$rLfnOvRVB81G7dz8wBNOIe2V4ZxM.UgvoD0GbWq8KqIuh4iukbrKq",
+	HashAlgorithmArgon2id: "$argon2id$v=19$m=1024,t=1,p=1$MDEyMzQ1Njc4OWFiY2RlZg$VDhG9BlzzJG312K5cvPiSkLrrg7oiIhFJXm3jqHQO5M",
+}
+
+func TestVerifyAPIKeyWithKnownHashes(t *testing.T) {
+	for algorithm, stored := range knownKeyHashes {
+		if ok, err := VerifyAPIKey(algorithm, "test-key-user1", stored); err != nil || !ok {
+			t.Errorf("%s: VerifyAPIKey of the right key = %v, %v", algorithm, ok, err)
+		}
+		if ok, err := VerifyAPIKey(algorithm, "test-key-user2", stored); err != nil || ok {
+			t.Errorf("%s: VerifyAPIKey of a wrong key = %v, %v", algorithm, ok, err)
+		}
+
+		hash, err := HashAPIKeyWith(algorithm, "test-key-user1")
+		if err != nil {
+			t.Fatalf("%s: HashAPIKeyWith: %v", algorithm, err)
+		}
+		if ok, err := VerifyAPIKey(algorithm, "test-key-user1", hash); err != nil || !ok {
+			t.Errorf("%s: VerifyAPIKey of a new hash %q = %v, %v", algorithm, hash, ok, err)
+		}
+	}
+
+	if _, err := VerifyAPIKey(HashAlgorithmArgon2id, "test-key-user1", knownKeyHashes[HashAlgorithmBcrypt]); err == nil {
+		t.Error("VerifyAPIKey accepted a bcrypt hash as argon2id")
+	}
+}
+
+func TestUpdateLoadsKeysHashedWithConfiguredAlgorithm(t *testing.T) {
+	for algorithm, stored := range knownKeyHashes {
+		path := writeYaml(t, "server:\n  hash_algorithm: "+algorithm+"\nusers:\n  user1:\n    keys: ['"+stored+"']\n")
+		cfg, err := NewYamlConfig(path, zap.NewNop())
+		if err != nil {
+			t.Fatalf("%s: %v", algorithm, err)
+		}
+		for range 2 { // The second lookup of slow hashes is answered from the cache
+			if userID, err := cfg.GetUserIDByKey("test-key-user1"); err != nil || userID != "user1" {
+				t.Errorf("%s: GetUserIDByKey = %q, %v; want user1", algorithm, userID, err)
+			}
+		}
+		if userID, err := cfg.GetUserIDByKey("test-key-user2"); err != nil || userID != "" {
+			t.Errorf("%s: GetUserIDByKey of an unknown key = %q, %v", algorithm, userID, err)
+		}
+	}
+
+	path := writeYaml(t, "server:\n  hash_algorithm: argon2id\nusers:\n  user1:\n    keys: ['"+knownKeyHashes[HashAlgorithmSHA256]+"']\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "user 'user1': keys: not an argon2id PHC string") {
+		t.Fatalf("Expected a sha256 key to be rejected with hash_algorithm argon2id, got: %v", err)
+	}
+	path = writeYaml(t, "server:\n  hash_algorithm: md5\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "server.hash_algorithm") {
+		t.Fatalf("Expected an unknown hash algorithm to be rejected, got: %v", err)
+	}
+}
+
+// countVerifications counts the slow hashes computed until the end of the test.
+func countVerifications(t *testing.T) *atomic.Int32 {
+	var count atomic.Int32
+	t.Cleanup(func() { verifyAPIKey = VerifyAPIKey })
+	verifyAPIKey = func(algorithm string, key string, stored string) (bool, error) {
+		count.Add(1)
+		return VerifyAPIKey(algorithm, key, stored)
+	}
+	return &count
+}
+
+func TestUnknownKeysCostAtMostOneSlowHash(t *testing.T) {
+	config := "server:\n  hash_algorithm: bcrypt\nusers:\n"
+	for _, user := range []string{"a", "b", "c"} {
+		hash, err := bcrypt.GenerateFromPassword([]byte("gw_"+user+"_secret"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config += fmt.Sprintf("  %s:\n    keys: [{hash: '%s', prefix: gw_%s_}]\n", user, hash, user)
+	}
+	cfg, err := NewYamlConfig(writeYaml(t, config), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := countVerifications(t)
+
+	for _, tc := range []struct {
+		key    string
+		wantID string
+		cost   int32 // Slow hashes computed for the lookup
+	}{
+		{"gw_b_wrong", "", 1}, // Only the hash of b
+		{"gw_b_wrong", "", 0}, // The miss is remembered
+		{"random", "", 0},     // No prefix matches
+		{"gw_c_secret", "c", 1},
+		{"gw_c_secret", "c", 0},
+	} {
+		before := count.Load()
+		if userID, err := cfg.GetUserIDByKey(tc.key); userID != tc.wantID || err != nil {
+			t.Fatalf("GetUserIDByKey(%s) = %q, %v; want %q", tc.key, userID, err, tc.wantID)
+		}
+		if cost := count.Load() - before; cost != tc.cost {
+			t.Errorf("GetUserIDByKey(%s) computed %d slow hashes, want %d", tc.key, cost, tc.cost)
+		}
+	}
+}
+
+func TestUnknownKeyMissesAreBounded(t *testing.T) {
+	cache := newKeyCache()
+	for i := range maxKeyMisses + 10 {
+		cache.add(fmt.Sprint(i), "", false)
+	}
+	if len(cache.misses) != maxKeyMisses || cache.order.Len() != maxKeyMisses {
+		t.Fatalf("%d misses kept, want %d", len(cache.misses), maxKeyMisses)
+	}
+	if _, _, cached := cache.lookup("0"); cached {
+		t.Error("The least recently used miss was kept")
+	}
+	if _, _, cached := cache.lookup(fmt.Sprint(maxKeyMisses + 9)); !cached {
+		t.Error("The last miss was dropped")
+	}
+}
+
+func TestSlowKeyVerificationsAreBounded(t *testing.T) {
+	cfg, err := NewYamlConfig(writeYaml(t, "server:\n  hash_algorithm: bcrypt\nusers:\n  user1:\n    keys: ['"+knownKeyHashes[HashAlgorithmBcrypt]+"']\n"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var running, peak atomic.Int32
+	t.Cleanup(func() { verifyAPIKey = VerifyAPIKey })
+	verifyAPIKey = func(algorithm string, key string, stored string) (bool, error) {
+		now := running.Add(1)
+		for old := peak.Load(); now > old && !peak.CompareAndSwap(old, now); old = peak.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return false, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range 4 * cap(keyVerifications) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg.GetUserIDByKey(fmt.Sprintf("random-%d", i))
+		}()
+	}
+	wg.Wait()
+	if int(peak.Load()) > cap(keyVerifications) {
+		t.Errorf("%d slow hashes ran at once, want at most %d", peak.Load(), cap(keyVerifications))
+	}
+}
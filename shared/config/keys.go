@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrKeyExpired is returned when looking up an API key outside its validity window:
+// before its not_before or from its expires_at timestamp.
+var ErrKeyExpired = errors.New("API key expired or not yet valid")
+
+// yamlKey is an entry of users.<id>.keys: either a key hash, or a mapping of the hash,
+// the RFC3339 timestamps bounding its validity and the prefix of the key.
+type yamlKey struct {
+	Hash      string `yaml:"hash"`
+	NotBefore string `yaml:"not_before"` // Valid from then on, e.g. "2025-01-01T00:00:00Z"
+	ExpiresAt string `yaml:"expires_at"` // Invalid from then on
+	Prefix    string `yaml:"prefix"`     // First characters of the key, e.g. "gw_3f9a", stored in clear
+}
+
+// UnmarshalYAML accepts a plain key hash as well as a mapping.
+func (k *yamlKey) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&k.Hash)
+	}
+	type plain yamlKey
+	return node.Decode((*plain)(k))
+}
+
+// userKey is a key hash of a user and its validity window.
+type userKey struct {
+	userID    string
+	notBefore time.Time // Zero if valid from the start
+	expiresAt time.Time // Zero if valid forever
+	prefix    string    // Keys not starting with it are not verified against the hash
+}
+
+// newUserKey parses the validity window of a key of the user.
+func newUserKey(userID string, key yamlKey) (userKey, error) {
+	uk := userKey{userID: userID, prefix: key.Prefix}
+	if key.Hash == "" {
+		return uk, errors.New("key hash is required")
+	}
+	var err error
+	if key.NotBefore != "" {
+		if uk.notBefore, err = time.Parse(time.RFC3339, key.NotBefore); err != nil {
+			return uk, fmt.Errorf("invalid not_before '%s', must be an RFC3339 timestamp", key.NotBefore)
+		}
+	}
+	if key.ExpiresAt != "" {
+		if uk.expiresAt, err = time.Parse(time.RFC3339, key.ExpiresAt); err != nil {
+			return uk, fmt.Errorf("invalid expires_at '%s', must be an RFC3339 timestamp", key.ExpiresAt)
+		}
+	}
+	if !uk.notBefore.IsZero() && !uk.expiresAt.IsZero() && !uk.expiresAt.After(uk.notBefore) {
+		return uk, fmt.Errorf("expires_at '%s' is not after not_before '%s'", key.ExpiresAt, key.NotBefore)
+	}
+	return uk, nil
+}
+
+// keyPrefix returns the prefix of the key, for matchKeyHash.
+func keyPrefix(k userKey) string {
+	return k.prefix
+}
+
+// validAt returns ErrKeyExpired if the key is not valid at now.
+func (k userKey) validAt(now time.Time) error {
+	if (!k.notBefore.IsZero() && now.Before(k.notBefore)) || (!k.expiresAt.IsZero() && !now.Before(k.expiresAt)) {
+		return ErrKeyExpired
+	}
+	return nil
+}
+
+// KeyRevoker is implemented by configurations whose API keys can be revoked at runtime,
+// e.g. by the admin API of the gateway.
+type KeyRevoker interface {
+	// RevokeKey stops accepting the key stored as keyHash, as written in users.<id>.keys,
+	// and returns the ID of its user. It returns ErrNotFound if no user has the key.
+	RevokeKey(keyHash string) (userID string, err error)
+}
+
+var _ KeyRevoker = (*YamlConfig)(nil)
+
+// RevokeKey revokes a key of a user. The key stays revoked until the gateway restarts,
+// even if the file still lists it when it is loaded again, so it should be removed from
+// the file too. Sessions opened with the key are left to the caller.
+func (c *YamlConfig) RevokeKey(keyHash string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	uk, exists := c.userAuthKeys[keyHash]
+	if !exists {
+		return "", ErrNotFound
+	}
+	delete(c.userAuthKeys, keyHash)
+	if c.revokedKeys == nil {
+		c.revokedKeys = make(map[string]bool)
+	}
+	c.revokedKeys[keyHash] = true
+	c.keyCache.forget(keyHash)
+	c.logger.Info("API key revoked at runtime", zap.String("userID", uk.userID))
+	c.recordAudit(AuditKeyRevoked, map[string]any{"userID": uk.userID, "keyHash": keyHash})
+	return uk.userID, nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestKeyValidityWindow(t *testing.T) {
+	path := writeYaml(t, `users:
+  unbounded:
+    keys: ['`+HashAPIKey("key-unbounded")+`']
+  current:
+    keys:
+      - hash: `+HashAPIKey("key-current")+`
+        not_before: 2000-01-01T00:00:00Z
+        expires_at: 2999-01-01T00:00:00Z
+  expired:
+    keys:
+      - hash: `+HashAPIKey("key-expired")+`
+        expires_at: 2001-01-01T00:00:00Z
+  future:
+    keys:
+      - hash: `+HashAPIKey("key-future")+`
+        not_before: 2999-01-01T00:00:00+02:00
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		key     string
+		wantID  string
+		wantErr error
+	}{
+		{"key-unbounded", "unbounded", nil},
+		{"key-current", "current", nil},
+		{"key-expired", "", ErrKeyExpired},
+		{"key-future", "", ErrKeyExpired},
+		{"key-unknown", "", nil},
+	} {
+		if userID, err := cfg.GetUserIDByKeyHash(HashAPIKey(tc.key)); userID != tc.wantID || !errors.Is(err, tc.wantErr) {
+			t.Errorf("GetUserIDByKeyHash(%s) = %q, %v; want %q, %v", tc.key, userID, err, tc.wantID, tc.wantErr)
+		}
+		if userID, err := cfg.GetUserIDByKey(tc.key); userID != tc.wantID || !errors.Is(err, tc.wantErr) {
+			t.Errorf("GetUserIDByKey(%s) = %q, %v; want %q, %v", tc.key, userID, err, tc.wantID, tc.wantErr)
+		}
+	}
+}
+
+func TestInvalidKeyValidityWindowRejected(t *testing.T) {
+	for _, tc := range []struct {
+		key  string
+		want string
+	}{
+		{"{hash: abc, expires_at: tomorrow}", "invalid expires_at 'tomorrow'"},
+		{"{hash: abc, not_before: 2025-01-01}", "invalid not_before '2025-01-01'"},
+		{"{hash: abc, not_before: 2025-02-01T00:00:00Z, expires_at: 2025-01-01T00:00:00Z}", "expires_at '2025-01-01T00:00:00Z' is not after not_before"},
+		{"{expires_at: 2025-01-01T00:00:00Z}", "key hash is required"},
+	} {
+		path := writeYaml(t, "users:\n  u1:\n    keys: ["+tc.key+"]\n")
+		if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "user 'u1': keys: "+tc.want) {
+			t.Errorf("Key %s: expected an error containing %q, got: %v", tc.key, tc.want, err)
+		}
+	}
+}
+
+func TestRevokeKey(t *testing.T) {
+	path := writeYaml(t, `users:
+  alice:
+    keys: ['`+HashAPIKey("key-old")+`', '`+HashAPIKey("key-new")+`']
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if userID, err := cfg.RevokeKey(HashAPIKey("key-old")); userID != "alice" || err != nil {
+		t.Fatalf("RevokeKey = %q, %v; want alice", userID, err)
+	}
+	if _, err := cfg.RevokeKey(HashAPIKey("key-old")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Revoking the key again: got %v, want ErrNotFound", err)
+	}
+	// Loading the file again, which still lists the key, does not bring it back
+	if err := cfg.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if userID, err := cfg.GetUserIDByKey("key-old"); userID != "" || err != nil {
+		t.Errorf("GetUserIDByKey(revoked) = %q, %v; want no user", userID, err)
+	}
+	if userID, err := cfg.GetUserIDByKeyHash(HashAPIKey("key-old")); userID != "" || err != nil {
+		t.Errorf("GetUserIDByKeyHash(revoked) = %q, %v; want no user", userID, err)
+	}
+	if userID, err := cfg.GetUserIDByKey("key-new"); userID != "alice" || err != nil {
+		t.Errorf("GetUserIDByKey(other key) = %q, %v; want alice", userID, err)
+	}
+}
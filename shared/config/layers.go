@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// NewYamlConfigLayered creates a file-based configuration from several files, e.g. a
+// shared base file followed by the overlay of an environment. Each file may be YAML,
+// JSON or TOML (see FileFormat); later files are merged over earlier ones as described
+// by MergeConfigs before the result is loaded like a single file. All files are watched
+// by Watch.
+func NewYamlConfigLayered(paths []string, logger *zap.Logger) (*YamlConfig, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no configuration files given")
+	}
+	if logger == nil {
+		var err error
+		logger, err = zap.NewProduction()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := newYamlConfig(paths[0], logger)
+	config.configPaths = append([]string(nil), paths...)
+	if err := config.Update(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// MergeConfigs merges YAML configuration documents, later documents over earlier ones:
+//   - mappings are merged key by key, recursively, so an overlay only needs the keys it
+//     changes, e.g. backends.search.timeout keeps the url of backends.search;
+//   - scalars and sequences replace the earlier value as a whole: an overlay listing
+//     ssl.acme_domains replaces the domains of the base file, and an explicit empty
+//     sequence (acme_domains: []) leaves none;
+//   - a key absent from an overlay, or an empty mapping ({}), keeps the earlier value;
+//   - a null value (key: null, key: ~ or a key without a value) removes the key, e.g.
+//     backends.legacy: null drops a backend of the base file, so the defaults apply to
+//     a removed setting;
+//   - a value of another kind than the earlier one, e.g. a mapping over a scalar,
+//     replaces it.
+//
+// Environment variables are expanded after merging, so an overlay may replace a value
+// holding a placeholder that is not set in its environment. Anchors and aliases are
+// resolved within each document.
+func MergeConfigs(docs ...[]byte) ([]byte, error) {
+	var merged *yaml.Node
+	for i, data := range docs {
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		if len(root.Content) == 0 {
+			continue // Empty document
+		}
+		node := resolveAliases(root.Content[0])
+		if node.Kind != yaml.MappingNode && !isNull(node) {
+			return nil, fmt.Errorf("document %d: not a mapping", i+1)
+		}
+		if merged == nil {
+			merged = node
+		} else {
+			merged = mergeNodes(merged, node)
+		}
+	}
+	if merged == nil || isNull(merged) {
+		return nil, nil
+	}
+	return yaml.Marshal(merged)
+}
+
+// readConfig reads the configuration files of c, merged if there are several.
+func (c *YamlConfig) readConfig() ([]byte, error) {
+	if len(c.configPaths) == 1 {
+		return readConfigFile(c.configPaths[0])
+	}
+	return readLayeredConfig(c.configPaths)
+}
+
+// readLayeredConfig reads the configuration files at paths, converted to YAML like
+// readConfigFile, and merges them with MergeConfigs.
+func readLayeredConfig(paths []string) ([]byte, error) {
+	docs := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		data, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, data)
+	}
+	merged, err := MergeConfigs(docs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %v: %w", paths, err)
+	}
+	return merged, nil
+}
+
+// mergeNodes merges overlay over base as described by MergeConfigs, changing base.
+func mergeNodes(base *yaml.Node, overlay *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		if isNull(value) {
+			setMappingValue(base, key.Value, nil)
+			continue
+		}
+		if existing := mappingValue(base, key.Value); existing != nil {
+			setMappingValue(base, key.Value, mergeNodes(existing, value))
+		} else {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+	return base
+}
+
+// isNull reports whether node is a null scalar.
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// resolveAliases returns node with its aliases replaced by copies of the nodes they
+// refer to, so that merged documents do not refer to anchors of other documents.
+func resolveAliases(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return resolveAliases(node.Alias)
+	}
+	resolved := *node
+	resolved.Anchor = ""
+	if len(node.Content) > 0 {
+		resolved.Content = make([]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			resolved.Content[i] = resolveAliases(child)
+		}
+	}
+	return &resolved
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// writeLayer writes a configuration file named name into dir.
+func writeLayer(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const layersBase = `
+server:
+  name: base
+  address: ":8080"
+  log_level: info
+  ssl:
+    enabled: false
+    mode: acme
+    acme_domains: [a.example.com, b.example.com]
+    acme_email: ops@example.com
+backends:
+  search:
+    url: http://search.internal/sse
+    bearer: base-token
+    timeout: 30s
+    retry:
+      codes: [-32001]
+      attempts: 2
+  legacy:
+    url: http://legacy.internal/sse
+`
+
+func TestYamlConfigLayeredMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := writeLayer(t, dir, "base.yaml", layersBase)
+	overlay := writeLayer(t, dir, "prod.yaml", `
+server:
+  name: prod         # Scalars replace
+  ssl:
+    acme_domains: [gw.example.com] # Sequences replace as a whole
+backends:
+  search:
+    timeout: 2m      # Other fields of the backend are kept
+    retry:
+      codes: [-32002]
+  legacy:            # Null removes the backend
+  reports:
+    url: http://reports.internal/sse
+`)
+
+	cfg, err := NewYamlConfigLayered([]string{base, overlay}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := cfg.ServerName(); name != "prod" {
+		t.Errorf("ServerName = %q, want the overlay's", name)
+	}
+	if address, _ := cfg.ListenAddr(); address != ":8080" {
+		t.Errorf("ListenAddr = %q, want the base file's", address)
+	}
+	if domains, _ := cfg.SSLAcmeDomains(); !slices.Equal(domains, []string{"gw.example.com"}) {
+		t.Errorf("SSLAcmeDomains = %v, want the overlay's only", domains)
+	}
+	if email, _ := cfg.SSLAcmeEmail(); email != "ops@example.com" {
+		t.Errorf("SSLAcmeEmail = %q, want the base file's", email)
+	}
+
+	search, err := cfg.GetBackend("search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if search.URL != "http://search.internal/sse" || search.Bearer != "base-token" {
+		t.Errorf("search backend = %+v, want url and bearer of the base file", search)
+	}
+	if search.Timeout != 2*time.Minute {
+		t.Errorf("search timeout = %v, want the overlay's", search.Timeout)
+	}
+	if !slices.Equal(search.RetryCodes, []int{-32002}) || search.RetryAttempts != 2 {
+		t.Errorf("search retry = %v x%d, want the overlay's codes and the base file's attempts", search.RetryCodes, search.RetryAttempts)
+	}
+	if ids, _ := cfg.BackendIDs(); !slices.Equal(ids, []string{"reports", "search"}) {
+		t.Errorf("BackendIDs = %v, want legacy removed and reports added", ids)
+	}
+	if _, err := cfg.GetBackend("legacy"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBackend(legacy) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestYamlConfigLayeredEmptyAndAbsent(t *testing.T) {
+	dir := t.TempDir()
+	base := writeLayer(t, dir, "base.yaml", layersBase)
+
+	tests := []struct {
+		name    string
+		overlay string
+		want    []string
+	}{
+		{"absent key keeps", "server:\n  ssl:\n    mode: acme\n", []string{"a.example.com", "b.example.com"}},
+		{"empty mapping keeps", "server:\n  ssl: {}\n", []string{"a.example.com", "b.example.com"}},
+		{"empty sequence clears", "server:\n  ssl:\n    acme_domains: []\n", []string{}},
+		{"null removes", "server:\n  ssl:\n    acme_domains: ~\n", []string{}},
+		{"empty file keeps", "", []string{"a.example.com", "b.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlay := writeLayer(t, t.TempDir(), "overlay.yaml", tt.overlay)
+			cfg, err := NewYamlConfigLayered([]string{base, overlay}, zap.NewNop())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if domains, _ := cfg.SSLAcmeDomains(); !slices.Equal(domains, tt.want) {
+				t.Errorf("SSLAcmeDomains = %v, want %v", domains, tt.want)
+			}
+		})
+	}
+}
+
+func TestYamlConfigLayeredFormatsAndEnv(t *testing.T) {
+	t.Setenv("LAYERS_TEST_TOKEN", "from-env")
+	dir := t.TempDir()
+	base := writeLayer(t, dir, "base.yaml", layersBase)
+	// A JSON overlay setting a placeholder and a TOML one after it
+	overlay := writeLayer(t, dir, "overlay.json", `{"backends": {"search": {"bearer": "${LAYERS_TEST_TOKEN}"}}}`)
+	local := writeLayer(t, dir, "local.toml", "[server]\nname = \"local\"\n")
+
+	cfg, err := NewYamlConfigLayered([]string{base, overlay, local}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := cfg.ServerName(); name != "local" {
+		t.Errorf("ServerName = %q, want the last file's", name)
+	}
+	if search, err := cfg.GetBackend("search"); err != nil || search.Bearer != "from-env" || search.URL != "http://search.internal/sse" {
+		t.Errorf("search backend = %+v, %v", search, err)
+	}
+}
+
+func TestYamlConfigLayeredErrors(t *testing.T) {
+	dir := t.TempDir()
+	base := writeLayer(t, dir, "base.yaml", layersBase)
+
+	if _, err := NewYamlConfigLayered(nil, zap.NewNop()); err == nil {
+		t.Error("No files were accepted")
+	}
+	if _, err := NewYamlConfigLayered([]string{base, filepath.Join(dir, "missing.yaml")}, zap.NewNop()); err == nil {
+		t.Error("A missing file was accepted")
+	}
+	notMapping := writeLayer(t, dir, "list.yaml", "- a\n- b\n")
+	if _, err := NewYamlConfigLayered([]string{base, notMapping}, zap.NewNop()); err == nil {
+		t.Error("A file that is not a mapping was accepted")
+	}
+	persist := writeLayer(t, dir, "persist.yaml", "server:\n  admin:\n    persist_backends: true\n")
+	if _, err := NewYamlConfigLayered([]string{base, persist}, zap.NewNop()); err == nil {
+		t.Error("persist_backends was accepted with several files")
+	}
+	// Validation applies to the merged configuration
+	noDomains := writeLayer(t, dir, "no-domains.yaml", "server:\n  ssl:\n    enabled: true\n    acme_domains: []\n")
+	if _, err := NewYamlConfigLayered([]string{base, noDomains}, zap.NewNop()); err == nil {
+		t.Error("ACME without domains was accepted")
+	}
+	// An overlay may fix a setting that is invalid on its own in the base file
+	invalid := writeLayer(t, dir, "invalid.yaml", "server:\n  tools_list_deadline: soon\n")
+	fix := writeLayer(t, dir, "fix.yaml", "server:\n  tools_list_deadline: 5s\n")
+	if _, err := NewYamlConfigLayered([]string{invalid, fix}, zap.NewNop()); err != nil {
+		t.Errorf("Overlay fixing a setting: %v", err)
+	}
+}
+
+func TestMergeConfigsAliases(t *testing.T) {
+	merged, err := MergeConfigs(
+		[]byte("defaults: &defaults\n  timeout: 30s\nbackends:\n  a: *defaults\n  b: *defaults\n"),
+		[]byte("backends:\n  a:\n    timeout: 1m\n"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "defaults:\n    timeout: 30s\nbackends:\n    a:\n        timeout: 1m\n    b:\n        timeout: 30s\n"
+	if string(merged) != want {
+		t.Errorf("MergeConfigs =\n%s\nwant\n%s", merged, want)
+	}
+}
+
+func TestWatchReloadsLayeredFiles(t *testing.T) {
+	watchDebounce = 50 * time.Millisecond
+	t.Cleanup(func() { watchDebounce = 500 * time.Millisecond })
+
+	base := writeYaml(t, "server:\n  name: base\n")
+	overlay := writeLayer(t, t.TempDir(), "overlay.yaml", "server:\n  address: \":9090\"\n")
+	cfg, err := NewYamlConfigLayered([]string{base, overlay}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cfg.Watch(ctx)
+	time.Sleep(50 * time.Millisecond) // Let the watcher start
+
+	if err := os.WriteFile(overlay, []byte("server:\n  name: overlay\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitForServerName(t, cfg, "overlay")
+	if err := os.WriteFile(overlay, []byte(""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitForServerName(t, cfg, "base")
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlURL is an entry of backends.<id>.urls or replicas: either a URL, or a mapping of
+// the URL and its weight.
+type yamlURL struct {
+	URL    string `yaml:"url"`
+	Weight int    `yaml:"weight"` // Share of the requests relative to the other URLs, 1 if 0
+}
+
+// UnmarshalYAML accepts a plain URL as well as a mapping.
+func (u *yamlURL) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&u.URL)
+	}
+	type plain yamlURL
+	return node.Decode((*plain)(u))
+}
+
+// backendURLs returns the URL and the replicas of a backend configured with url and
+// weight, urls or replicas, and the weights of those that have one.
+func backendURLs(url string, weight int, urls, replicas []yamlURL) (string, []string, map[string]int, error) {
+	if len(urls) > 0 {
+		if url != "" || weight != 0 {
+			return "", nil, nil, errors.New("set either url or urls")
+		}
+		url, weight = urls[0].URL, urls[0].Weight
+		replicas = append(append([]yamlURL(nil), urls[1:]...), replicas...)
+	}
+	weights := make(map[string]int)
+	setWeight := func(url string, weight int) error {
+		if weight < 0 {
+			return fmt.Errorf("invalid weight %d of '%s', must not be negative", weight, url)
+		}
+		if weight > 0 {
+			weights[url] = weight
+		}
+		return nil
+	}
+	if err := setWeight(url, weight); err != nil {
+		return "", nil, nil, err
+	}
+	replicaURLs := make([]string, 0, len(replicas))
+	for _, replica := range replicas {
+		if err := setWeight(replica.URL, replica.Weight); err != nil {
+			return "", nil, nil, err
+		}
+		replicaURLs = append(replicaURLs, replica.URL)
+	}
+	if len(weights) == 0 {
+		weights = nil
+	}
+	return url, replicaURLs, weights, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Prefixes of secret references: a setting holding "file:<path>" is read from the file
+// at path, one holding "env:<NAME>" from the environment variable NAME.
+const (
+	SecretFilePrefix = "file:"
+	SecretEnvPrefix  = "env:"
+)
+
+// ResolveSecret returns the secret value refers to: the content of the file of a
+// "file:" reference without surrounding whitespace, such as the trailing newline of a
+// mounted secret, or the value of the variable of an "env:" reference, as looked up by
+// lookupEnv. Any other value is a literal and returned unchanged. A reference to a
+// missing or unreadable file, an unset variable or an empty secret is an error.
+func ResolveSecret(value string, lookupEnv func(string) (string, bool)) (string, error) {
+	if path, ok := strings.CutPrefix(value, SecretFilePrefix); ok {
+		if path == "" {
+			return "", fmt.Errorf("empty file reference")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return "", fmt.Errorf("secret file %s is empty", path)
+		}
+		return secret, nil
+	}
+	if name, ok := strings.CutPrefix(value, SecretEnvPrefix); ok {
+		if name == "" {
+			return "", fmt.Errorf("empty environment variable reference")
+		}
+		secret, ok := lookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		if secret == "" {
+			return "", fmt.Errorf("environment variable %s is empty", name)
+		}
+		return secret, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"TOKEN": "from-env", "EMPTY": ""}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "literal-token", want: "literal-token"},
+		{value: "", want: ""},
+		{value: "file:" + secretFile, want: "from-file"},
+		{value: "env:TOKEN", want: "from-env"},
+		{value: "file:" + filepath.Join(dir, "missing"), wantErr: "failed to read secret"},
+		{value: "file:" + emptyFile, wantErr: "is empty"},
+		{value: "file:", wantErr: "empty file reference"},
+		{value: "env:MISSING", wantErr: "MISSING is not set"},
+		{value: "env:EMPTY", wantErr: "EMPTY is empty"},
+		{value: "env:", wantErr: "empty environment variable reference"},
+	}
+	for _, tt := range tests {
+		got, err := ResolveSecret(tt.value, lookupEnv)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveSecret(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveSecret(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestUpdateResolvesBackendBearer(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "search-token")
+	if err := os.WriteFile(secretFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GW_REPORTS_TOKEN", "env-token")
+
+	cfg, err := NewYamlConfig(writeYaml(t, `
+backends:
+  search:
+    url: http://search/sse
+    bearer: file:`+secretFile+`
+  reports:
+    url: http://reports/sse
+    bearer: env:GW_REPORTS_TOKEN
+  plain:
+    url: http://plain/sse
+    bearer: literal-token
+`), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for backendID, want := range map[string]string{"search": "file-token", "reports": "env-token", "plain": "literal-token"} {
+		backend, err := cfg.GetBackend(backendID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if backend.Bearer != want {
+			t.Errorf("backend %s: Bearer = %q, want %q", backendID, backend.Bearer, want)
+		}
+	}
+
+	// A reference that cannot be resolved fails the load, naming the backend
+	for _, bearer := range []string{"file:" + secretFile + ".missing", "env:GW_UNSET_TOKEN"} {
+		_, err := NewYamlConfig(writeYaml(t, "backends:\n  search:\n    url: http://search/sse\n    bearer: "+bearer+"\n"), zap.NewNop())
+		if err == nil || !strings.Contains(err.Error(), "backend 'search': bearer") {
+			t.Errorf("bearer %s: error = %v, want one naming the backend", bearer, err)
+		}
+	}
+}
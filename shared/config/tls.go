@@ -0,0 +1,85 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Minimum TLS versions of the server, see IConfig.SSLMinVersion.
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// TLSMinVersion returns the crypto/tls version of a minimum TLS version setting, 0 (the
+// crypto/tls default) if it is empty.
+func TLSMinVersion(version string) (uint16, error) {
+	switch version {
+	case "":
+		return 0, nil
+	case TLSVersion12:
+		return tls.VersionTLS12, nil
+	case TLSVersion13:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("TLS version must be %q or %q, got %q", TLSVersion12, TLSVersion13, version)
+	}
+}
+
+// TLSCipherSuiteNames returns the names of the cipher suites that can be configured: the
+// TLS 1.2 suites crypto/tls considers secure. TLS 1.3 suites are not configurable.
+func TLSCipherSuiteNames() []string {
+	var names []string
+	for _, suite := range tls.CipherSuites() {
+		if slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+			names = append(names, suite.Name)
+		}
+	}
+	return names
+}
+
+// TLSCipherSuites returns the IDs of the named cipher suites (see TLSCipherSuiteNames),
+// nil (the crypto/tls defaults) if there are none. An unknown name fails with an error
+// listing the valid ones.
+func TLSCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		var id uint16
+		for _, suite := range tls.CipherSuites() {
+			if suite.Name == name && slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+				id = suite.ID
+			}
+		}
+		if id == 0 {
+			return nil, fmt.Errorf("unknown cipher suite '%s', valid suites are: %s", name, strings.Join(TLSCipherSuiteNames(), ", "))
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ValidateTLSSettings checks a minimum TLS version and cipher suites setting. Cipher
+// suites cannot be set with a minimum version of TLS 1.3, whose suites are fixed, and
+// must include one of the AES-128-GCM suites HTTP/2 requires.
+func ValidateTLSSettings(minVersion string, cipherSuites []string) error {
+	if _, err := TLSMinVersion(minVersion); err != nil {
+		return fmt.Errorf("min_version: %w", err)
+	}
+	ids, err := TLSCipherSuites(cipherSuites)
+	if err != nil {
+		return fmt.Errorf("cipher_suites: %w", err)
+	}
+	if minVersion == TLSVersion13 && len(cipherSuites) > 0 {
+		return errors.New("cipher_suites: only apply to TLS 1.2 and cannot be set with min_version 1.3")
+	}
+	if len(ids) > 0 && !slices.Contains(ids, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) && !slices.Contains(ids, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256) {
+		return errors.New("cipher_suites: must include TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 or TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, required by HTTP/2")
+	}
+	return nil
+}
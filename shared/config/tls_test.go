@@ -0,0 +1,70 @@
+package config
+
+import (
+	"crypto/tls"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestTLSMinVersion(t *testing.T) {
+	for version, want := range map[string]uint16{"": 0, "1.2": tls.VersionTLS12, "1.3": tls.VersionTLS13} {
+		if got, err := TLSMinVersion(version); err != nil || got != want {
+			t.Errorf("TLSMinVersion(%q) = %x, %v; want %x", version, got, err, want)
+		}
+	}
+	if _, err := TLSMinVersion("1.1"); err == nil {
+		t.Error("TLSMinVersion accepted TLS 1.1")
+	}
+}
+
+func TestTLSCipherSuites(t *testing.T) {
+	ids, err := TLSCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"})
+	if err != nil || len(ids) != 2 || ids[0] != tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 || ids[1] != tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256 {
+		t.Errorf("TLSCipherSuites = %x, %v", ids, err)
+	}
+	if ids, err := TLSCipherSuites(nil); err != nil || ids != nil {
+		t.Errorf("TLSCipherSuites(nil) = %x, %v; want the defaults", ids, err)
+	}
+
+	// TLS 1.3 suites are not configurable and insecure ones are not offered
+	for _, name := range []string{"TLS_AES_128_GCM_SHA256", "TLS_RSA_WITH_RC4_128_SHA", "AES128"} {
+		_, err := TLSCipherSuites([]string{name})
+		if err == nil || !strings.Contains(err.Error(), "unknown cipher suite '"+name+"', valid suites are: ") ||
+			!strings.Contains(err.Error(), "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256") {
+			t.Errorf("TLSCipherSuites(%s) = %v, want an error listing the valid suites", name, err)
+		}
+	}
+}
+
+func TestYamlTLSSettings(t *testing.T) {
+	path := writeYaml(t, "server:\n  ssl:\n    min_version: \"1.2\"\n    cipher_suites: [TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256]\n    reload_interval: 30s\n")
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := cfg.SSLMinVersion(); version != TLSVersion12 {
+		t.Errorf("SSLMinVersion = %q", version)
+	}
+	if suites, _ := cfg.SSLCipherSuites(); len(suites) != 1 || suites[0] != "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256" {
+		t.Errorf("SSLCipherSuites = %v", suites)
+	}
+	if interval, _ := cfg.SSLReloadInterval(); interval != 30*time.Second {
+		t.Errorf("SSLReloadInterval = %v", interval)
+	}
+
+	for ssl, want := range map[string]string{
+		`{reload_interval: -1s}`: "invalid server.ssl.reload_interval '-1s'",
+		`{min_version: "1.0"}`:   "server.ssl.min_version: TLS version must be",
+		`{cipher_suites: [RC4]}`: "server.ssl.cipher_suites: unknown cipher suite 'RC4', valid suites are: ",
+		`{min_version: "1.3", cipher_suites: [TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256]}`: "server.ssl.cipher_suites: only apply to TLS 1.2",
+		`{cipher_suites: [TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384]}`:                     "server.ssl.cipher_suites: must include TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 or",
+	} {
+		path := writeYaml(t, "server:\n  ssl: "+ssl+"\n")
+		if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ssl %s: expected an error containing %q, got: %v", ssl, want, err)
+		}
+	}
+}
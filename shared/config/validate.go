@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+)
+
+// Validate checks the loaded configuration for mistakes that would otherwise only show
+// at runtime: backend and replica URLs that are not absolute http(s) URLs (unless the
+// backend runs a command), user keys that are not bcrypt or argon2id hashes when
+// server.hash_algorithm says so, user rate limits without a positive rate, an unknown
+// authorization or SSL mode, mtls authorization without a client CA bundle, missing
+// certificate files in manual SSL mode, missing domains, an invalid email or OCSP
+// stapling in ACME mode, and an unknown TLS version or cipher suite. The returned error
+// lists every problem found, one per line. Update calls it on the settings loaded from
+// the file, which only take effect if they pass.
+func (c *YamlConfig) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.validate()
+}
+
+// validate is Validate for settings not in effect yet, or for callers holding c.mu.
+func (c *yamlSettings) validate() error {
+	var errs []error
+
+	backendIDs := make([]string, 0, len(c.backends))
+	for backendID := range c.backends {
+		backendIDs = append(backendIDs, backendID)
+	}
+	sort.Strings(backendIDs)
+	for _, backendID := range backendIDs {
+		errs = append(errs, validateBackend(backendID, c.backends[backendID])...)
+	}
+
+	for _, keyHash := range slices.Sorted(maps.Keys(c.userAuthKeys)) {
+		if err := validateStoredKeyHash(c.hashAlgorithm, keyHash); err != nil {
+			errs = append(errs, fmt.Errorf("user '%s': keys: %w (server.hash_algorithm is '%s')", c.userAuthKeys[keyHash].userID, err, c.hashAlgorithm))
+		}
+	}
+
+	userIDs := slices.Sorted(maps.Keys(c.userRateLimits))
+	for _, userID := range userIDs {
+		if limit := c.userRateLimits[userID]; limit.RPS <= 0 || limit.Burst < 0 {
+			errs = append(errs, fmt.Errorf("user '%s': rate_limit: rps must be positive and burst not negative, got rps %v and burst %d", userID, limit.RPS, limit.Burst))
+		}
+	}
+
+	switch c.authorization {
+	case "", "users_only", "marked_methods", "none":
+	case "mtls":
+		if !c.sslEnabled || c.sslClientCAFile == "" {
+			errs = append(errs, errors.New("server.authorization: mtls requires server.ssl.enabled and server.ssl.client_ca_file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("server.authorization: unknown value '%s', must be users_only, marked_methods, none or mtls", c.authorization))
+	}
+	if c.sslClientCAFile != "" {
+		if err := validateSSLFile(c.sslClientCAFile); err != nil {
+			errs = append(errs, fmt.Errorf("server.ssl.client_ca_file: %w", err))
+		}
+	}
+
+	switch c.sslMode {
+	case "manual":
+		if c.sslEnabled {
+			if err := validateSSLFile(c.sslCertFile); err != nil {
+				errs = append(errs, fmt.Errorf("server.ssl.cert_file: %w", err))
+			}
+			if err := validateSSLFile(c.sslKeyFile); err != nil {
+				errs = append(errs, fmt.Errorf("server.ssl.key_file: %w", err))
+			}
+		}
+	case "acme":
+		if c.sslEnabled {
+			if len(c.sslAcmeDomains) == 0 {
+				errs = append(errs, errors.New("server.ssl.acme_domains: at least one domain is required in acme mode"))
+			}
+			if addr, err := mail.ParseAddress(c.sslAcmeEmail); err != nil || addr.Address != c.sslAcmeEmail {
+				errs = append(errs, fmt.Errorf("server.ssl.acme_email: '%s' is not a valid email address", c.sslAcmeEmail))
+			}
+			if c.sslOCSPStapling {
+				errs = append(errs, errors.New("server.ssl.ocsp_stapling: only supported in manual mode"))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("server.ssl.mode: unknown value '%s', must be manual or acme", c.sslMode))
+	}
+	if err := ValidateTLSSettings(c.sslMinVersion, c.sslCipherSuites); err != nil {
+		errs = append(errs, fmt.Errorf("server.ssl.%w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateBackend checks that the URL and replicas of a backend not running a command
+// are absolute http(s) URLs.
+func validateBackend(backendID string, backend *Backend) []error {
+	if len(backend.Command) > 0 {
+		return nil
+	}
+	var errs []error
+	if err := validateBackendURL(backend.URL); err != nil {
+		errs = append(errs, fmt.Errorf("backend '%s': %w", backendID, err))
+	}
+	for _, replica := range backend.Replicas {
+		if err := validateBackendURL(replica); err != nil {
+			errs = append(errs, fmt.Errorf("backend '%s': replica: %w", backendID, err))
+		}
+	}
+	return errs
+}
+
+// validateBackendURL checks that rawURL is an absolute http or https URL.
+func validateBackendURL(rawURL string) error {
+	if rawURL == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url '%s': %w", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url '%s': must be an absolute http or https URL", rawURL)
+	}
+	return nil
+}
+
+// validateSSLFile checks that a certificate or key file is set and exists.
+func validateSSLFile(path string) error {
+	if path == "" {
+		return errors.New("required in manual mode")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("'%s' is a directory", path)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestUpdateReportsEveryValidationProblem(t *testing.T) {
+	path := writeYaml(t, `server:
+  authorization: user_only
+  ssl:
+    enabled: true
+    mode: acme
+    acme_email: ops.example.com
+    ocsp_stapling: true
+backends:
+  b1:
+    url: localhost:8080/sse
+  b2:
+    url: ftp://b2/sse
+    replicas: ["/relative"]
+  b3:
+    url: https://b3.example.com/sse
+users:
+  u1:
+    rate_limit: {burst: 5}
+`)
+	_, err := NewYamlConfig(path, zap.NewNop())
+	if err == nil {
+		t.Fatal("Expected the invalid configuration to be rejected")
+	}
+	for _, want := range []string{
+		"backend 'b1': invalid url 'localhost:8080/sse'",
+		"backend 'b2': invalid url 'ftp://b2/sse'",
+		"backend 'b2': replica: invalid url '/relative'",
+		"user 'u1': rate_limit: rps must be positive",
+		"server.authorization: unknown value 'user_only'",
+		"server.ssl.acme_domains: at least one domain is required",
+		"server.ssl.acme_email: 'ops.example.com' is not a valid email address",
+		"server.ssl.ocsp_stapling: only supported in manual mode",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error does not mention %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "b3") {
+		t.Errorf("Error mentions the valid backend b3:\n%v", err)
+	}
+}
+
+func TestValidateManualSSLFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(certFile, []byte("cert"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeYaml(t, "server:\n  ssl:\n    enabled: true\n    cert_file: "+certFile+"\n    key_file: "+filepath.Join(dir, "key.pem")+"\n")
+	_, err := NewYamlConfig(path, zap.NewNop())
+	if err == nil || !strings.Contains(err.Error(), "server.ssl.key_file") || strings.Contains(err.Error(), "server.ssl.cert_file") {
+		t.Fatalf("Expected only the missing key file to be reported, got: %v", err)
+	}
+
+	path = writeYaml(t, "server:\n  ssl:\n    enabled: true\n    mode: manual\n    cert_file: "+certFile+"\n    key_file: "+certFile+"\n")
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Expected the configuration to load, got: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	// Certificate files are only required when SSL is enabled
+	path = writeYaml(t, "server:\n  ssl:\n    enabled: false\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err != nil {
+		t.Fatalf("Expected the configuration without SSL to load, got: %v", err)
+	}
+}
+
+func TestValidateMTLSAuthorization(t *testing.T) {
+	path := writeYaml(t, "server:\n  authorization: mtls\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "server.authorization: mtls requires server.ssl.enabled and server.ssl.client_ca_file") {
+		t.Fatalf("Expected mtls without a client CA bundle to be rejected, got: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(certFile, []byte("cert"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path = writeYaml(t, "server:\n  authorization: mtls\n  ssl:\n    enabled: true\n    cert_file: "+certFile+"\n    key_file: "+certFile+"\n    client_ca_file: "+filepath.Join(dir, "ca.pem")+"\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "server.ssl.client_ca_file") {
+		t.Fatalf("Expected a missing client CA bundle to be rejected, got: %v", err)
+	}
+
+	path = writeYaml(t, "server:\n  authorization: mtls\n  ssl:\n    enabled: true\n    cert_file: "+certFile+"\n    key_file: "+certFile+"\n    client_ca_file: "+certFile+`
+users:
+  svc-a:
+    client_certs: [svc-a.mesh.local, "spiffe://mesh/ns/default/sa/svc-a"]
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Expected the configuration to load, got: %v", err)
+	}
+	if auth, _ := cfg.AuthorizationType(); auth != AuthorizedByClientCert {
+		t.Errorf("AuthorizationType = %v", auth)
+	}
+	if caFile, _ := cfg.SSLClientCAFile(); caFile != certFile {
+		t.Errorf("SSLClientCAFile = %q", caFile)
+	}
+	for _, identity := range []string{"svc-a.mesh.local", "spiffe://mesh/ns/default/sa/svc-a"} {
+		if userID, _ := cfg.GetUserIDByCertIdentity(identity); userID != "svc-a" {
+			t.Errorf("GetUserIDByCertIdentity(%s) = %q", identity, userID)
+		}
+	}
+
+	path = writeYaml(t, "users:\n  u1:\n    client_certs: [shared]\n  u2:\n    client_certs: [shared]\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "client_certs: 'shared' is already mapped to user") {
+		t.Fatalf("Expected an identity of several users to be rejected, got: %v", err)
+	}
+}
+
+func TestUpdateKeepsConfigurationFailingValidation(t *testing.T) {
+	path := writeYaml(t, `server:
+  name: first
+backends:
+  b1:
+    url: https://b1.example.com/sse
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(`server:
+  name: second
+backends:
+  b2:
+    url: ftp://b2/sse
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Update(); err == nil || !strings.Contains(err.Error(), "backend 'b2': invalid url") {
+		t.Fatalf("Expected the invalid backend to be reported, got %v", err)
+	}
+	if name, _ := cfg.ServerName(); name != "first" {
+		t.Errorf("ServerName = %q, want the previous name", name)
+	}
+	if _, err := cfg.GetBackend("b2"); err == nil {
+		t.Error("The backend failing validation was installed")
+	}
+	if _, err := cfg.GetBackend("b1"); err != nil {
+		t.Errorf("The previous backend was removed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("The configuration in effect should still be valid, got %v", err)
+	}
+}
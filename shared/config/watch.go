@@ -0,0 +1,76 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// watchDebounce is how long Watch waits after the last change of the file before
+// reloading it, so that the several writes of an editor save cause a single reload.
+var watchDebounce = 500 * time.Millisecond
+
+// Watch reloads the configuration whenever the YAML file, or any of the files of a
+// layered configuration, is written or replaced, until ctx is done. The directory of
+// each file is watched rather than the file itself, so that editors and deployment
+// tools replacing the file by renaming a new one over it are noticed too. A file that
+// fails to load is logged and ignored: the previous configuration stays in effect until
+// the file is fixed. Watch returns nil when ctx
+// is done, or an error if the watcher cannot be set up or fails.
+func (c *YamlConfig) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	paths := make(map[string]bool, len(c.configPaths))
+	for _, path := range c.configPaths {
+		path = filepath.Clean(path)
+		paths[path] = true
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return err
+		}
+	}
+	c.logger.Info("Watching configuration file for changes", zap.Strings("paths", c.configPaths))
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if paths[filepath.Clean(event.Name)] && event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				debounce.Reset(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			c.logger.Error("Configuration file watcher failed", zap.Strings("paths", c.configPaths), zap.Error(err))
+			return err
+		case <-debounce.C:
+			c.reload()
+		}
+	}
+}
+
+// reload applies the YAML file if it loads without errors. Update reads the file once and
+// replaces the configuration only once the whole file has been parsed and validated, so
+// an invalid file leaves the previous configuration in effect.
+func (c *YamlConfig) reload() {
+	if err := c.Update(); err != nil {
+		c.logger.Error("Changed configuration file is invalid, keeping the previous configuration", zap.Strings("paths", c.configPaths), zap.Error(err))
+		c.recordAudit(AuditConfigRejected, map[string]any{"paths": c.configPaths, "error": err.Error()})
+		return
+	}
+	c.recordAudit(AuditConfigReloaded, map[string]any{"paths": c.configPaths})
+}
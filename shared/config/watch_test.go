@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// waitForServerName waits until the configuration reports the server name.
+func waitForServerName(t *testing.T, cfg *YamlConfig, name string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := cfg.ServerName()
+		if got == name {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("ServerName = %q, want %q", got, name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchReloadsChangedFile(t *testing.T) {
+	watchDebounce = 50 * time.Millisecond
+	t.Cleanup(func() { watchDebounce = 500 * time.Millisecond })
+
+	path := writeYaml(t, "server:\n  name: first\n")
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	watchErr := make(chan error, 1)
+	go func() { watchErr <- cfg.Watch(ctx) }()
+	time.Sleep(50 * time.Millisecond) // Let the watcher start
+
+	if err := os.WriteFile(path, []byte("server:\n  name: second\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitForServerName(t, cfg, "second")
+
+	// Replacing the file by renaming a new one over it, as editors do
+	tmp := filepath.Join(filepath.Dir(path), "config.yaml.tmp")
+	if err := os.WriteFile(tmp, []byte("server:\n  name: third\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	waitForServerName(t, cfg, "third")
+
+	// An invalid file keeps the previous configuration, including the settings before
+	// the invalid one
+	if err := os.WriteFile(path, []byte("server:\n  name: fourth\n  tools_list_deadline: soon\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	waitForServerName(t, cfg, "third")
+	if err := os.WriteFile(path, []byte("server:\n  name: [broken\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	waitForServerName(t, cfg, "third")
+
+	cancel()
+	select {
+	case err := <-watchErr:
+		if err != nil {
+			t.Fatalf("Watch returned %v after cancellation, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not return after cancellation")
+	}
+}
@@ -3,22 +3,38 @@ package config
 import (
 	"context"
 	"fmt"
+	"maps"
+	"math"
 	"os"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/gate4ai/mcp/shared/audit"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 )
 
 var _ IConfig = (*YamlConfig)(nil)
 
-// YamlConfig implements all configuration interfaces with YAML file-based storage
+// YamlConfig implements all configuration interfaces with file-based storage. The file
+// is YAML, or JSON or TOML with the same keys, depending on its extension (see FileFormat).
 type YamlConfig struct {
-	mu         sync.RWMutex
-	configPath string
-	logger     *zap.Logger
+	mu          sync.RWMutex
+	configPath  string   // The first file, where persist_backends writes
+	configPaths []string // All files, merged in order (see NewYamlConfigLayered)
+	logger      *zap.Logger
+	revokedKeys map[string]bool              // authKeys revoked at runtime, ignored in the file until restart
+	auditLog    atomic.Pointer[audit.Logger] // Records reloads and runtime changes if set
 
-	// Parsed configuration
+	yamlSettings
+}
+
+// yamlSettings is the configuration parsed from the files. Update builds and validates
+// a new one before replacing the current one as a whole, so that a file with an invalid
+// setting leaves the configuration in effect untouched.
+type yamlSettings struct {
 	serverAddress               string
 	serverName                  string
 	serverVersion               string
@@ -26,19 +42,66 @@ type YamlConfig struct {
 	DiscoveringHandlerPathValue string
 	frontendAddressValue        string
 	authorizationType           AuthorizationType
-	userAuthKeys                map[string]string            // authKey -> userID
+	authorization               string // As written in the file, for Validate
+	sseMaxStreams               int
+	sseAllowedOrigins           []string
+	sseQueueSize                int
+	sseQueueWait                time.Duration
+	sseKeepAlive                time.Duration
+	sseDrainTimeout             time.Duration
+	compressionMinSize          int
+	sanitizeInboundText         bool
+	sanitizeOutboundText        bool
+	toolsListDeadline           time.Duration
+	backendHealthInterval       time.Duration
+	metricsLatencyBuckets       []float64
+	metricsVersionLabels        bool
+	metricsEnabled              bool
+	metricsPath                 string
+	tracingEndpoint             string
+	tracingSampleRatio          float64
+	accessLogEnabled            bool
+	accessLogLevel              string
+	accessLogFields             []string
+	logPrivacy                  string
+	idGenerator                 string
+	methodsDeny                 []string
+	methodsAllow                []string
+	prefixNames                 bool
+	nameSeparator               string
+	userAuthKeys                map[string]userKey           // authKey -> user and validity of the key
+	hashAlgorithm               string                       // Of the keys in userAuthKeys
+	keyCache                    *keyCache                    // Of the keys verified against userAuthKeys with a slow hash algorithm
 	userParams                  map[string]map[string]string // userID -> paramName -> paramValue
 	userSubscribes              map[string][]string          // userID -> serverIDs
+	userDefaultBackends         map[string]string            // userID -> serverID
+	userRateLimits              map[string]RateLimit         // userID -> RateLimit, as written in the file
+	userCertIdentities          map[string]string            // Client certificate CN or SAN -> userID
 	backends                    map[string]*Backend          // serverID -> Server
+	a2aAgents                   map[string]A2ACardBaseInfo   // agentName -> card base info
+	a2aArtifactChecksums        bool
+	a2aDetectMimeTypes          bool
+	a2aMaxSessionTasks          int
+	a2aSessionTaskHardLimit     int
+	a2aMaxConcurrentUserTasks   int
+	a2aTaskIDScope              string
+	lintIssues                  []LintIssue // Of the last loaded file, e.g. deprecated fields
+	adminPersistBackends        bool        // Write backends changed with PutBackend and RemoveBackend to the file
+	adminAllowHostAccess        bool        // Let PutBackend run commands, expand variables and read secrets
 
 	// SSL Fields
-	sslEnabled      bool
-	sslMode         string
-	sslCertFile     string
-	sslKeyFile      string
-	sslAcmeDomains  []string
-	sslAcmeEmail    string
-	sslAcmeCacheDir string
+	sslEnabled        bool
+	sslMode           string
+	sslCertFile       string
+	sslKeyFile        string
+	sslAcmeDomains    []string
+	sslAcmeEmail      string
+	sslAcmeCacheDir   string
+	sslClientCAFile   string
+	sslOCSPStapling   bool
+	sslMinVersion     string
+	sslCipherSuites   []string
+	sslReloadInterval time.Duration
 }
 
 // YAML configuration structure matching the required format
@@ -48,36 +111,179 @@ type yamlConfig struct {
 		Name                   string   `yaml:"name"`
 		Version                string   `yaml:"version"`
 		LogLevel               string   `yaml:"log_level"`
+		LogPrivacy             string   `yaml:"log_privacy"`    // "none", "partial" or "strict"
+		IDGenerator            string   `yaml:"id_generator"`   // "random", "uuid" or "ulid"
+		HashAlgorithm          string   `yaml:"hash_algorithm"` // Of users.<id>.keys: "sha256", "bcrypt" or "argon2id"
 		DiscoveringHandlerPath string   `yaml:"info_handler"`
 		FrontendAddress        string   `yaml:"frontend_address"`
-		Authorization          string   `yaml:"authorization"` // Can be "users_only", "marked_methods", or "none"
+		Authorization          string   `yaml:"authorization"` // Can be "users_only", "marked_methods", "none" or "mtls"
 		SSL                    struct { // New SSL section
-			Enabled      bool     `yaml:"enabled"`
-			Mode         string   `yaml:"mode"`           // "manual" or "acme"
-			CertFile     string   `yaml:"cert_file"`      // Path for manual mode
-			KeyFile      string   `yaml:"key_file"`       // Path for manual mode
-			AcmeDomains  []string `yaml:"acme_domains"`   // Domains for ACME
-			AcmeEmail    string   `yaml:"acme_email"`     // Contact email for ACME
-			AcmeCacheDir string   `yaml:"acme_cache_dir"` // Cache directory for ACME
+			Enabled        bool     `yaml:"enabled"`
+			Mode           string   `yaml:"mode"`            // "manual" or "acme"
+			CertFile       string   `yaml:"cert_file"`       // Path for manual mode
+			KeyFile        string   `yaml:"key_file"`        // Path for manual mode
+			AcmeDomains    []string `yaml:"acme_domains"`    // Domains for ACME
+			AcmeEmail      string   `yaml:"acme_email"`      // Contact email for ACME
+			AcmeCacheDir   string   `yaml:"acme_cache_dir"`  // Cache directory for ACME
+			ClientCAFile   string   `yaml:"client_ca_file"`  // PEM CA bundle verifying client certificates
+			OCSPStapling   bool     `yaml:"ocsp_stapling"`   // Staple OCSP responses for cert_file
+			MinVersion     string   `yaml:"min_version"`     // "1.2" or "1.3"
+			CipherSuites   []string `yaml:"cipher_suites"`   // TLS 1.2 suites, e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"
+			ReloadInterval string   `yaml:"reload_interval"` // e.g. "1m", how often cert_file and key_file are checked for changes
 		} `yaml:"ssl"`
+		SSE struct {
+			MaxStreams     int      `yaml:"max_streams"`     // 0 or absent means unlimited
+			AllowedOrigins []string `yaml:"allowed_origins"` // Browser origins allowed to connect
+			QueueSize      int      `yaml:"queue_size"`      // Streams waiting for a slot at max_streams
+			QueueWait      string   `yaml:"queue_wait"`      // e.g. "2s", max wait of a queued stream
+			KeepAlive      string   `yaml:"keepalive"`       // e.g. "15s", interval of keepalive comments on open streams
+			DrainTimeout   string   `yaml:"drain_timeout"`   // e.g. "15s", max wait on shutdown for requests answered on open streams
+		} `yaml:"sse"`
+		Compression struct {
+			MinSize int `yaml:"min_size"` // Bytes from which JSON responses are compressed, negative disables compression
+		} `yaml:"compression"`
+		Sanitize struct {
+			Inbound  bool `yaml:"inbound"`  // Text in requests from clients
+			Outbound bool `yaml:"outbound"` // Text in responses to clients
+		} `yaml:"sanitize"`
+		ToolsListDeadline     string `yaml:"tools_list_deadline"`     // e.g. "2s", empty waits for all backends
+		BackendHealthInterval string `yaml:"backend_health_interval"` // e.g. "30s", how often backends are health checked
+		Metrics               struct {
+			LatencyBuckets []float64 `yaml:"latency_buckets"` // Seconds, ascending
+			VersionLabels  bool      `yaml:"version_labels"`  // Count relays by protocol versions
+			Enabled        bool      `yaml:"enabled"`         // Serve metrics at path
+			Path           string    `yaml:"path"`            // e.g. "/metrics", the default
+		} `yaml:"metrics"`
+		Tracing struct {
+			Endpoint    string   `yaml:"endpoint"`     // e.g. "http://collector:4318", empty disables tracing
+			SampleRatio *float64 `yaml:"sample_ratio"` // 0 to 1, DefaultTracingSampleRatio if absent
+		} `yaml:"tracing"`
+		AccessLog struct {
+			Enabled bool     `yaml:"enabled"` // One entry per answered request and closed SSE stream
+			Level   string   `yaml:"level"`   // e.g. "debug", DefaultAccessLogLevel if empty
+			Fields  []string `yaml:"fields"`  // Logged AccessLogFields, all if empty
+		} `yaml:"access_log"`
+		Methods struct {
+			Deny  []string `yaml:"deny"`  // Rejected for everyone, e.g. "resources/*"
+			Allow []string `yaml:"allow"` // When set, only these are accepted
+		} `yaml:"methods"`
+		Naming struct {
+			Prefix    bool   `yaml:"prefix"`    // List names prefixed with the backend ID
+			Separator string `yaml:"separator"` // e.g. "__", DefaultNameSeparator if empty
+		} `yaml:"naming"`
+		Admin struct {
+			PersistBackends bool `yaml:"persist_backends"`  // Write backends changed at runtime back to the file
+			AllowHostAccess bool `yaml:"allow_host_access"` // Accept stdio backends, placeholders and secret references at runtime
+		} `yaml:"admin"`
 	} `yaml:"server"`
 
 	Users map[string]struct {
-		Keys       []string `yaml:"keys"`
-		Subscribes []string `yaml:"subscribes"`
+		Keys           []yamlKey         `yaml:"keys"` // Hashes, alone or with not_before and expires_at
+		Subscribes     []string          `yaml:"subscribes"`
+		DefaultBackend string            `yaml:"default_backend"`
+		Params         map[string]string `yaml:"params"`       // Values available for argument injection
+		ClientCerts    []string          `yaml:"client_certs"` // CNs or SANs of client certificates authenticating the user
+		RateLimit      struct {
+			RPS   float64 `yaml:"rps"`   // Requests per second across all sessions of the user
+			Burst int     `yaml:"burst"` // Requests at once, defaults to rps rounded up
+		} `yaml:"rate_limit"`
 	} `yaml:"users"`
 
-	Backends map[string]struct {
-		URL    string `yaml:"url"`
-		Bearer string `yaml:"bearer"`
-	} `yaml:"backends"`
+	Backends map[string]yamlBackend `yaml:"backends"`
+
+	A2A struct {
+		Agents map[string]struct {
+			Path             string `yaml:"path"` // Defaults to /.well-known/agent.json
+			Description      string `yaml:"description"`
+			URL              string `yaml:"url"`
+			Version          string `yaml:"version"`
+			DocumentationURL string `yaml:"documentation_url"`
+			Provider         struct {
+				Organization string `yaml:"organization"`
+				URL          string `yaml:"url"`
+			} `yaml:"provider"`
+			Capabilities struct {
+				Streaming              bool `yaml:"streaming"`
+				PushNotifications      bool `yaml:"push_notifications"`
+				StateTransitionHistory bool `yaml:"state_transition_history"`
+			} `yaml:"capabilities"`
+			Skills []struct {
+				ID          string   `yaml:"id"`
+				Name        string   `yaml:"name"`
+				Description string   `yaml:"description"`
+				Tags        []string `yaml:"tags"`
+				Examples    []string `yaml:"examples"`
+				InputModes  []string `yaml:"input_modes"`  // Override the default input modes of the agent
+				OutputModes []string `yaml:"output_modes"` // Override the default output modes of the agent
+			} `yaml:"skills"`
+		} `yaml:"agents"`
+		ArtifactChecksums      bool   `yaml:"artifact_checksums"`        // Add sha256 checksums to artifact metadata
+		DetectMimeTypes        bool   `yaml:"detect_mime_types"`         // Fill in missing MIME types of file artifacts
+		MaxSessionTasks        int    `yaml:"max_session_tasks"`         // Evict terminal tasks of a session beyond this count
+		SessionTaskHardLimit   int    `yaml:"session_task_hard_limit"`   // Reject new tasks of a session at this count
+		TaskIDScope            string `yaml:"task_id_scope"`             // "session", "user" or "global"
+		MaxConcurrentUserTasks int    `yaml:"max_concurrent_user_tasks"` // Reject new tasks of a user with this many running
+	} `yaml:"a2a"`
 }
 
-// NewYamlConfig creates a new YAML-based configuration
-func NewYamlConfig(configPath string, logger *zap.Logger) (*YamlConfig, error) {
+// yamlBackend is a backend in the backends section of the configuration file
+type yamlBackend struct {
+	URL         string            `yaml:"url"`
+	Weight      int               `yaml:"weight"`      // Of url relative to the replicas, 1 if 0
+	URLs        []yamlURL         `yaml:"urls"`        // Instead of url: the URL followed by the replicas
+	Command     []string          `yaml:"command"`     // Instead of url: executable and arguments of a stdio backend
+	Env         map[string]string `yaml:"env"`         // Environment variables of the command
+	Dir         string            `yaml:"dir"`         // Working directory of the command
+	Bearer      string            `yaml:"bearer"`      // The token, or a "file:" or "env:" reference to it (see ResolveSecret)
+	Passthrough bool              `yaml:"passthrough"` // Relay backend responses verbatim
+	Timeout     string            `yaml:"timeout"`     // Per-request timeout, e.g. "30s"
+	Retry       struct {
+		Codes    []int  `yaml:"codes"`    // JSON-RPC error codes safe to retry
+		Attempts int    `yaml:"attempts"` // Maximum number of retries
+		Backoff  string `yaml:"backoff"`  // Initial wait, e.g. "200ms"
+	} `yaml:"retry"`
+	Breaker struct {
+		FailureThreshold int      `yaml:"failure_threshold"` // Consecutive faults opening the breaker, 0 disables it
+		OpenDuration     string   `yaml:"open_duration"`     // How long the open breaker rejects requests, e.g. "30s"
+		Faults           []string `yaml:"faults"`            // Result classes counted as faults
+		Threshold        int      `yaml:"threshold"`         // Deprecated: use failure_threshold
+		Cooldown         string   `yaml:"cooldown"`          // Deprecated: use open_duration
+	} `yaml:"breaker"`
+	HandshakeRetry  string    `yaml:"handshake_retry"`   // How often a failed handshake is retried, e.g. "30s"
+	Replicas        []yamlURL `yaml:"replicas"`          // Further URLs serving the same backend, alone or with a weight
+	Affinity        string    `yaml:"affinity"`          // "none", "session" or "hash"
+	IdleConnTimeout string    `yaml:"idle_conn_timeout"` // How long idle connections are kept, e.g. "30s"
+	MaxIdleConns    int       `yaml:"max_idle_conns"`    // Idle connections kept per backend host
+	Hedge           struct {
+		Methods []string `yaml:"methods"` // Idempotent methods to hedge, e.g. "resources/read"
+		Delay   string   `yaml:"delay"`   // Wait before asking another replica, e.g. "100ms"
+		Max     int      `yaml:"max"`     // Maximum hedged requests per request
+	} `yaml:"hedge"`
+	ListCache struct {
+		Enabled    bool   `yaml:"enabled"`     // Cache the answers to tools/list, prompts/list and resources/list
+		TTL        string `yaml:"ttl"`         // How long an answer is used, e.g. "30s"
+		MaxEntries int    `yaml:"max_entries"` // Answers kept at most
+	} `yaml:"list_cache"`
+	AggregatePagination bool `yaml:"aggregate_pagination"` // List all pages of tools/list at once
+	Inject              []struct {
+		Tool     string `yaml:"tool"`     // Empty for all tools
+		Param    string `yaml:"param"`    // User param name
+		Argument string `yaml:"argument"` // Defaults to the param name
+		Override bool   `yaml:"override"` // Replace client-supplied values
+	} `yaml:"inject"`
+}
+
+// NewConfig creates a file-based configuration, decoding the file as YAML, JSON or TOML
+// depending on its extension (see FileFormat)
+func NewConfig(configPath string, logger *zap.Logger) (*YamlConfig, error) {
 	return NewYamlConfigWithOptions(configPath, logger)
 }
 
+// NewYamlConfig creates a new YAML-based configuration. It is the same as NewConfig.
+func NewYamlConfig(configPath string, logger *zap.Logger) (*YamlConfig, error) {
+	return NewConfig(configPath, logger)
+}
+
 // NewYamlConfigWithOptions creates a new YAML-based configuration with specified options
 func NewYamlConfigWithOptions(configPath string, logger *zap.Logger) (*YamlConfig, error) {
 	if logger == nil {
@@ -89,18 +295,7 @@ func NewYamlConfigWithOptions(configPath string, logger *zap.Logger) (*YamlConfi
 		}
 	}
 
-	config := &YamlConfig{
-		configPath:        configPath,
-		logger:            logger,
-		userAuthKeys:      make(map[string]string),
-		userParams:        make(map[string]map[string]string),
-		userSubscribes:    make(map[string][]string),
-		backends:          make(map[string]*Backend),
-		authorizationType: AuthorizedUsersOnly, // Default to requiring authorization
-		// Default SSL settings
-		sslMode:         "manual",
-		sslAcmeCacheDir: "./.autocert-cache", // Default cache dir
-	}
+	config := newYamlConfig(configPath, logger)
 
 	// Load initial configuration
 	if err := config.Update(); err != nil {
@@ -110,103 +305,487 @@ func NewYamlConfigWithOptions(configPath string, logger *zap.Logger) (*YamlConfi
 	return config, nil
 }
 
-// Update reloads configuration from the YAML file
+// newYamlConfig creates a YAML-based configuration with the defaults, without loading it
+func newYamlConfig(configPath string, logger *zap.Logger) *YamlConfig {
+	return &YamlConfig{
+		configPath:   configPath,
+		configPaths:  []string{configPath},
+		logger:       logger,
+		yamlSettings: newYamlSettings(),
+	}
+}
+
+// newYamlSettings returns the settings in effect before a file is loaded.
+func newYamlSettings() yamlSettings {
+	return yamlSettings{
+		userAuthKeys:        make(map[string]userKey),
+		keyCache:            newKeyCache(),
+		userParams:          make(map[string]map[string]string),
+		userSubscribes:      make(map[string][]string),
+		userDefaultBackends: make(map[string]string),
+		userRateLimits:      make(map[string]RateLimit),
+		userCertIdentities:  make(map[string]string),
+		backends:            make(map[string]*Backend),
+		authorizationType:   AuthorizedUsersOnly, // Default to requiring authorization
+		// Default SSL settings
+		sslMode:         "manual",
+		sslAcmeCacheDir: "./.autocert-cache", // Default cache dir
+	}
+}
+
+// Update reloads configuration from the file. The file is parsed and validated into new
+// settings, which replace the current ones only if the file has no error; otherwise the
+// configuration in effect is kept and the error returned.
 func (c *YamlConfig) Update() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.logger.Info("Updating configuration from YAML file", zap.String("path", c.configPath))
+	c.logger.Info("Updating configuration from file", zap.Strings("paths", c.configPaths))
 
 	// Read and parse the configuration file
-	data, err := os.ReadFile(c.configPath)
+	data, err := c.readConfig()
+	if err != nil {
+		c.logger.Error("Failed to read configuration file", zap.Strings("paths", c.configPaths), zap.Error(err))
+		return err
+	}
+
+	expanded, err := ExpandEnv(data, os.LookupEnv)
 	if err != nil {
-		c.logger.Error("Failed to read configuration file", zap.String("path", c.configPath), zap.Error(err))
+		c.logger.Error("Failed to expand environment variables in configuration", zap.Error(err))
 		return err
 	}
 
 	var yamlCfg yamlConfig
-	if err := yaml.Unmarshal(data, &yamlCfg); err != nil {
+	if err := yaml.Unmarshal(expanded, &yamlCfg); err != nil {
 		c.logger.Error("Failed to parse YAML configuration", zap.Error(err))
 		return err
 	}
+	issues, err := Lint(data)
+	if err != nil {
+		c.logger.Error("Failed to lint YAML configuration", zap.Error(err))
+		return err
+	}
+	for _, issue := range issues {
+		c.logger.Warn("Configuration uses a deprecated field", zap.String("field", issue.Path), zap.String("issue", issue.Message))
+	}
+	s := newYamlSettings()
+	s.lintIssues = issues
 
 	// Process server configuration
-	c.serverAddress = yamlCfg.Server.Address
-	c.serverName = yamlCfg.Server.Name
-	c.serverVersion = yamlCfg.Server.Version
-	c.logLevel = yamlCfg.Server.LogLevel
-	c.DiscoveringHandlerPathValue = yamlCfg.Server.DiscoveringHandlerPath
-	c.frontendAddressValue = yamlCfg.Server.FrontendAddress
+	s.serverAddress = yamlCfg.Server.Address
+	s.serverName = yamlCfg.Server.Name
+	s.serverVersion = yamlCfg.Server.Version
+	s.logLevel = yamlCfg.Server.LogLevel
+	if err := ValidateLogPrivacy(yamlCfg.Server.LogPrivacy); err != nil {
+		c.logger.Error("Invalid log privacy", zap.String("log_privacy", yamlCfg.Server.LogPrivacy))
+		return fmt.Errorf("invalid server.log_privacy: %w", err)
+	}
+	s.logPrivacy = yamlCfg.Server.LogPrivacy
+	if err := ValidateHashAlgorithm(yamlCfg.Server.HashAlgorithm); err != nil {
+		c.logger.Error("Invalid hash algorithm", zap.String("hash_algorithm", yamlCfg.Server.HashAlgorithm))
+		return fmt.Errorf("invalid server.hash_algorithm: %w", err)
+	}
+	s.hashAlgorithm = yamlCfg.Server.HashAlgorithm
+	if err := ValidateIDGenerator(yamlCfg.Server.IDGenerator); err != nil {
+		c.logger.Error("Invalid ID generator", zap.String("id_generator", yamlCfg.Server.IDGenerator))
+		return fmt.Errorf("invalid server.id_generator: %w", err)
+	}
+	s.idGenerator = yamlCfg.Server.IDGenerator
+	s.DiscoveringHandlerPathValue = yamlCfg.Server.DiscoveringHandlerPath
+	s.frontendAddressValue = yamlCfg.Server.FrontendAddress
+	s.sseMaxStreams = yamlCfg.Server.SSE.MaxStreams
+	s.sseAllowedOrigins = yamlCfg.Server.SSE.AllowedOrigins
+	s.sseQueueSize = yamlCfg.Server.SSE.QueueSize
+	if yamlCfg.Server.SSE.QueueWait != "" {
+		wait, err := time.ParseDuration(yamlCfg.Server.SSE.QueueWait)
+		if err != nil || wait < 0 {
+			c.logger.Error("Invalid SSE queue wait", zap.String("wait", yamlCfg.Server.SSE.QueueWait), zap.Error(err))
+			return fmt.Errorf("invalid server.sse.queue_wait '%s'", yamlCfg.Server.SSE.QueueWait)
+		}
+		s.sseQueueWait = wait
+	}
+	if yamlCfg.Server.SSE.KeepAlive != "" {
+		interval, err := time.ParseDuration(yamlCfg.Server.SSE.KeepAlive)
+		if err != nil || interval <= 0 {
+			c.logger.Error("Invalid SSE keepalive interval", zap.String("interval", yamlCfg.Server.SSE.KeepAlive), zap.Error(err))
+			return fmt.Errorf("invalid server.sse.keepalive '%s'", yamlCfg.Server.SSE.KeepAlive)
+		}
+		s.sseKeepAlive = interval
+	}
+	if yamlCfg.Server.SSE.DrainTimeout != "" {
+		timeout, err := time.ParseDuration(yamlCfg.Server.SSE.DrainTimeout)
+		if err != nil || timeout <= 0 {
+			c.logger.Error("Invalid SSE drain timeout", zap.String("timeout", yamlCfg.Server.SSE.DrainTimeout), zap.Error(err))
+			return fmt.Errorf("invalid server.sse.drain_timeout '%s'", yamlCfg.Server.SSE.DrainTimeout)
+		}
+		s.sseDrainTimeout = timeout
+	}
+	s.compressionMinSize = yamlCfg.Server.Compression.MinSize
+	s.sanitizeInboundText = yamlCfg.Server.Sanitize.Inbound
+	s.sanitizeOutboundText = yamlCfg.Server.Sanitize.Outbound
+	if yamlCfg.Server.ToolsListDeadline != "" {
+		deadline, err := time.ParseDuration(yamlCfg.Server.ToolsListDeadline)
+		if err != nil || deadline < 0 {
+			c.logger.Error("Invalid tools list deadline", zap.String("deadline", yamlCfg.Server.ToolsListDeadline), zap.Error(err))
+			return fmt.Errorf("invalid server.tools_list_deadline '%s'", yamlCfg.Server.ToolsListDeadline)
+		}
+		s.toolsListDeadline = deadline
+	}
+	if yamlCfg.Server.BackendHealthInterval != "" {
+		interval, err := time.ParseDuration(yamlCfg.Server.BackendHealthInterval)
+		if err != nil || interval < 0 {
+			c.logger.Error("Invalid backend health interval", zap.String("interval", yamlCfg.Server.BackendHealthInterval), zap.Error(err))
+			return fmt.Errorf("invalid server.backend_health_interval '%s'", yamlCfg.Server.BackendHealthInterval)
+		}
+		s.backendHealthInterval = interval
+	}
+	if err := ValidateLatencyBuckets(yamlCfg.Server.Metrics.LatencyBuckets); err != nil {
+		return fmt.Errorf("invalid server.metrics.latency_buckets: %w", err)
+	}
+	s.metricsLatencyBuckets = yamlCfg.Server.Metrics.LatencyBuckets
+	s.metricsVersionLabels = yamlCfg.Server.Metrics.VersionLabels
+	if err := ValidateMetricsPath(yamlCfg.Server.Metrics.Path); err != nil {
+		return fmt.Errorf("invalid server.metrics.path: %w", err)
+	}
+	s.metricsEnabled = yamlCfg.Server.Metrics.Enabled
+	s.metricsPath = yamlCfg.Server.Metrics.Path
+	if yamlCfg.Server.Admin.PersistBackends && FileFormat(c.configPath) != FormatYAML {
+		return fmt.Errorf("server.admin.persist_backends: only supported for YAML files, not %s", FileFormat(c.configPath))
+	}
+	if yamlCfg.Server.Admin.PersistBackends && len(c.configPaths) > 1 {
+		return fmt.Errorf("server.admin.persist_backends: only supported with a single configuration file")
+	}
+	s.adminPersistBackends = yamlCfg.Server.Admin.PersistBackends
+	s.adminAllowHostAccess = yamlCfg.Server.Admin.AllowHostAccess
+	s.tracingEndpoint = yamlCfg.Server.Tracing.Endpoint
+	s.tracingSampleRatio = DefaultTracingSampleRatio
+	if ratio := yamlCfg.Server.Tracing.SampleRatio; ratio != nil {
+		if err := ValidateTracingSampleRatio(*ratio); err != nil {
+			return fmt.Errorf("invalid server.tracing.sample_ratio: %w", err)
+		}
+		s.tracingSampleRatio = *ratio
+	}
+	if err := ValidateAccessLogLevel(yamlCfg.Server.AccessLog.Level); err != nil {
+		return fmt.Errorf("invalid server.access_log.level: %w", err)
+	}
+	if err := ValidateAccessLogFields(yamlCfg.Server.AccessLog.Fields); err != nil {
+		return fmt.Errorf("invalid server.access_log.fields: %w", err)
+	}
+	s.accessLogEnabled = yamlCfg.Server.AccessLog.Enabled
+	s.accessLogLevel = yamlCfg.Server.AccessLog.Level
+	s.accessLogFields = yamlCfg.Server.AccessLog.Fields
+	if err := ValidateMethodPatterns(yamlCfg.Server.Methods.Deny); err != nil {
+		return fmt.Errorf("invalid server.methods.deny: %w", err)
+	}
+	if err := ValidateMethodPatterns(yamlCfg.Server.Methods.Allow); err != nil {
+		return fmt.Errorf("invalid server.methods.allow: %w", err)
+	}
+	s.methodsDeny = yamlCfg.Server.Methods.Deny
+	s.methodsAllow = yamlCfg.Server.Methods.Allow
+	s.prefixNames = yamlCfg.Server.Naming.Prefix
+	s.nameSeparator = yamlCfg.Server.Naming.Separator
 
 	// Process SSL settings
-	c.sslEnabled = yamlCfg.Server.SSL.Enabled
-	c.sslMode = yamlCfg.Server.SSL.Mode
-	c.sslCertFile = yamlCfg.Server.SSL.CertFile
-	c.sslKeyFile = yamlCfg.Server.SSL.KeyFile
-	c.sslAcmeDomains = yamlCfg.Server.SSL.AcmeDomains
-	c.sslAcmeEmail = yamlCfg.Server.SSL.AcmeEmail
-	c.sslAcmeCacheDir = yamlCfg.Server.SSL.AcmeCacheDir
+	s.sslEnabled = yamlCfg.Server.SSL.Enabled
+	s.sslMode = yamlCfg.Server.SSL.Mode
+	s.sslCertFile = yamlCfg.Server.SSL.CertFile
+	s.sslKeyFile = yamlCfg.Server.SSL.KeyFile
+	s.sslAcmeDomains = yamlCfg.Server.SSL.AcmeDomains
+	s.sslAcmeEmail = yamlCfg.Server.SSL.AcmeEmail
+	s.sslAcmeCacheDir = yamlCfg.Server.SSL.AcmeCacheDir
+	s.sslClientCAFile = yamlCfg.Server.SSL.ClientCAFile
+	s.sslOCSPStapling = yamlCfg.Server.SSL.OCSPStapling
+	s.sslMinVersion = yamlCfg.Server.SSL.MinVersion
+	s.sslCipherSuites = yamlCfg.Server.SSL.CipherSuites
+	if yamlCfg.Server.SSL.ReloadInterval != "" {
+		interval, err := time.ParseDuration(yamlCfg.Server.SSL.ReloadInterval)
+		if err != nil || interval < 0 {
+			c.logger.Error("Invalid SSL reload interval", zap.String("interval", yamlCfg.Server.SSL.ReloadInterval), zap.Error(err))
+			return fmt.Errorf("invalid server.ssl.reload_interval '%s'", yamlCfg.Server.SSL.ReloadInterval)
+		}
+		s.sslReloadInterval = interval
+	}
 	// Provide defaults if values are missing
-	if c.sslMode == "" {
-		c.sslMode = "manual"
+	if s.sslMode == "" {
+		s.sslMode = "manual"
 	}
-	if c.sslAcmeCacheDir == "" {
-		c.sslAcmeCacheDir = "./.autocert-cache"
+	if s.sslAcmeCacheDir == "" {
+		s.sslAcmeCacheDir = "./.autocert-cache"
 	}
 
 	// Process authorization type
+	s.authorization = yamlCfg.Server.Authorization
 	switch yamlCfg.Server.Authorization {
 	case "users_only":
-		c.authorizationType = AuthorizedUsersOnly
+		s.authorizationType = AuthorizedUsersOnly
 	case "marked_methods":
-		c.authorizationType = NotAuthorizedToMarkedMethods
+		s.authorizationType = NotAuthorizedToMarkedMethods
 	case "none":
-		c.authorizationType = NotAuthorizedEverywhere
+		s.authorizationType = NotAuthorizedEverywhere
+	case "mtls":
+		s.authorizationType = AuthorizedByClientCert
 	default:
 		// Default to requiring authorization for all users if not specified
-		c.authorizationType = AuthorizedUsersOnly
+		s.authorizationType = AuthorizedUsersOnly
 	}
 
 	// Process users and their auth keys
-	oldUserAuthKeys := c.userAuthKeys
-	c.userAuthKeys = make(map[string]string)
-	c.userSubscribes = make(map[string][]string)
-
-	// Collect all users for which we need to call the callbacks
-	affectedUsers := make(map[string]bool)
-
 	for userID, user := range yamlCfg.Users {
 		// Process auth keys
-		for _, authKey := range user.Keys {
-			c.userAuthKeys[authKey] = userID
-			if oldUserID, exists := oldUserAuthKeys[authKey]; !exists || oldUserID != userID {
-				affectedUsers[userID] = true
+		for _, key := range user.Keys {
+			if c.revokedKeys[key.Hash] {
+				continue
+			}
+			authKey, err := newUserKey(userID, key)
+			if err != nil {
+				c.logger.Error("Invalid user key", zap.String("userID", userID), zap.Error(err))
+				return fmt.Errorf("user '%s': keys: %w", userID, err)
 			}
+			s.userAuthKeys[key.Hash] = authKey
 		}
 
 		// Process subscribes
 		if len(user.Subscribes) > 0 {
-			c.userSubscribes[userID] = make([]string, len(user.Subscribes))
-			copy(c.userSubscribes[userID], user.Subscribes)
+			s.userSubscribes[userID] = make([]string, len(user.Subscribes))
+			copy(s.userSubscribes[userID], user.Subscribes)
 		}
-	}
-
-	// Check for removed auth keys
-	for authKey, userID := range oldUserAuthKeys {
-		if _, exists := c.userAuthKeys[authKey]; !exists {
-			affectedUsers[userID] = true
+		if user.DefaultBackend != "" {
+			s.userDefaultBackends[userID] = user.DefaultBackend
+		}
+		if limit := (RateLimit{RPS: user.RateLimit.RPS, Burst: user.RateLimit.Burst}); limit != (RateLimit{}) {
+			s.userRateLimits[userID] = limit
+		}
+		for _, identity := range user.ClientCerts {
+			if identity == "" {
+				return fmt.Errorf("user '%s': client_certs: empty identity", userID)
+			}
+			if other, exists := s.userCertIdentities[identity]; exists && other != userID {
+				c.logger.Error("Client certificate identity mapped to several users", zap.String("identity", identity))
+				return fmt.Errorf("user '%s': client_certs: '%s' is already mapped to user '%s'", userID, identity, other)
+			}
+			s.userCertIdentities[identity] = userID
+		}
+		if len(user.Params) > 0 {
+			s.userParams[userID] = make(map[string]string, len(user.Params))
+			for name, value := range user.Params {
+				s.userParams[userID][name] = value
+			}
 		}
 	}
 
 	// Process servers
-	c.backends = make(map[string]*Backend)
 	for backendID, backend := range yamlCfg.Backends {
-		c.backends[backendID] = &Backend{URL: backend.URL, Bearer: backend.Bearer}
+		parsed, err := c.parseBackend(backendID, backend)
+		if err != nil {
+			return err
+		}
+		s.backends[backendID] = parsed
+	}
+
+	// Process A2A agents
+	a2aAgents := make(map[string]A2ACardBaseInfo)
+	for name, agent := range yamlCfg.A2A.Agents {
+		var skills []A2ASkill
+		for _, skill := range agent.Skills {
+			skills = append(skills, A2ASkill(skill))
+		}
+		a2aAgents[name] = A2ACardBaseInfo{
+			Path:                   agent.Path,
+			Description:            agent.Description,
+			URL:                    agent.URL,
+			Version:                agent.Version,
+			DocumentationURL:       agent.DocumentationURL,
+			ProviderOrganization:   agent.Provider.Organization,
+			ProviderURL:            agent.Provider.URL,
+			Streaming:              agent.Capabilities.Streaming,
+			PushNotifications:      agent.Capabilities.PushNotifications,
+			StateTransitionHistory: agent.Capabilities.StateTransitionHistory,
+			Skills:                 skills,
+		}
+	}
+	if err := ValidateA2AAgents(a2aAgents); err != nil {
+		c.logger.Error("Invalid A2A agents configuration", zap.Error(err))
+		return err
+	}
+	s.a2aAgents = a2aAgents
+	s.a2aArtifactChecksums = yamlCfg.A2A.ArtifactChecksums
+	s.a2aDetectMimeTypes = yamlCfg.A2A.DetectMimeTypes
+	s.a2aMaxSessionTasks = yamlCfg.A2A.MaxSessionTasks
+	s.a2aSessionTaskHardLimit = yamlCfg.A2A.SessionTaskHardLimit
+	if yamlCfg.A2A.MaxConcurrentUserTasks < 0 {
+		return fmt.Errorf("a2a: invalid max concurrent user tasks %d", yamlCfg.A2A.MaxConcurrentUserTasks)
+	}
+	s.a2aMaxConcurrentUserTasks = yamlCfg.A2A.MaxConcurrentUserTasks
+	if err := ValidateA2ATaskIDScope(yamlCfg.A2A.TaskIDScope); err != nil {
+		c.logger.Error("Invalid A2A task ID scope", zap.String("task_id_scope", yamlCfg.A2A.TaskIDScope))
+		return err
+	}
+	s.a2aTaskIDScope = yamlCfg.A2A.TaskIDScope
+
+	if err := s.validate(); err != nil {
+		c.logger.Error("Invalid configuration", zap.Error(err))
+		return err
 	}
 
+	c.yamlSettings = s
 	return nil
 }
 
+// parseBackend converts the backend backendID of the configuration file
+func (c *YamlConfig) parseBackend(backendID string, backend yamlBackend) (*Backend, error) {
+	url, replicas, weights, err := backendURLs(backend.URL, backend.Weight, backend.URLs, backend.Replicas)
+	if err != nil {
+		return nil, fmt.Errorf("backend '%s': %w", backendID, err)
+	}
+	if len(backend.Command) > 0 {
+		if url != "" || len(replicas) > 0 {
+			return nil, fmt.Errorf("backend '%s': set either url or command", backendID)
+		}
+		if backend.Command[0] == "" {
+			return nil, fmt.Errorf("backend '%s': empty command", backendID)
+		}
+	} else if len(backend.Env) > 0 || backend.Dir != "" {
+		return nil, fmt.Errorf("backend '%s': env and dir require a command", backendID)
+	}
+	env := make([]string, 0, len(backend.Env))
+	for _, name := range slices.Sorted(maps.Keys(backend.Env)) {
+		env = append(env, name+"="+backend.Env[name])
+	}
+	var timeout time.Duration
+	if backend.Timeout != "" {
+		timeout, err = time.ParseDuration(backend.Timeout)
+		if err != nil || timeout < 0 {
+			c.logger.Error("Invalid backend timeout", zap.String("backend", backendID), zap.String("timeout", backend.Timeout), zap.Error(err))
+			return nil, fmt.Errorf("backend '%s': invalid timeout '%s'", backendID, backend.Timeout)
+		}
+	}
+	var retryBackoff time.Duration
+	if backend.Retry.Backoff != "" {
+		retryBackoff, err = time.ParseDuration(backend.Retry.Backoff)
+		if err != nil || retryBackoff < 0 {
+			c.logger.Error("Invalid backend retry backoff", zap.String("backend", backendID), zap.String("backoff", backend.Retry.Backoff), zap.Error(err))
+			return nil, fmt.Errorf("backend '%s': invalid retry backoff '%s'", backendID, backend.Retry.Backoff)
+		}
+	}
+	breakerThreshold, openDuration := backend.Breaker.FailureThreshold, backend.Breaker.OpenDuration
+	if backend.Breaker.Threshold != 0 {
+		if breakerThreshold != 0 {
+			return nil, fmt.Errorf("backend '%s': breaker may set failure_threshold or threshold, not both", backendID)
+		}
+		breakerThreshold = backend.Breaker.Threshold
+	}
+	if backend.Breaker.Cooldown != "" {
+		if openDuration != "" {
+			return nil, fmt.Errorf("backend '%s': breaker may set open_duration or cooldown, not both", backendID)
+		}
+		openDuration = backend.Breaker.Cooldown
+	}
+	var breakerCooldown time.Duration
+	if openDuration != "" {
+		breakerCooldown, err = time.ParseDuration(openDuration)
+		if err != nil || breakerCooldown < 0 {
+			c.logger.Error("Invalid backend breaker open duration", zap.String("backend", backendID), zap.String("openDuration", openDuration), zap.Error(err))
+			return nil, fmt.Errorf("backend '%s': invalid breaker open duration '%s'", backendID, openDuration)
+		}
+	}
+	var handshakeRetry time.Duration
+	if backend.HandshakeRetry != "" {
+		handshakeRetry, err = time.ParseDuration(backend.HandshakeRetry)
+		if err != nil || handshakeRetry < 0 {
+			c.logger.Error("Invalid backend handshake retry interval", zap.String("backend", backendID), zap.String("handshakeRetry", backend.HandshakeRetry), zap.Error(err))
+			return nil, fmt.Errorf("backend '%s': invalid handshake retry interval '%s'", backendID, backend.HandshakeRetry)
+		}
+	}
+	var idleConnTimeout time.Duration
+	if backend.IdleConnTimeout != "" {
+		idleConnTimeout, err = time.ParseDuration(backend.IdleConnTimeout)
+		if err != nil || idleConnTimeout < 0 {
+			c.logger.Error("Invalid backend idle connection timeout", zap.String("backend", backendID), zap.String("idleConnTimeout", backend.IdleConnTimeout), zap.Error(err))
+			return nil, fmt.Errorf("backend '%s': invalid idle connection timeout '%s'", backendID, backend.IdleConnTimeout)
+		}
+	}
+	if backend.MaxIdleConns < 0 {
+		return nil, fmt.Errorf("backend '%s': invalid max idle connections %d", backendID, backend.MaxIdleConns)
+	}
+	var hedgeDelay time.Duration
+	if backend.Hedge.Delay != "" {
+		hedgeDelay, err = time.ParseDuration(backend.Hedge.Delay)
+		if err != nil || hedgeDelay < 0 {
+			c.logger.Error("Invalid backend hedge delay", zap.String("backend", backendID), zap.String("delay", backend.Hedge.Delay), zap.Error(err))
+			return nil, fmt.Errorf("backend '%s': invalid hedge delay '%s'", backendID, backend.Hedge.Delay)
+		}
+	}
+	if backend.Hedge.Max < 0 {
+		return nil, fmt.Errorf("backend '%s': invalid max hedged requests %d", backendID, backend.Hedge.Max)
+	}
+	if err := ValidateHedgeMethods(backend.Hedge.Methods); err != nil {
+		return nil, fmt.Errorf("backend '%s': %w", backendID, err)
+	}
+	var listCacheTTL time.Duration
+	if backend.ListCache.TTL != "" {
+		listCacheTTL, err = time.ParseDuration(backend.ListCache.TTL)
+		if err != nil || listCacheTTL < 0 {
+			c.logger.Error("Invalid backend list cache TTL", zap.String("backend", backendID), zap.String("ttl", backend.ListCache.TTL), zap.Error(err))
+			return nil, fmt.Errorf("backend '%s': invalid list cache TTL '%s'", backendID, backend.ListCache.TTL)
+		}
+	}
+	if backend.ListCache.MaxEntries < 0 {
+		return nil, fmt.Errorf("backend '%s': invalid list cache max entries %d", backendID, backend.ListCache.MaxEntries)
+	}
+	if breakerThreshold < 0 {
+		return nil, fmt.Errorf("backend '%s': invalid breaker failure threshold %d", backendID, breakerThreshold)
+	}
+	if err := ValidateBackendAffinity(backend.Affinity); err != nil {
+		return nil, fmt.Errorf("backend '%s': %w", backendID, err)
+	}
+	bearer, err := ResolveSecret(backend.Bearer, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("backend '%s': bearer: %w", backendID, err)
+	}
+	injections := make([]ArgumentInjection, 0, len(backend.Inject))
+	for _, inject := range backend.Inject {
+		if inject.Param == "" {
+			return nil, fmt.Errorf("backend '%s': inject rule without param", backendID)
+		}
+		injections = append(injections, ArgumentInjection{Tool: inject.Tool, Param: inject.Param, Argument: inject.Argument, Override: inject.Override})
+	}
+	return &Backend{
+		URL:           url,
+		Command:       append([]string(nil), backend.Command...),
+		Env:           env,
+		Dir:           backend.Dir,
+		Bearer:        bearer,
+		Passthrough:   backend.Passthrough,
+		Timeout:       timeout,
+		RetryCodes:    append([]int(nil), backend.Retry.Codes...),
+		RetryAttempts: backend.Retry.Attempts,
+		RetryBackoff:  retryBackoff,
+		Inject:        injections,
+
+		BreakerThreshold: breakerThreshold,
+		BreakerCooldown:  breakerCooldown,
+		BreakerFaults:    append([]string(nil), backend.Breaker.Faults...),
+
+		HandshakeRetry: handshakeRetry,
+		Replicas:       replicas,
+		Weights:        weights,
+		Affinity:       backend.Affinity,
+
+		IdleConnTimeout: idleConnTimeout,
+		MaxIdleConns:    backend.MaxIdleConns,
+
+		HedgeMethods: append([]string(nil), backend.Hedge.Methods...),
+		HedgeDelay:   hedgeDelay,
+		HedgeMax:     backend.Hedge.Max,
+
+		ListCache:           backend.ListCache.Enabled,
+		ListCacheTTL:        listCacheTTL,
+		ListCacheMaxEntries: backend.ListCache.MaxEntries,
+
+		AggregatePagination: backend.AggregatePagination,
+	}, nil
+}
+
 // Close stops the file watcher and cleans up resources
 func (c *YamlConfig) Close() error {
 	return nil
@@ -225,14 +804,65 @@ func (c *YamlConfig) SetListenAddr(add string) {
 	c.serverAddress = add
 }
 
-// GetUserIDByKeyHash returns the user ID associated with the given key hash
+// GetUserIDByKey returns the user ID whose stored key hash matches the plaintext key
+// with the configured hash algorithm, or "" if none does, and ErrKeyExpired if the
+// matching key is outside its validity window. Keys verified with bcrypt or argon2id are
+// remembered until the file is loaded again, matching or not, so that only the first
+// request with a key pays for the slow hash; they are verified without holding the
+// configuration lock, and only against the hashes whose prefix starts them.
+func (c *YamlConfig) GetUserIDByKey(key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+	cacheKey := HashAPIKey(key)
+
+	c.mu.RLock()
+	algorithm, cache := c.hashAlgorithm, c.keyCache
+	var authKey string
+	var found, cached bool
+	var candidates map[string]userKey
+	if algorithm == "" || algorithm == HashAlgorithmSHA256 {
+		authKey = cacheKey
+		_, found = c.userAuthKeys[authKey]
+		cached = true
+	} else if authKey, found, cached = cache.lookup(cacheKey); !cached {
+		candidates = maps.Clone(c.userAuthKeys)
+	}
+	c.mu.RUnlock()
+
+	if !cached {
+		var err error
+		authKey, found, err = matchKeyHash(algorithm, candidates, key, keyPrefix)
+		if err != nil {
+			return "", err
+		}
+		cache.add(cacheKey, authKey, found)
+	}
+	if !found {
+		return "", nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	uk, ok := c.userAuthKeys[authKey]
+	if !ok {
+		return "", nil // Revoked or removed in the meantime
+	}
+	if err := uk.validAt(time.Now()); err != nil {
+		return "", err
+	}
+	return uk.userID, nil
+}
+
+// GetUserIDByKeyHash returns the user ID associated with the given key hash, and
+// ErrKeyExpired if the key is outside its validity window
 func (c *YamlConfig) GetUserIDByKeyHash(keyHash string) (string, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	// Load the file again to ensure we have the latest data
 	var config yamlConfig
-	data, err := os.ReadFile(c.configPath)
+	data, err := c.readConfig()
 	if err != nil {
 		return "", fmt.Errorf("failed to read config file: %w", err)
 	}
@@ -243,8 +873,15 @@ func (c *YamlConfig) GetUserIDByKeyHash(keyHash string) (string, error) {
 
 	// Iterate through users to find the matching key hash
 	for userID, user := range config.Users {
-		for _, hash := range user.Keys {
-			if hash == keyHash {
+		for _, key := range user.Keys {
+			if key.Hash == keyHash && !c.revokedKeys[keyHash] {
+				uk, err := newUserKey(userID, key)
+				if err != nil {
+					return "", fmt.Errorf("user '%s': keys: %w", userID, err)
+				}
+				if err := uk.validAt(time.Now()); err != nil {
+					return "", err
+				}
 				return userID, nil
 			}
 		}
@@ -287,6 +924,33 @@ func (c *YamlConfig) GetUserSubscribes(userID string) ([]string, error) {
 	return serversCopy, nil
 }
 
+// GetUserDefaultBackend returns the configured default backend of the user, or "" if none is set
+func (c *YamlConfig) GetUserDefaultBackend(userID string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.userDefaultBackends[userID], nil
+}
+
+// GetUserRateLimit returns the configured rate limit of the user, with the burst defaulting
+// to the rate rounded up, or zero if none is set
+// GetUserIDByCertIdentity returns the user listing the client certificate CN or SAN in
+// users.<id>.client_certs, "" if none does.
+func (c *YamlConfig) GetUserIDByCertIdentity(identity string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.userCertIdentities[identity], nil
+}
+
+func (c *YamlConfig) GetUserRateLimit(userID string) (RateLimit, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	limit := c.userRateLimits[userID]
+	if limit.Burst == 0 {
+		limit.Burst = int(math.Ceil(limit.RPS))
+	}
+	return limit, nil
+}
+
 // GetServer returns the URL for the given server ID
 func (c *YamlConfig) GetBackend(backendID string) (*Backend, error) {
 	c.mu.RLock()
@@ -300,6 +964,20 @@ func (c *YamlConfig) GetBackend(backendID string) (*Backend, error) {
 	return backend, nil
 }
 
+// BackendIDs returns the IDs of all configured backends, sorted
+func (c *YamlConfig) BackendIDs() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.backends)), nil
+}
+
+// BackendHealthInterval returns how often backends are health checked (0 for the default)
+func (c *YamlConfig) BackendHealthInterval() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.backendHealthInterval, nil
+}
+
 // Authorization returns the configured authorization type
 func (c *YamlConfig) AuthorizationType() (AuthorizationType, error) {
 	c.mu.RLock()
@@ -326,6 +1004,13 @@ func (c *YamlConfig) LogLevel() (string, error) {
 	return c.logLevel, nil
 }
 
+// Lint returns the issues of the loaded configuration, such as deprecated fields in use.
+func (c *YamlConfig) Lint() []LintIssue {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]LintIssue(nil), c.lintIssues...)
+}
+
 // DiscoveringHandlerPath returns the configured info handler path
 func (c *YamlConfig) DiscoveringHandlerPath() (string, error) {
 	// For YAML config, we don't have this setting
@@ -340,6 +1025,243 @@ func (c *YamlConfig) FrontendAddressForProxy() (string, error) {
 	return c.frontendAddressValue, nil
 }
 
+// SSEMaxStreams returns the server-wide limit of concurrent SSE streams
+func (c *YamlConfig) SSEMaxStreams() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sseMaxStreams, nil
+}
+
+// SSEQueueSize returns how many streams may wait for a slot once the stream limit is reached
+func (c *YamlConfig) SSEQueueSize() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sseQueueSize, nil
+}
+
+// SSEQueueWait returns how long a queued stream waits for a slot
+func (c *YamlConfig) SSEQueueWait() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sseQueueWait, nil
+}
+
+// SSEKeepAlive returns the interval of keepalive comments on open SSE streams (0 for the default)
+func (c *YamlConfig) SSEKeepAlive() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sseKeepAlive, nil
+}
+
+// SSEDrainTimeout returns how long open SSE streams are drained on shutdown (0 for the default)
+func (c *YamlConfig) SSEDrainTimeout() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sseDrainTimeout, nil
+}
+
+// CompressionMinSize returns the min size of compressed JSON responses (0 for the default)
+func (c *YamlConfig) CompressionMinSize() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.compressionMinSize, nil
+}
+
+// SSEAllowedOrigins returns the browser origins allowed to connect
+func (c *YamlConfig) SSEAllowedOrigins() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	origins := make([]string, len(c.sseAllowedOrigins))
+	copy(origins, c.sseAllowedOrigins)
+	return origins, nil
+}
+
+// SanitizeInboundText reports whether control sequences are stripped from client text
+func (c *YamlConfig) SanitizeInboundText() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sanitizeInboundText, nil
+}
+
+// SanitizeOutboundText reports whether control sequences are stripped from text returned to clients
+func (c *YamlConfig) SanitizeOutboundText() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sanitizeOutboundText, nil
+}
+
+// ToolsListDeadline returns how long tools/list waits for backends (0 waits for all)
+func (c *YamlConfig) ToolsListDeadline() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.toolsListDeadline, nil
+}
+
+// MetricsLatencyBuckets returns the bucket bounds of the latency histograms (empty for the defaults)
+func (c *YamlConfig) MetricsLatencyBuckets() ([]float64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]float64(nil), c.metricsLatencyBuckets...), nil
+}
+
+// MetricsVersionLabels reports whether relayed requests are counted by protocol versions
+func (c *YamlConfig) MetricsVersionLabels() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.metricsVersionLabels, nil
+}
+
+// MetricsEnabled reports whether metrics are served
+func (c *YamlConfig) MetricsEnabled() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.metricsEnabled, nil
+}
+
+// MetricsPath returns the path of the metrics endpoint (empty for DefaultMetricsPath)
+func (c *YamlConfig) MetricsPath() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.metricsPath, nil
+}
+
+// TracingEndpoint returns the base URL of the OTLP/HTTP collector (empty if tracing is disabled)
+func (c *YamlConfig) TracingEndpoint() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tracingEndpoint, nil
+}
+
+// TracingSampleRatio returns the share of the traces started by the gateway that are sampled
+func (c *YamlConfig) TracingSampleRatio() (float64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tracingSampleRatio, nil
+}
+
+// AccessLogEnabled reports whether requests and SSE streams are logged in the access log
+func (c *YamlConfig) AccessLogEnabled() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.accessLogEnabled, nil
+}
+
+// AccessLogLevel returns the level of the access log entries (empty for DefaultAccessLogLevel)
+func (c *YamlConfig) AccessLogLevel() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.accessLogLevel, nil
+}
+
+// AccessLogFields returns the fields of the access log entries (empty for all)
+func (c *YamlConfig) AccessLogFields() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.accessLogFields, nil
+}
+
+// LogPrivacy returns the redaction level of user data in logs
+func (c *YamlConfig) LogPrivacy() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.logPrivacy, nil
+}
+
+// IDGenerator returns the scheme of generated session and task IDs
+func (c *YamlConfig) IDGenerator() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.idGenerator, nil
+}
+
+// MethodsDeny returns the patterns of methods rejected for everyone
+func (c *YamlConfig) MethodsDeny() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.methodsDeny...), nil
+}
+
+// MethodsAllow returns the patterns of methods accepted (empty accepts all not denied)
+func (c *YamlConfig) MethodsAllow() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.methodsAllow...), nil
+}
+
+// PrefixNames reports whether listed names are prefixed with the ID of their backend
+func (c *YamlConfig) PrefixNames() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.prefixNames, nil
+}
+
+// NameSeparator returns the separator of prefixed names (empty for DefaultNameSeparator)
+func (c *YamlConfig) NameSeparator() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nameSeparator, nil
+}
+
+// A2AAgentNames returns the names of the configured A2A agents
+func (c *YamlConfig) A2AAgentNames() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return sortedA2AAgentNames(c.a2aAgents), nil
+}
+
+// GetA2ACardBaseInfo returns the configured card info of the A2A agent
+func (c *YamlConfig) GetA2ACardBaseInfo(agentName string) (A2ACardBaseInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	info, exists := c.a2aAgents[agentName]
+	if !exists {
+		return A2ACardBaseInfo{}, ErrNotFound
+	}
+	return info, nil
+}
+
+// A2AArtifactChecksums reports whether a sha256 checksum is added to the metadata of produced artifacts
+func (c *YamlConfig) A2AArtifactChecksums() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.a2aArtifactChecksums, nil
+}
+
+// A2ADetectArtifactMimeTypes reports whether missing MIME types of file artifacts are detected
+func (c *YamlConfig) A2ADetectArtifactMimeTypes() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.a2aDetectMimeTypes, nil
+}
+
+// A2AMaxSessionTasks returns the number of tasks kept per session before terminal ones are evicted
+func (c *YamlConfig) A2AMaxSessionTasks() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.a2aMaxSessionTasks, nil
+}
+
+// A2ASessionTaskHardLimit returns the number of tasks per session at which new tasks are rejected
+func (c *YamlConfig) A2ASessionTaskHardLimit() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.a2aSessionTaskHardLimit, nil
+}
+
+// A2ATaskIDScope returns the scope in which A2A task IDs are unique
+func (c *YamlConfig) A2ATaskIDScope() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.a2aTaskIDScope, nil
+}
+
+// A2AMaxConcurrentUserTasks returns the number of running tasks per user at which new tasks are rejected
+func (c *YamlConfig) A2AMaxConcurrentUserTasks() (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.a2aMaxConcurrentUserTasks, nil
+}
+
 func (c *YamlConfig) Status(ctx context.Context) error {
 	return nil
 }
@@ -390,3 +1312,33 @@ func (c *YamlConfig) SSLAcmeCacheDir() (string, error) {
 	defer c.mu.RUnlock()
 	return c.sslAcmeCacheDir, nil
 }
+
+func (c *YamlConfig) SSLClientCAFile() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sslClientCAFile, nil
+}
+
+func (c *YamlConfig) SSLOCSPStapling() (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sslOCSPStapling, nil
+}
+
+func (c *YamlConfig) SSLMinVersion() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sslMinVersion, nil
+}
+
+func (c *YamlConfig) SSLCipherSuites() ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.sslCipherSuites...), nil
+}
+
+func (c *YamlConfig) SSLReloadInterval() (time.Duration, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sslReloadInterval, nil
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestUpdateLoadsBackendBearer(t *testing.T) {
+	path := writeYaml(t, `backends:
+  secured:
+    url: https://secured.example.com/sse
+    bearer: upstream-token
+  open:
+    url: https://open.example.com/sse
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend, err := cfg.GetBackend("secured")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.Bearer != "upstream-token" {
+		t.Errorf("Bearer = %q, want the token from the file", backend.Bearer)
+	}
+	backend, err = cfg.GetBackend("open")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.Bearer != "" {
+		t.Errorf("Bearer of a backend without one = %q", backend.Bearer)
+	}
+}
+
+func TestUpdateLoadsBackendURLList(t *testing.T) {
+	path := writeYaml(t, `backends:
+  listed:
+    urls: [http://r1/sse, http://r2/sse, http://r3/sse]
+  single:
+    url: http://single/sse
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := cfg.GetBackend("listed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed.URL != "http://r1/sse" || !slices.Equal(listed.URLs(), []string{"http://r1/sse", "http://r2/sse", "http://r3/sse"}) {
+		t.Errorf("Backend with urls: URL = %q, URLs() = %v", listed.URL, listed.URLs())
+	}
+	single, err := cfg.GetBackend("single")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(single.URLs(), []string{"http://single/sse"}) {
+		t.Errorf("Backend with url: URLs() = %v", single.URLs())
+	}
+
+	path = writeYaml(t, "backends:\n  both:\n    url: http://a/sse\n    urls: [http://b/sse]\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "set either url or urls") {
+		t.Fatalf("Expected a backend with url and urls to be rejected, got: %v", err)
+	}
+}
+
+func TestUpdateLoadsBackendWeights(t *testing.T) {
+	path := writeYaml(t, `backends:
+  listed:
+    urls:
+      - {url: http://r1/sse, weight: 3}
+      - http://r2/sse
+  replicated:
+    url: http://main/sse
+    weight: 2
+    replicas: [{url: http://replica/sse, weight: 5}]
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := cfg.GetBackend("listed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(listed.URLs(), []string{"http://r1/sse", "http://r2/sse"}) {
+		t.Errorf("Backend with weighted urls: URLs() = %v", listed.URLs())
+	}
+	if w1, w2 := listed.Weight("http://r1/sse"), listed.Weight("http://r2/sse"); w1 != 3 || w2 != 1 {
+		t.Errorf("Backend with weighted urls: weights %d and %d, want 3 and 1", w1, w2)
+	}
+	replicated, err := cfg.GetBackend("replicated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w1, w2 := replicated.Weight("http://main/sse"), replicated.Weight("http://replica/sse"); w1 != 2 || w2 != 5 {
+		t.Errorf("Backend with weighted replicas: weights %d and %d, want 2 and 5", w1, w2)
+	}
+
+	path = writeYaml(t, "backends:\n  negative:\n    urls: [{url: http://a/sse, weight: -1}]\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Fatalf("Expected a negative weight to be rejected, got: %v", err)
+	}
+}
+
+func TestUpdateLoadsBackendBreaker(t *testing.T) {
+	path := writeYaml(t, `backends:
+  current:
+    url: http://current/sse
+    breaker:
+      failure_threshold: 3
+      open_duration: 10s
+  deprecated:
+    url: http://deprecated/sse
+    breaker:
+      threshold: 4
+      cooldown: 1m
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := cfg.GetBackend("current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.BreakerThreshold != 3 || current.BreakerCooldown != 10*time.Second {
+		t.Errorf("Breaker = %d, %v, want 3, 10s", current.BreakerThreshold, current.BreakerCooldown)
+	}
+	deprecated, err := cfg.GetBackend("deprecated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deprecated.BreakerThreshold != 4 || deprecated.BreakerCooldown != time.Minute {
+		t.Errorf("Breaker with the deprecated names = %d, %v, want 4, 1m", deprecated.BreakerThreshold, deprecated.BreakerCooldown)
+	}
+	if issues := cfg.Lint(); len(issues) != 2 {
+		t.Errorf("Expected the deprecated names to be reported, got %v", issues)
+	}
+
+	path = writeYaml(t, "backends:\n  both:\n    url: http://a/sse\n    breaker:\n      threshold: 1\n      failure_threshold: 2\n")
+	if _, err := NewYamlConfig(path, zap.NewNop()); err == nil || !strings.Contains(err.Error(), "failure_threshold or threshold") {
+		t.Fatalf("Expected a breaker with both names to be rejected, got: %v", err)
+	}
+}
+
+func TestUpdateLoadsBackendCommand(t *testing.T) {
+	path := writeYaml(t, `backends:
+  local:
+    command: [npx, -y, some-mcp-server]
+    env: {TOKEN: secret, MODE: fast}
+    dir: /srv/mcp
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := cfg.GetBackend("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(backend.Command, []string{"npx", "-y", "some-mcp-server"}) || !slices.Equal(backend.Env, []string{"MODE=fast", "TOKEN=secret"}) || backend.Dir != "/srv/mcp" {
+		t.Errorf("Backend with command: Command = %v, Env = %v, Dir = %q", backend.Command, backend.Env, backend.Dir)
+	}
+
+	for yaml, reason := range map[string]string{
+		"backends:\n  both:\n    url: http://a/sse\n    command: [server]\n": "set either url or command",
+		"backends:\n  env:\n    url: http://a/sse\n    env: {A: b}\n":        "env and dir require a command",
+	} {
+		if _, err := NewYamlConfig(writeYaml(t, yaml), zap.NewNop()); err == nil || !strings.Contains(err.Error(), reason) {
+			t.Errorf("Expected %q to be rejected with %q, got: %v", yaml, reason, err)
+		}
+	}
+}
+
+func TestUpdateKeepsConfigurationOfInvalidFile(t *testing.T) {
+	path := writeYaml(t, `server:
+  address: ":8080"
+  log_privacy: partial
+  sse:
+    max_streams: 5
+backends:
+  b1:
+    url: https://b1.example.com/sse
+`)
+	cfg, err := NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every setting before the invalid A2A setting is valid and would have been applied
+	if err := os.WriteFile(path, []byte(`server:
+  address: ":9090"
+  log_privacy: strict
+  sse:
+    max_streams: 9
+backends:
+  b2:
+    url: https://b2.example.com/sse
+a2a:
+  task_id_scope: everywhere
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Update(); err == nil {
+		t.Fatal("Expected the invalid file to be rejected")
+	}
+
+	if addr, _ := cfg.ListenAddr(); addr != ":8080" {
+		t.Errorf("ListenAddr = %q, want the previous address", addr)
+	}
+	if privacy, _ := cfg.LogPrivacy(); privacy != "partial" {
+		t.Errorf("LogPrivacy = %q, want the previous setting", privacy)
+	}
+	if streams, _ := cfg.SSEMaxStreams(); streams != 5 {
+		t.Errorf("SSEMaxStreams = %d, want the previous limit", streams)
+	}
+	if ids, _ := cfg.BackendIDs(); !slices.Equal(ids, []string{"b1"}) {
+		t.Errorf("BackendIDs = %v, want the previous backends", ids)
+	}
+}
@@ -0,0 +1,169 @@
+package shared
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gate4ai/mcp/shared/config"
+)
+
+// IDGenerator generates the IDs of sessions, and of A2A tasks whose caller did not
+// choose one. Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc adapts a function to an IDGenerator, e.g. a counter in tests.
+type IDGeneratorFunc func() string
+
+// NewID calls f.
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// RandomIDGenerator generates 32 random bytes in URL-safe base64, see RandomID. It is the default.
+type RandomIDGenerator struct{}
+
+// NewID returns a RandomID.
+func (RandomIDGenerator) NewID() string {
+	return RandomID()
+}
+
+// UUIDGenerator generates random (version 4) UUIDs.
+type UUIDGenerator struct{}
+
+// NewID returns a new UUID in its canonical textual form.
+func (UUIDGenerator) NewID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(err)
+	}
+	u[6] = u[6]&0x0f | 0x40 // Version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// ULIDGenerator generates ULIDs: 48 bits of millisecond timestamp followed by 80 random
+// bits, in Crockford's base32. IDs sort by creation time; within the same millisecond
+// the random part is incremented, so IDs of one generator are strictly increasing.
+type ULIDGenerator struct {
+	now     func() time.Time
+	entropy io.Reader
+
+	mu       sync.Mutex
+	lastTime uint64
+	lastRand [10]byte
+}
+
+// NewULIDGenerator creates a ULID generator reading the time from now and the random
+// bits from entropy; nil uses time.Now and crypto/rand. Fixed sources make the IDs
+// deterministic in tests.
+func NewULIDGenerator(now func() time.Time, entropy io.Reader) *ULIDGenerator {
+	if now == nil {
+		now = time.Now
+	}
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	return &ULIDGenerator{now: now, entropy: entropy}
+}
+
+// NewID returns a ULID greater than all previous ones of the generator.
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if ms <= g.lastTime {
+		// Same millisecond, or the clock went back: stay monotonic
+		ms = g.lastTime
+		if !incrementBytes(g.lastRand[:]) {
+			ms++ // The random part overflowed, borrow the next millisecond
+		}
+	} else if _, err := io.ReadFull(g.entropy, g.lastRand[:]); err != nil {
+		panic(err)
+	}
+	g.lastTime = ms
+
+	var id [16]byte
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], ms)
+	copy(id[:6], timestamp[2:])
+	copy(id[6:], g.lastRand[:])
+	return encodeULID(id)
+}
+
+// incrementBytes adds one to the big-endian number b and reports false on overflow.
+func incrementBytes(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// crockfordAlphabet is the base32 alphabet of ULIDs.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// encodeULID encodes the 128 bits of id as 26 base32 characters, the first one holding
+// the 3 most significant bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+// idGenerator is the generator used by NewID.
+var idGenerator atomic.Value
+
+// SetIDGenerator makes NewID use gen, nil restores the default RandomIDGenerator. It
+// returns the generator used before, so tests can restore it.
+func SetIDGenerator(gen IDGenerator) IDGenerator {
+	if gen == nil {
+		gen = RandomIDGenerator{}
+	}
+	previous := DefaultIDGenerator()
+	idGenerator.Store(&gen)
+	return previous
+}
+
+// DefaultIDGenerator returns the generator set with SetIDGenerator.
+func DefaultIDGenerator() IDGenerator {
+	if gen, ok := idGenerator.Load().(*IDGenerator); ok {
+		return *gen
+	}
+	return RandomIDGenerator{}
+}
+
+// NewID returns an ID of the default generator, used for session IDs.
+func NewID() string {
+	return DefaultIDGenerator().NewID()
+}
+
+// NewIDGenerator creates the generator of a config.IConfig.IDGenerator name; empty
+// means the default.
+func NewIDGenerator(name string) (IDGenerator, error) {
+	switch name {
+	case "", config.IDGeneratorRandom:
+		return RandomIDGenerator{}, nil
+	case config.IDGeneratorUUID:
+		return UUIDGenerator{}, nil
+	case config.IDGeneratorULID:
+		return NewULIDGenerator(nil, nil), nil
+	default:
+		return nil, config.ValidateIDGenerator(name)
+	}
+}
@@ -0,0 +1,102 @@
+package shared
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestIDGeneratorsGenerateUniqueIDs(t *testing.T) {
+	generators := map[string]IDGenerator{
+		"random": RandomIDGenerator{},
+		"uuid":   UUIDGenerator{},
+		"ulid":   NewULIDGenerator(nil, nil),
+	}
+	for name, gen := range generators {
+		t.Run(name, func(t *testing.T) {
+			seen := make(map[string]bool)
+			for i := 0; i < 1000; i++ {
+				id := gen.NewID()
+				if seen[id] {
+					t.Fatalf("Duplicate ID %q after %d IDs", id, i)
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestUUIDGeneratorFormat(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id := (UUIDGenerator{}).NewID(); !uuid.MatchString(id) {
+		t.Errorf("UUID %q is not a version 4 UUID", id)
+	}
+}
+
+func TestULIDGeneratorIsMonotonic(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	gen := NewULIDGenerator(func() time.Time { return now }, nil)
+
+	previous := gen.NewID()
+	for i := 0; i < 1000; i++ {
+		if i%100 == 0 {
+			now = now.Add(time.Millisecond)
+		}
+		if i == 500 {
+			now = now.Add(-time.Second) // The clock goes back
+		}
+		id := gen.NewID()
+		if len(id) != 26 {
+			t.Fatalf("ULID %q has %d characters", id, len(id))
+		}
+		if id <= previous {
+			t.Fatalf("ULID %q is not greater than the previous %q", id, previous)
+		}
+		previous = id
+	}
+}
+
+func TestULIDGeneratorIsDeterministicWithFixedSources(t *testing.T) {
+	now := func() time.Time { return time.UnixMilli(1469918176385) }
+	entropy := bytes.NewReader(make([]byte, 10))
+	gen := NewULIDGenerator(now, entropy)
+
+	if id := gen.NewID(); id != "01ARYZ6S41"+"0000000000000000" {
+		t.Errorf("First ULID = %q", id)
+	}
+	if id := gen.NewID(); id != "01ARYZ6S41"+"0000000000000001" {
+		t.Errorf("Second ULID in the same millisecond = %q", id)
+	}
+}
+
+func TestSetIDGeneratorIsUsedForSessions(t *testing.T) {
+	previous := SetIDGenerator(IDGeneratorFunc(func() string { return "fixed-id" }))
+	defer SetIDGenerator(previous)
+
+	if id := NewID(); id != "fixed-id" {
+		t.Errorf("NewID = %q", id)
+	}
+	session := NewBaseSession(zap.NewNop(), nil, nil)
+	if session.GetID() != "fixed-id" {
+		t.Errorf("Session ID = %q, want the custom generator's ID", session.GetID())
+	}
+}
+
+func TestNewIDGenerator(t *testing.T) {
+	for name, want := range map[string]string{"": "shared.RandomIDGenerator", "uuid": "shared.UUIDGenerator", "ulid": "*shared.ULIDGenerator"} {
+		gen, err := NewIDGenerator(name)
+		if err != nil {
+			t.Fatalf("NewIDGenerator(%q) failed: %v", name, err)
+		}
+		if got := fmt.Sprintf("%T", gen); got != want {
+			t.Errorf("NewIDGenerator(%q) = %s, want %s", name, got, want)
+		}
+	}
+	if _, err := NewIDGenerator("snowflake"); err == nil {
+		t.Error("NewIDGenerator accepted an unknown scheme")
+	}
+}
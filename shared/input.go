@@ -1,30 +1,52 @@
 package shared
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/tracing"
 	"go.uber.org/zap"
 )
 
+// ProgressNotificationMethod reports progress of a request carrying a progress token.
+// Its handlers run in the processing loop, so they receive the notifications in the
+// order they arrived and before the response of the request; they must not block.
+const ProgressNotificationMethod = "notifications/progress"
+
 type Input struct {
 	Mu              sync.RWMutex
 	input           chan *Message
 	logger          *zap.Logger
 	validators      []MessageValidator
-	methodHandlers  sync.Map      // Maps method names to handler functions
-	notFoundHandler atomic.Value  // func(*shared.Message) (interface{}, error)
-	capabilities    []ICapability // List of capabilities
+	observers       []RequestObserver
+	requestLoggers  []RequestLogger
+	tracer          *tracing.Tracer // Traces requests if not nil
+	methodHandlers  sync.Map        // Maps method names to handler functions
+	notFoundHandler atomic.Value    // func(*shared.Message) (interface{}, error)
+	capabilities    []ICapability   // List of capabilities
+	inFlight        atomic.Int64    // Requests queued or being handled
+	requestsMu      sync.Mutex
+	requests        map[requestKey]*Message // Requests queued or being handled, to cancel them
+}
+
+// requestKey identifies a request among those of all sessions of a processor.
+type requestKey struct {
+	session string
+	id      string
 }
 
 func NewInput(logger *zap.Logger) *Input {
 	i := &Input{
 		validators: []MessageValidator{},
 		logger:     logger,
+		requests:   make(map[requestKey]*Message),
 	}
 	// Initialize notFoundHandler
 	i.notFoundHandler.Store(func(msg *Message) (interface{}, error) {
@@ -42,6 +64,21 @@ type MessageValidator interface {
 	Validate(*Message) error
 }
 
+// RequestObserver is told about every request answered by the processor: its method,
+// the time its handler took (zero if a validator rejected it) and the error it was
+// answered with, nil on success. It is called before the response is sent and must not
+// block.
+type RequestObserver interface {
+	ObserveRequest(method string, duration time.Duration, err error)
+}
+
+// RequestLogger is told about every request answered by the processor like a
+// RequestObserver, with the request message, whose Backends are recorded by then, and the
+// size in bytes of the encoded result or error it was answered with.
+type RequestLogger interface {
+	LogRequest(msg *Message, duration time.Duration, err error, responseBytes int)
+}
+
 // HandleMessage validates and enqueues a message for processing
 func (i *Input) Put(msg *Message) error {
 	i.Mu.Lock()
@@ -51,11 +88,20 @@ func (i *Input) Put(msg *Message) error {
 
 	for _, validator := range copyOfValidators {
 		if err := validator.Validate(msg); err != nil {
+			if msg.Method != nil && !msg.ID.IsEmpty() {
+				i.observeRequest(msg, 0, nil, err)
+				go msg.Session.SendResponse(msg.ID, nil, err)
+			}
 			return err
 		}
 	}
 	msg.Session.UpdateLastActivity()
 
+	request := isRequest(msg)
+	if request {
+		i.inFlight.Add(1) // Before it is queued, so it is never seen answered but not yet counted
+		i.track(msg)      // Before it is queued, so a cancellation following it finds it
+	}
 	select {
 	case i.input <- msg:
 		i.logger.Debug("Message queued",
@@ -65,13 +111,19 @@ func (i *Input) Put(msg *Message) error {
 		)
 
 	default:
+		if request {
+			i.inFlight.Add(-1)
+			i.untrack(msg)
+		}
 		i.logger.Error("Input channel full, dropping message",
 			zap.String("sessionID", msg.Session.GetID()),
 			zap.Any("messageID", msg.ID),
 			zap.Stringp("method", msg.Method),
 		)
 		if !msg.ID.IsEmpty() {
-			go msg.Session.SendResponse(msg.ID, nil, errors.New("message processor busy, message dropped"))
+			err := errors.New("message processor busy, message dropped")
+			i.observeRequest(msg, 0, nil, err)
+			go msg.Session.SendResponse(msg.ID, nil, err)
 		}
 		return errors.New("input processor busy, input channel full")
 	}
@@ -107,49 +159,74 @@ func (i *Input) Process() {
 			continue
 		}
 
+		if msg.Method != nil && *msg.Method == ProgressNotificationMethod {
+			// Handled in order, before the response to the request it reports on is processed
+			i.processMessage(msg, logger)
+			continue
+		}
 		// Process each message in its own goroutine to prevent blocking the input channel
-		go func(msgToProcess *Message) {
-			defer func() {
-				if r := recover(); r != nil {
-					logger.Error("Panic recovered during message processing", zap.Any("panic", r), zap.Any("msgId", msgToProcess.ID))
-					// Optionally send an internal error response back if it was a request
-					if !msgToProcess.ID.IsEmpty() {
-						msgToProcess.Session.SendResponse(msgToProcess.ID, nil, fmt.Errorf("internal server error during processing: %v", r))
-					}
-				}
-				logger.Debug("Processed message",
-					zap.String("messageID", msgToProcess.ID.String()),
-					zap.String("method", NilIfNil(msgToProcess.Method)),
-				)
-			}() // End defer for panic recovery and logging
-			if msgToProcess.Method != nil {
-				if handler, exists := i.GetHandler(*msgToProcess.Method); exists {
-					response, err := handler(msg) // Execute the handler
-
-					// Only send a response if the original message had an ID (i.e., it was a request) and wasn't a notification method
-					if !msgToProcess.ID.IsEmpty() && !isNotificationMethod(msgToProcess.Method) {
-						msgToProcess.Session.SendResponse(msgToProcess.ID, response, err)
-					} else if err != nil { // Log errors from notification handlers
-						logger.Error("Error handling notification", zap.String("method", *msgToProcess.Method), zap.Error(err))
-					}
-				} else {
-					// This case should ideally not be reached if AddNotFoundHandle is used correctly
-					errMsg := fmt.Errorf("handler not found for method: %s", *msgToProcess.Method)
-					logger.Error(errMsg.Error())
-					if !msgToProcess.ID.IsEmpty() {
-						msgToProcess.Session.SendResponse(msgToProcess.ID, nil, &JSONRPCError{Code: JSONRPCErrorMethodNotFound, Message: fmt.Sprintf("Method not found: %s", *msgToProcess.Method)})
-					}
-				}
-			} else if !msgToProcess.ID.IsEmpty() {
-				// Handle responses to server-initiated requests
-				processed := msg.Session.GetRequestManager().ProcessResponse(msg)
-				if !processed {
-					logger.Warn("Received response for unknown or timed-out request",
-						zap.String("responseID", msgToProcess.ID.String()),
-					)
-				}
+		go i.processMessage(msg, logger)
+	}
+}
+
+// processMessage runs the handler of a request or notification, or passes a response
+// to the request manager.
+func (i *Input) processMessage(msgToProcess *Message, logger *zap.Logger) {
+	if isRequest(msgToProcess) {
+		defer i.inFlight.Add(-1) // Deferred first, so it runs once the response was sent
+		defer i.untrack(msgToProcess)
+	}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Panic recovered during message processing", zap.Any("panic", r), zap.Any("msgId", msgToProcess.ID))
+			// Optionally send an internal error response back if it was a request
+			if !msgToProcess.ID.IsEmpty() {
+				err := fmt.Errorf("internal server error during processing: %v", r)
+				msgToProcess.Span.End(err)
+				i.observeRequest(msgToProcess, time.Since(start), nil, err)
+				msgToProcess.Session.SendResponse(msgToProcess.ID, nil, err)
 			}
-		}(msg) // Pass msg to goroutine
+		}
+		logger.Debug("Processed message",
+			zap.String("messageID", msgToProcess.ID.String()),
+			zap.String("method", NilIfNil(msgToProcess.Method)),
+		)
+	}() // End defer for panic recovery and logging
+	if msgToProcess.Method != nil {
+		if handler, exists := i.GetHandler(*msgToProcess.Method); exists {
+			if !msgToProcess.ID.IsEmpty() && !isNotificationMethod(msgToProcess.Method) {
+				msgToProcess.Span = i.startSpan(msgToProcess)
+				msgToProcess.backends = &backendSet{}
+			}
+			response, err := handler(msgToProcess) // Execute the handler
+			msgToProcess.Span.End(err)
+
+			// Only send a response if the original message had an ID (i.e., it was a request) and wasn't a notification method
+			if !msgToProcess.ID.IsEmpty() && !isNotificationMethod(msgToProcess.Method) {
+				i.observeRequest(msgToProcess, time.Since(start), response, err)
+				msgToProcess.Session.SendResponse(msgToProcess.ID, response, err)
+			} else if err != nil { // Log errors from notification handlers
+				logger.Error("Error handling notification", zap.String("method", *msgToProcess.Method), zap.Error(err))
+			}
+		} else {
+			// This case should ideally not be reached if AddNotFoundHandle is used correctly
+			errMsg := fmt.Errorf("handler not found for method: %s", *msgToProcess.Method)
+			logger.Error(errMsg.Error())
+			if !msgToProcess.ID.IsEmpty() {
+				err := &JSONRPCError{Code: JSONRPCErrorMethodNotFound, Message: fmt.Sprintf("Method not found: %s", *msgToProcess.Method)}
+				i.observeRequest(msgToProcess, time.Since(start), nil, err)
+				msgToProcess.Session.SendResponse(msgToProcess.ID, nil, err)
+			}
+		}
+	} else if !msgToProcess.ID.IsEmpty() {
+		// Handle responses to server-initiated requests
+		processed := msgToProcess.Session.GetRequestManager().ProcessResponse(msgToProcess)
+		if !processed {
+			logger.Warn("Received response for unknown or timed-out request",
+				zap.String("responseID", msgToProcess.ID.String()),
+			)
+		}
 	}
 }
 
@@ -157,6 +234,68 @@ func isNotificationMethod(method *string) bool {
 	return method != nil && strings.HasPrefix(*method, "notifications/")
 }
 
+// isRequest reports whether the message is a request the processor answers.
+func isRequest(msg *Message) bool {
+	return msg.Method != nil && !msg.ID.IsEmpty() && !isNotificationMethod(msg.Method)
+}
+
+// RequestsInFlight returns the number of requests queued or being handled, whose
+// responses have not been sent yet.
+func (i *Input) RequestsInFlight() int64 {
+	return i.inFlight.Load()
+}
+
+// track gives a request a context cancelled when it is answered or by CancelRequest.
+func (i *Input) track(msg *Message) {
+	msg.ctx, msg.cancel = context.WithCancel(context.Background())
+	i.requestsMu.Lock()
+	defer i.requestsMu.Unlock()
+	i.requests[requestKey{msg.Session.GetID(), msg.ID.String()}] = msg
+}
+
+// untrack cancels the context of a request that has been answered or dropped.
+func (i *Input) untrack(msg *Message) {
+	if msg.cancel == nil {
+		return
+	}
+	msg.cancel()
+	key := requestKey{msg.Session.GetID(), msg.ID.String()}
+	i.requestsMu.Lock()
+	defer i.requestsMu.Unlock()
+	if i.requests[key] == msg {
+		delete(i.requests, key)
+	}
+}
+
+// CancelRequest cancels the context of a request of a session that is queued or being
+// handled, e.g. when the client sent notifications/cancelled for it, so the work done
+// for it, such as calls to backends, stops. The request is still answered, the client
+// ignores the response. It reports whether the request was in flight.
+func (i *Input) CancelRequest(sessionID string, id *schema.RequestID) bool {
+	if id.IsEmpty() {
+		return false
+	}
+	i.requestsMu.Lock()
+	msg, ok := i.requests[requestKey{sessionID, id.String()}]
+	i.requestsMu.Unlock()
+	if ok {
+		msg.cancel()
+	}
+	return ok
+}
+
+// CancelRequests cancels the contexts of all requests of the session in flight, e.g.
+// when the session closes, see CancelRequest.
+func (i *Input) CancelRequests(sessionID string) {
+	i.requestsMu.Lock()
+	defer i.requestsMu.Unlock()
+	for key, msg := range i.requests {
+		if key.session == sessionID {
+			msg.cancel()
+		}
+	}
+}
+
 // AddNotFoundHandle registers a handler for methods that don't have a specific handler
 func (i *Input) AddNotFoundHandle(handler func(*Message) (interface{}, error)) {
 	i.notFoundHandler.Store(handler)
@@ -185,6 +324,82 @@ func (i *Input) AddValidator(validators ...MessageValidator) {
 	i.validators = append(i.validators, validators...)
 }
 
+// AddRequestObserver adds observers told about every request answered
+func (i *Input) AddRequestObserver(observers ...RequestObserver) {
+	i.Mu.Lock()
+	defer i.Mu.Unlock()
+	i.observers = append(i.observers, observers...)
+}
+
+// AddRequestLogger adds loggers told about every request answered
+func (i *Input) AddRequestLogger(loggers ...RequestLogger) {
+	i.Mu.Lock()
+	defer i.Mu.Unlock()
+	i.requestLoggers = append(i.requestLoggers, loggers...)
+}
+
+// SetTracer makes the processor trace the requests it handles.
+func (i *Input) SetTracer(tracer *tracing.Tracer) {
+	i.Mu.Lock()
+	defer i.Mu.Unlock()
+	i.tracer = tracer
+}
+
+// startSpan starts the span of a request, continuing the trace it arrived with. It
+// returns nil if requests are not traced.
+func (i *Input) startSpan(msg *Message) *tracing.Span {
+	i.Mu.RLock()
+	tracer := i.tracer
+	i.Mu.RUnlock()
+	span := tracer.Start(*msg.Method, tracing.KindServer, msg.TraceParent)
+	span.SetAttribute(tracing.AttrMethod, *msg.Method)
+	return span
+}
+
+// observeRequest passes the outcome of a request, answered with response or err, to the
+// observers and loggers.
+func (i *Input) observeRequest(msg *Message, duration time.Duration, response interface{}, err error) {
+	if msg.Method == nil || isNotificationMethod(msg.Method) {
+		return
+	}
+	i.Mu.RLock()
+	observers := i.observers
+	loggers := i.requestLoggers
+	i.Mu.RUnlock()
+	for _, observer := range observers {
+		observer.ObserveRequest(*msg.Method, duration, err)
+	}
+	if len(loggers) == 0 {
+		return
+	}
+	size := responseSize(response, err)
+	for _, logger := range loggers {
+		logger.LogRequest(msg, duration, err, size)
+	}
+}
+
+// responseSize returns the size of the result or error a request is answered with, as
+// encoded by SendResponse.
+func responseSize(response interface{}, err error) int {
+	var data []byte
+	switch {
+	case err != nil:
+		rpcErr, ok := err.(*JSONRPCError)
+		if !ok {
+			rpcErr = &JSONRPCError{Code: JSONRPCErrorInternal, Message: err.Error()}
+		}
+		data, _ = json.Marshal(rpcErr)
+	case response == nil:
+		return 0
+	default:
+		if raw, ok := response.(json.RawMessage); ok {
+			return len(raw)
+		}
+		data, _ = json.Marshal(response)
+	}
+	return len(data)
+}
+
 // This method avoids the addition of incorrect capabilities (static analyzer assistance).
 func (i *Input) AddServerCapability(capabilities ...IServerCapability) {
 	for _, capability := range capabilities {
@@ -18,7 +18,10 @@ const (
 	JSONRPCErrorInternal       = -32603 // Internal JSON-RPC error
 
 	// -32000 to -32099 are reserved for implementation-defined server errors
-	JSONRPCErrorServerError = -32000 // Generic server error
+	JSONRPCErrorServerError  = -32000 // Generic server error
+	JSONRPCErrorAccessDenied = -32003 // The user may not use the requested backend
+	JSONRPCErrorRateLimited  = -32029 // Too many requests of the user, retry after the time in the error data
+	JSONRPCErrorUnavailable  = -32030 // The backend's circuit breaker is open, retry after the time in the error data
 )
 
 type JSONRPCErrorResponse struct {
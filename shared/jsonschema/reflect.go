@@ -0,0 +1,159 @@
+// Package jsonschema derives JSON Schemas from Go types as encoding/json encodes them,
+// so that documentation generated from the schema types stays in sync with them.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema.
+type Schema map[string]any
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Reflector derives schemas from Go types. Named struct types are described once under
+// "$defs" and referenced by name, which also allows recursive types.
+type Reflector struct {
+	defs  map[string]Schema
+	names map[reflect.Type]string
+}
+
+// NewReflector creates a reflector with no definitions.
+func NewReflector() *Reflector {
+	return &Reflector{
+		defs:  make(map[string]Schema),
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// Defs returns the definitions of the named struct types reflected so far, to be
+// placed under "$defs" of the document holding the returned references.
+func (r *Reflector) Defs() map[string]Schema {
+	return r.defs
+}
+
+// Reflect returns the schema of the JSON encoding of values of type t.
+func (r *Reflector) Reflect(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return Schema{} // Any JSON value
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return Schema{} // Custom encoding, not derivable
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return Schema{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": r.Reflect(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": r.Reflect(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		return Schema{"$ref": "#/$defs/" + r.define(t)}
+	default:
+		return Schema{} // Interfaces and anything else hold any JSON value
+	}
+}
+
+// define describes a named struct type under "$defs" and returns its name there.
+func (r *Reflector) define(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := r.defs[name]; taken {
+		name = qualifiedName(t)
+	}
+	r.names[t] = name
+	r.defs[name] = Schema{} // Placeholder, so that recursive references resolve
+	r.defs[name] = r.object(t)
+	return name
+}
+
+// qualifiedName names a type after its package path, for types sharing their name with
+// another type, e.g. "a2a.2025-draft.schema.Message".
+func qualifiedName(t reflect.Type) string {
+	path := t.PkgPath()
+	if i := strings.Index(path, "/shared/"); i >= 0 {
+		path = path[i+len("/shared/"):]
+	}
+	return strings.ReplaceAll(path, "/", ".") + "." + t.Name()
+}
+
+// object describes the JSON object encoding a struct type.
+func (r *Reflector) object(t reflect.Type) Schema {
+	properties := make(map[string]Schema)
+	var required []string
+	r.addFields(t, properties, &required)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the encoded fields of a struct type, including those of embedded structs.
+func (r *Reflector) addFields(t reflect.Type, properties map[string]Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = r.Reflect(field.Type)
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
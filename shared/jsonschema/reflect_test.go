@@ -0,0 +1,46 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type node struct {
+	base
+	Name     string          `json:"name"`
+	Label    string          `json:"label,omitempty"`
+	Parent   *node           `json:"parent"`
+	Children []node          `json:"children,omitempty"`
+	Raw      json.RawMessage `json:"raw,omitempty"`
+	Hidden   string          `json:"-"`
+}
+
+func TestReflectStruct(t *testing.T) {
+	r := NewReflector()
+	ref := r.Reflect(reflect.TypeOf(&node{}))
+	if ref["$ref"] != "#/$defs/node" {
+		t.Fatalf("Expected a reference to node, got %v", ref)
+	}
+
+	def := r.Defs()["node"]
+	properties := def["properties"].(map[string]Schema)
+	for _, name := range []string{"id", "name", "label", "parent", "children", "raw"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("Property %q is missing", name)
+		}
+	}
+	if _, ok := properties["Hidden"]; ok {
+		t.Error("Ignored field is described")
+	}
+	if properties["parent"]["$ref"] != "#/$defs/node" {
+		t.Errorf("Expected the recursive field to reference node, got %v", properties["parent"])
+	}
+	if required := def["required"].([]string); !reflect.DeepEqual(required, []string{"id", "name"}) {
+		t.Errorf("Expected id and name to be required, got %v", required)
+	}
+}
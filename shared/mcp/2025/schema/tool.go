@@ -73,6 +73,7 @@ type CallToolRequest struct {
 
 // CallToolRequestParams contains parameters for tool call requests.
 type CallToolRequestParams struct {
+	Meta Meta `json:"_meta,omitempty"` // Reserved for metadata
 	// The name of the tool.
 	Name string `json:"name"`
 	// Arguments for the tool call.
@@ -1,11 +1,15 @@
 package shared
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/tracing"
 )
 
 type Message struct {
@@ -18,6 +22,63 @@ type Message struct {
 
 	Processed bool     `json:"-"`
 	Session   ISession `json:"-"` // Will be either client.Session or mcp.Session
+
+	// Trace context: the traceparent header the message arrived with, or is sent with,
+	// and the span of a traced request while it is handled
+	TraceParent string        `json:"-"`
+	Span        *tracing.Span `json:"-"`
+
+	backends *backendSet        // Backends a request was sent to while it is handled
+	ctx      context.Context    // Of a request in flight, see Input.CancelRequest
+	cancel   context.CancelFunc // Cancels ctx
+}
+
+// Context returns a context carrying the span of the message, see tracing.SpanFromContext,
+// and recording the backends the request is sent to, see RecordBackend. The context of a
+// request is cancelled once it has been answered, or earlier if the client cancels it or
+// goes away, see Input.CancelRequest.
+func (m *Message) Context() context.Context {
+	base := m.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	ctx := tracing.ContextWithSpan(base, m.Span)
+	if m.backends != nil {
+		ctx = context.WithValue(ctx, backendsKey{}, m.backends)
+	}
+	return ctx
+}
+
+// Backends returns the IDs of the backends the request was sent to, in the order they
+// were first recorded with RecordBackend.
+func (m *Message) Backends() []string {
+	if m.backends == nil {
+		return nil
+	}
+	m.backends.mu.Lock()
+	defer m.backends.mu.Unlock()
+	return slices.Clone(m.backends.ids)
+}
+
+// RecordBackend records that the request handled with ctx, derived from its
+// Message.Context, is sent to the backend. It does nothing for other contexts.
+func RecordBackend(ctx context.Context, backendID string) {
+	backends, _ := ctx.Value(backendsKey{}).(*backendSet)
+	if backends == nil {
+		return
+	}
+	backends.mu.Lock()
+	defer backends.mu.Unlock()
+	if !slices.Contains(backends.ids, backendID) {
+		backends.ids = append(backends.ids, backendID)
+	}
+}
+
+type backendsKey struct{}
+
+type backendSet struct {
+	mu  sync.Mutex
+	ids []string
 }
 
 func ParseMessages(s ISession, data []byte) ([]*Message, error) {
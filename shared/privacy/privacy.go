@@ -0,0 +1,149 @@
+// Package privacy redacts user-identifying data and message content from log entries.
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// IdentityKeys are the log field keys holding user identifiers or key material, including
+// the subject and the identities (CN or SAN) of client certificates.
+var IdentityKeys = map[string]bool{
+	"userID":   true,
+	"user_id":  true,
+	"keyHash":  true,
+	"authKey":  true,
+	"apiKey":   true,
+	"bearer":   true,
+	"subject":  true,
+	"identity": true,
+}
+
+// ContentKeys are the log field keys holding message, prompt or tool content.
+var ContentKeys = map[string]bool{
+	"params":    true,
+	"arguments": true,
+	"argValue":  true,
+	"body":      true,
+	"data":      true,
+	"result":    true,
+	"text":      true,
+	"prompt":    true,
+	"content":   true,
+}
+
+// partialPrefix is the number of characters of an identifier kept at the partial level.
+const partialPrefix = 4
+
+// Pseudonym returns a stable pseudonymous ID for an identifier, so that entries of the
+// same user can be correlated without logging the identifier.
+func Pseudonym(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return "p-" + hex.EncodeToString(sum[:6])
+}
+
+// WrapLogger returns a logger redacting fields of the given level (see config.IConfig.LogPrivacy):
+//   - none: fields are logged as they are;
+//   - partial: identifiers are truncated to their first characters;
+//   - strict: identifiers are replaced by their Pseudonym, and content fields by their
+//     size in bytes, under the key "<key>_bytes".
+func WrapLogger(logger *zap.Logger, level string) *zap.Logger {
+	if level == "" || level == config.LogPrivacyNone {
+		return logger
+	}
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: core, strict: level == config.LogPrivacyStrict}
+	}))
+}
+
+// redactingCore rewrites the fields of entries and of child loggers before the wrapped
+// core encodes them.
+type redactingCore struct {
+	zapcore.Core
+	strict bool
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redact(fields)), strict: c.strict}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redact(fields))
+}
+
+// redact returns the fields with identifiers and, at the strict level, content replaced.
+func (c *redactingCore) redact(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		switch {
+		case IdentityKeys[field.Key]:
+			redacted = append(redacted, c.redactIdentity(field))
+		case c.strict && ContentKeys[field.Key]:
+			redacted = append(redacted, zap.Int(field.Key+"_bytes", fieldSize(field)))
+		default:
+			redacted = append(redacted, field)
+		}
+	}
+	return redacted
+}
+
+func (c *redactingCore) redactIdentity(field zapcore.Field) zapcore.Field {
+	id := fieldString(field)
+	if c.strict {
+		return zap.String(field.Key, Pseudonym(id))
+	}
+	if utf8.RuneCountInString(id) <= partialPrefix {
+		return zap.String(field.Key, "…")
+	}
+	return zap.String(field.Key, string([]rune(id)[:partialPrefix])+"…")
+}
+
+// fieldString returns the value of a field as a string.
+func fieldString(field zapcore.Field) string {
+	switch field.Type {
+	case zapcore.StringType:
+		return field.String
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		return string(field.Interface.([]byte))
+	case zapcore.StringerType:
+		if stringer, ok := field.Interface.(fmt.Stringer); ok {
+			return stringer.String()
+		}
+		return ""
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type:
+		return fmt.Sprint(field.Integer)
+	default:
+		return fmt.Sprint(field.Interface)
+	}
+}
+
+// fieldSize returns the size in bytes of the logged value of a field.
+func fieldSize(field zapcore.Field) int {
+	switch field.Type {
+	case zapcore.StringType:
+		return len(field.String)
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		return len(field.Interface.([]byte))
+	default:
+		encoded, err := json.Marshal(field.Interface)
+		if err != nil {
+			return 0
+		}
+		return len(encoded)
+	}
+}
@@ -0,0 +1,124 @@
+package privacy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const (
+	rawUserID  = "alice@example.com"
+	promptText = "summarize my medical records"
+)
+
+// logSample logs the identifiers and content the gateway logs, directly and through
+// child and sugared loggers, and returns the entries as JSON.
+func logSample(t *testing.T, level string) string {
+	t.Helper()
+	core, logs := observer.New(zap.DebugLevel)
+	logger := WrapLogger(zap.New(core), level)
+
+	logger.Info("Authenticated", zap.String("userID", rawUserID), zap.String("keyHash", "9f86d081884c7d65"))
+	logger.With(zap.String("userID", rawUserID)).Debug("Calling tool", zap.Any("arguments", map[string]string{"prompt": promptText}))
+	logger.Sugar().Infow("Prompt received", "user_id", rawUserID, "text", promptText)
+	logger.Debug("Request body", zap.ByteString("body", []byte(`{"prompt":"`+promptText+`"}`)))
+	logger.Info("Client certificate is not mapped to a user", zap.String("subject", "CN="+rawUserID))
+	logger.Error("Client certificate identity mapped to several users", zap.String("identity", rawUserID))
+
+	var out strings.Builder
+	for _, entry := range logs.All() {
+		encoded, err := json.Marshal(entry.ContextMap())
+		if err != nil {
+			t.Fatalf("Failed to encode entry: %v", err)
+		}
+		out.Write(encoded)
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+func TestStrictNeverLogsUserIDsOrContent(t *testing.T) {
+	logged := logSample(t, config.LogPrivacyStrict)
+	for _, secret := range []string{rawUserID, "alice", "9f86d081", promptText, "medical"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("Strict mode logged %q:\n%s", secret, logged)
+		}
+	}
+	// Entries of the same user correlate through the pseudonym
+	if n := strings.Count(logged, Pseudonym(rawUserID)); n != 4 {
+		t.Errorf("Expected the pseudonym in 4 entries, got %d:\n%s", n, logged)
+	}
+	for _, size := range []string{`"arguments_bytes":`, `"text_bytes":28`, `"body_bytes":41`} {
+		if !strings.Contains(logged, size) {
+			t.Errorf("Expected %s in:\n%s", size, logged)
+		}
+	}
+}
+
+func TestPartialTruncatesIdentifiersOnly(t *testing.T) {
+	logged := logSample(t, config.LogPrivacyPartial)
+	if strings.Contains(logged, rawUserID) || strings.Contains(logged, "9f86d081884c7d65") {
+		t.Errorf("Partial mode logged a full identifier:\n%s", logged)
+	}
+	if !strings.Contains(logged, `"userID":"alic…"`) {
+		t.Errorf("Expected the truncated user ID in:\n%s", logged)
+	}
+	if !strings.Contains(logged, promptText) {
+		t.Errorf("Partial mode should keep content:\n%s", logged)
+	}
+}
+
+func TestNoneKeepsFields(t *testing.T) {
+	logged := logSample(t, config.LogPrivacyNone)
+	if !strings.Contains(logged, rawUserID) || !strings.Contains(logged, promptText) {
+		t.Errorf("Expected fields unchanged:\n%s", logged)
+	}
+}
+
+// observeStrict returns a logger redacting at the strict level and the entries it logged.
+func observeStrict() (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zap.DebugLevel)
+	return WrapLogger(zap.New(core), config.LogPrivacyStrict), logs
+}
+
+// assertNotLogged fails if any field of the entries contains secret.
+func assertNotLogged(t *testing.T, logs *observer.ObservedLogs, secret string) {
+	t.Helper()
+	if logs.Len() == 0 {
+		t.Fatal("Expected entries to be logged")
+	}
+	for _, entry := range logs.All() {
+		encoded, err := json.Marshal(entry.ContextMap())
+		if err != nil {
+			t.Fatalf("Failed to encode entry: %v", err)
+		}
+		if strings.Contains(string(encoded), secret) {
+			t.Errorf("Entry %q logged %q: %s", entry.Message, secret, encoded)
+		}
+	}
+}
+
+func TestStrictRedactsUsersOfInvalidConfiguration(t *testing.T) {
+	for name, users := range map[string]string{
+		"invalid key":          "  " + rawUserID + ":\n    keys: [{hash: abc, expires_at: tomorrow}]\n",
+		"shared cert identity": "  " + rawUserID + ":\n    client_certs: [svc]\n  bob:\n    client_certs: [svc]\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte("users:\n"+users), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			logger, logs := observeStrict()
+			if _, err := config.NewYamlConfig(path, logger); err == nil {
+				t.Fatal("Expected the configuration to be rejected")
+			}
+			assertNotLogged(t, logs, rawUserID)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package shared
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SanitizeText removes terminal control sequences from text that may be shown on a
+// console: ANSI escape sequences (CSI, OSC, DCS and two-byte escapes), C0/C1 control
+// characters and Unicode bidirectional overrides. Tabs, newlines and CRLF line endings
+// are preserved; a lone carriage return (which can overwrite the visible line) is dropped.
+func SanitizeText(s string) string {
+	if !needsSanitizing(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == 0x1b: // ESC
+			i += escapeSequenceLen(s[i:])
+			continue
+		case r == 0x9b: // C1 CSI
+			i += size + csiBodyLen(s[i+size:])
+			continue
+		case r == 0x9d || r == 0x90: // C1 OSC / DCS
+			i += size + stringBodyLen(s[i+size:])
+			continue
+		case r == '\t' || r == '\n':
+			b.WriteRune(r)
+		case r == '\r':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				b.WriteRune(r)
+			}
+		case r == utf8.RuneError && size == 1:
+			// Invalid UTF-8 byte, drop it
+		case isControlRune(r):
+			// Dropped
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// needsSanitizing reports whether s contains anything SanitizeText would change.
+func needsSanitizing(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return true
+		}
+		if r == '\r' && (i+1 >= len(s) || s[i+1] != '\n') {
+			return true
+		}
+		if r != '\t' && r != '\n' && r != '\r' && isControlRune(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func isControlRune(r rune) bool {
+	switch {
+	case r < 0x20, r == 0x7f, r >= 0x80 && r <= 0x9f:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069: // Bidi embeddings, overrides and isolates
+		return true
+	}
+	return false
+}
+
+// escapeSequenceLen returns the length of the escape sequence starting with ESC at s[0].
+func escapeSequenceLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		return 2 + csiBodyLen(s[2:])
+	case ']', 'P', 'X', '^', '_': // OSC, DCS, SOS, PM, APC
+		return 2 + stringBodyLen(s[2:])
+	default:
+		return 2
+	}
+}
+
+// csiBodyLen returns the length of CSI parameters and intermediates up to and including the final byte.
+func csiBodyLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return i // Malformed sequence, drop only the introducer
+		}
+	}
+	return len(s)
+}
+
+// stringBodyLen returns the length of a control string up to and including its terminator (BEL or ESC \).
+func stringBodyLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case 0x07:
+			return i + 1
+		case 0x1b:
+			if i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+			return i
+		}
+	}
+	return len(s)
+}
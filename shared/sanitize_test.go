@@ -0,0 +1,34 @@
+package shared
+
+import "testing"
+
+func TestSanitizeText(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain text is unchanged", "hello, world", "hello, world"},
+		{"tabs and newlines survive", "a\tb\nc\r\nd", "a\tb\nc\r\nd"},
+		{"unicode survives", "привет 👋 — ok", "привет 👋 — ok"},
+		{"SGR color codes", "\x1b[31mred\x1b[0m text", "red text"},
+		{"cursor movement and erase", "safe\x1b[2K\x1b[1Aspoofed", "safespoofed"},
+		{"private mode CSI", "x\x1b[?25ly", "xy"},
+		{"OSC title with BEL", "\x1b]0;pwned\x07after", "after"},
+		{"OSC hyperlink with ST", "\x1b]8;;http://evil\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"two byte escape", "a\x1bcb", "ab"},
+		{"C1 CSI", "a\u009b31mb", "ab"},
+		{"lone carriage return", "visible\rhidden", "visiblehidden"},
+		{"bell, backspace and DEL", "a\x07b\x08c\x7fd", "abcd"},
+		{"bidi override", "file\u202etxt.exe", "filetxt.exe"},
+		{"trailing ESC", "end\x1b", "end"},
+		{"invalid UTF-8", "a\xffb", "ab"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SanitizeText(tc.in); got != tc.want {
+				t.Errorf("SanitizeText(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
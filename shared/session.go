@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+	"github.com/gate4ai/mcp/shared/tracing"
 	"go.uber.org/zap"
 )
 
@@ -68,6 +70,7 @@ type BaseSession struct {
 	Logger            *zap.Logger
 	negotiatedVersion string
 	inputProcessor    *Input
+	closeHooks        []func() // Called once the session is closed
 }
 
 // NewBaseSession creates a new base session with default values
@@ -75,7 +78,7 @@ func NewBaseSession(logger *zap.Logger, inputProcessor *Input, params *sync.Map)
 	if params == nil {
 		params = &sync.Map{}
 	}
-	sessionID := RandomID()
+	sessionID := NewID()
 	sessionLogger := logger.With(zap.String("session_id", sessionID))
 	sessionLogger.Debug("Creating new session")
 	s := &BaseSession{
@@ -150,20 +153,39 @@ func (s *BaseSession) GetRequestManager() *RequestManager {
 	return s.RequestManager
 }
 
+// Close closes the output of the session and cancels its requests in flight.
 func (s *BaseSession) Close() error {
+	if s.inputProcessor != nil {
+		s.inputProcessor.CancelRequests(s.ID)
+	}
 	s.Mu.Lock()
-	defer s.Mu.Unlock()
 	s.status = StatusNew
 	if s.output == nil {
+		s.Mu.Unlock()
 		s.Logger.Error("Double close of session")
 		return nil
 	}
 	close(s.output)
 	s.isOutputAcquired = false
 	s.output = nil // TODO: need the open function in interface?
+	hooks := s.closeHooks
+	s.closeHooks = nil
+	s.Mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
 	return nil
 }
 
+// OnClose registers f to be called when the session is closed, after its output is
+// closed. f must not block, it is called by Close.
+func (s *BaseSession) OnClose(f func()) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	s.closeHooks = append(s.closeHooks, f)
+}
+
 func (s *BaseSession) AcquireOutput() (<-chan *Message, bool) {
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
@@ -223,6 +245,12 @@ func (s *BaseSession) SendNotification(method string, params map[string]any) {
 
 // SendRequest sends a request and waits for a response
 func (s *BaseSession) SendRequest(method string, params interface{}, callback RequestCallback) (*schema.RequestID, error) {
+	return s.SendRequestContext(context.Background(), method, params, callback)
+}
+
+// SendRequestContext works like SendRequest; the request continues the trace of the span
+// carried by ctx, see tracing.SpanFromContext.
+func (s *BaseSession) SendRequestContext(ctx context.Context, method string, params interface{}, callback RequestCallback) (*schema.RequestID, error) {
 	if s.GetStatus() != StatusConnected && method != "initialize" {
 		s.Logger.Warn("Request sent to not connected session",
 			zap.String("method", method),
@@ -242,11 +270,12 @@ func (s *BaseSession) SendRequest(method string, params interface{}, callback Re
 	}
 
 	msg := &Message{
-		ID:        &msgID,
-		Method:    &method,
-		Session:   s,
-		Params:    jsonParams,
-		Timestamp: time.Now(),
+		ID:          &msgID,
+		Method:      &method,
+		Session:     s,
+		Params:      jsonParams,
+		Timestamp:   time.Now(),
+		TraceParent: tracing.SpanFromContext(ctx).Traceparent(),
 	}
 
 	s.RequestManager.RegisterRequest(&msgID, callback)
@@ -257,18 +286,27 @@ func (s *BaseSession) SendRequest(method string, params interface{}, callback Re
 	return &msgID, nil
 }
 
+// MaxListPages bounds the pages of a paginated list SendRequestSync fetches, so a server
+// handing out cursors endlessly cannot keep it going.
+const MaxListPages = 100
+
+// SendRequestSync sends a request of a paginated list method and emits the answers to
+// it and to the requests of the following pages, up to MaxListPages of them.
 func (s *BaseSession) SendRequestSync(method string, params interface{}) <-chan *Message {
 	resultChan := make(chan *Message, 1)
 	pendingRequests := &atomic.Int32{}
+	pages := &atomic.Int32{}
 
 	var reader func(msg *Message)
 	reader = func(msg *Message) {
 		if msg.Result != nil {
 			var paginated schema.PaginatedResult
 			if err := json.Unmarshal(*msg.Result, &paginated); err == nil {
-				if paginated.NextCursor != nil {
+				if paginated.NextCursor != nil && pages.Add(1) < MaxListPages {
 					pendingRequests.Add(1)
 					s.SendRequest(method, &schema.PaginatedRequestParams{Cursor: paginated.NextCursor}, reader)
+				} else if paginated.NextCursor != nil {
+					s.Logger.Warn("Stopped fetching the pages of a list at the page limit", zap.String("method", method), zap.Int("maxPages", MaxListPages))
 				}
 			}
 		}
@@ -316,6 +354,9 @@ func (s *BaseSession) SendResponse(msgId *schema.RequestID, result interface{},
 		}
 		jsonResult = nil // Ensure result is nil when sending an error
 		result = nil     // Ensure original result interface is nil too
+	} else if raw, ok := result.(json.RawMessage); ok {
+		// Already encoded (e.g. relayed verbatim from a backend), keep the bytes as is
+		jsonResult = &raw
 	} else if result != nil {
 		// Marshal the successful result
 		data, marshalErr := json.Marshal(result)
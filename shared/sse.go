@@ -0,0 +1,135 @@
+package shared
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// SSEPingEvent is the event type of keepalive events sent by gate4ai servers.
+const SSEPingEvent = "ping"
+
+// SSEEvent is a dispatched Server-Sent Event.
+type SSEEvent struct {
+	ID    string
+	Event string // "message" if the stream did not name the event
+	Data  string
+}
+
+// SSEReader parses a text/event-stream into events. Comment frames (lines starting
+// with ':') and ping events are heartbeats: they are counted but never returned.
+// Frames without data are skipped and counted too.
+type SSEReader struct {
+	reader     *bufio.Reader
+	logger     *zap.Logger // Frame logging is off when nil
+	heartbeats atomic.Int64
+	skipped    atomic.Int64
+}
+
+// SSEReaderOption configures an SSEReader.
+type SSEReaderOption func(*SSEReader)
+
+// WithSSEFrameLogging logs every heartbeat and skipped frame, with running counts,
+// at debug level. Meant for diagnosing stalled streams; off by default.
+func WithSSEFrameLogging(logger *zap.Logger) SSEReaderOption {
+	return func(r *SSEReader) {
+		r.logger = logger
+	}
+}
+
+// NewSSEReader creates a reader of the event stream r.
+func NewSSEReader(r io.Reader, opts ...SSEReaderOption) *SSEReader {
+	reader := &SSEReader{reader: bufio.NewReader(r)}
+	for _, opt := range opts {
+		opt(reader)
+	}
+	return reader
+}
+
+// Heartbeats returns the number of comment frames and ping events read so far.
+func (r *SSEReader) Heartbeats() int64 {
+	return r.heartbeats.Load()
+}
+
+// Skipped returns the number of frames read so far that carried no data.
+func (r *SSEReader) Skipped() int64 {
+	return r.skipped.Load()
+}
+
+// Next returns the next event with data. It returns io.EOF when the stream ends;
+// an incomplete trailing frame is discarded.
+func (r *SSEReader) Next() (*SSEEvent, error) {
+	var (
+		event      SSEEvent
+		data       []string
+		hasData    bool
+		comment    bool
+		frameLines int
+	)
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				// Unterminated last line: the frame is incomplete
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if frameLines == 0 {
+				continue // Blank line between frames
+			}
+			if ev, ok := r.dispatch(&event, data, hasData, comment); ok {
+				return ev, nil
+			}
+			event, data, hasData, comment, frameLines = SSEEvent{}, nil, false, false, 0
+			continue
+		}
+		frameLines++
+
+		if strings.HasPrefix(line, ":") {
+			comment = true
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		}
+	}
+}
+
+// dispatch completes a frame. Heartbeats and frames without data are counted and
+// reported as not dispatched.
+func (r *SSEReader) dispatch(event *SSEEvent, data []string, hasData bool, comment bool) (*SSEEvent, bool) {
+	if event.Event == SSEPingEvent || (comment && !hasData) {
+		count := r.heartbeats.Add(1)
+		if r.logger != nil {
+			r.logger.Debug("SSE heartbeat received", zap.Bool("comment", comment), zap.String("event", event.Event), zap.Int64("heartbeats", count))
+		}
+		return nil, false
+	}
+	if !hasData {
+		count := r.skipped.Add(1)
+		if r.logger != nil {
+			r.logger.Debug("SSE frame without data skipped", zap.String("event", event.Event), zap.String("id", event.ID), zap.Int64("skipped", count))
+		}
+		return nil, false
+	}
+	if event.Event == "" {
+		event.Event = "message"
+	}
+	event.Data = strings.Join(data, "\n")
+	return event, true
+}
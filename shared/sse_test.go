@@ -0,0 +1,72 @@
+package shared
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const heartbeatStream = ": connected\n\n" +
+	"event: ping\ndata: {}\n\n" +
+	"id: 1\nevent: message\ndata: {\"a\":1}\n\n" +
+	"retry: 1000\n\n" +
+	": keepalive\r\n\r\n" +
+	"data: line1\ndata: line2\n\n" +
+	"data: incomplete"
+
+func readAll(t *testing.T, reader *SSEReader) []*SSEEvent {
+	t.Helper()
+	var events []*SSEEvent
+	for {
+		event, err := reader.Next()
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestSSEReaderSkipsHeartbeats(t *testing.T) {
+	reader := NewSSEReader(strings.NewReader(heartbeatStream))
+	events := readAll(t, reader)
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(events), events)
+	}
+	if events[0].ID != "1" || events[0].Event != "message" || events[0].Data != `{"a":1}` {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if events[1].Event != "message" || events[1].Data != "line1\nline2" {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+	if reader.Heartbeats() != 3 {
+		t.Errorf("Heartbeats = %d, want 3", reader.Heartbeats())
+	}
+	if reader.Skipped() != 1 {
+		t.Errorf("Skipped = %d, want 1", reader.Skipped())
+	}
+}
+
+func TestSSEReaderFrameLogging(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	reader := NewSSEReader(strings.NewReader(heartbeatStream), WithSSEFrameLogging(zap.New(core)))
+	readAll(t, reader)
+
+	heartbeats := logs.FilterMessage("SSE heartbeat received").All()
+	if len(heartbeats) != 3 {
+		t.Fatalf("Expected 3 heartbeat log entries, got %d", len(heartbeats))
+	}
+	if count := heartbeats[2].ContextMap()["heartbeats"]; count != int64(3) {
+		t.Errorf("Last heartbeat entry should carry the running count 3, got %v", count)
+	}
+	if skipped := logs.FilterMessage("SSE frame without data skipped").Len(); skipped != 1 {
+		t.Errorf("Expected 1 skipped frame log entry, got %d", skipped)
+	}
+}
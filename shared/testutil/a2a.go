@@ -0,0 +1,84 @@
+package testutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+)
+
+// PointerTo returns a pointer to a copy of v, for filling optional schema fields.
+func PointerTo[T any](v T) *T {
+	return &v
+}
+
+// NewTextPart creates a text part.
+func NewTextPart(text string) a2aSchema.Part {
+	data, _ := json.Marshal(a2aSchema.TextPart{Type: "text", Text: text})
+	return a2aSchema.Part(data)
+}
+
+// NewDataPart creates a structured data part.
+func NewDataPart(data map[string]interface{}) a2aSchema.Part {
+	encoded, _ := json.Marshal(a2aSchema.DataPart{Type: "data", Data: data})
+	return a2aSchema.Part(encoded)
+}
+
+// NewTextMessage creates a message with a single text part.
+// Role is "user" or "agent".
+func NewTextMessage(role string, text string) a2aSchema.Message {
+	return a2aSchema.Message{
+		Role:  role,
+		Parts: []a2aSchema.Part{NewTextPart(text)},
+	}
+}
+
+// NewTaskSendParams creates tasks/send parameters for the task with a user text message.
+// An empty sessionID leaves the session unset.
+func NewTaskSendParams(taskID string, sessionID string, text string) a2aSchema.TaskSendParams {
+	params := a2aSchema.TaskSendParams{
+		ID:      taskID,
+		Message: NewTextMessage("user", text),
+	}
+	if sessionID != "" {
+		params.SessionID = PointerTo(sessionID)
+	}
+	return params
+}
+
+// TextOf concatenates the text parts of the message, skipping other part types.
+func TextOf(msg *a2aSchema.Message) string {
+	if msg == nil {
+		return ""
+	}
+	var text string
+	for _, part := range msg.Parts {
+		if tp, err := a2aSchema.AsTextPart(part); err == nil {
+			text += tp.Text
+		}
+	}
+	return text
+}
+
+// AssertTaskState fails the test if the task is nil or not in the expected state.
+func AssertTaskState(t testing.TB, task *a2aSchema.Task, want a2aSchema.TaskState) {
+	t.Helper()
+	if task == nil {
+		t.Fatalf("Expected task in state %q, got nil task", want)
+	}
+	if task.Status.State != want {
+		t.Fatalf("Task %s: expected state %q, got %q (message: %q)", task.ID, want, task.Status.State, TextOf(task.Status.Message))
+	}
+}
+
+// AssertTaskFinal fails the test if the task is not in a final state
+// (completed, canceled or failed).
+func AssertTaskFinal(t testing.TB, task *a2aSchema.Task) {
+	t.Helper()
+	if task == nil {
+		t.Fatalf("Expected a finished task, got nil task")
+	}
+	if !task.Status.State.IsFinal() {
+		t.Fatalf("Task %s: expected a final state, got %q", task.ID, task.Status.State)
+	}
+}
@@ -0,0 +1,596 @@
+// Package testutil provides fixtures and builders that reduce boilerplate in tests
+// working with gateway configuration and A2A schema types.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gate4ai/mcp/shared/config"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+type yamlUser struct {
+	Keys           []yamlKey         `yaml:"keys,omitempty"` // Hashes of apiKeys, set by Bytes
+	Subscribes     []string          `yaml:"subscribes,omitempty"`
+	DefaultBackend string            `yaml:"default_backend,omitempty"`
+	Params         map[string]string `yaml:"params,omitempty"`
+	RateLimit      *yamlRateLimit    `yaml:"rate_limit,omitempty"`
+	ClientCerts    []string          `yaml:"client_certs,omitempty"`
+
+	apiKeys []yamlKey // With the plain text key as Hash
+}
+
+type yamlKey struct {
+	Hash      string `yaml:"hash"`
+	NotBefore string `yaml:"not_before,omitempty"`
+	ExpiresAt string `yaml:"expires_at,omitempty"`
+}
+
+// MarshalYAML writes keys without a validity window as a plain hash.
+func (k yamlKey) MarshalYAML() (interface{}, error) {
+	if k.NotBefore == "" && k.ExpiresAt == "" {
+		return k.Hash, nil
+	}
+	type plain yamlKey
+	return plain(k), nil
+}
+
+type yamlRateLimit struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst,omitempty"`
+}
+
+type yamlBackend struct {
+	URL         string            `yaml:"url,omitempty"`
+	Weight      int               `yaml:"weight,omitempty"`
+	Command     []string          `yaml:"command,omitempty"`
+	Env         map[string]string `yaml:"env,omitempty"`
+	Bearer      string            `yaml:"bearer,omitempty"`
+	Passthrough bool              `yaml:"passthrough,omitempty"`
+	Timeout     string            `yaml:"timeout,omitempty"`
+	Retry       yamlRetry         `yaml:"retry,omitempty"`
+	Breaker     yamlBreaker       `yaml:"breaker,omitempty"`
+	Inject      []yamlInject      `yaml:"inject,omitempty"`
+
+	HandshakeRetry string        `yaml:"handshake_retry,omitempty"`
+	Replicas       []yamlReplica `yaml:"replicas,omitempty"`
+	Affinity       string        `yaml:"affinity,omitempty"`
+
+	IdleConnTimeout string `yaml:"idle_conn_timeout,omitempty"`
+	MaxIdleConns    int    `yaml:"max_idle_conns,omitempty"`
+
+	Hedge     yamlHedge     `yaml:"hedge,omitempty"`
+	ListCache yamlListCache `yaml:"list_cache,omitempty"`
+
+	AggregatePagination bool `yaml:"aggregate_pagination,omitempty"`
+}
+
+type yamlReplica struct {
+	URL    string `yaml:"url"`
+	Weight int    `yaml:"weight,omitempty"`
+}
+
+type yamlListCache struct {
+	Enabled    bool   `yaml:"enabled"`
+	TTL        string `yaml:"ttl,omitempty"`
+	MaxEntries int    `yaml:"max_entries,omitempty"`
+}
+
+type yamlHedge struct {
+	Methods []string `yaml:"methods,omitempty"`
+	Delay   string   `yaml:"delay,omitempty"`
+	Max     int      `yaml:"max,omitempty"`
+}
+
+type yamlInject struct {
+	Tool     string `yaml:"tool,omitempty"`
+	Param    string `yaml:"param"`
+	Argument string `yaml:"argument,omitempty"`
+	Override bool   `yaml:"override,omitempty"`
+}
+
+type yamlRetry struct {
+	Codes    []int  `yaml:"codes,omitempty"`
+	Attempts int    `yaml:"attempts,omitempty"`
+	Backoff  string `yaml:"backoff,omitempty"`
+}
+
+type yamlBreaker struct {
+	FailureThreshold int      `yaml:"failure_threshold,omitempty"`
+	OpenDuration     string   `yaml:"open_duration,omitempty"`
+	Faults           []string `yaml:"faults,omitempty"`
+}
+
+type yamlServer struct {
+	Address         string `yaml:"address,omitempty"`
+	Name            string `yaml:"name,omitempty"`
+	Version         string `yaml:"version,omitempty"`
+	LogLevel        string `yaml:"log_level,omitempty"`
+	LogPrivacy      string `yaml:"log_privacy,omitempty"`
+	IDGenerator     string `yaml:"id_generator,omitempty"`
+	HashAlgorithm   string `yaml:"hash_algorithm,omitempty"`
+	Authorization   string `yaml:"authorization,omitempty"`
+	FrontendAddress string `yaml:"frontend_address,omitempty"`
+	SSE             struct {
+		MaxStreams   int    `yaml:"max_streams,omitempty"`
+		QueueSize    int    `yaml:"queue_size,omitempty"`
+		QueueWait    string `yaml:"queue_wait,omitempty"`
+		KeepAlive    string `yaml:"keepalive,omitempty"`
+		DrainTimeout string `yaml:"drain_timeout,omitempty"`
+	} `yaml:"sse,omitempty"`
+	Compression struct {
+		MinSize int `yaml:"min_size,omitempty"`
+	} `yaml:"compression,omitempty"`
+	Sanitize struct {
+		Inbound  bool `yaml:"inbound,omitempty"`
+		Outbound bool `yaml:"outbound,omitempty"`
+	} `yaml:"sanitize,omitempty"`
+	ToolsListDeadline     string `yaml:"tools_list_deadline,omitempty"`
+	BackendHealthInterval string `yaml:"backend_health_interval,omitempty"`
+	Metrics               struct {
+		LatencyBuckets []float64 `yaml:"latency_buckets,omitempty"`
+		VersionLabels  bool      `yaml:"version_labels,omitempty"`
+		Enabled        bool      `yaml:"enabled,omitempty"`
+		Path           string    `yaml:"path,omitempty"`
+	} `yaml:"metrics,omitempty"`
+	Tracing struct {
+		Endpoint    string   `yaml:"endpoint,omitempty"`
+		SampleRatio *float64 `yaml:"sample_ratio,omitempty"`
+	} `yaml:"tracing,omitempty"`
+	AccessLog struct {
+		Enabled bool     `yaml:"enabled,omitempty"`
+		Level   string   `yaml:"level,omitempty"`
+		Fields  []string `yaml:"fields,omitempty"`
+	} `yaml:"access_log,omitempty"`
+	Methods struct {
+		Deny  []string `yaml:"deny,omitempty"`
+		Allow []string `yaml:"allow,omitempty"`
+	} `yaml:"methods,omitempty"`
+	Naming struct {
+		Prefix    bool   `yaml:"prefix,omitempty"`
+		Separator string `yaml:"separator,omitempty"`
+	} `yaml:"naming,omitempty"`
+}
+
+type yamlA2A struct {
+	Agents map[string]*yamlA2AAgent `yaml:"agents,omitempty"`
+}
+
+type yamlA2AAgent struct {
+	Path string `yaml:"path,omitempty"`
+	URL  string `yaml:"url,omitempty"`
+}
+
+// ConfigBuilder assembles a YAML gateway configuration with a fluent API.
+// API keys are given in plain text and stored hashed, as the gateway expects.
+type ConfigBuilder struct {
+	Server   yamlServer              `yaml:"server"`
+	Users    map[string]*yamlUser    `yaml:"users,omitempty"`
+	Backends map[string]*yamlBackend `yaml:"backends,omitempty"`
+	A2A      yamlA2A                 `yaml:"a2a,omitempty"`
+}
+
+// NewConfigBuilder creates a builder with the defaults used by most tests.
+func NewConfigBuilder() *ConfigBuilder {
+	b := &ConfigBuilder{
+		Users:    make(map[string]*yamlUser),
+		Backends: make(map[string]*yamlBackend),
+	}
+	b.Server.Name = "test-server"
+	b.Server.Version = "0.0.1"
+	b.Server.LogLevel = "debug"
+	return b
+}
+
+// WithServer sets the listen address, name and version of the server.
+func (b *ConfigBuilder) WithServer(address, name, version string) *ConfigBuilder {
+	b.Server.Address = address
+	b.Server.Name = name
+	b.Server.Version = version
+	return b
+}
+
+// WithLogLevel sets the log level.
+func (b *ConfigBuilder) WithLogLevel(level string) *ConfigBuilder {
+	b.Server.LogLevel = level
+	return b
+}
+
+// WithLogPrivacy sets the log privacy level ("none", "partial" or "strict").
+func (b *ConfigBuilder) WithLogPrivacy(level string) *ConfigBuilder {
+	b.Server.LogPrivacy = level
+	return b
+}
+
+// WithIDGenerator sets the scheme of generated IDs ("random", "uuid" or "ulid").
+func (b *ConfigBuilder) WithIDGenerator(name string) *ConfigBuilder {
+	b.Server.IDGenerator = name
+	return b
+}
+
+// WithHashAlgorithm stores the API keys of all users hashed with the algorithm, e.g.
+// config.HashAlgorithmBcrypt.
+func (b *ConfigBuilder) WithHashAlgorithm(algorithm string) *ConfigBuilder {
+	b.Server.HashAlgorithm = algorithm
+	return b
+}
+
+// WithPrefixedNames lists every tool, prompt and resource prefixed with the ID of its
+// backend and separator, or config.DefaultNameSeparator if separator is empty.
+func (b *ConfigBuilder) WithPrefixedNames(separator string) *ConfigBuilder {
+	b.Server.Naming.Prefix = true
+	b.Server.Naming.Separator = separator
+	return b
+}
+
+// WithMethodsDeny rejects the methods matching the patterns for everyone.
+func (b *ConfigBuilder) WithMethodsDeny(patterns ...string) *ConfigBuilder {
+	b.Server.Methods.Deny = append(b.Server.Methods.Deny, patterns...)
+	return b
+}
+
+// WithMethodsAllow accepts only the methods matching the patterns.
+func (b *ConfigBuilder) WithMethodsAllow(patterns ...string) *ConfigBuilder {
+	b.Server.Methods.Allow = append(b.Server.Methods.Allow, patterns...)
+	return b
+}
+
+// WithAuthorization sets the authorization mode ("users_only", "marked_methods" or "none").
+func (b *ConfigBuilder) WithAuthorization(mode string) *ConfigBuilder {
+	b.Server.Authorization = mode
+	return b
+}
+
+// WithSSEMaxStreams sets the server-wide limit of concurrent SSE streams.
+func (b *ConfigBuilder) WithSSEMaxStreams(max int) *ConfigBuilder {
+	b.Server.SSE.MaxStreams = max
+	return b
+}
+
+// WithSSEQueue lets size streams over the limit wait up to wait (e.g. "2s") for a slot.
+func (b *ConfigBuilder) WithSSEQueue(size int, wait string) *ConfigBuilder {
+	b.Server.SSE.QueueSize = size
+	b.Server.SSE.QueueWait = wait
+	return b
+}
+
+// WithSSEKeepAlive sets the interval (e.g. "15s") of keepalive comments on open SSE streams.
+func (b *ConfigBuilder) WithSSEKeepAlive(interval string) *ConfigBuilder {
+	b.Server.SSE.KeepAlive = interval
+	return b
+}
+
+// WithSSEDrainTimeout sets how long (e.g. "15s") open SSE streams are drained on shutdown.
+func (b *ConfigBuilder) WithSSEDrainTimeout(timeout string) *ConfigBuilder {
+	b.Server.SSE.DrainTimeout = timeout
+	return b
+}
+
+// WithCompressionMinSize sets the size in bytes from which JSON responses are compressed,
+// negative disables compression.
+func (b *ConfigBuilder) WithCompressionMinSize(size int) *ConfigBuilder {
+	b.Server.Compression.MinSize = size
+	return b
+}
+
+// WithSanitizeText enables stripping of control sequences from client text per direction.
+func (b *ConfigBuilder) WithSanitizeText(inbound, outbound bool) *ConfigBuilder {
+	b.Server.Sanitize.Inbound = inbound
+	b.Server.Sanitize.Outbound = outbound
+	return b
+}
+
+// WithBackendHealthInterval sets how often the gateway health checks backends, e.g. "50ms".
+func (b *ConfigBuilder) WithBackendHealthInterval(interval string) *ConfigBuilder {
+	b.Server.BackendHealthInterval = interval
+	return b
+}
+
+// WithToolsListDeadline sets how long tools/list waits for backends, e.g. "500ms".
+func (b *ConfigBuilder) WithToolsListDeadline(deadline string) *ConfigBuilder {
+	b.Server.ToolsListDeadline = deadline
+	return b
+}
+
+// WithMetricsLatencyBuckets sets the bucket bounds, in seconds, of the latency histograms.
+func (b *ConfigBuilder) WithMetricsLatencyBuckets(buckets ...float64) *ConfigBuilder {
+	b.Server.Metrics.LatencyBuckets = buckets
+	return b
+}
+
+// WithMetricsVersionLabels counts relayed requests by negotiated protocol versions.
+func (b *ConfigBuilder) WithMetricsVersionLabels() *ConfigBuilder {
+	b.Server.Metrics.VersionLabels = true
+	return b
+}
+
+// WithMetrics serves metrics at path, the default path if empty.
+func (b *ConfigBuilder) WithMetrics(path string) *ConfigBuilder {
+	b.Server.Metrics.Enabled = true
+	b.Server.Metrics.Path = path
+	return b
+}
+
+// WithTracing exports spans to the OTLP/HTTP collector at endpoint, sampling ratio of
+// the traces started by the gateway.
+func (b *ConfigBuilder) WithTracing(endpoint string, ratio float64) *ConfigBuilder {
+	b.Server.Tracing.Endpoint = endpoint
+	b.Server.Tracing.SampleRatio = &ratio
+	return b
+}
+
+// WithAccessLog enables the access log with entries at level holding fields, the
+// defaults if empty.
+func (b *ConfigBuilder) WithAccessLog(level string, fields ...string) *ConfigBuilder {
+	b.Server.AccessLog.Enabled = true
+	b.Server.AccessLog.Level = level
+	b.Server.AccessLog.Fields = fields
+	return b
+}
+
+// WithUser adds the user (if needed) with the plain text API key and backend subscriptions.
+func (b *ConfigBuilder) WithUser(userID string, apiKey string, subscribes ...string) *ConfigBuilder {
+	user := b.user(userID)
+	if apiKey != "" {
+		user.apiKeys = append(user.apiKeys, yamlKey{Hash: apiKey})
+	}
+	user.Subscribes = append(user.Subscribes, subscribes...)
+	return b
+}
+
+// WithUserKeyValidity adds the user (if needed) with the plain text API key valid from
+// notBefore until expiresAt, RFC3339 timestamps or "" for no bound.
+func (b *ConfigBuilder) WithUserKeyValidity(userID string, apiKey string, notBefore string, expiresAt string) *ConfigBuilder {
+	user := b.user(userID)
+	user.apiKeys = append(user.apiKeys, yamlKey{Hash: apiKey, NotBefore: notBefore, ExpiresAt: expiresAt})
+	return b
+}
+
+// WithUserDefaultBackend sets the default backend of the user.
+func (b *ConfigBuilder) WithUserDefaultBackend(userID string, backendID string) *ConfigBuilder {
+	b.user(userID).DefaultBackend = backendID
+	return b
+}
+
+// WithUserRateLimit limits the requests of the user across all their sessions to rps
+// per second and burst at once (0 for the default).
+func (b *ConfigBuilder) WithUserRateLimit(userID string, rps float64, burst int) *ConfigBuilder {
+	b.user(userID).RateLimit = &yamlRateLimit{RPS: rps, Burst: burst}
+	return b
+}
+
+// WithUserClientCerts maps client certificate CNs or SANs to the user.
+func (b *ConfigBuilder) WithUserClientCerts(userID string, identities ...string) *ConfigBuilder {
+	user := b.user(userID)
+	user.ClientCerts = append(user.ClientCerts, identities...)
+	return b
+}
+
+// WithUserParam sets a parameter of the user.
+func (b *ConfigBuilder) WithUserParam(userID string, name string, value string) *ConfigBuilder {
+	user := b.user(userID)
+	if user.Params == nil {
+		user.Params = make(map[string]string)
+	}
+	user.Params[name] = value
+	return b
+}
+
+// WithBackend adds a backend with the given URL.
+func (b *ConfigBuilder) WithBackend(backendID string, url string) *ConfigBuilder {
+	b.Backends[backendID] = &yamlBackend{URL: url}
+	return b
+}
+
+// WithCommandBackend adds a backend running command as a child process speaking MCP
+// over stdio.
+func (b *ConfigBuilder) WithCommandBackend(backendID string, command ...string) *ConfigBuilder {
+	b.Backends[backendID] = &yamlBackend{Command: command}
+	return b
+}
+
+// WithBackendEnv sets an environment variable of the command of an already added backend.
+func (b *ConfigBuilder) WithBackendEnv(backendID string, name string, value string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		if backend.Env == nil {
+			backend.Env = make(map[string]string)
+		}
+		backend.Env[name] = value
+	}
+	return b
+}
+
+// WithBackendBearer sets the bearer token used to connect to an already added backend.
+func (b *ConfigBuilder) WithBackendBearer(backendID string, bearer string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Bearer = bearer
+	}
+	return b
+}
+
+// WithBackendPassthrough enables passthrough mode for an already added backend.
+func (b *ConfigBuilder) WithBackendPassthrough(backendID string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Passthrough = true
+	}
+	return b
+}
+
+// WithBackendRetry sets the JSON-RPC error codes retried for an already added backend.
+// Zero attempts and an empty backoff (e.g. "10ms") select the gateway defaults.
+func (b *ConfigBuilder) WithBackendRetry(backendID string, codes []int, attempts int, backoff string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Retry = yamlRetry{Codes: codes, Attempts: attempts, Backoff: backoff}
+	}
+	return b
+}
+
+// WithBackendBreaker enables the circuit breaker of an already added backend. An empty
+// open duration (e.g. "1s") and no faults select the gateway defaults.
+func (b *ConfigBuilder) WithBackendBreaker(backendID string, failureThreshold int, openDuration string, faults ...string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Breaker = yamlBreaker{FailureThreshold: failureThreshold, OpenDuration: openDuration, Faults: faults}
+	}
+	return b
+}
+
+// WithBackendHandshakeRetry sets how often a failed handshake with an already added
+// backend is retried, e.g. "100ms".
+func (b *ConfigBuilder) WithBackendHandshakeRetry(backendID string, interval string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.HandshakeRetry = interval
+	}
+	return b
+}
+
+// WithBackendReplicas adds further URLs to an already added backend, chosen for client
+// sessions according to affinity ("none", "session" or "hash").
+func (b *ConfigBuilder) WithBackendReplicas(backendID string, affinity string, urls ...string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Affinity = affinity
+		for _, url := range urls {
+			backend.Replicas = append(backend.Replicas, yamlReplica{URL: url})
+		}
+	}
+	return b
+}
+
+// WithBackendWeight sets the share of the requests of the URL or a replica of an already
+// added backend, relative to the others; 0 selects the default weight of 1.
+func (b *ConfigBuilder) WithBackendWeight(backendID string, url string, weight int) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		if backend.URL == url {
+			backend.Weight = weight
+		}
+		for i := range backend.Replicas {
+			if backend.Replicas[i].URL == url {
+				backend.Replicas[i].Weight = weight
+			}
+		}
+	}
+	return b
+}
+
+// WithBackendTimeout sets the timeout of each request to an already added backend,
+// e.g. "100ms"; "" selects the gateway defaults.
+func (b *ConfigBuilder) WithBackendTimeout(backendID string, timeout string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Timeout = timeout
+	}
+	return b
+}
+
+// WithBackendIdleConns sets how long idle connections to an already added backend are
+// kept, e.g. "100ms", and how many per host; "" and 0 select the gateway defaults.
+func (b *ConfigBuilder) WithBackendIdleConns(backendID string, timeout string, maxIdle int) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.IdleConnTimeout = timeout
+		backend.MaxIdleConns = maxIdle
+	}
+	return b
+}
+
+// WithBackendHedge hedges the methods of an already added backend over its replicas,
+// asking another replica after delay, e.g. "50ms", up to max times; "" and 0 select the
+// gateway defaults.
+func (b *ConfigBuilder) WithBackendHedge(backendID string, delay string, max int, methods ...string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Hedge = yamlHedge{Methods: methods, Delay: delay, Max: max}
+	}
+	return b
+}
+
+// WithBackendListCache caches the answers of an already added backend to list methods
+// for ttl, e.g. "1m", keeping at most maxEntries; "" and 0 select the gateway defaults.
+func (b *ConfigBuilder) WithBackendListCache(backendID string, ttl string, maxEntries int) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.ListCache = yamlListCache{Enabled: true, TTL: ttl, MaxEntries: maxEntries}
+	}
+	return b
+}
+
+// WithBackendAggregatePagination makes the gateway list all pages of the tools of an
+// already added backend at once.
+func (b *ConfigBuilder) WithBackendAggregatePagination(backendID string) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.AggregatePagination = true
+	}
+	return b
+}
+
+// WithBackendInjection adds a rule injecting a user param into tool calls of an already added backend.
+func (b *ConfigBuilder) WithBackendInjection(backendID string, injection config.ArgumentInjection) *ConfigBuilder {
+	if backend, ok := b.Backends[backendID]; ok {
+		backend.Inject = append(backend.Inject, yamlInject{
+			Tool:     injection.Tool,
+			Param:    injection.Param,
+			Argument: injection.Argument,
+			Override: injection.Override,
+		})
+	}
+	return b
+}
+
+// WithA2AAgent adds an A2A agent whose card is served at path and whose methods are
+// served at the path of url; empty values get the defaults.
+func (b *ConfigBuilder) WithA2AAgent(name string, path string, url string) *ConfigBuilder {
+	if b.A2A.Agents == nil {
+		b.A2A.Agents = make(map[string]*yamlA2AAgent)
+	}
+	b.A2A.Agents[name] = &yamlA2AAgent{Path: path, URL: url}
+	return b
+}
+
+func (b *ConfigBuilder) user(userID string) *yamlUser {
+	user, ok := b.Users[userID]
+	if !ok {
+		user = &yamlUser{}
+		b.Users[userID] = user
+	}
+	return user
+}
+
+// Bytes returns the YAML document of the configuration, with the API keys hashed with
+// the configured hash algorithm.
+func (b *ConfigBuilder) Bytes() ([]byte, error) {
+	for _, user := range b.Users {
+		user.Keys = nil
+		for _, apiKey := range user.apiKeys {
+			hash, err := config.HashAPIKeyWith(b.Server.HashAlgorithm, apiKey.Hash)
+			if err != nil {
+				return nil, err
+			}
+			apiKey.Hash = hash
+			user.Keys = append(user.Keys, apiKey)
+		}
+	}
+	return yaml.Marshal(b)
+}
+
+// Build writes the configuration to a temporary file and loads it as a YamlConfig.
+// The file is removed when the test finishes.
+func (b *ConfigBuilder) Build(t testing.TB) *config.YamlConfig {
+	t.Helper()
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	return NewYamlConfigFromBytes(t, data)
+}
+
+// NewYamlConfigFromBytes writes data to a temporary file and loads it as a YamlConfig.
+func NewYamlConfigFromBytes(t testing.TB, data []byte) *config.YamlConfig {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	cfg, err := config.NewYamlConfig(path, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
@@ -0,0 +1,295 @@
+package testutil_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	a2aSchema "github.com/gate4ai/mcp/shared/a2a/2025-draft/schema"
+	"github.com/gate4ai/mcp/shared/config"
+	"github.com/gate4ai/mcp/shared/testutil"
+)
+
+func TestConfigBuilderProducesYamlConfig(t *testing.T) {
+	cfg := testutil.NewConfigBuilder().
+		WithServer(":9999", "builder", "1.2.3").
+		WithAuthorization("marked_methods").
+		WithLogPrivacy("strict").
+		WithIDGenerator("ulid").
+		WithMethodsDeny("resources/*").
+		WithMethodsAllow("tools/list", "tools/call").
+		WithSSEMaxStreams(7).
+		WithSSEQueue(3, "2s").
+		WithSSEKeepAlive("20s").
+		WithSSEDrainTimeout("3s").
+		WithCompressionMinSize(512).
+		WithSanitizeText(false, true).
+		WithToolsListDeadline("1500ms").
+		WithBackendHealthInterval("45s").
+		WithMetricsLatencyBuckets(0.1, 1, 10).
+		WithMetricsVersionLabels().
+		WithMetrics("/prometheus").
+		WithTracing("http://collector:4318", 0.25).
+		WithAccessLog("debug", "user", "method").
+		WithUser("alice", "key-alice", "b1", "b2").
+		WithUserDefaultBackend("alice", "b2").
+		WithUserKeyValidity("alice", "key-alice-old", "", "2001-01-01T00:00:00Z").
+		WithUserRateLimit("alice", 2.5, 0).
+		WithUserClientCerts("alice", "alice.mesh.local").
+		WithUserParam("alice", "locale", "de-DE").
+		WithBackend("b1", "http://localhost:1/sse").
+		WithBackend("b2", "http://localhost:2/sse").
+		WithBackendBearer("b2", "secret").
+		WithBackendPassthrough("b2").
+		WithBackendTimeout("b2", "2m").
+		WithBackendRetry("b2", []int{-32002}, 2, "50ms").
+		WithBackendBreaker("b2", 5, "1s", "timeout").
+		WithBackendHandshakeRetry("b2", "2s").
+		WithBackendReplicas("b2", "session", "http://b2-replica/sse").
+		WithBackendWeight("b2", "http://b2-replica/sse", 3).
+		WithBackendIdleConns("b2", "10s", 3).
+		WithBackendHedge("b2", "50ms", 2, "resources/read").
+		WithBackendListCache("b2", "1m", 10).
+		WithBackendInjection("b2", config.ArgumentInjection{Tool: "t", Param: "locale", Argument: "lang", Override: true}).
+		WithCommandBackend("b3", "mcp-server", "--stdio").
+		WithBackendEnv("b3", "TOKEN", "secret").
+		Build(t)
+
+	if addr, _ := cfg.ListenAddr(); addr != ":9999" {
+		t.Errorf("ListenAddr = %q", addr)
+	}
+	if name, _ := cfg.ServerName(); name != "builder" {
+		t.Errorf("ServerName = %q", name)
+	}
+	if auth, _ := cfg.AuthorizationType(); auth != config.NotAuthorizedToMarkedMethods {
+		t.Errorf("AuthorizationType = %v", auth)
+	}
+	if level, _ := cfg.LogPrivacy(); level != config.LogPrivacyStrict {
+		t.Errorf("LogPrivacy = %q", level)
+	}
+	if name, _ := cfg.IDGenerator(); name != config.IDGeneratorULID {
+		t.Errorf("IDGenerator = %q", name)
+	}
+	if deny, _ := cfg.MethodsDeny(); len(deny) != 1 || deny[0] != "resources/*" {
+		t.Errorf("MethodsDeny = %v", deny)
+	}
+	if allow, _ := cfg.MethodsAllow(); len(allow) != 2 || allow[1] != "tools/call" {
+		t.Errorf("MethodsAllow = %v", allow)
+	}
+	if max, _ := cfg.SSEMaxStreams(); max != 7 {
+		t.Errorf("SSEMaxStreams = %d", max)
+	}
+	if size, _ := cfg.SSEQueueSize(); size != 3 {
+		t.Errorf("SSEQueueSize = %d", size)
+	}
+	if wait, _ := cfg.SSEQueueWait(); wait != 2*time.Second {
+		t.Errorf("SSEQueueWait = %v", wait)
+	}
+	if interval, _ := cfg.SSEKeepAlive(); interval != 20*time.Second {
+		t.Errorf("SSEKeepAlive = %v", interval)
+	}
+	if timeout, _ := cfg.SSEDrainTimeout(); timeout != 3*time.Second {
+		t.Errorf("SSEDrainTimeout = %v", timeout)
+	}
+	if size, _ := cfg.CompressionMinSize(); size != 512 {
+		t.Errorf("CompressionMinSize = %d", size)
+	}
+	if inbound, _ := cfg.SanitizeInboundText(); inbound {
+		t.Errorf("SanitizeInboundText = true")
+	}
+	if outbound, _ := cfg.SanitizeOutboundText(); !outbound {
+		t.Errorf("SanitizeOutboundText = false")
+	}
+	if deadline, _ := cfg.ToolsListDeadline(); deadline != 1500*time.Millisecond {
+		t.Errorf("ToolsListDeadline = %v", deadline)
+	}
+	if interval, _ := cfg.BackendHealthInterval(); interval != 45*time.Second {
+		t.Errorf("BackendHealthInterval = %v", interval)
+	}
+	if ids, _ := cfg.BackendIDs(); len(ids) != 3 || ids[0] != "b1" || ids[1] != "b2" || ids[2] != "b3" {
+		t.Errorf("BackendIDs = %v", ids)
+	}
+	if buckets, _ := cfg.MetricsLatencyBuckets(); len(buckets) != 3 || buckets[2] != 10 {
+		t.Errorf("MetricsLatencyBuckets = %v", buckets)
+	}
+	if enabled, _ := cfg.MetricsVersionLabels(); !enabled {
+		t.Errorf("MetricsVersionLabels = false")
+	}
+	if enabled, _ := cfg.MetricsEnabled(); !enabled {
+		t.Errorf("MetricsEnabled = false")
+	}
+	if path, _ := cfg.MetricsPath(); path != "/prometheus" {
+		t.Errorf("MetricsPath = %q", path)
+	}
+	if endpoint, _ := cfg.TracingEndpoint(); endpoint != "http://collector:4318" {
+		t.Errorf("TracingEndpoint = %q", endpoint)
+	}
+	if ratio, _ := cfg.TracingSampleRatio(); ratio != 0.25 {
+		t.Errorf("TracingSampleRatio = %v", ratio)
+	}
+	if enabled, _ := cfg.AccessLogEnabled(); !enabled {
+		t.Errorf("AccessLogEnabled = false")
+	}
+	if level, _ := cfg.AccessLogLevel(); level != "debug" {
+		t.Errorf("AccessLogLevel = %q", level)
+	}
+	if fields, _ := cfg.AccessLogFields(); len(fields) != 2 || fields[0] != "user" || fields[1] != "method" {
+		t.Errorf("AccessLogFields = %v", fields)
+	}
+	if userID, _ := cfg.GetUserIDByKeyHash(config.HashAPIKey("key-alice")); userID != "alice" {
+		t.Errorf("GetUserIDByKeyHash = %q", userID)
+	}
+	if _, err := cfg.GetUserIDByKey("key-alice-old"); !errors.Is(err, config.ErrKeyExpired) {
+		t.Errorf("GetUserIDByKey of an expired key = %v", err)
+	}
+	if subs, _ := cfg.GetUserSubscribes("alice"); len(subs) != 2 || subs[0] != "b1" || subs[1] != "b2" {
+		t.Errorf("GetUserSubscribes = %v", subs)
+	}
+	if def, _ := cfg.GetUserDefaultBackend("alice"); def != "b2" {
+		t.Errorf("GetUserDefaultBackend = %q", def)
+	}
+	if limit, _ := cfg.GetUserRateLimit("alice"); limit != (config.RateLimit{RPS: 2.5, Burst: 3}) {
+		t.Errorf("GetUserRateLimit = %+v", limit)
+	}
+	if userID, _ := cfg.GetUserIDByCertIdentity("alice.mesh.local"); userID != "alice" {
+		t.Errorf("GetUserIDByCertIdentity = %q", userID)
+	}
+	if params, _ := cfg.GetUserParams("alice"); params["locale"] != "de-DE" {
+		t.Errorf("GetUserParams = %v", params)
+	}
+	backend, err := cfg.GetBackend("b2")
+	if err != nil {
+		t.Fatalf("GetBackend: %v", err)
+	}
+	if backend.URL != "http://localhost:2/sse" || backend.Bearer != "secret" || !backend.Passthrough {
+		t.Errorf("GetBackend = %+v", backend)
+	}
+	if len(backend.RetryCodes) != 1 || backend.RetryCodes[0] != -32002 || backend.RetryAttempts != 2 || backend.RetryBackoff != 50*time.Millisecond {
+		t.Errorf("GetBackend retry = %v, %d, %v", backend.RetryCodes, backend.RetryAttempts, backend.RetryBackoff)
+	}
+	if backend.BreakerThreshold != 5 || backend.BreakerCooldown != time.Second || len(backend.BreakerFaults) != 1 || backend.BreakerFaults[0] != "timeout" {
+		t.Errorf("GetBackend breaker = %d, %v, %v", backend.BreakerThreshold, backend.BreakerCooldown, backend.BreakerFaults)
+	}
+	if backend.Timeout != 2*time.Minute {
+		t.Errorf("GetBackend timeout = %v", backend.Timeout)
+	}
+	if backend.HandshakeRetry != 2*time.Second {
+		t.Errorf("GetBackend handshake retry = %v", backend.HandshakeRetry)
+	}
+	if backend.Affinity != "session" || len(backend.Replicas) != 1 || backend.Replicas[0] != "http://b2-replica/sse" {
+		t.Errorf("GetBackend replicas = %q, %v", backend.Affinity, backend.Replicas)
+	}
+	if backend.Weight("http://b2-replica/sse") != 3 || backend.Weight(backend.URL) != 1 {
+		t.Errorf("GetBackend weights = %v", backend.Weights)
+	}
+	if backend.IdleConnTimeout != 10*time.Second || backend.MaxIdleConns != 3 {
+		t.Errorf("GetBackend idle conns = %v, %d", backend.IdleConnTimeout, backend.MaxIdleConns)
+	}
+	if len(backend.HedgeMethods) != 1 || backend.HedgeDelay != 50*time.Millisecond || backend.HedgeMax != 2 {
+		t.Errorf("GetBackend hedge = %v, %v, %d", backend.HedgeMethods, backend.HedgeDelay, backend.HedgeMax)
+	}
+	if !backend.ListCache || backend.ListCacheTTL != time.Minute || backend.ListCacheMaxEntries != 10 {
+		t.Errorf("GetBackend list cache = %v, %v, %d", backend.ListCache, backend.ListCacheTTL, backend.ListCacheMaxEntries)
+	}
+	if want := (config.ArgumentInjection{Tool: "t", Param: "locale", Argument: "lang", Override: true}); len(backend.Inject) != 1 || backend.Inject[0] != want {
+		t.Errorf("GetBackend inject = %+v", backend.Inject)
+	}
+	backend, err = cfg.GetBackend("b3")
+	if err != nil {
+		t.Fatalf("GetBackend: %v", err)
+	}
+	if len(backend.Command) != 2 || backend.Command[1] != "--stdio" || len(backend.Env) != 1 || backend.Env[0] != "TOKEN=secret" {
+		t.Errorf("GetBackend command = %v, env = %v", backend.Command, backend.Env)
+	}
+}
+
+func TestConfigBuilderHashesKeysWithAlgorithm(t *testing.T) {
+	cfg := testutil.NewConfigBuilder().
+		WithHashAlgorithm(config.HashAlgorithmBcrypt).
+		WithUser("alice", "key-alice").
+		Build(t)
+	if userID, err := cfg.GetUserIDByKey("key-alice"); err != nil || userID != "alice" {
+		t.Errorf("GetUserIDByKey = %q, %v", userID, err)
+	}
+}
+
+func TestNewYamlConfigFromBytes(t *testing.T) {
+	cfg := testutil.NewYamlConfigFromBytes(t, []byte("server:\n  name: raw\n"))
+	if name, _ := cfg.ServerName(); name != "raw" {
+		t.Errorf("ServerName = %q", name)
+	}
+}
+
+func TestNewTaskSendParams(t *testing.T) {
+	params := testutil.NewTaskSendParams("task-1", "session-1", "hello")
+	if params.ID != "task-1" || params.SessionID == nil || *params.SessionID != "session-1" {
+		t.Fatalf("Unexpected params: %+v", params)
+	}
+	if params.Message.Role != "user" || testutil.TextOf(&params.Message) != "hello" {
+		t.Fatalf("Unexpected message: %+v", params.Message)
+	}
+	if testutil.NewTaskSendParams("task-2", "", "x").SessionID != nil {
+		t.Fatalf("Expected an empty session ID to be left unset")
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"id":"task-1","sessionId":"session-1","message":{"role":"user","parts":[{"type":"text","text":"hello"}]}}`
+	if string(data) != want {
+		t.Fatalf("Unexpected JSON:\n got: %s\nwant: %s", data, want)
+	}
+}
+
+func TestMessageHelpers(t *testing.T) {
+	msg := testutil.NewTextMessage("agent", "a")
+	msg.Parts = append(msg.Parts, testutil.NewDataPart(map[string]interface{}{"k": "v"}), testutil.NewTextPart("b"))
+	if got := testutil.TextOf(&msg); got != "ab" {
+		t.Fatalf("TextOf = %q", got)
+	}
+	if partType, _ := a2aSchema.GetPartType(msg.Parts[1]); partType != "data" {
+		t.Fatalf("Expected data part, got %q", partType)
+	}
+	if testutil.TextOf(nil) != "" {
+		t.Fatalf("Expected empty text for nil message")
+	}
+}
+
+func TestAssertTaskState(t *testing.T) {
+	task := &a2aSchema.Task{ID: "t", Status: a2aSchema.TaskStatus{State: a2aSchema.TaskStateCompleted}}
+	testutil.AssertTaskState(t, task, a2aSchema.TaskStateCompleted)
+	testutil.AssertTaskFinal(t, task)
+
+	ft := &fakeT{TB: t}
+	func() {
+		defer func() { recover() }()
+		testutil.AssertTaskState(ft, task, a2aSchema.TaskStateWorking)
+	}()
+	if !ft.failed {
+		t.Fatalf("Expected AssertTaskState to fail on state mismatch")
+	}
+
+	ft = &fakeT{TB: t}
+	func() {
+		defer func() { recover() }()
+		testutil.AssertTaskFinal(ft, &a2aSchema.Task{Status: a2aSchema.TaskStatus{State: a2aSchema.TaskStateWorking}})
+	}()
+	if !ft.failed {
+		t.Fatalf("Expected AssertTaskFinal to fail for a working task")
+	}
+}
+
+// fakeT records Fatalf calls instead of stopping the enclosing test.
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.failed = true
+	panic("fatal")
+}
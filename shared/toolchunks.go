@@ -0,0 +1,46 @@
+package shared
+
+import (
+	"github.com/gate4ai/mcp/shared/mcp/2025/schema"
+)
+
+// Chunked tool results let a server deliver the content of a large tools/call result in
+// pieces, as it is produced, instead of buffering it in a single response. A client that
+// can receive notifications while the call is running (e.g. over an SSE stream) opts in
+// by setting ChunkedResultMetaKey in the _meta of the request params to a token of its
+// choice. The server then sends ToolResultChunkMethod notifications carrying the token,
+// followed by the response, whose _meta holds the number of chunks sent under the same
+// key and whose content follows the content of the chunks. Without the opt-in, servers
+// return the whole content in the response.
+const (
+	ToolResultChunkMethod = "notifications/tools/result_chunk"
+	ChunkedResultMetaKey  = "gate4ai/chunkedResult"
+)
+
+// ToolResultChunk is the params of a ToolResultChunkMethod notification.
+type ToolResultChunk struct {
+	Token   string           `json:"token"`
+	Index   int              `json:"index"` // 0 for the first chunk of a result
+	Content []schema.Content `json:"content"`
+}
+
+// ChunkedResultToken returns the token of a tools/call request opting in to chunked results.
+func ChunkedResultToken(params *schema.CallToolRequestParams) (string, bool) {
+	token, ok := params.Meta[ChunkedResultMetaKey].(string)
+	return token, ok && token != ""
+}
+
+// ChunkedResultCount returns the number of chunks sent ahead of a tools/call result.
+func ChunkedResultCount(result *schema.CallToolResult) int {
+	if result.Meta == nil {
+		return 0
+	}
+	switch count := (*result.Meta)[ChunkedResultMetaKey].(type) {
+	case float64: // Decoded from JSON
+		return int(count)
+	case int:
+		return count
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,227 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Spans are exported in batches, at most exportDelay after they ended
+	exportDelay     = time.Second
+	exportBatchSize = 512
+	// Ended spans waiting to be exported; more are dropped while the collector is slow
+	exportQueueSize = 4096
+	exportTimeout   = 10 * time.Second
+
+	scopeName = "github.com/gate4ai/mcp"
+)
+
+// Status code of OTLP spans that failed; the status of others is left unset
+const statusError = 2
+
+// exporter posts batches of spans to an OTLP/HTTP collector in the JSON encoding.
+type exporter struct {
+	url     string
+	service string
+	logger  *zap.Logger
+	client  *http.Client
+
+	queue    chan otlpSpan
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newExporter(url string, service string, logger *zap.Logger) *exporter {
+	e := &exporter{
+		url:     url,
+		service: service,
+		logger:  logger,
+		client:  &http.Client{Timeout: exportTimeout},
+		queue:   make(chan otlpSpan, exportQueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// add queues an ended span for export.
+func (e *exporter) add(span otlpSpan) {
+	select {
+	case <-e.stop:
+		return
+	default:
+	}
+	select {
+	case e.queue <- span:
+	default:
+		e.logger.Debug("Span export queue full, dropping span", zap.String("span", span.Name))
+	}
+}
+
+// run exports the queued spans until shutdown, then the remaining ones.
+func (e *exporter) run() {
+	defer close(e.done)
+	for {
+		var batch []otlpSpan
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+		case <-e.stop:
+			e.drain()
+			return
+		}
+		timer := time.NewTimer(exportDelay)
+	collect:
+		for len(batch) < exportBatchSize {
+			select {
+			case span := <-e.queue:
+				batch = append(batch, span)
+			case <-timer.C:
+				break collect
+			case <-e.stop:
+				break collect
+			}
+		}
+		timer.Stop()
+		e.post(batch)
+	}
+}
+
+// drain exports the spans left in the queue.
+func (e *exporter) drain() {
+	for {
+		var batch []otlpSpan
+	collect:
+		for len(batch) < exportBatchSize {
+			select {
+			case span := <-e.queue:
+				batch = append(batch, span)
+			default:
+				break collect
+			}
+		}
+		if len(batch) == 0 {
+			return
+		}
+		e.post(batch)
+	}
+}
+
+// shutdown stops the exporter once it exported the queued spans or ctx is done.
+func (e *exporter) shutdown(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// post sends a batch of spans to the collector. Failures are logged, the spans are lost.
+func (e *exporter) post(spans []otlpSpan) {
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpAttribute{stringAttribute("service.name", e.service)}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: spans}},
+	}}})
+	if err != nil {
+		e.logger.Error("Failed to encode spans", zap.Error(err))
+		return
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		e.logger.Warn("Failed to export spans", zap.String("url", e.url), zap.Int("spans", len(spans)), zap.Error(err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		e.logger.Warn("Collector rejected spans", zap.String("url", e.url), zap.Int("spans", len(spans)), zap.Int("status", resp.StatusCode))
+	}
+}
+
+// export converts the span to its OTLP form.
+func (s *Span) export(end time.Time, attributes [][2]string, err error) otlpSpan {
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              int(s.kind),
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+	}
+	if s.parentID != ([8]byte{}) {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	for _, attribute := range attributes {
+		span.Attributes = append(span.Attributes, stringAttribute(attribute[0], attribute[1]))
+	}
+	if err != nil {
+		span.Status = otlpStatus{Code: statusError, Message: err.Error()}
+	}
+	return span
+}
+
+func stringAttribute(key string, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
+
+// OTLP/HTTP JSON encoding of ExportTraceServiceRequest, as far as the gateway uses it.
+// Trace and span IDs are hex strings and 64-bit integers decimal strings.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
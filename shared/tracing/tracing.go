@@ -0,0 +1,212 @@
+// Package tracing records OpenTelemetry spans of the requests handled by the gateway and
+// exports them to an OTLP/HTTP collector. Trace context is read from and passed on in W3C
+// traceparent headers.
+//
+// A nil *Tracer and a nil *Span are valid and do nothing, so code paths can be traced
+// unconditionally and tracing stays off unless a Tracer is configured.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TraceparentHeader is the HTTP header carrying the trace context (W3C Trace Context).
+const TraceparentHeader = "traceparent"
+
+// SpanKind tells how a span relates to the other spans of its trace (OTLP values).
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2 // A request received from a client
+	KindClient   SpanKind = 3 // A request sent to a backend
+)
+
+// Span attributes set by the gateway
+const (
+	AttrMethod    = "rpc.method"      // JSON-RPC method
+	AttrBackend   = "gate4ai.backend" // ID of the backend a request is sent to
+	AttrTransport = "gate4ai.transport"
+)
+
+// Tracer starts spans and exports the sampled ones when they end.
+type Tracer struct {
+	ratioBound uint64 // Root spans are sampled if the low bits of their trace ID are below it
+	exporter   *exporter
+}
+
+// New creates a tracer exporting spans of serviceName to the OTLP/HTTP collector at
+// endpoint, e.g. "http://collector:4318". Of the traces started by the gateway, the share
+// sampleRatio (0 to 1) is sampled; requests carrying a traceparent follow its sampled flag.
+func New(endpoint string, serviceName string, sampleRatio float64, logger *zap.Logger) (*Tracer, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("tracing endpoint must not be empty")
+	}
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio must be between 0 and 1, got %v", sampleRatio)
+	}
+	return &Tracer{
+		ratioBound: uint64(sampleRatio * (1 << 63)),
+		exporter:   newExporter(strings.TrimSuffix(endpoint, "/")+"/v1/traces", serviceName, logger.Named("tracing")),
+	}, nil
+}
+
+// Start starts a span continuing the trace of traceparent, or a new trace if it is empty
+// or invalid.
+func (t *Tracer) Start(name string, kind SpanKind, traceparent string) *Span {
+	if t == nil {
+		return nil
+	}
+	span := &Span{tracer: t, name: name, kind: kind, start: time.Now()}
+	if parent, ok := parseTraceparent(traceparent); ok {
+		span.traceID, span.parentID, span.sampled = parent.traceID, parent.spanID, parent.sampled
+	} else {
+		rand.Read(span.traceID[:])
+		span.sampled = binary.BigEndian.Uint64(span.traceID[8:])>>1 < t.ratioBound
+	}
+	rand.Read(span.spanID[:])
+	return span
+}
+
+// Shutdown exports the spans that ended and stops the exporter. Spans ending later are
+// dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.exporter.shutdown(ctx)
+}
+
+// Span is an operation of a trace. Its methods are safe for concurrent use.
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte // Zero for the root span of a trace
+	sampled  bool
+	name     string
+	kind     SpanKind
+	start    time.Time
+
+	mu         sync.Mutex
+	attributes [][2]string
+	ended      bool
+}
+
+// StartChild starts a span of the same trace with s as its parent.
+func (s *Span) StartChild(name string, kind SpanKind) *Span {
+	if s == nil {
+		return nil
+	}
+	return s.tracer.Start(name, kind, s.Traceparent())
+}
+
+// SetAttribute sets a string attribute of the span.
+func (s *Span) SetAttribute(key string, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, [2]string{key, value})
+}
+
+// End ends the span, with an error status if err is not nil, and exports it if it is
+// sampled. Only the first call counts.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	attributes := s.attributes
+	s.mu.Unlock()
+
+	if s.sampled {
+		s.tracer.exporter.add(s.export(end, attributes, err))
+	}
+}
+
+// Traceparent returns the traceparent header value that makes s the parent of the spans
+// of the receiver, or "" for a nil span.
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	flags := "00"
+	if s.sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(s.traceID[:]) + "-" + hex.EncodeToString(s.spanID[:]) + "-" + flags
+}
+
+// spanContext is the trace context received in a traceparent header.
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+// parseTraceparent parses a traceparent header value: version, trace ID, parent span ID
+// and flags in lower case hex, separated by dashes. Future versions may append fields.
+func parseTraceparent(value string) (spanContext, bool) {
+	var sc spanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	for _, part := range parts[:4] {
+		if strings.ToLower(part) != part {
+			return sc, false
+		}
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, false
+	}
+	if sc.traceID == ([16]byte{}) || sc.spanID == ([8]byte{}) {
+		return sc, false
+	}
+	sc.sampled = flags[0]&0x01 != 0
+	return sc, true
+}
+
+type contextKey struct{}
+
+// ContextWithSpan returns a context carrying span, see SpanFromContext.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(contextKey{}).(*Span)
+	return span
+}
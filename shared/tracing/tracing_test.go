@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a fake OTLP/HTTP collector.
+type collector struct {
+	mu    sync.Mutex
+	spans []otlpSpan
+}
+
+func startCollector(t *testing.T) (*collector, string) {
+	t.Helper()
+	c := &collector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return c, server.URL
+}
+
+// received returns the spans received so far by kind.
+func (c *collector) received() map[int]otlpSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spans := make(map[int]otlpSpan)
+	for _, span := range c.spans {
+		spans[span.Kind] = span
+	}
+	return spans
+}
+
+func TestSpansExportedWithParents(t *testing.T) {
+	c, url := startCollector(t)
+	tracer, err := New(url, "gate4ai-test", 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const incoming = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	server := tracer.Start("tools/call", KindServer, incoming)
+	server.SetAttribute(AttrMethod, "tools/call")
+	client := server.StartChild("tools/call", KindClient)
+	client.SetAttribute(AttrBackend, "b1")
+	if got := client.Traceparent(); !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || !strings.HasSuffix(got, "-01") {
+		t.Errorf("Expected the child to continue the incoming trace, got %q", got)
+	}
+	client.End(errors.New("backend failed"))
+	client.End(nil) // Ignored
+	server.End(nil)
+
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	spans := c.received()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %+v", spans)
+	}
+	serverSpan, clientSpan := spans[int(KindServer)], spans[int(KindClient)]
+	if serverSpan.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || serverSpan.ParentSpanID != "00f067aa0ba902b7" || serverSpan.Kind != int(KindServer) {
+		t.Errorf("Unexpected server span %+v", serverSpan)
+	}
+	if clientSpan.TraceID != serverSpan.TraceID || clientSpan.ParentSpanID != serverSpan.SpanID || clientSpan.Kind != int(KindClient) {
+		t.Errorf("Expected the client span to be a child of the server span, got %+v", clientSpan)
+	}
+	if len(clientSpan.Attributes) != 1 || clientSpan.Attributes[0].Key != AttrBackend || clientSpan.Attributes[0].Value.StringValue != "b1" {
+		t.Errorf("Unexpected attributes %+v", clientSpan.Attributes)
+	}
+	if clientSpan.Status.Code != statusError || clientSpan.Status.Message != "backend failed" || serverSpan.Status.Code != 0 {
+		t.Errorf("Unexpected statuses %+v and %+v", clientSpan.Status, serverSpan.Status)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	c, url := startCollector(t)
+	tracer, err := New(url, "gate4ai-test", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := tracer.Start("root", KindServer, "")
+	if !strings.HasSuffix(root.Traceparent(), "-00") {
+		t.Errorf("Expected an unsampled root span at ratio 0, got %q", root.Traceparent())
+	}
+	root.End(nil)
+	unsampled := tracer.Start("unsampled parent", KindServer, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	unsampled.End(nil)
+	sampled := tracer.Start("sampled parent", KindServer, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	sampled.End(nil)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(c.received()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Sampled span was not exported")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	tracer.Shutdown(context.Background())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.spans) != 1 || c.spans[0].Name != "sampled parent" {
+		t.Errorf("Expected only the span of the sampled parent, got %+v", c.spans)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":       true,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra": true, // Future versions may add fields
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra": false,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":       false,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01":       false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":       false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":       false,
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01":       false,
+		"":        false,
+		"garbage": false,
+	} {
+		if _, ok := parseTraceparent(value); ok != valid {
+			t.Errorf("parseTraceparent(%q) valid = %v, want %v", value, ok, valid)
+		}
+	}
+}
+
+func TestNilTracerAndSpan(t *testing.T) {
+	var tracer *Tracer
+	span := tracer.Start("request", KindServer, "")
+	span.SetAttribute(AttrMethod, "tools/call")
+	span.StartChild("backend", KindClient).End(nil)
+	span.End(nil)
+	if span.Traceparent() != "" {
+		t.Errorf("Expected no traceparent of a nil span")
+	}
+	if SpanFromContext(ContextWithSpan(context.Background(), span)) != nil {
+		t.Errorf("Expected no span in the context")
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown of a nil tracer failed: %v", err)
+	}
+}
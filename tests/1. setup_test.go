@@ -680,7 +680,9 @@ func startGatewayServer(ctx context.Context, port int) (*Server, error) {
 	if err != nil {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
-	cfgGw, err := config.NewDatabaseConfig(DATABASE_URL, logger)
+	// The tests change the database through the portal and expect the gateway to see
+	// the changes at once, so nothing is cached
+	cfgGw, err := config.NewDatabaseConfigWithOptions(DATABASE_URL, logger, config.DatabaseConfigOptions{})
 	if err != nil {
 		log.Fatalf("Failed to create config: %v", err)
 	}